@@ -1,53 +1,73 @@
-package main
+package handler
 
 import (
-	"github.com/go-chi/chi/v5"
 	"net/http"
+	product "sago-sample/feature/product/domain"
 	"sago-sample/feature/product/handler"
+	"sago-sample/feature/product/handler/gql"
+	"sago-sample/feature/product/handler/sse"
 	"sago-sample/feature/product/infrastructure"
 	usecase "sago-sample/feature/product/usecase"
+	webhookHandler "sago-sample/feature/webhook/handler"
+	webhookInfra "sago-sample/feature/webhook/infrastructure"
+	webhookUseCase "sago-sample/feature/webhook/usecase"
 )
 
 func Handler(w http.ResponseWriter, r *http.Request) {
 	// 1) DI: リポジトリ → ユースケース → ハンドラーを組み立て
 	repo := infrastructure.NewProductRepository()
+	svc := product.NewService(repo)
 	ucGetAll := usecase.NewGetAllProductsUseCase(repo)
 	ucGetByID := usecase.NewGetProductUseCase(repo)
-	//ucCreate := usecase.NewCreateProductUseCase(repo)
-	//ucUpdate := usecase.NewUpdateProductUseCase(repo)
-	//ucDelete := usecase.NewDeleteProductUseCase(repo)
-	//ucAddCat := usecase.NewAddCategoryToProductUseCase(repo)
-	//ucRemCat := usecase.NewRemoveCategoryFromProductUseCase(repo)
-	// 必要なら他のユースケースも…
+	ucList := usecase.NewListProductsUseCase(repo)
+	ucByCategory := usecase.NewGetProductsByCategoryUseCase(svc)
+	ucCreate := usecase.NewCreateProductUseCase(svc)
+	ucUpdate := usecase.NewUpdateProductUseCase(svc)
+	ucPatch := usecase.NewPatchProductUseCase(svc)
+	ucDelete := usecase.NewDeleteProductUseCase(svc)
+	ucAddCat := usecase.NewAddCategoryToProductUseCase(svc)
+	ucRemCat := usecase.NewRemoveCategoryFromProductUseCase(svc)
 
-	hGet := handler.NewGetProductHandler(ucGetByID, ucGetAll)
-	//hCreate := handler.NewCreateProductHandler(ucCreate)
-	//hUpdate := handler.NewUpdateProductHandler(ucUpdate)
-	//hDelete := handler.NewDeleteProductHandler(ucDelete)
-	//hCat := handler.NewCategoryHandler(ucAddCat, ucRemCat)
-	// それぞれ internal/handler/*.go ファイルに定義しておきます
+	hGet := handler.NewGetProductHandler(ucGetByID, ucList, ucByCategory)
+	hCreate := handler.NewCreateProductHandler(ucCreate)
+	hUpdate := handler.NewUpdateProductHandler(ucUpdate, ucGetByID)
+	hPatch := handler.NewPatchProductHandler(ucPatch)
+	hDelete := handler.NewDeleteProductHandler(ucDelete)
+	hCat := handler.NewCategoryHandler(ucAddCat, ucRemCat)
+	hGraphQL, err := gql.NewHandler(ucCreate, ucUpdate, ucDelete, ucGetByID, ucGetAll, ucAddCat, ucRemCat, ucByCategory)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	// 2) chi ルーターにパスを定義
-	rtr := chi.NewRouter()
+	broker := sse.NewBroker(sse.DefaultReplaySize)
+	svc.Subscribe(broker)
+	hStream := sse.NewHandler(broker, ucGetByID, sse.DefaultHeartbeat)
 
-	// Product
-	//rtr.Get("/api/products", hGet.HandleGetAll)       // GET  /api/products
-	rtr.Get("/api/products/{id}", hGet.HandleGetByID) // GET  /api/products/{id}
-	//rtr.Post("/api/products", hCreate.Handle)         // POST /api/products
-	//rtr.Put("/api/products/{id}", hUpdate.Handle)     // PUT  /api/products/{id}
-	//rtr.Delete("/api/products/{id}", hDelete.Handle)  // DELETE /api/products/{id}
+	// Webhook の配信ワーカーは常駐プロセス (cmd/app) でのみ動かす
+	subRepo := webhookInfra.NewSubscriptionRepository()
+	deliveryRepo := webhookInfra.NewDeliveryRepository()
+	hWebhook := webhookHandler.NewSubscriptionHandler(
+		webhookUseCase.NewCreateSubscriptionUseCase(subRepo),
+		webhookUseCase.NewGetSubscriptionUseCase(subRepo),
+		webhookUseCase.NewListSubscriptionsUseCase(subRepo),
+		webhookUseCase.NewUpdateSubscriptionUseCase(subRepo),
+		webhookUseCase.NewDeleteSubscriptionUseCase(subRepo),
+		webhookUseCase.NewListDeliveriesUseCase(subRepo, deliveryRepo),
+	)
 
-	// Category on Product
-	//rtr.Post("/api/products/{id}/categories", hCat.HandleAdd)            // POST   /api/products/{id}/categories
-	//rtr.Delete("/api/products/{id}/categories/{cid}", hCat.HandleRemove) // DELETE /api/products/{id}/categories/{cid}
-
-	// Optional: List by category
-	//rtr.Get("/api/categories/{id}/products", hGet.HandleByCategory)
-
-	//hello world
-	rtr.Get("/api/hello", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte("Hello, World!"))
+	// 2) chi ルーターにパスを定義 (feature/product/handler/router.go)
+	rtr := handler.NewRouter(handler.Handlers{
+		GetProduct:    hGet,
+		CreateProduct: hCreate,
+		UpdateProduct: hUpdate,
+		PatchProduct:  hPatch,
+		DeleteProduct: hDelete,
+		Category:      hCat,
+		GraphQL:       hGraphQL,
+		Stream:        hStream,
 	})
+	hWebhook.Register(rtr)
 
 	// 3) エントリポイントにリクエストを渡す
 	rtr.ServeHTTP(w, r)
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"sago-sample/config"
+	cartDomain "sago-sample/feature/cart/domain"
+	cartInfra "sago-sample/feature/cart/infrastructure"
+	cartPostgres "sago-sample/feature/cart/infrastructure/postgres"
+	orderDomain "sago-sample/feature/order/domain"
+	orderInfra "sago-sample/feature/order/infrastructure"
+	orderPostgres "sago-sample/feature/order/infrastructure/postgres"
+	product "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/infrastructure"
+	"sago-sample/feature/product/infrastructure/alerting"
+	"sago-sample/feature/product/infrastructure/blob"
+	"sago-sample/feature/product/infrastructure/cache"
+	"sago-sample/feature/product/infrastructure/postgres"
+)
+
+// Backend holds the repositories of the configured backend, so that the HTTP and gRPC servers store their data alike
+type Backend struct {
+	Products     product.Repository
+	Warehouses   product.WarehouseRepository
+	Movements    product.MovementRepository
+	Policies     product.ReorderPolicyRepository
+	Schemas      product.AttributeSchemaRepository
+	Translations product.CategoryTranslationRepository
+	Orders       orderDomain.Repository
+	Carts        cartDomain.Repository
+	Reservations cartDomain.ReservationRepository
+	// Transactor saves the products and their ledger movements together
+	Transactor product.Transactor
+	// Snapshots read the products and their ledger from one consistent snapshot
+	Snapshots product.Transactor
+	// close releases the backend
+	close func() error
+}
+
+// NewBackend creates the repositories of the configured backend; Close releases them
+func NewBackend(cfg config.Config) (*Backend, error) {
+	switch cfg.Repository.Backend {
+	case config.BackendPostgres:
+		db, err := postgres.Open(cfg.Database.URL, postgres.PoolOptions{
+			MaxOpenConns:    cfg.Database.MaxOpenConns,
+			MaxIdleConns:    cfg.Database.MaxIdleConns,
+			ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("connect to database: %w", err)
+		}
+		return &Backend{
+			Products:     postgres.NewProductRepository(db),
+			Warehouses:   postgres.NewWarehouseRepository(db),
+			Movements:    postgres.NewMovementRepository(db),
+			Policies:     postgres.NewReorderPolicyRepository(db),
+			Schemas:      postgres.NewAttributeSchemaRepository(db),
+			Translations: postgres.NewCategoryTranslationRepository(db),
+			Orders:       orderPostgres.NewOrderRepository(db),
+			Carts:        cartPostgres.NewCartRepository(db),
+			Reservations: cartPostgres.NewReservationRepository(db),
+			Transactor:   postgres.NewTransactor(db),
+			Snapshots:    postgres.NewSnapshotTransactor(db),
+			close:        func() error { return postgres.Close(db) },
+		}, nil
+	default:
+		return &Backend{
+			Products:     infrastructure.NewProductRepository(),
+			Warehouses:   infrastructure.NewWarehouseRepository(),
+			Movements:    infrastructure.NewMovementRepository(),
+			Policies:     infrastructure.NewReorderPolicyRepository(),
+			Schemas:      infrastructure.NewAttributeSchemaRepository(),
+			Translations: infrastructure.NewCategoryTranslationRepository(),
+			Orders:       orderInfra.NewOrderRepository(),
+			Carts:        cartInfra.NewCartRepository(),
+			Reservations: cartInfra.NewReservationRepository(),
+			Transactor:   product.NoTransaction,
+			Snapshots:    product.NoTransaction,
+			close:        func() error { return nil },
+		}, nil
+	}
+}
+
+// Close releases the backend
+func (b *Backend) Close() error {
+	return b.close()
+}
+
+// NewProductCache serves the product lookups by ID of products from an in-process cache
+func NewProductCache(products product.Repository) *cache.Repository {
+	return cache.NewRepository(
+		products,
+		cache.NewLRUCache(cache.DefaultLRUCapacity),
+		cache.Options{TTL: cache.DefaultTTL, NegativeTTL: cache.DefaultNegativeTTL},
+	)
+}
+
+// NewProductService creates the product service over products, the products of the backend possibly decorated and cached.
+// Stock changes are saved with their ledger movements in one transaction.
+func (b *Backend) NewProductService(products product.Repository) *product.Service {
+	service := product.NewService(products)
+	service.SetTransactor(b.Transactor)
+	service.SetLedger(b.Movements)
+	return service
+}
+
+// Subscribers are the subscribers to product events of both servers
+type Subscribers struct {
+	// Media deletes the image files of deleted products
+	Media *product.MediaService
+	// Evaluator alerts on low and out-of-stock products
+	Evaluator *alerting.Evaluator
+	// closeNotifier releases the notifier of the alerts
+	closeNotifier func() error
+}
+
+// Subscribe creates the media service and the alert evaluator configured by cfg, subscribes them to service
+// and starts the evaluator; Stop and Close release them
+func (b *Backend) Subscribe(cfg config.Config, service *product.Service) (*Subscribers, error) {
+	imageStore, err := blob.NewLocalStore(cfg.Media.Dir)
+	if err != nil {
+		return nil, err
+	}
+	notifier, closeNotifier, err := alerting.NewNotifier(cfg.Alerts.Notifier, cfg.Alerts.WebhookURL, cfg.Alerts.File)
+	if err != nil {
+		return nil, fmt.Errorf("alerts: %w", err)
+	}
+
+	s := &Subscribers{
+		Media:         product.NewMediaService(service, imageStore, cfg.Media.ImageLimits()),
+		Evaluator:     alerting.NewEvaluator(b.Policies, notifier, alerting.Config{Cooldown: cfg.Alerts.Cooldown}),
+		closeNotifier: closeNotifier,
+	}
+	service.Subscribe(s.Evaluator)
+	s.Evaluator.Start()
+	service.Subscribe(s.Media)
+	return s, nil
+}
+
+// Stop waits for the alerts being evaluated, up to the deadline of ctx
+func (s *Subscribers) Stop(ctx context.Context) error {
+	if err := s.Evaluator.Stop(ctx); err != nil {
+		return fmt.Errorf("alert evaluator: %w", err)
+	}
+	return nil
+}
+
+// Close releases the notifier of the alerts
+func (s *Subscribers) Close() error {
+	return s.closeNotifier()
+}
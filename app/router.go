@@ -0,0 +1,74 @@
+// Package app assembles the HTTP API of the server binary from the handlers of every feature,
+// so that the binary and the tests serve the same routes, and the backend both server binaries store their data in.
+package app
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	cartHandler "sago-sample/feature/cart/handler"
+	orderHandler "sago-sample/feature/order/handler"
+	"sago-sample/feature/product/handler"
+	"sago-sample/feature/product/handler/sse"
+	webhookHandler "sago-sample/feature/webhook/handler"
+	"sago-sample/observability/health"
+)
+
+// Handlers are the handlers of every route of the API
+type Handlers struct {
+	GetProduct    *handler.GetProductHandler
+	CreateProduct *handler.CreateProductHandler
+	UpdateProduct *handler.UpdateProductHandler
+	PatchProduct  *handler.PatchProductHandler
+	DeleteProduct *handler.DeleteProductHandler
+	Category      *handler.CategoryHandler
+	GraphQL       http.Handler
+	Stream        *sse.Handler
+	Inventory     *handler.InventoryHandler
+	StockMovement *handler.StockMovementHandler
+	Reorder       *handler.ReorderHandler
+	Attribute     *handler.AttributeHandler
+	Facet         *handler.FacetHandler
+	Image         *handler.ImageHandler
+	Translation   *handler.TranslationHandler
+	Lifecycle     *handler.LifecycleHandler
+	Subscription  *webhookHandler.SubscriptionHandler
+	Order         *orderHandler.OrderHandler
+	Cart          *cartHandler.CartHandler
+	Metrics       http.Handler
+	Health        *health.Handler
+}
+
+// NewRouter creates the router serving every route of the API.
+// The middlewares, e.g. metrics, run first in the given order; see handler.NewRouter.
+func NewRouter(h Handlers, middlewares ...func(http.Handler) http.Handler) chi.Router {
+	rtr := handler.NewRouter(handler.Handlers{
+		GetProduct:    h.GetProduct,
+		CreateProduct: h.CreateProduct,
+		UpdateProduct: h.UpdateProduct,
+		PatchProduct:  h.PatchProduct,
+		DeleteProduct: h.DeleteProduct,
+		Category:      h.Category,
+		GraphQL:       h.GraphQL,
+		Stream:        h.Stream,
+	}, middlewares...)
+	h.Inventory.Register(rtr)
+	h.StockMovement.Register(rtr)
+	h.Reorder.Register(rtr)
+	h.Attribute.Register(rtr)
+	h.Facet.Register(rtr)
+	h.Image.Register(rtr)
+	h.Translation.Register(rtr)
+	h.Lifecycle.Register(rtr)
+	h.Subscription.Register(rtr)
+	h.Order.Register(rtr)
+	h.Cart.Register(rtr)
+
+	// Observability
+	rtr.Get("/metrics", h.Metrics.ServeHTTP) // GET /metrics
+	rtr.Get("/healthz", h.Health.HandleLive) // GET /healthz
+	rtr.Get("/readyz", h.Health.HandleReady) // GET /readyz
+
+	return rtr
+}
@@ -0,0 +1,227 @@
+// Package client is a Go client for the product HTTP API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+)
+
+// Option configures a ProductClient
+type Option func(*ProductClient)
+
+// WithHTTPClient sets the HTTP client used to send requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *ProductClient) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times a failed request is retried and the backoff bounds between attempts
+func WithRetries(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *ProductClient) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithHeader adds a header to every request, e.g. an API key
+func WithHeader(key, value string) Option {
+	return func(c *ProductClient) {
+		c.header.Add(key, value)
+	}
+}
+
+// ProductClient is a typed client for the product API
+type ProductClient struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	header     http.Header
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// NewProductClient creates a new ProductClient for the server at baseURL, e.g. "http://localhost:8080"
+func NewProductClient(baseURL string, opts ...Option) (*ProductClient, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base url %q: scheme and host are required", baseURL)
+	}
+
+	c := &ProductClient{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		header:     make(http.Header),
+		maxRetries: defaultMaxRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// response is a decoded successful response
+type response struct {
+	header http.Header
+}
+
+// do sends a request and decodes a JSON response into out.
+// 429 responses are retried for every method, 5xx responses and transport errors only for idempotent methods,
+// since a POST that reached the server may already have been applied.
+func (c *ProductClient) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) (*response, error) {
+	var body []byte
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return nil, fmt.Errorf("encode request: %w", err)
+		}
+		body = b
+	}
+
+	// path is already escaped, so it is appended to the base URL as is
+	rawURL := c.baseURL.String() + path
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, rawURL, body)
+		if err != nil {
+			if ctx.Err() != nil || !isIdempotent(method) || attempt >= c.maxRetries {
+				return nil, err
+			}
+			if err := c.wait(ctx, attempt, ""); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if out != nil && resp.StatusCode != http.StatusNoContent {
+				if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+					return nil, fmt.Errorf("decode response: %w", err)
+				}
+			}
+			return &response{header: resp.Header}, nil
+		}
+
+		apiErr := decodeError(resp)
+		retryable := resp.StatusCode == http.StatusTooManyRequests ||
+			(resp.StatusCode >= http.StatusInternalServerError && isIdempotent(method))
+		if !retryable || attempt >= c.maxRetries {
+			return nil, apiErr
+		}
+		if err := c.wait(ctx, attempt, resp.Header.Get("Retry-After")); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send performs a single HTTP request
+func (c *ProductClient) send(ctx context.Context, method, rawURL string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// Continue the caller's trace on the server (a no-op unless a global propagator is set)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	return c.httpClient.Do(req)
+}
+
+// wait sleeps before the next attempt, honoring Retry-After when the server sent one
+func (c *ProductClient) wait(ctx context.Context, attempt int, retryAfter string) error {
+	delay := c.backoff(attempt)
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+		if delay > c.maxBackoff {
+			delay = c.maxBackoff
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff returns the exponential backoff with jitter for an attempt
+func (c *ProductClient) backoff(attempt int) time.Duration {
+	d := c.minBackoff << attempt
+	if d <= 0 || d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	// Full jitter between d/2 and d
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// decodeError reads the server's error body into an APIError
+func decodeError(resp *http.Response) *APIError {
+	defer resp.Body.Close()
+
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err == nil && body.Error != "" {
+		apiErr.Message = body.Error
+	}
+
+	return apiErr
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrBadRequest matches errors for requests the server rejected as invalid (400)
+	ErrBadRequest = errors.New("bad request")
+	// ErrNotFound matches errors for products that do not exist (404)
+	ErrNotFound = errors.New("not found")
+	// ErrConflict matches errors for products that already exist or conflicting changes (409)
+	ErrConflict = errors.New("conflict")
+	// ErrRateLimited matches errors for requests rejected by rate limiting (429)
+	ErrRateLimited = errors.New("rate limited")
+	// ErrServer matches errors for failures on the server side (5xx)
+	ErrServer = errors.New("server error")
+)
+
+// APIError is returned when the server answers with an error response.
+// It mirrors the server's {"error": "..."} body and can be matched with errors.Is against the Err* sentinels.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error returns the status code and the message sent by the server
+func (e *APIError) Error() string {
+	return fmt.Sprintf("product api: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is reports whether the error belongs to the class of target
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	default:
+		return false
+	}
+}
@@ -0,0 +1,330 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Category is a category assigned to a product
+type Category struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Product is a product returned by the API
+type Product struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Price       uint   `json:"price"`
+	Currency    string `json:"currency"`
+	Status      string `json:"status"`
+	// AvailableFrom and AvailableUntil bound the availability window; nil when open
+	AvailableFrom  *time.Time `json:"availableFrom,omitempty"`
+	AvailableUntil *time.Time `json:"availableUntil,omitempty"`
+	// Attributes holds the custom attribute values by key; nil when none is set
+	Attributes map[string]any `json:"attributes,omitempty"`
+	Stock      uint           `json:"stock"`
+	Categories []Category     `json:"categories"`
+}
+
+// Product lifecycle statuses; only published products inside their availability window are returned by the public reads
+const (
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// CreateProductRequest is the body of CreateProduct
+type CreateProductRequest struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Price       uint   `json:"price"`
+	Currency    string `json:"currency"`
+	Stock       uint   `json:"stock"`
+}
+
+// UpdateProductRequest is the body of UpdateProduct
+type UpdateProductRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Price       uint   `json:"price"`
+	Currency    string `json:"currency"`
+	Stock       uint   `json:"stock"`
+}
+
+// PatchProductRequest is the body of PatchProduct; nil fields keep their current value
+type PatchProductRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Price       *uint   `json:"price,omitempty"`
+	Currency    *string `json:"currency,omitempty"`
+	Stock       *uint   `json:"stock,omitempty"`
+}
+
+// ListProductsOptions selects a page of products
+type ListProductsOptions struct {
+	// Limit is the page size; zero lists every product in one page
+	Limit int
+	// Cursor is the NextCursor of the previous page
+	Cursor string
+	// Attributes only lists the products whose attributes have each of these values, e.g. {"color": "red"}
+	Attributes map[string]string
+}
+
+// ProductPage is one page of products ordered by ID
+type ProductPage struct {
+	Products []Product
+	// NextCursor is empty on the last page
+	NextCursor string
+	Total      int
+}
+
+// FacetOptions holds the current storefront filters; each facet is counted without its own filter
+type FacetOptions struct {
+	CategoryID string
+	// MinPrice and MaxPrice bound the price in minor units, inclusive; a zero MaxPrice leaves it unbounded
+	MinPrice uint
+	MaxPrice uint
+	Currency string
+	// InStock, when set, only keeps the products with (true) or without (false) stock
+	InStock    *bool
+	Attributes map[string]string
+	// PriceBuckets replaces the price bucket bounds configured on the server
+	PriceBuckets []uint
+}
+
+// Facets are the product counts per category, price bucket, stock availability and attribute value
+type Facets struct {
+	Total      int `json:"total"`
+	Categories []struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	} `json:"categories"`
+	Prices []struct {
+		From uint `json:"from"`
+		// To is zero for the last, unbounded bucket
+		To    uint `json:"to"`
+		Count int  `json:"count"`
+	} `json:"prices"`
+	Stock struct {
+		InStock    int `json:"inStock"`
+		OutOfStock int `json:"outOfStock"`
+	} `json:"stock"`
+	Attributes []struct {
+		Key    string `json:"key"`
+		Values []struct {
+			Value string `json:"value"`
+			Count int    `json:"count"`
+		} `json:"values"`
+	} `json:"attributes"`
+}
+
+// CreateProduct creates a new product
+func (c *ProductClient) CreateProduct(ctx context.Context, req CreateProductRequest) (*Product, error) {
+	var p Product
+	if _, err := c.do(ctx, http.MethodPost, "/api/products", nil, req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetProduct returns a published product by ID; other products are not found
+func (c *ProductClient) GetProduct(ctx context.Context, id string) (*Product, error) {
+	var p Product
+	if _, err := c.do(ctx, http.MethodGet, "/api/products/"+url.PathEscape(id), nil, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UpdateProduct replaces every field of a product
+func (c *ProductClient) UpdateProduct(ctx context.Context, id string, req UpdateProductRequest) (*Product, error) {
+	var p Product
+	if _, err := c.do(ctx, http.MethodPut, "/api/products/"+url.PathEscape(id), nil, req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// PatchProduct updates the non-nil fields of a product
+func (c *ProductClient) PatchProduct(ctx context.Context, id string, req PatchProductRequest) (*Product, error) {
+	var p Product
+	if _, err := c.do(ctx, http.MethodPatch, "/api/products/"+url.PathEscape(id), nil, req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// DeleteProduct deletes a product
+func (c *ProductClient) DeleteProduct(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/api/products/"+url.PathEscape(id), nil, nil, nil)
+	return err
+}
+
+// ListProducts returns one page of published products
+func (c *ProductClient) ListProducts(ctx context.Context, opts ListProductsOptions) (*ProductPage, error) {
+	query := url.Values{}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
+	}
+	for key, value := range opts.Attributes {
+		query.Set("attr."+key, value)
+	}
+
+	var products []Product
+	resp, err := c.do(ctx, http.MethodGet, "/api/products", query, nil, &products)
+	if err != nil {
+		return nil, err
+	}
+
+	total, _ := strconv.Atoi(resp.header.Get("X-Total-Count"))
+	return &ProductPage{
+		Products:   products,
+		NextCursor: resp.header.Get("X-Next-Cursor"),
+		Total:      total,
+	}, nil
+}
+
+// Products iterates over every product, fetching pages of pageSize as needed.
+// Iteration stops after the first error, which is yielded with a zero Product.
+func (c *ProductClient) Products(ctx context.Context, pageSize int) iter.Seq2[Product, error] {
+	return func(yield func(Product, error) bool) {
+		opts := ListProductsOptions{Limit: pageSize}
+		for {
+			page, err := c.ListProducts(ctx, opts)
+			if err != nil {
+				yield(Product{}, err)
+				return
+			}
+			for _, p := range page.Products {
+				if !yield(p, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			opts.Cursor = page.NextCursor
+		}
+	}
+}
+
+// ProductsByCategory returns the published products of a category
+func (c *ProductClient) ProductsByCategory(ctx context.Context, categoryID string) ([]Product, error) {
+	var products []Product
+	if _, err := c.do(ctx, http.MethodGet, "/api/categories/"+url.PathEscape(categoryID)+"/products", nil, nil, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// AddCategory assigns a category to a product
+func (c *ProductClient) AddCategory(ctx context.Context, productID, categoryID, categoryName string) (*Product, error) {
+	req := struct {
+		CategoryID   string `json:"categoryId"`
+		CategoryName string `json:"categoryName"`
+	}{categoryID, categoryName}
+
+	var p Product
+	if _, err := c.do(ctx, http.MethodPost, "/api/products/"+url.PathEscape(productID)+"/categories", nil, req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// RemoveCategory removes a category from a product
+func (c *ProductClient) RemoveCategory(ctx context.Context, productID, categoryID string) (*Product, error) {
+	var p Product
+	path := "/api/products/" + url.PathEscape(productID) + "/categories/" + url.PathEscape(categoryID)
+	if _, err := c.do(ctx, http.MethodDelete, path, nil, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ChangeStatus moves a product to another lifecycle status, e.g. StatusPublished
+func (c *ProductClient) ChangeStatus(ctx context.Context, id, status string) (*Product, error) {
+	req := struct {
+		Status string `json:"status"`
+	}{status}
+
+	var p Product
+	if _, err := c.do(ctx, http.MethodPost, "/api/admin/products/"+url.PathEscape(id)+"/status", nil, req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SetAvailability schedules when a published product is visible to customers; a nil bound leaves that side of the window open
+func (c *ProductClient) SetAvailability(ctx context.Context, id string, from, until *time.Time) (*Product, error) {
+	req := struct {
+		AvailableFrom  *time.Time `json:"availableFrom,omitempty"`
+		AvailableUntil *time.Time `json:"availableUntil,omitempty"`
+	}{from, until}
+
+	var p Product
+	if _, err := c.do(ctx, http.MethodPut, "/api/admin/products/"+url.PathEscape(id)+"/availability", nil, req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SetAttributes replaces the attributes of a product; they must be defined by the attribute schemas of its categories
+func (c *ProductClient) SetAttributes(ctx context.Context, id string, attributes map[string]any) (*Product, error) {
+	req := struct {
+		Attributes map[string]any `json:"attributes"`
+	}{attributes}
+
+	var p Product
+	if _, err := c.do(ctx, http.MethodPut, "/api/products/"+url.PathEscape(id)+"/attributes", nil, req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Facets counts the published products per category, price bucket, stock availability and attribute value
+func (c *ProductClient) Facets(ctx context.Context, opts FacetOptions) (*Facets, error) {
+	query := url.Values{}
+	if opts.CategoryID != "" {
+		query.Set("category", opts.CategoryID)
+	}
+	if opts.MinPrice > 0 {
+		query.Set("minPrice", strconv.FormatUint(uint64(opts.MinPrice), 10))
+	}
+	if opts.MaxPrice > 0 {
+		query.Set("maxPrice", strconv.FormatUint(uint64(opts.MaxPrice), 10))
+	}
+	if opts.Currency != "" {
+		query.Set("currency", opts.Currency)
+	}
+	if opts.InStock != nil {
+		query.Set("inStock", strconv.FormatBool(*opts.InStock))
+	}
+	for key, value := range opts.Attributes {
+		query.Set("attr."+key, value)
+	}
+	if len(opts.PriceBuckets) > 0 {
+		bounds := make([]string, len(opts.PriceBuckets))
+		for i, b := range opts.PriceBuckets {
+			bounds[i] = strconv.FormatUint(uint64(b), 10)
+		}
+		query.Set("priceBuckets", strings.Join(bounds, ","))
+	}
+
+	var f Facets
+	if _, err := c.do(ctx, http.MethodGet, "/api/products/facets", query, nil, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
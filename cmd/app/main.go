@@ -1,54 +1,437 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"sago-sample/api"
+	"os"
+	"os/signal"
+	"sago-sample/app"
+	"sago-sample/config"
+	cartDomain "sago-sample/feature/cart/domain"
+	cartHandler "sago-sample/feature/cart/handler"
+	cartUseCase "sago-sample/feature/cart/usecase"
+	orderDomain "sago-sample/feature/order/domain"
+	orderHandler "sago-sample/feature/order/handler"
+	orderUseCase "sago-sample/feature/order/usecase"
 	product "sago-sample/feature/product/domain"
-	"sago-sample/feature/product/infrastructure"
+	"sago-sample/feature/product/handler"
+	"sago-sample/feature/product/handler/gql"
+	"sago-sample/feature/product/handler/sse"
 	productUseCase "sago-sample/feature/product/usecase"
+	webhookHandler "sago-sample/feature/webhook/handler"
+	webhookInfra "sago-sample/feature/webhook/infrastructure"
+	webhookUseCase "sago-sample/feature/webhook/usecase"
+	"sago-sample/observability/health"
+	"sago-sample/observability/logging"
+	"sago-sample/observability/metrics"
+	"sago-sample/observability/tracing"
+	"sago-sample/ratelimit"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel"
 )
 
 func main() {
-	// Create repositories
-	productRepo := infrastructure.NewProductRepository()
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if err := run(cfg); err != nil {
+		slog.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run serves the API until SIGINT or SIGTERM, then drains in-flight requests and stops the background workers
+func run(cfg config.Config) (err error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Structured logs
+	logger, err := logging.New(os.Stdout, logging.Config{Format: cfg.Log.Format, Level: cfg.Log.Level})
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	logger.Info("configuration loaded", "config", cfg)
+	productUseCase.AddObserver(logging.UseCaseLogger{})
+
+	// Metrics for requests, use cases and repositories
+	appMetrics := metrics.New()
+	productUseCase.AddObserver(appMetrics)
+
+	// Traces for requests, use cases and repositories
+	tracerProvider, err := tracing.NewProvider(ctx, tracing.Config{
+		Exporter:     cfg.Tracing.Exporter,
+		ServiceName:  cfg.Tracing.ServiceName,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+	})
+	if err != nil {
+		return err
+	}
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(tracing.Propagator())
+	appTracer := tracing.New(tracerProvider, nil)
+	productUseCase.AddObserver(appTracer)
+
+	// Create repositories; product lookups by ID are served from an in-process cache
+	store, err := app.NewBackend(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := store.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+	}()
+	productRepo := app.NewProductCache(metrics.NewProductRepository(tracing.NewProductRepository(store.Products, appTracer), appMetrics))
+	if err := appMetrics.Register(metrics.NewProductCollector(productRepo), metrics.NewCacheCollector(productRepo.Stats)); err != nil {
+		return err
+	}
+
+	subscriptionRepo := webhookInfra.NewSubscriptionRepository()
+	deliveryRepo := webhookInfra.NewDeliveryRepository()
 
 	// Create domain services
-	productService := product.NewService(productRepo)
+	// Stock changes are saved with their ledger movements in one transaction
+	productService := store.NewProductService(productRepo)
+	inventoryService := product.NewInventoryService(productService, store.Warehouses)
+	attributeService := product.NewAttributeService(productService, store.Schemas)
+	locales, err := cfg.Catalog.SupportedLocales()
+	if err != nil {
+		return err
+	}
+	translationService := product.NewTranslationService(productService, store.Translations, locales)
+	orderService := orderDomain.NewService(store.Orders, productRepo, inventoryService)
+	cartService := cartDomain.NewService(
+		store.Carts,
+		store.Reservations,
+		productRepo,
+		inventoryService,
+		cartDomain.Config{TTL: cfg.Cart.TTL, ReservationTTL: cfg.Cart.ReservationTTL, Clock: productService.Clock()},
+	)
+
+	// Deliver product events to webhook subscribers
+	dispatcher := webhookInfra.NewDispatcher(subscriptionRepo, deliveryRepo, webhookInfra.DefaultDispatcherConfig())
+	productService.Subscribe(dispatcher)
+	dispatcher.Start()
+
+	// Alert on low and out-of-stock products, and delete the image files of deleted products
+	subscribers, err := store.Subscribe(cfg, productService)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := subscribers.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+	}()
+	mediaService := subscribers.Media
+
+	// Stream product events to Server-Sent Events clients
+	broker := sse.NewBroker(sse.DefaultReplaySize)
+	productService.Subscribe(broker)
 
 	// Create use cases
 	createProductUseCase := productUseCase.NewCreateProductUseCase(productService)
 	updateProductUseCase := productUseCase.NewUpdateProductUseCase(productService)
 	deleteProductUseCase := productUseCase.NewDeleteProductUseCase(productService)
-	getProductByIDUseCase := productUseCase.NewGetProductByIDUseCase(productService)
-	getAllProductsUseCase := productUseCase.NewGetAllProductsUseCase(productService)
+	getProductUseCase := productUseCase.NewGetProductUseCase(productRepo)
+	getAllProductsUseCase := productUseCase.NewGetAllProductsUseCase(productRepo)
+	listProductsUseCase := productUseCase.NewListProductsUseCase(productRepo)
+	patchProductUseCase := productUseCase.NewPatchProductUseCase(productService)
+	changeProductStatusUseCase := productUseCase.NewChangeProductStatusUseCase(productService)
+	setProductAvailabilityUseCase := productUseCase.NewSetProductAvailabilityUseCase(productService)
+	scheduleAvailabilityUseCase := productUseCase.NewScheduleAvailabilityUseCase(productService)
 
 	// Create category-related use cases
 	addCategoryToProductUseCase := productUseCase.NewAddCategoryToProductUseCase(productService)
 	removeCategoryFromProductUseCase := productUseCase.NewRemoveCategoryFromProductUseCase(productService)
 	getProductsByCategoryUseCase := productUseCase.NewGetProductsByCategoryUseCase(productService)
+	setAttributeSchemaUseCase := productUseCase.NewSetAttributeSchemaUseCase(attributeService)
+	getAttributeSchemaUseCase := productUseCase.NewGetAttributeSchemaUseCase(attributeService)
+	deleteAttributeSchemaUseCase := productUseCase.NewDeleteAttributeSchemaUseCase(attributeService)
+	setProductAttributesUseCase := productUseCase.NewSetProductAttributesUseCase(attributeService)
+	getProductFacetsUseCase := productUseCase.NewGetProductFacetsUseCase(productRepo, cfg.Catalog.PriceBuckets)
+	uploadProductImageUseCase := productUseCase.NewUploadProductImageUseCase(mediaService)
+	removeProductImageUseCase := productUseCase.NewRemoveProductImageUseCase(mediaService)
+	reorderProductImagesUseCase := productUseCase.NewReorderProductImagesUseCase(mediaService)
+	getProductImageUseCase := productUseCase.NewGetProductImageUseCase(productRepo, mediaService)
+	getProductTranslationsUseCase := productUseCase.NewGetProductTranslationsUseCase(translationService)
+	setProductTranslationUseCase := productUseCase.NewSetProductTranslationUseCase(translationService)
+	removeProductTranslationUseCase := productUseCase.NewRemoveProductTranslationUseCase(translationService)
+	getCategoryTranslationsUseCase := productUseCase.NewGetCategoryTranslationsUseCase(translationService)
+	setCategoryTranslationUseCase := productUseCase.NewSetCategoryTranslationUseCase(translationService)
+	removeCategoryTranslationUseCase := productUseCase.NewRemoveCategoryTranslationUseCase(translationService)
+
+	// Translate the category names of the storefront reads
+	getProductUseCase.SetTranslations(translationService)
+	listProductsUseCase.SetTranslations(translationService)
+	getProductsByCategoryUseCase.SetTranslations(translationService)
+
+	// Create warehouse and inventory use cases
+	createWarehouseUseCase := productUseCase.NewCreateWarehouseUseCase(inventoryService)
+	getWarehouseUseCase := productUseCase.NewGetWarehouseUseCase(inventoryService)
+	listWarehousesUseCase := productUseCase.NewListWarehousesUseCase(inventoryService)
+	updateWarehouseUseCase := productUseCase.NewUpdateWarehouseUseCase(inventoryService)
+	deleteWarehouseUseCase := productUseCase.NewDeleteWarehouseUseCase(inventoryService)
+	getInventoryUseCase := productUseCase.NewGetInventoryUseCase(productRepo)
+	setStockLevelUseCase := productUseCase.NewSetStockLevelUseCase(inventoryService)
+	transferStockUseCase := productUseCase.NewTransferStockUseCase(inventoryService)
+	allocateStockUseCase := productUseCase.NewAllocateStockUseCase(inventoryService)
+	listStockMovementsUseCase := productUseCase.NewListStockMovementsUseCase(productRepo, store.Movements)
+	recordStockMovementUseCase := productUseCase.NewRecordStockMovementUseCase(inventoryService)
+	reconcileStockUseCase := productUseCase.NewReconcileStockUseCase(productRepo, store.Movements)
+	reconcileStockUseCase.SetTransactor(store.Snapshots)
+	setReorderPolicyUseCase := productUseCase.NewSetReorderPolicyUseCase(productRepo, store.Policies)
+	getReorderPolicyUseCase := productUseCase.NewGetReorderPolicyUseCase(store.Policies)
+	deleteReorderPolicyUseCase := productUseCase.NewDeleteReorderPolicyUseCase(store.Policies)
+	getReorderSuggestionsUseCase := productUseCase.NewGetReorderSuggestionsUseCase(productRepo, store.Policies)
+
+	// Create webhook use cases
+	createSubscriptionUseCase := webhookUseCase.NewCreateSubscriptionUseCase(subscriptionRepo)
+	getSubscriptionUseCase := webhookUseCase.NewGetSubscriptionUseCase(subscriptionRepo)
+	listSubscriptionsUseCase := webhookUseCase.NewListSubscriptionsUseCase(subscriptionRepo)
+	updateSubscriptionUseCase := webhookUseCase.NewUpdateSubscriptionUseCase(subscriptionRepo)
+	deleteSubscriptionUseCase := webhookUseCase.NewDeleteSubscriptionUseCase(subscriptionRepo)
+	listDeliveriesUseCase := webhookUseCase.NewListDeliveriesUseCase(subscriptionRepo, deliveryRepo)
+
+	// Create order use cases
+	placeOrderUseCase := orderUseCase.NewPlaceOrderUseCase(orderService)
+	getOrderUseCase := orderUseCase.NewGetOrderUseCase(store.Orders)
+	listOrdersUseCase := orderUseCase.NewListOrdersUseCase(store.Orders)
+	cancelOrderUseCase := orderUseCase.NewCancelOrderUseCase(orderService)
+	fulfilOrderUseCase := orderUseCase.NewFulfilOrderUseCase(orderService)
+
+	// Create cart use cases
+	getCartUseCase := cartUseCase.NewGetCartUseCase(cartService)
+	addCartItemUseCase := cartUseCase.NewAddCartItemUseCase(cartService)
+	updateCartItemUseCase := cartUseCase.NewUpdateCartItemUseCase(cartService)
+	removeCartItemUseCase := cartUseCase.NewRemoveCartItemUseCase(cartService)
+	clearCartUseCase := cartUseCase.NewClearCartUseCase(cartService)
+	reserveCartUseCase := cartUseCase.NewReserveCartUseCase(cartService)
+	getReservationUseCase := cartUseCase.NewGetReservationUseCase(cartService)
+	releaseReservationUseCase := cartUseCase.NewReleaseReservationUseCase(cartService)
+	purgeExpiredCartsUseCase := cartUseCase.NewPurgeExpiredCartsUseCase(cartService)
+	releaseExpiredReservationsUseCase := cartUseCase.NewReleaseExpiredReservationsUseCase(cartService)
 
 	// Create handlers
-	productHandler := api.NewProductHandler(
+	getProductHandler := handler.NewGetProductHandler(getProductUseCase, listProductsUseCase, getProductsByCategoryUseCase)
+	getProductHandler.Locales = locales
+	createProductHandler := handler.NewCreateProductHandler(createProductUseCase)
+	updateProductHandler := handler.NewUpdateProductHandler(updateProductUseCase, getProductUseCase)
+	patchProductHandler := handler.NewPatchProductHandler(patchProductUseCase)
+	deleteProductHandler := handler.NewDeleteProductHandler(deleteProductUseCase)
+	categoryHandler := handler.NewCategoryHandler(addCategoryToProductUseCase, removeCategoryFromProductUseCase)
+	graphQLHandler, err := gql.NewHandler(
 		createProductUseCase,
 		updateProductUseCase,
 		deleteProductUseCase,
-		getProductByIDUseCase,
+		getProductUseCase,
 		getAllProductsUseCase,
 		addCategoryToProductUseCase,
 		removeCategoryFromProductUseCase,
 		getProductsByCategoryUseCase,
 	)
+	if err != nil {
+		return err
+	}
+	inventoryHandler := handler.NewInventoryHandler(
+		createWarehouseUseCase,
+		getWarehouseUseCase,
+		listWarehousesUseCase,
+		updateWarehouseUseCase,
+		deleteWarehouseUseCase,
+		getInventoryUseCase,
+		setStockLevelUseCase,
+		transferStockUseCase,
+		allocateStockUseCase,
+	)
+	stockMovementHandler := handler.NewStockMovementHandler(listStockMovementsUseCase, recordStockMovementUseCase, reconcileStockUseCase)
+	reorderHandler := handler.NewReorderHandler(
+		setReorderPolicyUseCase,
+		getReorderPolicyUseCase,
+		deleteReorderPolicyUseCase,
+		getReorderSuggestionsUseCase,
+	)
+	attributeHandler := handler.NewAttributeHandler(
+		setAttributeSchemaUseCase,
+		getAttributeSchemaUseCase,
+		deleteAttributeSchemaUseCase,
+		setProductAttributesUseCase,
+	)
+	facetHandler := handler.NewFacetHandler(getProductFacetsUseCase)
+	imageHandler := handler.NewImageHandler(
+		uploadProductImageUseCase,
+		removeProductImageUseCase,
+		reorderProductImagesUseCase,
+		getProductImageUseCase,
+		int64(cfg.Media.MaxImageSize),
+	)
+	translationHandler := handler.NewTranslationHandler(
+		getProductTranslationsUseCase,
+		setProductTranslationUseCase,
+		removeProductTranslationUseCase,
+		getCategoryTranslationsUseCase,
+		setCategoryTranslationUseCase,
+		removeCategoryTranslationUseCase,
+	)
+	lifecycleHandler := handler.NewLifecycleHandler(getProductUseCase, listProductsUseCase, changeProductStatusUseCase, setProductAvailabilityUseCase)
+	streamHandler := sse.NewHandler(broker, getProductUseCase, sse.DefaultHeartbeat)
+	subscriptionHandler := webhookHandler.NewSubscriptionHandler(
+		createSubscriptionUseCase,
+		getSubscriptionUseCase,
+		listSubscriptionsUseCase,
+		updateSubscriptionUseCase,
+		deleteSubscriptionUseCase,
+		listDeliveriesUseCase,
+	)
+	ordersHandler := orderHandler.NewOrderHandler(
+		placeOrderUseCase,
+		getOrderUseCase,
+		listOrdersUseCase,
+		cancelOrderUseCase,
+		fulfilOrderUseCase,
+	)
+	cartsHandler := cartHandler.NewCartHandler(
+		getCartUseCase,
+		addCartItemUseCase,
+		updateCartItemUseCase,
+		removeCartItemUseCase,
+		clearCartUseCase,
+		reserveCartUseCase,
+		getReservationUseCase,
+		releaseReservationUseCase,
+	)
+
+	// Per-client rate limits; the probes and metrics scrapes are exempt
+	middlewares := []func(http.Handler) http.Handler{
+		appTracer.Middleware,
+		logging.Middleware(logger),
+		appMetrics.Middleware,
+	}
+	if cfg.RateLimit.Enabled {
+		// Copied so that the exemptions are not appended into the backing array of the configuration
+		rules := append(append([]ratelimit.Rule(nil), cfg.RateLimit.Routes...),
+			ratelimit.Rule{Route: "/healthz"},
+			ratelimit.Rule{Route: "/readyz"},
+			ratelimit.Rule{Route: "/metrics"},
+		)
+		middlewares = append(middlewares, ratelimit.Middleware(ratelimit.NewMemoryStore(), ratelimit.Config{
+			Read:  cfg.RateLimit.Read,
+			Write: cfg.RateLimit.Write,
+			Rules: rules,
+			Key:   ratelimit.ClientKey(cfg.RateLimit.TrustProxy),
+		}))
+	}
 
 	// Create router
-	mux := http.NewServeMux()
+	router := app.NewRouter(app.Handlers{
+		GetProduct:    getProductHandler,
+		CreateProduct: createProductHandler,
+		UpdateProduct: updateProductHandler,
+		PatchProduct:  patchProductHandler,
+		DeleteProduct: deleteProductHandler,
+		Category:      categoryHandler,
+		GraphQL:       graphQLHandler,
+		Stream:        streamHandler,
+		Inventory:     inventoryHandler,
+		StockMovement: stockMovementHandler,
+		Reorder:       reorderHandler,
+		Attribute:     attributeHandler,
+		Facet:         facetHandler,
+		Image:         imageHandler,
+		Translation:   translationHandler,
+		Lifecycle:     lifecycleHandler,
+		Subscription:  subscriptionHandler,
+		Order:         ordersHandler,
+		Cart:          cartsHandler,
+		Metrics:       appMetrics.Handler(),
+		Health:        health.NewHandler(health.PingCheck("repository", productRepo)),
+	}, middlewares...)
+
+	// Background jobs are stopped and awaited on return, before the repositories are closed
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	var jobs sync.WaitGroup
+	defer func() {
+		stopJobs()
+		jobs.Wait()
+	}()
+	runEvery := func(job func(context.Context, time.Duration), interval time.Duration) {
+		if interval <= 0 {
+			return
+		}
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			job(jobsCtx, interval)
+		}()
+	}
+	// Compare the stock with the ledger periodically
+	runEvery(reconcileStockUseCase.RunEvery, cfg.Inventory.ReconcileInterval)
+	// Delete the expired carts and release the expired reservations periodically
+	runEvery(purgeExpiredCartsUseCase.RunEvery, cfg.Cart.PurgeInterval)
+	runEvery(releaseExpiredReservationsUseCase.RunEvery, cfg.Cart.PurgeInterval)
+	// Announce the availability windows opening and closing
+	runEvery(scheduleAvailabilityUseCase.RunEvery, cfg.Catalog.ScheduleInterval)
+
+	// Serve until a shutdown signal is received
+	server := &http.Server{
+		Addr:              cfg.Server.Addr(),
+		Handler:           router,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+	logger.Info("server running", "addr", server.Addr)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	// A second signal terminates the process immediately
+	stop()
+	logger.Info("shutting down", "timeout", cfg.Server.ShutdownTimeout)
 
-	// Register routes
-	productHandler.RegisterRoutes(mux)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
 
-	// Start server
-	port := 8080
-	fmt.Printf("Server running on port %d...\n", port)
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", port), mux))
+	// Event streams never end by themselves, so they are closed before waiting for in-flight requests
+	broker.Close()
+	var errs []error
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("http server: %w", err))
+	}
+	if err := dispatcher.Stop(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("webhook dispatcher: %w", err))
+	}
+	if err := subscribers.Stop(shutdownCtx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := tracerProvider.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("tracer provider: %w", err))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	logger.Info("server stopped")
+	return nil
 }
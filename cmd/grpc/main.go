@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"google.golang.org/grpc"
+
+	"sago-sample/app"
+	"sago-sample/config"
+	"sago-sample/feature/product/handler/grpcserver"
+	productUseCase "sago-sample/feature/product/usecase"
+	"sago-sample/observability/logging"
+	productv1 "sago-sample/proto/product/v1"
+)
+
+func main() {
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if err := run(cfg); err != nil {
+		slog.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run serves the gRPC API until SIGINT or SIGTERM, then waits for the running calls.
+// The products are stored in the configured backend, set up like the HTTP server's.
+func run(cfg config.Config) (err error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger, err := logging.New(os.Stdout, logging.Config{Format: cfg.Log.Format, Level: cfg.Log.Level})
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	// Create repositories; product lookups by ID are served from an in-process cache
+	store, err := app.NewBackend(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := store.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+	}()
+	productRepo := app.NewProductCache(store.Products)
+
+	// Create domain services
+	// Stock changes are saved with their ledger movements in one transaction
+	productService := store.NewProductService(productRepo)
+
+	// Alert on low and out-of-stock products, and delete the image files of deleted products
+	subscribers, err := store.Subscribe(cfg, productService)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := subscribers.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+	}()
+
+	// Create use cases
+	createProductUseCase := productUseCase.NewCreateProductUseCase(productService)
+	updateProductUseCase := productUseCase.NewUpdateProductUseCase(productService)
+	deleteProductUseCase := productUseCase.NewDeleteProductUseCase(productService)
+	getProductUseCase := productUseCase.NewGetProductUseCase(productRepo)
+	getAllProductsUseCase := productUseCase.NewGetAllProductsUseCase(productRepo)
+
+	// Create category-related use cases
+	addCategoryToProductUseCase := productUseCase.NewAddCategoryToProductUseCase(productService)
+	removeCategoryFromProductUseCase := productUseCase.NewRemoveCategoryFromProductUseCase(productService)
+	getProductsByCategoryUseCase := productUseCase.NewGetProductsByCategoryUseCase(productService)
+
+	// Create gRPC server
+	productServer := grpcserver.NewProductServer(
+		createProductUseCase,
+		updateProductUseCase,
+		deleteProductUseCase,
+		getProductUseCase,
+		getAllProductsUseCase,
+		addCategoryToProductUseCase,
+		removeCategoryFromProductUseCase,
+		getProductsByCategoryUseCase,
+	)
+
+	server := grpc.NewServer()
+	productv1.RegisterProductServiceServer(server, productServer)
+
+	// Serve until a shutdown signal is received
+	lis, err := net.Listen("tcp", cfg.Server.GRPCAddr())
+	if err != nil {
+		return err
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(lis)
+	}()
+	logger.Info("gRPC server running", "addr", lis.Addr().String())
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	// A second signal terminates the process immediately
+	stop()
+	logger.Info("shutting down", "timeout", cfg.Server.ShutdownTimeout)
+
+	// GracefulStop waits for the running calls, including open streams, so it is bounded by the shutdown timeout
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		server.Stop()
+		return fmt.Errorf("grpc server: %w", shutdownCtx.Err())
+	}
+	if err := subscribers.Stop(shutdownCtx); err != nil {
+		return err
+	}
+	logger.Info("server stopped")
+	return nil
+}
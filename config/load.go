@@ -0,0 +1,276 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"sago-sample/ratelimit"
+)
+
+// FileEnv names the environment variable pointing to a configuration file, like the -config flag
+const FileEnv = "CONFIG_FILE"
+
+// setting is a value that can be set from an environment variable and, if flag is not empty, a command-line flag
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+func settings() []setting {
+	return []setting{
+		{"PORT", "port", "port the HTTP server listens on", integer(func(c *Config) *int { return &c.Server.Port })},
+		{"GRPC_PORT", "grpc-port", "port the gRPC server listens on", integer(func(c *Config) *int { return &c.Server.GRPCPort })},
+		{"SERVER_READ_HEADER_TIMEOUT", "", "", duration(func(c *Config) *time.Duration { return &c.Server.ReadHeaderTimeout })},
+		{"SERVER_READ_TIMEOUT", "read-timeout", "maximum duration for reading a request", duration(func(c *Config) *time.Duration { return &c.Server.ReadTimeout })},
+		{"SERVER_WRITE_TIMEOUT", "write-timeout", "maximum duration for writing a response (streams are exempt)", duration(func(c *Config) *time.Duration { return &c.Server.WriteTimeout })},
+		{"SERVER_IDLE_TIMEOUT", "", "", duration(func(c *Config) *time.Duration { return &c.Server.IdleTimeout })},
+		{"SERVER_SHUTDOWN_TIMEOUT", "shutdown-timeout", "maximum duration for draining requests and workers on shutdown", duration(func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout })},
+		{"REPOSITORY_BACKEND", "repository", "product repository backend: memory or postgres", str(func(c *Config) *string { return &c.Repository.Backend })},
+		{"DATABASE_URL", "database-url", "PostgreSQL URL of the postgres backend", str(func(c *Config) *string { return &c.Database.URL })},
+		{"DATABASE_MAX_OPEN_CONNS", "", "", integer(func(c *Config) *int { return &c.Database.MaxOpenConns })},
+		{"DATABASE_MAX_IDLE_CONNS", "", "", integer(func(c *Config) *int { return &c.Database.MaxIdleConns })},
+		{"DATABASE_CONN_MAX_LIFETIME", "", "", duration(func(c *Config) *time.Duration { return &c.Database.ConnMaxLifetime })},
+		{"LOG_FORMAT", "log-format", "log format: json or text", str(func(c *Config) *string { return &c.Log.Format })},
+		{"LOG_LEVEL", "log-level", "minimum log level: debug, info, warn or error", str(func(c *Config) *string { return &c.Log.Level })},
+		{"OTEL_TRACES_EXPORTER", "tracing-exporter", "span exporter: none, stdout or otlp", str(func(c *Config) *string { return &c.Tracing.Exporter })},
+		{"OTEL_SERVICE_NAME", "", "", str(func(c *Config) *string { return &c.Tracing.ServiceName })},
+		{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "", "", str(func(c *Config) *string { return &c.Tracing.OTLPEndpoint })},
+		{"RATE_LIMIT_ENABLED", "rate-limit", "limit the request rate of each client", boolean(func(c *Config) *bool { return &c.RateLimit.Enabled })},
+		{"RATE_LIMIT_READ", "", "", limit(func(c *Config) *ratelimit.Limit { return &c.RateLimit.Read })},
+		{"RATE_LIMIT_WRITE", "", "", limit(func(c *Config) *ratelimit.Limit { return &c.RateLimit.Write })},
+		{"RATE_LIMIT_TRUST_PROXY", "", "", boolean(func(c *Config) *bool { return &c.RateLimit.TrustProxy })},
+		{"INVENTORY_RECONCILE_INTERVAL", "reconcile-interval", "how often stock is reconciled with the ledger, 0 to disable", duration(func(c *Config) *time.Duration { return &c.Inventory.ReconcileInterval })},
+		{"ALERTS_NOTIFIER", "alerts", "low-stock alert notifier: none, log, webhook or file", str(func(c *Config) *string { return &c.Alerts.Notifier })},
+		{"ALERTS_WEBHOOK_URL", "", "", str(func(c *Config) *string { return &c.Alerts.WebhookURL })},
+		{"ALERTS_FILE", "", "", str(func(c *Config) *string { return &c.Alerts.File })},
+		{"ALERTS_COOLDOWN", "", "", duration(func(c *Config) *time.Duration { return &c.Alerts.Cooldown })},
+		{"CART_TTL", "cart-ttl", "how long a cart lives without changes", duration(func(c *Config) *time.Duration { return &c.Cart.TTL })},
+		{"CART_RESERVATION_TTL", "", "", duration(func(c *Config) *time.Duration { return &c.Cart.ReservationTTL })},
+		{"CART_PURGE_INTERVAL", "", "", duration(func(c *Config) *time.Duration { return &c.Cart.PurgeInterval })},
+		{"CATALOG_SCHEDULE_INTERVAL", "", "", duration(func(c *Config) *time.Duration { return &c.Catalog.ScheduleInterval })},
+		{"CATALOG_PRICE_BUCKETS", "", "", amounts(func(c *Config) *[]uint { return &c.Catalog.PriceBuckets })},
+		{"CATALOG_DEFAULT_LOCALE", "default-locale", "locale of the products' own names and descriptions", str(func(c *Config) *string { return &c.Catalog.DefaultLocale })},
+		{"CATALOG_LOCALES", "", "", strs(func(c *Config) *[]string { return &c.Catalog.Locales })},
+		{"MEDIA_DIR", "media-dir", "directory holding the product image files", str(func(c *Config) *string { return &c.Media.Dir })},
+		{"MEDIA_MAX_IMAGE_SIZE", "", "", integer(func(c *Config) *int { return &c.Media.MaxImageSize })},
+		{"MEDIA_THUMBNAIL_SIZE", "", "", integer(func(c *Config) *int { return &c.Media.ThumbnailSize })},
+	}
+}
+
+// Load builds the configuration from the defaults, the file given by -config or CONFIG_FILE,
+// the environment and the command-line flags args, each overriding the previous ones, and validates it.
+// getenv is usually os.Getenv.
+func Load(args []string, getenv func(string) string) (Config, error) {
+	all := settings()
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	file := fs.String("config", getenv(FileEnv), "path to a YAML or TOML configuration file")
+	flags := make(map[string]*string)
+	for _, s := range all {
+		if s.flag != "" {
+			flags[s.flag] = fs.String(s.flag, "", s.usage)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	cfg := Default()
+	if *file != "" {
+		if err := loadFile(*file, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var errs []error
+	if getenv("DATABASE_URL") == "" {
+		if u := composeDatabaseURL(getenv); u != "" {
+			cfg.Database.URL = u
+		}
+	}
+	for _, s := range all {
+		if v := getenv(s.env); v != "" {
+			if err := s.set(&cfg, v); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, s := range all {
+		if s.flag != "" && set[s.flag] {
+			if err := s.set(&cfg, *flags[s.flag]); err != nil {
+				errs = append(errs, fmt.Errorf("-%s: %w", s.flag, err))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// loadFile decodes a YAML or TOML file, chosen by its extension, over cfg; unknown keys are rejected
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%s: unknown keys %v", path, undecoded)
+		}
+	default:
+		return fmt.Errorf("%s: unsupported configuration file extension %q", path, ext)
+	}
+	return nil
+}
+
+// composeDatabaseURL builds the database URL from the DB_HOST, DB_PORT, DB_USER, DB_PASSWORD and DB_NAME variables
+// used by docker-compose.yml, or returns "" when DB_HOST is not set
+func composeDatabaseURL(getenv func(string) string) string {
+	host := getenv("DB_HOST")
+	if host == "" {
+		return ""
+	}
+	if port := getenv("DB_PORT"); port != "" {
+		host += ":" + port
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		Host:     host,
+		Path:     "/" + getenv("DB_NAME"),
+		RawQuery: "sslmode=disable",
+	}
+	if user := getenv("DB_USER"); user != "" {
+		u.User = url.UserPassword(user, getenv("DB_PASSWORD"))
+	}
+	return u.String()
+}
+
+func str(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}
+}
+
+func integer(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", v)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func duration(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", v)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func boolean(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", v)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+// amounts parses a comma-separated list of non-negative integers, e.g. "1000,5000,10000"
+func amounts(field func(*Config) *[]uint) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		var list []uint
+		for _, part := range strings.Split(v, ",") {
+			n, err := strconv.ParseUint(strings.TrimSpace(part), 10, 0)
+			if err != nil {
+				return fmt.Errorf("invalid list of amounts %q", v)
+			}
+			list = append(list, uint(n))
+		}
+		*field(c) = list
+		return nil
+	}
+}
+
+// strs parses a comma-separated list of strings, e.g. "en,fr,pt-BR"
+func strs(field func(*Config) *[]string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		var list []string
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				list = append(list, part)
+			}
+		}
+		*field(c) = list
+		return nil
+	}
+}
+
+// limit parses "<requests>/<period>" or "<requests>/<period>/<burst>", e.g. "60/1m/20"
+func limit(field func(*Config) *ratelimit.Limit) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		parts := strings.Split(v, "/")
+		if len(parts) < 2 || len(parts) > 3 {
+			return fmt.Errorf("invalid limit %q, want <requests>/<period>[/<burst>]", v)
+		}
+		requests, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return fmt.Errorf("invalid limit %q: %w", v, err)
+		}
+		period, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid limit %q: %w", v, err)
+		}
+		l := ratelimit.Limit{Requests: requests, Period: period}
+		if len(parts) == 3 {
+			if l.Burst, err = strconv.Atoi(parts[2]); err != nil {
+				return fmt.Errorf("invalid limit %q: %w", v, err)
+			}
+		}
+		*field(c) = l
+		return nil
+	}
+}
@@ -0,0 +1,266 @@
+// Package cart holds shopping carts of products, checked against the current products whenever they are read,
+// and the stock reservations carts are converted into
+package cart
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	product "sago-sample/feature/product/domain"
+	"sago-sample/internal/textnorm"
+)
+
+// MaxLines is the largest number of distinct products in a cart
+const MaxLines = 100
+
+// MaxOwnerLength is the longest session or user a cart can be keyed by, in characters
+const MaxOwnerLength = 128
+
+var (
+	ErrCartNotFound = errors.New("cart not found")
+	ErrItemNotFound = errors.New("cart item not found")
+	ErrInvalidCart  = errors.New("invalid cart")
+	// ErrCurrencyMismatch is returned when a product priced in another currency than the cart is added
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrCartNeedsReview is returned when a cart with issues is reserved; reading the cart lists the issues
+	ErrCartNeedsReview = errors.New("cart needs review")
+)
+
+// OwnerKind tells whether a cart belongs to an anonymous session or to a signed-in user
+type OwnerKind string
+
+const (
+	OwnerSession OwnerKind = "session"
+	OwnerUser    OwnerKind = "user"
+)
+
+// CartID identifies a cart by its owner, e.g. "user:alice" or "session:3f2a..."
+type CartID string
+
+// NewCartID creates a new CartID for the session or user owner.
+// The owner is normalized to NFC, so a user name typed with combining accents keys the same cart.
+func NewCartID(kind OwnerKind, owner string) (CartID, error) {
+	if kind != OwnerSession && kind != OwnerUser {
+		return "", fmt.Errorf("%w: unknown owner kind %q", ErrInvalidCart, kind)
+	}
+	owner, err := textnorm.Identifier(owner)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s %v", ErrInvalidCart, kind, err)
+	}
+	if owner == "" {
+		return "", fmt.Errorf("%w: a session or user is required", ErrInvalidCart)
+	}
+	if textnorm.Length(owner) > MaxOwnerLength {
+		return "", fmt.Errorf("%w: %s cannot exceed %d characters", ErrInvalidCart, kind, MaxOwnerLength)
+	}
+	return CartID(string(kind) + ":" + owner), nil
+}
+
+// String returns the string representation of the CartID
+func (id CartID) String() string {
+	return string(id)
+}
+
+// Line is a quantity of a product at the price it had when it was last added or updated
+type Line struct {
+	productID product.ProductID
+	quantity  uint
+	price     product.Price
+}
+
+// NewLine creates a new Line
+func NewLine(productID product.ProductID, quantity uint, price product.Price) (Line, error) {
+	if productID == "" {
+		return Line{}, fmt.Errorf("%w: item product ID cannot be empty", ErrInvalidCart)
+	}
+	if quantity == 0 {
+		return Line{}, fmt.Errorf("%w: item quantity must be positive", ErrInvalidCart)
+	}
+	return Line{productID: productID, quantity: quantity, price: price}, nil
+}
+
+// ProductID returns the product in the cart
+func (l Line) ProductID() product.ProductID {
+	return l.productID
+}
+
+// Quantity returns the quantity of the product
+func (l Line) Quantity() uint {
+	return l.quantity
+}
+
+// Price returns the unit price the customer last saw, used to detect reprices
+func (l Line) Price() product.Price {
+	return l.price
+}
+
+// Cart is the shopping cart of a session or user. It expires when it is not changed for its time to live.
+// Changes replace the lines rather than modifying them, so copies of a cart never share changes.
+type Cart struct {
+	id        CartID
+	lines     []Line
+	createdAt time.Time
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+// NewCart creates a new empty Cart expiring after ttl
+func NewCart(id CartID, now time.Time, ttl time.Duration) *Cart {
+	return &Cart{id: id, createdAt: now, updatedAt: now, expiresAt: now.Add(ttl)}
+}
+
+// RestoreCart rebuilds a Cart from persisted state
+func RestoreCart(id CartID, lines []Line, createdAt, updatedAt, expiresAt time.Time) *Cart {
+	return &Cart{
+		id:        id,
+		lines:     append([]Line(nil), lines...),
+		createdAt: createdAt,
+		updatedAt: updatedAt,
+		expiresAt: expiresAt,
+	}
+}
+
+// ID returns the cart ID
+func (c *Cart) ID() CartID {
+	return c.id
+}
+
+// Lines returns the lines of the cart in the order they were added
+func (c *Cart) Lines() []Line {
+	return append([]Line(nil), c.lines...)
+}
+
+// CreatedAt returns when the cart was created
+func (c *Cart) CreatedAt() time.Time {
+	return c.createdAt
+}
+
+// UpdatedAt returns when the cart was last changed
+func (c *Cart) UpdatedAt() time.Time {
+	return c.updatedAt
+}
+
+// ExpiresAt returns when the cart expires unless it changes
+func (c *Cart) ExpiresAt() time.Time {
+	return c.expiresAt
+}
+
+// IsExpired reports whether the cart has expired at now
+func (c *Cart) IsExpired(now time.Time) bool {
+	return !now.Before(c.expiresAt)
+}
+
+// IsEmpty reports whether the cart has no lines
+func (c *Cart) IsEmpty() bool {
+	return len(c.lines) == 0
+}
+
+// Currency returns the currency of the cart, that of its lines, or "" when it is empty
+func (c *Cart) Currency() string {
+	if len(c.lines) == 0 {
+		return ""
+	}
+	return c.lines[0].price.Currency()
+}
+
+// Quantities returns the quantity of each product in the cart
+func (c *Cart) Quantities() map[product.ProductID]uint {
+	quantities := make(map[product.ProductID]uint, len(c.lines))
+	for _, l := range c.lines {
+		quantities[l.productID] += l.quantity
+	}
+	return quantities
+}
+
+// Quantity returns the quantity of a product in the cart, 0 when it is not in the cart
+func (c *Cart) Quantity(productID product.ProductID) uint {
+	if i := c.indexOf(productID); i >= 0 {
+		return c.lines[i].quantity
+	}
+	return 0
+}
+
+// AddItem adds a quantity of a product at its current price, adding to the quantity already in the cart.
+// The price of the line is refreshed, so adding a repriced product acknowledges its new price.
+func (c *Cart) AddItem(productID product.ProductID, quantity uint, price product.Price) error {
+	if i := c.indexOf(productID); i >= 0 {
+		return c.UpdateItem(productID, c.lines[i].quantity+quantity, price)
+	}
+
+	line, err := NewLine(productID, quantity, price)
+	if err != nil {
+		return err
+	}
+	if err := c.checkCurrency(price); err != nil {
+		return err
+	}
+	if len(c.lines) >= MaxLines {
+		return fmt.Errorf("%w: a cart cannot have more than %d items", ErrInvalidCart, MaxLines)
+	}
+	c.lines = append(c.Lines(), line)
+	return nil
+}
+
+// UpdateItem replaces the quantity of a product in the cart and refreshes its price
+func (c *Cart) UpdateItem(productID product.ProductID, quantity uint, price product.Price) error {
+	i := c.indexOf(productID)
+	if i < 0 {
+		return fmt.Errorf("%w: product %s", ErrItemNotFound, productID)
+	}
+	line, err := NewLine(productID, quantity, price)
+	if err != nil {
+		return err
+	}
+	if len(c.lines) > 1 {
+		if err := c.checkCurrency(price); err != nil {
+			return err
+		}
+	}
+
+	lines := c.Lines()
+	lines[i] = line
+	c.lines = lines
+	return nil
+}
+
+// RemoveItem removes a product from the cart
+func (c *Cart) RemoveItem(productID product.ProductID) error {
+	i := c.indexOf(productID)
+	if i < 0 {
+		return fmt.Errorf("%w: product %s", ErrItemNotFound, productID)
+	}
+
+	lines := make([]Line, 0, len(c.lines)-1)
+	lines = append(lines, c.lines[:i]...)
+	c.lines = append(lines, c.lines[i+1:]...)
+	return nil
+}
+
+// Clear removes every line
+func (c *Cart) Clear() {
+	c.lines = nil
+}
+
+// Touch records a change at now and extends the expiry by ttl
+func (c *Cart) Touch(now time.Time, ttl time.Duration) {
+	c.updatedAt = now
+	c.expiresAt = now.Add(ttl)
+}
+
+// checkCurrency rejects a price in another currency than the cart
+func (c *Cart) checkCurrency(price product.Price) error {
+	if currency := c.Currency(); currency != "" && price.Currency() != currency {
+		return fmt.Errorf("%w: the cart is in %s, the product is priced in %s", ErrCurrencyMismatch, currency, price.Currency())
+	}
+	return nil
+}
+
+func (c *Cart) indexOf(productID product.ProductID) int {
+	for i, l := range c.lines {
+		if l.productID == productID {
+			return i
+		}
+	}
+	return -1
+}
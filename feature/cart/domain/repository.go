@@ -0,0 +1,22 @@
+package cart
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	FindByID(ctx context.Context, id CartID) (*Cart, error)
+	Save(ctx context.Context, cart *Cart) error
+	Delete(ctx context.Context, id CartID) error
+	// DeleteExpired deletes the carts expired at now and returns how many were deleted
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+type ReservationRepository interface {
+	FindByID(ctx context.Context, id ReservationID) (*Reservation, error)
+	Save(ctx context.Context, reservation *Reservation) error
+	Delete(ctx context.Context, id ReservationID) error
+	// FindExpired returns the reservations expired at now
+	FindExpired(ctx context.Context, now time.Time) ([]*Reservation, error)
+}
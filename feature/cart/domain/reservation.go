@@ -0,0 +1,119 @@
+package cart
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	product "sago-sample/feature/product/domain"
+)
+
+var ErrReservationNotFound = errors.New("reservation not found")
+
+// ReservationID represents the unique identifier of a reservation
+type ReservationID string
+
+// NewReservationID creates a new ReservationID with validation
+func NewReservationID(id string) (ReservationID, error) {
+	if id == "" {
+		return "", fmt.Errorf("%w: reservation ID cannot be empty", ErrInvalidCart)
+	}
+	return ReservationID(id), nil
+}
+
+// GenerateReservationID returns a random reservation ID
+func GenerateReservationID() ReservationID {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return ReservationID(hex.EncodeToString(b))
+}
+
+// String returns the string representation of the ReservationID
+func (id ReservationID) String() string {
+	return string(id)
+}
+
+// Reservation holds the stock of the lines of a cart, at the prices they had when the cart was reserved, until it expires
+type Reservation struct {
+	id        ReservationID
+	cartID    CartID
+	lines     []Line
+	createdAt time.Time
+	expiresAt time.Time
+}
+
+// NewReservation creates a new Reservation of the lines of a cart, expiring ttl after now
+func NewReservation(id ReservationID, cartID CartID, lines []Line, now time.Time, ttl time.Duration) (*Reservation, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: the cart is empty", ErrInvalidCart)
+	}
+	return &Reservation{id: id, cartID: cartID, lines: append([]Line(nil), lines...), createdAt: now, expiresAt: now.Add(ttl)}, nil
+}
+
+// RestoreReservation rebuilds a Reservation from persisted state
+func RestoreReservation(id ReservationID, cartID CartID, lines []Line, createdAt, expiresAt time.Time) *Reservation {
+	return &Reservation{
+		id:        id,
+		cartID:    cartID,
+		lines:     append([]Line(nil), lines...),
+		createdAt: createdAt,
+		expiresAt: expiresAt,
+	}
+}
+
+// ID returns the reservation ID
+func (r *Reservation) ID() ReservationID {
+	return r.id
+}
+
+// CartID returns the cart the reservation was made from
+func (r *Reservation) CartID() CartID {
+	return r.cartID
+}
+
+// Lines returns the reserved lines
+func (r *Reservation) Lines() []Line {
+	return append([]Line(nil), r.lines...)
+}
+
+// CreatedAt returns when the reservation was made
+func (r *Reservation) CreatedAt() time.Time {
+	return r.createdAt
+}
+
+// ExpiresAt returns when the reservation releases its stock
+func (r *Reservation) ExpiresAt() time.Time {
+	return r.expiresAt
+}
+
+// IsExpired reports whether the reservation is expired at now
+func (r *Reservation) IsExpired(now time.Time) bool {
+	return !now.Before(r.expiresAt)
+}
+
+// Currency returns the currency of the reserved lines
+func (r *Reservation) Currency() string {
+	return r.lines[0].price.Currency()
+}
+
+// Total returns the price of the reserved lines
+func (r *Reservation) Total() uint {
+	var total uint
+	for _, l := range r.lines {
+		total += l.price.Amount() * l.quantity
+	}
+	return total
+}
+
+// Quantities returns the reserved quantity of each product
+func (r *Reservation) Quantities() map[product.ProductID]uint {
+	quantities := make(map[product.ProductID]uint, len(r.lines))
+	for _, l := range r.lines {
+		quantities[l.productID] += l.quantity
+	}
+	return quantities
+}
@@ -0,0 +1,126 @@
+package cart
+
+import (
+	"time"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// IssueType identifies why a cart line needs the customer's attention
+type IssueType string
+
+const (
+	// IssueProductDeleted flags a line whose product no longer exists
+	IssueProductDeleted IssueType = "product_deleted"
+	// IssueProductUnavailable flags a line whose product was unpublished or archived, or is outside its availability window
+	IssueProductUnavailable IssueType = "product_unavailable"
+	// IssuePriceChanged flags a line whose product was repriced since it was added
+	IssuePriceChanged IssueType = "price_changed"
+	// IssueCurrencyChanged flags a line whose product is now priced in another currency than the cart
+	IssueCurrencyChanged IssueType = "currency_changed"
+	// IssueOutOfStock flags a line whose product has no stock left
+	IssueOutOfStock IssueType = "out_of_stock"
+	// IssueInsufficientStock flags a line asking for more than the stock of its product
+	IssueInsufficientStock IssueType = "insufficient_stock"
+)
+
+// IssueTypes lists every issue type
+var IssueTypes = []IssueType{IssueProductDeleted, IssueProductUnavailable, IssuePriceChanged, IssueCurrencyChanged, IssueOutOfStock, IssueInsufficientStock}
+
+// String returns the string representation of the IssueType
+func (t IssueType) String() string {
+	return string(t)
+}
+
+// ReviewedLine is a cart line checked against the current state of its product
+type ReviewedLine struct {
+	Line
+	// Product is the current product, nil when it was deleted
+	Product *product.Product
+	Issues  []IssueType
+}
+
+// CurrentPrice returns the current unit price of the product, or the price of the line when the product was deleted
+func (l ReviewedLine) CurrentPrice() product.Price {
+	if l.Product == nil {
+		return l.price
+	}
+	return l.Product.Price()
+}
+
+// Available returns the current stock of the product, 0 when it was deleted
+func (l ReviewedLine) Available() uint {
+	if l.Product == nil {
+		return 0
+	}
+	return l.Product.Stock().Quantity()
+}
+
+// Counted reports whether the line is part of the total: its product exists, is on sale and is priced in the cart currency
+func (l ReviewedLine) Counted() bool {
+	for _, issue := range l.Issues {
+		if issue == IssueProductDeleted || issue == IssueProductUnavailable || issue == IssueCurrencyChanged {
+			return false
+		}
+	}
+	return true
+}
+
+// Total returns the price of the line at the current price, 0 when it is not counted
+func (l ReviewedLine) Total() uint {
+	if !l.Counted() {
+		return 0
+	}
+	return l.Product.Price().Amount() * l.quantity
+}
+
+// Review is a cart checked against the current products
+type Review struct {
+	Lines []ReviewedLine
+	// Currency is the currency of the cart and its total
+	Currency string
+	// Total is the sum of the counted lines at the current prices
+	Total uint
+}
+
+// Valid reports whether no line has an issue, so that the cart can be reserved
+func (r Review) Valid() bool {
+	for _, l := range r.Lines {
+		if len(l.Issues) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Revalidate checks every line of the cart against the current products, given by ID, at now; a missing product was deleted.
+// The total is computed from the current prices of the lines whose product exists, is available and is still priced in the cart currency.
+func (c *Cart) Revalidate(products map[product.ProductID]*product.Product, now time.Time) Review {
+	review := Review{Lines: make([]ReviewedLine, 0, len(c.lines)), Currency: c.Currency()}
+	for _, l := range c.lines {
+		reviewed := ReviewedLine{Line: l, Product: products[l.productID]}
+
+		if p := reviewed.Product; p == nil {
+			reviewed.Issues = append(reviewed.Issues, IssueProductDeleted)
+		} else if !p.IsAvailableAt(now) {
+			reviewed.Issues = append(reviewed.Issues, IssueProductUnavailable)
+		} else {
+			switch {
+			case p.Price().Currency() != review.Currency:
+				reviewed.Issues = append(reviewed.Issues, IssueCurrencyChanged)
+			case p.Price() != l.price:
+				reviewed.Issues = append(reviewed.Issues, IssuePriceChanged)
+			}
+			switch stock := p.Stock().Quantity(); {
+			case stock == 0:
+				reviewed.Issues = append(reviewed.Issues, IssueOutOfStock)
+			case stock < l.quantity:
+				reviewed.Issues = append(reviewed.Issues, IssueInsufficientStock)
+			}
+		}
+
+		review.Total += reviewed.Total()
+		review.Lines = append(review.Lines, reviewed)
+	}
+	return review
+}
@@ -0,0 +1,305 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	product "sago-sample/feature/product/domain"
+)
+
+const (
+	// DefaultTTL is how long a cart lives without changes by default
+	DefaultTTL = 24 * time.Hour
+	// DefaultReservationTTL is how long a reservation holds its stock by default
+	DefaultReservationTTL = 30 * time.Minute
+)
+
+// Config configures the carts
+type Config struct {
+	// TTL is how long a cart lives without changes; DefaultTTL when not positive
+	TTL time.Duration
+	// ReservationTTL is how long a reservation holds its stock; DefaultReservationTTL when not positive
+	ReservationTTL time.Duration
+	// Clock tells the current time, as the product service's does; product.SystemClock when nil
+	Clock product.Clock
+}
+
+// Service changes carts, checks them against the current products and converts them into stock reservations.
+// Reading a cart that does not exist or expired returns a new empty cart, which is only saved once it changes.
+type Service struct {
+	carts        Repository
+	reservations ReservationRepository
+	products     product.Repository
+	inventory    *product.InventoryService
+	config       Config
+	// mutex serializes the changes, so that concurrent requests of one owner do not lose items
+	mutex sync.Mutex
+}
+
+// NewService creates a new cart service
+func NewService(carts Repository, reservations ReservationRepository, products product.Repository, inventory *product.InventoryService, config Config) *Service {
+	if config.TTL <= 0 {
+		config.TTL = DefaultTTL
+	}
+	if config.ReservationTTL <= 0 {
+		config.ReservationTTL = DefaultReservationTTL
+	}
+	if config.Clock == nil {
+		config.Clock = product.SystemClock
+	}
+	return &Service{carts: carts, reservations: reservations, products: products, inventory: inventory, config: config}
+}
+
+// GetCart returns a cart and its review against the current products
+func (s *Service) GetCart(ctx context.Context, id CartID) (*Cart, Review, error) {
+	cart, err := s.load(ctx, id)
+	if err != nil {
+		return nil, Review{}, err
+	}
+	return s.review(ctx, cart)
+}
+
+// AddItem adds a quantity of a product to a cart at its current price.
+// The quantity in the cart cannot exceed the stock of the product.
+func (s *Service) AddItem(ctx context.Context, id CartID, productID product.ProductID, quantity uint) (*Cart, Review, error) {
+	return s.change(ctx, id, productID, func(cart *Cart, p *product.Product) error {
+		if err := checkStock(p, cart.Quantity(productID)+quantity); err != nil {
+			return err
+		}
+		return cart.AddItem(productID, quantity, p.Price())
+	})
+}
+
+// UpdateItem replaces the quantity of a product in a cart, refreshing its price
+func (s *Service) UpdateItem(ctx context.Context, id CartID, productID product.ProductID, quantity uint) (*Cart, Review, error) {
+	return s.change(ctx, id, productID, func(cart *Cart, p *product.Product) error {
+		if err := checkStock(p, quantity); err != nil {
+			return err
+		}
+		return cart.UpdateItem(productID, quantity, p.Price())
+	})
+}
+
+// RemoveItem removes a product from a cart; the product may have been deleted since it was added
+func (s *Service) RemoveItem(ctx context.Context, id CartID, productID product.ProductID) (*Cart, Review, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cart, err := s.load(ctx, id)
+	if err != nil {
+		return nil, Review{}, err
+	}
+	if err := cart.RemoveItem(productID); err != nil {
+		return nil, Review{}, err
+	}
+	if err := s.save(ctx, cart); err != nil {
+		return nil, Review{}, err
+	}
+	return s.review(ctx, cart)
+}
+
+// ClearCart deletes a cart
+func (s *Service) ClearCart(ctx context.Context, id CartID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.carts.Delete(ctx, id); err != nil && !errors.Is(err, ErrCartNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Reserve converts a cart into a reservation holding the stock of its lines, then empties the cart.
+// A cart whose review has issues returns ErrCartNeedsReview: the customer must first remove the deleted products
+// and update the others, which acknowledges their new prices. Either the stock of every line is reserved or none is.
+func (s *Service) Reserve(ctx context.Context, id CartID) (*Reservation, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cart, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cart.IsEmpty() {
+		return nil, fmt.Errorf("%w: the cart is empty", ErrInvalidCart)
+	}
+	_, review, err := s.review(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+	if !review.Valid() {
+		return nil, fmt.Errorf("%w: some items were deleted, repriced or lack stock", ErrCartNeedsReview)
+	}
+
+	reservation, err := NewReservation(GenerateReservationID(), cart.ID(), cart.Lines(), s.config.Clock.Now(), s.config.ReservationTTL)
+	if err != nil {
+		return nil, err
+	}
+	reason := "reservation " + reservation.ID().String()
+	err = s.inventory.Transaction(ctx, func(ctx context.Context) error {
+		if _, err := s.inventory.TakeStock(ctx, reservation.Quantities(), product.MovementReservation, reason); err != nil {
+			return err
+		}
+		if err := s.reservations.Save(ctx, reservation); err != nil {
+			_, returnErr := s.inventory.ReturnStock(ctx, reservation.Quantities(), product.MovementReturn, reason+" not saved")
+			return errors.Join(err, returnErr)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The stock is held by the reservation now; a cart left behind by a failed delete only expires
+	_ = s.carts.Delete(ctx, cart.ID())
+	return reservation, nil
+}
+
+// GetReservation retrieves a reservation by ID
+func (s *Service) GetReservation(ctx context.Context, id ReservationID) (*Reservation, error) {
+	return s.reservations.FindByID(ctx, id)
+}
+
+// ReleaseReservation returns the stock held by a reservation and deletes it
+func (s *Service) ReleaseReservation(ctx context.Context, id ReservationID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.release(ctx, id, "reservation "+id.String()+" released")
+}
+
+// ReleaseExpiredReservations returns the stock held by the expired reservations, deletes them and returns how many were released.
+// A reservation that cannot be released is kept for the next run; the errors are joined.
+func (s *Service) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	expired, err := s.reservations.FindExpired(ctx, s.config.Clock.Now())
+	if err != nil {
+		return 0, err
+	}
+	released := 0
+	var errs []error
+	for _, reservation := range expired {
+		err := s.release(ctx, reservation.ID(), "reservation "+reservation.ID().String()+" expired")
+		if errors.Is(err, ErrReservationNotFound) {
+			// Released meanwhile, by its owner or another instance
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("release reservation %s: %w", reservation.ID(), err))
+			continue
+		}
+		released++
+	}
+	return released, errors.Join(errs...)
+}
+
+// release returns the stock held by a reservation and deletes it, in one transaction.
+// The reservation is read in that transaction, so that instances releasing it at the same time return its stock once.
+func (s *Service) release(ctx context.Context, id ReservationID, reason string) error {
+	return s.inventory.Transaction(ctx, func(ctx context.Context) error {
+		reservation, err := s.reservations.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if _, err := s.inventory.ReturnStock(ctx, reservation.Quantities(), product.MovementReturn, reason); err != nil {
+			return err
+		}
+		if err := s.reservations.Delete(ctx, reservation.ID()); err != nil {
+			_, takeErr := s.inventory.TakeStock(ctx, reservation.Quantities(), product.MovementReservation, reason+" not deleted")
+			return errors.Join(err, takeErr)
+		}
+		return nil
+	})
+}
+
+// PurgeExpired deletes the expired carts and returns how many were deleted
+func (s *Service) PurgeExpired(ctx context.Context) (int, error) {
+	return s.carts.DeleteExpired(ctx, s.config.Clock.Now())
+}
+
+// change applies fn to a cart with the current state of a product, then saves the cart.
+// Only published products can be added or updated.
+func (s *Service) change(ctx context.Context, id CartID, productID product.ProductID, fn func(cart *Cart, p *product.Product) error) (*Cart, Review, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cart, err := s.load(ctx, id)
+	if err != nil {
+		return nil, Review{}, err
+	}
+	p, err := s.products.FindByID(ctx, productID)
+	if err != nil {
+		return nil, Review{}, err
+	}
+	if err := p.CheckAvailableAt(s.config.Clock.Now()); err != nil {
+		return nil, Review{}, err
+	}
+	if err := fn(cart, p); err != nil {
+		return nil, Review{}, err
+	}
+	if err := s.save(ctx, cart); err != nil {
+		return nil, Review{}, err
+	}
+	return s.review(ctx, cart)
+}
+
+// load returns the cart of id, or a new empty cart when it does not exist or expired
+func (s *Service) load(ctx context.Context, id CartID) (*Cart, error) {
+	now := s.config.Clock.Now()
+
+	cart, err := s.carts.FindByID(ctx, id)
+	if errors.Is(err, ErrCartNotFound) {
+		return NewCart(id, now, s.config.TTL), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cart.IsExpired(now) {
+		if err := s.carts.Delete(ctx, id); err != nil && !errors.Is(err, ErrCartNotFound) {
+			return nil, err
+		}
+		return NewCart(id, now, s.config.TTL), nil
+	}
+	return cart, nil
+}
+
+// save extends the expiry of a cart and saves it; an emptied cart is deleted instead
+func (s *Service) save(ctx context.Context, cart *Cart) error {
+	cart.Touch(s.config.Clock.Now(), s.config.TTL)
+	if cart.IsEmpty() {
+		if err := s.carts.Delete(ctx, cart.ID()); err != nil && !errors.Is(err, ErrCartNotFound) {
+			return err
+		}
+		return nil
+	}
+	return s.carts.Save(ctx, cart)
+}
+
+// review checks a cart against the current state of its products
+func (s *Service) review(ctx context.Context, cart *Cart) (*Cart, Review, error) {
+	products := make(map[product.ProductID]*product.Product, len(cart.lines))
+	for _, l := range cart.lines {
+		p, err := s.products.FindByID(ctx, l.productID)
+		if errors.Is(err, product.ErrProductNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, Review{}, err
+		}
+		products[l.productID] = p
+	}
+	return cart, cart.Revalidate(products, s.config.Clock.Now()), nil
+}
+
+// checkStock rejects a quantity above the stock of a product
+func checkStock(p *product.Product, quantity uint) error {
+	if stock := p.Stock().Quantity(); quantity > stock {
+		return fmt.Errorf("%w: product %s has %d", product.ErrInsufficientStock, p.ID(), stock)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	cart "sago-sample/feature/cart/usecase"
+	"sago-sample/principal"
+)
+
+// SessionHeader is the request header identifying the cart of an anonymous session
+const SessionHeader = "X-Session-ID"
+
+// AddCartItemRequest represents the request body for adding a product to the cart
+type AddCartItemRequest struct {
+	ProductID string `json:"productId"`
+	Quantity  uint   `json:"quantity"`
+}
+
+// UpdateCartItemRequest represents the request body for changing the quantity of a product in the cart
+type UpdateCartItemRequest struct {
+	Quantity uint `json:"quantity"`
+}
+
+// CartLineResponse represents a line of the cart in the response
+type CartLineResponse struct {
+	ProductID    string   `json:"productId"`
+	ProductName  string   `json:"productName,omitempty"`
+	Quantity     uint     `json:"quantity"`
+	AddedPrice   uint     `json:"addedPrice"`
+	CurrentPrice uint     `json:"currentPrice"`
+	Currency     string   `json:"currency"`
+	Available    uint     `json:"available"`
+	Total        uint     `json:"total"`
+	Issues       []string `json:"issues"`
+}
+
+// CartResponse represents the cart in the response
+type CartResponse struct {
+	ID        string             `json:"id"`
+	Lines     []CartLineResponse `json:"lines"`
+	Currency  string             `json:"currency,omitempty"`
+	Total     uint               `json:"total"`
+	Valid     bool               `json:"valid"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+}
+
+// ReservationLineResponse represents a reserved quantity of a product in the response
+type ReservationLineResponse struct {
+	ProductID string `json:"productId"`
+	Quantity  uint   `json:"quantity"`
+	UnitPrice uint   `json:"unitPrice"`
+	Total     uint   `json:"total"`
+}
+
+// ReservationResponse represents a stock reservation in the response
+type ReservationResponse struct {
+	ID        string                    `json:"id"`
+	CartID    string                    `json:"cartId"`
+	Lines     []ReservationLineResponse `json:"lines"`
+	Currency  string                    `json:"currency"`
+	Total     uint                      `json:"total"`
+	CreatedAt time.Time                 `json:"createdAt"`
+	ExpiresAt time.Time                 `json:"expiresAt"`
+}
+
+// CartHandler handles the cart of the caller and the reservations made from carts
+type CartHandler struct {
+	GetUseCase                *cart.GetCartUseCase
+	AddItemUseCase            *cart.AddCartItemUseCase
+	UpdateItemUseCase         *cart.UpdateCartItemUseCase
+	RemoveItemUseCase         *cart.RemoveCartItemUseCase
+	ClearUseCase              *cart.ClearCartUseCase
+	ReserveUseCase            *cart.ReserveCartUseCase
+	GetReservationUseCase     *cart.GetReservationUseCase
+	ReleaseReservationUseCase *cart.ReleaseReservationUseCase
+}
+
+func NewCartHandler(
+	getUc *cart.GetCartUseCase,
+	addItemUc *cart.AddCartItemUseCase,
+	updateItemUc *cart.UpdateCartItemUseCase,
+	removeItemUc *cart.RemoveCartItemUseCase,
+	clearUc *cart.ClearCartUseCase,
+	reserveUc *cart.ReserveCartUseCase,
+	getReservationUc *cart.GetReservationUseCase,
+	releaseReservationUc *cart.ReleaseReservationUseCase,
+) *CartHandler {
+	return &CartHandler{
+		GetUseCase:                getUc,
+		AddItemUseCase:            addItemUc,
+		UpdateItemUseCase:         updateItemUc,
+		RemoveItemUseCase:         removeItemUc,
+		ClearUseCase:              clearUc,
+		ReserveUseCase:            reserveUc,
+		GetReservationUseCase:     getReservationUc,
+		ReleaseReservationUseCase: releaseReservationUc,
+	}
+}
+
+// Register adds the cart and reservation routes to rtr
+func (h *CartHandler) Register(rtr chi.Router) {
+	rtr.Get("/api/cart", h.HandleGet)                                // GET    /api/cart
+	rtr.Delete("/api/cart", h.HandleClear)                           // DELETE /api/cart
+	rtr.Post("/api/cart/items", h.HandleAddItem)                     // POST   /api/cart/items
+	rtr.Put("/api/cart/items/{productId}", h.HandleUpdateItem)       // PUT    /api/cart/items/{productId}
+	rtr.Delete("/api/cart/items/{productId}", h.HandleRemoveItem)    // DELETE /api/cart/items/{productId}
+	rtr.Post("/api/cart/reservations", h.HandleReserve)              // POST   /api/cart/reservations
+	rtr.Get("/api/reservations/{id}", h.HandleGetReservation)        // GET    /api/reservations/{id}
+	rtr.Delete("/api/reservations/{id}", h.HandleReleaseReservation) // DELETE /api/reservations/{id}
+}
+
+// HandleGet handles reading the cart; every line is checked against its product and flagged when it was deleted,
+// repriced or lacks stock
+func (h *CartHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	output, err := h.GetUseCase.Execute(r.Context(), ownerOf(r))
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toCartResponse(output))
+}
+
+// HandleClear handles emptying the cart
+func (h *CartHandler) HandleClear(w http.ResponseWriter, r *http.Request) {
+	if err := h.ClearUseCase.Execute(r.Context(), ownerOf(r)); err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleAddItem handles adding a quantity of a product to the cart
+func (h *CartHandler) HandleAddItem(w http.ResponseWriter, r *http.Request) {
+	var req AddCartItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	output, err := h.AddItemUseCase.Execute(r.Context(), cart.CartItemInput{
+		Owner:     ownerOf(r),
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toCartResponse(output))
+}
+
+// HandleUpdateItem handles replacing the quantity of a product in the cart, which also accepts its current price
+func (h *CartHandler) HandleUpdateItem(w http.ResponseWriter, r *http.Request) {
+	var req UpdateCartItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	output, err := h.UpdateItemUseCase.Execute(r.Context(), cart.CartItemInput{
+		Owner:     ownerOf(r),
+		ProductID: chi.URLParam(r, "productId"),
+		Quantity:  req.Quantity,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toCartResponse(output))
+}
+
+// HandleRemoveItem handles removing a product from the cart
+func (h *CartHandler) HandleRemoveItem(w http.ResponseWriter, r *http.Request) {
+	output, err := h.RemoveItemUseCase.Execute(r.Context(), ownerOf(r), chi.URLParam(r, "productId"))
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toCartResponse(output))
+}
+
+// HandleReserve handles converting the cart into a reservation of the stock of its lines
+func (h *CartHandler) HandleReserve(w http.ResponseWriter, r *http.Request) {
+	output, err := h.ReserveUseCase.Execute(r.Context(), ownerOf(r))
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toReservationResponse(output))
+}
+
+// HandleGetReservation handles getting a reservation by ID
+func (h *CartHandler) HandleGetReservation(w http.ResponseWriter, r *http.Request) {
+	output, err := h.GetReservationUseCase.Execute(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toReservationResponse(output))
+}
+
+// HandleReleaseReservation handles releasing a reservation, which returns its stock
+func (h *CartHandler) HandleReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	if err := h.ReleaseReservationUseCase.Execute(r.Context(), chi.URLParam(r, "id")); err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ownerOf returns whose cart a request uses: the authenticated principal, or the session of SessionHeader
+func ownerOf(r *http.Request) cart.Owner {
+	user, _ := principal.FromContext(r.Context())
+	return cart.Owner{User: user, Session: r.Header.Get(SessionHeader)}
+}
+
+// toCartResponse maps the output of a use case to the response
+func toCartResponse(o *cart.CartOutput) CartResponse {
+	lines := make([]CartLineResponse, 0, len(o.Lines))
+	for _, l := range o.Lines {
+		lines = append(lines, CartLineResponse{
+			ProductID:    l.ProductID,
+			ProductName:  l.ProductName,
+			Quantity:     l.Quantity,
+			AddedPrice:   l.AddedPrice,
+			CurrentPrice: l.CurrentPrice,
+			Currency:     l.Currency,
+			Available:    l.Available,
+			Total:        l.Total,
+			Issues:       l.Issues,
+		})
+	}
+	return CartResponse{
+		ID:        o.ID,
+		Lines:     lines,
+		Currency:  o.Currency,
+		Total:     o.Total,
+		Valid:     o.Valid,
+		ExpiresAt: o.ExpiresAt,
+	}
+}
+
+// toReservationResponse maps the output of a use case to the response
+func toReservationResponse(o *cart.ReservationOutput) ReservationResponse {
+	lines := make([]ReservationLineResponse, 0, len(o.Lines))
+	for _, l := range o.Lines {
+		lines = append(lines, ReservationLineResponse{
+			ProductID: l.ProductID,
+			Quantity:  l.Quantity,
+			UnitPrice: l.UnitPrice,
+			Total:     l.Total,
+		})
+	}
+	return ReservationResponse{
+		ID:        o.ID,
+		CartID:    o.CartID,
+		Lines:     lines,
+		Currency:  o.Currency,
+		Total:     o.Total,
+		CreatedAt: o.CreatedAt,
+		ExpiresAt: o.ExpiresAt,
+	}
+}
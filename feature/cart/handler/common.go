@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	domain "sago-sample/feature/cart/domain"
+	product "sago-sample/feature/product/domain"
+)
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// statusFromError returns the HTTP status code for an error returned by a use case
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrCartNotFound), errors.Is(err, domain.ErrItemNotFound), errors.Is(err, domain.ErrReservationNotFound),
+		errors.Is(err, product.ErrProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrCurrencyMismatch), errors.Is(err, domain.ErrCartNeedsReview), errors.Is(err, product.ErrInsufficientStock),
+		errors.Is(err, product.ErrProductNotPublished), errors.Is(err, product.ErrProductUnavailable):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidCart), product.IsValidationError(err):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondWithUseCaseError returns an error response for an error returned by a use case
+func respondWithUseCaseError(w http.ResponseWriter, err error) {
+	respondWithError(w, statusFromError(err), err.Error())
+}
+
+// respondWithError returns an error response
+func respondWithError(w http.ResponseWriter, code int, message string) {
+	respondWithJSON(w, code, ErrorResponse{Error: message})
+}
+
+// respondWithJSON returns a JSON response
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
@@ -0,0 +1,132 @@
+// Package infrastructure stores carts and reservations in memory; carts are short-lived and expire by themselves
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	cart "sago-sample/feature/cart/domain"
+)
+
+// CartRepository is an in-memory implementation of the cart.Repository interface.
+// Carts are copied on the way in and out, so a change is only visible once saved.
+type CartRepository struct {
+	carts map[cart.CartID]cart.Cart
+	mutex sync.RWMutex
+}
+
+// NewCartRepository creates a new in-memory cart repository
+func NewCartRepository() *CartRepository {
+	return &CartRepository{
+		carts: make(map[cart.CartID]cart.Cart),
+	}
+}
+
+// FindByID finds a cart by its ID
+func (r *CartRepository) FindByID(ctx context.Context, id cart.CartID) (*cart.Cart, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	c, exists := r.carts[id]
+	if !exists {
+		return nil, cart.ErrCartNotFound
+	}
+	return &c, nil
+}
+
+// Save persists a cart
+func (r *CartRepository) Save(ctx context.Context, c *cart.Cart) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.carts[c.ID()] = *c
+	return nil
+}
+
+// Delete removes a cart
+func (r *CartRepository) Delete(ctx context.Context, id cart.CartID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.carts[id]; !exists {
+		return cart.ErrCartNotFound
+	}
+	delete(r.carts, id)
+	return nil
+}
+
+// DeleteExpired deletes the carts expired at now
+func (r *CartRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	deleted := 0
+	for id, c := range r.carts {
+		if c.IsExpired(now) {
+			delete(r.carts, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ReservationRepository is an in-memory implementation of the cart.ReservationRepository interface
+type ReservationRepository struct {
+	reservations map[cart.ReservationID]cart.Reservation
+	mutex        sync.RWMutex
+}
+
+// NewReservationRepository creates a new in-memory reservation repository
+func NewReservationRepository() *ReservationRepository {
+	return &ReservationRepository{
+		reservations: make(map[cart.ReservationID]cart.Reservation),
+	}
+}
+
+// FindByID finds a reservation by its ID
+func (r *ReservationRepository) FindByID(ctx context.Context, id cart.ReservationID) (*cart.Reservation, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	reservation, exists := r.reservations[id]
+	if !exists {
+		return nil, cart.ErrReservationNotFound
+	}
+	return &reservation, nil
+}
+
+// Save persists a reservation
+func (r *ReservationRepository) Save(ctx context.Context, reservation *cart.Reservation) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.reservations[reservation.ID()] = *reservation
+	return nil
+}
+
+// Delete removes a reservation
+func (r *ReservationRepository) Delete(ctx context.Context, id cart.ReservationID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.reservations[id]; !exists {
+		return cart.ErrReservationNotFound
+	}
+	delete(r.reservations, id)
+	return nil
+}
+
+// FindExpired returns the reservations expired at now
+func (r *ReservationRepository) FindExpired(ctx context.Context, now time.Time) ([]*cart.Reservation, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var expired []*cart.Reservation
+	for _, reservation := range r.reservations {
+		if reservation.IsExpired(now) {
+			expired = append(expired, &reservation)
+		}
+	}
+	return expired, nil
+}
@@ -0,0 +1,280 @@
+// Package postgres stores carts and reservations in PostgreSQL through gorm, using the schema in /migrations.
+// Both join the transactions of the product service, so a reservation is saved or deleted with the stock it holds.
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	cart "sago-sample/feature/cart/domain"
+	product "sago-sample/feature/product/domain"
+	productPostgres "sago-sample/feature/product/infrastructure/postgres"
+)
+
+// cartRow is a row of the carts table
+type cartRow struct {
+	ID        string    `gorm:"column:id;primaryKey"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime:false"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime:false"`
+	ExpiresAt time.Time `gorm:"column:expires_at"`
+}
+
+func (cartRow) TableName() string { return "carts" }
+
+// cartLineRow is a row of the cart_lines table; position keeps the order of the lines
+type cartLineRow struct {
+	CartID    string `gorm:"column:cart_id;primaryKey"`
+	Position  int    `gorm:"column:position;primaryKey"`
+	ProductID string `gorm:"column:product_id"`
+	Quantity  uint   `gorm:"column:quantity"`
+	UnitPrice uint   `gorm:"column:unit_price"`
+	Currency  string `gorm:"column:currency"`
+}
+
+func (cartLineRow) TableName() string { return "cart_lines" }
+
+// reservationRow is a row of the reservations table
+type reservationRow struct {
+	ID        string    `gorm:"column:id;primaryKey"`
+	CartID    string    `gorm:"column:cart_id"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime:false"`
+	ExpiresAt time.Time `gorm:"column:expires_at"`
+}
+
+func (reservationRow) TableName() string { return "reservations" }
+
+// reservationLineRow is a row of the reservation_lines table; position keeps the order of the lines
+type reservationLineRow struct {
+	ReservationID string `gorm:"column:reservation_id;primaryKey"`
+	Position      int    `gorm:"column:position;primaryKey"`
+	ProductID     string `gorm:"column:product_id"`
+	Quantity      uint   `gorm:"column:quantity"`
+	UnitPrice     uint   `gorm:"column:unit_price"`
+	Currency      string `gorm:"column:currency"`
+}
+
+func (reservationLineRow) TableName() string { return "reservation_lines" }
+
+// CartRepository is a PostgreSQL implementation of the cart.Repository interface
+type CartRepository struct {
+	db *gorm.DB
+}
+
+// NewCartRepository creates a new PostgreSQL cart repository
+func NewCartRepository(db *gorm.DB) *CartRepository {
+	return &CartRepository{db: db}
+}
+
+// FindByID finds a cart by its ID
+func (r *CartRepository) FindByID(ctx context.Context, id cart.CartID) (*cart.Cart, error) {
+	var row cartRow
+	err := productPostgres.Conn(ctx, r.db).Where("id = ?", id.String()).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, cart.ErrCartNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lineRows []cartLineRow
+	if err := productPostgres.Conn(ctx, r.db).Where("cart_id = ?", row.ID).Order("position").Find(&lineRows).Error; err != nil {
+		return nil, err
+	}
+	lines := make([]cart.Line, 0, len(lineRows))
+	for _, l := range lineRows {
+		line, err := restoreLine(l.ProductID, l.Quantity, l.UnitPrice, l.Currency)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return cart.RestoreCart(cart.CartID(row.ID), lines, row.CreatedAt, row.UpdatedAt, row.ExpiresAt), nil
+}
+
+// Save persists a cart, replacing its lines
+func (r *CartRepository) Save(ctx context.Context, c *cart.Cart) error {
+	row := cartRow{
+		ID:        c.ID().String(),
+		CreatedAt: c.CreatedAt().UTC(),
+		UpdatedAt: c.UpdatedAt().UTC(),
+		ExpiresAt: c.ExpiresAt().UTC(),
+	}
+
+	return productPostgres.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"updated_at", "expires_at"}),
+		}).Create(&row)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := tx.Where("cart_id = ?", row.ID).Delete(&cartLineRow{}).Error; err != nil {
+			return err
+		}
+		if len(c.Lines()) == 0 {
+			return nil
+		}
+		lines := make([]cartLineRow, 0, len(c.Lines()))
+		for i, l := range c.Lines() {
+			lines = append(lines, cartLineRow{
+				CartID:    row.ID,
+				Position:  i,
+				ProductID: l.ProductID().String(),
+				Quantity:  l.Quantity(),
+				UnitPrice: l.Price().Amount(),
+				Currency:  l.Price().Currency(),
+			})
+		}
+		return tx.Create(&lines).Error
+	})
+}
+
+// Delete removes a cart with its lines
+func (r *CartRepository) Delete(ctx context.Context, id cart.CartID) error {
+	result := productPostgres.Conn(ctx, r.db).Where("id = ?", id.String()).Delete(&cartRow{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cart.ErrCartNotFound
+	}
+	return nil
+}
+
+// DeleteExpired deletes the carts expired at now
+func (r *CartRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	result := productPostgres.Conn(ctx, r.db).Where("expires_at <= ?", now.UTC()).Delete(&cartRow{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+// ReservationRepository is a PostgreSQL implementation of the cart.ReservationRepository interface
+type ReservationRepository struct {
+	db *gorm.DB
+}
+
+// NewReservationRepository creates a new PostgreSQL reservation repository
+func NewReservationRepository(db *gorm.DB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+// FindByID finds a reservation by its ID.
+// Inside a transaction the reservation row stays locked until it ends, so that other instances cannot release it meanwhile.
+func (r *ReservationRepository) FindByID(ctx context.Context, id cart.ReservationID) (*cart.Reservation, error) {
+	var row reservationRow
+	err := productPostgres.ConnForUpdate(ctx, r.db).Where("id = ?", id.String()).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, cart.ErrReservationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reservations, err := r.restore(ctx, []reservationRow{row})
+	if err != nil {
+		return nil, err
+	}
+	return reservations[0], nil
+}
+
+// Save persists a reservation with its lines; reservations never change once made
+func (r *ReservationRepository) Save(ctx context.Context, reservation *cart.Reservation) error {
+	row := reservationRow{
+		ID:        reservation.ID().String(),
+		CartID:    reservation.CartID().String(),
+		CreatedAt: reservation.CreatedAt().UTC(),
+		ExpiresAt: reservation.ExpiresAt().UTC(),
+	}
+
+	return productPostgres.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+
+		lines := make([]reservationLineRow, 0, len(reservation.Lines()))
+		for i, l := range reservation.Lines() {
+			lines = append(lines, reservationLineRow{
+				ReservationID: row.ID,
+				Position:      i,
+				ProductID:     l.ProductID().String(),
+				Quantity:      l.Quantity(),
+				UnitPrice:     l.Price().Amount(),
+				Currency:      l.Price().Currency(),
+			})
+		}
+		return tx.Create(&lines).Error
+	})
+}
+
+// Delete removes a reservation with its lines
+func (r *ReservationRepository) Delete(ctx context.Context, id cart.ReservationID) error {
+	result := productPostgres.Conn(ctx, r.db).Where("id = ?", id.String()).Delete(&reservationRow{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cart.ErrReservationNotFound
+	}
+	return nil
+}
+
+// FindExpired returns the reservations expired at now, oldest first
+func (r *ReservationRepository) FindExpired(ctx context.Context, now time.Time) ([]*cart.Reservation, error) {
+	var rows []reservationRow
+	if err := productPostgres.Conn(ctx, r.db).Where("expires_at <= ?", now.UTC()).Order("expires_at, id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return r.restore(ctx, rows)
+}
+
+// restore rebuilds the reservations of rows, loading their lines with one query
+func (r *ReservationRepository) restore(ctx context.Context, rows []reservationRow) ([]*cart.Reservation, error) {
+	reservations := make([]*cart.Reservation, 0, len(rows))
+	if len(rows) == 0 {
+		return reservations, nil
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	var lineRows []reservationLineRow
+	if err := productPostgres.Conn(ctx, r.db).Where("reservation_id IN ?", ids).Order("reservation_id, position").Find(&lineRows).Error; err != nil {
+		return nil, err
+	}
+	lines := make(map[string][]cart.Line, len(rows))
+	for _, l := range lineRows {
+		line, err := restoreLine(l.ProductID, l.Quantity, l.UnitPrice, l.Currency)
+		if err != nil {
+			return nil, err
+		}
+		lines[l.ReservationID] = append(lines[l.ReservationID], line)
+	}
+
+	for _, row := range rows {
+		reservations = append(reservations, cart.RestoreReservation(
+			cart.ReservationID(row.ID), cart.CartID(row.CartID), lines[row.ID], row.CreatedAt, row.ExpiresAt,
+		))
+	}
+	return reservations, nil
+}
+
+// restoreLine rebuilds a line of a cart or reservation from its columns
+func restoreLine(productID string, quantity, unitPrice uint, currency string) (cart.Line, error) {
+	id, err := product.NewProductID(productID)
+	if err != nil {
+		return cart.Line{}, err
+	}
+	price, err := product.NewPrice(unitPrice, currency)
+	if err != nil {
+		return cart.Line{}, err
+	}
+	return cart.NewLine(id, quantity, price)
+}
@@ -0,0 +1,260 @@
+package cart
+
+import (
+	"context"
+	"time"
+
+	domain "sago-sample/feature/cart/domain"
+	product "sago-sample/feature/product/domain"
+	"sago-sample/observability/logging"
+)
+
+// Owner identifies whose cart to use: the user when signed in, the session otherwise
+type Owner struct {
+	User    string
+	Session string
+}
+
+// cartID returns the ID of the cart of the owner
+func (o Owner) cartID() (domain.CartID, error) {
+	if o.User != "" {
+		return domain.NewCartID(domain.OwnerUser, o.User)
+	}
+	return domain.NewCartID(domain.OwnerSession, o.Session)
+}
+
+// CartLineOutput represents a line of a cart checked against its product
+type CartLineOutput struct {
+	ProductID string
+	// ProductName is empty when the product was deleted
+	ProductName string
+	Quantity    uint
+	// AddedPrice is the unit price when the line was last added or updated
+	AddedPrice uint
+	// CurrentPrice is the unit price now, or AddedPrice when the product was deleted
+	CurrentPrice uint
+	Currency     string
+	Available    uint
+	// Total is the line at the current price, 0 when the line is not counted
+	Total  uint
+	Issues []string
+}
+
+// CartOutput represents a cart checked against the current products
+type CartOutput struct {
+	ID       string
+	Lines    []CartLineOutput
+	Currency string
+	Total    uint
+	// Valid is false when a line has issues; such a cart cannot be reserved
+	Valid     bool
+	ExpiresAt time.Time
+}
+
+// toCartOutput maps a reviewed cart to its output
+func toCartOutput(c *domain.Cart, review domain.Review) *CartOutput {
+	lines := make([]CartLineOutput, 0, len(review.Lines))
+	for _, l := range review.Lines {
+		line := CartLineOutput{
+			ProductID:    l.ProductID().String(),
+			Quantity:     l.Quantity(),
+			AddedPrice:   l.Price().Amount(),
+			CurrentPrice: l.CurrentPrice().Amount(),
+			Currency:     l.CurrentPrice().Currency(),
+			Available:    l.Available(),
+			Total:        l.Total(),
+			Issues:       make([]string, 0, len(l.Issues)),
+		}
+		if l.Product != nil {
+			line.ProductName = l.Product.Name().String()
+		}
+		for _, issue := range l.Issues {
+			line.Issues = append(line.Issues, issue.String())
+		}
+		lines = append(lines, line)
+	}
+	return &CartOutput{
+		ID:        c.ID().String(),
+		Lines:     lines,
+		Currency:  review.Currency,
+		Total:     review.Total,
+		Valid:     review.Valid(),
+		ExpiresAt: c.ExpiresAt(),
+	}
+}
+
+// GetCartUseCase defines the use case for reading a cart, revalidated against the current products
+type GetCartUseCase struct {
+	service *domain.Service
+}
+
+// NewGetCartUseCase creates a new instance of GetCartUseCase
+func NewGetCartUseCase(service *domain.Service) *GetCartUseCase {
+	return &GetCartUseCase{service: service}
+}
+
+// Execute runs the use case
+func (uc *GetCartUseCase) Execute(ctx context.Context, owner Owner) (*CartOutput, error) {
+	id, err := owner.cartID()
+	if err != nil {
+		return nil, err
+	}
+
+	c, review, err := uc.service.GetCart(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toCartOutput(c, review), nil
+}
+
+// CartItemInput represents the input data for adding or updating an item of a cart
+type CartItemInput struct {
+	Owner     Owner
+	ProductID string
+	Quantity  uint
+}
+
+// AddCartItemUseCase defines the use case for adding a product to a cart
+type AddCartItemUseCase struct {
+	service *domain.Service
+}
+
+// NewAddCartItemUseCase creates a new instance of AddCartItemUseCase
+func NewAddCartItemUseCase(service *domain.Service) *AddCartItemUseCase {
+	return &AddCartItemUseCase{service: service}
+}
+
+// Execute runs the use case
+func (uc *AddCartItemUseCase) Execute(ctx context.Context, input CartItemInput) (*CartOutput, error) {
+	id, productID, err := parseItem(input.Owner, input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	c, review, err := uc.service.AddItem(ctx, id, productID, input.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	return toCartOutput(c, review), nil
+}
+
+// UpdateCartItemUseCase defines the use case for changing the quantity of a product in a cart
+type UpdateCartItemUseCase struct {
+	service *domain.Service
+}
+
+// NewUpdateCartItemUseCase creates a new instance of UpdateCartItemUseCase
+func NewUpdateCartItemUseCase(service *domain.Service) *UpdateCartItemUseCase {
+	return &UpdateCartItemUseCase{service: service}
+}
+
+// Execute runs the use case
+func (uc *UpdateCartItemUseCase) Execute(ctx context.Context, input CartItemInput) (*CartOutput, error) {
+	id, productID, err := parseItem(input.Owner, input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	c, review, err := uc.service.UpdateItem(ctx, id, productID, input.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	return toCartOutput(c, review), nil
+}
+
+// RemoveCartItemUseCase defines the use case for removing a product from a cart
+type RemoveCartItemUseCase struct {
+	service *domain.Service
+}
+
+// NewRemoveCartItemUseCase creates a new instance of RemoveCartItemUseCase
+func NewRemoveCartItemUseCase(service *domain.Service) *RemoveCartItemUseCase {
+	return &RemoveCartItemUseCase{service: service}
+}
+
+// Execute runs the use case
+func (uc *RemoveCartItemUseCase) Execute(ctx context.Context, owner Owner, productID string) (*CartOutput, error) {
+	id, pid, err := parseItem(owner, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	c, review, err := uc.service.RemoveItem(ctx, id, pid)
+	if err != nil {
+		return nil, err
+	}
+	return toCartOutput(c, review), nil
+}
+
+// ClearCartUseCase defines the use case for emptying a cart
+type ClearCartUseCase struct {
+	service *domain.Service
+}
+
+// NewClearCartUseCase creates a new instance of ClearCartUseCase
+func NewClearCartUseCase(service *domain.Service) *ClearCartUseCase {
+	return &ClearCartUseCase{service: service}
+}
+
+// Execute runs the use case
+func (uc *ClearCartUseCase) Execute(ctx context.Context, owner Owner) error {
+	id, err := owner.cartID()
+	if err != nil {
+		return err
+	}
+	return uc.service.ClearCart(ctx, id)
+}
+
+// PurgeExpiredCartsUseCase defines the use case for deleting the expired carts
+type PurgeExpiredCartsUseCase struct {
+	service *domain.Service
+}
+
+// NewPurgeExpiredCartsUseCase creates a new instance of PurgeExpiredCartsUseCase
+func NewPurgeExpiredCartsUseCase(service *domain.Service) *PurgeExpiredCartsUseCase {
+	return &PurgeExpiredCartsUseCase{service: service}
+}
+
+// Execute runs the use case and returns how many carts were deleted
+func (uc *PurgeExpiredCartsUseCase) Execute(ctx context.Context) (int, error) {
+	return uc.service.PurgeExpired(ctx)
+}
+
+// RunEvery runs the use case every interval until ctx is done, logging the result
+func (uc *PurgeExpiredCartsUseCase) RunEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := logging.FromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		deleted, err := uc.Execute(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error("expired cart purge failed", "error", err)
+			}
+			continue
+		}
+		if deleted > 0 {
+			logger.Info("expired carts purged", "carts", deleted)
+		}
+	}
+}
+
+// parseItem validates the owner and product of a cart item
+func parseItem(owner Owner, productID string) (domain.CartID, product.ProductID, error) {
+	id, err := owner.cartID()
+	if err != nil {
+		return "", "", err
+	}
+	pid, err := product.NewProductID(productID)
+	if err != nil {
+		return "", "", err
+	}
+	return id, pid, nil
+}
@@ -0,0 +1,155 @@
+package cart
+
+import (
+	"context"
+	"time"
+
+	domain "sago-sample/feature/cart/domain"
+	"sago-sample/observability/logging"
+)
+
+// ReservationLineOutput represents a reserved quantity of a product
+type ReservationLineOutput struct {
+	ProductID string
+	Quantity  uint
+	UnitPrice uint
+	Total     uint
+}
+
+// ReservationOutput represents a stock reservation made from a cart
+type ReservationOutput struct {
+	ID        string
+	CartID    string
+	Lines     []ReservationLineOutput
+	Currency  string
+	Total     uint
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// toReservationOutput maps a reservation to its output
+func toReservationOutput(r *domain.Reservation) *ReservationOutput {
+	lines := make([]ReservationLineOutput, 0, len(r.Lines()))
+	for _, l := range r.Lines() {
+		lines = append(lines, ReservationLineOutput{
+			ProductID: l.ProductID().String(),
+			Quantity:  l.Quantity(),
+			UnitPrice: l.Price().Amount(),
+			Total:     l.Price().Amount() * l.Quantity(),
+		})
+	}
+	return &ReservationOutput{
+		ID:        r.ID().String(),
+		CartID:    r.CartID().String(),
+		Lines:     lines,
+		Currency:  r.Currency(),
+		Total:     r.Total(),
+		CreatedAt: r.CreatedAt(),
+		ExpiresAt: r.ExpiresAt(),
+	}
+}
+
+// ReserveCartUseCase defines the use case for converting a cart into a stock reservation
+type ReserveCartUseCase struct {
+	service *domain.Service
+}
+
+// NewReserveCartUseCase creates a new instance of ReserveCartUseCase
+func NewReserveCartUseCase(service *domain.Service) *ReserveCartUseCase {
+	return &ReserveCartUseCase{service: service}
+}
+
+// Execute runs the use case
+func (uc *ReserveCartUseCase) Execute(ctx context.Context, owner Owner) (*ReservationOutput, error) {
+	id, err := owner.cartID()
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := uc.service.Reserve(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toReservationOutput(r), nil
+}
+
+// GetReservationUseCase defines the use case for getting a reservation
+type GetReservationUseCase struct {
+	service *domain.Service
+}
+
+// NewGetReservationUseCase creates a new instance of GetReservationUseCase
+func NewGetReservationUseCase(service *domain.Service) *GetReservationUseCase {
+	return &GetReservationUseCase{service: service}
+}
+
+// Execute runs the use case
+func (uc *GetReservationUseCase) Execute(ctx context.Context, id string) (*ReservationOutput, error) {
+	reservationID, err := domain.NewReservationID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := uc.service.GetReservation(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	return toReservationOutput(r), nil
+}
+
+// ReleaseReservationUseCase defines the use case for releasing the stock held by a reservation
+type ReleaseReservationUseCase struct {
+	service *domain.Service
+}
+
+// NewReleaseReservationUseCase creates a new instance of ReleaseReservationUseCase
+func NewReleaseReservationUseCase(service *domain.Service) *ReleaseReservationUseCase {
+	return &ReleaseReservationUseCase{service: service}
+}
+
+// Execute runs the use case
+func (uc *ReleaseReservationUseCase) Execute(ctx context.Context, id string) error {
+	reservationID, err := domain.NewReservationID(id)
+	if err != nil {
+		return err
+	}
+	return uc.service.ReleaseReservation(ctx, reservationID)
+}
+
+// ReleaseExpiredReservationsUseCase defines the use case for returning the stock held by the expired reservations
+type ReleaseExpiredReservationsUseCase struct {
+	service *domain.Service
+}
+
+// NewReleaseExpiredReservationsUseCase creates a new instance of ReleaseExpiredReservationsUseCase
+func NewReleaseExpiredReservationsUseCase(service *domain.Service) *ReleaseExpiredReservationsUseCase {
+	return &ReleaseExpiredReservationsUseCase{service: service}
+}
+
+// Execute runs the use case and returns how many reservations were released
+func (uc *ReleaseExpiredReservationsUseCase) Execute(ctx context.Context) (int, error) {
+	return uc.service.ReleaseExpiredReservations(ctx)
+}
+
+// RunEvery runs the use case every interval until ctx is done, logging the result
+func (uc *ReleaseExpiredReservationsUseCase) RunEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := logging.FromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		released, err := uc.Execute(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("expired reservation release failed", "error", err)
+		}
+		if released > 0 {
+			logger.Info("expired reservations released", "reservations", released)
+		}
+	}
+}
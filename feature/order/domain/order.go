@@ -0,0 +1,254 @@
+package order
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// MaxLines is the maximum number of lines of an order
+const MaxLines = 100
+
+var (
+	ErrOrderNotFound = errors.New("order not found")
+	ErrInvalidOrder  = errors.New("invalid order")
+	// ErrInvalidTransition is returned when an order cannot move to the requested status, e.g. cancelling a fulfilled order
+	ErrInvalidTransition = errors.New("invalid order status transition")
+)
+
+// OrderID represents the unique identifier of an order
+type OrderID string
+
+// NewOrderID creates a new OrderID with validation
+func NewOrderID(id string) (OrderID, error) {
+	if id == "" {
+		return "", fmt.Errorf("%w: order ID cannot be empty", ErrInvalidOrder)
+	}
+	return OrderID(id), nil
+}
+
+// GenerateID returns a random order ID
+func GenerateID() OrderID {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return OrderID(hex.EncodeToString(b))
+}
+
+// String returns the string representation of the OrderID
+func (id OrderID) String() string {
+	return string(id)
+}
+
+// Status is the stage of an order
+type Status string
+
+const (
+	// StatusPlaced orders hold their stock until they are fulfilled or cancelled
+	StatusPlaced    Status = "placed"
+	StatusFulfilled Status = "fulfilled"
+	StatusCancelled Status = "cancelled"
+)
+
+// Statuses lists every status
+var Statuses = []Status{StatusPlaced, StatusFulfilled, StatusCancelled}
+
+// transitions lists the statuses each status can move to; fulfilled and cancelled orders are final
+var transitions = map[Status][]Status{
+	StatusPlaced: {StatusFulfilled, StatusCancelled},
+}
+
+// NewStatus creates a new Status with validation
+func NewStatus(s string) (Status, error) {
+	for _, status := range Statuses {
+		if Status(s) == status {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown status %q", ErrInvalidOrder, s)
+}
+
+// String returns the string representation of the Status
+func (s Status) String() string {
+	return string(s)
+}
+
+// CanTransitionTo reports whether an order can move from s to next
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Line is a quantity of a product at the unit price it had when the order was placed
+type Line struct {
+	productID   product.ProductID
+	productName product.ProductName
+	quantity    uint
+	unitPrice   product.Price
+}
+
+// NewLine creates a new Line; the name and price are snapshots of the product
+func NewLine(productID product.ProductID, productName product.ProductName, quantity uint, unitPrice product.Price) (Line, error) {
+	if productID == "" {
+		return Line{}, fmt.Errorf("%w: line product ID cannot be empty", ErrInvalidOrder)
+	}
+	if quantity == 0 {
+		return Line{}, fmt.Errorf("%w: line quantity must be positive", ErrInvalidOrder)
+	}
+	return Line{productID: productID, productName: productName, quantity: quantity, unitPrice: unitPrice}, nil
+}
+
+// ProductID returns the ordered product
+func (l Line) ProductID() product.ProductID {
+	return l.productID
+}
+
+// ProductName returns the name of the product when the order was placed
+func (l Line) ProductName() product.ProductName {
+	return l.productName
+}
+
+// Quantity returns the ordered quantity
+func (l Line) Quantity() uint {
+	return l.quantity
+}
+
+// UnitPrice returns the price of one unit when the order was placed
+func (l Line) UnitPrice() product.Price {
+	return l.unitPrice
+}
+
+// Total returns the price of the line
+func (l Line) Total() uint {
+	return l.unitPrice.Amount() * l.quantity
+}
+
+// Order is an aggregate of product lines going through the placed, fulfilled and cancelled statuses
+type Order struct {
+	id        OrderID
+	lines     []Line
+	status    Status
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+// NewOrder creates a new placed Order; its lines must be for distinct products and in a single currency
+func NewOrder(id OrderID, lines []Line) (*Order, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: order ID cannot be empty", ErrInvalidOrder)
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: an order needs at least one line", ErrInvalidOrder)
+	}
+	if len(lines) > MaxLines {
+		return nil, fmt.Errorf("%w: an order cannot have more than %d lines", ErrInvalidOrder, MaxLines)
+	}
+
+	seen := make(map[product.ProductID]bool, len(lines))
+	for _, l := range lines {
+		if seen[l.productID] {
+			return nil, fmt.Errorf("%w: product %s is ordered twice", ErrInvalidOrder, l.productID)
+		}
+		seen[l.productID] = true
+		if l.unitPrice.Currency() != lines[0].unitPrice.Currency() {
+			return nil, fmt.Errorf("%w: lines must share one currency, got %s and %s", ErrInvalidOrder, lines[0].unitPrice.Currency(), l.unitPrice.Currency())
+		}
+	}
+
+	now := time.Now()
+	return &Order{
+		id:        id,
+		lines:     append([]Line(nil), lines...),
+		status:    StatusPlaced,
+		createdAt: now,
+		updatedAt: now,
+	}, nil
+}
+
+// RestoreOrder rebuilds an Order from persisted state
+func RestoreOrder(id OrderID, lines []Line, status Status, createdAt, updatedAt time.Time) *Order {
+	return &Order{
+		id:        id,
+		lines:     lines,
+		status:    status,
+		createdAt: createdAt,
+		updatedAt: updatedAt,
+	}
+}
+
+// ID returns the order's ID
+func (o *Order) ID() OrderID {
+	return o.id
+}
+
+// Lines returns the order's lines
+func (o *Order) Lines() []Line {
+	return o.lines
+}
+
+// Status returns the order's status
+func (o *Order) Status() Status {
+	return o.status
+}
+
+// CreatedAt returns when the order was placed
+func (o *Order) CreatedAt() time.Time {
+	return o.createdAt
+}
+
+// UpdatedAt returns when the order last changed status
+func (o *Order) UpdatedAt() time.Time {
+	return o.updatedAt
+}
+
+// Currency returns the currency of every line
+func (o *Order) Currency() string {
+	return o.lines[0].unitPrice.Currency()
+}
+
+// Total returns the price of the order in its currency
+func (o *Order) Total() uint {
+	var total uint
+	for _, l := range o.lines {
+		total += l.Total()
+	}
+	return total
+}
+
+// Quantities returns the ordered quantity of each product
+func (o *Order) Quantities() map[product.ProductID]uint {
+	quantities := make(map[product.ProductID]uint, len(o.lines))
+	for _, l := range o.lines {
+		quantities[l.productID] += l.quantity
+	}
+	return quantities
+}
+
+// Fulfil marks a placed order as fulfilled
+func (o *Order) Fulfil() error {
+	return o.transitionTo(StatusFulfilled)
+}
+
+// Cancel marks a placed order as cancelled
+func (o *Order) Cancel() error {
+	return o.transitionTo(StatusCancelled)
+}
+
+// transitionTo moves the order to next if its status allows it
+func (o *Order) transitionTo(next Status) error {
+	if !o.status.CanTransitionTo(next) {
+		return fmt.Errorf("%w: order %s is %s and cannot become %s", ErrInvalidTransition, o.id, o.status, next)
+	}
+	o.status = next
+	o.updatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,12 @@
+package order
+
+import (
+	"context"
+)
+
+type Repository interface {
+	FindByID(ctx context.Context, id OrderID) (*Order, error)
+	// FindAll returns every order, newest first
+	FindAll(ctx context.Context) ([]*Order, error)
+	Save(ctx context.Context, order *Order) error
+}
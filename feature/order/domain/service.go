@@ -0,0 +1,183 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// LineRequest is a quantity of a product to order
+type LineRequest struct {
+	ProductID product.ProductID
+	Quantity  uint
+}
+
+// Service places orders and moves them through their statuses, taking and returning the stock of the ordered products.
+// The stock of every line is taken, or returned, at once through the inventory service, in the transaction saving the order.
+// A status change reads the order in that transaction too: the changes of one order are serialized within the process,
+// and across instances by the row lock the repository takes when the order is read, so an order cannot be cancelled twice
+// nor cancelled and fulfilled at the same time.
+type Service struct {
+	orders    Repository
+	products  product.Repository
+	inventory *product.InventoryService
+	// clock tells whether the products are inside their availability window
+	clock product.Clock
+	locks orderLocks
+}
+
+// NewService creates a new order service
+func NewService(orders Repository, products product.Repository, inventory *product.InventoryService) *Service {
+	return &Service{orders: orders, products: products, inventory: inventory, clock: product.SystemClock}
+}
+
+// SetClock replaces the clock telling whether the products are inside their availability window; tests inject a fake one
+func (s *Service) SetClock(clock product.Clock) {
+	s.clock = clock
+}
+
+// PlaceOrder creates an order at the current prices of the products and takes their stock.
+// Only published products inside their availability window can be ordered.
+// When any product lacks stock, product.ErrInsufficientStock is returned and no stock is taken.
+func (s *Service) PlaceOrder(ctx context.Context, requests []LineRequest) (*Order, error) {
+	now := s.clock.Now()
+	lines := make([]Line, 0, len(requests))
+	for _, r := range requests {
+		p, err := s.products.FindByID(ctx, r.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.CheckAvailableAt(now); err != nil {
+			return nil, err
+		}
+		line, err := NewLine(p.ID(), p.Name(), r.Quantity, p.Price())
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	order, err := NewOrder(GenerateID(), lines)
+	if err != nil {
+		return nil, err
+	}
+
+	// The stock is taken and the order saved in one transaction; without transactions a failed save returns the stock
+	reason := "order " + order.ID().String()
+	err = s.inventory.Transaction(ctx, func(ctx context.Context) error {
+		if _, err := s.inventory.TakeStock(ctx, order.Quantities(), product.MovementSale, reason); err != nil {
+			return err
+		}
+		if err := s.orders.Save(ctx, order); err != nil {
+			return errors.Join(err, s.returnStock(ctx, order, reason+" not saved"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// CancelOrder cancels a placed order and returns its stock
+func (s *Service) CancelOrder(ctx context.Context, id OrderID) (*Order, error) {
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	var order *Order
+	err := s.inventory.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if order, err = s.orders.FindByID(ctx, id); err != nil {
+			return err
+		}
+		if err := order.Cancel(); err != nil {
+			return err
+		}
+
+		reason := "order " + order.ID().String() + " cancelled"
+		if err := s.returnStock(ctx, order, reason); err != nil {
+			return err
+		}
+		if err := s.orders.Save(ctx, order); err != nil {
+			// Take the stock back, the order is still placed
+			_, takeErr := s.inventory.TakeStock(ctx, order.Quantities(), product.MovementSale, "order "+order.ID().String()+" not cancelled")
+			return errors.Join(err, takeErr)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// FulfilOrder marks a placed order as fulfilled; its stock was taken when it was placed
+func (s *Service) FulfilOrder(ctx context.Context, id OrderID) (*Order, error) {
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	var order *Order
+	err := s.inventory.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if order, err = s.orders.FindByID(ctx, id); err != nil {
+			return err
+		}
+		if err := order.Fulfil(); err != nil {
+			return err
+		}
+		return s.orders.Save(ctx, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// returnStock gives the stock of an order back to its products
+func (s *Service) returnStock(ctx context.Context, order *Order, reason string) error {
+	if _, err := s.inventory.ReturnStock(ctx, order.Quantities(), product.MovementReturn, reason); err != nil {
+		return fmt.Errorf("return stock of order %s: %w", order.ID(), err)
+	}
+	return nil
+}
+
+// orderLocks serializes the status changes of each order within the process
+type orderLocks struct {
+	mutex sync.Mutex
+	held  map[OrderID]*orderLock
+}
+
+type orderLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// lock acquires the lock of an order and returns the function releasing it
+func (l *orderLocks) lock(id OrderID) (unlock func()) {
+	l.mutex.Lock()
+	if l.held == nil {
+		l.held = make(map[OrderID]*orderLock)
+	}
+	ol, ok := l.held[id]
+	if !ok {
+		ol = &orderLock{}
+		l.held[id] = ol
+	}
+	ol.waiters++
+	l.mutex.Unlock()
+
+	ol.Lock()
+	return func() {
+		ol.Unlock()
+
+		l.mutex.Lock()
+		ol.waiters--
+		if ol.waiters == 0 {
+			delete(l.held, id)
+		}
+		l.mutex.Unlock()
+	}
+}
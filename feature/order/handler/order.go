@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	order "sago-sample/feature/order/usecase"
+)
+
+// PlaceOrderLineRequest represents a quantity of a product in the request body for placing an order
+type PlaceOrderLineRequest struct {
+	ProductID string `json:"productId"`
+	Quantity  uint   `json:"quantity"`
+}
+
+// PlaceOrderRequest represents the request body for placing an order
+type PlaceOrderRequest struct {
+	Lines []PlaceOrderLineRequest `json:"lines"`
+}
+
+// OrderLineResponse represents a line of an order in the response
+type OrderLineResponse struct {
+	ProductID   string `json:"productId"`
+	ProductName string `json:"productName"`
+	Quantity    uint   `json:"quantity"`
+	UnitPrice   uint   `json:"unitPrice"`
+	Total       uint   `json:"total"`
+}
+
+// OrderResponse represents an order in the response
+type OrderResponse struct {
+	ID        string              `json:"id"`
+	Status    string              `json:"status"`
+	Lines     []OrderLineResponse `json:"lines"`
+	Currency  string              `json:"currency"`
+	Total     uint                `json:"total"`
+	CreatedAt time.Time           `json:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+}
+
+// OrderHandler handles orders
+type OrderHandler struct {
+	PlaceUseCase  *order.PlaceOrderUseCase
+	GetUseCase    *order.GetOrderUseCase
+	ListUseCase   *order.ListOrdersUseCase
+	CancelUseCase *order.CancelOrderUseCase
+	FulfilUseCase *order.FulfilOrderUseCase
+}
+
+func NewOrderHandler(
+	placeUc *order.PlaceOrderUseCase,
+	getUc *order.GetOrderUseCase,
+	listUc *order.ListOrdersUseCase,
+	cancelUc *order.CancelOrderUseCase,
+	fulfilUc *order.FulfilOrderUseCase,
+) *OrderHandler {
+	return &OrderHandler{
+		PlaceUseCase:  placeUc,
+		GetUseCase:    getUc,
+		ListUseCase:   listUc,
+		CancelUseCase: cancelUc,
+		FulfilUseCase: fulfilUc,
+	}
+}
+
+// Register adds the order routes to rtr
+func (h *OrderHandler) Register(rtr chi.Router) {
+	rtr.Get("/api/orders", h.HandleList)                // GET    /api/orders
+	rtr.Post("/api/orders", h.HandlePlace)              // POST   /api/orders
+	rtr.Get("/api/orders/{id}", h.HandleGet)            // GET    /api/orders/{id}
+	rtr.Post("/api/orders/{id}/cancel", h.HandleCancel) // POST   /api/orders/{id}/cancel
+	rtr.Post("/api/orders/{id}/fulfil", h.HandleFulfil) // POST   /api/orders/{id}/fulfil
+}
+
+// HandlePlace handles placing an order; the stock of every line is taken, or none is
+func (h *OrderHandler) HandlePlace(w http.ResponseWriter, r *http.Request) {
+	var req PlaceOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	input := order.PlaceOrderInput{Lines: make([]order.PlaceOrderLineInput, 0, len(req.Lines))}
+	for _, l := range req.Lines {
+		input.Lines = append(input.Lines, order.PlaceOrderLineInput{ProductID: l.ProductID, Quantity: l.Quantity})
+	}
+
+	output, err := h.PlaceUseCase.Execute(r.Context(), input)
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toOrderResponse(output))
+}
+
+// HandleList handles listing the orders, newest first; ?status= filters them
+func (h *OrderHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	outputs, err := h.ListUseCase.Execute(r.Context(), order.ListOrdersInput{Status: r.URL.Query().Get("status")})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	responses := make([]OrderResponse, 0, len(outputs))
+	for _, o := range outputs {
+		responses = append(responses, toOrderResponse(o))
+	}
+	respondWithJSON(w, http.StatusOK, responses)
+}
+
+// HandleGet handles getting an order by ID
+func (h *OrderHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	output, err := h.GetUseCase.Execute(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toOrderResponse(output))
+}
+
+// HandleCancel handles cancelling a placed order, which returns its stock
+func (h *OrderHandler) HandleCancel(w http.ResponseWriter, r *http.Request) {
+	output, err := h.CancelUseCase.Execute(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toOrderResponse(output))
+}
+
+// HandleFulfil handles fulfilling a placed order
+func (h *OrderHandler) HandleFulfil(w http.ResponseWriter, r *http.Request) {
+	output, err := h.FulfilUseCase.Execute(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toOrderResponse(output))
+}
+
+// toOrderResponse maps the output of a use case to the response
+func toOrderResponse(o *order.OrderOutput) OrderResponse {
+	lines := make([]OrderLineResponse, 0, len(o.Lines))
+	for _, l := range o.Lines {
+		lines = append(lines, OrderLineResponse{
+			ProductID:   l.ProductID,
+			ProductName: l.ProductName,
+			Quantity:    l.Quantity,
+			UnitPrice:   l.UnitPrice,
+			Total:       l.Total,
+		})
+	}
+	return OrderResponse{
+		ID:        o.ID,
+		Status:    o.Status,
+		Lines:     lines,
+		Currency:  o.Currency,
+		Total:     o.Total,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+}
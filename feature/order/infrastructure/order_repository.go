@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	order "sago-sample/feature/order/domain"
+)
+
+// OrderRepository is an in-memory implementation of the order.Repository interface.
+// Orders are copied on the way in and out, so a status change is only visible once saved.
+type OrderRepository struct {
+	orders map[order.OrderID]order.Order
+	mutex  sync.RWMutex
+}
+
+// NewOrderRepository creates a new in-memory order repository
+func NewOrderRepository() *OrderRepository {
+	return &OrderRepository{
+		orders: make(map[order.OrderID]order.Order),
+	}
+}
+
+// FindByID finds an order by its ID
+func (r *OrderRepository) FindByID(ctx context.Context, id order.OrderID) (*order.Order, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	o, exists := r.orders[id]
+	if !exists {
+		return nil, order.ErrOrderNotFound
+	}
+	return &o, nil
+}
+
+// FindAll returns all orders, newest first
+func (r *OrderRepository) FindAll(ctx context.Context) ([]*order.Order, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	orders := make([]*order.Order, 0, len(r.orders))
+	for _, o := range r.orders {
+		o := o
+		orders = append(orders, &o)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].CreatedAt().Equal(orders[j].CreatedAt()) {
+			return orders[i].CreatedAt().After(orders[j].CreatedAt())
+		}
+		return orders[i].ID() < orders[j].ID()
+	})
+	return orders, nil
+}
+
+// Save persists an order
+func (r *OrderRepository) Save(ctx context.Context, o *order.Order) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.orders[o.ID()] = *o
+	return nil
+}
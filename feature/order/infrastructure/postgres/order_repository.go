@@ -0,0 +1,182 @@
+// Package postgres stores orders in PostgreSQL through gorm, using the schema in /migrations
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	order "sago-sample/feature/order/domain"
+	product "sago-sample/feature/product/domain"
+	productPostgres "sago-sample/feature/product/infrastructure/postgres"
+)
+
+// orderRow is a row of the orders table
+type orderRow struct {
+	ID        string    `gorm:"column:id;primaryKey"`
+	Status    string    `gorm:"column:status"`
+	Currency  string    `gorm:"column:currency"`
+	Total     uint      `gorm:"column:total"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime:false"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime:false"`
+}
+
+func (orderRow) TableName() string { return "orders" }
+
+// orderLineRow is a row of the order_lines table; position keeps the order of the lines
+type orderLineRow struct {
+	OrderID     string `gorm:"column:order_id;primaryKey"`
+	Position    int    `gorm:"column:position;primaryKey"`
+	ProductID   string `gorm:"column:product_id"`
+	ProductName string `gorm:"column:product_name"`
+	Quantity    uint   `gorm:"column:quantity"`
+	UnitPrice   uint   `gorm:"column:unit_price"`
+}
+
+func (orderLineRow) TableName() string { return "order_lines" }
+
+// OrderRepository is a PostgreSQL implementation of the order.Repository interface
+type OrderRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderRepository creates a new PostgreSQL order repository
+func NewOrderRepository(db *gorm.DB) *OrderRepository {
+	return &OrderRepository{db: db}
+}
+
+// FindByID finds an order by its ID.
+// Inside a transaction the order row stays locked until it ends, so that other instances cannot change its status meanwhile.
+func (r *OrderRepository) FindByID(ctx context.Context, id order.OrderID) (*order.Order, error) {
+	var row orderRow
+	err := productPostgres.ConnForUpdate(ctx, r.db).Where("id = ?", id.String()).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, order.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := r.restore(ctx, []orderRow{row})
+	if err != nil {
+		return nil, err
+	}
+	return orders[0], nil
+}
+
+// FindAll returns all orders, newest first
+func (r *OrderRepository) FindAll(ctx context.Context) ([]*order.Order, error) {
+	var rows []orderRow
+	if err := productPostgres.Conn(ctx, r.db).Order("created_at DESC, id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return r.restore(ctx, rows)
+}
+
+// Save persists an order with its lines; lines never change once placed, so an existing order only updates its status
+func (r *OrderRepository) Save(ctx context.Context, o *order.Order) error {
+	row := orderRow{
+		ID:        o.ID().String(),
+		Status:    o.Status().String(),
+		Currency:  o.Currency(),
+		Total:     o.Total(),
+		CreatedAt: o.CreatedAt().UTC(),
+		UpdatedAt: o.UpdatedAt().UTC(),
+	}
+
+	return productPostgres.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"status", "updated_at"}),
+		}).Create(&row)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		var count int64
+		if err := tx.Model(&orderLineRow{}).Where("order_id = ?", row.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		lines := make([]orderLineRow, 0, len(o.Lines()))
+		for i, l := range o.Lines() {
+			lines = append(lines, orderLineRow{
+				OrderID:     row.ID,
+				Position:    i,
+				ProductID:   l.ProductID().String(),
+				ProductName: l.ProductName().String(),
+				Quantity:    l.Quantity(),
+				UnitPrice:   l.UnitPrice().Amount(),
+			})
+		}
+		return tx.Create(&lines).Error
+	})
+}
+
+// restore rebuilds the orders of rows, loading their lines with one query
+func (r *OrderRepository) restore(ctx context.Context, rows []orderRow) ([]*order.Order, error) {
+	orders := make([]*order.Order, 0, len(rows))
+	if len(rows) == 0 {
+		return orders, nil
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	var lineRows []orderLineRow
+	if err := productPostgres.Conn(ctx, r.db).Where("order_id IN ?", ids).Order("order_id, position").Find(&lineRows).Error; err != nil {
+		return nil, err
+	}
+	lines := make(map[string][]orderLineRow, len(rows))
+	for _, l := range lineRows {
+		lines[l.OrderID] = append(lines[l.OrderID], l)
+	}
+
+	for _, row := range rows {
+		o, err := restoreOrder(row, lines[row.ID])
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+// restoreOrder rebuilds an order from its rows; every value is validated again but the product names, restored as stored
+// like the product repository restores them
+func restoreOrder(row orderRow, lineRows []orderLineRow) (*order.Order, error) {
+	id, err := order.NewOrderID(row.ID)
+	if err != nil {
+		return nil, err
+	}
+	status, err := order.NewStatus(row.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]order.Line, 0, len(lineRows))
+	for _, l := range lineRows {
+		productID, err := product.NewProductID(l.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		name := product.ProductName(l.ProductName)
+		price, err := product.NewPrice(l.UnitPrice, row.Currency)
+		if err != nil {
+			return nil, err
+		}
+		line, err := order.NewLine(productID, name, l.Quantity, price)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return order.RestoreOrder(id, lines, status, row.CreatedAt, row.UpdatedAt), nil
+}
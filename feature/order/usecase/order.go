@@ -0,0 +1,204 @@
+package order
+
+import (
+	"context"
+	"time"
+
+	domain "sago-sample/feature/order/domain"
+	product "sago-sample/feature/product/domain"
+)
+
+// OrderLineOutput represents one line of an order
+type OrderLineOutput struct {
+	ProductID   string
+	ProductName string
+	Quantity    uint
+	UnitPrice   uint
+	Total       uint
+}
+
+// OrderOutput represents an order
+type OrderOutput struct {
+	ID        string
+	Status    string
+	Lines     []OrderLineOutput
+	Currency  string
+	Total     uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// toOrderOutput maps an order to its output
+func toOrderOutput(o *domain.Order) *OrderOutput {
+	lines := make([]OrderLineOutput, 0, len(o.Lines()))
+	for _, l := range o.Lines() {
+		lines = append(lines, OrderLineOutput{
+			ProductID:   l.ProductID().String(),
+			ProductName: l.ProductName().String(),
+			Quantity:    l.Quantity(),
+			UnitPrice:   l.UnitPrice().Amount(),
+			Total:       l.Total(),
+		})
+	}
+	return &OrderOutput{
+		ID:        o.ID().String(),
+		Status:    o.Status().String(),
+		Lines:     lines,
+		Currency:  o.Currency(),
+		Total:     o.Total(),
+		CreatedAt: o.CreatedAt(),
+		UpdatedAt: o.UpdatedAt(),
+	}
+}
+
+// PlaceOrderLineInput represents a quantity of a product to order
+type PlaceOrderLineInput struct {
+	ProductID string
+	Quantity  uint
+}
+
+// PlaceOrderInput represents the input data for placing an order
+type PlaceOrderInput struct {
+	Lines []PlaceOrderLineInput
+}
+
+// PlaceOrderUseCase defines the use case for placing an order
+type PlaceOrderUseCase struct {
+	service *domain.Service
+}
+
+// NewPlaceOrderUseCase creates a new instance of PlaceOrderUseCase
+func NewPlaceOrderUseCase(service *domain.Service) *PlaceOrderUseCase {
+	return &PlaceOrderUseCase{service: service}
+}
+
+// Execute runs the use case
+func (uc *PlaceOrderUseCase) Execute(ctx context.Context, input PlaceOrderInput) (*OrderOutput, error) {
+	requests := make([]domain.LineRequest, 0, len(input.Lines))
+	for _, l := range input.Lines {
+		productID, err := product.NewProductID(l.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, domain.LineRequest{ProductID: productID, Quantity: l.Quantity})
+	}
+
+	o, err := uc.service.PlaceOrder(ctx, requests)
+	if err != nil {
+		return nil, err
+	}
+	return toOrderOutput(o), nil
+}
+
+// CancelOrderUseCase defines the use case for cancelling an order
+type CancelOrderUseCase struct {
+	service *domain.Service
+}
+
+// NewCancelOrderUseCase creates a new instance of CancelOrderUseCase
+func NewCancelOrderUseCase(service *domain.Service) *CancelOrderUseCase {
+	return &CancelOrderUseCase{service: service}
+}
+
+// Execute runs the use case
+func (uc *CancelOrderUseCase) Execute(ctx context.Context, id string) (*OrderOutput, error) {
+	orderID, err := domain.NewOrderID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := uc.service.CancelOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return toOrderOutput(o), nil
+}
+
+// FulfilOrderUseCase defines the use case for fulfilling an order
+type FulfilOrderUseCase struct {
+	service *domain.Service
+}
+
+// NewFulfilOrderUseCase creates a new instance of FulfilOrderUseCase
+func NewFulfilOrderUseCase(service *domain.Service) *FulfilOrderUseCase {
+	return &FulfilOrderUseCase{service: service}
+}
+
+// Execute runs the use case
+func (uc *FulfilOrderUseCase) Execute(ctx context.Context, id string) (*OrderOutput, error) {
+	orderID, err := domain.NewOrderID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := uc.service.FulfilOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return toOrderOutput(o), nil
+}
+
+// GetOrderUseCase defines the use case for getting an order
+type GetOrderUseCase struct {
+	repo domain.Repository
+}
+
+// NewGetOrderUseCase creates a new instance of GetOrderUseCase
+func NewGetOrderUseCase(repo domain.Repository) *GetOrderUseCase {
+	return &GetOrderUseCase{repo: repo}
+}
+
+// Execute runs the use case
+func (uc *GetOrderUseCase) Execute(ctx context.Context, id string) (*OrderOutput, error) {
+	orderID, err := domain.NewOrderID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := uc.repo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return toOrderOutput(o), nil
+}
+
+// ListOrdersInput represents the input data for listing orders
+type ListOrdersInput struct {
+	// Status filters the orders when set
+	Status string
+}
+
+// ListOrdersUseCase defines the use case for listing orders, newest first
+type ListOrdersUseCase struct {
+	repo domain.Repository
+}
+
+// NewListOrdersUseCase creates a new instance of ListOrdersUseCase
+func NewListOrdersUseCase(repo domain.Repository) *ListOrdersUseCase {
+	return &ListOrdersUseCase{repo: repo}
+}
+
+// Execute runs the use case
+func (uc *ListOrdersUseCase) Execute(ctx context.Context, input ListOrdersInput) ([]*OrderOutput, error) {
+	var status domain.Status
+	if input.Status != "" {
+		var err error
+		if status, err = domain.NewStatus(input.Status); err != nil {
+			return nil, err
+		}
+	}
+
+	orders, err := uc.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]*OrderOutput, 0, len(orders))
+	for _, o := range orders {
+		if status != "" && o.Status() != status {
+			continue
+		}
+		outputs = append(outputs, toOrderOutput(o))
+	}
+	return outputs, nil
+}
@@ -0,0 +1,331 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sago-sample/internal/textnorm"
+)
+
+// ErrAttributeSchemaNotFound is returned when a category has no attribute schema
+var ErrAttributeSchemaNotFound = errors.New("attribute schema not found")
+
+// AttributeKeyPattern is the pattern of attribute keys, e.g. color or warranty_months
+const AttributeKeyPattern = "^[a-z][a-z0-9_]{0,63}$"
+
+// MaxAttributeValueLength is the maximum length of a string or enum attribute value, in characters
+const MaxAttributeValueLength = 200
+
+var attributeKey = regexp.MustCompile(AttributeKeyPattern)
+
+// ValidateAttributeKey checks that key matches AttributeKeyPattern
+func ValidateAttributeKey(key string) error {
+	if !attributeKey.MatchString(key) {
+		return NewValidationError(fmt.Sprintf("attribute key %q must match %s", key, AttributeKeyPattern))
+	}
+	return nil
+}
+
+// AttributeSchemaRepository stores the attribute schemas of categories
+type AttributeSchemaRepository interface {
+	FindByCategory(ctx context.Context, categoryID CategoryID) (*AttributeSchema, error)
+	Save(ctx context.Context, schema *AttributeSchema) error
+	Delete(ctx context.Context, categoryID CategoryID) error
+}
+
+// AttributeType is the type of the values of an attribute
+type AttributeType string
+
+const (
+	AttributeString AttributeType = "string"
+	AttributeNumber AttributeType = "number"
+	AttributeBool   AttributeType = "bool"
+	// AttributeEnum is a string among the values of its definition
+	AttributeEnum AttributeType = "enum"
+)
+
+// AttributeTypes lists every attribute type
+var AttributeTypes = []AttributeType{AttributeString, AttributeNumber, AttributeBool, AttributeEnum}
+
+// NewAttributeType creates a new AttributeType from its name
+func NewAttributeType(s string) (AttributeType, error) {
+	for _, t := range AttributeTypes {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", NewValidationError(fmt.Sprintf("unknown attribute type %q", s))
+}
+
+// String returns the string representation of the AttributeType
+func (t AttributeType) String() string {
+	return string(t)
+}
+
+// AttributeDefinition declares an attribute the products of a category carry
+type AttributeDefinition struct {
+	key      string
+	attrType AttributeType
+	required bool
+	values   []string
+}
+
+// NewAttributeDefinition creates a new AttributeDefinition; values lists the allowed values of an enum and is empty otherwise.
+// Enum values are normalized like string attribute values.
+func NewAttributeDefinition(key string, attrType AttributeType, required bool, values []string) (AttributeDefinition, error) {
+	if err := ValidateAttributeKey(key); err != nil {
+		return AttributeDefinition{}, err
+	}
+	if _, err := NewAttributeType(string(attrType)); err != nil {
+		return AttributeDefinition{}, err
+	}
+	if attrType != AttributeEnum {
+		if len(values) > 0 {
+			return AttributeDefinition{}, NewValidationError(fmt.Sprintf("attribute %s: only enum attributes have values", key))
+		}
+		return AttributeDefinition{key: key, attrType: attrType, required: required}, nil
+	}
+
+	if len(values) == 0 {
+		return AttributeDefinition{}, NewValidationError(fmt.Sprintf("attribute %s: an enum needs at least one value", key))
+	}
+	normalized := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, raw := range values {
+		v, err := textnorm.Line(raw)
+		if err != nil {
+			return AttributeDefinition{}, NewValidationError(fmt.Sprintf("attribute %s: enum value %s", key, err))
+		}
+		if v == "" || textnorm.Length(v) > MaxAttributeValueLength {
+			return AttributeDefinition{}, NewValidationError(fmt.Sprintf("attribute %s: enum values must have 1 to %d characters", key, MaxAttributeValueLength))
+		}
+		if seen[v] {
+			return AttributeDefinition{}, NewValidationError(fmt.Sprintf("attribute %s: duplicate enum value %q", key, v))
+		}
+		seen[v] = true
+		normalized = append(normalized, v)
+	}
+	return AttributeDefinition{key: key, attrType: attrType, required: required, values: normalized}, nil
+}
+
+// RestoreAttributeDefinition rebuilds an AttributeDefinition from persisted state, keeping its key and values as saved.
+// It is meant for repositories; new definitions are created with NewAttributeDefinition.
+func RestoreAttributeDefinition(key string, attrType AttributeType, required bool, values []string) AttributeDefinition {
+	return AttributeDefinition{key: key, attrType: attrType, required: required, values: append([]string(nil), values...)}
+}
+
+// Key returns the key of the attribute
+func (d AttributeDefinition) Key() string {
+	return d.key
+}
+
+// Type returns the type of the attribute
+func (d AttributeDefinition) Type() AttributeType {
+	return d.attrType
+}
+
+// Required reports whether every product of the category must set the attribute
+func (d AttributeDefinition) Required() bool {
+	return d.required
+}
+
+// Values returns the allowed values of an enum attribute
+func (d AttributeDefinition) Values() []string {
+	return append([]string(nil), d.values...)
+}
+
+// check returns why value does not fit the definition, or an empty string when it does
+func (d AttributeDefinition) check(value any) string {
+	switch d.attrType {
+	case AttributeNumber:
+		if _, ok := value.(float64); !ok {
+			return d.key + " must be a number"
+		}
+	case AttributeBool:
+		if _, ok := value.(bool); !ok {
+			return d.key + " must be a boolean"
+		}
+	case AttributeEnum:
+		s, ok := value.(string)
+		if !ok || !contains(d.values, s) {
+			return fmt.Sprintf("%s must be one of %s", d.key, strings.Join(d.values, ", "))
+		}
+	default:
+		if _, ok := value.(string); !ok {
+			return d.key + " must be a string"
+		}
+	}
+	return ""
+}
+
+// AttributeSchema lists the attributes of the products of a category
+type AttributeSchema struct {
+	categoryID  CategoryID
+	definitions []AttributeDefinition
+}
+
+// NewAttributeSchema creates a new AttributeSchema; each key can only be defined once
+func NewAttributeSchema(categoryID CategoryID, definitions []AttributeDefinition) (*AttributeSchema, error) {
+	if categoryID.IsEmpty() {
+		return nil, NewValidationError("category id cannot be empty")
+	}
+	seen := make(map[string]bool, len(definitions))
+	for _, d := range definitions {
+		if seen[d.key] {
+			return nil, NewValidationError(fmt.Sprintf("attribute %s is defined twice", d.key))
+		}
+		seen[d.key] = true
+	}
+	return &AttributeSchema{categoryID: categoryID, definitions: append([]AttributeDefinition(nil), definitions...)}, nil
+}
+
+// CategoryID returns the category the schema applies to
+func (s *AttributeSchema) CategoryID() CategoryID {
+	return s.categoryID
+}
+
+// Definitions returns the attribute definitions in their declared order
+func (s *AttributeSchema) Definitions() []AttributeDefinition {
+	return append([]AttributeDefinition(nil), s.definitions...)
+}
+
+// Attributes are the custom attribute values of a product by key; each value is a string, a float64 or a bool
+type Attributes map[string]any
+
+// NewAttributes validates the keys and values of attributes; strings are normalized to NFC on a single line
+// and integers are converted to float64
+func NewAttributes(values map[string]any) (Attributes, error) {
+	attrs := make(Attributes, len(values))
+	for key, value := range values {
+		if err := ValidateAttributeKey(key); err != nil {
+			return nil, err
+		}
+		switch v := value.(type) {
+		case string:
+			normalized, err := textnorm.Line(v)
+			if err != nil {
+				return nil, NewValidationError(fmt.Sprintf("attribute %s %s", key, err))
+			}
+			if textnorm.Length(normalized) > MaxAttributeValueLength {
+				return nil, NewValidationError(fmt.Sprintf("attribute %s cannot exceed %d characters", key, MaxAttributeValueLength))
+			}
+			attrs[key] = normalized
+		case bool, float64:
+			attrs[key] = v
+		case int:
+			attrs[key] = float64(v)
+		case int64:
+			attrs[key] = float64(v)
+		case uint:
+			attrs[key] = float64(v)
+		default:
+			return nil, NewValidationError(fmt.Sprintf("attribute %s must be a string, a number or a boolean", key))
+		}
+	}
+	return attrs, nil
+}
+
+// Keys returns the attribute keys in order
+func (a Attributes) Keys() []string {
+	keys := make([]string, 0, len(a))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Matches reports whether the attribute key is set to value, given as text: strings are compared once normalized,
+// numbers numerically, and booleans accept the forms of strconv.ParseBool
+func (a Attributes) Matches(key, value string) bool {
+	switch v := a[key].(type) {
+	case string:
+		normalized, err := textnorm.Line(value)
+		return err == nil && v == normalized
+	case float64:
+		f, err := strconv.ParseFloat(value, 64)
+		return err == nil && f == v
+	case bool:
+		b, err := strconv.ParseBool(value)
+		return err == nil && b == v
+	default:
+		return false
+	}
+}
+
+// clone returns a copy of the attributes
+func (a Attributes) clone() Attributes {
+	c := make(Attributes, len(a))
+	for key, value := range a {
+		c[key] = value
+	}
+	return c
+}
+
+// ValidateAttributes checks attributes against the schemas of the categories of a product: every attribute must be
+// defined by one of the schemas and fit each definition of its key, and every required attribute must be set.
+// Every problem is reported at once.
+func ValidateAttributes(attrs Attributes, schemas []*AttributeSchema) error {
+	var reasons []string
+	defined := make(map[string]bool)
+	required := make(map[string]bool)
+	for _, s := range schemas {
+		for _, d := range s.definitions {
+			defined[d.key] = true
+			if d.required {
+				required[d.key] = true
+			}
+			if value, ok := attrs[d.key]; ok {
+				if reason := d.check(value); reason != "" && !contains(reasons, reason) {
+					reasons = append(reasons, reason)
+				}
+			}
+		}
+	}
+	for _, key := range attrs.Keys() {
+		if !defined[key] {
+			reasons = append(reasons, key+" is not defined by the categories of the product")
+		}
+	}
+	requiredKeys := make([]string, 0, len(required))
+	for key := range required {
+		requiredKeys = append(requiredKeys, key)
+	}
+	sort.Strings(requiredKeys)
+	for _, key := range requiredKeys {
+		if _, ok := attrs[key]; !ok {
+			reasons = append(reasons, key+" is required")
+		}
+	}
+
+	if len(reasons) > 0 {
+		return NewValidationError("invalid attributes: " + strings.Join(reasons, "; "))
+	}
+	return nil
+}
+
+// Attributes returns a copy of the product's attributes
+func (p *Product) Attributes() Attributes {
+	return p.attributes.clone()
+}
+
+// SetAttributes replaces the product's attributes; they are validated against the schemas of its categories by AttributeService
+func (p *Product) SetAttributes(attrs Attributes) {
+	p.attributes = attrs.clone()
+	p.updatedAt = p.now()
+}
+
+// contains reports whether values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
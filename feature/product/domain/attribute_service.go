@@ -0,0 +1,81 @@
+package product
+
+import (
+	"context"
+	"errors"
+)
+
+// AttributeService manages the attribute schemas of categories and the attribute values of products.
+// Attribute changes are saved and published through the product service.
+type AttributeService struct {
+	products *Service
+	schemas  AttributeSchemaRepository
+}
+
+// NewAttributeService creates a new attribute service
+func NewAttributeService(products *Service, schemas AttributeSchemaRepository) *AttributeService {
+	return &AttributeService{
+		products: products,
+		schemas:  schemas,
+	}
+}
+
+// SaveSchema creates or replaces the attribute schema of a category.
+// The attributes already set on products are not checked again; they are validated the next time they are set.
+func (s *AttributeService) SaveSchema(ctx context.Context, schema *AttributeSchema) error {
+	return s.schemas.Save(ctx, schema)
+}
+
+// GetSchema returns the attribute schema of a category
+func (s *AttributeService) GetSchema(ctx context.Context, categoryID CategoryID) (*AttributeSchema, error) {
+	return s.schemas.FindByCategory(ctx, categoryID)
+}
+
+// DeleteSchema removes the attribute schema of a category
+func (s *AttributeService) DeleteSchema(ctx context.Context, categoryID CategoryID) error {
+	return s.schemas.Delete(ctx, categoryID)
+}
+
+// SetAttributes replaces the attributes of a product after validating them against the schemas of its categories
+func (s *AttributeService) SetAttributes(ctx context.Context, productID ProductID, attrs Attributes) (*Product, error) {
+	unlock := s.products.locks.lock(productID)
+	defer unlock()
+
+	product, err := s.products.find(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas, err := s.schemasOf(ctx, product)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateAttributes(attrs, schemas); err != nil {
+		return nil, err
+	}
+
+	product.SetAttributes(attrs)
+	if err := s.products.repo.Save(ctx, product); err != nil {
+		return nil, err
+	}
+
+	s.products.publish(ctx, Event{Type: EventProductUpdated, ProductID: product.ID(), Product: product})
+
+	return product, nil
+}
+
+// schemasOf returns the attribute schemas of the categories of a product; categories without a schema are skipped
+func (s *AttributeService) schemasOf(ctx context.Context, product *Product) ([]*AttributeSchema, error) {
+	schemas := make([]*AttributeSchema, 0, len(product.Categories()))
+	for _, c := range product.Categories() {
+		schema, err := s.schemas.FindByCategory(ctx, c.ID())
+		if errors.Is(err, ErrAttributeSchemaNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
@@ -0,0 +1,127 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrProductUnavailable is returned when a published product is sold outside its availability window
+var ErrProductUnavailable = errors.New("product is not available")
+
+// Availability is the window in which a published product is visible to customers.
+// A zero bound leaves that side of the window open; the zero Availability is always open.
+type Availability struct {
+	from  time.Time
+	until time.Time
+}
+
+// NewAvailability creates an availability window from from, inclusive, to until, exclusive
+func NewAvailability(from, until time.Time) (Availability, error) {
+	if !from.IsZero() && !until.IsZero() && !until.After(from) {
+		return Availability{}, NewValidationError("availableUntil must be after availableFrom")
+	}
+	return Availability{from: from.UTC(), until: until.UTC()}, nil
+}
+
+// From returns when the window opens, or the zero time when it has always been open
+func (a Availability) From() time.Time {
+	return a.from
+}
+
+// Until returns when the window closes, or the zero time when it never closes
+func (a Availability) Until() time.Time {
+	return a.until
+}
+
+// Contains reports whether t is inside the window
+func (a Availability) Contains(t time.Time) bool {
+	return (a.from.IsZero() || !t.Before(a.from)) && (a.until.IsZero() || t.Before(a.until))
+}
+
+// Availability returns the product's availability window
+func (p *Product) Availability() Availability {
+	return p.availability
+}
+
+// SetAvailability replaces the product's availability window
+func (p *Product) SetAvailability(availability Availability) {
+	p.availability = availability
+	p.updatedAt = p.now()
+}
+
+// IsAvailableAt reports whether the product is published and inside its availability window at t
+func (p *Product) IsAvailableAt(t time.Time) bool {
+	return p.IsPublished() && p.availability.Contains(t)
+}
+
+// CheckAvailableAt returns why the product cannot be sold at t, or nil when it can
+func (p *Product) CheckAvailableAt(t time.Time) error {
+	if !p.IsPublished() {
+		return fmt.Errorf("%w: %s", ErrProductNotPublished, p.id)
+	}
+	if !p.availability.Contains(t) {
+		return fmt.Errorf("%w: %s", ErrProductUnavailable, p.id)
+	}
+	return nil
+}
+
+// SetAvailability changes the availability window of a product
+func (s *Service) SetAvailability(ctx context.Context, id ProductID, availability Availability) (*Product, error) {
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	// Find existing product
+	product, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	product.SetAvailability(availability)
+
+	// Save to repository
+	if err := s.repo.Save(ctx, product); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, Event{Type: EventProductUpdated, ProductID: product.ID(), Product: product})
+
+	return product, nil
+}
+
+// AnnounceAvailability publishes EventProductAvailable and EventProductUnavailable for every published product
+// whose availability window opened or closed in (since, until], in the order they happened, and returns them.
+// A window that both opened and closed in the interval yields both events.
+func (s *Service) AnnounceAvailability(ctx context.Context, since, until time.Time) ([]Event, error) {
+	products, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []Event{}
+	passed := func(t time.Time) bool {
+		return !t.IsZero() && t.After(since) && !t.After(until)
+	}
+	for _, p := range products {
+		if !p.IsPublished() {
+			continue
+		}
+		if from := p.Availability().From(); passed(from) {
+			events = append(events, Event{Type: EventProductAvailable, ProductID: p.ID(), Product: p, OccurredAt: from})
+		}
+		if to := p.Availability().Until(); passed(to) {
+			events = append(events, Event{Type: EventProductUnavailable, ProductID: p.ID(), Product: p, OccurredAt: to})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].OccurredAt.Equal(events[j].OccurredAt) {
+			return events[i].OccurredAt.Before(events[j].OccurredAt)
+		}
+		return events[i].ProductID < events[j].ProductID
+	})
+
+	s.publish(ctx, events...)
+	return events, nil
+}
@@ -1,17 +1,21 @@
 package product
 
 import (
-	"errors"
 	"strings"
+
+	"sago-sample/internal/textnorm"
 )
 
+// MaxCategoryNameLength is the maximum length of a category name, in characters
+const MaxCategoryNameLength = 50
+
 // CategoryID represents the unique identifier for a category
 type CategoryID string
 
 // NewCategoryID creates a new CategoryID
 func NewCategoryID(id string) (CategoryID, error) {
 	if strings.TrimSpace(id) == "" {
-		return "", errors.New("category id cannot be empty")
+		return "", NewValidationError("category id cannot be empty")
 	}
 	return CategoryID(id), nil
 }
@@ -29,16 +33,19 @@ func (id CategoryID) IsEmpty() bool {
 // CategoryName represents the name of a category
 type CategoryName string
 
-// NewCategoryName creates a new CategoryName
+// NewCategoryName creates a new CategoryName; the name is normalized to NFC on a single line
 func NewCategoryName(name string) (CategoryName, error) {
-	trimmedName := strings.TrimSpace(name)
-	if trimmedName == "" {
-		return "", errors.New("category name cannot be empty")
+	normalized, err := textnorm.Line(name)
+	if err != nil {
+		return "", NewValidationError("category name " + err.Error())
+	}
+	if normalized == "" {
+		return "", NewValidationError("category name cannot be empty")
 	}
-	if len(trimmedName) > 50 {
-		return "", errors.New("category name cannot exceed 50 characters")
+	if textnorm.Length(normalized) > MaxCategoryNameLength {
+		return "", NewValidationError("category name cannot exceed 50 characters")
 	}
-	return CategoryName(trimmedName), nil
+	return CategoryName(normalized), nil
 }
 
 // String returns the string representation of the CategoryName
@@ -60,10 +67,10 @@ type Category struct {
 // NewCategory creates a new Category
 func NewCategory(id CategoryID, name CategoryName) (*Category, error) {
 	if id.IsEmpty() {
-		return nil, errors.New("category id cannot be empty")
+		return nil, NewValidationError("category id cannot be empty")
 	}
 	if name.IsEmpty() {
-		return nil, errors.New("category name cannot be empty")
+		return nil, NewValidationError("category name cannot be empty")
 	}
 	return &Category{
 		id:   id,
@@ -84,7 +91,7 @@ func (c *Category) Name() CategoryName {
 // UpdateName updates the category's name
 func (c *Category) UpdateName(name CategoryName) error {
 	if name.IsEmpty() {
-		return errors.New("category name cannot be empty")
+		return NewValidationError("category name cannot be empty")
 	}
 	c.name = name
 	return nil
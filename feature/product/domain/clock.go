@@ -0,0 +1,19 @@
+package product
+
+import "time"
+
+// Clock tells the current time; tests inject a fake one to control the timestamps and availability windows
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock
+type ClockFunc func() time.Time
+
+// Now calls f
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the wall clock, used when no other clock is injected
+var SystemClock Clock = ClockFunc(time.Now)
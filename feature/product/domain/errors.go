@@ -0,0 +1,29 @@
+package product
+
+import (
+	"errors"
+)
+
+// ErrInsufficientStock is returned when a stock decrease exceeds the available quantity
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// ValidationError reports that a value did not satisfy a domain rule
+type ValidationError struct {
+	msg string
+}
+
+// Error returns the validation message
+func (e *ValidationError) Error() string {
+	return e.msg
+}
+
+// NewValidationError creates a new ValidationError with the given message
+func NewValidationError(msg string) error {
+	return &ValidationError{msg: msg}
+}
+
+// IsValidationError checks if err is or wraps a ValidationError
+func IsValidationError(err error) bool {
+	var ve *ValidationError
+	return errors.As(err, &ve)
+}
@@ -0,0 +1,86 @@
+package product
+
+import (
+	"context"
+	"time"
+)
+
+// EventType identifies the kind of change described by an Event
+type EventType string
+
+const (
+	EventProductCreated  EventType = "product.created"
+	EventProductUpdated  EventType = "product.updated"
+	EventProductDeleted  EventType = "product.deleted"
+	EventPriceChanged    EventType = "product.price_changed"
+	EventStockChanged    EventType = "product.stock_changed"
+	EventCategoryAdded   EventType = "product.category_added"
+	EventCategoryRemoved EventType = "product.category_removed"
+	EventStatusChanged   EventType = "product.status_changed"
+	// EventProductAvailable and EventProductUnavailable are published by the scheduler when the availability window
+	// of a published product opens or closes; OccurredAt is the bound of the window
+	EventProductAvailable   EventType = "product.available"
+	EventProductUnavailable EventType = "product.unavailable"
+)
+
+// EventTypes lists every event type emitted by the Service
+var EventTypes = []EventType{
+	EventProductCreated,
+	EventProductUpdated,
+	EventProductDeleted,
+	EventPriceChanged,
+	EventStockChanged,
+	EventCategoryAdded,
+	EventCategoryRemoved,
+	EventStatusChanged,
+	EventProductAvailable,
+	EventProductUnavailable,
+}
+
+// IsValid checks if the event type is one emitted by the Service
+func (t EventType) IsValid() bool {
+	for _, et := range EventTypes {
+		if t == et {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the string representation of the EventType
+func (t EventType) String() string {
+	return string(t)
+}
+
+// Event describes a change made to a product through the Service
+type Event struct {
+	Type      EventType
+	ProductID ProductID
+	// Product is the product after the change, or the deleted product for EventProductDeleted
+	Product *Product
+	// PreviousPrice is set for EventPriceChanged
+	PreviousPrice Price
+	// PreviousStock is set for EventStockChanged
+	PreviousStock Stock
+	// CategoryID is set for EventCategoryAdded and EventCategoryRemoved
+	CategoryID CategoryID
+	// PreviousStatus is set for EventStatusChanged
+	PreviousStatus ProductStatus
+	// Movements records the stock changes of the event, one per warehouse whose stock changed
+	Movements  []Movement
+	OccurredAt time.Time
+}
+
+// EventHandler receives the events emitted by the Service.
+// HandleEvent is called synchronously after the change has been saved, so it must not block.
+type EventHandler interface {
+	HandleEvent(ctx context.Context, event Event)
+}
+
+// EventHandlerFunc adapts a function to the EventHandler interface
+type EventHandlerFunc func(ctx context.Context, event Event)
+
+// HandleEvent calls f(ctx, event)
+func (f EventHandlerFunc) HandleEvent(ctx context.Context, event Event) {
+	f(ctx, event)
+}
@@ -0,0 +1,238 @@
+package product
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DefaultPriceBounds are the boundaries of the default price buckets, in minor units
+var DefaultPriceBounds = []uint{1000, 5000, 10000, 50000}
+
+// PriceBucket is the price range [From, To) in minor units; To is zero for the last, unbounded bucket
+type PriceBucket struct {
+	From uint
+	To   uint
+}
+
+// NewPriceBuckets splits prices at bounds, which must be positive and increasing:
+// the bounds 1000 and 5000 give the buckets [0, 1000), [1000, 5000) and [5000, ∞)
+func NewPriceBuckets(bounds []uint) ([]PriceBucket, error) {
+	buckets := make([]PriceBucket, 0, len(bounds)+1)
+	var from uint
+	for _, b := range bounds {
+		if b <= from {
+			return nil, NewValidationError(fmt.Sprintf("price bucket bounds must be positive and increasing, got %v", bounds))
+		}
+		buckets = append(buckets, PriceBucket{From: from, To: b})
+		from = b
+	}
+	return append(buckets, PriceBucket{From: from}), nil
+}
+
+// Contains reports whether amount falls in the bucket
+func (b PriceBucket) Contains(amount uint) bool {
+	return amount >= b.From && (b.To == 0 || amount < b.To)
+}
+
+// FacetQuery selects the products counted by Repository.Facets.
+// Status, AvailableAt and Currency restrict every count. CategoryID, the price range, InStock and Attributes are the
+// filters the facets drill into: each facet is counted with every filter except its own, so the counts tell how many
+// products each other choice of that facet would give.
+type FacetQuery struct {
+	// Status, when set, only counts the products with this status
+	Status ProductStatus
+	// AvailableAt, when set, only counts the products whose availability window contains it
+	AvailableAt time.Time
+	// Currency, when set, only counts the products priced in this currency
+	Currency string
+
+	// CategoryID, when set, only keeps the products of this category
+	CategoryID CategoryID
+	// MinPrice and MaxPrice bound the price, inclusive; a zero MaxPrice leaves it unbounded
+	MinPrice uint
+	MaxPrice uint
+	// InStock, when set, only keeps the products with (true) or without (false) stock
+	InStock *bool
+	// Attributes only keeps the products whose attributes have each of these values, as in Attributes.Matches
+	Attributes map[string]string
+
+	// PriceBuckets are the price ranges counted by the price facet
+	PriceBuckets []PriceBucket
+}
+
+// matchesBase reports whether p is counted at all
+func (q FacetQuery) matchesBase(p *Product) bool {
+	if q.Status != "" && p.Status() != q.Status {
+		return false
+	}
+	if !q.AvailableAt.IsZero() && !p.Availability().Contains(q.AvailableAt) {
+		return false
+	}
+	return q.Currency == "" || p.Price().Currency() == q.Currency
+}
+
+// failedFilters returns the facets whose filter p does not pass, stopping after two
+func (q FacetQuery) failedFilters(p *Product) []string {
+	var failed []string
+	if !q.CategoryID.IsEmpty() && !p.HasCategory(q.CategoryID) {
+		failed = append(failed, facetCategory)
+	}
+	if amount := p.Price().Amount(); amount < q.MinPrice || (q.MaxPrice > 0 && amount > q.MaxPrice) {
+		failed = append(failed, facetPrice)
+	}
+	if q.InStock != nil && (p.Stock().Quantity() > 0) != *q.InStock {
+		failed = append(failed, facetStock)
+	}
+	attributes := p.attributes
+	for key, value := range q.Attributes {
+		if len(failed) > 1 {
+			break
+		}
+		if !attributes.Matches(key, value) {
+			failed = append(failed, facetAttribute+key)
+		}
+	}
+	return failed
+}
+
+// Facet names used while counting
+const (
+	facetCategory  = "category"
+	facetPrice     = "price"
+	facetStock     = "stock"
+	facetAttribute = "attr."
+)
+
+// Facets are the product counts of a FacetQuery
+type Facets struct {
+	// Total is the number of products passing every filter
+	Total int
+	// Categories are ordered by ID
+	Categories []CategoryCount
+	// Prices has one entry per bucket of the query, in order
+	Prices []PriceCount
+	Stock  StockCount
+	// Attributes are ordered by key
+	Attributes []AttributeCount
+}
+
+// CategoryCount is the number of products in a category
+type CategoryCount struct {
+	ID    CategoryID
+	Name  CategoryName
+	Count int
+}
+
+// PriceCount is the number of products priced in a bucket
+type PriceCount struct {
+	Bucket PriceBucket
+	Count  int
+}
+
+// StockCount is the number of products with and without stock
+type StockCount struct {
+	InStock    int
+	OutOfStock int
+}
+
+// AttributeCount is the number of products per value of an attribute
+type AttributeCount struct {
+	Key string
+	// Values are ordered by value
+	Values []AttributeValueCount
+}
+
+// AttributeValueCount is the number of products having an attribute value, written as in Attributes.Matches
+type AttributeValueCount struct {
+	Value string
+	Count int
+}
+
+// CountFacets counts the facets of products in a single pass; it serves the repositories without aggregate queries.
+// A product failing exactly one filter is only counted in the facet of that filter.
+func CountFacets(products []*Product, query FacetQuery) *Facets {
+	facets := &Facets{Prices: make([]PriceCount, len(query.PriceBuckets))}
+	for i, b := range query.PriceBuckets {
+		facets.Prices[i].Bucket = b
+	}
+	categories := make(map[CategoryID]*CategoryCount)
+	attributes := make(map[string]map[string]int)
+
+	for _, p := range products {
+		if !query.matchesBase(p) {
+			continue
+		}
+		failed := query.failedFilters(p)
+		if len(failed) > 1 {
+			continue
+		}
+		counts := func(facet string) bool { return len(failed) == 0 || failed[0] == facet }
+		if len(failed) == 0 {
+			facets.Total++
+		}
+
+		if counts(facetCategory) {
+			for _, c := range p.Categories() {
+				if categories[c.ID()] == nil {
+					categories[c.ID()] = &CategoryCount{ID: c.ID(), Name: c.Name()}
+				}
+				categories[c.ID()].Count++
+			}
+		}
+		if counts(facetPrice) {
+			for i := range facets.Prices {
+				if facets.Prices[i].Bucket.Contains(p.Price().Amount()) {
+					facets.Prices[i].Count++
+					break
+				}
+			}
+		}
+		if counts(facetStock) {
+			if p.Stock().Quantity() > 0 {
+				facets.Stock.InStock++
+			} else {
+				facets.Stock.OutOfStock++
+			}
+		}
+		for key, value := range p.attributes {
+			if !counts(facetAttribute + key) {
+				continue
+			}
+			if attributes[key] == nil {
+				attributes[key] = make(map[string]int)
+			}
+			attributes[key][AttributeText(value)]++
+		}
+	}
+
+	for _, c := range categories {
+		facets.Categories = append(facets.Categories, *c)
+	}
+	sort.Slice(facets.Categories, func(i, j int) bool { return facets.Categories[i].ID < facets.Categories[j].ID })
+	for key, values := range attributes {
+		count := AttributeCount{Key: key}
+		for value, n := range values {
+			count.Values = append(count.Values, AttributeValueCount{Value: value, Count: n})
+		}
+		sort.Slice(count.Values, func(i, j int) bool { return count.Values[i].Value < count.Values[j].Value })
+		facets.Attributes = append(facets.Attributes, count)
+	}
+	sort.Slice(facets.Attributes, func(i, j int) bool { return facets.Attributes[i].Key < facets.Attributes[j].Key })
+	return facets
+}
+
+// AttributeText writes an attribute value as text: numbers without exponent or trailing zeros, booleans as true or false
+func AttributeText(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,188 @@
+package product
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxImagesPerProduct is the maximum number of images of a product
+const MaxImagesPerProduct = 20
+
+// ErrImageNotFound is returned when a product has no image with the requested ID
+var ErrImageNotFound = errors.New("image not found")
+
+// ImageID represents the unique identifier of a product image
+type ImageID string
+
+// String returns the string representation of the ImageID
+func (id ImageID) String() string {
+	return string(id)
+}
+
+// newImageID returns a random image ID
+func newImageID() ImageID {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return ImageID(hex.EncodeToString(b))
+}
+
+// Image is an image of a product; the image and its thumbnail are stored in a BlobStore under their keys
+type Image struct {
+	id           ImageID
+	key          string
+	thumbnailKey string
+	contentType  string
+	size         int64
+	width        int
+	height       int
+	createdAt    time.Time
+}
+
+// RestoreImage rebuilds an Image from persisted state.
+// It is meant for repositories; new images are added with MediaService.UploadImage.
+func RestoreImage(id ImageID, key, thumbnailKey, contentType string, size int64, width, height int, createdAt time.Time) Image {
+	return Image{
+		id:           id,
+		key:          key,
+		thumbnailKey: thumbnailKey,
+		contentType:  contentType,
+		size:         size,
+		width:        width,
+		height:       height,
+		createdAt:    createdAt,
+	}
+}
+
+// ID returns the image's ID
+func (i Image) ID() ImageID {
+	return i.id
+}
+
+// Key returns the blob key of the image
+func (i Image) Key() string {
+	return i.key
+}
+
+// ThumbnailKey returns the blob key of the thumbnail
+func (i Image) ThumbnailKey() string {
+	return i.thumbnailKey
+}
+
+// ContentType returns the media type of the image, e.g. image/png; the thumbnail has the same one
+func (i Image) ContentType() string {
+	return i.contentType
+}
+
+// Size returns the size of the image in bytes
+func (i Image) Size() int64 {
+	return i.size
+}
+
+// Width returns the width of the image in pixels
+func (i Image) Width() int {
+	return i.width
+}
+
+// Height returns the height of the image in pixels
+func (i Image) Height() int {
+	return i.height
+}
+
+// CreatedAt returns the upload time of the image
+func (i Image) CreatedAt() time.Time {
+	return i.createdAt
+}
+
+// Images returns a copy of the product's images in display order; the first one is the primary image
+func (p *Product) Images() []Image {
+	return append([]Image(nil), p.images...)
+}
+
+// PrimaryImage returns the first image of the product, or false when it has none
+func (p *Product) PrimaryImage() (Image, bool) {
+	if len(p.images) == 0 {
+		return Image{}, false
+	}
+	return p.images[0], true
+}
+
+// Image returns the image of the product with the given ID
+func (p *Product) Image(id ImageID) (Image, error) {
+	for _, img := range p.images {
+		if img.id == id {
+			return img, nil
+		}
+	}
+	return Image{}, ErrImageNotFound
+}
+
+// AddImage appends an image to the product; the first image added becomes the primary one
+func (p *Product) AddImage(img Image) error {
+	if len(p.images) >= MaxImagesPerProduct {
+		return NewValidationError(fmt.Sprintf("a product cannot have more than %d images", MaxImagesPerProduct))
+	}
+	p.images = append(p.images, img)
+	p.updatedAt = p.now()
+	return nil
+}
+
+// RemoveImage removes an image from the product and returns it; the next image becomes primary when the primary one is removed
+func (p *Product) RemoveImage(id ImageID) (Image, error) {
+	for i, img := range p.images {
+		if img.id == id {
+			p.images = append(p.images[:i:i], p.images[i+1:]...)
+			p.updatedAt = p.now()
+			return img, nil
+		}
+	}
+	return Image{}, ErrImageNotFound
+}
+
+// resetImages replaces the images of the product, e.g. to undo a change that could not be saved
+func (p *Product) resetImages(images []Image) {
+	p.images = images
+}
+
+// ReorderImages puts the images in the order of ids, which must list every image of the product exactly once.
+// The first one becomes the primary image.
+func (p *Product) ReorderImages(ids []ImageID) error {
+	if len(ids) != len(p.images) {
+		return NewValidationError(fmt.Sprintf("image order must list the %d images of the product", len(p.images)))
+	}
+
+	byID := make(map[ImageID]Image, len(p.images))
+	for _, img := range p.images {
+		byID[img.id] = img
+	}
+	ordered := make([]Image, 0, len(ids))
+	for _, id := range ids {
+		img, ok := byID[id]
+		if !ok {
+			return NewValidationError(fmt.Sprintf("image %s is not an image of the product or is listed twice", id))
+		}
+		delete(byID, id)
+		ordered = append(ordered, img)
+	}
+
+	p.images = ordered
+	p.updatedAt = p.now()
+	return nil
+}
+
+// SetPrimaryImage moves an image to the front of the product's images, keeping the order of the others
+func (p *Product) SetPrimaryImage(id ImageID) error {
+	for i, img := range p.images {
+		if img.id == id {
+			copy(p.images[1:i+1], p.images[:i])
+			p.images[0] = img
+			p.updatedAt = p.now()
+			return nil
+		}
+	}
+	return ErrImageNotFound
+}
@@ -0,0 +1,193 @@
+package product
+
+import (
+	"math"
+	"sort"
+)
+
+// StockLevel is the quantity of a product held in one warehouse
+type StockLevel struct {
+	warehouseID WarehouseID
+	quantity    uint
+}
+
+// NewStockLevel creates a new StockLevel
+func NewStockLevel(warehouseID WarehouseID, quantity uint) StockLevel {
+	return StockLevel{warehouseID: warehouseID, quantity: quantity}
+}
+
+// WarehouseID returns the warehouse holding the stock
+func (l StockLevel) WarehouseID() WarehouseID {
+	return l.warehouseID
+}
+
+// Quantity returns the quantity held in the warehouse
+func (l StockLevel) Quantity() uint {
+	return l.quantity
+}
+
+// AllocationStrategy decides which warehouses a stock decrement is taken from
+type AllocationStrategy interface {
+	// Rank returns the warehouses to draw from, most preferred first.
+	// levels only contains warehouses holding stock; warehouses maps the known warehouses by ID.
+	Rank(levels []StockLevel, warehouses map[WarehouseID]*Warehouse) []WarehouseID
+}
+
+// MostStockStrategy draws from the warehouses holding the most stock first
+type MostStockStrategy struct{}
+
+// Rank implements AllocationStrategy
+func (MostStockStrategy) Rank(levels []StockLevel, _ map[WarehouseID]*Warehouse) []WarehouseID {
+	sorted := append([]StockLevel(nil), levels...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].quantity > sorted[j].quantity
+	})
+	return warehouseIDs(sorted)
+}
+
+// NearestStrategy draws from the warehouses closest to a destination first.
+// Warehouses without a location are used last.
+type NearestStrategy struct {
+	Destination Location
+}
+
+// Rank implements AllocationStrategy
+func (s NearestStrategy) Rank(levels []StockLevel, warehouses map[WarehouseID]*Warehouse) []WarehouseID {
+	distance := func(id WarehouseID) float64 {
+		if w, ok := warehouses[id]; ok && w.Location() != nil {
+			return s.Destination.DistanceKm(*w.Location())
+		}
+		return math.Inf(1)
+	}
+
+	sorted := append([]StockLevel(nil), levels...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return distance(sorted[i].warehouseID) < distance(sorted[j].warehouseID)
+	})
+	return warehouseIDs(sorted)
+}
+
+// PriorityStrategy draws from the listed warehouses in order; unlisted warehouses are not used
+type PriorityStrategy struct {
+	Warehouses []WarehouseID
+}
+
+// Rank implements AllocationStrategy
+func (s PriorityStrategy) Rank(_ []StockLevel, _ map[WarehouseID]*Warehouse) []WarehouseID {
+	return s.Warehouses
+}
+
+// warehouseIDs returns the warehouses of levels in order
+func warehouseIDs(levels []StockLevel) []WarehouseID {
+	ids := make([]WarehouseID, 0, len(levels))
+	for _, l := range levels {
+		ids = append(ids, l.warehouseID)
+	}
+	return ids
+}
+
+// StockLevels returns the non-empty stock levels of the product, ordered by warehouse ID
+func (p *Product) StockLevels() []StockLevel {
+	levels := make([]StockLevel, 0, len(p.stock))
+	for id, quantity := range p.stock {
+		if quantity > 0 {
+			levels = append(levels, StockLevel{warehouseID: id, quantity: quantity})
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].warehouseID < levels[j].warehouseID
+	})
+	return levels
+}
+
+// StockIn returns the product's stock in a warehouse
+func (p *Product) StockIn(warehouseID WarehouseID) Stock {
+	return NewStock(p.stock[warehouseID])
+}
+
+// SetStockLevel replaces the product's stock in a warehouse, e.g. after a receipt or a count
+func (p *Product) SetStockLevel(warehouseID WarehouseID, stock Stock) {
+	if stock.Quantity() == 0 {
+		delete(p.stock, warehouseID)
+	} else {
+		p.stock[warehouseID] = stock.Quantity()
+	}
+	p.updatedAt = p.now()
+}
+
+// TransferStock moves a quantity of the product between two warehouses.
+// Either the whole quantity is moved or, on error, nothing is.
+func (p *Product) TransferStock(from, to WarehouseID, quantity uint) error {
+	if quantity == 0 {
+		return NewValidationError("transfer quantity must be positive")
+	}
+	if from == to {
+		return NewValidationError("cannot transfer stock to the same warehouse")
+	}
+	if p.stock[from] < quantity {
+		return ErrInsufficientStock
+	}
+
+	p.SetStockLevel(from, NewStock(p.stock[from]-quantity))
+	p.SetStockLevel(to, NewStock(p.stock[to]+quantity))
+	return nil
+}
+
+// AllocateStock decreases the product's stock by quantity, drawing from the warehouses in the order the strategy ranks them.
+// It returns the quantity taken from each warehouse. When the ranked warehouses cannot cover the quantity,
+// ErrInsufficientStock is returned and the stock is left unchanged.
+func (p *Product) AllocateStock(quantity uint, strategy AllocationStrategy, warehouses map[WarehouseID]*Warehouse) ([]StockLevel, error) {
+	if quantity == 0 {
+		return nil, NewValidationError("allocation quantity must be positive")
+	}
+	return p.decrease(quantity, strategy.Rank(p.StockLevels(), warehouses))
+}
+
+// decrease draws quantity from the warehouses in order, all or nothing
+func (p *Product) decrease(quantity uint, order []WarehouseID) ([]StockLevel, error) {
+	var allocations []StockLevel
+	remaining := quantity
+	seen := make(map[WarehouseID]bool, len(order))
+	for _, id := range order {
+		if remaining == 0 {
+			break
+		}
+		if seen[id] || p.stock[id] == 0 {
+			continue
+		}
+		seen[id] = true
+
+		take := min(remaining, p.stock[id])
+		allocations = append(allocations, StockLevel{warehouseID: id, quantity: take})
+		remaining -= take
+	}
+	if remaining > 0 {
+		return nil, ErrInsufficientStock
+	}
+
+	for _, a := range allocations {
+		p.SetStockLevel(a.warehouseID, NewStock(p.stock[a.warehouseID]-a.quantity))
+	}
+	return allocations, nil
+}
+
+// resetStock replaces every stock level of the product, e.g. to undo a change that could not be saved
+func (p *Product) resetStock(levels []StockLevel) {
+	p.stock = make(map[WarehouseID]uint, len(levels))
+	for _, l := range levels {
+		if l.quantity > 0 {
+			p.stock[l.warehouseID] = l.quantity
+		}
+	}
+}
+
+// defaultOrder is the order stock is removed in when no warehouse is chosen: the default warehouse first, then by ID
+func (p *Product) defaultOrder() []WarehouseID {
+	order := []WarehouseID{DefaultWarehouseID}
+	for _, l := range p.StockLevels() {
+		if l.warehouseID != DefaultWarehouseID {
+			order = append(order, l.warehouseID)
+		}
+	}
+	return order
+}
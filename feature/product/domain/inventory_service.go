@@ -0,0 +1,323 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"sago-sample/internal/textnorm"
+)
+
+// InventoryService provides the warehouse operations and the per-warehouse stock operations of products.
+// Stock changes are saved with the product and its ledger movements in one transaction and published through the product service.
+type InventoryService struct {
+	products   *Service
+	warehouses WarehouseRepository
+}
+
+// NewInventoryService creates a new inventory service
+func NewInventoryService(products *Service, warehouses WarehouseRepository) *InventoryService {
+	return &InventoryService{
+		products:   products,
+		warehouses: warehouses,
+	}
+}
+
+// CreateWarehouse creates a new warehouse
+func (s *InventoryService) CreateWarehouse(ctx context.Context, id WarehouseID, name WarehouseName, location *Location) (*Warehouse, error) {
+	existing, err := s.warehouses.FindByID(ctx, id)
+	if err != nil && !errors.Is(err, ErrWarehouseNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrWarehouseExists
+	}
+
+	warehouse, err := NewWarehouse(id, name, location)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.warehouses.Save(ctx, warehouse); err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+// UpdateWarehouse replaces the name and location of a warehouse
+func (s *InventoryService) UpdateWarehouse(ctx context.Context, id WarehouseID, name WarehouseName, location *Location) (*Warehouse, error) {
+	warehouse, err := s.warehouses.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouse.Update(name, location)
+	if err := s.warehouses.Save(ctx, warehouse); err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+// DeleteWarehouse deletes a warehouse; the default warehouse and warehouses still holding stock cannot be deleted.
+// The check and the delete run in one transaction, and the repository refuses to delete a warehouse whose stock
+// was received meanwhile with ErrWarehouseInUse as well.
+func (s *InventoryService) DeleteWarehouse(ctx context.Context, id WarehouseID) error {
+	if id == DefaultWarehouseID {
+		return ErrWarehouseInUse
+	}
+
+	return s.products.transaction(ctx, func(ctx context.Context) error {
+		if _, err := s.warehouses.FindByID(ctx, id); err != nil {
+			return err
+		}
+
+		products, err := s.products.repo.FindAll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, p := range products {
+			if p.StockIn(id).IsAvailable() {
+				return ErrWarehouseInUse
+			}
+		}
+
+		return s.warehouses.Delete(ctx, id)
+	})
+}
+
+// GetWarehouse retrieves a warehouse by ID
+func (s *InventoryService) GetWarehouse(ctx context.Context, id WarehouseID) (*Warehouse, error) {
+	return s.warehouses.FindByID(ctx, id)
+}
+
+// GetAllWarehouses retrieves all warehouses
+func (s *InventoryService) GetAllWarehouses(ctx context.Context) ([]*Warehouse, error) {
+	return s.warehouses.FindAll(ctx)
+}
+
+// SetStockLevel replaces the stock of a product in a warehouse
+func (s *InventoryService) SetStockLevel(ctx context.Context, productID ProductID, warehouseID WarehouseID, stock Stock) (*Product, error) {
+	if _, err := s.warehouses.FindByID(ctx, warehouseID); err != nil {
+		return nil, err
+	}
+
+	return s.change(ctx, productID, MovementAdjustment, "stock level set", func(p *Product) error {
+		p.SetStockLevel(warehouseID, stock)
+		return nil
+	})
+}
+
+// TransferStock moves a quantity of a product between two warehouses
+func (s *InventoryService) TransferStock(ctx context.Context, productID ProductID, from, to WarehouseID, quantity uint) (*Product, error) {
+	for _, id := range []WarehouseID{from, to} {
+		if _, err := s.warehouses.FindByID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	reason := "transfer from " + from.String() + " to " + to.String()
+	return s.change(ctx, productID, MovementTransfer, reason, func(p *Product) error {
+		return p.TransferStock(from, to, quantity)
+	})
+}
+
+// AllocateStock decreases the stock of a product, drawing from the warehouses ranked by the strategy.
+// It returns the quantity taken from each warehouse.
+func (s *InventoryService) AllocateStock(ctx context.Context, productID ProductID, quantity uint, strategy AllocationStrategy) (*Product, []StockLevel, error) {
+	warehouses, err := s.warehouses.FindAll(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[WarehouseID]*Warehouse, len(warehouses))
+	for _, w := range warehouses {
+		byID[w.ID()] = w
+	}
+
+	var allocations []StockLevel
+	p, err := s.change(ctx, productID, MovementSale, "allocation", func(p *Product) (err error) {
+		allocations, err = p.AllocateStock(quantity, strategy, byID)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, allocations, nil
+}
+
+// RecordMovement changes the stock of a product in a warehouse by a signed quantity, recording it in the ledger as a movement of the given type.
+// Receipts and returns must be positive, sales and reservations negative; transfers are made with TransferStock.
+func (s *InventoryService) RecordMovement(ctx context.Context, productID ProductID, warehouseID WarehouseID, movementType MovementType, quantity int, reason string) (*Product, error) {
+	reason, err := textnorm.Line(reason)
+	if err != nil {
+		return nil, NewValidationError("movement reason " + err.Error())
+	}
+	switch {
+	case quantity == 0:
+		return nil, NewValidationError("movement quantity cannot be zero")
+	case movementType == MovementTransfer:
+		return nil, NewValidationError("transfers are recorded by transferring stock between warehouses")
+	case movementType.Increases() && quantity < 0:
+		return nil, NewValidationError(movementType.String() + " movements must increase the stock")
+	case movementType.Decreases() && quantity > 0:
+		return nil, NewValidationError(movementType.String() + " movements must decrease the stock")
+	case movementType == MovementAdjustment && reason == "":
+		return nil, NewValidationError("adjustments require a reason")
+	case textnorm.Length(reason) > MaxMovementReasonLength:
+		return nil, NewValidationError("movement reason cannot exceed 255 characters")
+	}
+	if _, err := s.warehouses.FindByID(ctx, warehouseID); err != nil {
+		return nil, err
+	}
+
+	return s.change(ctx, productID, movementType, reason, func(p *Product) error {
+		current := p.StockIn(warehouseID).Quantity()
+		if quantity < 0 && uint(-quantity) > current {
+			return ErrInsufficientStock
+		}
+		p.SetStockLevel(warehouseID, NewStock(uint(int(current)+quantity)))
+		return nil
+	})
+}
+
+// TakeStock decreases the stock of several products at once, e.g. for an order, taking it from the default warehouse first.
+// Either every quantity is taken or, on error, none is: ErrInsufficientStock names the first product that falls short.
+func (s *InventoryService) TakeStock(ctx context.Context, quantities map[ProductID]uint, movementType MovementType, reason string) ([]*Product, error) {
+	return s.changeAll(ctx, quantities, true, movementType, reason)
+}
+
+// ReturnStock increases the stock of several products at once in the default warehouse, e.g. when an order is cancelled.
+// Products that no longer exist are skipped.
+func (s *InventoryService) ReturnStock(ctx context.Context, quantities map[ProductID]uint, movementType MovementType, reason string) ([]*Product, error) {
+	return s.changeAll(ctx, quantities, false, movementType, reason)
+}
+
+// changeAll takes or returns stock of several products under their locks. Nothing changes unless every product can change:
+// the products are read, checked and saved with their movements in one transaction.
+func (s *InventoryService) changeAll(ctx context.Context, quantities map[ProductID]uint, take bool, movementType MovementType, reason string) ([]*Product, error) {
+	ids := make([]ProductID, 0, len(quantities))
+	for id := range quantities {
+		ids = append(ids, id)
+	}
+	unlock := s.products.locks.lockAll(ids)
+	defer unlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	type snapshot struct {
+		product *Product
+		stock   Stock
+		levels  []StockLevel
+	}
+	var snapshots []snapshot
+	var events []Event
+	err := s.products.transaction(ctx, func(ctx context.Context) error {
+		snapshots = make([]snapshot, 0, len(ids))
+		for _, id := range ids {
+			p, err := s.products.find(ctx, id)
+			if errors.Is(err, ErrProductNotFound) && !take {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if take && p.Stock().Quantity() < quantities[id] {
+				return fmt.Errorf("%w: product %s has %d", ErrInsufficientStock, id, p.Stock().Quantity())
+			}
+			snapshots = append(snapshots, snapshot{product: p, stock: p.Stock(), levels: p.StockLevels()})
+		}
+
+		var movements []Movement
+		for _, snap := range snapshots {
+			p := snap.product
+			// Cannot fail: every product was checked above
+			if take {
+				_ = p.DecreaseStock(quantities[p.ID()])
+			} else {
+				p.IncreaseStock(quantities[p.ID()])
+			}
+			if err := s.products.repo.Save(ctx, p); err != nil {
+				return err
+			}
+			if p.Stock() == snap.stock {
+				continue
+			}
+			event := Event{
+				Type:          EventStockChanged,
+				ProductID:     p.ID(),
+				Product:       p,
+				PreviousStock: snap.stock,
+				Movements:     movementsBetween(ctx, s.products.Clock(), p.ID(), snap.levels, p.StockLevels(), movementType, reason),
+			}
+			events = append(events, event)
+			movements = append(movements, event.Movements...)
+		}
+		return s.products.record(ctx, movements)
+	})
+	if err != nil {
+		// The transaction is rolled back; products held in memory are restored here
+		for _, undo := range snapshots {
+			undo.product.resetStock(undo.levels)
+		}
+		return nil, err
+	}
+
+	products := make([]*Product, 0, len(snapshots))
+	for _, snap := range snapshots {
+		products = append(products, snap.product)
+	}
+	s.products.publish(ctx, events...)
+	return products, nil
+}
+
+// change applies fn to a product under its lock and saves it, recording the stock changes as movements of the given type.
+// A change of the total stock is published as EventStockChanged, any other change as EventProductUpdated.
+func (s *InventoryService) change(ctx context.Context, productID ProductID, movementType MovementType, reason string, fn func(p *Product) error) (*Product, error) {
+	unlock := s.products.locks.lock(productID)
+	defer unlock()
+
+	var product *Product
+	var event Event
+	err := s.products.transaction(ctx, func(ctx context.Context) error {
+		var err error
+		product, err = s.products.find(ctx, productID)
+		if err != nil {
+			return err
+		}
+
+		previousStock := product.Stock()
+		previousLevels := product.StockLevels()
+		if err := fn(product); err != nil {
+			return err
+		}
+
+		movements := movementsBetween(ctx, s.products.Clock(), product.ID(), previousLevels, product.StockLevels(), movementType, reason)
+		if err := s.products.repo.Save(ctx, product); err != nil {
+			return err
+		}
+		if err := s.products.record(ctx, movements); err != nil {
+			return err
+		}
+
+		event = Event{
+			Type:      EventProductUpdated,
+			ProductID: product.ID(),
+			Product:   product,
+			Movements: movements,
+		}
+		if product.Stock() != previousStock {
+			event.Type = EventStockChanged
+			event.PreviousStock = previousStock
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.products.publish(ctx, event)
+	return product, nil
+}
+
+// Transaction runs fn in one transaction with the stock changes it makes through the service,
+// so that a caller can save its own changes, e.g. an order, together with them. The events are published once it commits.
+func (s *InventoryService) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.products.transaction(ctx, fn)
+}
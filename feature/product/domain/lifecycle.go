@@ -0,0 +1,114 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidStatusTransition is returned when a product cannot move to the requested status, e.g. archiving an archived product
+	ErrInvalidStatusTransition = errors.New("invalid product status transition")
+	// ErrNotPublishable is returned when a product lacks the data required to publish it
+	ErrNotPublishable = errors.New("product cannot be published")
+	// ErrProductNotPublished is returned when a product that is not published is sold, e.g. ordered or added to a cart
+	ErrProductNotPublished = errors.New("product is not published")
+)
+
+// ProductStatus is the stage of a product in its lifecycle; only published products are visible to customers
+type ProductStatus string
+
+const (
+	// StatusDraft is a product being prepared; new products start as drafts
+	StatusDraft ProductStatus = "draft"
+	// StatusPublished is a product visible to customers
+	StatusPublished ProductStatus = "published"
+	// StatusArchived is a product withdrawn from sale, kept for its history
+	StatusArchived ProductStatus = "archived"
+)
+
+// ProductStatuses lists every product status
+var ProductStatuses = []ProductStatus{StatusDraft, StatusPublished, StatusArchived}
+
+// statusTransitions lists the statuses each status can move to
+var statusTransitions = map[ProductStatus][]ProductStatus{
+	StatusDraft:     {StatusPublished, StatusArchived},
+	StatusPublished: {StatusDraft, StatusArchived},
+	StatusArchived:  {StatusDraft},
+}
+
+// NewProductStatus creates a new ProductStatus with validation
+func NewProductStatus(s string) (ProductStatus, error) {
+	for _, status := range ProductStatuses {
+		if ProductStatus(s) == status {
+			return status, nil
+		}
+	}
+	return "", NewValidationError(fmt.Sprintf("unknown product status %q", s))
+}
+
+// String returns the string representation of the ProductStatus
+func (s ProductStatus) String() string {
+	return string(s)
+}
+
+// CanTransitionTo reports whether a product can move from s to next
+func (s ProductStatus) CanTransitionTo(next ProductStatus) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// PublishGuard returns why a product cannot be published, or "" when it can
+type PublishGuard func(p *Product) string
+
+// DefaultPublishGuards require a product to belong to a category and to have stock
+var DefaultPublishGuards = []PublishGuard{
+	func(p *Product) string {
+		if len(p.categories) == 0 {
+			return "it has no category"
+		}
+		return ""
+	},
+	func(p *Product) string {
+		if !p.Stock().IsAvailable() {
+			return "it has no stock"
+		}
+		return ""
+	},
+}
+
+// Status returns the product's lifecycle status
+func (p *Product) Status() ProductStatus {
+	return p.status
+}
+
+// IsPublished reports whether the product is visible to customers
+func (p *Product) IsPublished() bool {
+	return p.status == StatusPublished
+}
+
+// TransitionTo moves the product to next if its status allows it; publishing also requires every guard to pass
+func (p *Product) TransitionTo(next ProductStatus, guards []PublishGuard) error {
+	if !p.status.CanTransitionTo(next) {
+		return fmt.Errorf("%w: product %s is %s and cannot become %s", ErrInvalidStatusTransition, p.id, p.status, next)
+	}
+	if next == StatusPublished {
+		var reasons []string
+		for _, guard := range guards {
+			if reason := guard(p); reason != "" {
+				reasons = append(reasons, reason)
+			}
+		}
+		if len(reasons) > 0 {
+			return fmt.Errorf("%w: %s", ErrNotPublishable, strings.Join(reasons, ", "))
+		}
+	}
+
+	p.status = next
+	p.updatedAt = p.now()
+	return nil
+}
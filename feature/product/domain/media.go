@@ -0,0 +1,304 @@
+package product
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+)
+
+var (
+	// ErrBlobNotFound is returned by a BlobStore when no blob has the requested key
+	ErrBlobNotFound = errors.New("blob not found")
+	// ErrImageTooLarge is returned when an uploaded image exceeds ImageLimits.MaxSize
+	ErrImageTooLarge = errors.New("image too large")
+	// ErrUnsupportedImage is returned when an upload is not a PNG, JPEG or GIF image
+	ErrUnsupportedImage = errors.New("unsupported image type, want image/png, image/jpeg or image/gif")
+)
+
+// BlobStore stores the files of product images; implementations must be safe for concurrent use
+type BlobStore interface {
+	// Put stores data under key, replacing any blob with the same key
+	Put(ctx context.Context, key, contentType string, data io.Reader) error
+	// Open returns the blob stored under key, or ErrBlobNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key; deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// ImageLimits bound the images accepted by MediaService
+type ImageLimits struct {
+	// MaxSize is the largest image file in bytes
+	MaxSize int64
+	// MaxDimension is the largest width or height in pixels, checked before the image is decoded
+	MaxDimension int
+	// MaxPixels is the largest width×height, checked before the image is decoded: it bounds the memory decoding takes
+	MaxPixels int
+	// ThumbnailSize is the largest width or height of the thumbnails in pixels
+	ThumbnailSize int
+}
+
+// DefaultImageLimits accept images of up to 10 MiB, 8000 pixels wide or high and 16 megapixels
+// (64 MiB once decoded), with 256 pixel thumbnails
+var DefaultImageLimits = ImageLimits{MaxSize: 10 << 20, MaxDimension: 8000, MaxPixels: 16_000_000, ThumbnailSize: 256}
+
+// imageFormats maps the image formats decoded by the standard library to their media type and file extension
+var imageFormats = map[string]struct{ contentType, ext string }{
+	"png":  {"image/png", ".png"},
+	"jpeg": {"image/jpeg", ".jpg"},
+	"gif":  {"image/gif", ".gif"},
+}
+
+// MediaService manages the images of products: the files are kept in a BlobStore and the image list on the product.
+// Image changes are saved and published through the product service.
+type MediaService struct {
+	products *Service
+	blobs    BlobStore
+	limits   ImageLimits
+}
+
+// NewMediaService creates a new media service
+func NewMediaService(products *Service, blobs BlobStore, limits ImageLimits) *MediaService {
+	return &MediaService{
+		products: products,
+		blobs:    blobs,
+		limits:   limits,
+	}
+}
+
+// Limits returns the limits of the accepted images
+func (s *MediaService) Limits() ImageLimits {
+	return s.limits
+}
+
+// UploadImage stores an image and its thumbnail and appends the image to the product.
+// The type is sniffed from the data; PNG, JPEG and GIF images are accepted.
+func (s *MediaService) UploadImage(ctx context.Context, productID ProductID, data []byte) (*Product, Image, error) {
+	if int64(len(data)) > s.limits.MaxSize {
+		return nil, Image{}, fmt.Errorf("%w: %d bytes, the limit is %d", ErrImageTooLarge, len(data), s.limits.MaxSize)
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, Image{}, ErrUnsupportedImage
+	}
+	if config.Width > s.limits.MaxDimension || config.Height > s.limits.MaxDimension {
+		return nil, Image{}, NewValidationError(fmt.Sprintf("image is %d×%d pixels, the limit is %d×%d",
+			config.Width, config.Height, s.limits.MaxDimension, s.limits.MaxDimension))
+	}
+	if int64(config.Width)*int64(config.Height) > int64(s.limits.MaxPixels) {
+		return nil, Image{}, NewValidationError(fmt.Sprintf("image is %d×%d pixels, the limit is %d pixels",
+			config.Width, config.Height, s.limits.MaxPixels))
+	}
+	thumbnail, err := Thumbnail(data, s.limits.ThumbnailSize)
+	if err != nil {
+		return nil, Image{}, NewValidationError("image cannot be decoded: " + err.Error())
+	}
+
+	unlock := s.products.locks.lock(productID)
+	defer unlock()
+
+	product, err := s.products.find(ctx, productID)
+	if err != nil {
+		return nil, Image{}, err
+	}
+
+	id := newImageID()
+	f := imageFormats[format]
+	img := RestoreImage(id, "images/"+id.String()+f.ext, "images/"+id.String()+"_thumb"+f.ext,
+		f.contentType, int64(len(data)), config.Width, config.Height, product.now())
+	if len(product.images) >= MaxImagesPerProduct {
+		return nil, Image{}, NewValidationError(fmt.Sprintf("a product cannot have more than %d images", MaxImagesPerProduct))
+	}
+
+	// The files are stored before the product refers to them, and deleted again when it cannot
+	if err := s.blobs.Put(ctx, img.Key(), img.ContentType(), bytes.NewReader(data)); err != nil {
+		return nil, Image{}, err
+	}
+	if err := s.blobs.Put(ctx, img.ThumbnailKey(), img.ContentType(), bytes.NewReader(thumbnail)); err != nil {
+		s.deleteBlobs(ctx, img)
+		return nil, Image{}, err
+	}
+	images := product.Images()
+	if err := product.AddImage(img); err != nil {
+		s.deleteBlobs(ctx, img)
+		return nil, Image{}, err
+	}
+	if err := s.products.repo.Save(ctx, product); err != nil {
+		// The product held in memory must not refer to the deleted files either
+		product.resetImages(images)
+		s.deleteBlobs(ctx, img)
+		return nil, Image{}, err
+	}
+
+	s.products.publish(ctx, Event{Type: EventProductUpdated, ProductID: product.ID(), Product: product})
+
+	return product, img, nil
+}
+
+// RemoveImage removes an image from the product and deletes its files
+func (s *MediaService) RemoveImage(ctx context.Context, productID ProductID, imageID ImageID) (*Product, error) {
+	unlock := s.products.locks.lock(productID)
+	defer unlock()
+
+	product, err := s.products.find(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	images := product.Images()
+	img, err := product.RemoveImage(imageID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.products.repo.Save(ctx, product); err != nil {
+		product.resetImages(images)
+		return nil, err
+	}
+	// The files are deleted once the product no longer refers to them
+	s.deleteBlobs(ctx, img)
+
+	s.products.publish(ctx, Event{Type: EventProductUpdated, ProductID: product.ID(), Product: product})
+
+	return product, nil
+}
+
+// ReorderImages puts the images of the product in the order of ids; the first one becomes the primary image
+func (s *MediaService) ReorderImages(ctx context.Context, productID ProductID, ids []ImageID) (*Product, error) {
+	unlock := s.products.locks.lock(productID)
+	defer unlock()
+
+	product, err := s.products.find(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	images := product.Images()
+	if err := product.ReorderImages(ids); err != nil {
+		return nil, err
+	}
+	if err := s.products.repo.Save(ctx, product); err != nil {
+		product.resetImages(images)
+		return nil, err
+	}
+
+	s.products.publish(ctx, Event{Type: EventProductUpdated, ProductID: product.ID(), Product: product})
+
+	return product, nil
+}
+
+// OpenImage returns the file of an image of the product, or of its thumbnail
+func (s *MediaService) OpenImage(ctx context.Context, productID ProductID, imageID ImageID, thumbnail bool) (io.ReadCloser, Image, error) {
+	product, err := s.products.find(ctx, productID)
+	if err != nil {
+		return nil, Image{}, err
+	}
+	img, err := product.Image(imageID)
+	if err != nil {
+		return nil, Image{}, err
+	}
+
+	key := img.Key()
+	if thumbnail {
+		key = img.ThumbnailKey()
+	}
+	r, err := s.blobs.Open(ctx, key)
+	if errors.Is(err, ErrBlobNotFound) {
+		return nil, Image{}, ErrImageNotFound
+	}
+	if err != nil {
+		return nil, Image{}, err
+	}
+	return r, img, nil
+}
+
+// HandleEvent deletes the files of the images of deleted products
+func (s *MediaService) HandleEvent(ctx context.Context, event Event) {
+	if event.Type != EventProductDeleted || event.Product == nil {
+		return
+	}
+	for _, img := range event.Product.Images() {
+		s.deleteBlobs(ctx, img)
+	}
+}
+
+// deleteBlobs deletes the files of an image; failures are logged since the image is already gone from the product
+func (s *MediaService) deleteBlobs(ctx context.Context, img Image) {
+	for _, key := range []string{img.Key(), img.ThumbnailKey()} {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			slog.WarnContext(ctx, "could not delete image file", "image_id", img.ID().String(), "key", key, "error", err)
+		}
+	}
+}
+
+// Thumbnail scales the PNG, JPEG or GIF image in data down to fit in a size×size square, averaging the pixels each
+// thumbnail pixel covers. The thumbnail is encoded in the format of the image; GIFs become single-frame.
+// Images already fitting are re-encoded at their size.
+func Thumbnail(data []byte, size int) ([]byte, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	b := src.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, max(1, height*size/width)
+		} else {
+			width, height = max(1, width*size/height), size
+		}
+	}
+	thumb := scale(src, width, height)
+
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+	case "gif":
+		err = gif.Encode(&buf, thumb, nil)
+	default:
+		err = png.Encode(&buf, thumb)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// scale resizes src to width×height with a box filter
+func scale(src image.Image, width, height int) *image.NRGBA {
+	b := src.Bounds()
+	in := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(in, in.Bounds(), src, b.Min, draw.Src)
+	if width == b.Dx() && height == b.Dy() {
+		return in
+	}
+
+	out := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*b.Dy()/height, max((y+1)*b.Dy()/height, y*b.Dy()/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*b.Dx()/width, max((x+1)*b.Dx()/width, x*b.Dx()/width+1)
+
+			var r, g, bl, a, n int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := in.PixOffset(sx, sy)
+					r += int(in.Pix[i])
+					g += int(in.Pix[i+1])
+					bl += int(in.Pix[i+2])
+					a += int(in.Pix[i+3])
+					n++
+				}
+			}
+			o := out.PixOffset(x, y)
+			out.Pix[o], out.Pix[o+1], out.Pix[o+2], out.Pix[o+3] = uint8(r/n), uint8(g/n), uint8(bl/n), uint8(a/n)
+		}
+	}
+	return out
+}
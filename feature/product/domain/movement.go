@@ -0,0 +1,250 @@
+package product
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// MovementType identifies why the stock of a product changed
+type MovementType string
+
+const (
+	// MovementReceipt adds stock received from a supplier
+	MovementReceipt MovementType = "receipt"
+	// MovementSale removes sold stock
+	MovementSale MovementType = "sale"
+	// MovementReturn adds stock returned by a customer
+	MovementReturn MovementType = "return"
+	// MovementAdjustment corrects the stock in either direction, e.g. after a count
+	MovementAdjustment MovementType = "adjustment"
+	// MovementReservation removes stock set aside for an order
+	MovementReservation MovementType = "reservation"
+	// MovementTransfer moves stock between warehouses; a transfer is recorded as one movement out and one in
+	MovementTransfer MovementType = "transfer"
+)
+
+// MovementTypes lists every movement type
+var MovementTypes = []MovementType{
+	MovementReceipt,
+	MovementSale,
+	MovementReturn,
+	MovementAdjustment,
+	MovementReservation,
+	MovementTransfer,
+}
+
+// NewMovementType creates a new MovementType
+func NewMovementType(t string) (MovementType, error) {
+	for _, mt := range MovementTypes {
+		if MovementType(t) == mt {
+			return mt, nil
+		}
+	}
+	return "", NewValidationError("unknown movement type " + t)
+}
+
+// String returns the string representation of the MovementType
+func (t MovementType) String() string {
+	return string(t)
+}
+
+// Increases reports whether movements of the type add stock
+func (t MovementType) Increases() bool {
+	return t == MovementReceipt || t == MovementReturn
+}
+
+// Decreases reports whether movements of the type remove stock
+func (t MovementType) Decreases() bool {
+	return t == MovementSale || t == MovementReservation
+}
+
+// MaxMovementReasonLength is the maximum length of the reason of a movement, in characters
+const MaxMovementReasonLength = 255
+
+// SystemActor is the actor of changes made outside of a request, e.g. by background jobs
+const SystemActor = "system"
+
+type actorKey struct{}
+
+// WithActor returns a context whose stock changes are attributed to actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor, or SystemActor
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return SystemActor
+}
+
+// MovementRepository is the append-only ledger of stock movements
+type MovementRepository interface {
+	Append(ctx context.Context, movements ...Movement) error
+	// FindByProduct returns the movements of a product, oldest first
+	FindByProduct(ctx context.Context, productID ProductID) ([]Movement, error)
+	// Balances returns the sum of the movements of every product per warehouse
+	Balances(ctx context.Context) (map[ProductID]map[WarehouseID]int, error)
+}
+
+// Movement is an entry of the stock ledger: a signed change of the stock of a product in one warehouse
+type Movement struct {
+	id           string
+	productID    ProductID
+	warehouseID  WarehouseID
+	movementType MovementType
+	quantity     int
+	reason       string
+	actor        string
+	occurredAt   time.Time
+}
+
+// RestoreMovement rebuilds a Movement from persisted state.
+// It is meant for repositories; movements are created by the services along with the change they record.
+func RestoreMovement(id string, productID ProductID, warehouseID WarehouseID, movementType MovementType, quantity int, reason, actor string, occurredAt time.Time) Movement {
+	return Movement{
+		id:           id,
+		productID:    productID,
+		warehouseID:  warehouseID,
+		movementType: movementType,
+		quantity:     quantity,
+		reason:       reason,
+		actor:        actor,
+		occurredAt:   occurredAt,
+	}
+}
+
+// ID returns the movement's ID
+func (m Movement) ID() string {
+	return m.id
+}
+
+// ProductID returns the product whose stock changed
+func (m Movement) ProductID() ProductID {
+	return m.productID
+}
+
+// WarehouseID returns the warehouse whose stock changed
+func (m Movement) WarehouseID() WarehouseID {
+	return m.warehouseID
+}
+
+// Type returns the movement's type
+func (m Movement) Type() MovementType {
+	return m.movementType
+}
+
+// Quantity returns the change of stock, negative for decreases
+func (m Movement) Quantity() int {
+	return m.quantity
+}
+
+// Reason returns why the stock changed
+func (m Movement) Reason() string {
+	return m.reason
+}
+
+// Actor returns who changed the stock
+func (m Movement) Actor() string {
+	return m.actor
+}
+
+// OccurredAt returns when the stock changed
+func (m Movement) OccurredAt() time.Time {
+	return m.occurredAt
+}
+
+// movementsBetween returns the movements turning the stock levels before into the stock levels after, one per changed warehouse,
+// stamped with the clock's time
+func movementsBetween(ctx context.Context, clock Clock, productID ProductID, before, after []StockLevel, movementType MovementType, reason string) []Movement {
+	changes := make(map[WarehouseID]int)
+	for _, l := range before {
+		changes[l.warehouseID] -= int(l.quantity)
+	}
+	for _, l := range after {
+		changes[l.warehouseID] += int(l.quantity)
+	}
+
+	warehouses := make([]WarehouseID, 0, len(changes))
+	for id, change := range changes {
+		if change != 0 {
+			warehouses = append(warehouses, id)
+		}
+	}
+	sort.Slice(warehouses, func(i, j int) bool {
+		// Decreases first, so that a transfer reads as out, then in
+		if (changes[warehouses[i]] < 0) != (changes[warehouses[j]] < 0) {
+			return changes[warehouses[i]] < 0
+		}
+		return warehouses[i] < warehouses[j]
+	})
+
+	actor := ActorFromContext(ctx)
+	now := clock.Now()
+	movements := make([]Movement, 0, len(warehouses))
+	for _, id := range warehouses {
+		movements = append(movements, Movement{
+			id:           newMovementID(),
+			productID:    productID,
+			warehouseID:  id,
+			movementType: movementType,
+			quantity:     changes[id],
+			reason:       reason,
+			actor:        actor,
+			occurredAt:   now,
+		})
+	}
+	return movements
+}
+
+// newMovementID returns a random movement ID
+func newMovementID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// StockDrift reports a warehouse where the stock of a product differs from the balance of its ledger
+type StockDrift struct {
+	ProductID   ProductID
+	WarehouseID WarehouseID
+	// Ledger is the sum of the movements; Stock is the stock held by the product
+	Ledger int
+	Stock  uint
+}
+
+// Difference returns how much the stock exceeds the ledger balance
+func (d StockDrift) Difference() int {
+	return int(d.Stock) - d.Ledger
+}
+
+// Reconcile compares the stock of p with the balances of its movements per warehouse, as returned by
+// MovementRepository.Balances, and returns the drifting warehouses, ordered by ID
+func Reconcile(p *Product, ledger map[WarehouseID]int) []StockDrift {
+	balances := make(map[WarehouseID]int, len(ledger))
+	for id, balance := range ledger {
+		balances[id] = balance
+	}
+	for _, l := range p.StockLevels() {
+		if _, ok := balances[l.warehouseID]; !ok {
+			balances[l.warehouseID] = 0
+		}
+	}
+
+	var drifts []StockDrift
+	for id, balance := range balances {
+		if stock := p.StockIn(id).Quantity(); int(stock) != balance {
+			drifts = append(drifts, StockDrift{ProductID: p.ID(), WarehouseID: id, Ledger: balance, Stock: stock})
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].WarehouseID < drifts[j].WarehouseID
+	})
+	return drifts
+}
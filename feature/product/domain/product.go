@@ -1,7 +1,6 @@
 package product
 
 import (
-	"errors"
 	"time"
 )
 
@@ -11,29 +10,84 @@ type Product struct {
 	name        ProductName
 	description ProductDescription
 	price       Price
-	stock       Stock
-	categories  []*Category
-	createdAt   time.Time
-	updatedAt   time.Time
+	status      ProductStatus
+	// availability is when the product is visible to customers once published
+	availability Availability
+	// attributes are the values of the custom attributes defined by the schemas of the categories
+	attributes Attributes
+	// images are in display order; the first one is the primary image
+	images []Image
+	// translations hold the name and description in the locales other than the default one
+	translations map[Locale]ProductTranslation
+	stock        map[WarehouseID]uint
+	categories   []*Category
+	createdAt    time.Time
+	updatedAt    time.Time
+	// clock stamps the changes; SystemClock when nil
+	clock Clock
+}
+
+// NewProduct creates a new draft Product entity; its stock is held in the default warehouse
+func NewProduct(id ProductID, name ProductName, description ProductDescription, price Price, stock Stock) (*Product, error) {
+	return newProduct(id, name, description, price, stock, SystemClock)
 }
 
-// NewProduct creates a new Product entity
-func NewProduct(id ProductID, name ProductName, description ProductDescription, price Price, stock Stock) (*Product, error) {
+// newProduct creates a new draft Product entity whose timestamps are read from clock
+func newProduct(id ProductID, name ProductName, description ProductDescription, price Price, stock Stock, clock Clock) (*Product, error) {
 	if id.IsEmpty() {
-		return nil, errors.New("product id cannot be empty")
+		return nil, NewValidationError("product id cannot be empty")
 	}
 
-	now := time.Now()
-	return &Product{
+	now := clock.Now()
+	p := &Product{
 		id:          id,
 		name:        name,
 		description: description,
 		price:       price,
-		stock:       stock,
+		status:      StatusDraft,
+		attributes:  Attributes{},
+		stock:       map[WarehouseID]uint{},
 		categories:  []*Category{},
 		createdAt:   now,
 		updatedAt:   now,
-	}, nil
+		clock:       clock,
+	}
+	if stock.Quantity() > 0 {
+		p.stock[DefaultWarehouseID] = stock.Quantity()
+	}
+	return p, nil
+}
+
+// RestoreProduct rebuilds a Product from persisted state, keeping its timestamps.
+// It is meant for repositories; new products are created with NewProduct.
+func RestoreProduct(id ProductID, name ProductName, description ProductDescription, price Price, status ProductStatus, availability Availability, attributes Attributes, images []Image, translations map[Locale]ProductTranslation, levels []StockLevel, categories []*Category, createdAt, updatedAt time.Time) *Product {
+	if categories == nil {
+		categories = []*Category{}
+	}
+	if attributes == nil {
+		attributes = Attributes{}
+	}
+	stock := make(map[WarehouseID]uint, len(levels))
+	for _, l := range levels {
+		if l.quantity > 0 {
+			stock[l.warehouseID] += l.quantity
+		}
+	}
+	return &Product{
+		id:           id,
+		name:         name,
+		description:  description,
+		price:        price,
+		status:       status,
+		availability: availability,
+		attributes:   attributes,
+		images:       images,
+		translations: translations,
+		stock:        stock,
+		categories:   categories,
+		createdAt:    createdAt,
+		updatedAt:    updatedAt,
+	}
 }
 
 // ID returns the product's ID
@@ -56,9 +110,13 @@ func (p *Product) Price() Price {
 	return p.price
 }
 
-// Stock returns the product's stock
+// Stock returns the product's total stock across every warehouse
 func (p *Product) Stock() Stock {
-	return p.stock
+	var total uint
+	for _, quantity := range p.stock {
+		total += quantity
+	}
+	return NewStock(total)
 }
 
 // CreatedAt returns the product's creation time
@@ -71,43 +129,65 @@ func (p *Product) UpdatedAt() time.Time {
 	return p.updatedAt
 }
 
+// useClock makes the product stamp its changes with clock
+func (p *Product) useClock(clock Clock) {
+	p.clock = clock
+}
+
+// now returns the current time of the product's clock
+func (p *Product) now() time.Time {
+	if p.clock == nil {
+		return SystemClock.Now()
+	}
+	return p.clock.Now()
+}
+
 // UpdateName updates the product's name
 func (p *Product) UpdateName(name ProductName) {
 	p.name = name
-	p.updatedAt = time.Now()
+	p.updatedAt = p.now()
 }
 
 // UpdateDescription updates the product's description
 func (p *Product) UpdateDescription(description ProductDescription) {
 	p.description = description
-	p.updatedAt = time.Now()
+	p.updatedAt = p.now()
 }
 
 // UpdatePrice updates the product's price
 func (p *Product) UpdatePrice(price Price) {
 	p.price = price
-	p.updatedAt = time.Now()
+	p.updatedAt = p.now()
 }
 
-// UpdateStock updates the product's stock
+// UpdateStock sets the product's total stock.
+// An increase goes to the default warehouse; a decrease is taken from the default warehouse first, then the others by ID.
 func (p *Product) UpdateStock(stock Stock) {
-	p.stock = stock
-	p.updatedAt = time.Now()
+	current := p.Stock().Quantity()
+	switch {
+	case stock.Quantity() > current:
+		p.IncreaseStock(stock.Quantity() - current)
+	case stock.Quantity() < current:
+		// Cannot fail: the warehouses hold the current total
+		_, _ = p.decrease(current-stock.Quantity(), p.defaultOrder())
+	default:
+		p.updatedAt = p.now()
+	}
 }
 
-// DecreaseStock decreases the product's stock by the given quantity
+// DecreaseStock decreases the product's stock by the given quantity,
+// taking it from the default warehouse first, then the others by ID
 func (p *Product) DecreaseStock(quantity uint) error {
-	if err := p.stock.Decrease(quantity); err != nil {
-		return err
+	if quantity == 0 {
+		return nil
 	}
-	p.updatedAt = time.Now()
-	return nil
+	_, err := p.decrease(quantity, p.defaultOrder())
+	return err
 }
 
-// IncreaseStock increases the product's stock by the given quantity
+// IncreaseStock increases the product's stock in the default warehouse by the given quantity
 func (p *Product) IncreaseStock(quantity uint) {
-	p.stock.Increase(quantity)
-	p.updatedAt = time.Now()
+	p.SetStockLevel(DefaultWarehouseID, NewStock(p.stock[DefaultWarehouseID]+quantity))
 }
 
 // Categories returns the product's categories
@@ -125,7 +205,7 @@ func (p *Product) AddCategory(category *Category) {
 	}
 
 	p.categories = append(p.categories, category)
-	p.updatedAt = time.Now()
+	p.updatedAt = p.now()
 }
 
 // RemoveCategory removes a category from the product
@@ -135,7 +215,7 @@ func (p *Product) RemoveCategory(categoryID CategoryID) {
 			// Remove category by replacing it with the last element and truncating the slice
 			p.categories[i] = p.categories[len(p.categories)-1]
 			p.categories = p.categories[:len(p.categories)-1]
-			p.updatedAt = time.Now()
+			p.updatedAt = p.now()
 			return
 		}
 	}
@@ -0,0 +1,153 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrReorderPolicyNotFound = errors.New("reorder policy not found")
+
+// ReorderPolicyRepository stores the reorder policies of products
+type ReorderPolicyRepository interface {
+	FindByProduct(ctx context.Context, productID ProductID) (*ReorderPolicy, error)
+	FindAll(ctx context.Context) ([]*ReorderPolicy, error)
+	Save(ctx context.Context, policy *ReorderPolicy) error
+	Delete(ctx context.Context, productID ProductID) error
+}
+
+// ReorderPolicy tells when a product runs low and how much stock to order then.
+// The stock is low at or below the threshold; orders bring it back up to the target.
+type ReorderPolicy struct {
+	productID ProductID
+	threshold uint
+	target    uint
+}
+
+// NewReorderPolicy creates a new ReorderPolicy; the target must exceed the threshold
+func NewReorderPolicy(productID ProductID, threshold, target uint) (*ReorderPolicy, error) {
+	if productID == "" {
+		return nil, NewValidationError("product id cannot be empty")
+	}
+	if target <= threshold {
+		return nil, NewValidationError("reorder target must be greater than the threshold")
+	}
+	return &ReorderPolicy{productID: productID, threshold: threshold, target: target}, nil
+}
+
+// ProductID returns the product the policy applies to
+func (p *ReorderPolicy) ProductID() ProductID {
+	return p.productID
+}
+
+// Threshold returns the stock at or below which the product is low
+func (p *ReorderPolicy) Threshold() uint {
+	return p.threshold
+}
+
+// Target returns the stock a reorder brings the product back to
+func (p *ReorderPolicy) Target() uint {
+	return p.target
+}
+
+// SuggestedQuantity returns how much to order for the stock to reach the target, or 0 while the stock is above the threshold
+func (p *ReorderPolicy) SuggestedQuantity(stock Stock) uint {
+	if stock.Quantity() > p.threshold {
+		return 0
+	}
+	return p.target - stock.Quantity()
+}
+
+// StockStatus classifies the stock of a product against its reorder policy
+type StockStatus int
+
+const (
+	// StockAvailable is stock above the reorder threshold
+	StockAvailable StockStatus = iota
+	// StockLow is stock at or below the reorder threshold
+	StockLow
+	// StockOut is no stock at all
+	StockOut
+)
+
+// String returns the string representation of the StockStatus
+func (s StockStatus) String() string {
+	switch s {
+	case StockLow:
+		return "low_stock"
+	case StockOut:
+		return "out_of_stock"
+	default:
+		return "available"
+	}
+}
+
+// StatusOf returns the status of stock under policy; without a policy, stock is only ever available or out
+func StatusOf(stock Stock, policy *ReorderPolicy) StockStatus {
+	switch {
+	case !stock.IsAvailable():
+		return StockOut
+	case policy != nil && stock.Quantity() <= policy.threshold:
+		return StockLow
+	default:
+		return StockAvailable
+	}
+}
+
+// AlertType identifies the kind of an inventory alert
+type AlertType string
+
+const (
+	AlertLowStock   AlertType = "low_stock"
+	AlertOutOfStock AlertType = "out_of_stock"
+)
+
+// String returns the string representation of the AlertType
+func (t AlertType) String() string {
+	return string(t)
+}
+
+// Alert reports a product whose stock fell to its reorder threshold or ran out
+type Alert struct {
+	Type        AlertType
+	ProductID   ProductID
+	ProductName ProductName
+	Stock       uint
+	// Threshold, Target and SuggestedQuantity are zero when the product has no reorder policy
+	Threshold         uint
+	Target            uint
+	SuggestedQuantity uint
+	OccurredAt        time.Time
+}
+
+// EvaluateStockChange returns the alert raised when the stock of a product changes from previous to current.
+// An alert is only raised when the change crosses into a worse status: stock falling to the threshold raises
+// AlertLowStock, stock running out raises AlertOutOfStock; changes within a status and increases raise none.
+func EvaluateStockChange(productID ProductID, name ProductName, previous, current Stock, policy *ReorderPolicy, occurredAt time.Time) (Alert, bool) {
+	status := StatusOf(current, policy)
+	if status <= StatusOf(previous, policy) {
+		return Alert{}, false
+	}
+
+	alert := Alert{
+		Type:        AlertLowStock,
+		ProductID:   productID,
+		ProductName: name,
+		Stock:       current.Quantity(),
+		OccurredAt:  occurredAt,
+	}
+	if status == StockOut {
+		alert.Type = AlertOutOfStock
+	}
+	if policy != nil {
+		alert.Threshold = policy.threshold
+		alert.Target = policy.target
+		alert.SuggestedQuantity = policy.SuggestedQuantity(current)
+	}
+	return alert, true
+}
+
+// Notifier delivers inventory alerts, e.g. to the logs or a webhook
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
@@ -14,6 +14,8 @@ type Repository interface {
 	FindByID(ctx context.Context, id ProductID) (*Product, error)
 	FindAll(ctx context.Context) ([]*Product, error)
 	FindByCategory(ctx context.Context, categoryID CategoryID) ([]*Product, error)
+	// Facets counts the products per category, price bucket, stock availability and attribute value
+	Facets(ctx context.Context, query FacetQuery) (*Facets, error)
 	Save(ctx context.Context, product *Product) error
 	Delete(ctx context.Context, id ProductID) error
 }
@@ -3,78 +3,260 @@ package product
 import (
 	"context"
 	"errors"
+	"sort"
+	"sync"
 )
 
 // Service provides domain operations for products
 type Service struct {
-	repo Repository
+	repo       Repository
+	mutex      sync.RWMutex
+	handlers   []EventHandler
+	locks      productLocks
+	guards     []PublishGuard
+	clock      Clock
+	transactor Transactor
+	ledger     MovementRepository
 }
 
 // NewService creates a new product service
 func NewService(repo Repository) *Service {
 	return &Service{
-		repo: repo,
+		repo:       repo,
+		guards:     DefaultPublishGuards,
+		clock:      SystemClock,
+		transactor: NoTransaction,
 	}
 }
 
-// CreateProduct creates a new product
-func (s *Service) CreateProduct(ctx context.Context, id ProductID, name ProductName, description ProductDescription, price Price, stock Stock) (*Product, error) {
-	// Check if product with the same ID already exists
-	existingProduct, err := s.repo.FindByID(ctx, id)
-	if err != nil && !errors.Is(err, ErrProductNotFound) {
-		return nil, err
+// SetTransactor replaces the transactions the stock changes are saved in; NoTransaction by default
+func (s *Service) SetTransactor(transactor Transactor) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.transactor = transactor
+}
+
+// SetLedger makes the service append the movements of its stock changes to movements,
+// in the same transaction as the products. Without a ledger the movements are only published with the events.
+func (s *Service) SetLedger(movements MovementRepository) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.ledger = movements
+}
+
+// transaction runs fn in a transaction of the service's transactor
+func (s *Service) transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	s.mutex.RLock()
+	transactor := s.transactor
+	s.mutex.RUnlock()
+
+	return runTransaction(ctx, transactor, fn)
+}
+
+// record appends movements to the ledger; call it in the transaction saving the stock they record
+func (s *Service) record(ctx context.Context, movements []Movement) error {
+	s.mutex.RLock()
+	ledger := s.ledger
+	s.mutex.RUnlock()
+
+	if ledger == nil || len(movements) == 0 {
+		return nil
 	}
+	return ledger.Append(ctx, movements...)
+}
+
+// SetClock replaces the clock stamping the products and events; tests inject a fake one
+func (s *Service) SetClock(clock Clock) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.clock = clock
+}
+
+// Clock returns the clock of the service
+func (s *Service) Clock() Clock {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 
-	if existingProduct != nil {
-		return nil, ErrProductExists
+	return s.clock
+}
+
+// find loads a product and makes it stamp its changes with the service's clock
+func (s *Service) find(ctx context.Context, id ProductID) (*Product, error) {
+	product, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
+	product.useClock(s.Clock())
+	return product, nil
+}
+
+// SetPublishGuards replaces the checks a product must pass to be published
+func (s *Service) SetPublishGuards(guards ...PublishGuard) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.guards = guards
+}
+
+// Subscribe registers a handler for the events emitted after each successful change
+func (s *Service) Subscribe(handler EventHandler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.handlers = append(s.handlers, handler)
+}
+
+// publish sends events to every subscribed handler; inside a transaction, once it commits
+func (s *Service) publish(ctx context.Context, events ...Event) {
+	s.mutex.RLock()
+	handlers := s.handlers
+	clock := s.clock
+	s.mutex.RUnlock()
+
+	now := clock.Now()
+	AfterCommit(ctx, func(ctx context.Context) {
+		for _, event := range events {
+			if event.OccurredAt.IsZero() {
+				event.OccurredAt = now
+			}
+			for _, h := range handlers {
+				h.HandleEvent(ctx, event)
+			}
+		}
+	})
+}
 
+// CreateProduct creates a new product
+func (s *Service) CreateProduct(ctx context.Context, id ProductID, name ProductName, description ProductDescription, price Price, stock Stock) (*Product, error) {
 	// Create new product
-	product, err := NewProduct(id, name, description, price, stock)
+	product, err := newProduct(id, name, description, price, stock, s.Clock())
 	if err != nil {
 		return nil, err
 	}
+	movements := movementsBetween(ctx, s.Clock(), product.ID(), nil, product.StockLevels(), MovementReceipt, "initial stock")
 
-	// Save to repository
-	if err := s.repo.Save(ctx, product); err != nil {
+	// Save to repository with the initial stock in the ledger, unless a product with the same ID already exists
+	err = s.transaction(ctx, func(ctx context.Context) error {
+		existingProduct, err := s.repo.FindByID(ctx, id)
+		if err != nil && !errors.Is(err, ErrProductNotFound) {
+			return err
+		}
+		if existingProduct != nil {
+			return ErrProductExists
+		}
+		if err := s.repo.Save(ctx, product); err != nil {
+			return err
+		}
+		return s.record(ctx, movements)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	s.publish(ctx, Event{
+		Type:      EventProductCreated,
+		ProductID: product.ID(),
+		Product:   product,
+		Movements: movements,
+	})
+
 	return product, nil
 }
 
 // UpdateProduct updates an existing product
 func (s *Service) UpdateProduct(ctx context.Context, id ProductID, name ProductName, description ProductDescription, price Price, stock Stock) (*Product, error) {
-	// Find existing product
-	product, err := s.repo.FindByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
+	unlock := s.locks.lock(id)
+	defer unlock()
 
-	// Update product fields
-	product.UpdateName(name)
-	product.UpdateDescription(description)
-	product.UpdatePrice(price)
-	product.UpdateStock(stock)
+	var product *Product
+	var events []Event
+	err := s.transaction(ctx, func(ctx context.Context) error {
+		// Find existing product
+		var err error
+		product, err = s.find(ctx, id)
+		if err != nil {
+			return err
+		}
 
-	// Save to repository
-	if err := s.repo.Save(ctx, product); err != nil {
+		previousPrice := product.Price()
+		previousStock := product.Stock()
+		previousLevels := product.StockLevels()
+
+		// Update product fields
+		product.UpdateName(name)
+		product.UpdateDescription(description)
+		product.UpdatePrice(price)
+		product.UpdateStock(stock)
+
+		// Save to repository with the stock change in the ledger
+		movements := movementsBetween(ctx, s.Clock(), product.ID(), previousLevels, product.StockLevels(), MovementAdjustment, "product update")
+		if err := s.repo.Save(ctx, product); err != nil {
+			return err
+		}
+		if err := s.record(ctx, movements); err != nil {
+			return err
+		}
+
+		events = []Event{{Type: EventProductUpdated, ProductID: product.ID(), Product: product}}
+		if previousPrice != price {
+			events = append(events, Event{Type: EventPriceChanged, ProductID: product.ID(), Product: product, PreviousPrice: previousPrice})
+		}
+		if previousStock != stock {
+			events = append(events, Event{
+				Type:          EventStockChanged,
+				ProductID:     product.ID(),
+				Product:       product,
+				PreviousStock: previousStock,
+				Movements:     movements,
+			})
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.publish(ctx, events...)
 
 	return product, nil
 }
 
 // DeleteProduct deletes a product
 func (s *Service) DeleteProduct(ctx context.Context, id ProductID) error {
-	// Check if product exists
-	_, err := s.repo.FindByID(ctx, id)
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	var product *Product
+	var movements []Movement
+	err := s.transaction(ctx, func(ctx context.Context) error {
+		// Check if product exists
+		var err error
+		product, err = s.find(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Delete from repository; the remaining stock leaves the ledger with the product
+		movements = movementsBetween(ctx, s.Clock(), id, product.StockLevels(), nil, MovementAdjustment, "product deleted")
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.record(ctx, movements)
+	})
 	if err != nil {
 		return err
 	}
 
-	// Delete from repository
-	return s.repo.Delete(ctx, id)
+	s.publish(ctx, Event{
+		Type:      EventProductDeleted,
+		ProductID: id,
+		Product:   product,
+		Movements: movements,
+	})
+
+	return nil
 }
 
 // GetProductByID retrieves a product by ID
@@ -94,8 +276,11 @@ func (s *Service) GetProductsByCategory(ctx context.Context, categoryID Category
 
 // AddCategoryToProduct adds a category to a product
 func (s *Service) AddCategoryToProduct(ctx context.Context, productID ProductID, category *Category) (*Product, error) {
+	unlock := s.locks.lock(productID)
+	defer unlock()
+
 	// Find existing product
-	product, err := s.repo.FindByID(ctx, productID)
+	product, err := s.find(ctx, productID)
 	if err != nil {
 		return nil, err
 	}
@@ -108,13 +293,18 @@ func (s *Service) AddCategoryToProduct(ctx context.Context, productID ProductID,
 		return nil, err
 	}
 
+	s.publish(ctx, Event{Type: EventCategoryAdded, ProductID: product.ID(), Product: product, CategoryID: category.ID()})
+
 	return product, nil
 }
 
 // RemoveCategoryFromProduct removes a category from a product
 func (s *Service) RemoveCategoryFromProduct(ctx context.Context, productID ProductID, categoryID CategoryID) (*Product, error) {
+	unlock := s.locks.lock(productID)
+	defer unlock()
+
 	// Find existing product
-	product, err := s.repo.FindByID(ctx, productID)
+	product, err := s.find(ctx, productID)
 	if err != nil {
 		return nil, err
 	}
@@ -127,5 +317,95 @@ func (s *Service) RemoveCategoryFromProduct(ctx context.Context, productID Produ
 		return nil, err
 	}
 
+	s.publish(ctx, Event{Type: EventCategoryRemoved, ProductID: product.ID(), Product: product, CategoryID: categoryID})
+
+	return product, nil
+}
+
+// ChangeStatus moves a product to another lifecycle status
+func (s *Service) ChangeStatus(ctx context.Context, id ProductID, status ProductStatus) (*Product, error) {
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	// Find existing product
+	product, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	guards := s.guards
+	s.mutex.RUnlock()
+
+	previousStatus := product.Status()
+	if err := product.TransitionTo(status, guards); err != nil {
+		return nil, err
+	}
+
+	// Save to repository
+	if err := s.repo.Save(ctx, product); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, Event{Type: EventStatusChanged, ProductID: product.ID(), Product: product, PreviousStatus: previousStatus})
+
 	return product, nil
 }
+
+// productLocks serializes the read-modify-write cycles of each product within the process
+type productLocks struct {
+	mutex sync.Mutex
+	held  map[ProductID]*productLock
+}
+
+type productLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// lock acquires the lock of a product and returns the function releasing it
+func (l *productLocks) lock(id ProductID) (unlock func()) {
+	l.mutex.Lock()
+	if l.held == nil {
+		l.held = make(map[ProductID]*productLock)
+	}
+	pl, ok := l.held[id]
+	if !ok {
+		pl = &productLock{}
+		l.held[id] = pl
+	}
+	pl.waiters++
+	l.mutex.Unlock()
+
+	pl.Lock()
+	return func() {
+		pl.Unlock()
+
+		l.mutex.Lock()
+		pl.waiters--
+		if pl.waiters == 0 {
+			delete(l.held, id)
+		}
+		l.mutex.Unlock()
+	}
+}
+
+// lockAll acquires the locks of several products in ID order, so that concurrent callers cannot deadlock,
+// and returns the function releasing them
+func (l *productLocks) lockAll(ids []ProductID) (unlock func()) {
+	sorted := append([]ProductID(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	unlocks := make([]func(), 0, len(sorted))
+	for i, id := range sorted {
+		if i > 0 && id == sorted[i-1] {
+			continue
+		}
+		unlocks = append(unlocks, l.lock(id))
+	}
+	return func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+}
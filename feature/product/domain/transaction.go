@@ -0,0 +1,83 @@
+package product
+
+import (
+	"context"
+	"sync"
+)
+
+// Transactor runs functions in a transaction of the repositories.
+// The repositories given the context passed to fn make their changes in the transaction,
+// which is committed when fn returns nil and rolled back otherwise.
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// TransactorFunc adapts a function to the Transactor interface
+type TransactorFunc func(ctx context.Context, fn func(ctx context.Context) error) error
+
+// Transaction calls f(ctx, fn)
+func (f TransactorFunc) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return f(ctx, fn)
+}
+
+// NoTransaction runs fn directly, for repositories without transactions such as the in-memory ones:
+// the writes made before fn failed are kept, and the events are published as soon as each change is saved.
+var NoTransaction Transactor = noTransaction{}
+
+type noTransaction struct{}
+
+// Transaction implements Transactor
+func (noTransaction) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type transactionKey struct{}
+
+// transaction collects the functions to run once the transaction of a context commits
+type transaction struct {
+	// ctx is the context the transaction was started from
+	ctx      context.Context
+	mutex    sync.Mutex
+	onCommit []func(ctx context.Context)
+}
+
+// InTransaction reports whether ctx belongs to a transaction started by a service.
+// Caches read through inside a transaction, so that the repository sees, and locks, the current state.
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(transactionKey{}).(*transaction)
+	return ok
+}
+
+// AfterCommit runs fn once the transaction of ctx commits, or right away outside of a transaction.
+// fn is given the context the transaction was started from, which no longer belongs to the transaction.
+// The services publish their events with it, and caches drop the entries written in the transaction.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	tx, ok := ctx.Value(transactionKey{}).(*transaction)
+	if !ok {
+		fn(ctx)
+		return
+	}
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	tx.onCommit = append(tx.onCommit, fn)
+}
+
+// runTransaction runs fn in a transaction of transactor, then the functions registered with AfterCommit if it committed.
+// Inside a transaction fn joins it.
+func runTransaction(ctx context.Context, transactor Transactor, fn func(ctx context.Context) error) error {
+	if _, none := transactor.(noTransaction); none || InTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	tx := &transaction{ctx: ctx}
+	if err := transactor.Transaction(context.WithValue(ctx, transactionKey{}, tx), fn); err != nil {
+		return err
+	}
+	tx.mutex.Lock()
+	onCommit := tx.onCommit
+	tx.mutex.Unlock()
+	for _, f := range onCommit {
+		f(tx.ctx)
+	}
+	return nil
+}
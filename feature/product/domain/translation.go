@@ -0,0 +1,279 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrTranslationNotFound is returned when a product or category has no translation in the requested locale
+var ErrTranslationNotFound = errors.New("translation not found")
+
+// LocalePattern is the canonical form of a locale: a language, optionally followed by a script and a region,
+// e.g. en, ja, en-US or zh-Hant-TW
+const LocalePattern = "^[a-z]{2,3}(-[A-Z][a-z]{3})?(-[A-Z]{2}|-[0-9]{3})?$"
+
+var localePattern = regexp.MustCompile(LocalePattern)
+
+// Locale is a language tag in its canonical form, e.g. ja or en-US
+type Locale string
+
+// NewLocale creates a new Locale; the case of the subtags is normalized and _ is accepted as a separator, so en_us becomes en-US
+func NewLocale(tag string) (Locale, error) {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-")
+	for i, part := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		default:
+			parts[i] = strings.ToUpper(part)
+		}
+	}
+	locale := strings.Join(parts, "-")
+	if !localePattern.MatchString(locale) {
+		return "", NewValidationError(fmt.Sprintf("invalid locale %q, want a language tag such as en or ja-JP", tag))
+	}
+	return Locale(locale), nil
+}
+
+// String returns the string representation of the Locale
+func (l Locale) String() string {
+	return string(l)
+}
+
+// Language returns the language of the locale without its script and region, e.g. en for en-US
+func (l Locale) Language() Locale {
+	language, _, _ := strings.Cut(string(l), "-")
+	return Locale(language)
+}
+
+// Locales are the locales the catalog is translated to. The names and descriptions of products and categories
+// themselves are in the default locale; the other locales are kept as translations.
+type Locales struct {
+	defaultLocale Locale
+	supported     []Locale
+}
+
+// DefaultLocales only support English
+var DefaultLocales = Locales{defaultLocale: "en", supported: []Locale{"en"}}
+
+// NewLocales creates the locales of the catalog; the default locale is supported even when supported omits it
+func NewLocales(defaultLocale string, supported []string) (Locales, error) {
+	def, err := NewLocale(defaultLocale)
+	if err != nil {
+		return Locales{}, err
+	}
+	locales := Locales{defaultLocale: def, supported: []Locale{def}}
+	for _, tag := range supported {
+		l, err := NewLocale(tag)
+		if err != nil {
+			return Locales{}, err
+		}
+		if !locales.Supports(l) {
+			locales.supported = append(locales.supported, l)
+		}
+	}
+	return locales, nil
+}
+
+// Default returns the locale of the texts of products and categories themselves
+func (l Locales) Default() Locale {
+	return l.defaultLocale
+}
+
+// Supported returns every supported locale, the default one first
+func (l Locales) Supported() []Locale {
+	return append([]Locale(nil), l.supported...)
+}
+
+// Supports reports whether texts can be translated to locale
+func (l Locales) Supports(locale Locale) bool {
+	for _, s := range l.supported {
+		if s == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate returns the supported locale best matching preferred, which lists the locales of the client by decreasing
+// preference. Each preference is matched exactly, then by language (ja-JP matches ja, and en matches en-US), before
+// the next one is tried. The default locale is returned when nothing matches.
+func (l Locales) Negotiate(preferred []Locale) Locale {
+	for _, p := range preferred {
+		if l.Supports(p) {
+			return p
+		}
+		if l.Supports(p.Language()) {
+			return p.Language()
+		}
+		for _, s := range l.supported {
+			if s.Language() == p.Language() {
+				return s
+			}
+		}
+	}
+	return l.defaultLocale
+}
+
+// ProductTranslation is the name and description of a product in a locale other than the default one
+type ProductTranslation struct {
+	name        ProductName
+	description ProductDescription
+}
+
+// NewProductTranslation creates a new ProductTranslation; an empty description falls back to the default one
+func NewProductTranslation(name ProductName, description ProductDescription) (ProductTranslation, error) {
+	if name.IsEmpty() {
+		return ProductTranslation{}, NewValidationError("product name cannot be empty")
+	}
+	return ProductTranslation{name: name, description: description}, nil
+}
+
+// Name returns the translated name
+func (t ProductTranslation) Name() ProductName {
+	return t.name
+}
+
+// Description returns the translated description, empty when it is not translated
+func (t ProductTranslation) Description() ProductDescription {
+	return t.description
+}
+
+// Translations returns a copy of the product's translations by locale
+func (p *Product) Translations() map[Locale]ProductTranslation {
+	translations := make(map[Locale]ProductTranslation, len(p.translations))
+	for l, t := range p.translations {
+		translations[l] = t
+	}
+	return translations
+}
+
+// SetTranslation adds or replaces the translation of the product in locale
+func (p *Product) SetTranslation(locale Locale, t ProductTranslation) {
+	if p.translations == nil {
+		p.translations = make(map[Locale]ProductTranslation)
+	}
+	p.translations[locale] = t
+	p.updatedAt = p.now()
+}
+
+// RemoveTranslation removes the translation of the product in locale
+func (p *Product) RemoveTranslation(locale Locale) error {
+	if _, ok := p.translations[locale]; !ok {
+		return ErrTranslationNotFound
+	}
+	delete(p.translations, locale)
+	p.updatedAt = p.now()
+	return nil
+}
+
+// LocalizedName returns the name of the product in locale, or in its language, falling back to the default name
+func (p *Product) LocalizedName(locale Locale) ProductName {
+	if t, ok := p.translation(locale); ok {
+		return t.name
+	}
+	return p.name
+}
+
+// LocalizedDescription returns the description of the product in locale, or in its language, falling back to the default description
+func (p *Product) LocalizedDescription(locale Locale) ProductDescription {
+	if t, ok := p.translation(locale); ok && t.description != "" {
+		return t.description
+	}
+	return p.description
+}
+
+// translation returns the translation in locale, or else in its language
+func (p *Product) translation(locale Locale) (ProductTranslation, bool) {
+	if t, ok := p.translations[locale]; ok {
+		return t, true
+	}
+	t, ok := p.translations[locale.Language()]
+	return t, ok
+}
+
+// CategoryTranslations are the names of a category in the locales other than the default one
+type CategoryTranslations struct {
+	categoryID CategoryID
+	names      map[Locale]CategoryName
+}
+
+// NewCategoryTranslations creates the translations of a category
+func NewCategoryTranslations(categoryID CategoryID, names map[Locale]CategoryName) (*CategoryTranslations, error) {
+	if categoryID.IsEmpty() {
+		return nil, NewValidationError("category id cannot be empty")
+	}
+	t := &CategoryTranslations{categoryID: categoryID, names: make(map[Locale]CategoryName, len(names))}
+	for l, name := range names {
+		if name.IsEmpty() {
+			return nil, NewValidationError("category name cannot be empty")
+		}
+		t.names[l] = name
+	}
+	return t, nil
+}
+
+// CategoryID returns the ID of the translated category
+func (t *CategoryTranslations) CategoryID() CategoryID {
+	return t.categoryID
+}
+
+// Names returns a copy of the translated names by locale
+func (t *CategoryTranslations) Names() map[Locale]CategoryName {
+	names := make(map[Locale]CategoryName, len(t.names))
+	for l, name := range t.names {
+		names[l] = name
+	}
+	return names
+}
+
+// Locales returns the translated locales in order
+func (t *CategoryTranslations) Locales() []Locale {
+	locales := make([]Locale, 0, len(t.names))
+	for l := range t.names {
+		locales = append(locales, l)
+	}
+	sort.Slice(locales, func(i, j int) bool { return locales[i] < locales[j] })
+	return locales
+}
+
+// Name returns the name of the category in locale, or else in its language
+func (t *CategoryTranslations) Name(locale Locale) (CategoryName, bool) {
+	if name, ok := t.names[locale]; ok {
+		return name, true
+	}
+	name, ok := t.names[locale.Language()]
+	return name, ok
+}
+
+// Set adds or replaces the name of the category in locale
+func (t *CategoryTranslations) Set(locale Locale, name CategoryName) error {
+	if name.IsEmpty() {
+		return NewValidationError("category name cannot be empty")
+	}
+	t.names[locale] = name
+	return nil
+}
+
+// Remove removes the name of the category in locale
+func (t *CategoryTranslations) Remove(locale Locale) error {
+	if _, ok := t.names[locale]; !ok {
+		return ErrTranslationNotFound
+	}
+	delete(t.names, locale)
+	return nil
+}
+
+// CategoryTranslationRepository stores the translated names of categories
+type CategoryTranslationRepository interface {
+	// FindByCategory returns the translations of a category, or ErrTranslationNotFound when it has none
+	FindByCategory(ctx context.Context, categoryID CategoryID) (*CategoryTranslations, error)
+	// Save replaces the translations of a category
+	Save(ctx context.Context, translations *CategoryTranslations) error
+}
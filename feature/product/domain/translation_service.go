@@ -0,0 +1,174 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// TranslationService manages the translations of product and category texts in the supported locales.
+// Product translation changes are saved and published through the product service.
+type TranslationService struct {
+	products   *Service
+	categories CategoryTranslationRepository
+	locales    Locales
+	// categoryMutex serializes the changes of category translations, which are read and saved whole
+	categoryMutex sync.Mutex
+}
+
+// NewTranslationService creates a new translation service
+func NewTranslationService(products *Service, categories CategoryTranslationRepository, locales Locales) *TranslationService {
+	return &TranslationService{
+		products:   products,
+		categories: categories,
+		locales:    locales,
+	}
+}
+
+// Locales returns the locales of the catalog
+func (s *TranslationService) Locales() Locales {
+	return s.locales
+}
+
+// checkLocale rejects the locales texts cannot be translated to: unsupported ones and the default one,
+// whose texts are the product's or category's own
+func (s *TranslationService) checkLocale(locale Locale) error {
+	if locale == s.locales.Default() {
+		return NewValidationError(fmt.Sprintf("locale %s is the default locale; its texts are set on the product or category itself", locale))
+	}
+	if !s.locales.Supports(locale) {
+		return NewValidationError(fmt.Sprintf("locale %s is not supported, want one of %v", locale, s.locales.Supported()))
+	}
+	return nil
+}
+
+// GetProduct returns a product with its translations
+func (s *TranslationService) GetProduct(ctx context.Context, productID ProductID) (*Product, error) {
+	return s.products.find(ctx, productID)
+}
+
+// SetProductTranslation adds or replaces the name and description of a product in locale
+func (s *TranslationService) SetProductTranslation(ctx context.Context, productID ProductID, locale Locale, t ProductTranslation) (*Product, error) {
+	if err := s.checkLocale(locale); err != nil {
+		return nil, err
+	}
+
+	unlock := s.products.locks.lock(productID)
+	defer unlock()
+
+	product, err := s.products.find(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	product.SetTranslation(locale, t)
+	if err := s.products.repo.Save(ctx, product); err != nil {
+		return nil, err
+	}
+
+	s.products.publish(ctx, Event{Type: EventProductUpdated, ProductID: product.ID(), Product: product})
+
+	return product, nil
+}
+
+// RemoveProductTranslation removes the translation of a product in locale; the product then shows its default texts there
+func (s *TranslationService) RemoveProductTranslation(ctx context.Context, productID ProductID, locale Locale) (*Product, error) {
+	unlock := s.products.locks.lock(productID)
+	defer unlock()
+
+	product, err := s.products.find(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if err := product.RemoveTranslation(locale); err != nil {
+		return nil, err
+	}
+	if err := s.products.repo.Save(ctx, product); err != nil {
+		return nil, err
+	}
+
+	s.products.publish(ctx, Event{Type: EventProductUpdated, ProductID: product.ID(), Product: product})
+
+	return product, nil
+}
+
+// GetCategoryTranslations returns the translated names of a category; a category without translations has none
+func (s *TranslationService) GetCategoryTranslations(ctx context.Context, categoryID CategoryID) (*CategoryTranslations, error) {
+	translations, err := s.categories.FindByCategory(ctx, categoryID)
+	if errors.Is(err, ErrTranslationNotFound) {
+		return NewCategoryTranslations(categoryID, nil)
+	}
+	return translations, err
+}
+
+// SetCategoryTranslation adds or replaces the name of a category in locale.
+// A category can be translated before any product is added to it.
+func (s *TranslationService) SetCategoryTranslation(ctx context.Context, categoryID CategoryID, locale Locale, name CategoryName) (*CategoryTranslations, error) {
+	if err := s.checkLocale(locale); err != nil {
+		return nil, err
+	}
+
+	s.categoryMutex.Lock()
+	defer s.categoryMutex.Unlock()
+
+	translations, err := s.GetCategoryTranslations(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if err := translations.Set(locale, name); err != nil {
+		return nil, err
+	}
+	if err := s.categories.Save(ctx, translations); err != nil {
+		return nil, err
+	}
+	return translations, nil
+}
+
+// RemoveCategoryTranslation removes the name of a category in locale
+func (s *TranslationService) RemoveCategoryTranslation(ctx context.Context, categoryID CategoryID, locale Locale) (*CategoryTranslations, error) {
+	s.categoryMutex.Lock()
+	defer s.categoryMutex.Unlock()
+
+	translations, err := s.GetCategoryTranslations(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if err := translations.Remove(locale); err != nil {
+		return nil, err
+	}
+	if err := s.categories.Save(ctx, translations); err != nil {
+		return nil, err
+	}
+	return translations, nil
+}
+
+// CategoryNames returns the names in locale of the categories of products, by category ID.
+// Categories without a name in locale, or in its language, are left out; nothing is looked up for the default locale.
+func (s *TranslationService) CategoryNames(ctx context.Context, locale Locale, products []*Product) (map[CategoryID]CategoryName, error) {
+	names := make(map[CategoryID]CategoryName)
+	if locale == s.locales.Default() {
+		return names, nil
+	}
+
+	seen := make(map[CategoryID]bool)
+	for _, p := range products {
+		for _, c := range p.Categories() {
+			if seen[c.ID()] {
+				continue
+			}
+			seen[c.ID()] = true
+
+			translations, err := s.categories.FindByCategory(ctx, c.ID())
+			if errors.Is(err, ErrTranslationNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if name, ok := translations.Name(locale); ok {
+				names[c.ID()] = name
+			}
+		}
+	}
+	return names, nil
+}
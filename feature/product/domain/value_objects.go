@@ -1,10 +1,20 @@
 package product
 
 import (
-	"errors"
 	"fmt"
 	"regexp"
 	"strings"
+
+	"sago-sample/internal/textnorm"
+)
+
+const (
+	// MaxProductNameLength is the maximum length of a product name, in characters
+	MaxProductNameLength = 100
+	// MaxProductDescriptionLength is the maximum length of a product description, in characters
+	MaxProductDescriptionLength = 1000
+	// CurrencyPattern is the format of a currency code
+	CurrencyPattern = "^[A-Z]{3}$"
 )
 
 // ProductID represents the unique identifier for a product
@@ -13,7 +23,7 @@ type ProductID string
 // NewProductID creates a new ProductID
 func NewProductID(id string) (ProductID, error) {
 	if strings.TrimSpace(id) == "" {
-		return "", errors.New("product id cannot be empty")
+		return "", NewValidationError("product id cannot be empty")
 	}
 	return ProductID(id), nil
 }
@@ -40,16 +50,19 @@ func MustNewProductID(id string) ProductID {
 // ProductName represents the name of a product
 type ProductName string
 
-// NewProductName creates a new ProductName
+// NewProductName creates a new ProductName; the name is normalized to NFC on a single line
 func NewProductName(name string) (ProductName, error) {
-	trimmedName := strings.TrimSpace(name)
-	if trimmedName == "" {
-		return "", errors.New("product name cannot be empty")
+	normalized, err := textnorm.Line(name)
+	if err != nil {
+		return "", NewValidationError("product name " + err.Error())
 	}
-	if len(trimmedName) > 100 {
-		return "", errors.New("product name cannot exceed 100 characters")
+	if normalized == "" {
+		return "", NewValidationError("product name cannot be empty")
 	}
-	return ProductName(trimmedName), nil
+	if textnorm.Length(normalized) > MaxProductNameLength {
+		return "", NewValidationError("product name cannot exceed 100 characters")
+	}
+	return ProductName(normalized), nil
 }
 
 // String returns the string representation of the ProductName
@@ -74,13 +87,16 @@ func MustNewProductName(name string) ProductName {
 // ProductDescription represents the description of a product
 type ProductDescription string
 
-// NewProductDescription creates a new ProductDescription
+// NewProductDescription creates a new ProductDescription; the description is normalized to NFC, keeping its paragraphs
 func NewProductDescription(description string) (ProductDescription, error) {
-	trimmedDesc := strings.TrimSpace(description)
-	if len(trimmedDesc) > 1000 {
-		return "", errors.New("product description cannot exceed 1000 characters")
+	normalized, err := textnorm.Block(description)
+	if err != nil {
+		return "", NewValidationError("product description " + err.Error())
+	}
+	if textnorm.Length(normalized) > MaxProductDescriptionLength {
+		return "", NewValidationError("product description cannot exceed 1000 characters")
 	}
-	return ProductDescription(trimmedDesc), nil
+	return ProductDescription(normalized), nil
 }
 
 // String returns the string representation of the ProductDescription
@@ -106,18 +122,18 @@ type Price struct {
 // NewPrice creates a new Price
 func NewPrice(amount uint, currency string) (Price, error) {
 	if amount == 0 {
-		return Price{}, errors.New("price amount cannot be zero")
+		return Price{}, NewValidationError("price amount cannot be zero")
 	}
 
 	currency = strings.ToUpper(strings.TrimSpace(currency))
 	if currency == "" {
-		return Price{}, errors.New("currency cannot be empty")
+		return Price{}, NewValidationError("currency cannot be empty")
 	}
 
 	// Simple currency code validation (3 uppercase letters)
-	match, _ := regexp.MatchString("^[A-Z]{3}$", currency)
+	match, _ := regexp.MatchString(CurrencyPattern, currency)
 	if !match {
-		return Price{}, errors.New("invalid currency format, must be 3 uppercase letters")
+		return Price{}, NewValidationError("invalid currency format, must be 3 uppercase letters")
 	}
 
 	return Price{
@@ -173,7 +189,7 @@ func (s *Stock) Increase(amount uint) {
 // Decrease decreases the stock quantity by the given amount
 func (s *Stock) Decrease(amount uint) error {
 	if amount > s.quantity {
-		return errors.New("insufficient stock")
+		return ErrInsufficientStock
 	}
 	s.quantity -= amount
 	return nil
@@ -0,0 +1,150 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"sago-sample/internal/textnorm"
+)
+
+const (
+	// DefaultWarehouseID is the warehouse holding stock that was not assigned to a specific warehouse
+	DefaultWarehouseID WarehouseID = "default"
+	// MaxWarehouseNameLength is the maximum length of a warehouse name, in characters
+	MaxWarehouseNameLength = 100
+)
+
+var (
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	ErrWarehouseExists   = errors.New("warehouse already exists")
+	// ErrWarehouseInUse is returned when deleting a warehouse that still holds stock, or the default warehouse
+	ErrWarehouseInUse = errors.New("warehouse is in use")
+)
+
+// WarehouseRepository stores the warehouses products are stocked in
+type WarehouseRepository interface {
+	FindByID(ctx context.Context, id WarehouseID) (*Warehouse, error)
+	FindAll(ctx context.Context) ([]*Warehouse, error)
+	Save(ctx context.Context, warehouse *Warehouse) error
+	Delete(ctx context.Context, id WarehouseID) error
+}
+
+// WarehouseID represents the unique identifier for a warehouse
+type WarehouseID string
+
+// NewWarehouseID creates a new WarehouseID
+func NewWarehouseID(id string) (WarehouseID, error) {
+	if strings.TrimSpace(id) == "" {
+		return "", NewValidationError("warehouse id cannot be empty")
+	}
+	return WarehouseID(id), nil
+}
+
+// String returns the string representation of the WarehouseID
+func (id WarehouseID) String() string {
+	return string(id)
+}
+
+// WarehouseName represents the name of a warehouse
+type WarehouseName string
+
+// NewWarehouseName creates a new WarehouseName; the name is normalized to NFC on a single line
+func NewWarehouseName(name string) (WarehouseName, error) {
+	normalized, err := textnorm.Line(name)
+	if err != nil {
+		return "", NewValidationError("warehouse name " + err.Error())
+	}
+	if normalized == "" {
+		return "", NewValidationError("warehouse name cannot be empty")
+	}
+	if textnorm.Length(normalized) > MaxWarehouseNameLength {
+		return "", NewValidationError("warehouse name cannot exceed 100 characters")
+	}
+	return WarehouseName(normalized), nil
+}
+
+// String returns the string representation of the WarehouseName
+func (n WarehouseName) String() string {
+	return string(n)
+}
+
+// Location is a point on Earth given in decimal degrees
+type Location struct {
+	latitude  float64
+	longitude float64
+}
+
+// NewLocation creates a new Location
+func NewLocation(latitude, longitude float64) (Location, error) {
+	if math.IsNaN(latitude) || latitude < -90 || latitude > 90 {
+		return Location{}, NewValidationError("latitude must be between -90 and 90")
+	}
+	if math.IsNaN(longitude) || longitude < -180 || longitude > 180 {
+		return Location{}, NewValidationError("longitude must be between -180 and 180")
+	}
+	return Location{latitude: latitude, longitude: longitude}, nil
+}
+
+// Latitude returns the latitude in degrees
+func (l Location) Latitude() float64 {
+	return l.latitude
+}
+
+// Longitude returns the longitude in degrees
+func (l Location) Longitude() float64 {
+	return l.longitude
+}
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
+
+// DistanceKm returns the great-circle distance to other in kilometres
+func (l Location) DistanceKm(other Location) float64 {
+	lat1, lat2 := l.latitude*math.Pi/180, other.latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (other.longitude - l.longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Warehouse is a place where products are stocked
+type Warehouse struct {
+	id       WarehouseID
+	name     WarehouseName
+	location *Location
+}
+
+// NewWarehouse creates a new Warehouse; the location is optional
+func NewWarehouse(id WarehouseID, name WarehouseName, location *Location) (*Warehouse, error) {
+	if strings.TrimSpace(id.String()) == "" {
+		return nil, NewValidationError("warehouse id cannot be empty")
+	}
+	if name == "" {
+		return nil, NewValidationError("warehouse name cannot be empty")
+	}
+	return &Warehouse{id: id, name: name, location: location}, nil
+}
+
+// ID returns the warehouse's ID
+func (w *Warehouse) ID() WarehouseID {
+	return w.id
+}
+
+// Name returns the warehouse's name
+func (w *Warehouse) Name() WarehouseName {
+	return w.name
+}
+
+// Location returns the warehouse's location, or nil when it is unknown
+func (w *Warehouse) Location() *Location {
+	return w.location
+}
+
+// Update replaces the warehouse's name and location
+func (w *Warehouse) Update(name WarehouseName, location *Location) {
+	w.name = name
+	w.location = location
+}
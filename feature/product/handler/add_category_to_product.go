@@ -2,8 +2,8 @@ package handler
 
 import (
 	"encoding/json"
+	"github.com/go-chi/chi/v5"
 	"net/http"
-	"strings"
 
 	product "sago-sample/feature/product/usecase"
 )
@@ -14,12 +14,18 @@ type AddCategoryToProductRequest struct {
 	CategoryName string `json:"categoryName"`
 }
 
-// AddCategoryToProduct handles adding a category to a product
-func (h *api.ProductHandler) AddCategoryToProduct(w http.ResponseWriter, r *http.Request) {
-	// Extract product ID from URL
-	productID := strings.TrimPrefix(r.URL.Path, "/products/")
-	productID = strings.TrimSuffix(productID, "/categories")
+// CategoryHandler handles the category assignments of a product
+type CategoryHandler struct {
+	AddUseCase    *product.AddCategoryToProductUseCase
+	RemoveUseCase *product.RemoveCategoryFromProductUseCase
+}
+
+func NewCategoryHandler(addUc *product.AddCategoryToProductUseCase, removeUc *product.RemoveCategoryFromProductUseCase) *CategoryHandler {
+	return &CategoryHandler{AddUseCase: addUc, RemoveUseCase: removeUc}
+}
 
+// HandleAdd handles adding a category to a product
+func (h *CategoryHandler) HandleAdd(w http.ResponseWriter, r *http.Request) {
 	var req AddCategoryToProductRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
@@ -28,38 +34,26 @@ func (h *api.ProductHandler) AddCategoryToProduct(w http.ResponseWriter, r *http
 	defer r.Body.Close()
 
 	input := product.AddCategoryToProductInput{
-		ProductID:    productID,
+		ProductID:    chi.URLParam(r, "id"),
 		CategoryID:   req.CategoryID,
 		CategoryName: req.CategoryName,
 	}
 
-	output, err := h.addCategoryToProductUseCase.Execute(r.Context(), input)
+	output, err := h.AddUseCase.Execute(r.Context(), input)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			respondWithError(w, http.StatusNotFound, err.Error())
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithUseCaseError(w, err)
 		return
 	}
 
-	// Map categories
-	categories := make([]CategoryResponse, 0, len(output.Categories))
-	for _, c := range output.Categories {
-		categories = append(categories, CategoryResponse{
-			ID:   c.ID,
-			Name: c.Name,
-		})
-	}
-
 	response := ProductResponse{
 		ID:          output.ProductID,
 		Name:        output.Name,
 		Description: output.Description,
 		Price:       output.Price,
 		Currency:    output.Currency,
+		Status:      output.Status,
 		Stock:       output.Stock,
-		Categories:  categories,
+		Categories:  toCategoryResponses(output.Categories),
 	}
 
 	respondWithJSON(w, http.StatusOK, response)
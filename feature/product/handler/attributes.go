@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	product "sago-sample/feature/product/usecase"
+)
+
+// AttributeDefinitionRequest represents an attribute in the schema of a category
+type AttributeDefinitionRequest struct {
+	Key      string `json:"key"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	// Values lists the allowed values of an enum attribute; it is omitted for the other types
+	Values []string `json:"values,omitempty"`
+}
+
+// AttributeSchemaRequest represents the request body for defining the attributes of the products of a category
+type AttributeSchemaRequest struct {
+	Definitions []AttributeDefinitionRequest `json:"definitions"`
+}
+
+// AttributeSchemaResponse represents the attribute schema of a category
+type AttributeSchemaResponse struct {
+	CategoryID  string                       `json:"categoryId"`
+	Definitions []AttributeDefinitionRequest `json:"definitions"`
+}
+
+// ProductAttributesRequest represents the request body for replacing the attributes of a product
+type ProductAttributesRequest struct {
+	Attributes map[string]any `json:"attributes"`
+}
+
+// AttributeHandler handles the attribute schemas of categories and the attributes of products
+type AttributeHandler struct {
+	SetSchemaUseCase     *product.SetAttributeSchemaUseCase
+	GetSchemaUseCase     *product.GetAttributeSchemaUseCase
+	DeleteSchemaUseCase  *product.DeleteAttributeSchemaUseCase
+	SetAttributesUseCase *product.SetProductAttributesUseCase
+}
+
+func NewAttributeHandler(
+	setSchemaUc *product.SetAttributeSchemaUseCase,
+	getSchemaUc *product.GetAttributeSchemaUseCase,
+	deleteSchemaUc *product.DeleteAttributeSchemaUseCase,
+	setAttributesUc *product.SetProductAttributesUseCase,
+) *AttributeHandler {
+	return &AttributeHandler{
+		SetSchemaUseCase:     setSchemaUc,
+		GetSchemaUseCase:     getSchemaUc,
+		DeleteSchemaUseCase:  deleteSchemaUc,
+		SetAttributesUseCase: setAttributesUc,
+	}
+}
+
+// Register adds the attribute routes to rtr
+func (h *AttributeHandler) Register(rtr chi.Router) {
+	rtr.Get("/api/categories/{id}/attribute-schema", h.HandleGetSchema)       // GET    /api/categories/{id}/attribute-schema
+	rtr.Put("/api/categories/{id}/attribute-schema", h.HandleSetSchema)       // PUT    /api/categories/{id}/attribute-schema
+	rtr.Delete("/api/categories/{id}/attribute-schema", h.HandleDeleteSchema) // DELETE /api/categories/{id}/attribute-schema
+	rtr.Put("/api/products/{id}/attributes", h.HandleSetAttributes)           // PUT    /api/products/{id}/attributes
+}
+
+// HandleGetSchema handles getting the attribute schema of a category
+func (h *AttributeHandler) HandleGetSchema(w http.ResponseWriter, r *http.Request) {
+	output, err := h.GetSchemaUseCase.Execute(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toAttributeSchemaResponse(*output))
+}
+
+// HandleSetSchema handles creating or replacing the attribute schema of a category
+func (h *AttributeHandler) HandleSetSchema(w http.ResponseWriter, r *http.Request) {
+	var req AttributeSchemaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	definitions := make([]product.AttributeDefinitionInput, 0, len(req.Definitions))
+	for _, d := range req.Definitions {
+		definitions = append(definitions, product.AttributeDefinitionInput{Key: d.Key, Type: d.Type, Required: d.Required, Values: d.Values})
+	}
+	output, err := h.SetSchemaUseCase.Execute(r.Context(), product.SetAttributeSchemaInput{
+		CategoryID:  chi.URLParam(r, "id"),
+		Definitions: definitions,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toAttributeSchemaResponse(*output))
+}
+
+// HandleDeleteSchema handles removing the attribute schema of a category
+func (h *AttributeHandler) HandleDeleteSchema(w http.ResponseWriter, r *http.Request) {
+	if err := h.DeleteSchemaUseCase.Execute(r.Context(), chi.URLParam(r, "id")); err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleSetAttributes handles replacing the attributes of a product
+func (h *AttributeHandler) HandleSetAttributes(w http.ResponseWriter, r *http.Request) {
+	var req ProductAttributesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	output, err := h.SetAttributesUseCase.Execute(r.Context(), product.SetProductAttributesInput{
+		ProductID:  chi.URLParam(r, "id"),
+		Attributes: req.Attributes,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toProductResponse(*output))
+}
+
+// toAttributeSchemaResponse maps a use case attribute schema output to a response
+func toAttributeSchemaResponse(o product.AttributeSchemaOutput) AttributeSchemaResponse {
+	definitions := make([]AttributeDefinitionRequest, 0, len(o.Definitions))
+	for _, d := range o.Definitions {
+		definitions = append(definitions, AttributeDefinitionRequest{Key: d.Key, Type: d.Type, Required: d.Required, Values: d.Values})
+	}
+	return AttributeSchemaResponse{CategoryID: o.CategoryID, Definitions: definitions}
+}
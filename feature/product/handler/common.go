@@ -2,9 +2,18 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
+
+	domain "sago-sample/feature/product/domain"
+	product "sago-sample/feature/product/usecase"
 )
 
+// publicStatus is the only status of the products returned by the public read endpoints,
+// which also hide the products outside their availability window
+const publicStatus = string(domain.StatusPublished)
+
 // CategoryResponse represents a category in the response
 type CategoryResponse struct {
 	ID   string `json:"id"`
@@ -13,13 +22,21 @@ type CategoryResponse struct {
 
 // ProductResponse represents the response body for product operations
 type ProductResponse struct {
-	ID          string             `json:"id"`
-	Name        string             `json:"name"`
-	Description string             `json:"description"`
-	Price       uint               `json:"price"`
-	Currency    string             `json:"currency"`
-	Stock       uint               `json:"stock"`
-	Categories  []CategoryResponse `json:"categories"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Price       uint   `json:"price"`
+	Currency    string `json:"currency"`
+	Status      string `json:"status"`
+	// AvailableFrom and AvailableUntil bound the availability window; they are omitted when open
+	AvailableFrom  *time.Time `json:"availableFrom,omitempty"`
+	AvailableUntil *time.Time `json:"availableUntil,omitempty"`
+	// Attributes are the custom attribute values of the product; they are omitted when none is set
+	Attributes map[string]any `json:"attributes,omitempty"`
+	// Images are in display order, the primary image first; they are omitted when the product has none
+	Images     []ImageResponse    `json:"images,omitempty"`
+	Stock      uint               `json:"stock"`
+	Categories []CategoryResponse `json:"categories"`
 }
 
 // ErrorResponse represents an error response
@@ -27,6 +44,76 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// toCategoryResponses maps use case category outputs to responses
+func toCategoryResponses(categories []product.CategoryOutput) []CategoryResponse {
+	responses := make([]CategoryResponse, 0, len(categories))
+	for _, c := range categories {
+		responses = append(responses, CategoryResponse{
+			ID:   c.ID,
+			Name: c.Name,
+		})
+	}
+	return responses
+}
+
+// toProductResponse maps a use case product output to a response
+func toProductResponse(p product.ProductOutput) ProductResponse {
+	return ProductResponse{
+		ID:             p.ID,
+		Name:           p.Name,
+		Description:    p.Description,
+		Price:          p.Price,
+		Currency:       p.Currency,
+		Status:         p.Status,
+		AvailableFrom:  p.AvailableFrom,
+		AvailableUntil: p.AvailableUntil,
+		Attributes:     p.Attributes,
+		Images:         toImageResponses(p.ID, p.Images),
+		Stock:          p.Stock,
+		Categories:     toCategoryResponses(p.Categories),
+	}
+}
+
+// toProductResponses maps use case product outputs to responses
+func toProductResponses(products []product.ProductOutput) []ProductResponse {
+	responses := make([]ProductResponse, 0, len(products))
+	for _, p := range products {
+		responses = append(responses, toProductResponse(p))
+	}
+	return responses
+}
+
+// statusFromError returns the HTTP status code for an error returned by a use case
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound), errors.Is(err, domain.ErrWarehouseNotFound), errors.Is(err, domain.ErrReorderPolicyNotFound),
+		errors.Is(err, domain.ErrAttributeSchemaNotFound), errors.Is(err, domain.ErrImageNotFound), errors.Is(err, domain.ErrTranslationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrImageTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrUnsupportedImage):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, domain.ErrProductExists):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrWarehouseExists), errors.Is(err, domain.ErrWarehouseInUse):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInsufficientStock):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidStatusTransition), errors.Is(err, domain.ErrNotPublishable), errors.Is(err, domain.ErrProductNotPublished),
+		errors.Is(err, domain.ErrProductUnavailable):
+		return http.StatusConflict
+	case domain.IsValidationError(err):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondWithUseCaseError returns an error response for an error returned by a use case
+func respondWithUseCaseError(w http.ResponseWriter, err error) {
+	respondWithError(w, statusFromError(err), err.Error())
+}
+
 // respondWithError returns an error response
 func respondWithError(w http.ResponseWriter, code int, message string) {
 	respondWithJSON(w, code, ErrorResponse{Error: message})
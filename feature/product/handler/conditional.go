@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	product "sago-sample/feature/product/usecase"
+)
+
+// CachePolicy holds the Cache-Control header sent by each cacheable route
+type CachePolicy struct {
+	// Product applies to GET /api/products/{id}
+	Product string
+	// List applies to GET /api/products
+	List string
+	// Category applies to GET /api/categories/{id}/products
+	Category string
+}
+
+// DefaultCachePolicy lets caches store product reads but revalidate them on every use
+func DefaultCachePolicy() CachePolicy {
+	return CachePolicy{
+		Product:  "public, no-cache",
+		List:     "public, no-cache",
+		Category: "public, no-cache",
+	}
+}
+
+// validators are the ETag and Last-Modified of a representation
+type validators struct {
+	etag         string
+	lastModified time.Time
+}
+
+// productValidators computes the validators of one or more products.
+// The strong ETag is derived from the ID, update time and category names of every product plus extra,
+// which identifies anything else that shapes the representation (e.g. the page or the locale).
+// Category names are included since their translations change without touching the products.
+// Last-Modified is the latest update time; it does not change when a product leaves a list,
+// so list clients should prefer If-None-Match.
+func productValidators(products []product.ProductOutput, extra ...string) validators {
+	h := sha256.New()
+	var lastModified time.Time
+	for _, p := range products {
+		h.Write([]byte(p.ID))
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.FormatInt(p.UpdatedAt.UnixNano(), 10)))
+		h.Write([]byte{0})
+		for _, c := range p.Categories {
+			h.Write([]byte(c.Name))
+			h.Write([]byte{0})
+		}
+		if p.UpdatedAt.After(lastModified) {
+			lastModified = p.UpdatedAt
+		}
+	}
+	for _, e := range extra {
+		h.Write([]byte{1})
+		h.Write([]byte(e))
+	}
+
+	return validators{
+		etag:         `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`,
+		lastModified: lastModified,
+	}
+}
+
+// writeCacheHeaders sets the caching headers and reports whether the request's
+// preconditions show the client already has the representation, in which case 304 Not Modified is sent
+func writeCacheHeaders(w http.ResponseWriter, r *http.Request, v validators, cacheControl string) bool {
+	w.Header().Set("ETag", v.etag)
+	if !v.lastModified.IsZero() {
+		w.Header().Set("Last-Modified", v.lastModified.UTC().Format(http.TimeFormat))
+	}
+	if cacheControl != "" {
+		w.Header().Set("Cache-Control", cacheControl)
+	}
+
+	if notModified(r, v) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when it is absent (RFC 9110 section 13.2.2)
+func notModified(r *http.Request, v validators) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == v.etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !v.lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		// Last-Modified has a resolution of one second
+		return !v.lastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
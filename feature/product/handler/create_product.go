@@ -3,10 +3,20 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
-	handler2 "sago-sample/feature/handler"
+
 	product "sago-sample/feature/product/usecase"
 )
 
+// CreateProductRequest represents the request body for creating a product
+type CreateProductRequest struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Price       uint   `json:"price"`
+	Currency    string `json:"currency"`
+	Stock       uint   `json:"stock"`
+}
+
 type CreateProductHandler struct {
 	UseCase *product.CreateProductUseCase
 }
@@ -16,18 +26,36 @@ func NewCreateProductHandler(uc *product.CreateProductUseCase) *CreateProductHan
 }
 
 func (h *CreateProductHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	var in product.CreateProductInput
-	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-		handler2.respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+	var req CreateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 	defer r.Body.Close()
 
+	in := product.CreateProductInput{
+		ID:          req.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Currency:    req.Currency,
+		Stock:       req.Stock,
+	}
+
 	out, err := h.UseCase.Execute(r.Context(), in)
 	if err != nil {
-		handler2.respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithUseCaseError(w, err)
 		return
 	}
 
-	handler2.respondWithJSON(w, http.StatusCreated, out)
+	respondWithJSON(w, http.StatusCreated, ProductResponse{
+		ID:          out.ID,
+		Name:        out.Name,
+		Description: out.Description,
+		Price:       out.Price,
+		Currency:    out.Currency,
+		Status:      out.Status,
+		Stock:       out.Stock,
+		Categories:  []CategoryResponse{},
+	})
 }
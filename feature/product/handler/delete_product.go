@@ -1,28 +1,28 @@
 package handler
 
 import (
+	"github.com/go-chi/chi/v5"
 	"net/http"
-	"sago-sample/api"
-	"strings"
 
 	product "sago-sample/feature/product/usecase"
 )
 
-// DeleteProduct handles the deletion of a product
-func (h *api.ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/products/")
+type DeleteProductHandler struct {
+	UseCase *product.DeleteProductUseCase
+}
+
+func NewDeleteProductHandler(uc *product.DeleteProductUseCase) *DeleteProductHandler {
+	return &DeleteProductHandler{UseCase: uc}
+}
 
+// Handle handles the deletion of a product
+func (h *DeleteProductHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	input := product.DeleteProductInput{
-		ID: id,
+		ID: chi.URLParam(r, "id"),
 	}
 
-	err := h.deleteProductUseCase.Execute(r.Context(), input)
-	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			respondWithError(w, http.StatusNotFound, err.Error())
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+	if err := h.UseCase.Execute(r.Context(), input); err != nil {
+		respondWithUseCaseError(w, err)
 		return
 	}
 
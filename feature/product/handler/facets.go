@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	product "sago-sample/feature/product/usecase"
+)
+
+// FacetsResponse represents the product counts of every facet
+type FacetsResponse struct {
+	// Total is the number of products passing every filter
+	Total      int                      `json:"total"`
+	Categories []CategoryFacetResponse  `json:"categories"`
+	Prices     []PriceFacetResponse     `json:"prices"`
+	Stock      StockFacetResponse       `json:"stock"`
+	Attributes []AttributeFacetResponse `json:"attributes"`
+}
+
+// CategoryFacetResponse represents the number of products in a category
+type CategoryFacetResponse struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// PriceFacetResponse represents the number of products priced in [from, to); to is omitted for the last bucket
+type PriceFacetResponse struct {
+	From  uint `json:"from"`
+	To    uint `json:"to,omitempty"`
+	Count int  `json:"count"`
+}
+
+// StockFacetResponse represents the number of products with and without stock
+type StockFacetResponse struct {
+	InStock    int `json:"inStock"`
+	OutOfStock int `json:"outOfStock"`
+}
+
+// AttributeFacetResponse represents the number of products per value of an attribute
+type AttributeFacetResponse struct {
+	Key    string                        `json:"key"`
+	Values []AttributeValueFacetResponse `json:"values"`
+}
+
+// AttributeValueFacetResponse represents the number of products having an attribute value
+type AttributeValueFacetResponse struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// FacetHandler handles the facet counts of the storefront filters
+type FacetHandler struct {
+	UseCase *product.GetProductFacetsUseCase
+}
+
+func NewFacetHandler(uc *product.GetProductFacetsUseCase) *FacetHandler {
+	return &FacetHandler{UseCase: uc}
+}
+
+// Register adds the facet routes to rtr
+func (h *FacetHandler) Register(rtr chi.Router) {
+	rtr.Get("/api/products/facets", h.HandleGetFacets) // GET    /api/products/facets
+}
+
+// HandleGetFacets counts the published products inside their availability window per category, price bucket,
+// stock availability and attribute value. The filters are the query parameters category, minPrice, maxPrice,
+// currency, inStock and attr.<key>; priceBuckets replaces the configured price bucket bounds.
+func (h *FacetHandler) HandleGetFacets(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	input := product.GetProductFacetsInput{
+		Status:     publicStatus,
+		Available:  true,
+		Currency:   query.Get("currency"),
+		CategoryID: query.Get("category"),
+		Attributes: attributeFilters(query),
+	}
+
+	var err error
+	if input.MinPrice, err = amountParam(query.Get("minPrice")); err != nil {
+		respondWithError(w, http.StatusBadRequest, "minPrice must be a non-negative integer")
+		return
+	}
+	if input.MaxPrice, err = amountParam(query.Get("maxPrice")); err != nil {
+		respondWithError(w, http.StatusBadRequest, "maxPrice must be a non-negative integer")
+		return
+	}
+	if v := query.Get("inStock"); v != "" {
+		inStock, err := strconv.ParseBool(v)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "inStock must be true or false")
+			return
+		}
+		input.InStock = &inStock
+	}
+	if v := query.Get("priceBuckets"); v != "" {
+		for _, part := range strings.Split(v, ",") {
+			bound, err := amountParam(part)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, "priceBuckets must be a comma-separated list of amounts")
+				return
+			}
+			input.PriceBounds = append(input.PriceBounds, bound)
+		}
+	}
+
+	output, err := h.UseCase.Execute(r.Context(), input)
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	response := FacetsResponse{
+		Total:      output.Total,
+		Categories: make([]CategoryFacetResponse, 0, len(output.Categories)),
+		Prices:     make([]PriceFacetResponse, 0, len(output.Prices)),
+		Stock:      StockFacetResponse{InStock: output.InStock, OutOfStock: output.OutOfStock},
+		Attributes: make([]AttributeFacetResponse, 0, len(output.Attributes)),
+	}
+	for _, c := range output.Categories {
+		response.Categories = append(response.Categories, CategoryFacetResponse{ID: c.ID, Name: c.Name, Count: c.Count})
+	}
+	for _, p := range output.Prices {
+		response.Prices = append(response.Prices, PriceFacetResponse{From: p.From, To: p.To, Count: p.Count})
+	}
+	for _, a := range output.Attributes {
+		values := make([]AttributeValueFacetResponse, 0, len(a.Values))
+		for _, v := range a.Values {
+			values = append(values, AttributeValueFacetResponse{Value: v.Value, Count: v.Count})
+		}
+		response.Attributes = append(response.Attributes, AttributeFacetResponse{Key: a.Key, Values: values})
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// amountParam parses an optional amount in minor units; an empty value is zero
+func amountParam(v string) (uint, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(v, 10, 0)
+	return uint(n), err
+}
@@ -1,54 +1,115 @@
 package handler
 
 import (
-	"encoding/json"
+	"fmt"
 	"github.com/go-chi/chi/v5"
 	"net/http"
+	"net/url"
+	domain "sago-sample/feature/product/domain"
 	product "sago-sample/feature/product/usecase"
+	"strconv"
+	"strings"
 )
 
 type GetProductHandler struct {
-	UseCase       *product.GetProductUseCase
-	GetAllUseCase *product.GetAllProductsUseCase
+	UseCase           *product.GetProductUseCase
+	ListUseCase       *product.ListProductsUseCase
+	ByCategoryUseCase *product.GetProductsByCategoryUseCase
+	CachePolicy       CachePolicy
+	// Locales are negotiated with the Accept-Language header of each request to pick the language of the texts
+	Locales domain.Locales
 }
 
-func NewGetProductHandler(uc *product.GetProductUseCase, getAllUc *product.GetAllProductsUseCase) *GetProductHandler {
-	return &GetProductHandler{UseCase: uc, GetAllUseCase: getAllUc}
+func NewGetProductHandler(uc *product.GetProductUseCase, listUc *product.ListProductsUseCase, byCategoryUc *product.GetProductsByCategoryUseCase) *GetProductHandler {
+	return &GetProductHandler{UseCase: uc, ListUseCase: listUc, ByCategoryUseCase: byCategoryUc, CachePolicy: DefaultCachePolicy(), Locales: domain.DefaultLocales}
 }
 
+// HandleGetAll returns the published products inside their availability window, ordered by ID.
+// With ?limit=N only one page is returned and the next page is linked through the Link and X-Next-Cursor headers.
+// Each ?attr.<key>=<value> parameter only keeps the products whose attribute key has that value.
+// The texts are in the locale negotiated from Accept-Language.
 func (h *GetProductHandler) HandleGetAll(w http.ResponseWriter, r *http.Request) {
-	output, err := h.GetAllUseCase.Execute(r.Context())
+	filters := attributeFilters(r.URL.Query())
+	locale := negotiateLocale(w, r, h.Locales)
+	input := product.ListProductsInput{After: r.URL.Query().Get("cursor"), Status: publicStatus, Available: true, Attributes: filters, Locale: locale.String()}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		input.Limit = limit
+	}
+
+	output, err := h.ListUseCase.Execute(r.Context(), input)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(output.Total))
+	if output.NextCursor != "" {
+		next := url.Values{}
+		for key, value := range filters {
+			next.Set(attributeFilterPrefix+key, value)
+		}
+		next.Set("limit", strconv.Itoa(input.Limit))
+		next.Set("cursor", output.NextCursor)
+		w.Header().Set("X-Next-Cursor", output.NextCursor)
+		w.Header().Set("Link", fmt.Sprintf("<%s?%s>; rel=\"next\"", r.URL.Path, next.Encode()))
+	}
+
+	v := productValidators(output.Products, "list", strconv.Itoa(input.Limit), input.After, strconv.Itoa(output.Total), encodeAttributeFilters(filters), locale.String())
+	if writeCacheHeaders(w, r, v, h.CachePolicy.List) {
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, output.Products)
+	respondWithJSON(w, http.StatusOK, toProductResponses(output.Products))
 }
 
+// HandleGetByID returns a published product inside its availability window; other products are not found.
+// The texts are in the locale negotiated from Accept-Language.
 func (h *GetProductHandler) HandleGetByID(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	out, err := h.UseCase.Execute(r.Context(), product.GetProductInput{ID: id})
+	locale := negotiateLocale(w, r, h.Locales)
+	out, err := h.UseCase.Execute(r.Context(), product.GetProductInput{ID: id, Status: publicStatus, Available: true, Locale: locale.String()})
 	if err != nil {
-		if err.Error() == "product not found" {
-			respondWithError(w, http.StatusNotFound, err.Error())
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	p := product.ProductOutput(*out)
+	if writeCacheHeaders(w, r, productValidators([]product.ProductOutput{p}, locale.String()), h.CachePolicy.Product) {
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, out)
+	respondWithJSON(w, http.StatusOK, toProductResponse(p))
 }
 
-// Helper functions for JSON responses
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
+// attributeFilterPrefix prefixes the query parameters filtering products by attribute, e.g. ?attr.color=red
+const attributeFilterPrefix = "attr."
+
+// attributeFilters returns the attribute values to filter by from the attr.<key> query parameters
+func attributeFilters(query url.Values) map[string]string {
+	var filters map[string]string
+	for name, values := range query {
+		key, ok := strings.CutPrefix(name, attributeFilterPrefix)
+		if !ok || len(values) == 0 {
+			continue
+		}
+		if filters == nil {
+			filters = make(map[string]string)
+		}
+		filters[key] = values[0]
+	}
+	return filters
 }
 
-func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	response, _ := json.Marshal(payload)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	w.Write(response)
+// encodeAttributeFilters encodes filters in a stable order for the list validators
+func encodeAttributeFilters(filters map[string]string) string {
+	values := url.Values{}
+	for key, value := range filters {
+		values.Set(key, value)
+	}
+	return values.Encode()
 }
@@ -3,31 +3,37 @@ package handler
 import (
 	"github.com/go-chi/chi/v5"
 	"net/http"
-	"strings"
 
 	product "sago-sample/feature/product/usecase"
 )
 
-func (h ProductHandler) GetProductsByCategory(w http.ResponseWriter, r *http.Request) {
-	categoryID := chi.URLParam(r, "categoryID")
+// HandleByCategory handles the retrieval of the published products of a category inside their availability window,
+// with their texts in the locale negotiated from Accept-Language
+func (h *GetProductHandler) HandleByCategory(w http.ResponseWriter, r *http.Request) {
+	categoryID := chi.URLParam(r, "id")
 
 	if categoryID == "" {
 		respondWithError(w, http.StatusBadRequest, "Category ID is required")
 		return
 	}
 
-	input := usecase.GetProductsByCategoryInput{
+	locale := negotiateLocale(w, r, h.Locales)
+	input := product.GetProductsByCategoryInput{
 		CategoryID: categoryID,
+		Status:     publicStatus,
+		Available:  true,
+		Locale:     locale.String(),
 	}
 
-	// --- ↓↓↓ 以下は元のコードとほぼ同じ ---
-	output, err := h.getProductsByCategoryUseCase.Execute(r.Context(), input)
+	output, err := h.ByCategoryUseCase.Execute(r.Context(), input)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithUseCaseError(w, err)
 		return
 	}
 
-	var response []ProductResponse
+	if writeCacheHeaders(w, r, productValidators(output.Products, "category", categoryID, locale.String()), h.CachePolicy.Category) {
+		return
+	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, http.StatusOK, toProductResponses(output.Products))
 }
@@ -0,0 +1,141 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+
+	usecase "sago-sample/feature/product/usecase"
+)
+
+// Request represents a GraphQL request body
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Handler serves GraphQL queries and mutations over products and categories
+type Handler struct {
+	schema   graphql.Schema
+	resolver *resolver
+}
+
+// NewHandler creates a new GraphQL handler
+func NewHandler(
+	createProductUseCase *usecase.CreateProductUseCase,
+	updateProductUseCase *usecase.UpdateProductUseCase,
+	deleteProductUseCase *usecase.DeleteProductUseCase,
+	getProductUseCase *usecase.GetProductUseCase,
+	getAllProductsUseCase *usecase.GetAllProductsUseCase,
+	addCategoryToProductUseCase *usecase.AddCategoryToProductUseCase,
+	removeCategoryFromProductUseCase *usecase.RemoveCategoryFromProductUseCase,
+	getProductsByCategoryUseCase *usecase.GetProductsByCategoryUseCase,
+) (*Handler, error) {
+	r := &resolver{
+		createProductUseCase:             createProductUseCase,
+		updateProductUseCase:             updateProductUseCase,
+		deleteProductUseCase:             deleteProductUseCase,
+		getProductUseCase:                getProductUseCase,
+		getAllProductsUseCase:            getAllProductsUseCase,
+		addCategoryToProductUseCase:      addCategoryToProductUseCase,
+		removeCategoryFromProductUseCase: removeCategoryFromProductUseCase,
+		getProductsByCategoryUseCase:     getProductsByCategoryUseCase,
+	}
+
+	schema, err := newSchema(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{schema: schema, resolver: r}, nil
+}
+
+// ServeHTTP executes a GraphQL request sent as a JSON POST body or as GET query parameters.
+// Mutations are only accepted over POST, so that a link or an image cannot trigger them and no cache stores them.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if v := r.URL.Query().Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				respondWithErrors(w, http.StatusBadRequest, "Invalid variables")
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithErrors(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		defer r.Body.Close()
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		respondWithErrors(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if req.Query == "" {
+		respondWithErrors(w, http.StatusBadRequest, "Query is required")
+		return
+	}
+	if r.Method == http.MethodGet && isMutation(req.Query, req.OperationName) {
+		w.Header().Set("Allow", "POST")
+		respondWithErrors(w, http.StatusMethodNotAllowed, "Mutations must be sent with POST")
+		return
+	}
+
+	result := h.Execute(r.Context(), req)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// Execute runs a GraphQL request with a fresh set of batch loaders
+func (h *Handler) Execute(ctx context.Context, req Request) *graphql.Result {
+	ctx = context.WithValue(ctx, loadersKey{}, h.resolver.newLoaders())
+
+	return graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+}
+
+// isMutation reports whether the operation of a request selected by operationName is a mutation.
+// Without an operation name any mutation counts; a query that does not parse is left to Execute to report.
+func isMutation(query, operationName string) bool {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return false
+	}
+
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok || op.Operation != ast.OperationTypeMutation {
+			continue
+		}
+		if operationName == "" || (op.Name != nil && op.Name.Value == operationName) {
+			return true
+		}
+	}
+	return false
+}
+
+// respondWithErrors returns a GraphQL-style error response
+func respondWithErrors(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []map[string]string{{"message": message}},
+	})
+}
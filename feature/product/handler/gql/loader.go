@@ -0,0 +1,85 @@
+package gql
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchFunc loads the values of many keys at once
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects keys requested while a GraphQL level is resolved and loads them with a single batch call.
+// Values are cached for the lifetime of the loader, so a Loader must be created per request.
+type Loader[K comparable, V any] struct {
+	batch   BatchFunc[K, V]
+	mutex   sync.Mutex
+	pending []K
+	results map[K]V
+	errs    map[K]error
+}
+
+// NewLoader creates a new Loader using the given batch function
+func NewLoader[K comparable, V any](batch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		batch:   batch,
+		results: make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+// Load queues key and returns a thunk resolving its value.
+// The first thunk that is called dispatches one batch for every key queued so far.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	l.mutex.Lock()
+	if !l.known(key) && !l.queued(key) {
+		l.pending = append(l.pending, key)
+	}
+	l.mutex.Unlock()
+
+	return func() (V, error) {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		if !l.known(key) {
+			l.dispatch(ctx)
+		}
+		return l.results[key], l.errs[key]
+	}
+}
+
+// dispatch runs the batch function for the pending keys; the caller must hold the mutex
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+	if len(keys) == 0 {
+		return
+	}
+
+	values, err := l.batch(ctx, keys)
+	for _, k := range keys {
+		if err != nil {
+			l.errs[k] = err
+			continue
+		}
+		l.results[k] = values[k]
+	}
+}
+
+// known checks if key has already been loaded; the caller must hold the mutex
+func (l *Loader[K, V]) known(key K) bool {
+	if _, ok := l.results[key]; ok {
+		return true
+	}
+	_, ok := l.errs[key]
+	return ok
+}
+
+// queued checks if key is waiting for the next batch; the caller must hold the mutex
+func (l *Loader[K, V]) queued(key K) bool {
+	for _, k := range l.pending {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,397 @@
+package gql
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+
+	domain "sago-sample/feature/product/domain"
+	usecase "sago-sample/feature/product/usecase"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	cursorPrefix    = "product:"
+	// publicStatus is the only status of the products the queries return, as the public REST reads
+	publicStatus = string(domain.StatusPublished)
+)
+
+// resolver resolves GraphQL fields by calling the product use cases
+type resolver struct {
+	createProductUseCase             *usecase.CreateProductUseCase
+	updateProductUseCase             *usecase.UpdateProductUseCase
+	deleteProductUseCase             *usecase.DeleteProductUseCase
+	getProductUseCase                *usecase.GetProductUseCase
+	getAllProductsUseCase            *usecase.GetAllProductsUseCase
+	addCategoryToProductUseCase      *usecase.AddCategoryToProductUseCase
+	removeCategoryFromProductUseCase *usecase.RemoveCategoryFromProductUseCase
+	getProductsByCategoryUseCase     *usecase.GetProductsByCategoryUseCase
+}
+
+// loaders holds the per-request batch loaders
+type loaders struct {
+	categoryProducts *Loader[string, []usecase.ProductOutput]
+}
+
+type loadersKey struct{}
+
+// newLoaders creates the batch loaders for one request
+func (r *resolver) newLoaders() *loaders {
+	return &loaders{
+		categoryProducts: NewLoader(r.batchCategoryProducts),
+	}
+}
+
+// batchCategoryProducts loads the products of many categories with a single use case call
+func (r *resolver) batchCategoryProducts(ctx context.Context, categoryIDs []string) (map[string][]usecase.ProductOutput, error) {
+	output, err := r.getAllProductsUseCase.Execute(ctx, usecase.GetAllProductsInput{Status: publicStatus, Available: true})
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(categoryIDs))
+	for _, id := range categoryIDs {
+		wanted[id] = true
+	}
+
+	result := make(map[string][]usecase.ProductOutput, len(categoryIDs))
+	for _, id := range categoryIDs {
+		result[id] = []usecase.ProductOutput{}
+	}
+	for _, p := range sortProducts(output.Products) {
+		for _, c := range p.Categories {
+			if wanted[c.ID] {
+				result[c.ID] = append(result[c.ID], p)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// loadersFrom returns the loaders stored in ctx, creating fresh ones if the context has none
+func (r *resolver) loadersFrom(ctx context.Context) *loaders {
+	if l, ok := ctx.Value(loadersKey{}).(*loaders); ok {
+		return l
+	}
+	return r.newLoaders()
+}
+
+// Product fields
+
+func (r *resolver) productID(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(usecase.ProductOutput).ID, nil
+}
+
+func (r *resolver) productName(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(usecase.ProductOutput).Name, nil
+}
+
+func (r *resolver) productDescription(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(usecase.ProductOutput).Description, nil
+}
+
+// productSelf passes the product down so that Price and Stock can read from it
+func (r *resolver) productSelf(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source, nil
+}
+
+func (r *resolver) productCategories(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(usecase.ProductOutput).Categories, nil
+}
+
+func (r *resolver) priceAmount(p graphql.ResolveParams) (interface{}, error) {
+	return int(p.Source.(usecase.ProductOutput).Price), nil
+}
+
+func (r *resolver) priceCurrency(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(usecase.ProductOutput).Currency, nil
+}
+
+func (r *resolver) stockQuantity(p graphql.ResolveParams) (interface{}, error) {
+	return int(p.Source.(usecase.ProductOutput).Stock), nil
+}
+
+func (r *resolver) stockAvailable(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(usecase.ProductOutput).Stock > 0, nil
+}
+
+// Category fields
+
+func (r *resolver) categoryID(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(usecase.CategoryOutput).ID, nil
+}
+
+func (r *resolver) categoryName(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(usecase.CategoryOutput).Name, nil
+}
+
+// categoryProducts defers the lookup to the request's loader so sibling categories share one batch
+func (r *resolver) categoryProducts(p graphql.ResolveParams) (interface{}, error) {
+	category := p.Source.(usecase.CategoryOutput)
+	thunk := r.loadersFrom(p.Context).categoryProducts.Load(p.Context, category.ID)
+
+	return func() (interface{}, error) {
+		return thunk()
+	}, nil
+}
+
+// Queries
+
+func (r *resolver) queryProduct(p graphql.ResolveParams) (interface{}, error) {
+	output, err := r.getProductUseCase.Execute(p.Context, usecase.GetProductInput{ID: p.Args["id"].(string), Status: publicStatus, Available: true})
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return usecase.ProductOutput{
+		ID:          output.ID,
+		Name:        output.Name,
+		Description: output.Description,
+		Price:       output.Price,
+		Currency:    output.Currency,
+		Stock:       output.Stock,
+		Categories:  output.Categories,
+	}, nil
+}
+
+func (r *resolver) queryProducts(p graphql.ResolveParams) (interface{}, error) {
+	first, _ := p.Args["first"].(int)
+	if first < 0 {
+		return nil, errors.New("first cannot be negative")
+	}
+	if first > maxPageSize {
+		first = maxPageSize
+	}
+
+	afterID := ""
+	if after, ok := p.Args["after"].(string); ok && after != "" {
+		id, err := decodeCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		afterID = id
+	}
+
+	output, err := r.getAllProductsUseCase.Execute(p.Context, usecase.GetAllProductsInput{Status: publicStatus, Available: true})
+	if err != nil {
+		return nil, err
+	}
+	products := sortProducts(output.Products)
+
+	start := 0
+	if afterID != "" {
+		start = sort.Search(len(products), func(i int) bool { return products[i].ID > afterID })
+	}
+	end := start + first
+	if end > len(products) {
+		end = len(products)
+	}
+
+	edges := make([]map[string]interface{}, 0, end-start)
+	var endCursor interface{}
+	for _, product := range products[start:end] {
+		cursor := encodeCursor(product.ID)
+		edges = append(edges, map[string]interface{}{
+			"cursor": cursor,
+			"node":   product,
+		})
+		endCursor = cursor
+	}
+
+	return map[string]interface{}{
+		"edges": edges,
+		"pageInfo": map[string]interface{}{
+			"hasNextPage": end < len(products),
+			"endCursor":   endCursor,
+		},
+		"totalCount": len(products),
+	}, nil
+}
+
+func (r *resolver) queryProductsByCategory(p graphql.ResolveParams) (interface{}, error) {
+	output, err := r.getProductsByCategoryUseCase.Execute(p.Context, usecase.GetProductsByCategoryInput{
+		CategoryID: p.Args["categoryId"].(string),
+		Status:     publicStatus,
+		Available:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sortProducts(output.Products), nil
+}
+
+// Mutations
+
+func (r *resolver) mutateCreateProduct(p graphql.ResolveParams) (interface{}, error) {
+	input, err := productInputFrom(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := r.createProductUseCase.Execute(p.Context, usecase.CreateProductInput{
+		ID:          p.Args["id"].(string),
+		Name:        input.Name,
+		Description: input.Description,
+		Price:       input.Price,
+		Currency:    input.Currency,
+		Stock:       input.Stock,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return usecase.ProductOutput{
+		ID:          output.ID,
+		Name:        output.Name,
+		Description: output.Description,
+		Price:       output.Price,
+		Currency:    output.Currency,
+		Stock:       output.Stock,
+		Categories:  []usecase.CategoryOutput{},
+	}, nil
+}
+
+func (r *resolver) mutateUpdateProduct(p graphql.ResolveParams) (interface{}, error) {
+	input, err := productInputFrom(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := r.updateProductUseCase.Execute(p.Context, usecase.UpdateProductInput{
+		ID:          p.Args["id"].(string),
+		Name:        input.Name,
+		Description: input.Description,
+		Price:       input.Price,
+		Currency:    input.Currency,
+		Stock:       input.Stock,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return usecase.ProductOutput{
+		ID:          output.ID,
+		Name:        output.Name,
+		Description: output.Description,
+		Price:       output.Price,
+		Currency:    output.Currency,
+		Stock:       output.Stock,
+		Categories:  output.Categories,
+	}, nil
+}
+
+func (r *resolver) mutateDeleteProduct(p graphql.ResolveParams) (interface{}, error) {
+	if err := r.deleteProductUseCase.Execute(p.Context, usecase.DeleteProductInput{ID: p.Args["id"].(string)}); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+func (r *resolver) mutateAddCategoryToProduct(p graphql.ResolveParams) (interface{}, error) {
+	output, err := r.addCategoryToProductUseCase.Execute(p.Context, usecase.AddCategoryToProductInput{
+		ProductID:    p.Args["productId"].(string),
+		CategoryID:   p.Args["categoryId"].(string),
+		CategoryName: p.Args["categoryName"].(string),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return usecase.ProductOutput{
+		ID:          output.ProductID,
+		Name:        output.Name,
+		Description: output.Description,
+		Price:       output.Price,
+		Currency:    output.Currency,
+		Stock:       output.Stock,
+		Categories:  output.Categories,
+	}, nil
+}
+
+func (r *resolver) mutateRemoveCategoryFromProduct(p graphql.ResolveParams) (interface{}, error) {
+	output, err := r.removeCategoryFromProductUseCase.Execute(p.Context, usecase.RemoveCategoryFromProductInput{
+		ProductID:  p.Args["productId"].(string),
+		CategoryID: p.Args["categoryId"].(string),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return usecase.ProductOutput{
+		ID:          output.ProductID,
+		Name:        output.Name,
+		Description: output.Description,
+		Price:       output.Price,
+		Currency:    output.Currency,
+		Stock:       output.Stock,
+		Categories:  output.Categories,
+	}, nil
+}
+
+// productInput is the decoded ProductInput argument
+type productInput struct {
+	Name        string
+	Description string
+	Price       uint
+	Currency    string
+	Stock       uint
+}
+
+// productInputFrom decodes the ProductInput argument and rejects negative numbers
+func productInputFrom(arg interface{}) (productInput, error) {
+	m, _ := arg.(map[string]interface{})
+
+	price, _ := m["price"].(int)
+	if price < 0 {
+		return productInput{}, errors.New("price cannot be negative")
+	}
+	stock, _ := m["stock"].(int)
+	if stock < 0 {
+		return productInput{}, errors.New("stock cannot be negative")
+	}
+
+	name, _ := m["name"].(string)
+	description, _ := m["description"].(string)
+	currency, _ := m["currency"].(string)
+
+	return productInput{
+		Name:        name,
+		Description: description,
+		Price:       uint(price),
+		Currency:    currency,
+		Stock:       uint(stock),
+	}, nil
+}
+
+// sortProducts orders products by ID so that pages are stable
+func sortProducts(products []usecase.ProductOutput) []usecase.ProductOutput {
+	sorted := make([]usecase.ProductOutput, len(products))
+	copy(sorted, products)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	return sorted
+}
+
+// encodeCursor returns the opaque cursor of a product
+func encodeCursor(id string) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + id))
+}
+
+// decodeCursor returns the product ID of a cursor
+func decodeCursor(cursor string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(b), cursorPrefix) {
+		return "", fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return strings.TrimPrefix(string(b), cursorPrefix), nil
+}
@@ -0,0 +1,173 @@
+package gql
+
+import (
+	"github.com/graphql-go/graphql"
+)
+
+// newSchema builds the GraphQL schema for products and categories
+func newSchema(r *resolver) (graphql.Schema, error) {
+	priceType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Price",
+		Description: "Monetary value of a product",
+		Fields: graphql.Fields{
+			"amount":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: r.priceAmount},
+			"currency": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: r.priceCurrency},
+		},
+	})
+
+	stockType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Stock",
+		Description: "Available quantity of a product",
+		Fields: graphql.Fields{
+			"quantity":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: r.stockQuantity},
+			"available": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: r.stockAvailable},
+		},
+	})
+
+	categoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Category",
+		Description: "Product category",
+		Fields: graphql.Fields{
+			"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: r.categoryID},
+			"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: r.categoryName},
+		},
+	})
+
+	productType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Product",
+		Description: "Product in the catalog",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: r.productID},
+			"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: r.productName},
+			"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: r.productDescription},
+			"price":       &graphql.Field{Type: graphql.NewNonNull(priceType), Resolve: r.productSelf},
+			"stock":       &graphql.Field{Type: graphql.NewNonNull(stockType), Resolve: r.productSelf},
+			"categories": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(categoryType))),
+				Resolve: r.productCategories,
+			},
+		},
+	})
+
+	// Category.products refers back to Product, so it is added once both types exist
+	categoryType.AddFieldConfig("products", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(productType))),
+		Description: "Products in this category, loaded in one batch per request",
+		Resolve:     r.categoryProducts,
+	})
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"endCursor":   &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	productEdgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProductEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: graphql.NewNonNull(productType)},
+		},
+	})
+
+	productConnectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProductConnection",
+		Fields: graphql.Fields{
+			"edges":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(productEdgeType)))},
+			"pageInfo":   &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	productInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ProductInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.InputObjectFieldConfig{Type: graphql.String, DefaultValue: ""},
+			"price":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+			"currency":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"stock":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"product": &graphql.Field{
+				Type: productType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: r.queryProduct,
+			},
+			"products": &graphql.Field{
+				Type: graphql.NewNonNull(productConnectionType),
+				Args: graphql.FieldConfigArgument{
+					"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+					"after": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: r.queryProducts,
+			},
+			"productsByCategory": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(productType))),
+				Args: graphql.FieldConfigArgument{
+					"categoryId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: r.queryProductsByCategory,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createProduct": &graphql.Field{
+				Type: graphql.NewNonNull(productType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(productInputType)},
+				},
+				Resolve: r.mutateCreateProduct,
+			},
+			"updateProduct": &graphql.Field{
+				Type: graphql.NewNonNull(productType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(productInputType)},
+				},
+				Resolve: r.mutateUpdateProduct,
+			},
+			"deleteProduct": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: r.mutateDeleteProduct,
+			},
+			"addCategoryToProduct": &graphql.Field{
+				Type: graphql.NewNonNull(productType),
+				Args: graphql.FieldConfigArgument{
+					"productId":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"categoryId":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"categoryName": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: r.mutateAddCategoryToProduct,
+			},
+			"removeCategoryFromProduct": &graphql.Field{
+				Type: graphql.NewNonNull(productType),
+				Args: graphql.FieldConfigArgument{
+					"productId":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"categoryId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: r.mutateRemoveCategoryFromProduct,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
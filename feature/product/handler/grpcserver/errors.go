@@ -0,0 +1,33 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	domain "sago-sample/feature/product/domain"
+)
+
+// toStatusError maps an error returned by a use case to a gRPC status error
+func toStatusError(err error) error {
+	msg := err.Error()
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, msg)
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, msg)
+	case errors.Is(err, domain.ErrProductNotFound):
+		return status.Error(codes.NotFound, msg)
+	case errors.Is(err, domain.ErrProductExists):
+		return status.Error(codes.AlreadyExists, msg)
+	case errors.Is(err, domain.ErrInsufficientStock):
+		return status.Error(codes.FailedPrecondition, msg)
+	case domain.IsValidationError(err):
+		return status.Error(codes.InvalidArgument, msg)
+	default:
+		return status.Error(codes.Internal, msg)
+	}
+}
@@ -0,0 +1,247 @@
+package grpcserver
+
+import (
+	"context"
+
+	domain "sago-sample/feature/product/domain"
+	usecase "sago-sample/feature/product/usecase"
+	productv1 "sago-sample/proto/product/v1"
+)
+
+// publicStatus is the only status of the products GetProduct and ListProducts return, as the public REST reads
+const publicStatus = string(domain.StatusPublished)
+
+// ProductServer implements productv1.ProductServiceServer on top of the product use cases
+type ProductServer struct {
+	productv1.UnimplementedProductServiceServer
+
+	createProductUseCase             *usecase.CreateProductUseCase
+	updateProductUseCase             *usecase.UpdateProductUseCase
+	deleteProductUseCase             *usecase.DeleteProductUseCase
+	getProductUseCase                *usecase.GetProductUseCase
+	getAllProductsUseCase            *usecase.GetAllProductsUseCase
+	addCategoryToProductUseCase      *usecase.AddCategoryToProductUseCase
+	removeCategoryFromProductUseCase *usecase.RemoveCategoryFromProductUseCase
+	getProductsByCategoryUseCase     *usecase.GetProductsByCategoryUseCase
+}
+
+// NewProductServer creates a new ProductServer
+func NewProductServer(
+	createProductUseCase *usecase.CreateProductUseCase,
+	updateProductUseCase *usecase.UpdateProductUseCase,
+	deleteProductUseCase *usecase.DeleteProductUseCase,
+	getProductUseCase *usecase.GetProductUseCase,
+	getAllProductsUseCase *usecase.GetAllProductsUseCase,
+	addCategoryToProductUseCase *usecase.AddCategoryToProductUseCase,
+	removeCategoryFromProductUseCase *usecase.RemoveCategoryFromProductUseCase,
+	getProductsByCategoryUseCase *usecase.GetProductsByCategoryUseCase,
+) *ProductServer {
+	return &ProductServer{
+		createProductUseCase:             createProductUseCase,
+		updateProductUseCase:             updateProductUseCase,
+		deleteProductUseCase:             deleteProductUseCase,
+		getProductUseCase:                getProductUseCase,
+		getAllProductsUseCase:            getAllProductsUseCase,
+		addCategoryToProductUseCase:      addCategoryToProductUseCase,
+		removeCategoryFromProductUseCase: removeCategoryFromProductUseCase,
+		getProductsByCategoryUseCase:     getProductsByCategoryUseCase,
+	}
+}
+
+// GetProduct returns a single published product available to customers by ID
+func (s *ProductServer) GetProduct(ctx context.Context, req *productv1.GetProductRequest) (*productv1.GetProductResponse, error) {
+	output, err := s.getProductUseCase.Execute(ctx, usecase.GetProductInput{ID: req.GetId(), Status: publicStatus, Available: true})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &productv1.GetProductResponse{
+		Product: &productv1.Product{
+			Id:          output.ID,
+			Name:        output.Name,
+			Description: output.Description,
+			Price:       uint64(output.Price),
+			Currency:    output.Currency,
+			Stock:       uint64(output.Stock),
+			Categories:  toCategoryMessages(output.Categories),
+		},
+	}, nil
+}
+
+// ListProducts streams the published products available to customers, or those of one category when CategoryId is set
+func (s *ProductServer) ListProducts(req *productv1.ListProductsRequest, stream productv1.ProductService_ListProductsServer) error {
+	ctx := stream.Context()
+
+	var products []usecase.ProductOutput
+	if req.GetCategoryId() != "" {
+		output, err := s.getProductsByCategoryUseCase.Execute(ctx, usecase.GetProductsByCategoryInput{
+			CategoryID: req.GetCategoryId(),
+			Status:     publicStatus,
+			Available:  true,
+		})
+		if err != nil {
+			return toStatusError(err)
+		}
+		products = output.Products
+	} else {
+		output, err := s.getAllProductsUseCase.Execute(ctx, usecase.GetAllProductsInput{Status: publicStatus, Available: true})
+		if err != nil {
+			return toStatusError(err)
+		}
+		products = output.Products
+	}
+
+	for _, p := range products {
+		if err := ctx.Err(); err != nil {
+			return toStatusError(err)
+		}
+		if err := stream.Send(&productv1.ListProductsResponse{Product: toProductMessage(p)}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateProduct creates a new product
+func (s *ProductServer) CreateProduct(ctx context.Context, req *productv1.CreateProductRequest) (*productv1.CreateProductResponse, error) {
+	input := usecase.CreateProductInput{
+		ID:          req.GetId(),
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Price:       uint(req.GetPrice()),
+		Currency:    req.GetCurrency(),
+		Stock:       uint(req.GetStock()),
+	}
+
+	output, err := s.createProductUseCase.Execute(ctx, input)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &productv1.CreateProductResponse{
+		Product: &productv1.Product{
+			Id:          output.ID,
+			Name:        output.Name,
+			Description: output.Description,
+			Price:       uint64(output.Price),
+			Currency:    output.Currency,
+			Stock:       uint64(output.Stock),
+			Categories:  []*productv1.Category{},
+		},
+	}, nil
+}
+
+// UpdateProduct replaces the fields of an existing product
+func (s *ProductServer) UpdateProduct(ctx context.Context, req *productv1.UpdateProductRequest) (*productv1.UpdateProductResponse, error) {
+	input := usecase.UpdateProductInput{
+		ID:          req.GetId(),
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Price:       uint(req.GetPrice()),
+		Currency:    req.GetCurrency(),
+		Stock:       uint(req.GetStock()),
+	}
+
+	output, err := s.updateProductUseCase.Execute(ctx, input)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &productv1.UpdateProductResponse{
+		Product: &productv1.Product{
+			Id:          output.ID,
+			Name:        output.Name,
+			Description: output.Description,
+			Price:       uint64(output.Price),
+			Currency:    output.Currency,
+			Stock:       uint64(output.Stock),
+			Categories:  toCategoryMessages(output.Categories),
+		},
+	}, nil
+}
+
+// DeleteProduct deletes a product
+func (s *ProductServer) DeleteProduct(ctx context.Context, req *productv1.DeleteProductRequest) (*productv1.DeleteProductResponse, error) {
+	if err := s.deleteProductUseCase.Execute(ctx, usecase.DeleteProductInput{ID: req.GetId()}); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &productv1.DeleteProductResponse{}, nil
+}
+
+// AddCategory assigns a category to a product
+func (s *ProductServer) AddCategory(ctx context.Context, req *productv1.AddCategoryRequest) (*productv1.AddCategoryResponse, error) {
+	input := usecase.AddCategoryToProductInput{
+		ProductID:    req.GetProductId(),
+		CategoryID:   req.GetCategoryId(),
+		CategoryName: req.GetCategoryName(),
+	}
+
+	output, err := s.addCategoryToProductUseCase.Execute(ctx, input)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &productv1.AddCategoryResponse{
+		Product: &productv1.Product{
+			Id:          output.ProductID,
+			Name:        output.Name,
+			Description: output.Description,
+			Price:       uint64(output.Price),
+			Currency:    output.Currency,
+			Stock:       uint64(output.Stock),
+			Categories:  toCategoryMessages(output.Categories),
+		},
+	}, nil
+}
+
+// RemoveCategory removes a category from a product
+func (s *ProductServer) RemoveCategory(ctx context.Context, req *productv1.RemoveCategoryRequest) (*productv1.RemoveCategoryResponse, error) {
+	input := usecase.RemoveCategoryFromProductInput{
+		ProductID:  req.GetProductId(),
+		CategoryID: req.GetCategoryId(),
+	}
+
+	output, err := s.removeCategoryFromProductUseCase.Execute(ctx, input)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &productv1.RemoveCategoryResponse{
+		Product: &productv1.Product{
+			Id:          output.ProductID,
+			Name:        output.Name,
+			Description: output.Description,
+			Price:       uint64(output.Price),
+			Currency:    output.Currency,
+			Stock:       uint64(output.Stock),
+			Categories:  toCategoryMessages(output.Categories),
+		},
+	}, nil
+}
+
+// toProductMessage maps a use case product output to its protobuf message
+func toProductMessage(p usecase.ProductOutput) *productv1.Product {
+	return &productv1.Product{
+		Id:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       uint64(p.Price),
+		Currency:    p.Currency,
+		Stock:       uint64(p.Stock),
+		Categories:  toCategoryMessages(p.Categories),
+	}
+}
+
+// toCategoryMessages maps use case category outputs to protobuf messages
+func toCategoryMessages(categories []usecase.CategoryOutput) []*productv1.Category {
+	messages := make([]*productv1.Category, 0, len(categories))
+	for _, c := range categories {
+		messages = append(messages, &productv1.Category{
+			Id:   c.ID,
+			Name: c.Name,
+		})
+	}
+	return messages
+}
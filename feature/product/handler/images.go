@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+
+	product "sago-sample/feature/product/usecase"
+)
+
+// imageFormField is the multipart field holding an uploaded image
+const imageFormField = "image"
+
+// imageCacheControl lets clients keep image files for a year: an image ID always names the same file
+const imageCacheControl = "public, max-age=31536000, immutable"
+
+// sniffedImageTypes are the media types accepted by the upload endpoint, as sniffed by http.DetectContentType
+var sniffedImageTypes = map[string]bool{"image/png": true, "image/jpeg": true, "image/gif": true}
+
+// ImageResponse represents an image of a product in the response
+type ImageResponse struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+	ContentType  string `json:"contentType"`
+	Size         int64  `json:"size"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	// Primary is true for the first image of the product
+	Primary bool `json:"primary"`
+}
+
+// ReorderImagesRequest represents the request body for reordering the images of a product
+type ReorderImagesRequest struct {
+	// ImageIDs lists every image of the product in the new order; the first one becomes the primary image
+	ImageIDs []string `json:"imageIds"`
+}
+
+// toImageResponses maps the image outputs of a product to responses linking to their files
+func toImageResponses(productID string, images []product.ImageOutput) []ImageResponse {
+	if len(images) == 0 {
+		return nil
+	}
+	responses := make([]ImageResponse, 0, len(images))
+	for i, img := range images {
+		responses = append(responses, toImageResponse(productID, img, i == 0))
+	}
+	return responses
+}
+
+// toImageResponse maps an image output to a response
+func toImageResponse(productID string, img product.ImageOutput, primary bool) ImageResponse {
+	link := "/api/products/" + url.PathEscape(productID) + "/images/" + url.PathEscape(img.ID)
+	return ImageResponse{
+		ID:           img.ID,
+		URL:          link,
+		ThumbnailURL: link + "/thumbnail",
+		ContentType:  img.ContentType,
+		Size:         img.Size,
+		Width:        img.Width,
+		Height:       img.Height,
+		Primary:      primary,
+	}
+}
+
+// ImageHandler handles the images of products
+type ImageHandler struct {
+	UploadUseCase  *product.UploadProductImageUseCase
+	RemoveUseCase  *product.RemoveProductImageUseCase
+	ReorderUseCase *product.ReorderProductImagesUseCase
+	GetUseCase     *product.GetProductImageUseCase
+	// MaxSize is the largest image file accepted by the upload endpoint, in bytes
+	MaxSize int64
+}
+
+func NewImageHandler(
+	upload *product.UploadProductImageUseCase,
+	remove *product.RemoveProductImageUseCase,
+	reorder *product.ReorderProductImagesUseCase,
+	get *product.GetProductImageUseCase,
+	maxSize int64,
+) *ImageHandler {
+	return &ImageHandler{
+		UploadUseCase:  upload,
+		RemoveUseCase:  remove,
+		ReorderUseCase: reorder,
+		GetUseCase:     get,
+		MaxSize:        maxSize,
+	}
+}
+
+// Register adds the image routes to rtr
+func (h *ImageHandler) Register(rtr chi.Router) {
+	rtr.Post("/api/products/{id}/images", h.HandleUpload)                       // POST   /api/products/{id}/images
+	rtr.Put("/api/products/{id}/images", h.HandleReorder)                       // PUT    /api/products/{id}/images
+	rtr.Delete("/api/products/{id}/images/{imageId}", h.HandleRemove)           // DELETE /api/products/{id}/images/{imageId}
+	rtr.Get("/api/products/{id}/images/{imageId}", h.HandleGet(false))          // GET    /api/products/{id}/images/{imageId}
+	rtr.Get("/api/products/{id}/images/{imageId}/thumbnail", h.HandleGet(true)) // GET    /api/products/{id}/images/{imageId}/thumbnail
+}
+
+// HandleUpload adds the image in the multipart field "image" to a product.
+// The type is sniffed from the content, whatever the part declares.
+func (h *ImageHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
+	productID := chi.URLParam(r, "id")
+
+	if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "multipart/form-data" {
+		respondWithError(w, http.StatusUnsupportedMediaType, "content type must be multipart/form-data")
+		return
+	}
+	// The rest of the form is small; the image itself is limited while it is read
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxSize+64<<10)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid multipart body")
+		return
+	}
+
+	var data []byte
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			respondWithUploadError(w, err)
+			return
+		}
+		if part.FormName() != imageFormField {
+			continue
+		}
+		data, err = io.ReadAll(io.LimitReader(part, h.MaxSize+1))
+		if err != nil {
+			respondWithUploadError(w, err)
+			return
+		}
+		break
+	}
+	if data == nil {
+		respondWithError(w, http.StatusBadRequest, `multipart field "image" is required`)
+		return
+	}
+	if int64(len(data)) > h.MaxSize {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "image too large")
+		return
+	}
+	if !sniffedImageTypes[http.DetectContentType(data)] {
+		respondWithError(w, http.StatusUnsupportedMediaType, "unsupported image type, want image/png, image/jpeg or image/gif")
+		return
+	}
+
+	output, err := h.UploadUseCase.Execute(r.Context(), product.UploadProductImageInput{ProductID: productID, Data: data})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	primary := len(output.Product.Images) > 0 && output.Product.Images[0].ID == output.Image.ID
+	response := toImageResponse(productID, output.Image, primary)
+	w.Header().Set("Location", response.URL)
+	respondWithJSON(w, http.StatusCreated, response)
+}
+
+// respondWithUploadError returns the error of a multipart body that could not be read
+func respondWithUploadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "image too large")
+		return
+	}
+	respondWithError(w, http.StatusBadRequest, "Invalid multipart body")
+}
+
+// HandleReorder puts the images of a product in the order of the request; the first one becomes the primary image
+func (h *ImageHandler) HandleReorder(w http.ResponseWriter, r *http.Request) {
+	var req ReorderImagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	output, err := h.ReorderUseCase.Execute(r.Context(), product.ReorderProductImagesInput{
+		ProductID: chi.URLParam(r, "id"),
+		ImageIDs:  req.ImageIDs,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toProductResponse(*output))
+}
+
+// HandleRemove removes an image of a product and deletes its files
+func (h *ImageHandler) HandleRemove(w http.ResponseWriter, r *http.Request) {
+	_, err := h.RemoveUseCase.Execute(r.Context(), product.RemoveProductImageInput{
+		ProductID: chi.URLParam(r, "id"),
+		ImageID:   chi.URLParam(r, "imageId"),
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleGet returns a handler serving the file of an image, or of its thumbnail, of a published product inside its availability window
+func (h *ImageHandler) HandleGet(thumbnail bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		imageID := chi.URLParam(r, "imageId")
+		output, err := h.GetUseCase.Execute(r.Context(), product.GetProductImageInput{
+			ProductID: chi.URLParam(r, "id"),
+			ImageID:   imageID,
+			Thumbnail: thumbnail,
+			Status:    publicStatus,
+			Available: true,
+		})
+		if err != nil {
+			respondWithUseCaseError(w, err)
+			return
+		}
+		defer output.Content.Close()
+
+		etag := `"` + imageID + `"`
+		if thumbnail {
+			etag = `"` + imageID + `-thumbnail"`
+		}
+		w.Header().Set("Content-Type", output.ContentType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if writeCacheHeaders(w, r, validators{etag: etag, lastModified: output.CreatedAt}, imageCacheControl) {
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		io.Copy(w, output.Content)
+	}
+}
@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	product "sago-sample/feature/product/usecase"
+)
+
+// WarehouseRequest represents the request body for creating or updating a warehouse
+type WarehouseRequest struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+// WarehouseResponse represents a warehouse in the response
+type WarehouseResponse struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+// SetStockLevelRequest represents the request body for setting the stock of a product in a warehouse
+type SetStockLevelRequest struct {
+	Quantity uint `json:"quantity"`
+}
+
+// TransferStockRequest represents the request body for moving stock between two warehouses
+type TransferStockRequest struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Quantity uint   `json:"quantity"`
+}
+
+// AllocateStockRequest represents the request body for decreasing stock across warehouses
+type AllocateStockRequest struct {
+	Quantity   uint     `json:"quantity"`
+	Strategy   string   `json:"strategy,omitempty"`
+	Latitude   *float64 `json:"latitude,omitempty"`
+	Longitude  *float64 `json:"longitude,omitempty"`
+	Warehouses []string `json:"warehouses,omitempty"`
+}
+
+// StockLevelResponse represents the stock of a product in one warehouse
+type StockLevelResponse struct {
+	WarehouseID string `json:"warehouseId"`
+	Quantity    uint   `json:"quantity"`
+}
+
+// InventoryResponse represents the stock of a product per warehouse
+type InventoryResponse struct {
+	ProductID string               `json:"productId"`
+	Total     uint                 `json:"total"`
+	Levels    []StockLevelResponse `json:"levels"`
+}
+
+// AllocationResponse represents the quantity taken from each warehouse and the remaining stock
+type AllocationResponse struct {
+	InventoryResponse
+	Allocations []StockLevelResponse `json:"allocations"`
+}
+
+// InventoryHandler handles warehouses and the stock of products per warehouse
+type InventoryHandler struct {
+	CreateWarehouseUseCase *product.CreateWarehouseUseCase
+	GetWarehouseUseCase    *product.GetWarehouseUseCase
+	ListWarehousesUseCase  *product.ListWarehousesUseCase
+	UpdateWarehouseUseCase *product.UpdateWarehouseUseCase
+	DeleteWarehouseUseCase *product.DeleteWarehouseUseCase
+	GetInventoryUseCase    *product.GetInventoryUseCase
+	SetStockLevelUseCase   *product.SetStockLevelUseCase
+	TransferStockUseCase   *product.TransferStockUseCase
+	AllocateStockUseCase   *product.AllocateStockUseCase
+}
+
+func NewInventoryHandler(
+	createWarehouseUc *product.CreateWarehouseUseCase,
+	getWarehouseUc *product.GetWarehouseUseCase,
+	listWarehousesUc *product.ListWarehousesUseCase,
+	updateWarehouseUc *product.UpdateWarehouseUseCase,
+	deleteWarehouseUc *product.DeleteWarehouseUseCase,
+	getInventoryUc *product.GetInventoryUseCase,
+	setStockLevelUc *product.SetStockLevelUseCase,
+	transferStockUc *product.TransferStockUseCase,
+	allocateStockUc *product.AllocateStockUseCase,
+) *InventoryHandler {
+	return &InventoryHandler{
+		CreateWarehouseUseCase: createWarehouseUc,
+		GetWarehouseUseCase:    getWarehouseUc,
+		ListWarehousesUseCase:  listWarehousesUc,
+		UpdateWarehouseUseCase: updateWarehouseUc,
+		DeleteWarehouseUseCase: deleteWarehouseUc,
+		GetInventoryUseCase:    getInventoryUc,
+		SetStockLevelUseCase:   setStockLevelUc,
+		TransferStockUseCase:   transferStockUc,
+		AllocateStockUseCase:   allocateStockUc,
+	}
+}
+
+// Register adds the warehouse and inventory routes to rtr
+func (h *InventoryHandler) Register(rtr chi.Router) {
+	rtr.Get("/api/warehouses", h.HandleListWarehouses)          // GET    /api/warehouses
+	rtr.Post("/api/warehouses", h.HandleCreateWarehouse)        // POST   /api/warehouses
+	rtr.Get("/api/warehouses/{id}", h.HandleGetWarehouse)       // GET    /api/warehouses/{id}
+	rtr.Put("/api/warehouses/{id}", h.HandleUpdateWarehouse)    // PUT    /api/warehouses/{id}
+	rtr.Delete("/api/warehouses/{id}", h.HandleDeleteWarehouse) // DELETE /api/warehouses/{id}
+
+	rtr.Get("/api/products/{id}/stock", h.HandleGetInventory)                // GET    /api/products/{id}/stock
+	rtr.Put("/api/products/{id}/stock/{warehouseId}", h.HandleSetStockLevel) // PUT    /api/products/{id}/stock/{warehouseId}
+	rtr.Post("/api/products/{id}/stock/transfers", h.HandleTransferStock)    // POST   /api/products/{id}/stock/transfers
+	rtr.Post("/api/products/{id}/stock/allocations", h.HandleAllocateStock)  // POST   /api/products/{id}/stock/allocations
+}
+
+// HandleCreateWarehouse handles the creation of a warehouse
+func (h *InventoryHandler) HandleCreateWarehouse(w http.ResponseWriter, r *http.Request) {
+	var req WarehouseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	output, err := h.CreateWarehouseUseCase.Execute(r.Context(), product.WarehouseInput{
+		ID:        req.ID,
+		Name:      req.Name,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toWarehouseResponse(*output))
+}
+
+// HandleListWarehouses handles listing the warehouses
+func (h *InventoryHandler) HandleListWarehouses(w http.ResponseWriter, r *http.Request) {
+	outputs, err := h.ListWarehousesUseCase.Execute(r.Context())
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	responses := make([]WarehouseResponse, 0, len(outputs))
+	for _, o := range outputs {
+		responses = append(responses, toWarehouseResponse(o))
+	}
+	respondWithJSON(w, http.StatusOK, responses)
+}
+
+// HandleGetWarehouse handles getting a warehouse by ID
+func (h *InventoryHandler) HandleGetWarehouse(w http.ResponseWriter, r *http.Request) {
+	output, err := h.GetWarehouseUseCase.Execute(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toWarehouseResponse(*output))
+}
+
+// HandleUpdateWarehouse handles replacing the name and location of a warehouse
+func (h *InventoryHandler) HandleUpdateWarehouse(w http.ResponseWriter, r *http.Request) {
+	var req WarehouseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	output, err := h.UpdateWarehouseUseCase.Execute(r.Context(), product.WarehouseInput{
+		ID:        chi.URLParam(r, "id"),
+		Name:      req.Name,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toWarehouseResponse(*output))
+}
+
+// HandleDeleteWarehouse handles deleting a warehouse that holds no stock
+func (h *InventoryHandler) HandleDeleteWarehouse(w http.ResponseWriter, r *http.Request) {
+	if err := h.DeleteWarehouseUseCase.Execute(r.Context(), chi.URLParam(r, "id")); err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleGetInventory handles getting the stock of a product per warehouse
+func (h *InventoryHandler) HandleGetInventory(w http.ResponseWriter, r *http.Request) {
+	output, err := h.GetInventoryUseCase.Execute(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toInventoryResponse(*output))
+}
+
+// HandleSetStockLevel handles setting the stock of a product in a warehouse
+func (h *InventoryHandler) HandleSetStockLevel(w http.ResponseWriter, r *http.Request) {
+	var req SetStockLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	output, err := h.SetStockLevelUseCase.Execute(r.Context(), product.SetStockLevelInput{
+		ProductID:   chi.URLParam(r, "id"),
+		WarehouseID: chi.URLParam(r, "warehouseId"),
+		Quantity:    req.Quantity,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toInventoryResponse(*output))
+}
+
+// HandleTransferStock handles moving stock of a product between two warehouses
+func (h *InventoryHandler) HandleTransferStock(w http.ResponseWriter, r *http.Request) {
+	var req TransferStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	output, err := h.TransferStockUseCase.Execute(r.Context(), product.TransferStockInput{
+		ProductID: chi.URLParam(r, "id"),
+		From:      req.From,
+		To:        req.To,
+		Quantity:  req.Quantity,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toInventoryResponse(*output))
+}
+
+// HandleAllocateStock handles decreasing the stock of a product across warehouses
+func (h *InventoryHandler) HandleAllocateStock(w http.ResponseWriter, r *http.Request) {
+	var req AllocateStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	output, err := h.AllocateStockUseCase.Execute(r.Context(), product.AllocateStockInput{
+		ProductID:  chi.URLParam(r, "id"),
+		Quantity:   req.Quantity,
+		Strategy:   req.Strategy,
+		Latitude:   req.Latitude,
+		Longitude:  req.Longitude,
+		Warehouses: req.Warehouses,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, AllocationResponse{
+		InventoryResponse: toInventoryResponse(output.InventoryOutput),
+		Allocations:       toStockLevelResponses(output.Allocations),
+	})
+}
+
+// toWarehouseResponse maps a use case warehouse output to a response
+func toWarehouseResponse(o product.WarehouseOutput) WarehouseResponse {
+	return WarehouseResponse{ID: o.ID, Name: o.Name, Latitude: o.Latitude, Longitude: o.Longitude}
+}
+
+// toInventoryResponse maps a use case inventory output to a response
+func toInventoryResponse(o product.InventoryOutput) InventoryResponse {
+	return InventoryResponse{ProductID: o.ProductID, Total: o.Total, Levels: toStockLevelResponses(o.Levels)}
+}
+
+// toStockLevelResponses maps use case stock level outputs to responses
+func toStockLevelResponses(levels []product.StockLevelOutput) []StockLevelResponse {
+	responses := make([]StockLevelResponse, 0, len(levels))
+	for _, l := range levels {
+		responses = append(responses, StockLevelResponse{WarehouseID: l.WarehouseID, Quantity: l.Quantity})
+	}
+	return responses
+}
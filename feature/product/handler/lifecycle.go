@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	product "sago-sample/feature/product/usecase"
+)
+
+// ChangeStatusRequest represents the request body for moving a product to another lifecycle status
+type ChangeStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// AvailabilityRequest represents the request body for scheduling when a product is visible to customers.
+// A missing bound leaves that side of the window open.
+type AvailabilityRequest struct {
+	AvailableFrom  *time.Time `json:"availableFrom,omitempty"`
+	AvailableUntil *time.Time `json:"availableUntil,omitempty"`
+}
+
+// LifecycleHandler handles the admin endpoints, which see products in every status and move them through their lifecycle
+type LifecycleHandler struct {
+	GetUseCase          *product.GetProductUseCase
+	ListUseCase         *product.ListProductsUseCase
+	ChangeStatusUseCase *product.ChangeProductStatusUseCase
+	AvailabilityUseCase *product.SetProductAvailabilityUseCase
+}
+
+func NewLifecycleHandler(getUc *product.GetProductUseCase, listUc *product.ListProductsUseCase, changeStatusUc *product.ChangeProductStatusUseCase, availabilityUc *product.SetProductAvailabilityUseCase) *LifecycleHandler {
+	return &LifecycleHandler{GetUseCase: getUc, ListUseCase: listUc, ChangeStatusUseCase: changeStatusUc, AvailabilityUseCase: availabilityUc}
+}
+
+// Register adds the admin product routes to rtr
+func (h *LifecycleHandler) Register(rtr chi.Router) {
+	rtr.Get("/api/admin/products", h.HandleList)                              // GET    /api/admin/products
+	rtr.Get("/api/admin/products/{id}", h.HandleGet)                          // GET    /api/admin/products/{id}
+	rtr.Post("/api/admin/products/{id}/status", h.HandleChangeStatus)         // POST   /api/admin/products/{id}/status
+	rtr.Put("/api/admin/products/{id}/availability", h.HandleSetAvailability) // PUT    /api/admin/products/{id}/availability
+}
+
+// HandleList returns the products in every status, whatever their availability window, ordered by ID, or only those of ?status=.
+// Pages work as for GET /api/products.
+func (h *LifecycleHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	input := product.ListProductsInput{After: r.URL.Query().Get("cursor"), Status: r.URL.Query().Get("status")}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		input.Limit = limit
+	}
+
+	output, err := h.ListUseCase.Execute(r.Context(), input)
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(output.Total))
+	if output.NextCursor != "" {
+		next := url.Values{}
+		if input.Status != "" {
+			next.Set("status", input.Status)
+		}
+		next.Set("limit", strconv.Itoa(input.Limit))
+		next.Set("cursor", output.NextCursor)
+		w.Header().Set("X-Next-Cursor", output.NextCursor)
+		w.Header().Set("Link", fmt.Sprintf("<%s?%s>; rel=\"next\"", r.URL.Path, next.Encode()))
+	}
+
+	respondWithJSON(w, http.StatusOK, toProductResponses(output.Products))
+}
+
+// HandleGet returns a product whatever its status and availability window
+func (h *LifecycleHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	out, err := h.GetUseCase.Execute(r.Context(), product.GetProductInput{ID: chi.URLParam(r, "id")})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toProductResponse(product.ProductOutput(*out)))
+}
+
+// HandleChangeStatus publishes, unpublishes or archives a product
+func (h *LifecycleHandler) HandleChangeStatus(w http.ResponseWriter, r *http.Request) {
+	var req ChangeStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	out, err := h.ChangeStatusUseCase.Execute(r.Context(), product.ChangeProductStatusInput{
+		ProductID: chi.URLParam(r, "id"),
+		Status:    req.Status,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toProductResponse(*out))
+}
+
+// HandleSetAvailability replaces the availability window of a product; the window only applies once it is published
+func (h *LifecycleHandler) HandleSetAvailability(w http.ResponseWriter, r *http.Request) {
+	var req AvailabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	out, err := h.AvailabilityUseCase.Execute(r.Context(), product.SetProductAvailabilityInput{
+		ProductID:      chi.URLParam(r, "id"),
+		AvailableFrom:  req.AvailableFrom,
+		AvailableUntil: req.AvailableUntil,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toProductResponse(*out))
+}
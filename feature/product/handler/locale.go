@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	domain "sago-sample/feature/product/domain"
+)
+
+// negotiateLocale picks the supported locale best matching the Accept-Language header of r.
+// The response announces it in Content-Language and varies on Accept-Language.
+func negotiateLocale(w http.ResponseWriter, r *http.Request, locales domain.Locales) domain.Locale {
+	locale := locales.Negotiate(parseAcceptLanguage(r.Header.Get("Accept-Language")))
+	w.Header().Add("Vary", "Accept-Language")
+	if locale != "" {
+		w.Header().Set("Content-Language", locale.String())
+	}
+	return locale
+}
+
+// parseAcceptLanguage returns the locales of an Accept-Language header by decreasing quality (RFC 9110 section 12.5.4).
+// The wildcard, tags that are not locales and tags with q=0 are skipped; equal qualities keep the header order.
+func parseAcceptLanguage(header string) []domain.Locale {
+	type weighted struct {
+		locale  domain.Locale
+		quality float64
+	}
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			v, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = v
+		}
+		locale, err := domain.NewLocale(tag)
+		if err != nil || quality <= 0 {
+			continue
+		}
+		ranges = append(ranges, weighted{locale: locale, quality: quality})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	locales := make([]domain.Locale, 0, len(ranges))
+	for _, r := range ranges {
+		locales = append(locales, r.locale)
+	}
+	return locales
+}
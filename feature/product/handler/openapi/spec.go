@@ -0,0 +1,1711 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+
+	cart "sago-sample/feature/cart/domain"
+	order "sago-sample/feature/order/domain"
+	domain "sago-sample/feature/product/domain"
+	usecase "sago-sample/feature/product/usecase"
+	webhook "sago-sample/feature/webhook/domain"
+)
+
+// Version is the version of the product API described by the document
+const Version = "1.0.0"
+
+var (
+	specOnce sync.Once
+	spec     *Document
+	specJSON []byte
+)
+
+// Spec returns the OpenAPI document of the product API.
+// Value-object constraints are taken from the domain package so the two cannot drift apart.
+func Spec() *Document {
+	specOnce.Do(func() {
+		spec = buildSpec()
+		specJSON, _ = json.MarshalIndent(spec, "", "  ")
+	})
+	return spec
+}
+
+// Handler serves the OpenAPI document as JSON
+func Handler(w http.ResponseWriter, r *http.Request) {
+	Spec()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(specJSON)
+}
+
+func buildSpec() *Document {
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info: Info{
+			Title:       "Product Management API",
+			Description: "Manage products, their categories, their stock per warehouse and its ledger, webhook subscriptions to product events, orders, and shopping carts",
+			Version:     Version,
+		},
+		Paths: make(map[string]*PathItem),
+		Components: Components{
+			Schemas: schemas(),
+		},
+	}
+
+	productID := pathParam("id", "Product ID")
+	locale := &Parameter{Name: "locale", In: "path", Required: true, Description: "Supported locale other than the default one, e.g. fr or pt-BR", Schema: &Schema{Type: "string", Pattern: domain.LocalePattern}}
+
+	productPage := localized(cached(jsonResponse("Products ordered by ID", arrayOf(ref("ProductResponse")))))
+	productPage.Headers["X-Total-Count"] = &Header{Description: "Number of products", Schema: &Schema{Type: "integer"}}
+	productPage.Headers["X-Next-Cursor"] = &Header{Description: "Cursor of the next page, absent on the last page", Schema: &Schema{Type: "string"}}
+	productPage.Headers["Link"] = &Header{Description: "RFC 8288 link to the next page", Schema: &Schema{Type: "string"}}
+	doc.Add(http.MethodGet, "/api/products", &Operation{
+		OperationID: "getAllProducts",
+		Summary:     "Get all published products inside their availability window, optionally one page at a time",
+		Description: "Each attr.<key>=<value> query parameter, e.g. attr.color=red, only keeps the products whose attribute key has that value. " +
+			"Numbers are compared numerically and booleans accept true and false.",
+		Tags: []string{"products"},
+		Parameters: append([]*Parameter{
+			{Name: "limit", In: "query", Description: "Page size; every product is returned when omitted", Schema: &Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(usecase.MaxListProductsLimit)}},
+			{Name: "cursor", In: "query", Description: "Cursor returned by the previous page", Schema: &Schema{Type: "string"}},
+			acceptLanguage(),
+		}, conditionalParams()...),
+		Responses: map[string]*Response{
+			"200": productPage,
+			"304": notModified(),
+			"400": errorResponse("Invalid request"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/products/facets", &Operation{
+		OperationID: "getProductFacets",
+		Summary:     "Count the published products inside their availability window per category, price bucket, stock availability and attribute value",
+		Description: "Each facet is counted with every filter except its own, so its counts tell how many products each other choice would give. " +
+			"Attribute filters are written attr.<key>=<value> as on getAllProducts.",
+		Tags: []string{"products"},
+		Parameters: []*Parameter{
+			{Name: "category", In: "query", Description: "Only keep the products of this category", Schema: &Schema{Type: "string"}},
+			{Name: "minPrice", In: "query", Description: "Lowest price in minor units, inclusive", Schema: &Schema{Type: "integer", Minimum: floatPtr(0)}},
+			{Name: "maxPrice", In: "query", Description: "Highest price in minor units, inclusive; 0 leaves it unbounded", Schema: &Schema{Type: "integer", Minimum: floatPtr(0)}},
+			{Name: "currency", In: "query", Description: "Only count the products priced in this currency", Schema: &Schema{Type: "string", Pattern: domain.CurrencyPattern}},
+			{Name: "inStock", In: "query", Description: "Only keep the products with (true) or without (false) stock", Schema: &Schema{Type: "boolean"}},
+			{Name: "priceBuckets", In: "query", Description: "Increasing price bucket bounds in minor units, e.g. 1000,5000; the configured bounds are used when omitted", Schema: &Schema{Type: "string", Pattern: `^[0-9]+(,[0-9]+)*$`}},
+		},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Facet counts", ref("FacetsResponse")),
+			"400": errorResponse("Invalid request"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/products", &Operation{
+		OperationID: "createProduct",
+		Summary:     "Create a new product",
+		Tags:        []string{"products"},
+		RequestBody: jsonBody(ref("CreateProductRequest")),
+		Responses: map[string]*Response{
+			"201": jsonResponse("Created product", ref("ProductResponse")),
+			"400": errorResponse("Invalid request"),
+			"409": errorResponse("Product already exists"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/products/{id}", &Operation{
+		OperationID: "getProductByID",
+		Summary:     "Get a published product inside its availability window by ID; other products are not found",
+		Tags:        []string{"products"},
+		Parameters:  append([]*Parameter{productID, acceptLanguage()}, conditionalParams()...),
+		Responses: map[string]*Response{
+			"200": localized(cached(jsonResponse("Product", ref("ProductResponse")))),
+			"304": notModified(),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/products/{id}", &Operation{
+		OperationID: "updateProduct",
+		Summary:     "Update an existing product",
+		Tags:        []string{"products"},
+		Parameters:  []*Parameter{productID},
+		RequestBody: jsonBody(ref("UpdateProductRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Updated product", ref("ProductResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPatch, "/api/products/{id}", &Operation{
+		OperationID: "patchProduct",
+		Summary:     "Update some fields of an existing product",
+		Tags:        []string{"products"},
+		Parameters:  []*Parameter{productID},
+		RequestBody: jsonBody(ref("PatchProductRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Updated product", ref("ProductResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/products/{id}", &Operation{
+		OperationID: "deleteProduct",
+		Summary:     "Delete a product",
+		Tags:        []string{"products"},
+		Parameters:  []*Parameter{productID},
+		Responses: map[string]*Response{
+			"204": {Description: "Product deleted"},
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	categoryFilter := &Parameter{Name: "category", In: "query", Description: "Only stream products in this category; may be repeated", Schema: &Schema{Type: "string", MinLength: intPtr(1)}}
+	lastEventID := &Parameter{Name: "Last-Event-ID", In: "header", Description: "ID of the last event received; buffered events after it are replayed", Schema: &Schema{Type: "string"}}
+	eventStream := &Response{
+		Description: "Server-Sent Events named after the product event type, with a JSON ProductEvent as data",
+		Content:     map[string]*MediaType{"text/event-stream": {Schema: ref("ProductEvent")}},
+	}
+	doc.Add(http.MethodGet, "/api/products/stream", &Operation{
+		OperationID: "streamProducts",
+		Summary:     "Stream product changes as Server-Sent Events",
+		Tags:        []string{"products"},
+		Parameters:  []*Parameter{categoryFilter, lastEventID},
+		Responses: map[string]*Response{
+			"200": eventStream,
+			"400": errorResponse("Invalid request"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/products/{id}/stream", &Operation{
+		OperationID: "streamProduct",
+		Summary:     "Stream the changes of one product as Server-Sent Events",
+		Tags:        []string{"products"},
+		Parameters:  []*Parameter{productID, lastEventID},
+		Responses: map[string]*Response{
+			"200": eventStream,
+			"404": errorResponse("Product not found"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/products/{id}/categories", &Operation{
+		OperationID: "addCategoryToProduct",
+		Summary:     "Add a category to a product",
+		Tags:        []string{"categories"},
+		Parameters:  []*Parameter{productID},
+		RequestBody: jsonBody(ref("AddCategoryToProductRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Updated product", ref("ProductResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/products/{id}/categories/{cid}", &Operation{
+		OperationID: "removeCategoryFromProduct",
+		Summary:     "Remove a category from a product",
+		Tags:        []string{"categories"},
+		Parameters:  []*Parameter{productID, pathParam("cid", "Category ID")},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Updated product", ref("ProductResponse")),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/categories/{id}/attribute-schema", &Operation{
+		OperationID: "getAttributeSchema",
+		Summary:     "Get the attributes defined for the products of a category",
+		Tags:        []string{"categories"},
+		Parameters:  []*Parameter{pathParam("id", "Category ID")},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Attribute schema", ref("AttributeSchemaResponse")),
+			"404": errorResponse("Attribute schema not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/categories/{id}/attribute-schema", &Operation{
+		OperationID: "setAttributeSchema",
+		Summary:     "Create or replace the attributes defined for the products of a category",
+		Description: "The attributes already set on products are checked against the new schema the next time they are set.",
+		Tags:        []string{"categories"},
+		Parameters:  []*Parameter{pathParam("id", "Category ID")},
+		RequestBody: jsonBody(ref("AttributeSchemaRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Attribute schema", ref("AttributeSchemaResponse")),
+			"400": errorResponse("Invalid request"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/categories/{id}/attribute-schema", &Operation{
+		OperationID: "deleteAttributeSchema",
+		Summary:     "Remove the attribute schema of a category",
+		Tags:        []string{"categories"},
+		Parameters:  []*Parameter{pathParam("id", "Category ID")},
+		Responses: map[string]*Response{
+			"204": {Description: "Attribute schema removed"},
+			"404": errorResponse("Attribute schema not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/products/{id}/attributes", &Operation{
+		OperationID: "setProductAttributes",
+		Summary:     "Replace the attributes of a product",
+		Description: "Every attribute must be defined by the schema of one of the product's categories, " +
+			"and every required attribute of those schemas must be set.",
+		Tags:        []string{"products"},
+		Parameters:  []*Parameter{productID},
+		RequestBody: jsonBody(ref("ProductAttributesRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Updated product", ref("ProductResponse")),
+			"400": errorResponse("Invalid or undefined attributes"),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	imageID := pathParam("imageId", "Image ID")
+	doc.Add(http.MethodPost, "/api/products/{id}/images", &Operation{
+		OperationID: "uploadProductImage",
+		Summary:     "Add an image to a product",
+		Description: "The image is sent in the multipart field image. Its type is sniffed from the content: PNG, JPEG and GIF images are accepted. " +
+			"A thumbnail is generated, and the first image of a product is its primary image.",
+		Tags:       []string{"products"},
+		Parameters: []*Parameter{productID},
+		RequestBody: &RequestBody{Required: true, Content: map[string]*MediaType{"multipart/form-data": {Schema: &Schema{
+			Type:       "object",
+			Properties: map[string]*Schema{"image": {Type: "string", Format: "binary"}},
+			Required:   []string{"image"},
+		}}}},
+		Responses: map[string]*Response{
+			"201": {
+				Description: "Uploaded image",
+				Headers:     map[string]*Header{"Location": {Description: "URL of the image file", Schema: &Schema{Type: "string"}}},
+				Content:     map[string]*MediaType{"application/json": {Schema: ref("ImageResponse")}},
+			},
+			"400": errorResponse("Invalid image or too many images"),
+			"404": errorResponse("Product not found"),
+			"413": errorResponse("Image too large"),
+			"415": errorResponse("Not a PNG, JPEG or GIF image"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/products/{id}/images", &Operation{
+		OperationID: "reorderProductImages",
+		Summary:     "Reorder the images of a product; the first one becomes the primary image",
+		Tags:        []string{"products"},
+		Parameters:  []*Parameter{productID},
+		RequestBody: jsonBody(ref("ReorderImagesRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Updated product", ref("ProductResponse")),
+			"400": errorResponse("The order does not list every image exactly once"),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/products/{id}/images/{imageId}", &Operation{
+		OperationID: "removeProductImage",
+		Summary:     "Remove an image of a product and delete its files",
+		Tags:        []string{"products"},
+		Parameters:  []*Parameter{productID, imageID},
+		Responses: map[string]*Response{
+			"204": {Description: "Image removed"},
+			"404": errorResponse("Product or image not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	for _, thumbnail := range []bool{false, true} {
+		path, operationID, summary := "/api/products/{id}/images/{imageId}", "getProductImage", "Get an image file"
+		if thumbnail {
+			path, operationID, summary = path+"/thumbnail", "getProductImageThumbnail", "Get the thumbnail of an image"
+		}
+		file := cached(&Response{Description: "Image file", Content: map[string]*MediaType{}})
+		for _, contentType := range []string{"image/png", "image/jpeg", "image/gif"} {
+			file.Content[contentType] = &MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+		}
+		doc.Add(http.MethodGet, path, &Operation{
+			OperationID: operationID,
+			Summary:     summary + " of a published product inside its availability window",
+			Tags:        []string{"products"},
+			Parameters:  append([]*Parameter{productID, imageID}, conditionalParams()...),
+			Responses: map[string]*Response{
+				"200": file,
+				"304": notModified(),
+				"404": errorResponse("Product or image not found"),
+				"500": errorResponse("Internal error"),
+			},
+		})
+	}
+	doc.Add(http.MethodGet, "/api/products/{id}/translations", &Operation{
+		OperationID: "getProductTranslations",
+		Summary:     "Get the names and descriptions of a product in the locales it is translated to, whatever its status",
+		Tags:        []string{"products"},
+		Parameters:  []*Parameter{productID},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Product translations", ref("ProductTranslationsResponse")),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/products/{id}/translations/{locale}", &Operation{
+		OperationID: "setProductTranslation",
+		Summary:     "Add or replace the name and description of a product in a locale",
+		Description: "The translated texts follow the length rules of the default ones, counted in characters.",
+		Tags:        []string{"products"},
+		Parameters:  []*Parameter{productID, locale},
+		RequestBody: jsonBody(ref("ProductTranslationRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Product translations", ref("ProductTranslationsResponse")),
+			"400": errorResponse("Invalid texts, or default or unsupported locale"),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/products/{id}/translations/{locale}", &Operation{
+		OperationID: "removeProductTranslation",
+		Summary:     "Remove the translation of a product in a locale",
+		Tags:        []string{"products"},
+		Parameters:  []*Parameter{productID, locale},
+		Responses: map[string]*Response{
+			"204": {Description: "Translation removed"},
+			"400": errorResponse("Invalid locale"),
+			"404": errorResponse("Product or translation not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/categories/{id}/translations", &Operation{
+		OperationID: "getCategoryTranslations",
+		Summary:     "Get the names of a category in the locales it is translated to",
+		Tags:        []string{"categories"},
+		Parameters:  []*Parameter{pathParam("id", "Category ID")},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Category translations", ref("CategoryTranslationsResponse")),
+			"400": errorResponse("Invalid request"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/categories/{id}/translations/{locale}", &Operation{
+		OperationID: "setCategoryTranslation",
+		Summary:     "Add or replace the name of a category in a locale",
+		Description: "A category can be translated before any product is added to it.",
+		Tags:        []string{"categories"},
+		Parameters:  []*Parameter{pathParam("id", "Category ID"), locale},
+		RequestBody: jsonBody(ref("CategoryTranslationRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Category translations", ref("CategoryTranslationsResponse")),
+			"400": errorResponse("Invalid name, or default or unsupported locale"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/categories/{id}/translations/{locale}", &Operation{
+		OperationID: "removeCategoryTranslation",
+		Summary:     "Remove the name of a category in a locale",
+		Tags:        []string{"categories"},
+		Parameters:  []*Parameter{pathParam("id", "Category ID"), locale},
+		Responses: map[string]*Response{
+			"204": {Description: "Translation removed"},
+			"400": errorResponse("Invalid locale"),
+			"404": errorResponse("Translation not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/categories/{id}/products", &Operation{
+		OperationID: "getProductsByCategory",
+		Summary:     "Get the published products of a category inside their availability window",
+		Tags:        []string{"categories"},
+		Parameters:  append([]*Parameter{pathParam("id", "Category ID"), acceptLanguage()}, conditionalParams()...),
+		Responses: map[string]*Response{
+			"200": localized(cached(jsonResponse("Products ordered by ID", arrayOf(ref("ProductResponse"))))),
+			"304": notModified(),
+			"400": errorResponse("Invalid request"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+
+	warehouseID := pathParam("id", "Warehouse ID")
+	doc.Add(http.MethodGet, "/api/warehouses", &Operation{
+		OperationID: "listWarehouses",
+		Summary:     "List warehouses ordered by ID",
+		Tags:        []string{"inventory"},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Warehouses", arrayOf(ref("WarehouseResponse"))),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/warehouses", &Operation{
+		OperationID: "createWarehouse",
+		Summary:     "Create a warehouse",
+		Tags:        []string{"inventory"},
+		RequestBody: jsonBody(ref("CreateWarehouseRequest")),
+		Responses: map[string]*Response{
+			"201": jsonResponse("Created warehouse", ref("WarehouseResponse")),
+			"400": errorResponse("Invalid request"),
+			"409": errorResponse("Warehouse already exists"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/warehouses/{id}", &Operation{
+		OperationID: "getWarehouse",
+		Summary:     "Get a warehouse",
+		Tags:        []string{"inventory"},
+		Parameters:  []*Parameter{warehouseID},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Warehouse", ref("WarehouseResponse")),
+			"404": errorResponse("Warehouse not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/warehouses/{id}", &Operation{
+		OperationID: "updateWarehouse",
+		Summary:     "Update the name and location of a warehouse",
+		Tags:        []string{"inventory"},
+		Parameters:  []*Parameter{warehouseID},
+		RequestBody: jsonBody(ref("UpdateWarehouseRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Updated warehouse", ref("WarehouseResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Warehouse not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/warehouses/{id}", &Operation{
+		OperationID: "deleteWarehouse",
+		Summary:     "Delete a warehouse that holds no stock; the default warehouse cannot be deleted",
+		Tags:        []string{"inventory"},
+		Parameters:  []*Parameter{warehouseID},
+		Responses: map[string]*Response{
+			"204": {Description: "Warehouse deleted"},
+			"404": errorResponse("Warehouse not found"),
+			"409": errorResponse("Warehouse is in use"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/products/{id}/stock", &Operation{
+		OperationID: "getProductInventory",
+		Summary:     "Get the stock of a product per warehouse",
+		Tags:        []string{"inventory"},
+		Parameters:  []*Parameter{productID},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Stock per warehouse", ref("InventoryResponse")),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/products/{id}/stock/{warehouseId}", &Operation{
+		OperationID: "setProductStockLevel",
+		Summary:     "Set the stock of a product in a warehouse",
+		Tags:        []string{"inventory"},
+		Parameters:  []*Parameter{productID, pathParam("warehouseId", "Warehouse ID")},
+		RequestBody: jsonBody(ref("SetStockLevelRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Stock per warehouse", ref("InventoryResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product or warehouse not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/products/{id}/stock/transfers", &Operation{
+		OperationID: "transferProductStock",
+		Summary:     "Move stock of a product between two warehouses; either the whole quantity is moved or nothing is",
+		Tags:        []string{"inventory"},
+		Parameters:  []*Parameter{productID},
+		RequestBody: jsonBody(ref("TransferStockRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Stock per warehouse", ref("InventoryResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product or warehouse not found"),
+			"409": errorResponse("Insufficient stock in the source warehouse"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/products/{id}/stock/allocations", &Operation{
+		OperationID: "allocateProductStock",
+		Summary:     "Decrease the stock of a product, drawing from the warehouses in the order of an allocation strategy",
+		Tags:        []string{"inventory"},
+		Parameters:  []*Parameter{productID},
+		RequestBody: jsonBody(ref("AllocateStockRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Quantity taken from each warehouse and the remaining stock", ref("AllocationResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product not found"),
+			"409": errorResponse("Insufficient stock"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/products/{id}/stock-movements", &Operation{
+		OperationID: "listProductStockMovements",
+		Summary:     "Get the stock ledger of a product, oldest first",
+		Tags:        []string{"inventory"},
+		Parameters: []*Parameter{
+			productID,
+			{Name: "type", In: "query", Description: "Only return movements of this type", Schema: &Schema{Type: "string", Enum: movementTypes(true)}},
+			{Name: "warehouse", In: "query", Description: "Only return movements of this warehouse", Schema: &Schema{Type: "string"}},
+		},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Stock movements", arrayOf(ref("StockMovementResponse"))),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/products/{id}/stock-movements", &Operation{
+		OperationID: "recordProductStockMovement",
+		Summary:     "Change the stock of a product in a warehouse through a movement recorded in the ledger",
+		Tags:        []string{"inventory"},
+		Parameters:  []*Parameter{productID},
+		RequestBody: jsonBody(ref("RecordStockMovementRequest")),
+		Responses: map[string]*Response{
+			"201": jsonResponse("Stock per warehouse", ref("InventoryResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product or warehouse not found"),
+			"409": errorResponse("Insufficient stock"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/inventory/reconciliation", &Operation{
+		OperationID: "reconcileStock",
+		Summary:     "Compare the stock of every product with its ledger",
+		Tags:        []string{"inventory"},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Warehouses whose stock drifted from the ledger", ref("ReconciliationResponse")),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/products/{id}/reorder-policy", &Operation{
+		OperationID: "getReorderPolicy",
+		Summary:     "Get the reorder threshold and target of a product",
+		Tags:        []string{"inventory"},
+		Parameters:  []*Parameter{productID},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Reorder policy", ref("ReorderPolicyResponse")),
+			"404": errorResponse("Reorder policy not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/products/{id}/reorder-policy", &Operation{
+		OperationID: "setReorderPolicy",
+		Summary:     "Set the reorder threshold and target of a product",
+		Tags:        []string{"inventory"},
+		Parameters:  []*Parameter{productID},
+		RequestBody: jsonBody(ref("ReorderPolicyRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Reorder policy", ref("ReorderPolicyResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/products/{id}/reorder-policy", &Operation{
+		OperationID: "deleteReorderPolicy",
+		Summary:     "Remove the reorder policy of a product",
+		Tags:        []string{"inventory"},
+		Parameters:  []*Parameter{productID},
+		Responses: map[string]*Response{
+			"204": {Description: "Reorder policy removed"},
+			"404": errorResponse("Reorder policy not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/inventory/reorder-suggestions", &Operation{
+		OperationID: "getReorderSuggestions",
+		Summary:     "List the products at or below their reorder threshold with the quantity to order, out-of-stock products first",
+		Tags:        []string{"inventory"},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Reorder suggestions", arrayOf(ref("ReorderSuggestionResponse"))),
+			"500": errorResponse("Internal error"),
+		},
+	})
+
+	adminPage := jsonResponse("Products in every status ordered by ID", arrayOf(ref("ProductResponse")))
+	adminPage.Headers = map[string]*Header{
+		"X-Total-Count": {Description: "Number of products", Schema: &Schema{Type: "integer"}},
+		"X-Next-Cursor": {Description: "Cursor of the next page, absent on the last page", Schema: &Schema{Type: "string"}},
+		"Link":          {Description: "RFC 8288 link to the next page", Schema: &Schema{Type: "string"}},
+	}
+	doc.Add(http.MethodGet, "/api/admin/products", &Operation{
+		OperationID: "adminListProducts",
+		Summary:     "List the products in every status, optionally one page at a time",
+		Tags:        []string{"admin"},
+		Parameters: []*Parameter{
+			{Name: "status", In: "query", Description: "Only return products in this status", Schema: &Schema{Type: "string", Enum: productStatuses()}},
+			{Name: "limit", In: "query", Description: "Page size; every product is returned when omitted", Schema: &Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(usecase.MaxListProductsLimit)}},
+			{Name: "cursor", In: "query", Description: "Cursor returned by the previous page", Schema: &Schema{Type: "string"}},
+		},
+		Responses: map[string]*Response{
+			"200": adminPage,
+			"400": errorResponse("Invalid request"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/admin/products/{id}", &Operation{
+		OperationID: "adminGetProduct",
+		Summary:     "Get a product whatever its status and availability window",
+		Tags:        []string{"admin"},
+		Parameters:  []*Parameter{productID},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Product", ref("ProductResponse")),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/admin/products/{id}/status", &Operation{
+		OperationID: "changeProductStatus",
+		Summary:     "Publish, unpublish or archive a product",
+		Tags:        []string{"admin"},
+		Parameters:  []*Parameter{productID},
+		RequestBody: jsonBody(ref("ChangeStatusRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Updated product", ref("ProductResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product not found"),
+			"409": errorResponse("Transition not allowed, or the product is missing data required to publish it"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/admin/products/{id}/availability", &Operation{
+		OperationID: "setProductAvailability",
+		Summary:     "Schedule when a product is visible to customers once published",
+		Tags:        []string{"admin"},
+		Parameters:  []*Parameter{productID},
+		RequestBody: jsonBody(ref("AvailabilityRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Updated product", ref("ProductResponse")),
+			"400": errorResponse("Invalid request, e.g. availableUntil not after availableFrom"),
+			"404": errorResponse("Product not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+
+	webhookID := pathParam("id", "Webhook subscription ID")
+	doc.Add(http.MethodGet, "/api/webhooks", &Operation{
+		OperationID: "listWebhooks",
+		Summary:     "List webhook subscriptions",
+		Tags:        []string{"webhooks"},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Subscriptions", arrayOf(ref("WebhookSubscriptionResponse"))),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/webhooks", &Operation{
+		OperationID: "createWebhook",
+		Summary:     "Subscribe a URL to product events; the secret is only returned here",
+		Tags:        []string{"webhooks"},
+		RequestBody: jsonBody(ref("CreateWebhookRequest")),
+		Responses: map[string]*Response{
+			"201": jsonResponse("Created subscription", ref("WebhookSubscriptionResponse")),
+			"400": errorResponse("Invalid request"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/webhooks/{id}", &Operation{
+		OperationID: "getWebhook",
+		Summary:     "Get a webhook subscription",
+		Tags:        []string{"webhooks"},
+		Parameters:  []*Parameter{webhookID},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Subscription", ref("WebhookSubscriptionResponse")),
+			"404": errorResponse("Subscription not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/webhooks/{id}", &Operation{
+		OperationID: "updateWebhook",
+		Summary:     "Update a webhook subscription",
+		Tags:        []string{"webhooks"},
+		Parameters:  []*Parameter{webhookID},
+		RequestBody: jsonBody(ref("UpdateWebhookRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Updated subscription", ref("WebhookSubscriptionResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Subscription not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/webhooks/{id}", &Operation{
+		OperationID: "deleteWebhook",
+		Summary:     "Delete a webhook subscription",
+		Tags:        []string{"webhooks"},
+		Parameters:  []*Parameter{webhookID},
+		Responses: map[string]*Response{
+			"204": {Description: "Subscription deleted"},
+			"404": errorResponse("Subscription not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/webhooks/{id}/deliveries", &Operation{
+		OperationID: "listWebhookDeliveries",
+		Summary:     "Get the delivery log of a webhook subscription, newest first",
+		Tags:        []string{"webhooks"},
+		Parameters: []*Parameter{
+			webhookID,
+			{Name: "status", In: "query", Description: "Only return deliveries in this status; dead_lettered lists the dead-letter queue", Schema: &Schema{Type: "string", Enum: []string{"pending", "succeeded", "dead_lettered"}}},
+		},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Deliveries", arrayOf(ref("WebhookDeliveryResponse"))),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Subscription not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+
+	orderID := pathParam("id", "Order ID")
+	doc.Add(http.MethodGet, "/api/orders", &Operation{
+		OperationID: "listOrders",
+		Summary:     "List orders, newest first",
+		Tags:        []string{"orders"},
+		Parameters: []*Parameter{
+			{Name: "status", In: "query", Description: "Only return orders in this status", Schema: &Schema{Type: "string", Enum: orderStatuses()}},
+		},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Orders", arrayOf(ref("OrderResponse"))),
+			"400": errorResponse("Invalid request"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/orders", &Operation{
+		OperationID: "placeOrder",
+		Summary:     "Place an order at the current prices; the stock of every line is taken, or none is",
+		Tags:        []string{"orders"},
+		RequestBody: jsonBody(ref("PlaceOrderRequest")),
+		Responses: map[string]*Response{
+			"201": jsonResponse("Placed order", ref("OrderResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product not found"),
+			"409": errorResponse("Insufficient stock, or a product is not published or outside its availability window"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/orders/{id}", &Operation{
+		OperationID: "getOrder",
+		Summary:     "Get an order",
+		Tags:        []string{"orders"},
+		Parameters:  []*Parameter{orderID},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Order", ref("OrderResponse")),
+			"404": errorResponse("Order not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/orders/{id}/cancel", &Operation{
+		OperationID: "cancelOrder",
+		Summary:     "Cancel a placed order and return its stock",
+		Tags:        []string{"orders"},
+		Parameters:  []*Parameter{orderID},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Cancelled order", ref("OrderResponse")),
+			"404": errorResponse("Order not found"),
+			"409": errorResponse("Order already fulfilled or cancelled"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/orders/{id}/fulfil", &Operation{
+		OperationID: "fulfilOrder",
+		Summary:     "Mark a placed order as fulfilled",
+		Tags:        []string{"orders"},
+		Parameters:  []*Parameter{orderID},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Fulfilled order", ref("OrderResponse")),
+			"404": errorResponse("Order not found"),
+			"409": errorResponse("Order already fulfilled or cancelled"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+
+	session := &Parameter{Name: "X-Session-ID", In: "header", Description: "Session owning the cart of an anonymous caller; the cart of an authenticated caller belongs to the user", Schema: &Schema{Type: "string", MaxLength: intPtr(cart.MaxOwnerLength)}}
+	cartProductID := pathParam("productId", "Product ID")
+	doc.Add(http.MethodGet, "/api/cart", &Operation{
+		OperationID: "getCart",
+		Summary:     "Get the cart of the caller, with every line checked against the current products",
+		Tags:        []string{"cart"},
+		Parameters:  []*Parameter{session},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Cart; empty when the caller has none or it expired", ref("CartResponse")),
+			"400": errorResponse("No session or user"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/cart", &Operation{
+		OperationID: "clearCart",
+		Summary:     "Empty the cart of the caller",
+		Tags:        []string{"cart"},
+		Parameters:  []*Parameter{session},
+		Responses: map[string]*Response{
+			"204": {Description: "Cart emptied"},
+			"400": errorResponse("No session or user"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/cart/items", &Operation{
+		OperationID: "addCartItem",
+		Summary:     "Add a quantity of a product to the cart at its current price",
+		Tags:        []string{"cart"},
+		Parameters:  []*Parameter{session},
+		RequestBody: jsonBody(ref("AddCartItemRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Cart", ref("CartResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product not found"),
+			"409": errorResponse("Product not published or outside its availability window, insufficient stock, or another currency than the cart"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/cart/items/{productId}", &Operation{
+		OperationID: "updateCartItem",
+		Summary:     "Replace the quantity of a product in the cart, accepting its current price",
+		Tags:        []string{"cart"},
+		Parameters:  []*Parameter{session, cartProductID},
+		RequestBody: jsonBody(ref("UpdateCartItemRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Cart", ref("CartResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product not found or not in the cart"),
+			"409": errorResponse("Product not published or outside its availability window, insufficient stock, or another currency than the cart"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/cart/items/{productId}", &Operation{
+		OperationID: "removeCartItem",
+		Summary:     "Remove a product from the cart",
+		Tags:        []string{"cart"},
+		Parameters:  []*Parameter{session, cartProductID},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Cart", ref("CartResponse")),
+			"400": errorResponse("Invalid request"),
+			"404": errorResponse("Product not in the cart"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/cart/reservations", &Operation{
+		OperationID: "reserveCart",
+		Summary:     "Convert the cart into a reservation of the stock of its lines, then empty it",
+		Tags:        []string{"cart"},
+		Parameters:  []*Parameter{session},
+		Responses: map[string]*Response{
+			"201": jsonResponse("Reservation", ref("ReservationResponse")),
+			"400": errorResponse("Empty cart, or no session or user"),
+			"409": errorResponse("Cart with issues, or insufficient stock"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	reservationID := pathParam("id", "Reservation ID")
+	doc.Add(http.MethodGet, "/api/reservations/{id}", &Operation{
+		OperationID: "getReservation",
+		Summary:     "Get a stock reservation",
+		Tags:        []string{"cart"},
+		Parameters:  []*Parameter{reservationID},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Reservation", ref("ReservationResponse")),
+			"404": errorResponse("Reservation not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/reservations/{id}", &Operation{
+		OperationID: "releaseReservation",
+		Summary:     "Release a stock reservation, returning its stock",
+		Tags:        []string{"cart"},
+		Parameters:  []*Parameter{reservationID},
+		Responses: map[string]*Response{
+			"204": {Description: "Reservation released"},
+			"404": errorResponse("Reservation not found"),
+			"500": errorResponse("Internal error"),
+		},
+	})
+
+	doc.Add(http.MethodGet, "/graphql", &Operation{
+		OperationID: "graphqlQuery",
+		Summary:     "Execute a GraphQL query passed as query parameters",
+		Tags:        []string{"graphql"},
+		Parameters: []*Parameter{
+			{Name: "query", In: "query", Required: true, Schema: &Schema{Type: "string"}},
+			{Name: "operationName", In: "query", Schema: &Schema{Type: "string"}},
+			{Name: "variables", In: "query", Description: "JSON encoded variables", Schema: &Schema{Type: "string"}},
+		},
+		Responses: map[string]*Response{
+			"200": jsonResponse("GraphQL result", ref("GraphQLResponse")),
+			"400": jsonResponse("Invalid request", ref("GraphQLResponse")),
+		},
+	})
+	doc.Add(http.MethodPost, "/graphql", &Operation{
+		OperationID: "graphqlExecute",
+		Summary:     "Execute a GraphQL query or mutation",
+		Tags:        []string{"graphql"},
+		RequestBody: jsonBody(ref("GraphQLRequest")),
+		Responses: map[string]*Response{
+			"200": jsonResponse("GraphQL result", ref("GraphQLResponse")),
+			"400": jsonResponse("Invalid request", ref("GraphQLResponse")),
+		},
+	})
+
+	doc.Add(http.MethodGet, "/openapi.json", &Operation{
+		OperationID: "getOpenAPI",
+		Summary:     "Get this OpenAPI document",
+		Tags:        []string{"meta"},
+		Responses: map[string]*Response{
+			"200": jsonResponse("OpenAPI document", &Schema{Type: "object"}),
+		},
+	})
+	doc.Add(http.MethodGet, "/metrics", &Operation{
+		OperationID: "getMetrics",
+		Summary:     "Prometheus metrics",
+		Tags:        []string{"meta"},
+		Responses: map[string]*Response{
+			"200": {Description: "Metrics in the Prometheus exposition format", Content: map[string]*MediaType{"text/plain": {Schema: &Schema{Type: "string"}}}},
+		},
+	})
+	doc.Add(http.MethodGet, "/healthz", &Operation{
+		OperationID: "getHealth",
+		Summary:     "Liveness: the process is up",
+		Tags:        []string{"meta"},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Alive", ref("HealthResponse")),
+		},
+	})
+	doc.Add(http.MethodGet, "/readyz", &Operation{
+		OperationID: "getReadiness",
+		Summary:     "Readiness: the dependencies, such as the repository, are reachable",
+		Tags:        []string{"meta"},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Ready", ref("HealthResponse")),
+			"503": jsonResponse("A check failed", ref("HealthResponse")),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/hello", &Operation{
+		OperationID: "hello",
+		Summary:     "Hello world",
+		Tags:        []string{"meta"},
+		Responses: map[string]*Response{
+			"200": {Description: "Greeting", Content: map[string]*MediaType{"text/plain": {Schema: &Schema{Type: "string"}}}},
+		},
+	})
+
+	// Every operation but the meta ones is rate limited per client
+	for _, item := range doc.Paths {
+		for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodPatch} {
+			if op := item.Operation(method); op != nil && !slices.Contains(op.Tags, "meta") {
+				op.Responses["429"] = rateLimited()
+			}
+		}
+	}
+
+	return doc
+}
+
+// schemas returns the reusable component schemas
+func schemas() map[string]*Schema {
+	productName := &Schema{Type: "string", MinLength: intPtr(1), MaxLength: intPtr(domain.MaxProductNameLength)}
+	attributeKey := &Schema{Type: "string", Pattern: domain.AttributeKeyPattern}
+	attributeValues := &Schema{Type: "object", Description: "Attribute values by key; each value is a string, a number or a boolean"}
+	productDescription := &Schema{Type: "string", MaxLength: intPtr(domain.MaxProductDescriptionLength)}
+	price := &Schema{Type: "integer", Minimum: floatPtr(1), Description: "Amount in the smallest unit of the currency"}
+	currency := &Schema{Type: "string", Pattern: domain.CurrencyPattern, Description: "ISO 4217 currency code"}
+	stock := &Schema{Type: "integer", Minimum: floatPtr(0)}
+
+	warehouseName := &Schema{Type: "string", MinLength: intPtr(1), MaxLength: intPtr(domain.MaxWarehouseNameLength)}
+	latitude := &Schema{Type: "number", Minimum: floatPtr(-90), Maximum: floatPtr(90)}
+	longitude := &Schema{Type: "number", Minimum: floatPtr(-180), Maximum: floatPtr(180)}
+
+	eventNames := []string{"*"}
+	for _, et := range domain.EventTypes {
+		eventNames = append(eventNames, et.String())
+	}
+	webhookURL := &Schema{Type: "string", Format: "uri", MinLength: intPtr(1)}
+	webhookEvents := arrayOf(&Schema{Type: "string", Enum: eventNames, Description: "Product event type; * subscribes to every type"})
+
+	return map[string]*Schema{
+		"CategoryResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":   {Type: "string"},
+				"name": {Type: "string"},
+			},
+			Required: []string{"id", "name"},
+		},
+		"ProductResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":             {Type: "string"},
+				"name":           {Type: "string"},
+				"description":    {Type: "string"},
+				"price":          {Type: "integer"},
+				"currency":       {Type: "string"},
+				"status":         {Type: "string", Enum: productStatuses()},
+				"availableFrom":  {Type: "string", Format: "date-time", Description: "Start of the availability window, inclusive; omitted when open"},
+				"availableUntil": {Type: "string", Format: "date-time", Description: "End of the availability window, exclusive; omitted when open"},
+				"attributes":     {Type: "object", Description: "Attribute values by key; omitted when none is set"},
+				"images":         {Type: "array", Items: ref("ImageResponse"), Description: "Images in display order, the primary one first; omitted when none is set"},
+				"stock":          {Type: "integer"},
+				"categories":     arrayOf(ref("CategoryResponse")),
+			},
+			Required: []string{"id", "name", "description", "price", "currency", "status", "stock", "categories"},
+		},
+		"ImageResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":           {Type: "string"},
+				"url":          {Type: "string", Description: "URL of the image file"},
+				"thumbnailUrl": {Type: "string", Description: "URL of the thumbnail file"},
+				"contentType":  {Type: "string", Enum: []string{"image/png", "image/jpeg", "image/gif"}},
+				"size":         {Type: "integer", Description: "Size of the image file in bytes"},
+				"width":        {Type: "integer"},
+				"height":       {Type: "integer"},
+				"primary":      {Type: "boolean", Description: "Whether this is the first image of the product"},
+			},
+			Required: []string{"id", "url", "thumbnailUrl", "contentType", "size", "width", "height", "primary"},
+		},
+		"ReorderImagesRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"imageIds": {Type: "array", Items: &Schema{Type: "string"}, Description: "Every image of the product in the new order"},
+			},
+			Required: []string{"imageIds"},
+		},
+		"ProductTranslationRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"name":        productName,
+				"description": {Type: "string", MaxLength: intPtr(domain.MaxProductDescriptionLength), Description: "Omitted when the default description is shown in the locale"},
+			},
+			Required: []string{"name"},
+		},
+		"ProductTranslationsResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"productId":     {Type: "string"},
+				"defaultLocale": {Type: "string", Description: "Locale of the product's own name and description"},
+				"translations":  {Type: "object", Description: "ProductTranslationRequest objects by locale"},
+			},
+			Required: []string{"productId", "defaultLocale", "translations"},
+		},
+		"CategoryTranslationRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"name": {Type: "string", MinLength: intPtr(1), MaxLength: intPtr(domain.MaxCategoryNameLength)},
+			},
+			Required: []string{"name"},
+		},
+		"CategoryTranslationsResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"categoryId":    {Type: "string"},
+				"defaultLocale": {Type: "string", Description: "Locale of the category's own name"},
+				"names":         {Type: "object", Description: "Category names by locale"},
+			},
+			Required: []string{"categoryId", "defaultLocale", "names"},
+		},
+		"AttributeDefinition": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"key":      attributeKey,
+				"type":     {Type: "string", Enum: attributeTypes()},
+				"required": {Type: "boolean", Description: "Whether every product of the category must set the attribute"},
+				"values":   {Type: "array", Items: &Schema{Type: "string", MinLength: intPtr(1), MaxLength: intPtr(domain.MaxAttributeValueLength)}, Description: "Allowed values of an enum attribute; omitted for the other types"},
+			},
+			Required: []string{"key", "type"},
+		},
+		"AttributeSchemaRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"definitions": arrayOf(ref("AttributeDefinition")),
+			},
+			Required: []string{"definitions"},
+		},
+		"AttributeSchemaResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"categoryId":  {Type: "string"},
+				"definitions": arrayOf(ref("AttributeDefinition")),
+			},
+			Required: []string{"categoryId", "definitions"},
+		},
+		"ProductAttributesRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"attributes": attributeValues,
+			},
+			Required: []string{"attributes"},
+		},
+		"FacetsResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"total":      {Type: "integer", Description: "Number of products passing every filter"},
+				"categories": arrayOf(ref("CategoryFacet")),
+				"prices":     arrayOf(ref("PriceFacet")),
+				"stock":      ref("StockFacet"),
+				"attributes": arrayOf(ref("AttributeFacet")),
+			},
+			Required: []string{"total", "categories", "prices", "stock", "attributes"},
+		},
+		"CategoryFacet": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":    {Type: "string"},
+				"name":  {Type: "string"},
+				"count": {Type: "integer"},
+			},
+			Required: []string{"id", "name", "count"},
+		},
+		"PriceFacet": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"from":  {Type: "integer", Description: "Lowest price of the bucket in minor units, inclusive"},
+				"to":    {Type: "integer", Description: "Highest price of the bucket in minor units, exclusive; omitted for the last bucket"},
+				"count": {Type: "integer"},
+			},
+			Required: []string{"from", "count"},
+		},
+		"StockFacet": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"inStock":    {Type: "integer"},
+				"outOfStock": {Type: "integer"},
+			},
+			Required: []string{"inStock", "outOfStock"},
+		},
+		"AttributeFacet": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"key":    attributeKey,
+				"values": arrayOf(ref("AttributeValueFacet")),
+			},
+			Required: []string{"key", "values"},
+		},
+		"AttributeValueFacet": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"value": {Type: "string", Description: "Attribute value as text; numbers without trailing zeros, booleans as true or false"},
+				"count": {Type: "integer"},
+			},
+			Required: []string{"value", "count"},
+		},
+		"AvailabilityRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"availableFrom":  {Type: "string", Format: "date-time", Description: "Start of the window, inclusive; omitted leaves it open"},
+				"availableUntil": {Type: "string", Format: "date-time", Description: "End of the window, exclusive; omitted leaves it open"},
+			},
+		},
+		"ChangeStatusRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"status": {Type: "string", Enum: productStatuses()},
+			},
+			Required: []string{"status"},
+		},
+		"ErrorResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"error": {Type: "string"},
+			},
+			Required: []string{"error"},
+		},
+		"CreateProductRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":          {Type: "string", MinLength: intPtr(1), MaxLength: intPtr(36)},
+				"name":        productName,
+				"description": productDescription,
+				"price":       price,
+				"currency":    currency,
+				"stock":       stock,
+			},
+			Required: []string{"id", "name", "price", "currency"},
+		},
+		"UpdateProductRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"name":        productName,
+				"description": productDescription,
+				"price":       price,
+				"currency":    currency,
+				"stock":       stock,
+			},
+			Required: []string{"name", "price", "currency"},
+		},
+		"PatchProductRequest": {
+			Type:        "object",
+			Description: "Omitted fields keep their current value",
+			Properties: map[string]*Schema{
+				"name":        productName,
+				"description": productDescription,
+				"price":       price,
+				"currency":    currency,
+				"stock":       stock,
+			},
+		},
+		"AddCategoryToProductRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"categoryId":   {Type: "string", MinLength: intPtr(1), MaxLength: intPtr(36)},
+				"categoryName": {Type: "string", MinLength: intPtr(1), MaxLength: intPtr(domain.MaxCategoryNameLength)},
+			},
+			Required: []string{"categoryId", "categoryName"},
+		},
+		"ProductEvent": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"type":           {Type: "string", Enum: eventNames[1:]},
+				"productId":      {Type: "string"},
+				"product":        ref("ProductResponse"),
+				"previousPrice":  {Type: "integer", Description: "Set for product.price_changed"},
+				"previousStock":  {Type: "integer", Description: "Set for product.stock_changed"},
+				"categoryId":     {Type: "string", Description: "Set for product.category_added and product.category_removed"},
+				"previousStatus": {Type: "string", Enum: productStatuses(), Description: "Set for product.status_changed"},
+				"occurredAt":     {Type: "string", Format: "date-time"},
+			},
+			Required: []string{"type", "productId", "occurredAt"},
+		},
+		"WarehouseResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":        {Type: "string"},
+				"name":      {Type: "string"},
+				"latitude":  {Type: "number", Description: "Absent when the location is unknown"},
+				"longitude": {Type: "number", Description: "Absent when the location is unknown"},
+			},
+			Required: []string{"id", "name"},
+		},
+		"CreateWarehouseRequest": {
+			Type:        "object",
+			Description: "latitude and longitude are set together or not at all",
+			Properties: map[string]*Schema{
+				"id":        {Type: "string", MinLength: intPtr(1), MaxLength: intPtr(36)},
+				"name":      warehouseName,
+				"latitude":  latitude,
+				"longitude": longitude,
+			},
+			Required: []string{"id", "name"},
+		},
+		"UpdateWarehouseRequest": {
+			Type:        "object",
+			Description: "latitude and longitude are set together or not at all; an omitted location is cleared",
+			Properties: map[string]*Schema{
+				"name":      warehouseName,
+				"latitude":  latitude,
+				"longitude": longitude,
+			},
+			Required: []string{"name"},
+		},
+		"StockLevelResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"warehouseId": {Type: "string"},
+				"quantity":    {Type: "integer"},
+			},
+			Required: []string{"warehouseId", "quantity"},
+		},
+		"InventoryResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"productId": {Type: "string"},
+				"total":     {Type: "integer", Description: "Stock across every warehouse, as returned in ProductResponse.stock"},
+				"levels":    arrayOf(ref("StockLevelResponse")),
+			},
+			Required: []string{"productId", "total", "levels"},
+		},
+		"AllocationResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"productId":   {Type: "string"},
+				"total":       {Type: "integer"},
+				"levels":      arrayOf(ref("StockLevelResponse")),
+				"allocations": arrayOf(ref("StockLevelResponse")),
+			},
+			Required: []string{"productId", "total", "levels", "allocations"},
+		},
+		"SetStockLevelRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"quantity": stock,
+			},
+			Required: []string{"quantity"},
+		},
+		"TransferStockRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"from":     {Type: "string", MinLength: intPtr(1), Description: "Source warehouse ID"},
+				"to":       {Type: "string", MinLength: intPtr(1), Description: "Destination warehouse ID"},
+				"quantity": {Type: "integer", Minimum: floatPtr(1)},
+			},
+			Required: []string{"from", "to", "quantity"},
+		},
+		"AllocateStockRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"quantity":   {Type: "integer", Minimum: floatPtr(1)},
+				"strategy":   {Type: "string", Enum: usecase.AllocationStrategies, Description: "Defaults to most-stock"},
+				"latitude":   {Type: "number", Minimum: floatPtr(-90), Maximum: floatPtr(90), Description: "Destination, required by the nearest strategy"},
+				"longitude":  {Type: "number", Minimum: floatPtr(-180), Maximum: floatPtr(180), Description: "Destination, required by the nearest strategy"},
+				"warehouses": arrayOf(&Schema{Type: "string", Description: "Warehouses to draw from in order, required by the priority strategy"}),
+			},
+			Required: []string{"quantity"},
+		},
+		"StockMovementResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":          {Type: "string"},
+				"warehouseId": {Type: "string"},
+				"type":        {Type: "string", Enum: movementTypes(true)},
+				"quantity":    {Type: "integer", Description: "Change of stock, negative for decreases"},
+				"balance":     {Type: "integer", Description: "Total stock according to the ledger after the movement"},
+				"reason":      {Type: "string"},
+				"actor":       {Type: "string", Description: "Caller that changed the stock, or system"},
+				"occurredAt":  {Type: "string", Format: "date-time"},
+			},
+			Required: []string{"id", "warehouseId", "type", "quantity", "balance", "actor", "occurredAt"},
+		},
+		"RecordStockMovementRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"type":        {Type: "string", Enum: movementTypes(false), Description: "Transfers are made through /stock/transfers"},
+				"warehouseId": {Type: "string", Description: "Defaults to the default warehouse"},
+				"quantity":    {Type: "integer", Description: "Positive; only adjustments may be negative"},
+				"reason":      {Type: "string", MaxLength: intPtr(domain.MaxMovementReasonLength), Description: "Required for adjustments"},
+			},
+			Required: []string{"type", "quantity"},
+		},
+		"StockDriftResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"productId":   {Type: "string"},
+				"warehouseId": {Type: "string"},
+				"ledger":      {Type: "integer", Description: "Stock according to the ledger"},
+				"stock":       {Type: "integer", Description: "Stock held by the product"},
+				"difference":  {Type: "integer", Description: "stock minus ledger"},
+			},
+			Required: []string{"productId", "warehouseId", "ledger", "stock", "difference"},
+		},
+		"ReconciliationResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"products": {Type: "integer", Description: "Number of products checked"},
+				"drifts":   arrayOf(ref("StockDriftResponse")),
+			},
+			Required: []string{"products", "drifts"},
+		},
+		"ReorderPolicyRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"threshold": {Type: "integer", Minimum: floatPtr(0), Description: "Stock at or below which the product is low"},
+				"target":    {Type: "integer", Minimum: floatPtr(1), Description: "Stock a reorder brings the product back to; greater than the threshold"},
+			},
+			Required: []string{"threshold", "target"},
+		},
+		"ReorderPolicyResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"productId": {Type: "string"},
+				"threshold": {Type: "integer"},
+				"target":    {Type: "integer"},
+			},
+			Required: []string{"productId", "threshold", "target"},
+		},
+		"ReorderSuggestionResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"productId":         {Type: "string"},
+				"productName":       {Type: "string"},
+				"status":            {Type: "string", Enum: []string{domain.StockLow.String(), domain.StockOut.String()}},
+				"stock":             {Type: "integer"},
+				"threshold":         {Type: "integer"},
+				"target":            {Type: "integer"},
+				"suggestedQuantity": {Type: "integer", Description: "target minus stock"},
+			},
+			Required: []string{"productId", "productName", "status", "stock", "threshold", "target", "suggestedQuantity"},
+		},
+		"CreateWebhookRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"url":    webhookURL,
+				"events": webhookEvents,
+				"secret": {Type: "string", MinLength: intPtr(webhook.MinSecretLength), Description: "HMAC-SHA256 signing secret; generated when omitted"},
+			},
+			Required: []string{"url", "events"},
+		},
+		"UpdateWebhookRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"url":    webhookURL,
+				"events": webhookEvents,
+				"active": {Type: "boolean", Description: "Defaults to true"},
+			},
+			Required: []string{"url", "events"},
+		},
+		"WebhookSubscriptionResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":        {Type: "string"},
+				"url":       {Type: "string"},
+				"events":    arrayOf(&Schema{Type: "string"}),
+				"active":    {Type: "boolean"},
+				"secret":    {Type: "string", Description: "Only present in the creation response"},
+				"createdAt": {Type: "string", Format: "date-time"},
+				"updatedAt": {Type: "string", Format: "date-time"},
+			},
+			Required: []string{"id", "url", "events", "active", "createdAt", "updatedAt"},
+		},
+		"WebhookDeliveryResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":             {Type: "string", Description: "Sent in the X-Webhook-Delivery header"},
+				"eventId":        {Type: "string"},
+				"event":          {Type: "string"},
+				"status":         {Type: "string", Enum: []string{"pending", "succeeded", "dead_lettered"}},
+				"attempts":       {Type: "integer"},
+				"lastStatusCode": {Type: "integer"},
+				"lastError":      {Type: "string"},
+				"nextAttemptAt":  {Type: "string", Format: "date-time"},
+				"createdAt":      {Type: "string", Format: "date-time"},
+				"updatedAt":      {Type: "string", Format: "date-time"},
+			},
+			Required: []string{"id", "eventId", "event", "status", "attempts", "createdAt", "updatedAt"},
+		},
+		"PlaceOrderRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"lines": arrayOf(&Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"productId": {Type: "string", MinLength: intPtr(1)},
+						"quantity":  {Type: "integer", Minimum: floatPtr(1)},
+					},
+					Required: []string{"productId", "quantity"},
+				}),
+			},
+			Required: []string{"lines"},
+		},
+		"OrderLineResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"productId":   {Type: "string"},
+				"productName": {Type: "string", Description: "Name of the product when the order was placed"},
+				"quantity":    {Type: "integer"},
+				"unitPrice":   {Type: "integer", Description: "Price of the product when the order was placed, in minor units"},
+				"total":       {Type: "integer"},
+			},
+			Required: []string{"productId", "productName", "quantity", "unitPrice", "total"},
+		},
+		"OrderResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":        {Type: "string"},
+				"status":    {Type: "string", Enum: orderStatuses()},
+				"lines":     arrayOf(ref("OrderLineResponse")),
+				"currency":  {Type: "string"},
+				"total":     {Type: "integer"},
+				"createdAt": {Type: "string", Format: "date-time"},
+				"updatedAt": {Type: "string", Format: "date-time"},
+			},
+			Required: []string{"id", "status", "lines", "currency", "total", "createdAt", "updatedAt"},
+		},
+		"AddCartItemRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"productId": {Type: "string", MinLength: intPtr(1)},
+				"quantity":  {Type: "integer", Minimum: floatPtr(1)},
+			},
+			Required: []string{"productId", "quantity"},
+		},
+		"UpdateCartItemRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"quantity": {Type: "integer", Minimum: floatPtr(1)},
+			},
+			Required: []string{"quantity"},
+		},
+		"CartLineResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"productId":    {Type: "string"},
+				"productName":  {Type: "string", Description: "Absent when the product was deleted"},
+				"quantity":     {Type: "integer"},
+				"addedPrice":   {Type: "integer", Description: "Unit price when the line was last added or updated"},
+				"currentPrice": {Type: "integer", Description: "Unit price now"},
+				"currency":     {Type: "string"},
+				"available":    {Type: "integer", Description: "Stock of the product"},
+				"total":        {Type: "integer", Description: "Line at the current price; 0 when the product was deleted or changed currency"},
+				"issues":       arrayOf(&Schema{Type: "string", Enum: cartIssues()}),
+			},
+			Required: []string{"productId", "quantity", "addedPrice", "currentPrice", "currency", "available", "total", "issues"},
+		},
+		"CartResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":        {Type: "string", Description: "user:<name> or session:<id>"},
+				"lines":     arrayOf(ref("CartLineResponse")),
+				"currency":  {Type: "string", Description: "Absent while the cart is empty"},
+				"total":     {Type: "integer"},
+				"valid":     {Type: "boolean", Description: "False when a line has issues; such a cart cannot be reserved"},
+				"expiresAt": {Type: "string", Format: "date-time"},
+			},
+			Required: []string{"id", "lines", "total", "valid", "expiresAt"},
+		},
+		"ReservationResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":     {Type: "string"},
+				"cartId": {Type: "string"},
+				"lines": arrayOf(&Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"productId": {Type: "string"},
+						"quantity":  {Type: "integer"},
+						"unitPrice": {Type: "integer"},
+						"total":     {Type: "integer"},
+					},
+					Required: []string{"productId", "quantity", "unitPrice", "total"},
+				}),
+				"currency":  {Type: "string"},
+				"total":     {Type: "integer"},
+				"createdAt": {Type: "string", Format: "date-time"},
+			},
+			Required: []string{"id", "cartId", "lines", "currency", "total", "createdAt"},
+		},
+		"HealthResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"status": {Type: "string", Enum: []string{"ok", "unavailable"}},
+				"checks": {Type: "object", Description: "Result of each readiness check: ok or the error"},
+			},
+			Required: []string{"status"},
+		},
+		"GraphQLRequest": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"query":         {Type: "string", MinLength: intPtr(1)},
+				"operationName": {Type: "string"},
+				"variables":     {Type: "object"},
+			},
+			Required: []string{"query"},
+		},
+		"GraphQLResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"data":   {Type: "object"},
+				"errors": arrayOf(&Schema{Type: "object"}),
+			},
+		},
+	}
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func arrayOf(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+func pathParam(name, description string) *Parameter {
+	return &Parameter{Name: name, In: "path", Description: description, Required: true, Schema: &Schema{Type: "string", MinLength: intPtr(1)}}
+}
+
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]*MediaType{"application/json": {Schema: schema}}}
+}
+
+func jsonResponse(description string, schema *Schema) *Response {
+	return &Response{Description: description, Content: map[string]*MediaType{"application/json": {Schema: schema}}}
+}
+
+// cached adds the validator and Cache-Control headers to a response
+func cached(r *Response) *Response {
+	if r.Headers == nil {
+		r.Headers = make(map[string]*Header)
+	}
+	r.Headers["ETag"] = &Header{Description: "Strong validator of the representation", Schema: &Schema{Type: "string"}}
+	r.Headers["Last-Modified"] = &Header{Description: "Latest update time of the returned products", Schema: &Schema{Type: "string"}}
+	r.Headers["Cache-Control"] = &Header{Description: "Caching policy configured for the route", Schema: &Schema{Type: "string"}}
+	return r
+}
+
+// conditionalParams are the precondition headers honored by cacheable reads
+func conditionalParams() []*Parameter {
+	return []*Parameter{
+		{Name: "If-None-Match", In: "header", Description: "ETags the client holds; takes precedence over If-Modified-Since", Schema: &Schema{Type: "string"}},
+		{Name: "If-Modified-Since", In: "header", Description: "HTTP date of the client's copy", Schema: &Schema{Type: "string"}},
+	}
+}
+
+// acceptLanguage is the header cacheable product reads negotiate their locale with
+func acceptLanguage() *Parameter {
+	return &Parameter{Name: "Accept-Language", In: "header", Description: "Preferred locales; the default locale is used when none is supported", Schema: &Schema{Type: "string"}}
+}
+
+// localized adds the Content-Language header to the response of a negotiated read
+func localized(r *Response) *Response {
+	r.Headers["Content-Language"] = &Header{Description: "Locale of the names and descriptions", Schema: &Schema{Type: "string"}}
+	return r
+}
+
+func notModified() *Response {
+	return cached(&Response{Description: "The client's copy is still current"})
+}
+
+// rateLimited is the response of clients that exceeded their rate limit
+func rateLimited() *Response {
+	r := errorResponse("Rate limit exceeded")
+	r.Headers = map[string]*Header{
+		"Retry-After":         {Description: "Seconds until the next request is allowed", Schema: &Schema{Type: "integer"}},
+		"RateLimit-Limit":     {Description: "Capacity of the client's token bucket", Schema: &Schema{Type: "integer"}},
+		"RateLimit-Remaining": {Description: "Requests left in the bucket", Schema: &Schema{Type: "integer"}},
+		"RateLimit-Reset":     {Description: "Seconds until the bucket is full again", Schema: &Schema{Type: "integer"}},
+		"RateLimit-Policy":    {Description: "Bucket capacity and refill window, e.g. 60;w=60", Schema: &Schema{Type: "string"}},
+	}
+	return r
+}
+
+func errorResponse(description string) *Response {
+	return jsonResponse(description, ref("ErrorResponse"))
+}
+
+// movementTypes returns the names of the movement types, with or without transfers
+func movementTypes(withTransfer bool) []string {
+	var names []string
+	for _, mt := range domain.MovementTypes {
+		if mt != domain.MovementTransfer || withTransfer {
+			names = append(names, mt.String())
+		}
+	}
+	return names
+}
+
+// productStatuses returns the names of the product lifecycle statuses
+func productStatuses() []string {
+	names := make([]string, 0, len(domain.ProductStatuses))
+	for _, s := range domain.ProductStatuses {
+		names = append(names, s.String())
+	}
+	return names
+}
+
+// attributeTypes returns the names of the attribute types
+func attributeTypes() []string {
+	names := make([]string, 0, len(domain.AttributeTypes))
+	for _, t := range domain.AttributeTypes {
+		names = append(names, t.String())
+	}
+	return names
+}
+
+// orderStatuses returns the names of the order statuses
+func orderStatuses() []string {
+	names := make([]string, 0, len(order.Statuses))
+	for _, s := range order.Statuses {
+		names = append(names, s.String())
+	}
+	return names
+}
+
+// cartIssues returns the names of the issues of cart lines
+func cartIssues() []string {
+	names := make([]string, 0, len(cart.IssueTypes))
+	for _, issue := range cart.IssueTypes {
+		names = append(names, issue.String())
+	}
+	return names
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
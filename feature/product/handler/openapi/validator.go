@@ -0,0 +1,311 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+
+	"sago-sample/internal/textnorm"
+)
+
+// maxBodySize is the largest request body the validator reads
+const maxBodySize = 1 << 20
+
+// ValidationError describes why a request does not match the document
+type ValidationError struct {
+	Location string
+	Message  string
+}
+
+// Error returns the validation message prefixed with the offending location
+func (e *ValidationError) Error() string {
+	if e.Location == "" {
+		return e.Message
+	}
+	return e.Location + ": " + e.Message
+}
+
+// Validator validates incoming requests against a Document
+type Validator struct {
+	doc      *Document
+	mutex    sync.Mutex
+	patterns map[string]*regexp.Regexp
+}
+
+// NewValidator creates a new Validator for doc
+func NewValidator(doc *Document) *Validator {
+	return &Validator{
+		doc:      doc,
+		patterns: make(map[string]*regexp.Regexp),
+	}
+}
+
+// Middleware rejects requests that do not match their operation with 400 Bad Request.
+// Requests for paths or methods missing from the document are passed through so the router can answer them.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op, params, ok := v.doc.FindOperation(r.Method, r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err := v.ValidateRequest(r, op, params); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ValidateRequest validates the parameters and body of r against op.
+// The body is read and replaced, so handlers can still decode it.
+func (v *Validator) ValidateRequest(r *http.Request, op *Operation, pathParams map[string]string) error {
+	for _, p := range op.Parameters {
+		var value string
+		var present bool
+		switch p.In {
+		case "path":
+			value, present = pathParams[p.Name]
+		case "query":
+			present = r.URL.Query().Has(p.Name)
+			value = r.URL.Query().Get(p.Name)
+		case "header":
+			value = r.Header.Get(p.Name)
+			present = value != ""
+		default:
+			continue
+		}
+
+		location := p.In + " parameter " + strconv.Quote(p.Name)
+		if !present {
+			if p.Required {
+				return &ValidationError{Location: location, Message: "is required"}
+			}
+			continue
+		}
+		if err := v.validateParameter(location, value, v.doc.Resolve(p.Schema)); err != nil {
+			return err
+		}
+	}
+
+	if op.RequestBody == nil {
+		return nil
+	}
+	// Other bodies, such as multipart uploads, are read and limited by their handler
+	media, ok := op.RequestBody.Content["application/json"]
+	if !ok {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		return &ValidationError{Location: "body", Message: "could not be read"}
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) > maxBodySize {
+		return &ValidationError{Location: "body", Message: "is too large"}
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			return &ValidationError{Location: "body", Message: "is required"}
+		}
+		return nil
+	}
+
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if mt, _, err := mime.ParseMediaType(ct); err != nil || mt != "application/json" {
+			return &ValidationError{Location: "body", Message: "content type must be application/json"}
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return &ValidationError{Location: "body", Message: "is not valid JSON"}
+	}
+
+	return v.validateValue("body", value, media.Schema)
+}
+
+// validateParameter converts a raw parameter value to the schema type and validates it
+func (v *Validator) validateParameter(location, raw string, s *Schema) error {
+	if s == nil {
+		return nil
+	}
+
+	switch s.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return &ValidationError{Location: location, Message: mustBe(s.Type)}
+		}
+		return v.validateValue(location, json.Number(raw), s)
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return &ValidationError{Location: location, Message: "must be a boolean"}
+		}
+		return v.validateValue(location, b, s)
+	default:
+		return v.validateValue(location, raw, s)
+	}
+}
+
+// validateValue validates a decoded JSON value against a schema
+func (v *Validator) validateValue(location string, value interface{}, s *Schema) error {
+	s = v.doc.Resolve(s)
+	if s == nil {
+		return nil
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return &ValidationError{Location: location, Message: "must be an object"}
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return &ValidationError{Location: location + "." + name, Message: "is required"}
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if field, ok := obj[name]; ok {
+				if err := v.validateValue(location+"."+name, field, s.Properties[name]); err != nil {
+					return err
+				}
+			}
+		}
+
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return &ValidationError{Location: location, Message: "must be an array"}
+		}
+		for i, item := range arr {
+			if err := v.validateValue(fmt.Sprintf("%s[%d]", location, i), item, s.Items); err != nil {
+				return err
+			}
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return &ValidationError{Location: location, Message: "must be a string"}
+		}
+		// JSON Schema lengths count characters, not bytes; they are counted on the text the domain stores
+		shortest, longest := normalizedLengths(str)
+		if s.MinLength != nil && longest < *s.MinLength {
+			return &ValidationError{Location: location, Message: fmt.Sprintf("must be at least %d characters", *s.MinLength)}
+		}
+		if s.MaxLength != nil && shortest > *s.MaxLength {
+			return &ValidationError{Location: location, Message: fmt.Sprintf("must be at most %d characters", *s.MaxLength)}
+		}
+		if s.Pattern != "" && !v.pattern(s.Pattern).MatchString(str) {
+			return &ValidationError{Location: location, Message: "must match pattern " + s.Pattern}
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			return &ValidationError{Location: location, Message: fmt.Sprintf("must be one of %v", s.Enum)}
+		}
+
+	case "integer", "number":
+		num, ok := value.(json.Number)
+		if !ok {
+			return &ValidationError{Location: location, Message: mustBe(s.Type)}
+		}
+		f, err := num.Float64()
+		if err != nil {
+			return &ValidationError{Location: location, Message: mustBe(s.Type)}
+		}
+		if s.Type == "integer" {
+			if _, err := num.Int64(); err != nil {
+				return &ValidationError{Location: location, Message: "must be an integer"}
+			}
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			return &ValidationError{Location: location, Message: fmt.Sprintf("must be at least %v", *s.Minimum)}
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			return &ValidationError{Location: location, Message: fmt.Sprintf("must be at most %v", *s.Maximum)}
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return &ValidationError{Location: location, Message: "must be a boolean"}
+		}
+	}
+
+	return nil
+}
+
+// pattern returns the compiled regular expression for a schema pattern
+func (v *Validator) pattern(expr string) *regexp.Regexp {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	re, ok := v.patterns[expr]
+	if !ok {
+		re = regexp.MustCompile(expr)
+		v.patterns[expr] = re
+	}
+	return re
+}
+
+// mustBe returns the message for a value that is not of the expected numeric type
+func mustBe(typ string) string {
+	if typ == "integer" {
+		return "must be an integer"
+	}
+	return "must be a " + typ
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// respondWithError writes an error in the same format as the product handlers
+func respondWithError(w http.ResponseWriter, code int, message string) {
+	response, _ := json.Marshal(map[string]string{"error": message})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
+
+// normalizedLengths returns the lengths of a text normalized as the domain normalizes it, as a single line and as a block
+// of lines. Which of the two a field is is not known here, so the bounds are checked on the length leaving the most room
+// and the domain checks the exact one. A text that cannot be normalized is measured as is and rejected by the domain.
+func normalizedLengths(str string) (shortest, longest int) {
+	line, err := textnorm.Line(str)
+	if err != nil {
+		length := textnorm.Length(str)
+		return length, length
+	}
+	block, err := textnorm.Block(str)
+	if err != nil {
+		block = line
+	}
+	// A line is never longer than the block: the blank lines kept between paragraphs become one space
+	return textnorm.Length(line), textnorm.Length(block)
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	product "sago-sample/feature/product/usecase"
+)
+
+// PatchProductRequest represents the request body for partially updating a product.
+// Omitted fields keep their current value.
+type PatchProductRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Price       *uint   `json:"price,omitempty"`
+	Currency    *string `json:"currency,omitempty"`
+	Stock       *uint   `json:"stock,omitempty"`
+}
+
+type PatchProductHandler struct {
+	UseCase *product.PatchProductUseCase
+}
+
+func NewPatchProductHandler(uc *product.PatchProductUseCase) *PatchProductHandler {
+	return &PatchProductHandler{UseCase: uc}
+}
+
+func (h *PatchProductHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var req PatchProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	in := product.PatchProductInput{
+		ID:          chi.URLParam(r, "id"),
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Currency:    req.Currency,
+		Stock:       req.Stock,
+	}
+
+	out, err := h.UseCase.Execute(r.Context(), in)
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toProductResponse(*out))
+}
@@ -1,58 +1,34 @@
 package handler
 
 import (
+	"github.com/go-chi/chi/v5"
 	"net/http"
-	"sago-sample/api"
-	"strings"
 
 	product "sago-sample/feature/product/usecase"
 )
 
-// RemoveCategoryFromProduct handles removing a category from a product
-func (h *api.ProductHandler) RemoveCategoryFromProduct(w http.ResponseWriter, r *http.Request) {
-	// Extract product ID and category ID from URL
-	path := strings.TrimPrefix(r.URL.Path, "/products/")
-	parts := strings.Split(path, "/categories/")
-	if len(parts) != 2 {
-		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
-		return
-	}
-
-	productID := parts[0]
-	categoryID := parts[1]
-
+// HandleRemove handles removing a category from a product
+func (h *CategoryHandler) HandleRemove(w http.ResponseWriter, r *http.Request) {
 	input := product.RemoveCategoryFromProductInput{
-		ProductID:  productID,
-		CategoryID: categoryID,
+		ProductID:  chi.URLParam(r, "id"),
+		CategoryID: chi.URLParam(r, "cid"),
 	}
 
-	output, err := h.removeCategoryFromProductUseCase.Execute(r.Context(), input)
+	output, err := h.RemoveUseCase.Execute(r.Context(), input)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			respondWithError(w, http.StatusNotFound, err.Error())
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithUseCaseError(w, err)
 		return
 	}
 
-	// Map categories
-	categories := make([]CategoryResponse, 0, len(output.Categories))
-	for _, c := range output.Categories {
-		categories = append(categories, CategoryResponse{
-			ID:   c.ID,
-			Name: c.Name,
-		})
-	}
-
 	response := ProductResponse{
 		ID:          output.ProductID,
 		Name:        output.Name,
 		Description: output.Description,
 		Price:       output.Price,
 		Currency:    output.Currency,
+		Status:      output.Status,
 		Stock:       output.Stock,
-		Categories:  categories,
+		Categories:  toCategoryResponses(output.Categories),
 	}
 
 	respondWithJSON(w, http.StatusOK, response)
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	product "sago-sample/feature/product/usecase"
+)
+
+// ReorderPolicyRequest represents the request body for setting the reorder policy of a product
+type ReorderPolicyRequest struct {
+	Threshold uint `json:"threshold"`
+	Target    uint `json:"target"`
+}
+
+// ReorderPolicyResponse represents the reorder policy of a product
+type ReorderPolicyResponse struct {
+	ProductID string `json:"productId"`
+	Threshold uint   `json:"threshold"`
+	Target    uint   `json:"target"`
+}
+
+// ReorderSuggestionResponse represents a product to reorder
+type ReorderSuggestionResponse struct {
+	ProductID         string `json:"productId"`
+	ProductName       string `json:"productName"`
+	Status            string `json:"status"`
+	Stock             uint   `json:"stock"`
+	Threshold         uint   `json:"threshold"`
+	Target            uint   `json:"target"`
+	SuggestedQuantity uint   `json:"suggestedQuantity"`
+}
+
+// ReorderHandler handles the reorder policies of products and the reorder suggestions
+type ReorderHandler struct {
+	SetPolicyUseCase      *product.SetReorderPolicyUseCase
+	GetPolicyUseCase      *product.GetReorderPolicyUseCase
+	DeletePolicyUseCase   *product.DeleteReorderPolicyUseCase
+	GetSuggestionsUseCase *product.GetReorderSuggestionsUseCase
+}
+
+func NewReorderHandler(
+	setPolicyUc *product.SetReorderPolicyUseCase,
+	getPolicyUc *product.GetReorderPolicyUseCase,
+	deletePolicyUc *product.DeleteReorderPolicyUseCase,
+	getSuggestionsUc *product.GetReorderSuggestionsUseCase,
+) *ReorderHandler {
+	return &ReorderHandler{
+		SetPolicyUseCase:      setPolicyUc,
+		GetPolicyUseCase:      getPolicyUc,
+		DeletePolicyUseCase:   deletePolicyUc,
+		GetSuggestionsUseCase: getSuggestionsUc,
+	}
+}
+
+// Register adds the reorder routes to rtr
+func (h *ReorderHandler) Register(rtr chi.Router) {
+	rtr.Get("/api/products/{id}/reorder-policy", h.HandleGetPolicy)       // GET    /api/products/{id}/reorder-policy
+	rtr.Put("/api/products/{id}/reorder-policy", h.HandleSetPolicy)       // PUT    /api/products/{id}/reorder-policy
+	rtr.Delete("/api/products/{id}/reorder-policy", h.HandleDeletePolicy) // DELETE /api/products/{id}/reorder-policy
+	rtr.Get("/api/inventory/reorder-suggestions", h.HandleGetSuggestions) // GET    /api/inventory/reorder-suggestions
+}
+
+// HandleGetPolicy handles getting the reorder policy of a product
+func (h *ReorderHandler) HandleGetPolicy(w http.ResponseWriter, r *http.Request) {
+	output, err := h.GetPolicyUseCase.Execute(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toReorderPolicyResponse(*output))
+}
+
+// HandleSetPolicy handles setting the reorder policy of a product
+func (h *ReorderHandler) HandleSetPolicy(w http.ResponseWriter, r *http.Request) {
+	var req ReorderPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	output, err := h.SetPolicyUseCase.Execute(r.Context(), product.SetReorderPolicyInput{
+		ProductID: chi.URLParam(r, "id"),
+		Threshold: req.Threshold,
+		Target:    req.Target,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toReorderPolicyResponse(*output))
+}
+
+// HandleDeletePolicy handles removing the reorder policy of a product
+func (h *ReorderHandler) HandleDeletePolicy(w http.ResponseWriter, r *http.Request) {
+	if err := h.DeletePolicyUseCase.Execute(r.Context(), chi.URLParam(r, "id")); err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleGetSuggestions handles listing the products to reorder with the quantities to order
+func (h *ReorderHandler) HandleGetSuggestions(w http.ResponseWriter, r *http.Request) {
+	outputs, err := h.GetSuggestionsUseCase.Execute(r.Context())
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	responses := make([]ReorderSuggestionResponse, 0, len(outputs))
+	for _, o := range outputs {
+		responses = append(responses, ReorderSuggestionResponse{
+			ProductID:         o.ProductID,
+			ProductName:       o.ProductName,
+			Status:            o.Status,
+			Stock:             o.Stock,
+			Threshold:         o.Threshold,
+			Target:            o.Target,
+			SuggestedQuantity: o.SuggestedQuantity,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, responses)
+}
+
+// toReorderPolicyResponse maps a use case reorder policy output to a response
+func toReorderPolicyResponse(o product.ReorderPolicyOutput) ReorderPolicyResponse {
+	return ReorderPolicyResponse{ProductID: o.ProductID, Threshold: o.Threshold, Target: o.Target}
+}
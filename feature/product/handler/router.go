@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/handler/openapi"
+	"sago-sample/feature/product/handler/sse"
+	"sago-sample/principal"
+)
+
+// Handlers are the handlers of the product routes served by NewRouter
+type Handlers struct {
+	GetProduct    *GetProductHandler
+	CreateProduct *CreateProductHandler
+	UpdateProduct *UpdateProductHandler
+	PatchProduct  *PatchProductHandler
+	DeleteProduct *DeleteProductHandler
+	Category      *CategoryHandler
+	GraphQL       http.Handler
+	Stream        *sse.Handler
+}
+
+// NewRouter creates a chi router serving every product endpoint.
+// Requests are validated against the OpenAPI document, which is served at /openapi.json.
+// The middlewares, e.g. metrics, run before validation in the given order.
+// Stock changes are attributed to the caller of the request, see principal.FromRequest.
+func NewRouter(h Handlers, middlewares ...func(http.Handler) http.Handler) chi.Router {
+	rtr := chi.NewRouter()
+	rtr.Use(middlewares...)
+	rtr.Use(withActor)
+	rtr.Use(openapi.NewValidator(openapi.Spec()).Middleware)
+
+	// OpenAPI
+	rtr.Get("/openapi.json", openapi.Handler) // GET /openapi.json
+
+	// Product
+	rtr.Get("/api/products", h.GetProduct.HandleGetAll)       // GET    /api/products
+	rtr.Get("/api/products/{id}", h.GetProduct.HandleGetByID) // GET    /api/products/{id}
+	rtr.Post("/api/products", h.CreateProduct.Handle)         // POST   /api/products
+	rtr.Put("/api/products/{id}", h.UpdateProduct.Handle)     // PUT    /api/products/{id}
+	rtr.Patch("/api/products/{id}", h.PatchProduct.Handle)    // PATCH  /api/products/{id}
+	rtr.Delete("/api/products/{id}", h.DeleteProduct.Handle)  // DELETE /api/products/{id}
+
+	// Live changes
+	rtr.Get("/api/products/stream", h.Stream.HandleStream)             // GET    /api/products/stream
+	rtr.Get("/api/products/{id}/stream", h.Stream.HandleProductStream) // GET    /api/products/{id}/stream
+
+	// Category on Product
+	rtr.Post("/api/products/{id}/categories", h.Category.HandleAdd)            // POST   /api/products/{id}/categories
+	rtr.Delete("/api/products/{id}/categories/{cid}", h.Category.HandleRemove) // DELETE /api/products/{id}/categories/{cid}
+
+	// List by category
+	rtr.Get("/api/categories/{id}/products", h.GetProduct.HandleByCategory) // GET /api/categories/{id}/products
+
+	// GraphQL
+	rtr.Get("/graphql", h.GraphQL.ServeHTTP)  // GET  /graphql
+	rtr.Post("/graphql", h.GraphQL.ServeHTTP) // POST /graphql
+
+	// hello world
+	rtr.Get("/api/hello", helloHandler) // GET /api/hello
+
+	return rtr
+}
+
+// withActor attributes the changes made by a request to its caller
+func withActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(domain.WithActor(r.Context(), principal.FromRequest(r))))
+	})
+}
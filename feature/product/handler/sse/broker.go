@@ -0,0 +1,293 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	domain "sago-sample/feature/product/domain"
+)
+
+// DefaultReplaySize is the number of events kept for Last-Event-ID resumption
+const DefaultReplaySize = 1024
+
+// subscriberBuffer is the number of events a subscriber may lag behind before it is disconnected
+const subscriberBuffer = 64
+
+// Message is a product event ready to be written to a stream
+type Message struct {
+	ID          uint64
+	Event       string
+	Data        []byte
+	ProductID   string
+	CategoryIDs []string
+}
+
+// EventData is the JSON data of a stream message
+type EventData struct {
+	Type           string       `json:"type"`
+	ProductID      string       `json:"productId"`
+	Product        *ProductData `json:"product,omitempty"`
+	PreviousPrice  *uint        `json:"previousPrice,omitempty"`
+	PreviousStock  *uint        `json:"previousStock,omitempty"`
+	CategoryID     string       `json:"categoryId,omitempty"`
+	PreviousStatus string       `json:"previousStatus,omitempty"`
+	OccurredAt     time.Time    `json:"occurredAt"`
+}
+
+// ProductData is the state of the product after the change
+type ProductData struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description"`
+	Price          uint           `json:"price"`
+	Currency       string         `json:"currency"`
+	Status         string         `json:"status"`
+	AvailableFrom  *time.Time     `json:"availableFrom,omitempty"`
+	AvailableUntil *time.Time     `json:"availableUntil,omitempty"`
+	Attributes     map[string]any `json:"attributes,omitempty"`
+	Stock          uint           `json:"stock"`
+	Categories     []CategoryData `json:"categories"`
+}
+
+// CategoryData is a category of the product
+type CategoryData struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Filter selects the messages delivered to a subscriber; empty fields match everything
+type Filter struct {
+	ProductID   string
+	CategoryIDs []string
+}
+
+// Matches checks if the message passes the filter
+func (f Filter) Matches(m Message) bool {
+	if f.ProductID != "" && f.ProductID != m.ProductID {
+		return false
+	}
+	if len(f.CategoryIDs) == 0 {
+		return true
+	}
+	for _, want := range f.CategoryIDs {
+		for _, got := range m.CategoryIDs {
+			if want == got {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Subscription receives the messages published after it was created
+type Subscription struct {
+	// C is closed when the subscriber falls too far behind or the broker is closed
+	C      <-chan Message
+	c      chan Message
+	filter Filter
+}
+
+// Broker fans product events out to stream subscribers.
+// It keeps the last events in a bounded buffer so reconnecting clients can resume from their Last-Event-ID.
+type Broker struct {
+	mutex       sync.Mutex
+	nextID      uint64
+	replay      []Message
+	replaySize  int
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewBroker creates a new Broker keeping replaySize events for resumption
+func NewBroker(replaySize int) *Broker {
+	if replaySize <= 0 {
+		replaySize = DefaultReplaySize
+	}
+	return &Broker{
+		nextID:      1,
+		replaySize:  replaySize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// HandleEvent publishes a product event to the matching subscribers.
+// Only the events of published products are streamed, and the change withdrawing a product from publication.
+func (b *Broker) HandleEvent(ctx context.Context, event domain.Event) {
+	if !isPublic(event) {
+		return
+	}
+	data, categoryIDs := newEventData(event)
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	msg := Message{
+		ID:          b.nextID,
+		Event:       event.Type.String(),
+		Data:        encoded,
+		ProductID:   event.ProductID.String(),
+		CategoryIDs: categoryIDs,
+	}
+	b.nextID++
+
+	b.replay = append(b.replay, msg)
+	if len(b.replay) > b.replaySize {
+		b.replay = append(b.replay[:0:0], b.replay[len(b.replay)-b.replaySize:]...)
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(msg) {
+			continue
+		}
+		select {
+		case sub.c <- msg:
+		default:
+			// The client cannot keep up; it resumes from its last event ID after reconnecting
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber and returns the buffered messages published after lastEventID.
+// lastEventID is the Last-Event-ID sent by a reconnecting client, or empty for a new stream.
+func (b *Broker) Subscribe(filter Filter, lastEventID string) ([]Message, *Subscription) {
+	c := make(chan Message, subscriberBuffer)
+	sub := &Subscription{C: c, c: c, filter: filter}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		close(c)
+		return nil, sub
+	}
+	b.subscribers[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return nil, sub
+	}
+
+	last, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil || last >= b.nextID {
+		// The ID was not issued by this broker, e.g. before a restart; replay everything still buffered
+		last = 0
+	}
+
+	var replay []Message
+	for _, msg := range b.replay {
+		if msg.ID > last && filter.Matches(msg) {
+			replay = append(replay, msg)
+		}
+	}
+	return replay, sub
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.remove(sub)
+}
+
+// Subscribers returns the number of connected subscribers
+func (b *Broker) Subscribers() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return len(b.subscribers)
+}
+
+// Close disconnects every subscriber; later subscriptions are closed immediately
+func (b *Broker) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		b.remove(sub)
+	}
+}
+
+// remove drops a subscriber; the caller must hold the mutex
+func (b *Broker) remove(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.c)
+}
+
+// newEventData builds the message data and the category IDs used for filtering
+func newEventData(event domain.Event) (EventData, []string) {
+	data := EventData{
+		Type:       event.Type.String(),
+		ProductID:  event.ProductID.String(),
+		OccurredAt: event.OccurredAt,
+	}
+
+	var categoryIDs []string
+	if p := event.Product; p != nil {
+		categories := make([]CategoryData, 0, len(p.Categories()))
+		for _, c := range p.Categories() {
+			categories = append(categories, CategoryData{ID: c.ID().String(), Name: c.Name().String()})
+			categoryIDs = append(categoryIDs, c.ID().String())
+		}
+		data.Product = &ProductData{
+			ID:          p.ID().String(),
+			Name:        p.Name().String(),
+			Description: p.Description().String(),
+			Price:       p.Price().Amount(),
+			Currency:    p.Price().Currency(),
+			Status:      p.Status().String(),
+			Attributes:  p.Attributes(),
+			Stock:       p.Stock().Quantity(),
+			Categories:  categories,
+		}
+		if from := p.Availability().From(); !from.IsZero() {
+			data.Product.AvailableFrom = &from
+		}
+		if until := p.Availability().Until(); !until.IsZero() {
+			data.Product.AvailableUntil = &until
+		}
+	}
+
+	switch event.Type {
+	case domain.EventPriceChanged:
+		amount := event.PreviousPrice.Amount()
+		data.PreviousPrice = &amount
+	case domain.EventStockChanged:
+		quantity := event.PreviousStock.Quantity()
+		data.PreviousStock = &quantity
+	case domain.EventCategoryAdded, domain.EventCategoryRemoved:
+		data.CategoryID = event.CategoryID.String()
+		// A product leaving a category is still reported to that category's subscribers
+		categoryIDs = append(categoryIDs, data.CategoryID)
+	case domain.EventStatusChanged:
+		data.PreviousStatus = event.PreviousStatus.String()
+	}
+
+	return data, categoryIDs
+}
+
+// isPublic reports whether an event concerns a published product or withdraws one from publication
+func isPublic(event domain.Event) bool {
+	if event.Product == nil {
+		return false
+	}
+	if event.Product.Status() == domain.StatusPublished {
+		return true
+	}
+	return event.Type == domain.EventStatusChanged && event.PreviousStatus == domain.StatusPublished
+}
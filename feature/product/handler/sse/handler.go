@@ -0,0 +1,122 @@
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	domain "sago-sample/feature/product/domain"
+	product "sago-sample/feature/product/usecase"
+)
+
+// DefaultHeartbeat is the interval between comments sent to keep idle connections open
+const DefaultHeartbeat = 15 * time.Second
+
+// retryMillis is the reconnection delay advertised to clients
+const retryMillis = 3000
+
+// Handler streams product events as Server-Sent Events
+type Handler struct {
+	Broker     *Broker
+	GetUseCase *product.GetProductUseCase
+	Heartbeat  time.Duration
+}
+
+// NewHandler creates a new Handler; a heartbeat of zero uses DefaultHeartbeat
+func NewHandler(broker *Broker, getUc *product.GetProductUseCase, heartbeat time.Duration) *Handler {
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeat
+	}
+	return &Handler{Broker: broker, GetUseCase: getUc, Heartbeat: heartbeat}
+}
+
+// HandleStream streams the events of every product, or of the products in the ?category= categories
+func (h *Handler) HandleStream(w http.ResponseWriter, r *http.Request) {
+	h.stream(w, r, Filter{CategoryIDs: r.URL.Query()["category"]})
+}
+
+// HandleProductStream streams the events of one published product available to customers
+func (h *Handler) HandleProductStream(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	input := product.GetProductInput{ID: id, Status: string(domain.StatusPublished), Available: true}
+	if _, err := h.GetUseCase.Execute(r.Context(), input); err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrProductNotFound) {
+			code = http.StatusNotFound
+		}
+		respondWithError(w, code, err.Error())
+		return
+	}
+
+	h.stream(w, r, Filter{ProductID: id})
+}
+
+// stream writes the replayed and live messages matching filter until the client disconnects
+func (h *Handler) stream(w http.ResponseWriter, r *http.Request, filter Filter) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondWithError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+
+	// Streams outlive the server's WriteTimeout, so the write deadline is lifted for this response
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	replay, sub := h.Broker.Subscribe(filter, lastEventID)
+	defer h.Broker.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+	for _, msg := range replay {
+		writeMessage(w, msg)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			writeMessage(w, msg)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeMessage writes one message in the text/event-stream format
+func writeMessage(w http.ResponseWriter, msg Message) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Event, msg.Data)
+}
+
+// respondWithError returns an error response in the API's error format
+func respondWithError(w http.ResponseWriter, code int, message string) {
+	response, _ := json.Marshal(map[string]string{"error": message})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
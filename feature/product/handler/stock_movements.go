@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	product "sago-sample/feature/product/usecase"
+)
+
+// RecordStockMovementRequest represents the request body for recording a stock movement
+type RecordStockMovementRequest struct {
+	Type        string `json:"type"`
+	WarehouseID string `json:"warehouseId,omitempty"`
+	Quantity    int    `json:"quantity"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// StockMovementResponse represents an entry of the stock ledger
+type StockMovementResponse struct {
+	ID          string    `json:"id"`
+	WarehouseID string    `json:"warehouseId"`
+	Type        string    `json:"type"`
+	Quantity    int       `json:"quantity"`
+	Balance     int       `json:"balance"`
+	Reason      string    `json:"reason,omitempty"`
+	Actor       string    `json:"actor"`
+	OccurredAt  time.Time `json:"occurredAt"`
+}
+
+// StockDriftResponse represents a warehouse where the stock of a product differs from its ledger
+type StockDriftResponse struct {
+	ProductID   string `json:"productId"`
+	WarehouseID string `json:"warehouseId"`
+	Ledger      int    `json:"ledger"`
+	Stock       uint   `json:"stock"`
+	Difference  int    `json:"difference"`
+}
+
+// ReconciliationResponse represents the result of a reconciliation
+type ReconciliationResponse struct {
+	Products int                  `json:"products"`
+	Drifts   []StockDriftResponse `json:"drifts"`
+}
+
+// StockMovementHandler handles the stock ledger
+type StockMovementHandler struct {
+	ListUseCase      *product.ListStockMovementsUseCase
+	RecordUseCase    *product.RecordStockMovementUseCase
+	ReconcileUseCase *product.ReconcileStockUseCase
+}
+
+func NewStockMovementHandler(listUc *product.ListStockMovementsUseCase, recordUc *product.RecordStockMovementUseCase, reconcileUc *product.ReconcileStockUseCase) *StockMovementHandler {
+	return &StockMovementHandler{ListUseCase: listUc, RecordUseCase: recordUc, ReconcileUseCase: reconcileUc}
+}
+
+// Register adds the stock ledger routes to rtr
+func (h *StockMovementHandler) Register(rtr chi.Router) {
+	rtr.Get("/api/products/{id}/stock-movements", h.HandleList)    // GET  /api/products/{id}/stock-movements
+	rtr.Post("/api/products/{id}/stock-movements", h.HandleRecord) // POST /api/products/{id}/stock-movements
+	rtr.Get("/api/inventory/reconciliation", h.HandleReconcile)    // GET  /api/inventory/reconciliation
+}
+
+// HandleList handles listing the stock ledger of a product, oldest first
+func (h *StockMovementHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	outputs, err := h.ListUseCase.Execute(r.Context(), product.ListStockMovementsInput{
+		ProductID:   chi.URLParam(r, "id"),
+		Type:        r.URL.Query().Get("type"),
+		WarehouseID: r.URL.Query().Get("warehouse"),
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	responses := make([]StockMovementResponse, 0, len(outputs))
+	for _, o := range outputs {
+		responses = append(responses, StockMovementResponse{
+			ID:          o.ID,
+			WarehouseID: o.WarehouseID,
+			Type:        o.Type,
+			Quantity:    o.Quantity,
+			Balance:     o.Balance,
+			Reason:      o.Reason,
+			Actor:       o.Actor,
+			OccurredAt:  o.OccurredAt,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, responses)
+}
+
+// HandleRecord handles changing the stock of a product through a typed movement
+func (h *StockMovementHandler) HandleRecord(w http.ResponseWriter, r *http.Request) {
+	var req RecordStockMovementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	output, err := h.RecordUseCase.Execute(r.Context(), product.RecordStockMovementInput{
+		ProductID:   chi.URLParam(r, "id"),
+		WarehouseID: req.WarehouseID,
+		Type:        req.Type,
+		Quantity:    req.Quantity,
+		Reason:      req.Reason,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toInventoryResponse(*output))
+}
+
+// HandleReconcile handles comparing the stock of every product with its ledger
+func (h *StockMovementHandler) HandleReconcile(w http.ResponseWriter, r *http.Request) {
+	output, err := h.ReconcileUseCase.Execute(r.Context())
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	response := ReconciliationResponse{Products: output.Products, Drifts: make([]StockDriftResponse, 0, len(output.Drifts))}
+	for _, d := range output.Drifts {
+		response.Drifts = append(response.Drifts, StockDriftResponse{
+			ProductID:   d.ProductID,
+			WarehouseID: d.WarehouseID,
+			Ledger:      d.Ledger,
+			Stock:       d.Stock,
+			Difference:  d.Difference,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
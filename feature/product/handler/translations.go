@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	product "sago-sample/feature/product/usecase"
+)
+
+// ProductTranslationRequest represents the texts of a product in one locale
+type ProductTranslationRequest struct {
+	Name string `json:"name"`
+	// Description is omitted when the default description is shown in the locale
+	Description string `json:"description,omitempty"`
+}
+
+// ProductTranslationsResponse represents the translations of a product
+type ProductTranslationsResponse struct {
+	ProductID string `json:"productId"`
+	// DefaultLocale is the locale of the product's own name and description
+	DefaultLocale string `json:"defaultLocale"`
+	// Translations are keyed by locale
+	Translations map[string]ProductTranslationRequest `json:"translations"`
+}
+
+// CategoryTranslationRequest represents the name of a category in one locale
+type CategoryTranslationRequest struct {
+	Name string `json:"name"`
+}
+
+// CategoryTranslationsResponse represents the translated names of a category
+type CategoryTranslationsResponse struct {
+	CategoryID string `json:"categoryId"`
+	// DefaultLocale is the locale of the category's own name
+	DefaultLocale string `json:"defaultLocale"`
+	// Names are keyed by locale
+	Names map[string]string `json:"names"`
+}
+
+// TranslationHandler handles the translations of products and categories
+type TranslationHandler struct {
+	GetProductUseCase     *product.GetProductTranslationsUseCase
+	SetProductUseCase     *product.SetProductTranslationUseCase
+	RemoveProductUseCase  *product.RemoveProductTranslationUseCase
+	GetCategoryUseCase    *product.GetCategoryTranslationsUseCase
+	SetCategoryUseCase    *product.SetCategoryTranslationUseCase
+	RemoveCategoryUseCase *product.RemoveCategoryTranslationUseCase
+}
+
+func NewTranslationHandler(
+	getProductUc *product.GetProductTranslationsUseCase,
+	setProductUc *product.SetProductTranslationUseCase,
+	removeProductUc *product.RemoveProductTranslationUseCase,
+	getCategoryUc *product.GetCategoryTranslationsUseCase,
+	setCategoryUc *product.SetCategoryTranslationUseCase,
+	removeCategoryUc *product.RemoveCategoryTranslationUseCase,
+) *TranslationHandler {
+	return &TranslationHandler{
+		GetProductUseCase:     getProductUc,
+		SetProductUseCase:     setProductUc,
+		RemoveProductUseCase:  removeProductUc,
+		GetCategoryUseCase:    getCategoryUc,
+		SetCategoryUseCase:    setCategoryUc,
+		RemoveCategoryUseCase: removeCategoryUc,
+	}
+}
+
+// Register adds the translation routes to rtr
+func (h *TranslationHandler) Register(rtr chi.Router) {
+	rtr.Get("/api/products/{id}/translations", h.HandleGetProduct)                   // GET    /api/products/{id}/translations
+	rtr.Put("/api/products/{id}/translations/{locale}", h.HandleSetProduct)          // PUT    /api/products/{id}/translations/{locale}
+	rtr.Delete("/api/products/{id}/translations/{locale}", h.HandleRemoveProduct)    // DELETE /api/products/{id}/translations/{locale}
+	rtr.Get("/api/categories/{id}/translations", h.HandleGetCategory)                // GET    /api/categories/{id}/translations
+	rtr.Put("/api/categories/{id}/translations/{locale}", h.HandleSetCategory)       // PUT    /api/categories/{id}/translations/{locale}
+	rtr.Delete("/api/categories/{id}/translations/{locale}", h.HandleRemoveCategory) // DELETE /api/categories/{id}/translations/{locale}
+}
+
+// HandleGetProduct handles getting the translations of a product, whatever its status
+func (h *TranslationHandler) HandleGetProduct(w http.ResponseWriter, r *http.Request) {
+	output, err := h.GetProductUseCase.Execute(r.Context(), product.GetProductTranslationsInput{ProductID: chi.URLParam(r, "id")})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toProductTranslationsResponse(*output))
+}
+
+// HandleSetProduct handles adding or replacing the name and description of a product in a locale
+func (h *TranslationHandler) HandleSetProduct(w http.ResponseWriter, r *http.Request) {
+	var req ProductTranslationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	output, err := h.SetProductUseCase.Execute(r.Context(), product.SetProductTranslationInput{
+		ProductID:   chi.URLParam(r, "id"),
+		Locale:      chi.URLParam(r, "locale"),
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toProductTranslationsResponse(*output))
+}
+
+// HandleRemoveProduct handles removing the translation of a product in a locale
+func (h *TranslationHandler) HandleRemoveProduct(w http.ResponseWriter, r *http.Request) {
+	_, err := h.RemoveProductUseCase.Execute(r.Context(), product.RemoveProductTranslationInput{
+		ProductID: chi.URLParam(r, "id"),
+		Locale:    chi.URLParam(r, "locale"),
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleGetCategory handles getting the translated names of a category
+func (h *TranslationHandler) HandleGetCategory(w http.ResponseWriter, r *http.Request) {
+	output, err := h.GetCategoryUseCase.Execute(r.Context(), product.GetCategoryTranslationsInput{CategoryID: chi.URLParam(r, "id")})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toCategoryTranslationsResponse(*output))
+}
+
+// HandleSetCategory handles adding or replacing the name of a category in a locale
+func (h *TranslationHandler) HandleSetCategory(w http.ResponseWriter, r *http.Request) {
+	var req CategoryTranslationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	output, err := h.SetCategoryUseCase.Execute(r.Context(), product.SetCategoryTranslationInput{
+		CategoryID: chi.URLParam(r, "id"),
+		Locale:     chi.URLParam(r, "locale"),
+		Name:       req.Name,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toCategoryTranslationsResponse(*output))
+}
+
+// HandleRemoveCategory handles removing the name of a category in a locale
+func (h *TranslationHandler) HandleRemoveCategory(w http.ResponseWriter, r *http.Request) {
+	_, err := h.RemoveCategoryUseCase.Execute(r.Context(), product.RemoveCategoryTranslationInput{
+		CategoryID: chi.URLParam(r, "id"),
+		Locale:     chi.URLParam(r, "locale"),
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// toProductTranslationsResponse maps a use case product translations output to a response
+func toProductTranslationsResponse(o product.ProductTranslationsOutput) ProductTranslationsResponse {
+	translations := make(map[string]ProductTranslationRequest, len(o.Translations))
+	for locale, t := range o.Translations {
+		translations[locale] = ProductTranslationRequest{Name: t.Name, Description: t.Description}
+	}
+	return ProductTranslationsResponse{ProductID: o.ProductID, DefaultLocale: o.DefaultLocale, Translations: translations}
+}
+
+// toCategoryTranslationsResponse maps a use case category translations output to a response
+func toCategoryTranslationsResponse(o product.CategoryTranslationsOutput) CategoryTranslationsResponse {
+	return CategoryTranslationsResponse{CategoryID: o.CategoryID, DefaultLocale: o.DefaultLocale, Names: o.Names}
+}
@@ -7,36 +7,55 @@ import (
 	product "sago-sample/feature/product/usecase"
 )
 
+// UpdateProductRequest represents the request body for updating a product
+type UpdateProductRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Price       uint   `json:"price"`
+	Currency    string `json:"currency"`
+	Stock       uint   `json:"stock"`
+}
+
 type UpdateProductHandler struct {
-	UseCase *product.UpdateProductUseCase
+	UseCase    *product.UpdateProductUseCase
+	GetUseCase *product.GetProductUseCase
 }
 
-func NewUpdateProductHandler(uc *product.UpdateProductUseCase) *UpdateProductHandler {
-	return &UpdateProductHandler{UseCase: uc}
+func NewUpdateProductHandler(uc *product.UpdateProductUseCase, getUc *product.GetProductUseCase) *UpdateProductHandler {
+	return &UpdateProductHandler{UseCase: uc, GetUseCase: getUc}
 }
 
 func (h *UpdateProductHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	var in product.UpdateProductInput
-	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+	var req UpdateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 	defer r.Body.Close()
 
 	// Set the ID from the URL parameter
-	in.ID = id
+	in := product.UpdateProductInput{
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Currency:    req.Currency,
+		Stock:       req.Stock,
+	}
+
+	if _, err := h.UseCase.Execute(r.Context(), in); err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
 
-	out, err := h.UseCase.Execute(r.Context(), in)
+	// The update output carries no categories, so read the product back
+	out, err := h.GetUseCase.Execute(r.Context(), product.GetProductInput{ID: id})
 	if err != nil {
-		if err.Error() == "product not found" {
-			respondWithError(w, http.StatusNotFound, err.Error())
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithUseCaseError(w, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, out)
+	respondWithJSON(w, http.StatusOK, toProductResponse(product.ProductOutput(*out)))
 }
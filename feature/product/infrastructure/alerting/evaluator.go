@@ -0,0 +1,210 @@
+// Package alerting raises low-stock and out-of-stock alerts when the stock of a product crosses its reorder threshold
+package alerting
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	product "sago-sample/feature/product/domain"
+	"sago-sample/observability/logging"
+)
+
+// DefaultCooldown is how long an alert of a product is not repeated by default
+const DefaultCooldown = time.Hour
+
+// Config configures the evaluation of stock changes
+type Config struct {
+	// Cooldown is how long an alert of a product is not repeated, e.g. while the stock flaps around the threshold
+	Cooldown time.Duration
+	// QueueSize bounds the stock changes waiting for evaluation; further changes are dropped
+	QueueSize int
+	// Now returns the current time; time.Now when nil
+	Now func() time.Time
+}
+
+// stockChange is a change of the stock of a product, copied from the event since the product may change again before evaluation
+type stockChange struct {
+	ctx       context.Context
+	productID product.ProductID
+	name      product.ProductName
+	previous  product.Stock
+	current   product.Stock
+}
+
+// alertKey identifies the alerts of one type for one product
+type alertKey struct {
+	productID product.ProductID
+	alertType product.AlertType
+}
+
+// Evaluator checks the stock changes published by the product service against the reorder policies in the background,
+// and sends an alert through the notifier when the stock falls to the threshold or runs out.
+// An alert is not repeated for the same product within the cooldown.
+type Evaluator struct {
+	policies product.ReorderPolicyRepository
+	notifier product.Notifier
+	config   Config
+
+	queue   chan stockChange
+	done    chan struct{}
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+	started bool
+	stopped bool
+	// sent holds when each alert was last sent; it is only used by the worker
+	sent map[alertKey]time.Time
+}
+
+// NewEvaluator creates a new Evaluator; call Start to begin evaluating
+func NewEvaluator(policies product.ReorderPolicyRepository, notifier product.Notifier, config Config) *Evaluator {
+	if config.Cooldown < 0 {
+		config.Cooldown = 0
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 256
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return &Evaluator{
+		policies: policies,
+		notifier: notifier,
+		config:   config,
+		queue:    make(chan stockChange, config.QueueSize),
+		done:     make(chan struct{}),
+		sent:     make(map[alertKey]time.Time),
+	}
+}
+
+// Start launches the evaluation worker
+func (e *Evaluator) Start() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.started || e.stopped {
+		return
+	}
+	e.started = true
+
+	e.wg.Add(1)
+	go e.work()
+}
+
+// Stop stops the worker after the changes already queued are evaluated, waiting until ctx is done
+func (e *Evaluator) Stop(ctx context.Context) error {
+	e.mutex.Lock()
+	if e.stopped {
+		e.mutex.Unlock()
+		return nil
+	}
+	e.stopped = true
+	close(e.done)
+	e.mutex.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// HandleEvent queues the stock changes for evaluation without blocking the caller
+func (e *Evaluator) HandleEvent(ctx context.Context, event product.Event) {
+	if event.Type != product.EventStockChanged || event.Product == nil {
+		return
+	}
+
+	change := stockChange{
+		// The change is evaluated after the request ends, so only its values are kept
+		ctx:       context.WithoutCancel(ctx),
+		productID: event.ProductID,
+		name:      event.Product.Name(),
+		previous:  event.PreviousStock,
+		current:   event.Product.Stock(),
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.stopped {
+		return
+	}
+	select {
+	case e.queue <- change:
+	default:
+		logging.FromContext(ctx).Warn("alerting: queue full, stock change dropped", "product_id", event.ProductID.String())
+	}
+}
+
+// work evaluates the queued changes until Stop is called, then evaluates the changes left in the queue
+func (e *Evaluator) work() {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case change := <-e.queue:
+			e.evaluate(change)
+		case <-e.done:
+			for {
+				select {
+				case change := <-e.queue:
+					e.evaluate(change)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// evaluate sends the alert raised by a change, unless the same alert was sent within the cooldown
+func (e *Evaluator) evaluate(change stockChange) {
+	logger := logging.FromContext(change.ctx)
+
+	policy, err := e.policies.FindByProduct(change.ctx, change.productID)
+	if err != nil && !errors.Is(err, product.ErrReorderPolicyNotFound) {
+		logger.Error("alerting: failed to load reorder policy", "product_id", change.productID.String(), "error", err)
+		return
+	}
+
+	now := e.config.Now()
+	alert, ok := product.EvaluateStockChange(change.productID, change.name, change.previous, change.current, policy, now)
+	if !ok {
+		return
+	}
+
+	key := alertKey{productID: alert.ProductID, alertType: alert.Type}
+	if last, sent := e.sent[key]; sent && now.Sub(last) < e.config.Cooldown {
+		logger.Debug("alerting: duplicate alert suppressed", "product_id", alert.ProductID.String(), "alert", alert.Type.String())
+		return
+	}
+
+	if err := e.notifier.Notify(change.ctx, alert); err != nil {
+		logger.Error("alerting: failed to send alert", "product_id", alert.ProductID.String(), "alert", alert.Type.String(), "error", err)
+		return
+	}
+	e.sent[key] = now
+	e.forget(now)
+}
+
+// forget drops the alerts sent before the cooldown, so that sent does not grow with every product ever alerted
+func (e *Evaluator) forget(now time.Time) {
+	if len(e.sent) < 1024 {
+		return
+	}
+	for key, last := range e.sent {
+		if now.Sub(last) >= e.config.Cooldown {
+			delete(e.sent, key)
+		}
+	}
+}
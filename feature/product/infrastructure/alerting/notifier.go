@@ -0,0 +1,176 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	product "sago-sample/feature/product/domain"
+	"sago-sample/observability/logging"
+)
+
+// Notifier names, as used in the configuration
+const (
+	NotifierNone    = "none"
+	NotifierLog     = "log"
+	NotifierWebhook = "webhook"
+	NotifierFile    = "file"
+)
+
+// alertPayload is the JSON representation of an alert sent by the webhook and file notifiers
+type alertPayload struct {
+	Type              string    `json:"type"`
+	ProductID         string    `json:"productId"`
+	ProductName       string    `json:"productName"`
+	Stock             uint      `json:"stock"`
+	Threshold         uint      `json:"threshold,omitempty"`
+	Target            uint      `json:"target,omitempty"`
+	SuggestedQuantity uint      `json:"suggestedQuantity,omitempty"`
+	OccurredAt        time.Time `json:"occurredAt"`
+}
+
+func newAlertPayload(alert product.Alert) alertPayload {
+	return alertPayload{
+		Type:              alert.Type.String(),
+		ProductID:         alert.ProductID.String(),
+		ProductName:       alert.ProductName.String(),
+		Stock:             alert.Stock,
+		Threshold:         alert.Threshold,
+		Target:            alert.Target,
+		SuggestedQuantity: alert.SuggestedQuantity,
+		OccurredAt:        alert.OccurredAt.UTC(),
+	}
+}
+
+// NopNotifier discards alerts
+type NopNotifier struct{}
+
+// Notify implements product.Notifier
+func (NopNotifier) Notify(context.Context, product.Alert) error {
+	return nil
+}
+
+// LogNotifier writes alerts to the logger of the context as warnings
+type LogNotifier struct{}
+
+// Notify implements product.Notifier
+func (LogNotifier) Notify(ctx context.Context, alert product.Alert) error {
+	logging.FromContext(ctx).Warn("inventory alert",
+		"alert", alert.Type.String(),
+		"product_id", alert.ProductID.String(),
+		"stock", alert.Stock,
+		"threshold", alert.Threshold,
+		"suggested_quantity", alert.SuggestedQuantity,
+	)
+	return nil
+}
+
+// WebhookNotifier posts alerts as JSON to a URL; any status other than 2xx is an error
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a new WebhookNotifier; a client with a 10 second timeout is used when client is nil
+func NewWebhookNotifier(url string, client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookNotifier{url: url, client: client}
+}
+
+// Notify implements product.Notifier
+func (n *WebhookNotifier) Notify(ctx context.Context, alert product.Alert) error {
+	body, err := json.Marshal(newAlertPayload(alert))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sago-sample-alerts/1.0")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("alert webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FileNotifier appends alerts to a file, one JSON object per line
+type FileNotifier struct {
+	file  *os.File
+	mutex sync.Mutex
+}
+
+// NewFileNotifier creates a new FileNotifier appending to path, which is created if needed; call Close when done
+func NewFileNotifier(path string) (*FileNotifier, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileNotifier{file: file}, nil
+}
+
+// Notify implements product.Notifier
+func (n *FileNotifier) Notify(_ context.Context, alert product.Alert) error {
+	line, err := json.Marshal(newAlertPayload(alert))
+	if err != nil {
+		return err
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	_, err = n.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file
+func (n *FileNotifier) Close() error {
+	return n.file.Close()
+}
+
+// NewNotifier creates the notifier named by kind: NotifierNone, NotifierLog, NotifierWebhook posting to url,
+// or NotifierFile appending to path. The returned function releases the notifier.
+func NewNotifier(kind, url, path string) (product.Notifier, func() error, error) {
+	noClose := func() error { return nil }
+	switch kind {
+	case NotifierNone:
+		return NopNotifier{}, noClose, nil
+	case "", NotifierLog:
+		return LogNotifier{}, noClose, nil
+	case NotifierWebhook:
+		if url == "" {
+			return nil, nil, errors.New("the webhook notifier needs a URL")
+		}
+		return NewWebhookNotifier(url, nil), noClose, nil
+	case NotifierFile:
+		if path == "" {
+			return nil, nil, errors.New("the file notifier needs a path")
+		}
+		n, err := NewFileNotifier(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return n, n.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown notifier %q", kind)
+	}
+}
@@ -0,0 +1,54 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// AttributeSchemaRepository is an in-memory implementation of the product.AttributeSchemaRepository interface
+type AttributeSchemaRepository struct {
+	schemas map[product.CategoryID]*product.AttributeSchema
+	mutex   sync.RWMutex
+}
+
+// NewAttributeSchemaRepository creates a new in-memory attribute schema repository
+func NewAttributeSchemaRepository() *AttributeSchemaRepository {
+	return &AttributeSchemaRepository{
+		schemas: make(map[product.CategoryID]*product.AttributeSchema),
+	}
+}
+
+// FindByCategory finds the attribute schema of a category
+func (r *AttributeSchemaRepository) FindByCategory(ctx context.Context, categoryID product.CategoryID) (*product.AttributeSchema, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	schema, exists := r.schemas[categoryID]
+	if !exists {
+		return nil, product.ErrAttributeSchemaNotFound
+	}
+	return schema, nil
+}
+
+// Save persists an attribute schema, replacing the previous schema of the category
+func (r *AttributeSchemaRepository) Save(ctx context.Context, schema *product.AttributeSchema) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.schemas[schema.CategoryID()] = schema
+	return nil
+}
+
+// Delete removes the attribute schema of a category
+func (r *AttributeSchemaRepository) Delete(ctx context.Context, categoryID product.CategoryID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.schemas[categoryID]; !exists {
+		return product.ErrAttributeSchemaNotFound
+	}
+	delete(r.schemas, categoryID)
+	return nil
+}
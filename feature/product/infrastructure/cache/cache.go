@@ -0,0 +1,18 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is a byte store with per-entry expiry.
+// Implementations must be safe for concurrent use; LRUCache keeps entries in process and
+// RedisCache shares them between instances through any Redis-compatible server.
+type Cache interface {
+	// Get returns the value stored under key; ok is false when the key is missing or expired
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set stores value under key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the keys; missing keys are ignored
+	Delete(ctx context.Context, keys ...string) error
+}
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"encoding/json"
+	"time"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// productRecord is the cached representation of a product
+type productRecord struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Price       uint   `json:"price"`
+	Currency    string `json:"currency"`
+	Status      string `json:"status"`
+	// AvailableFrom and AvailableUntil are omitted for an open bound
+	AvailableFrom  *time.Time     `json:"availableFrom,omitempty"`
+	AvailableUntil *time.Time     `json:"availableUntil,omitempty"`
+	Attributes     map[string]any `json:"attributes,omitempty"`
+	Images         []imageRecord  `json:"images,omitempty"`
+	// Translations are keyed by locale
+	Translations map[string]translationRecord `json:"translations,omitempty"`
+	Stock        uint                         `json:"stock"`
+	StockLevels  []stockRecord                `json:"stockLevels"`
+	Categories   []categoryRecord             `json:"categories"`
+	CreatedAt    time.Time                    `json:"createdAt"`
+	UpdatedAt    time.Time                    `json:"updatedAt"`
+}
+
+type stockRecord struct {
+	WarehouseID string `json:"warehouseId"`
+	Quantity    uint   `json:"quantity"`
+}
+
+type imageRecord struct {
+	ID           string    `json:"id"`
+	Key          string    `json:"key"`
+	ThumbnailKey string    `json:"thumbnailKey"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type translationRecord struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type categoryRecord struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// notFound is cached for products known not to exist
+var notFound = []byte("null")
+
+// encodeProduct serializes a product for the cache
+func encodeProduct(p *product.Product) ([]byte, error) {
+	categories := make([]categoryRecord, 0, len(p.Categories()))
+	for _, c := range p.Categories() {
+		categories = append(categories, categoryRecord{ID: c.ID().String(), Name: c.Name().String()})
+	}
+
+	levels := make([]stockRecord, 0, len(p.StockLevels()))
+	for _, l := range p.StockLevels() {
+		levels = append(levels, stockRecord{WarehouseID: l.WarehouseID().String(), Quantity: l.Quantity()})
+	}
+
+	var images []imageRecord
+	for _, img := range p.Images() {
+		images = append(images, imageRecord{
+			ID:           img.ID().String(),
+			Key:          img.Key(),
+			ThumbnailKey: img.ThumbnailKey(),
+			ContentType:  img.ContentType(),
+			Size:         img.Size(),
+			Width:        img.Width(),
+			Height:       img.Height(),
+			CreatedAt:    img.CreatedAt(),
+		})
+	}
+
+	var translations map[string]translationRecord
+	for locale, t := range p.Translations() {
+		if translations == nil {
+			translations = make(map[string]translationRecord)
+		}
+		translations[locale.String()] = translationRecord{Name: t.Name().String(), Description: t.Description().String()}
+	}
+
+	return json.Marshal(productRecord{
+		ID:             p.ID().String(),
+		Name:           p.Name().String(),
+		Description:    p.Description().String(),
+		Price:          p.Price().Amount(),
+		Currency:       p.Price().Currency(),
+		Status:         p.Status().String(),
+		AvailableFrom:  optionalTime(p.Availability().From()),
+		AvailableUntil: optionalTime(p.Availability().Until()),
+		Attributes:     p.Attributes(),
+		Images:         images,
+		Translations:   translations,
+		Stock:          p.Stock().Quantity(),
+		StockLevels:    levels,
+		Categories:     categories,
+		CreatedAt:      p.CreatedAt(),
+		UpdatedAt:      p.UpdatedAt(),
+	})
+}
+
+// decodeProduct rebuilds a product from the cache; every value is validated again but the free texts,
+// which are restored as the repository restored them
+func decodeProduct(data []byte) (*product.Product, error) {
+	var r productRecord
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+
+	id, err := product.NewProductID(r.ID)
+	if err != nil {
+		return nil, err
+	}
+	name := product.ProductName(r.Name)
+	description := product.ProductDescription(r.Description)
+	price, err := product.NewPrice(r.Price, r.Currency)
+	if err != nil {
+		return nil, err
+	}
+	// Entries written before products had a lifecycle were all live
+	status := product.StatusPublished
+	if r.Status != "" {
+		if status, err = product.NewProductStatus(r.Status); err != nil {
+			return nil, err
+		}
+	}
+	var from, until time.Time
+	if r.AvailableFrom != nil {
+		from = *r.AvailableFrom
+	}
+	if r.AvailableUntil != nil {
+		until = *r.AvailableUntil
+	}
+	availability, err := product.NewAvailability(from, until)
+	if err != nil {
+		return nil, err
+	}
+	attributes := product.Attributes(r.Attributes)
+	var images []product.Image
+	for _, img := range r.Images {
+		images = append(images, product.RestoreImage(product.ImageID(img.ID), img.Key, img.ThumbnailKey, img.ContentType, img.Size, img.Width, img.Height, img.CreatedAt))
+	}
+	translations := make(map[product.Locale]product.ProductTranslation, len(r.Translations))
+	for tag, t := range r.Translations {
+		locale, err := product.NewLocale(tag)
+		if err != nil {
+			return nil, err
+		}
+		if translations[locale], err = restoreTranslation(t.Name, t.Description); err != nil {
+			return nil, err
+		}
+	}
+	// Entries written before stock was kept per warehouse only have the total
+	levels := []product.StockLevel{product.NewStockLevel(product.DefaultWarehouseID, r.Stock)}
+	if r.StockLevels != nil {
+		levels = make([]product.StockLevel, 0, len(r.StockLevels))
+		for _, l := range r.StockLevels {
+			warehouseID, err := product.NewWarehouseID(l.WarehouseID)
+			if err != nil {
+				return nil, err
+			}
+			levels = append(levels, product.NewStockLevel(warehouseID, l.Quantity))
+		}
+	}
+
+	categories := make([]*product.Category, 0, len(r.Categories))
+	for _, c := range r.Categories {
+		categoryID, err := product.NewCategoryID(c.ID)
+		if err != nil {
+			return nil, err
+		}
+		category, err := product.NewCategory(categoryID, product.CategoryName(c.Name))
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return product.RestoreProduct(id, name, description, price, status, availability, attributes, images, translations, levels, categories, r.CreatedAt, r.UpdatedAt), nil
+}
+
+// restoreTranslation rebuilds the translation of a product, restoring its texts as stored
+func restoreTranslation(rawName, rawDescription string) (product.ProductTranslation, error) {
+	return product.NewProductTranslation(product.ProductName(rawName), product.ProductDescription(rawDescription))
+}
+
+// optionalTime maps the zero time to nil
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,247 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	product "sago-sample/feature/product/domain"
+	"sago-sample/observability/logging"
+)
+
+const (
+	// DefaultTTL is how long a found product stays cached
+	DefaultTTL = 5 * time.Minute
+	// DefaultNegativeTTL is how long a missing product is remembered as missing
+	DefaultNegativeTTL = 30 * time.Second
+)
+
+// Options configures a caching Repository
+type Options struct {
+	TTL         time.Duration
+	NegativeTTL time.Duration
+}
+
+// Stats counts the cache lookups of a Repository
+type Stats struct {
+	// Hits are lookups answered with a cached product
+	Hits uint64
+	// NegativeHits are lookups answered with a cached ErrProductNotFound
+	NegativeHits uint64
+	// Misses are lookups that went to the underlying repository
+	Misses uint64
+	// Errors are failed cache reads, writes and invalidations
+	Errors uint64
+}
+
+// Repository is a read-through cache in front of a product.Repository.
+// FindByID is served from the cache, including ErrProductNotFound; concurrent misses for the
+// same product share one load. Save and Delete invalidate the entry before writing and again once the write is committed.
+// Reads inside a product service transaction, FindAll, FindByCategory and Facets are not cached.
+type Repository struct {
+	next    product.Repository
+	cache   Cache
+	options Options
+	group   singleflight.Group
+
+	// generation counts the invalidations so a load racing with a write is not cached. It is shared by every product:
+	// a load racing with the write of another product is not cached either, but nothing is kept per product ID.
+	generation atomic.Uint64
+
+	hits         atomic.Uint64
+	negativeHits atomic.Uint64
+	misses       atomic.Uint64
+	errors       atomic.Uint64
+}
+
+// NewRepository creates a new caching Repository decorating next
+func NewRepository(next product.Repository, cache Cache, options Options) *Repository {
+	if options.TTL <= 0 {
+		options.TTL = DefaultTTL
+	}
+	if options.NegativeTTL <= 0 {
+		options.NegativeTTL = DefaultNegativeTTL
+	}
+	return &Repository{
+		next:    next,
+		cache:   cache,
+		options: options,
+	}
+}
+
+// Stats returns the lookup counters
+func (r *Repository) Stats() Stats {
+	return Stats{
+		Hits:         r.hits.Load(),
+		NegativeHits: r.negativeHits.Load(),
+		Misses:       r.misses.Load(),
+		Errors:       r.errors.Load(),
+	}
+}
+
+// FindByID finds a product by its ID, from the cache when possible
+func (r *Repository) FindByID(ctx context.Context, id product.ProductID) (*product.Product, error) {
+	// A transaction must see, and lock, the stored product; what it reads may not be committed yet
+	if product.InTransaction(ctx) {
+		return r.next.FindByID(ctx, id)
+	}
+
+	key := productKey(id)
+
+	data, ok, err := r.cache.Get(ctx, key)
+	if err != nil {
+		r.fail(ctx, "read", key, err)
+	}
+	if ok {
+		if p, err := r.decode(data); err == nil {
+			if p == nil {
+				r.negativeHits.Add(1)
+				return nil, product.ErrProductNotFound
+			}
+			r.hits.Add(1)
+			return p, nil
+		}
+		r.fail(ctx, "decode", key, err)
+	}
+
+	r.misses.Add(1)
+
+	// The load outlives a cancelled caller because other callers may be waiting for it
+	loadCtx := context.WithoutCancel(ctx)
+	v, err, _ := r.group.Do(key, func() (interface{}, error) {
+		return r.load(loadCtx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Each caller decodes its own copy, so callers never share a mutable Product
+	p, err := r.decode(v.([]byte))
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, product.ErrProductNotFound
+	}
+	return p, nil
+}
+
+// load reads a product from the underlying repository and caches the encoded result
+func (r *Repository) load(ctx context.Context, id product.ProductID) ([]byte, error) {
+	key := productKey(id)
+	generation := r.generation.Load()
+
+	p, err := r.next.FindByID(ctx, id)
+	switch {
+	case errors.Is(err, product.ErrProductNotFound):
+		r.store(ctx, id, generation, notFound, r.options.NegativeTTL)
+		return notFound, nil
+	case err != nil:
+		return nil, err
+	}
+
+	data, err := encodeProduct(p)
+	if err != nil {
+		r.fail(ctx, "encode", key, err)
+		return nil, err
+	}
+	r.store(ctx, id, generation, data, r.options.TTL)
+	return data, nil
+}
+
+// store caches data unless a product was invalidated since generation was read
+func (r *Repository) store(ctx context.Context, id product.ProductID, generation uint64, data []byte, ttl time.Duration) {
+	if r.generation.Load() != generation {
+		return
+	}
+	if err := r.cache.Set(ctx, productKey(id), data, ttl); err != nil {
+		r.fail(ctx, "write", productKey(id), err)
+	}
+}
+
+// FindAll returns all products from the underlying repository
+func (r *Repository) FindAll(ctx context.Context) ([]*product.Product, error) {
+	return r.next.FindAll(ctx)
+}
+
+// FindByCategory finds products by category ID in the underlying repository
+func (r *Repository) FindByCategory(ctx context.Context, categoryID product.CategoryID) ([]*product.Product, error) {
+	return r.next.FindByCategory(ctx, categoryID)
+}
+
+// Facets counts the products per facet in the underlying repository
+func (r *Repository) Facets(ctx context.Context, query product.FacetQuery) (*product.Facets, error) {
+	return r.next.Facets(ctx, query)
+}
+
+// Ping checks the underlying repository when it supports it
+func (r *Repository) Ping(ctx context.Context) error {
+	if pinger, ok := r.next.(interface{ Ping(context.Context) error }); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// Save persists a product and invalidates its cache entry
+func (r *Repository) Save(ctx context.Context, p *product.Product) error {
+	if err := r.invalidate(ctx, p.ID()); err != nil {
+		return err
+	}
+	if err := r.next.Save(ctx, p); err != nil {
+		return err
+	}
+	r.invalidateAfterCommit(ctx, p.ID())
+	return nil
+}
+
+// Delete removes a product and invalidates its cache entry
+func (r *Repository) Delete(ctx context.Context, id product.ProductID) error {
+	if err := r.invalidate(ctx, id); err != nil {
+		return err
+	}
+	if err := r.next.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.invalidateAfterCommit(ctx, id)
+	return nil
+}
+
+// invalidate drops the cache entry of a product.
+// It is called before a write, so a failure aborts the write, and again after it to evict loads that raced with the write.
+func (r *Repository) invalidate(ctx context.Context, id product.ProductID) error {
+	r.generation.Add(1)
+
+	if err := r.cache.Delete(ctx, productKey(id)); err != nil {
+		r.fail(ctx, "invalidate", productKey(id), err)
+		return err
+	}
+	return nil
+}
+
+// invalidateAfterCommit invalidates the entry of a product again once the transaction of ctx commits,
+// so that loads made before the commit do not stay cached
+func (r *Repository) invalidateAfterCommit(ctx context.Context, id product.ProductID) {
+	product.AfterCommit(ctx, func(ctx context.Context) {
+		r.invalidate(context.WithoutCancel(ctx), id)
+	})
+}
+
+// decode returns the cached product, or nil for a cached ErrProductNotFound
+func (r *Repository) decode(data []byte) (*product.Product, error) {
+	if string(data) == string(notFound) {
+		return nil, nil
+	}
+	return decodeProduct(data)
+}
+
+func (r *Repository) fail(ctx context.Context, op, key string, err error) {
+	r.errors.Add(1)
+	logging.FromContext(ctx).Warn("cache operation failed", "op", op, "key", key, "error", err)
+}
+
+func productKey(id product.ProductID) string {
+	return "product:" + id.String()
+}
@@ -0,0 +1,48 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// CategoryTranslationRepository is an in-memory implementation of the product.CategoryTranslationRepository interface.
+// It keeps copies, so translations being changed are never seen by concurrent readers.
+type CategoryTranslationRepository struct {
+	names map[product.CategoryID]map[product.Locale]product.CategoryName
+	mutex sync.RWMutex
+}
+
+// NewCategoryTranslationRepository creates a new in-memory category translation repository
+func NewCategoryTranslationRepository() *CategoryTranslationRepository {
+	return &CategoryTranslationRepository{
+		names: make(map[product.CategoryID]map[product.Locale]product.CategoryName),
+	}
+}
+
+// FindByCategory finds the translations of a category
+func (r *CategoryTranslationRepository) FindByCategory(ctx context.Context, categoryID product.CategoryID) (*product.CategoryTranslations, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	names, exists := r.names[categoryID]
+	if !exists {
+		return nil, product.ErrTranslationNotFound
+	}
+	return product.NewCategoryTranslations(categoryID, names)
+}
+
+// Save persists the translations of a category, replacing the previous ones; a category without translations is removed
+func (r *CategoryTranslationRepository) Save(ctx context.Context, translations *product.CategoryTranslations) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	names := translations.Names()
+	if len(names) == 0 {
+		delete(r.names, translations.CategoryID())
+		return nil
+	}
+	r.names[translations.CategoryID()] = names
+	return nil
+}
@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// MovementRepository is an in-memory implementation of the product.MovementRepository interface
+type MovementRepository struct {
+	movements map[product.ProductID][]product.Movement
+	mutex     sync.RWMutex
+}
+
+// NewMovementRepository creates a new in-memory stock ledger
+func NewMovementRepository() *MovementRepository {
+	return &MovementRepository{
+		movements: make(map[product.ProductID][]product.Movement),
+	}
+}
+
+// Append adds movements to the ledger
+func (r *MovementRepository) Append(ctx context.Context, movements ...product.Movement) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, m := range movements {
+		r.movements[m.ProductID()] = append(r.movements[m.ProductID()], m)
+	}
+	return nil
+}
+
+// FindByProduct returns the movements of a product, oldest first
+func (r *MovementRepository) FindByProduct(ctx context.Context, productID product.ProductID) ([]product.Movement, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return append([]product.Movement(nil), r.movements[productID]...), nil
+}
+
+// Balances returns the sum of the movements of every product per warehouse
+func (r *MovementRepository) Balances(ctx context.Context) (map[product.ProductID]map[product.WarehouseID]int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	balances := make(map[product.ProductID]map[product.WarehouseID]int, len(r.movements))
+	for productID, movements := range r.movements {
+		balances[productID] = make(map[product.WarehouseID]int)
+		for _, m := range movements {
+			balances[productID][m.WarehouseID()] += m.Quantity()
+		}
+	}
+	return balances, nil
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// attributeSchemaRow is a row of the attribute_schemas table
+type attributeSchemaRow struct {
+	CategoryID string `gorm:"column:category_id;primaryKey"`
+	// Definitions is the JSONB array of attributeDefinitionRecord
+	Definitions string `gorm:"column:definitions;type:jsonb"`
+}
+
+func (attributeSchemaRow) TableName() string { return "attribute_schemas" }
+
+// attributeDefinitionRecord is an attribute definition in the definitions column
+type attributeDefinitionRecord struct {
+	Key      string   `json:"key"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// AttributeSchemaRepository is a PostgreSQL implementation of the product.AttributeSchemaRepository interface.
+// A schema can be defined before any product of its category is saved, so it does not reference the categories table.
+type AttributeSchemaRepository struct {
+	db *gorm.DB
+}
+
+// NewAttributeSchemaRepository creates a new PostgreSQL attribute schema repository
+func NewAttributeSchemaRepository(db *gorm.DB) *AttributeSchemaRepository {
+	return &AttributeSchemaRepository{db: db}
+}
+
+// FindByCategory finds the attribute schema of a category
+func (r *AttributeSchemaRepository) FindByCategory(ctx context.Context, categoryID product.CategoryID) (*product.AttributeSchema, error) {
+	var row attributeSchemaRow
+	err := r.db.WithContext(ctx).Where("category_id = ?", categoryID.String()).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, product.ErrAttributeSchemaNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []attributeDefinitionRecord
+	if err := json.Unmarshal([]byte(row.Definitions), &records); err != nil {
+		return nil, err
+	}
+	definitions := make([]product.AttributeDefinition, 0, len(records))
+	for _, rec := range records {
+		// The definitions are restored as saved, so the stored attribute values of the products keep matching them
+		definitions = append(definitions, product.RestoreAttributeDefinition(rec.Key, product.AttributeType(rec.Type), rec.Required, rec.Values))
+	}
+	return product.NewAttributeSchema(product.CategoryID(row.CategoryID), definitions)
+}
+
+// Save persists an attribute schema, replacing the previous schema of the category
+func (r *AttributeSchemaRepository) Save(ctx context.Context, schema *product.AttributeSchema) error {
+	records := make([]attributeDefinitionRecord, 0, len(schema.Definitions()))
+	for _, d := range schema.Definitions() {
+		records = append(records, attributeDefinitionRecord{Key: d.Key(), Type: d.Type().String(), Required: d.Required(), Values: d.Values()})
+	}
+	definitions, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	row := attributeSchemaRow{CategoryID: schema.CategoryID().String(), Definitions: string(definitions)}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"definitions"}),
+	}).Create(&row).Error
+}
+
+// Delete removes the attribute schema of a category
+func (r *AttributeSchemaRepository) Delete(ctx context.Context, categoryID product.CategoryID) error {
+	result := r.db.WithContext(ctx).Where("category_id = ?", categoryID.String()).Delete(&attributeSchemaRow{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return product.ErrAttributeSchemaNotFound
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// categoryTranslationRow is a row of the category_translations table, the name of a category in one locale
+type categoryTranslationRow struct {
+	CategoryID string `gorm:"column:category_id;primaryKey"`
+	Locale     string `gorm:"column:locale;primaryKey"`
+	Name       string `gorm:"column:name"`
+}
+
+func (categoryTranslationRow) TableName() string { return "category_translations" }
+
+// CategoryTranslationRepository is a PostgreSQL implementation of the product.CategoryTranslationRepository interface.
+// A category can be translated before any product of it is saved, so the rows do not reference the categories table.
+type CategoryTranslationRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryTranslationRepository creates a new PostgreSQL category translation repository
+func NewCategoryTranslationRepository(db *gorm.DB) *CategoryTranslationRepository {
+	return &CategoryTranslationRepository{db: db}
+}
+
+// FindByCategory finds the translations of a category
+func (r *CategoryTranslationRepository) FindByCategory(ctx context.Context, categoryID product.CategoryID) (*product.CategoryTranslations, error) {
+	var rows []categoryTranslationRow
+	if err := r.db.WithContext(ctx).Where("category_id = ?", categoryID.String()).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, product.ErrTranslationNotFound
+	}
+
+	names := make(map[product.Locale]product.CategoryName, len(rows))
+	for _, row := range rows {
+		locale, err := product.NewLocale(row.Locale)
+		if err != nil {
+			return nil, err
+		}
+		// The names are restored as stored, as the product texts are
+		names[locale] = product.CategoryName(row.Name)
+	}
+	return product.NewCategoryTranslations(categoryID, names)
+}
+
+// Save persists the translations of a category, replacing the previous ones
+func (r *CategoryTranslationRepository) Save(ctx context.Context, translations *product.CategoryTranslations) error {
+	categoryID := translations.CategoryID().String()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("category_id = ?", categoryID).Delete(&categoryTranslationRow{}).Error; err != nil {
+			return err
+		}
+		names := translations.Names()
+		if len(names) == 0 {
+			return nil
+		}
+		rows := make([]categoryTranslationRow, 0, len(names))
+		for locale, name := range names {
+			rows = append(rows, categoryTranslationRow{CategoryID: categoryID, Locale: locale.String(), Name: name.String()})
+		}
+		return tx.Create(&rows).Error
+	})
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	pgdriver "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// PoolOptions configures the connection pool; zero values keep the database/sql defaults
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// Open connects to the PostgreSQL database at dsn
+func Open(dsn string, pool PoolOptions) (*gorm.DB, error) {
+	db, err := gorm.Open(pgdriver.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if pool.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	}
+	if pool.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	if pool.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	}
+	return db, nil
+}
+
+// Close closes the connections of db
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+type transactionKey struct{}
+
+// Transactor runs the changes of the product service in one database transaction
+type Transactor struct {
+	db      *gorm.DB
+	options *sql.TxOptions
+}
+
+// NewTransactor creates a new Transactor; give it to the product service with SetTransactor
+func NewTransactor(db *gorm.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// NewSnapshotTransactor creates a Transactor running read-only, repeatable read transactions:
+// every read of a transaction sees the database as it was when the transaction started
+func NewSnapshotTransactor(db *gorm.DB) *Transactor {
+	return &Transactor{db: db, options: &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}}
+}
+
+// Transaction implements product.Transactor
+func (t *Transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, transactionKey{}, tx))
+	}, t.options)
+}
+
+// Conn returns the transaction of a Transactor ctx belongs to, or db outside of a transaction.
+// Repositories of other features use it to join the transactions of the product service.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(transactionKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// ConnForUpdate returns Conn(ctx, db), locking the rows it reads until the transaction ctx belongs to ends.
+// Outside of a transaction nothing is locked.
+func ConnForUpdate(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if _, ok := ctx.Value(transactionKey{}).(*gorm.DB); ok {
+		return Conn(ctx, db).Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	return Conn(ctx, db)
+}
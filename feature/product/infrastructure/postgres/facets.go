@@ -0,0 +1,232 @@
+package postgres
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	product "sago-sample/feature/product/domain"
+	"sago-sample/internal/textnorm"
+)
+
+// Facets whose own filter is left out while they are counted
+const (
+	facetCategory  = "category"
+	facetPrice     = "price"
+	facetStock     = "stock"
+	facetAttribute = "attr."
+)
+
+// facetRow is a value of a facet with its number of products
+type facetRow struct {
+	Key   string
+	Value string
+	Name  string
+	Count int
+}
+
+// Facets counts the products per facet with one aggregate query per facet, plus one per attribute filter.
+// Each facet is counted with every filter of the query except its own, as product.CountFacets does.
+func (r *ProductRepository) Facets(ctx context.Context, query product.FacetQuery) (*product.Facets, error) {
+	products := func(except string) *gorm.DB {
+		return r.db.WithContext(ctx).Table("products AS p").Scopes(facetScope(query, except))
+	}
+	facets := &product.Facets{}
+
+	var total int64
+	if err := products("").Count(&total).Error; err != nil {
+		return nil, err
+	}
+	facets.Total = int(total)
+
+	var categories []facetRow
+	err := products(facetCategory).
+		Select("c.id AS value, c.name AS name, COUNT(*) AS count").
+		Joins("JOIN product_categories AS pc ON pc.product_id = p.id").
+		Joins("JOIN categories AS c ON c.id = pc.category_id").
+		Group("c.id, c.name").
+		Order(`c.id COLLATE "C"`).
+		Scan(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range categories {
+		facets.Categories = append(facets.Categories, product.CategoryCount{ID: product.CategoryID(c.Value), Name: product.CategoryName(c.Name), Count: c.Count})
+	}
+
+	if facets.Prices, err = priceFacet(products(facetPrice), query.PriceBuckets); err != nil {
+		return nil, err
+	}
+
+	var stock struct {
+		InStock    int
+		OutOfStock int
+	}
+	err = products(facetStock).
+		Select("COUNT(*) FILTER (WHERE p.stock_quantity > 0) AS in_stock, COUNT(*) FILTER (WHERE p.stock_quantity = 0) AS out_of_stock").
+		Scan(&stock).Error
+	if err != nil {
+		return nil, err
+	}
+	facets.Stock = product.StockCount{InStock: stock.InStock, OutOfStock: stock.OutOfStock}
+
+	// The attributes without a filter are counted together; each filtered attribute is counted without its own filter
+	filtered := make([]string, 0, len(query.Attributes))
+	for key := range query.Attributes {
+		filtered = append(filtered, key)
+	}
+	unfiltered := products("")
+	if len(filtered) > 0 {
+		unfiltered = unfiltered.Where("a.key NOT IN ?", filtered)
+	}
+	rows, err := attributeFacet(unfiltered)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range filtered {
+		keyRows, err := attributeFacet(products(facetAttribute+key).Where("a.key = ?", key))
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, keyRows...)
+	}
+	facets.Attributes = attributeCounts(rows)
+
+	return facets, nil
+}
+
+// priceFacet counts the products of db per price bucket
+func priceFacet(db *gorm.DB, buckets []product.PriceBucket) ([]product.PriceCount, error) {
+	counts := make([]product.PriceCount, len(buckets))
+	if len(buckets) == 0 {
+		return counts, nil
+	}
+
+	var expr strings.Builder
+	var args []any
+	expr.WriteString("CASE")
+	for i, b := range buckets {
+		counts[i].Bucket = b
+		if b.To == 0 {
+			expr.WriteString(" WHEN p.price_amount >= ? THEN " + strconv.Itoa(i))
+			args = append(args, b.From)
+		} else {
+			expr.WriteString(" WHEN p.price_amount >= ? AND p.price_amount < ? THEN " + strconv.Itoa(i))
+			args = append(args, b.From, b.To)
+		}
+	}
+	expr.WriteString(" END AS bucket, COUNT(*) AS count")
+
+	var rows []struct {
+		Bucket *int
+		Count  int
+	}
+	if err := db.Select(expr.String(), args...).Group("bucket").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if row.Bucket != nil {
+			counts[*row.Bucket].Count = row.Count
+		}
+	}
+	return counts, nil
+}
+
+// attributeFacet counts the products of db per attribute key and value
+func attributeFacet(db *gorm.DB) ([]facetRow, error) {
+	var rows []facetRow
+	err := db.
+		Select("a.key AS key, a.value AS value, COUNT(*) AS count").
+		Joins("CROSS JOIN LATERAL jsonb_each_text(p.attributes) AS a").
+		Group("a.key, a.value").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// attributeCounts groups the attribute rows by key, ordering keys and values as product.CountFacets does
+func attributeCounts(rows []facetRow) []product.AttributeCount {
+	byKey := make(map[string]*product.AttributeCount)
+	var keys []string
+	for _, row := range rows {
+		if byKey[row.Key] == nil {
+			byKey[row.Key] = &product.AttributeCount{Key: row.Key}
+			keys = append(keys, row.Key)
+		}
+		byKey[row.Key].Values = append(byKey[row.Key].Values, product.AttributeValueCount{Value: row.Value, Count: row.Count})
+	}
+	sort.Strings(keys)
+
+	var counts []product.AttributeCount
+	for _, key := range keys {
+		c := byKey[key]
+		sort.Slice(c.Values, func(i, j int) bool { return c.Values[i].Value < c.Values[j].Value })
+		counts = append(counts, *c)
+	}
+	return counts
+}
+
+// facetScope restricts the products aliased p to the query, leaving out the filter of the facet except
+func facetScope(query product.FacetQuery, except string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query.Status != "" {
+			db = db.Where("p.status = ?", query.Status.String())
+		}
+		if !query.AvailableAt.IsZero() {
+			at := query.AvailableAt.UTC()
+			db = db.Where("(p.available_from IS NULL OR p.available_from <= ?) AND (p.available_until IS NULL OR p.available_until > ?)", at, at)
+		}
+		if query.Currency != "" {
+			db = db.Where("p.price_currency = ?", query.Currency)
+		}
+
+		if except != facetCategory && !query.CategoryID.IsEmpty() {
+			db = db.Where("EXISTS (SELECT 1 FROM product_categories AS fc WHERE fc.product_id = p.id AND fc.category_id = ?)", query.CategoryID.String())
+		}
+		if except != facetPrice {
+			if query.MinPrice > 0 {
+				db = db.Where("p.price_amount >= ?", query.MinPrice)
+			}
+			if query.MaxPrice > 0 {
+				db = db.Where("p.price_amount <= ?", query.MaxPrice)
+			}
+		}
+		if except != facetStock && query.InStock != nil {
+			if *query.InStock {
+				db = db.Where("p.stock_quantity > 0")
+			} else {
+				db = db.Where("p.stock_quantity = 0")
+			}
+		}
+		for key, value := range query.Attributes {
+			if except != facetAttribute+key {
+				db = attributeMatches(db, key, value)
+			}
+		}
+		return db
+	}
+}
+
+// attributeMatches keeps the products whose attribute key has value, comparing as product.Attributes.Matches does:
+// strings once normalized, numbers numerically and booleans in any form strconv.ParseBool accepts
+func attributeMatches(db *gorm.DB, key, value string) *gorm.DB {
+	normalized, err := textnorm.Line(value)
+	if err != nil {
+		// Nor is such a value a number or a boolean
+		return db.Where("false")
+	}
+	conditions := []string{"p.attributes -> ? = to_jsonb(?::text)"}
+	args := []any{key, normalized}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		// CASE keeps the cast from running on values that are not numbers
+		conditions = append(conditions, "CASE WHEN jsonb_typeof(p.attributes -> ?) = 'number' THEN (p.attributes ->> ?)::numeric = ? ELSE false END")
+		args = append(args, key, key, f)
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		conditions = append(conditions, "p.attributes -> ? = ?::jsonb")
+		args = append(args, key, strconv.FormatBool(b))
+	}
+	return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// movementRow is a row of the stock_movements table; seq keeps the order in which movements were appended
+type movementRow struct {
+	Seq         int64     `gorm:"column:seq;primaryKey;autoIncrement"`
+	ID          string    `gorm:"column:id"`
+	ProductID   string    `gorm:"column:product_id"`
+	WarehouseID string    `gorm:"column:warehouse_id"`
+	Type        string    `gorm:"column:type"`
+	Quantity    int       `gorm:"column:quantity"`
+	Reason      string    `gorm:"column:reason"`
+	Actor       string    `gorm:"column:actor"`
+	OccurredAt  time.Time `gorm:"column:occurred_at"`
+}
+
+func (movementRow) TableName() string { return "stock_movements" }
+
+// MovementRepository is a PostgreSQL implementation of the product.MovementRepository interface.
+// The table refuses updates and deletes, see /migrations.
+type MovementRepository struct {
+	db *gorm.DB
+}
+
+// NewMovementRepository creates a new PostgreSQL stock ledger
+func NewMovementRepository(db *gorm.DB) *MovementRepository {
+	return &MovementRepository{db: db}
+}
+
+// Append adds movements to the ledger in a single statement
+func (r *MovementRepository) Append(ctx context.Context, movements ...product.Movement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+
+	rows := make([]movementRow, 0, len(movements))
+	for _, m := range movements {
+		rows = append(rows, movementRow{
+			ID:          m.ID(),
+			ProductID:   m.ProductID().String(),
+			WarehouseID: m.WarehouseID().String(),
+			Type:        m.Type().String(),
+			Quantity:    m.Quantity(),
+			Reason:      m.Reason(),
+			Actor:       m.Actor(),
+			OccurredAt:  m.OccurredAt().UTC(),
+		})
+	}
+	return Conn(ctx, r.db).Create(&rows).Error
+}
+
+// Balances returns the sum of the movements of every product per warehouse, in a single query
+func (r *MovementRepository) Balances(ctx context.Context) (map[product.ProductID]map[product.WarehouseID]int, error) {
+	var rows []struct {
+		ProductID   string
+		WarehouseID string
+		Balance     int
+	}
+	err := Conn(ctx, r.db).Model(&movementRow{}).
+		Select("product_id, warehouse_id, SUM(quantity) AS balance").
+		Group("product_id, warehouse_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[product.ProductID]map[product.WarehouseID]int)
+	for _, row := range rows {
+		productID := product.ProductID(row.ProductID)
+		if balances[productID] == nil {
+			balances[productID] = make(map[product.WarehouseID]int)
+		}
+		balances[productID][product.WarehouseID(row.WarehouseID)] = row.Balance
+	}
+	return balances, nil
+}
+
+// FindByProduct returns the movements of a product, oldest first
+func (r *MovementRepository) FindByProduct(ctx context.Context, productID product.ProductID) ([]product.Movement, error) {
+	var rows []movementRow
+	if err := Conn(ctx, r.db).Where("product_id = ?", productID.String()).Order("seq").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	movements := make([]product.Movement, 0, len(rows))
+	for _, row := range rows {
+		movementType, err := product.NewMovementType(row.Type)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, product.RestoreMovement(
+			row.ID,
+			product.ProductID(row.ProductID),
+			product.WarehouseID(row.WarehouseID),
+			movementType,
+			row.Quantity,
+			row.Reason,
+			row.Actor,
+			row.OccurredAt,
+		))
+	}
+	return movements, nil
+}
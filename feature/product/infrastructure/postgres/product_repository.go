@@ -0,0 +1,420 @@
+// Package postgres stores products in PostgreSQL through gorm, using the schema in /migrations
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// productRow is a row of the products table
+type productRow struct {
+	ID            string `gorm:"column:id;primaryKey"`
+	Name          string `gorm:"column:name"`
+	Description   string `gorm:"column:description"`
+	PriceAmount   uint   `gorm:"column:price_amount"`
+	PriceCurrency string `gorm:"column:price_currency"`
+	Status        string `gorm:"column:status"`
+	// AvailableFrom and AvailableUntil are NULL for an open bound
+	AvailableFrom  *time.Time `gorm:"column:available_from"`
+	AvailableUntil *time.Time `gorm:"column:available_until"`
+	// Attributes is the JSONB object of the attribute values
+	Attributes    string    `gorm:"column:attributes;type:jsonb"`
+	StockQuantity uint      `gorm:"column:stock_quantity"`
+	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime:false"`
+	UpdatedAt     time.Time `gorm:"column:updated_at;autoUpdateTime:false"`
+}
+
+func (productRow) TableName() string { return "products" }
+
+// categoryRow is a row of the categories table
+type categoryRow struct {
+	ID   string `gorm:"column:id;primaryKey"`
+	Name string `gorm:"column:name"`
+}
+
+func (categoryRow) TableName() string { return "categories" }
+
+// productCategoryRow is a row of the product_categories junction table
+type productCategoryRow struct {
+	ProductID  string `gorm:"column:product_id;primaryKey"`
+	CategoryID string `gorm:"column:category_id;primaryKey"`
+}
+
+func (productCategoryRow) TableName() string { return "product_categories" }
+
+// productStockRow is a row of the product_stock table, the stock of a product in one warehouse
+type productStockRow struct {
+	ProductID   string `gorm:"column:product_id;primaryKey"`
+	WarehouseID string `gorm:"column:warehouse_id;primaryKey"`
+	Quantity    uint   `gorm:"column:quantity"`
+}
+
+func (productStockRow) TableName() string { return "product_stock" }
+
+// productImageRow is a row of the product_images table; position orders the images of a product, the primary one first
+type productImageRow struct {
+	ProductID    string    `gorm:"column:product_id;primaryKey"`
+	ID           string    `gorm:"column:id;primaryKey"`
+	Position     int       `gorm:"column:position"`
+	Key          string    `gorm:"column:key"`
+	ThumbnailKey string    `gorm:"column:thumbnail_key"`
+	ContentType  string    `gorm:"column:content_type"`
+	Size         int64     `gorm:"column:size"`
+	Width        int       `gorm:"column:width"`
+	Height       int       `gorm:"column:height"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime:false"`
+}
+
+func (productImageRow) TableName() string { return "product_images" }
+
+// productTranslationRow is a row of the product_translations table, the texts of a product in one locale
+type productTranslationRow struct {
+	ProductID   string `gorm:"column:product_id;primaryKey"`
+	Locale      string `gorm:"column:locale;primaryKey"`
+	Name        string `gorm:"column:name"`
+	Description string `gorm:"column:description"`
+}
+
+func (productTranslationRow) TableName() string { return "product_translations" }
+
+// productCategory is a category joined with the product it belongs to
+type productCategory struct {
+	ProductID string
+	ID        string
+	Name      string
+}
+
+// ProductRepository is a PostgreSQL implementation of the product.Repository interface
+type ProductRepository struct {
+	db *gorm.DB
+}
+
+// NewProductRepository creates a new PostgreSQL product repository
+func NewProductRepository(db *gorm.DB) *ProductRepository {
+	return &ProductRepository{db: db}
+}
+
+// FindByID finds a product by its ID.
+// Inside a transaction the product row stays locked until it ends, so that other instances wait for the change instead of losing it.
+func (r *ProductRepository) FindByID(ctx context.Context, id product.ProductID) (*product.Product, error) {
+	var row productRow
+	err := ConnForUpdate(ctx, r.db).Where("id = ?", id.String()).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, product.ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := r.restore(ctx, []productRow{row})
+	if err != nil {
+		return nil, err
+	}
+	return products[0], nil
+}
+
+// FindAll returns all products
+func (r *ProductRepository) FindAll(ctx context.Context) ([]*product.Product, error) {
+	var rows []productRow
+	if err := Conn(ctx, r.db).Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return r.restore(ctx, rows)
+}
+
+// FindByCategory finds products by category ID
+func (r *ProductRepository) FindByCategory(ctx context.Context, categoryID product.CategoryID) ([]*product.Product, error) {
+	var rows []productRow
+	err := Conn(ctx, r.db).
+		Where("id IN (?)", r.db.Model(&productCategoryRow{}).Select("product_id").Where("category_id = ?", categoryID.String())).
+		Order("id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.restore(ctx, rows)
+}
+
+// Save persists a product with its stock levels, images, translations and categories.
+// stock_quantity keeps the total across warehouses.
+func (r *ProductRepository) Save(ctx context.Context, p *product.Product) error {
+	attributes, err := json.Marshal(p.Attributes())
+	if err != nil {
+		return err
+	}
+	row := productRow{
+		ID:             p.ID().String(),
+		Name:           p.Name().String(),
+		Description:    p.Description().String(),
+		PriceAmount:    p.Price().Amount(),
+		PriceCurrency:  p.Price().Currency(),
+		Status:         p.Status().String(),
+		AvailableFrom:  nullableTime(p.Availability().From()),
+		AvailableUntil: nullableTime(p.Availability().Until()),
+		Attributes:     string(attributes),
+		StockQuantity:  p.Stock().Quantity(),
+		CreatedAt:      p.CreatedAt().UTC(),
+		UpdatedAt:      p.UpdatedAt().UTC(),
+	}
+
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "description", "price_amount", "price_currency", "status", "available_from", "available_until", "attributes", "stock_quantity", "updated_at"}),
+		}).Create(&row).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("product_id = ?", row.ID).Delete(&productStockRow{}).Error; err != nil {
+			return err
+		}
+		if levels := p.StockLevels(); len(levels) > 0 {
+			stock := make([]productStockRow, 0, len(levels))
+			for _, l := range levels {
+				stock = append(stock, productStockRow{ProductID: row.ID, WarehouseID: l.WarehouseID().String(), Quantity: l.Quantity()})
+			}
+			if err := tx.Create(&stock).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("product_id = ?", row.ID).Delete(&productImageRow{}).Error; err != nil {
+			return err
+		}
+		if images := p.Images(); len(images) > 0 {
+			imageRows := make([]productImageRow, 0, len(images))
+			for i, img := range images {
+				imageRows = append(imageRows, productImageRow{
+					ProductID:    row.ID,
+					ID:           img.ID().String(),
+					Position:     i,
+					Key:          img.Key(),
+					ThumbnailKey: img.ThumbnailKey(),
+					ContentType:  img.ContentType(),
+					Size:         img.Size(),
+					Width:        img.Width(),
+					Height:       img.Height(),
+					CreatedAt:    img.CreatedAt().UTC(),
+				})
+			}
+			if err := tx.Create(&imageRows).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("product_id = ?", row.ID).Delete(&productTranslationRow{}).Error; err != nil {
+			return err
+		}
+		if translations := p.Translations(); len(translations) > 0 {
+			translationRows := make([]productTranslationRow, 0, len(translations))
+			for locale, t := range translations {
+				translationRows = append(translationRows, productTranslationRow{
+					ProductID:   row.ID,
+					Locale:      locale.String(),
+					Name:        t.Name().String(),
+					Description: t.Description().String(),
+				})
+			}
+			if err := tx.Create(&translationRows).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("product_id = ?", row.ID).Delete(&productCategoryRow{}).Error; err != nil {
+			return err
+		}
+		if len(p.Categories()) == 0 {
+			return nil
+		}
+
+		categories := make([]categoryRow, 0, len(p.Categories()))
+		links := make([]productCategoryRow, 0, len(p.Categories()))
+		for _, c := range p.Categories() {
+			categories = append(categories, categoryRow{ID: c.ID().String(), Name: c.Name().String()})
+			links = append(links, productCategoryRow{ProductID: row.ID, CategoryID: c.ID().String()})
+		}
+
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name"}),
+		}).Create(&categories).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+// Delete removes a product; its stock levels, images, translations and category links are removed by the foreign key cascade
+func (r *ProductRepository) Delete(ctx context.Context, id product.ProductID) error {
+	result := Conn(ctx, r.db).Where("id = ?", id.String()).Delete(&productRow{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return product.ErrProductNotFound
+	}
+	return nil
+}
+
+// Ping checks that the database is reachable
+func (r *ProductRepository) Ping(ctx context.Context) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// restore rebuilds the domain products of rows, loading their stock levels, images, translations and categories with one query each
+func (r *ProductRepository) restore(ctx context.Context, rows []productRow) ([]*product.Product, error) {
+	products := make([]*product.Product, 0, len(rows))
+	if len(rows) == 0 {
+		return products, nil
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+
+	var joined []productCategory
+	err := Conn(ctx, r.db).
+		Table("product_categories AS pc").
+		Select("pc.product_id, c.id, c.name").
+		Joins("JOIN categories AS c ON c.id = pc.category_id").
+		Where("pc.product_id IN ?", ids).
+		Order("c.id").
+		Scan(&joined).Error
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[string][]*product.Category, len(rows))
+	for _, j := range joined {
+		c, err := restoreCategory(j.ID, j.Name)
+		if err != nil {
+			return nil, err
+		}
+		categories[j.ProductID] = append(categories[j.ProductID], c)
+	}
+
+	var stockRows []productStockRow
+	if err := Conn(ctx, r.db).Where("product_id IN ?", ids).Order("warehouse_id").Find(&stockRows).Error; err != nil {
+		return nil, err
+	}
+	levels := make(map[string][]product.StockLevel, len(rows))
+	for _, s := range stockRows {
+		warehouseID, err := product.NewWarehouseID(s.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		levels[s.ProductID] = append(levels[s.ProductID], product.NewStockLevel(warehouseID, s.Quantity))
+	}
+
+	var imageRows []productImageRow
+	if err := Conn(ctx, r.db).Where("product_id IN ?", ids).Order("product_id, position").Find(&imageRows).Error; err != nil {
+		return nil, err
+	}
+	images := make(map[string][]product.Image, len(rows))
+	for _, i := range imageRows {
+		images[i.ProductID] = append(images[i.ProductID], product.RestoreImage(product.ImageID(i.ID), i.Key, i.ThumbnailKey, i.ContentType, i.Size, i.Width, i.Height, i.CreatedAt))
+	}
+
+	var translationRows []productTranslationRow
+	if err := Conn(ctx, r.db).Where("product_id IN ?", ids).Find(&translationRows).Error; err != nil {
+		return nil, err
+	}
+	translations := make(map[string]map[product.Locale]product.ProductTranslation, len(rows))
+	for _, t := range translationRows {
+		locale, err := product.NewLocale(t.Locale)
+		if err != nil {
+			return nil, err
+		}
+		translation, err := restoreTranslation(t.Name, t.Description)
+		if err != nil {
+			return nil, err
+		}
+		if translations[t.ProductID] == nil {
+			translations[t.ProductID] = make(map[product.Locale]product.ProductTranslation)
+		}
+		translations[t.ProductID][locale] = translation
+	}
+
+	for _, row := range rows {
+		p, err := restoreProduct(row, images[row.ID], translations[row.ID], levels[row.ID], categories[row.ID])
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, nil
+}
+
+// restoreProduct rebuilds a product from its row; every value is validated again but the free texts, which are restored
+// as stored: they were validated when saved, and rows saved before the texts were normalized must still load
+func restoreProduct(row productRow, images []product.Image, translations map[product.Locale]product.ProductTranslation, levels []product.StockLevel, categories []*product.Category) (*product.Product, error) {
+	id, err := product.NewProductID(row.ID)
+	if err != nil {
+		return nil, err
+	}
+	name := product.ProductName(row.Name)
+	description := product.ProductDescription(row.Description)
+	price, err := product.NewPrice(row.PriceAmount, row.PriceCurrency)
+	if err != nil {
+		return nil, err
+	}
+	status, err := product.NewProductStatus(row.Status)
+	if err != nil {
+		return nil, err
+	}
+	var from, until time.Time
+	if row.AvailableFrom != nil {
+		from = *row.AvailableFrom
+	}
+	if row.AvailableUntil != nil {
+		until = *row.AvailableUntil
+	}
+	availability, err := product.NewAvailability(from, until)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]any
+	if row.Attributes != "" {
+		if err := json.Unmarshal([]byte(row.Attributes), &values); err != nil {
+			return nil, err
+		}
+	}
+	attributes := product.Attributes(values)
+	return product.RestoreProduct(id, name, description, price, status, availability, attributes, images, translations, levels, categories, row.CreatedAt, row.UpdatedAt), nil
+}
+
+// nullableTime maps the zero time to NULL
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// restoreTranslation rebuilds the translation of a product, restoring its texts as stored
+func restoreTranslation(rawName, rawDescription string) (product.ProductTranslation, error) {
+	return product.NewProductTranslation(product.ProductName(rawName), product.ProductDescription(rawDescription))
+}
+
+func restoreCategory(rawID, rawName string) (*product.Category, error) {
+	id, err := product.NewCategoryID(rawID)
+	if err != nil {
+		return nil, err
+	}
+	return product.NewCategory(id, product.CategoryName(rawName))
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// reorderPolicyRow is a row of the reorder_policies table
+type reorderPolicyRow struct {
+	ProductID string `gorm:"column:product_id;primaryKey"`
+	Threshold uint   `gorm:"column:threshold"`
+	Target    uint   `gorm:"column:target"`
+}
+
+func (reorderPolicyRow) TableName() string { return "reorder_policies" }
+
+// ReorderPolicyRepository is a PostgreSQL implementation of the product.ReorderPolicyRepository interface.
+// Policies are deleted along with their product by the foreign key.
+type ReorderPolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewReorderPolicyRepository creates a new PostgreSQL reorder policy repository
+func NewReorderPolicyRepository(db *gorm.DB) *ReorderPolicyRepository {
+	return &ReorderPolicyRepository{db: db}
+}
+
+// FindByProduct finds the reorder policy of a product
+func (r *ReorderPolicyRepository) FindByProduct(ctx context.Context, productID product.ProductID) (*product.ReorderPolicy, error) {
+	var row reorderPolicyRow
+	err := r.db.WithContext(ctx).Where("product_id = ?", productID.String()).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, product.ErrReorderPolicyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return product.NewReorderPolicy(product.ProductID(row.ProductID), row.Threshold, row.Target)
+}
+
+// FindAll returns all reorder policies ordered by product ID
+func (r *ReorderPolicyRepository) FindAll(ctx context.Context) ([]*product.ReorderPolicy, error) {
+	var rows []reorderPolicyRow
+	if err := r.db.WithContext(ctx).Order("product_id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	policies := make([]*product.ReorderPolicy, 0, len(rows))
+	for _, row := range rows {
+		policy, err := product.NewReorderPolicy(product.ProductID(row.ProductID), row.Threshold, row.Target)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// Save persists a reorder policy, replacing the previous policy of the product
+func (r *ReorderPolicyRepository) Save(ctx context.Context, policy *product.ReorderPolicy) error {
+	row := reorderPolicyRow{
+		ProductID: policy.ProductID().String(),
+		Threshold: policy.Threshold(),
+		Target:    policy.Target(),
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"threshold", "target"}),
+	}).Create(&row).Error
+}
+
+// Delete removes the reorder policy of a product
+func (r *ReorderPolicyRepository) Delete(ctx context.Context, productID product.ProductID) error {
+	result := r.db.WithContext(ctx).Where("product_id = ?", productID.String()).Delete(&reorderPolicyRow{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return product.ErrReorderPolicyNotFound
+	}
+	return nil
+}
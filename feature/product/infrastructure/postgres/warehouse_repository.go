@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// warehouseRow is a row of the warehouses table; the location is stored when both coordinates are set
+type warehouseRow struct {
+	ID        string   `gorm:"column:id;primaryKey"`
+	Name      string   `gorm:"column:name"`
+	Latitude  *float64 `gorm:"column:latitude"`
+	Longitude *float64 `gorm:"column:longitude"`
+}
+
+func (warehouseRow) TableName() string { return "warehouses" }
+
+// foreignKeyViolation is the PostgreSQL error code of a statement breaking a foreign key
+const foreignKeyViolation = "23503"
+
+// WarehouseRepository is a PostgreSQL implementation of the product.WarehouseRepository interface
+type WarehouseRepository struct {
+	db *gorm.DB
+}
+
+// NewWarehouseRepository creates a new PostgreSQL warehouse repository
+func NewWarehouseRepository(db *gorm.DB) *WarehouseRepository {
+	return &WarehouseRepository{db: db}
+}
+
+// FindByID finds a warehouse by its ID
+func (r *WarehouseRepository) FindByID(ctx context.Context, id product.WarehouseID) (*product.Warehouse, error) {
+	var row warehouseRow
+	err := Conn(ctx, r.db).Where("id = ?", id.String()).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, product.ErrWarehouseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return restoreWarehouse(row)
+}
+
+// FindAll returns all warehouses ordered by ID
+func (r *WarehouseRepository) FindAll(ctx context.Context) ([]*product.Warehouse, error) {
+	var rows []warehouseRow
+	if err := r.db.WithContext(ctx).Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	warehouses := make([]*product.Warehouse, 0, len(rows))
+	for _, row := range rows {
+		w, err := restoreWarehouse(row)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, w)
+	}
+	return warehouses, nil
+}
+
+// Save persists a warehouse
+func (r *WarehouseRepository) Save(ctx context.Context, w *product.Warehouse) error {
+	row := warehouseRow{ID: w.ID().String(), Name: w.Name().String()}
+	if loc := w.Location(); loc != nil {
+		latitude, longitude := loc.Latitude(), loc.Longitude()
+		row.Latitude, row.Longitude = &latitude, &longitude
+	}
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "latitude", "longitude"}),
+	}).Create(&row).Error
+}
+
+// Delete removes a warehouse; the foreign key of product_stock refuses warehouses still holding stock with product.ErrWarehouseInUse
+func (r *WarehouseRepository) Delete(ctx context.Context, id product.WarehouseID) error {
+	result := Conn(ctx, r.db).Where("id = ?", id.String()).Delete(&warehouseRow{})
+	var pgErr *pgconn.PgError
+	if errors.As(result.Error, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return product.ErrWarehouseInUse
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return product.ErrWarehouseNotFound
+	}
+	return nil
+}
+
+// restoreWarehouse rebuilds a warehouse from its row; every value is validated again but the name, restored as stored
+func restoreWarehouse(row warehouseRow) (*product.Warehouse, error) {
+	id, err := product.NewWarehouseID(row.ID)
+	if err != nil {
+		return nil, err
+	}
+	name := product.WarehouseName(row.Name)
+
+	var location *product.Location
+	if row.Latitude != nil && row.Longitude != nil {
+		loc, err := product.NewLocation(*row.Latitude, *row.Longitude)
+		if err != nil {
+			return nil, err
+		}
+		location = &loc
+	}
+	return product.NewWarehouse(id, name, location)
+}
@@ -82,3 +82,15 @@ func (r *ProductRepository) FindByCategory(ctx context.Context, categoryID produ
 
 	return result, nil
 }
+
+// Facets counts the products per facet in a single pass
+func (r *ProductRepository) Facets(ctx context.Context, query product.FacetQuery) (*product.Facets, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	products := make([]*product.Product, 0, len(r.products))
+	for _, p := range r.products {
+		products = append(products, p)
+	}
+	return product.CountFacets(products, query), nil
+}
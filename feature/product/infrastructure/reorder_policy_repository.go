@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// ReorderPolicyRepository is an in-memory implementation of the product.ReorderPolicyRepository interface
+type ReorderPolicyRepository struct {
+	policies map[product.ProductID]*product.ReorderPolicy
+	mutex    sync.RWMutex
+}
+
+// NewReorderPolicyRepository creates a new in-memory reorder policy repository
+func NewReorderPolicyRepository() *ReorderPolicyRepository {
+	return &ReorderPolicyRepository{
+		policies: make(map[product.ProductID]*product.ReorderPolicy),
+	}
+}
+
+// FindByProduct finds the reorder policy of a product
+func (r *ReorderPolicyRepository) FindByProduct(ctx context.Context, productID product.ProductID) (*product.ReorderPolicy, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	policy, exists := r.policies[productID]
+	if !exists {
+		return nil, product.ErrReorderPolicyNotFound
+	}
+	return policy, nil
+}
+
+// FindAll returns all reorder policies ordered by product ID
+func (r *ReorderPolicyRepository) FindAll(ctx context.Context) ([]*product.ReorderPolicy, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	policies := make([]*product.ReorderPolicy, 0, len(r.policies))
+	for _, p := range r.policies {
+		policies = append(policies, p)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].ProductID() < policies[j].ProductID()
+	})
+	return policies, nil
+}
+
+// Save persists a reorder policy, replacing the previous policy of the product
+func (r *ReorderPolicyRepository) Save(ctx context.Context, policy *product.ReorderPolicy) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.policies[policy.ProductID()] = policy
+	return nil
+}
+
+// Delete removes the reorder policy of a product
+func (r *ReorderPolicyRepository) Delete(ctx context.Context, productID product.ProductID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.policies[productID]; !exists {
+		return product.ErrReorderPolicyNotFound
+	}
+	delete(r.policies, productID)
+	return nil
+}
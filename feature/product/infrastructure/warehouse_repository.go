@@ -0,0 +1,73 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// WarehouseRepository is an in-memory implementation of the product.WarehouseRepository interface
+type WarehouseRepository struct {
+	warehouses map[product.WarehouseID]*product.Warehouse
+	mutex      sync.RWMutex
+}
+
+// NewWarehouseRepository creates a new in-memory warehouse repository holding the default warehouse
+func NewWarehouseRepository() *WarehouseRepository {
+	defaultWarehouse, _ := product.NewWarehouse(product.DefaultWarehouseID, "Default warehouse", nil)
+	return &WarehouseRepository{
+		warehouses: map[product.WarehouseID]*product.Warehouse{
+			product.DefaultWarehouseID: defaultWarehouse,
+		},
+	}
+}
+
+// FindByID finds a warehouse by its ID
+func (r *WarehouseRepository) FindByID(ctx context.Context, id product.WarehouseID) (*product.Warehouse, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	w, exists := r.warehouses[id]
+	if !exists {
+		return nil, product.ErrWarehouseNotFound
+	}
+	return w, nil
+}
+
+// FindAll returns all warehouses ordered by ID
+func (r *WarehouseRepository) FindAll(ctx context.Context) ([]*product.Warehouse, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	warehouses := make([]*product.Warehouse, 0, len(r.warehouses))
+	for _, w := range r.warehouses {
+		warehouses = append(warehouses, w)
+	}
+	sort.Slice(warehouses, func(i, j int) bool {
+		return warehouses[i].ID() < warehouses[j].ID()
+	})
+	return warehouses, nil
+}
+
+// Save persists a warehouse
+func (r *WarehouseRepository) Save(ctx context.Context, w *product.Warehouse) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.warehouses[w.ID()] = w
+	return nil
+}
+
+// Delete removes a warehouse
+func (r *WarehouseRepository) Delete(ctx context.Context, id product.WarehouseID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.warehouses[id]; !exists {
+		return product.ErrWarehouseNotFound
+	}
+	delete(r.warehouses, id)
+	return nil
+}
@@ -21,6 +21,7 @@ type AddCategoryToProductOutput struct {
 	Description string
 	Price       uint
 	Currency    string
+	Status      string
 	Stock       uint
 	Categories  []CategoryOutput
 }
@@ -44,7 +45,10 @@ func NewAddCategoryToProductUseCase(productService *domain.Service) *AddCategory
 }
 
 // Execute runs the use case
-func (uc *AddCategoryToProductUseCase) Execute(ctx context.Context, input AddCategoryToProductInput) (*AddCategoryToProductOutput, error) {
+func (uc *AddCategoryToProductUseCase) Execute(ctx context.Context, input AddCategoryToProductInput) (_ *AddCategoryToProductOutput, err error) {
+	ctx, done := observe(ctx, "AddCategoryToProduct")
+	defer func() { done(err) }()
+
 	// Create value objects
 	productID, err := domain.NewProductID(input.ProductID)
 	if err != nil {
@@ -71,7 +75,7 @@ func (uc *AddCategoryToProductUseCase) Execute(ctx context.Context, input AddCat
 	updatedProduct, err := uc.productService.AddCategoryToProduct(ctx, productID, category)
 	if err != nil {
 		if errors.Is(err, domain.ErrProductNotFound) {
-			return nil, errors.New("product not found")
+			return nil, domain.ErrProductNotFound
 		}
 		return nil, err
 	}
@@ -91,6 +95,7 @@ func (uc *AddCategoryToProductUseCase) Execute(ctx context.Context, input AddCat
 		Description: updatedProduct.Description().String(),
 		Price:       updatedProduct.Price().Amount(),
 		Currency:    updatedProduct.Price().Currency(),
+		Status:      updatedProduct.Status().String(),
 		Stock:       updatedProduct.Stock().Quantity(),
 		Categories:  categories,
 	}, nil
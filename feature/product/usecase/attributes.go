@@ -0,0 +1,212 @@
+package product
+
+import (
+	"context"
+	"errors"
+
+	domain "sago-sample/feature/product/domain"
+)
+
+// AttributeDefinitionOutput represents an attribute defined by the schema of a category
+type AttributeDefinitionOutput struct {
+	Key      string
+	Type     string
+	Required bool
+	// Values lists the allowed values of an enum attribute
+	Values []string
+}
+
+// AttributeSchemaOutput represents the attribute schema of a category
+type AttributeSchemaOutput struct {
+	CategoryID  string
+	Definitions []AttributeDefinitionOutput
+}
+
+// toAttributeSchemaOutput maps an attribute schema to its output
+func toAttributeSchemaOutput(s *domain.AttributeSchema) *AttributeSchemaOutput {
+	definitions := make([]AttributeDefinitionOutput, 0, len(s.Definitions()))
+	for _, d := range s.Definitions() {
+		definitions = append(definitions, AttributeDefinitionOutput{
+			Key:      d.Key(),
+			Type:     d.Type().String(),
+			Required: d.Required(),
+			Values:   d.Values(),
+		})
+	}
+	return &AttributeSchemaOutput{
+		CategoryID:  s.CategoryID().String(),
+		Definitions: definitions,
+	}
+}
+
+// AttributeDefinitionInput represents an attribute to define in the schema of a category
+type AttributeDefinitionInput struct {
+	Key      string
+	Type     string
+	Required bool
+	Values   []string
+}
+
+// SetAttributeSchemaInput represents the input data for defining the attributes of the products of a category
+type SetAttributeSchemaInput struct {
+	CategoryID  string
+	Definitions []AttributeDefinitionInput
+}
+
+// SetAttributeSchemaUseCase defines the use case for creating or replacing the attribute schema of a category
+type SetAttributeSchemaUseCase struct {
+	attributeService *domain.AttributeService
+}
+
+// NewSetAttributeSchemaUseCase creates a new instance of SetAttributeSchemaUseCase
+func NewSetAttributeSchemaUseCase(attributeService *domain.AttributeService) *SetAttributeSchemaUseCase {
+	return &SetAttributeSchemaUseCase{attributeService: attributeService}
+}
+
+// Execute runs the use case
+func (uc *SetAttributeSchemaUseCase) Execute(ctx context.Context, input SetAttributeSchemaInput) (_ *AttributeSchemaOutput, err error) {
+	ctx, done := observe(ctx, "SetAttributeSchema")
+	defer func() { done(err) }()
+
+	categoryID, err := domain.NewCategoryID(input.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	definitions := make([]domain.AttributeDefinition, 0, len(input.Definitions))
+	for _, d := range input.Definitions {
+		attrType, err := domain.NewAttributeType(d.Type)
+		if err != nil {
+			return nil, err
+		}
+		definition, err := domain.NewAttributeDefinition(d.Key, attrType, d.Required, d.Values)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+	schema, err := domain.NewAttributeSchema(categoryID, definitions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.attributeService.SaveSchema(ctx, schema); err != nil {
+		return nil, err
+	}
+	return toAttributeSchemaOutput(schema), nil
+}
+
+// GetAttributeSchemaUseCase defines the use case for getting the attribute schema of a category
+type GetAttributeSchemaUseCase struct {
+	attributeService *domain.AttributeService
+}
+
+// NewGetAttributeSchemaUseCase creates a new instance of GetAttributeSchemaUseCase
+func NewGetAttributeSchemaUseCase(attributeService *domain.AttributeService) *GetAttributeSchemaUseCase {
+	return &GetAttributeSchemaUseCase{attributeService: attributeService}
+}
+
+// Execute runs the use case
+func (uc *GetAttributeSchemaUseCase) Execute(ctx context.Context, categoryID string) (_ *AttributeSchemaOutput, err error) {
+	ctx, done := observe(ctx, "GetAttributeSchema")
+	defer func() { done(err) }()
+
+	id, err := domain.NewCategoryID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := uc.attributeService.GetSchema(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toAttributeSchemaOutput(schema), nil
+}
+
+// DeleteAttributeSchemaUseCase defines the use case for removing the attribute schema of a category
+type DeleteAttributeSchemaUseCase struct {
+	attributeService *domain.AttributeService
+}
+
+// NewDeleteAttributeSchemaUseCase creates a new instance of DeleteAttributeSchemaUseCase
+func NewDeleteAttributeSchemaUseCase(attributeService *domain.AttributeService) *DeleteAttributeSchemaUseCase {
+	return &DeleteAttributeSchemaUseCase{attributeService: attributeService}
+}
+
+// Execute runs the use case
+func (uc *DeleteAttributeSchemaUseCase) Execute(ctx context.Context, categoryID string) (err error) {
+	ctx, done := observe(ctx, "DeleteAttributeSchema")
+	defer func() { done(err) }()
+
+	id, err := domain.NewCategoryID(categoryID)
+	if err != nil {
+		return err
+	}
+	return uc.attributeService.DeleteSchema(ctx, id)
+}
+
+// SetProductAttributesInput represents the input data for replacing the attributes of a product
+type SetProductAttributesInput struct {
+	ProductID string
+	// Attributes holds strings, numbers and booleans by attribute key
+	Attributes map[string]any
+}
+
+// SetProductAttributesUseCase defines the use case for replacing the attributes of a product
+type SetProductAttributesUseCase struct {
+	attributeService *domain.AttributeService
+}
+
+// NewSetProductAttributesUseCase creates a new instance of SetProductAttributesUseCase
+func NewSetProductAttributesUseCase(attributeService *domain.AttributeService) *SetProductAttributesUseCase {
+	return &SetProductAttributesUseCase{attributeService: attributeService}
+}
+
+// Execute runs the use case
+func (uc *SetProductAttributesUseCase) Execute(ctx context.Context, input SetProductAttributesInput) (_ *ProductOutput, err error) {
+	ctx, done := observe(ctx, "SetProductAttributes")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := domain.NewAttributes(input.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedProduct, err := uc.attributeService.SetAttributes(ctx, productID, attributes)
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	output := toProductOutput(updatedProduct)
+	return &output, nil
+}
+
+// attributeFilter narrows matches to the products whose attributes have every value of filters
+func attributeFilter(matches func(p *domain.Product) bool, filters map[string]string) (func(p *domain.Product) bool, error) {
+	if len(filters) == 0 {
+		return matches, nil
+	}
+	for key := range filters {
+		if err := domain.ValidateAttributeKey(key); err != nil {
+			return nil, err
+		}
+	}
+	return func(p *domain.Product) bool {
+		if !matches(p) {
+			return false
+		}
+		attributes := p.Attributes()
+		for key, value := range filters {
+			if !attributes.Matches(key, value) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
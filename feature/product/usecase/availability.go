@@ -0,0 +1,160 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/observability/logging"
+)
+
+// SetProductAvailabilityInput represents the input data for changing the availability window of a product
+type SetProductAvailabilityInput struct {
+	ProductID string
+	// AvailableFrom and AvailableUntil bound the window; nil leaves a bound open
+	AvailableFrom  *time.Time
+	AvailableUntil *time.Time
+}
+
+// SetProductAvailabilityUseCase defines the use case for scheduling when a product is visible to customers
+type SetProductAvailabilityUseCase struct {
+	productService *domain.Service
+}
+
+// NewSetProductAvailabilityUseCase creates a new instance of SetProductAvailabilityUseCase
+func NewSetProductAvailabilityUseCase(productService *domain.Service) *SetProductAvailabilityUseCase {
+	return &SetProductAvailabilityUseCase{productService: productService}
+}
+
+// Execute runs the use case
+func (uc *SetProductAvailabilityUseCase) Execute(ctx context.Context, input SetProductAvailabilityInput) (_ *ProductOutput, err error) {
+	ctx, done := observe(ctx, "SetProductAvailability")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	var from, until time.Time
+	if input.AvailableFrom != nil {
+		from = *input.AvailableFrom
+	}
+	if input.AvailableUntil != nil {
+		until = *input.AvailableUntil
+	}
+	availability, err := domain.NewAvailability(from, until)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedProduct, err := uc.productService.SetAvailability(ctx, productID, availability)
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	output := toProductOutput(updatedProduct)
+	return &output, nil
+}
+
+// AvailabilityEventOutput represents the opening or closing of the availability window of a product
+type AvailabilityEventOutput struct {
+	Type       string
+	ProductID  string
+	OccurredAt time.Time
+}
+
+// ScheduleAvailabilityOutput represents the windows that opened or closed in (Since, Until]
+type ScheduleAvailabilityOutput struct {
+	Since  time.Time
+	Until  time.Time
+	Events []AvailabilityEventOutput
+}
+
+// ScheduleAvailabilityUseCase defines the scheduler announcing the availability windows of published products as they open and close.
+// Each run covers the time since the previous successful run; the first one covers the time since the use case was created.
+type ScheduleAvailabilityUseCase struct {
+	productService *domain.Service
+	mutex          sync.Mutex
+	last           time.Time
+}
+
+// NewScheduleAvailabilityUseCase creates a new instance of ScheduleAvailabilityUseCase, starting at the current time of the service's clock
+func NewScheduleAvailabilityUseCase(productService *domain.Service) *ScheduleAvailabilityUseCase {
+	return &ScheduleAvailabilityUseCase{productService: productService, last: productService.Clock().Now()}
+}
+
+// Execute runs the use case
+func (uc *ScheduleAvailabilityUseCase) Execute(ctx context.Context) (_ *ScheduleAvailabilityOutput, err error) {
+	ctx, done := observe(ctx, "ScheduleAvailability")
+	defer func() { done(err) }()
+
+	uc.mutex.Lock()
+	defer uc.mutex.Unlock()
+
+	now := uc.productService.Clock().Now()
+	events, err := uc.productService.AnnounceAvailability(ctx, uc.last, now)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ScheduleAvailabilityOutput{Since: uc.last, Until: now, Events: make([]AvailabilityEventOutput, 0, len(events))}
+	for _, e := range events {
+		output.Events = append(output.Events, AvailabilityEventOutput{
+			Type:       e.Type.String(),
+			ProductID:  e.ProductID.String(),
+			OccurredAt: e.OccurredAt,
+		})
+	}
+	uc.last = now
+	return output, nil
+}
+
+// RunEvery runs the scheduler every interval until ctx is done, logging every window that opened or closed
+func (uc *ScheduleAvailabilityUseCase) RunEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := logging.FromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		output, err := uc.Execute(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error("availability scheduling failed", "error", err)
+			}
+			continue
+		}
+		for _, e := range output.Events {
+			logger.Info("product availability changed",
+				"event", e.Type,
+				"product_id", e.ProductID,
+				"occurred_at", e.OccurredAt,
+			)
+		}
+	}
+}
+
+// availableAt narrows matches to the products available to customers at now
+func availableAt(matches func(p *domain.Product) bool, now time.Time) func(p *domain.Product) bool {
+	return func(p *domain.Product) bool {
+		return matches(p) && p.IsAvailableAt(now)
+	}
+}
+
+// optionalTime maps the zero time of an open bound to nil
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -24,6 +24,7 @@ type CreateProductOutput struct {
 	Description string
 	Price       uint
 	Currency    string
+	Status      string
 	Stock       uint
 }
 
@@ -40,7 +41,10 @@ func NewCreateProductUseCase(productService *domain.Service) *CreateProductUseCa
 }
 
 // Execute runs the use case
-func (uc *CreateProductUseCase) Execute(ctx context.Context, input CreateProductInput) (*CreateProductOutput, error) {
+func (uc *CreateProductUseCase) Execute(ctx context.Context, input CreateProductInput) (_ *CreateProductOutput, err error) {
+	ctx, done := observe(ctx, "CreateProduct")
+	defer func() { done(err) }()
+
 	// Create value objects
 	productID, err := domain.NewProductID(input.ID)
 	if err != nil {
@@ -68,7 +72,7 @@ func (uc *CreateProductUseCase) Execute(ctx context.Context, input CreateProduct
 	createdProduct, err := uc.productService.CreateProduct(ctx, productID, productName, productDescription, price, stock)
 	if err != nil {
 		if errors.Is(err, domain.ErrProductExists) {
-			return nil, errors.New("product with this ID already exists")
+			return nil, domain.ErrProductExists
 		}
 		return nil, err
 	}
@@ -80,6 +84,7 @@ func (uc *CreateProductUseCase) Execute(ctx context.Context, input CreateProduct
 		Description: createdProduct.Description().String(),
 		Price:       createdProduct.Price().Amount(),
 		Currency:    createdProduct.Price().Currency(),
+		Status:      createdProduct.Status().String(),
 		Stock:       createdProduct.Stock().Quantity(),
 	}, nil
 }
@@ -25,7 +25,10 @@ func NewDeleteProductUseCase(productService *domain.Service) *DeleteProductUseCa
 }
 
 // Execute runs the use case
-func (uc *DeleteProductUseCase) Execute(ctx context.Context, input DeleteProductInput) error {
+func (uc *DeleteProductUseCase) Execute(ctx context.Context, input DeleteProductInput) (err error) {
+	ctx, done := observe(ctx, "DeleteProduct")
+	defer func() { done(err) }()
+
 	productID, err := domain.NewProductID(input.ID)
 	if err != nil {
 		return err
@@ -34,7 +37,7 @@ func (uc *DeleteProductUseCase) Execute(ctx context.Context, input DeleteProduct
 	err = uc.productService.DeleteProduct(ctx, productID)
 	if err != nil {
 		if errors.Is(err, domain.ErrProductNotFound) {
-			return errors.New("product not found")
+			return domain.ErrProductNotFound
 		}
 		return err
 	}
@@ -0,0 +1,149 @@
+package product
+
+import (
+	"context"
+
+	domain "sago-sample/feature/product/domain"
+)
+
+// GetProductFacetsInput represents the filters of a facet query
+type GetProductFacetsInput struct {
+	// Status, when set, only counts the products with this status
+	Status string
+	// Available, when true, only counts the products available to customers at the current time
+	Available bool
+	// Currency, when set, only counts the products priced in this currency
+	Currency string
+
+	// CategoryID, MinPrice, MaxPrice, InStock and Attributes are the current filters; each facet ignores its own
+	CategoryID string
+	MinPrice   uint
+	// MaxPrice is inclusive; zero leaves the price unbounded
+	MaxPrice uint
+	InStock  *bool
+	// Attributes only counts the products whose attributes have each of these values
+	Attributes map[string]string
+
+	// PriceBounds split prices into the buckets of the price facet; nil uses the bounds of the use case
+	PriceBounds []uint
+}
+
+// GetProductFacetsOutput represents the product counts of every facet
+type GetProductFacetsOutput struct {
+	// Total is the number of products passing every filter
+	Total      int
+	Categories []CategoryFacetOutput
+	Prices     []PriceFacetOutput
+	InStock    int
+	OutOfStock int
+	Attributes []AttributeFacetOutput
+}
+
+// CategoryFacetOutput represents the number of products in a category
+type CategoryFacetOutput struct {
+	ID    string
+	Name  string
+	Count int
+}
+
+// PriceFacetOutput represents the number of products priced in [From, To); To is zero for the last bucket
+type PriceFacetOutput struct {
+	From  uint
+	To    uint
+	Count int
+}
+
+// AttributeFacetOutput represents the number of products per value of an attribute
+type AttributeFacetOutput struct {
+	Key    string
+	Values []AttributeValueFacetOutput
+}
+
+// AttributeValueFacetOutput represents the number of products having an attribute value
+type AttributeValueFacetOutput struct {
+	Value string
+	Count int
+}
+
+// GetProductFacetsUseCase defines the use case for counting products per category, price bucket, stock availability and attribute value
+type GetProductFacetsUseCase struct {
+	repo        domain.Repository
+	clock       domain.Clock
+	priceBounds []uint
+}
+
+// NewGetProductFacetsUseCase creates a new instance of GetProductFacetsUseCase splitting prices at priceBounds by default
+func NewGetProductFacetsUseCase(repo domain.Repository, priceBounds []uint) *GetProductFacetsUseCase {
+	return &GetProductFacetsUseCase{repo: repo, clock: domain.SystemClock, priceBounds: priceBounds}
+}
+
+// SetClock replaces the clock telling which products are available; tests inject a fake one
+func (uc *GetProductFacetsUseCase) SetClock(clock domain.Clock) {
+	uc.clock = clock
+}
+
+// Execute runs the use case
+func (uc *GetProductFacetsUseCase) Execute(ctx context.Context, input GetProductFacetsInput) (_ *GetProductFacetsOutput, err error) {
+	ctx, done := observe(ctx, "GetProductFacets")
+	defer func() { done(err) }()
+
+	query := domain.FacetQuery{
+		Currency:   input.Currency,
+		CategoryID: domain.CategoryID(input.CategoryID),
+		MinPrice:   input.MinPrice,
+		MaxPrice:   input.MaxPrice,
+		InStock:    input.InStock,
+		Attributes: input.Attributes,
+	}
+	if input.Status != "" {
+		if query.Status, err = domain.NewProductStatus(input.Status); err != nil {
+			return nil, err
+		}
+	}
+	if input.Available {
+		query.AvailableAt = uc.clock.Now()
+	}
+	if input.MaxPrice > 0 && input.MinPrice > input.MaxPrice {
+		return nil, domain.NewValidationError("minPrice cannot exceed maxPrice")
+	}
+	for key := range input.Attributes {
+		if err := domain.ValidateAttributeKey(key); err != nil {
+			return nil, err
+		}
+	}
+	bounds := input.PriceBounds
+	if bounds == nil {
+		bounds = uc.priceBounds
+	}
+	if query.PriceBuckets, err = domain.NewPriceBuckets(bounds); err != nil {
+		return nil, err
+	}
+
+	facets, err := uc.repo.Facets(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &GetProductFacetsOutput{
+		Total:      facets.Total,
+		Categories: make([]CategoryFacetOutput, 0, len(facets.Categories)),
+		Prices:     make([]PriceFacetOutput, 0, len(facets.Prices)),
+		InStock:    facets.Stock.InStock,
+		OutOfStock: facets.Stock.OutOfStock,
+		Attributes: make([]AttributeFacetOutput, 0, len(facets.Attributes)),
+	}
+	for _, c := range facets.Categories {
+		output.Categories = append(output.Categories, CategoryFacetOutput{ID: c.ID.String(), Name: c.Name.String(), Count: c.Count})
+	}
+	for _, p := range facets.Prices {
+		output.Prices = append(output.Prices, PriceFacetOutput{From: p.Bucket.From, To: p.Bucket.To, Count: p.Count})
+	}
+	for _, a := range facets.Attributes {
+		values := make([]AttributeValueFacetOutput, 0, len(a.Values))
+		for _, v := range a.Values {
+			values = append(values, AttributeValueFacetOutput{Value: v.Value, Count: v.Count})
+		}
+		output.Attributes = append(output.Attributes, AttributeFacetOutput{Key: a.Key, Values: values})
+	}
+	return output, nil
+}
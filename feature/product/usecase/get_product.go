@@ -3,12 +3,20 @@ package product
 import (
 	"context"
 	"errors"
+	"time"
 
 	domain "sago-sample/feature/product/domain"
 )
 
 type GetProductInput struct {
 	ID string
+	// Status, when set, hides the product unless it has this status
+	Status string
+	// Available, when true, also hides the product unless it is available to customers at the current time
+	Available bool
+	// Locale, when set, returns the name and description of the product and its categories in this locale,
+	// falling back to the default texts where they are not translated
+	Locale string
 }
 
 type GetProductOutput struct {
@@ -17,36 +25,73 @@ type GetProductOutput struct {
 	Description string
 	Price       uint
 	Currency    string
-	Stock       uint
-	Categories  []CategoryOutput
+	Status      string
+	// AvailableFrom and AvailableUntil bound the availability window; nil leaves a bound open
+	AvailableFrom  *time.Time
+	AvailableUntil *time.Time
+	// Attributes are the custom attribute values defined by the schemas of the categories
+	Attributes map[string]any
+	// Images are in display order, the primary image first
+	Images     []ImageOutput
+	Stock      uint
+	Categories []CategoryOutput
+	UpdatedAt  time.Time
 }
 
 type GetProductUseCase struct {
-	repo domain.Repository
+	repo         domain.Repository
+	clock        domain.Clock
+	translations *domain.TranslationService
 }
 
 func NewGetProductUseCase(repo domain.Repository) *GetProductUseCase {
 	return &GetProductUseCase{
-		repo: repo,
+		repo:  repo,
+		clock: domain.SystemClock,
 	}
 }
 
+// SetClock replaces the clock telling which products are available; tests inject a fake one
+func (uc *GetProductUseCase) SetClock(clock domain.Clock) {
+	uc.clock = clock
+}
+
+// SetTranslations makes the use case translate category names; product texts are translated regardless
+func (uc *GetProductUseCase) SetTranslations(translations *domain.TranslationService) {
+	uc.translations = translations
+}
+
 // Execute runs the use case
-func (uc *GetProductUseCase) Execute(ctx context.Context, input GetProductInput) (*GetProductOutput, error) {
+func (uc *GetProductUseCase) Execute(ctx context.Context, input GetProductInput) (_ *GetProductOutput, err error) {
+	ctx, done := observe(ctx, "GetProduct")
+	defer func() { done(err) }()
+
 	// Create value object
 	productID, err := domain.NewProductID(input.ID)
 	if err != nil {
 		return nil, err
 	}
 
+	matches, err := statusFilter(input.Status)
+	if err != nil {
+		return nil, err
+	}
+	if input.Available {
+		matches = availableAt(matches, uc.clock.Now())
+	}
+
 	// Call domain service to get product
 	foundProduct, err := uc.repo.FindByID(ctx, productID)
 	if err != nil {
 		if errors.Is(err, domain.ErrProductNotFound) {
-			return nil, errors.New("product not found")
+			return nil, domain.ErrProductNotFound
 		}
 		return nil, err
 	}
+	// A product hidden by the filters does not exist for the caller
+	if !matches(foundProduct) {
+		return nil, domain.ErrProductNotFound
+	}
 
 	// Map domain entity to output
 	// Map categories
@@ -58,15 +103,35 @@ func (uc *GetProductUseCase) Execute(ctx context.Context, input GetProductInput)
 		})
 	}
 
-	return &GetProductOutput{
-		ID:          foundProduct.ID().String(),
-		Name:        foundProduct.Name().String(),
-		Description: foundProduct.Description().String(),
-		Price:       foundProduct.Price().Amount(),
-		Currency:    foundProduct.Price().Currency(),
-		Stock:       foundProduct.Stock().Quantity(),
-		Categories:  categories,
-	}, nil
+	outputs := []ProductOutput{{
+		ID:             foundProduct.ID().String(),
+		Name:           foundProduct.Name().String(),
+		Description:    foundProduct.Description().String(),
+		Price:          foundProduct.Price().Amount(),
+		Currency:       foundProduct.Price().Currency(),
+		Status:         foundProduct.Status().String(),
+		AvailableFrom:  optionalTime(foundProduct.Availability().From()),
+		AvailableUntil: optionalTime(foundProduct.Availability().Until()),
+		Attributes:     foundProduct.Attributes(),
+		Images:         toImageOutputs(foundProduct),
+		Stock:          foundProduct.Stock().Quantity(),
+		Categories:     categories,
+		UpdatedAt:      foundProduct.UpdatedAt(),
+	}}
+	if err := localizeProducts(ctx, uc.translations, input.Locale, []*domain.Product{foundProduct}, outputs); err != nil {
+		return nil, err
+	}
+
+	output := GetProductOutput(outputs[0])
+	return &output, nil
+}
+
+// GetAllProductsInput filters the list of all products
+type GetAllProductsInput struct {
+	// Status, when set, only returns the products with this status
+	Status string
+	// Available, when true, only returns the products available to customers at the current time
+	Available bool
 }
 
 // GetAllProductsOutput represents a product in the list of all products
@@ -81,26 +146,56 @@ type ProductOutput struct {
 	Description string
 	Price       uint
 	Currency    string
-	Stock       uint
-	Categories  []CategoryOutput
+	Status      string
+	// AvailableFrom and AvailableUntil bound the availability window; nil leaves a bound open
+	AvailableFrom  *time.Time
+	AvailableUntil *time.Time
+	// Attributes are the custom attribute values defined by the schemas of the categories
+	Attributes map[string]any
+	// Images are in display order, the primary image first
+	Images     []ImageOutput
+	Stock      uint
+	Categories []CategoryOutput
+	UpdatedAt  time.Time
 }
 
 // GetAllProductsUseCase defines the use case for getting all products
 type GetAllProductsUseCase struct {
-	repo domain.Repository
+	repo  domain.Repository
+	clock domain.Clock
 }
 
 // NewGetAllProductsUseCase creates a new instance of GetAllProductsUseCase
 func NewGetAllProductsUseCase(repo domain.Repository) *GetAllProductsUseCase {
-	return &GetAllProductsUseCase{repo: repo}
+	return &GetAllProductsUseCase{
+		repo:  repo,
+		clock: domain.SystemClock,
+	}
+}
+
+// SetClock replaces the clock telling which products are available; tests inject a fake one
+func (uc *GetAllProductsUseCase) SetClock(clock domain.Clock) {
+	uc.clock = clock
 }
 
 // Execute runs the use case
-func (uc *GetAllProductsUseCase) Execute(ctx context.Context) (*GetAllProductsOutput, error) {
+func (uc *GetAllProductsUseCase) Execute(ctx context.Context, input GetAllProductsInput) (_ *GetAllProductsOutput, err error) {
+	ctx, done := observe(ctx, "GetAllProducts")
+	defer func() { done(err) }()
+
+	matches, err := statusFilter(input.Status)
+	if err != nil {
+		return nil, err
+	}
+	if input.Available {
+		matches = availableAt(matches, uc.clock.Now())
+	}
+
 	products, err := uc.repo.FindAll(ctx)
 	if err != nil {
 		return nil, err
 	}
+	products = filterProducts(products, matches)
 
 	output := &GetAllProductsOutput{
 		Products: make([]ProductOutput, len(products)),
@@ -116,13 +211,19 @@ func (uc *GetAllProductsUseCase) Execute(ctx context.Context) (*GetAllProductsOu
 		}
 
 		output.Products[i] = ProductOutput{
-			ID:          p.ID().String(),
-			Name:        p.Name().String(),
-			Description: p.Description().String(),
-			Price:       p.Price().Amount(),
-			Currency:    p.Price().Currency(),
-			Stock:       p.Stock().Quantity(),
-			Categories:  categories,
+			ID:             p.ID().String(),
+			Name:           p.Name().String(),
+			Description:    p.Description().String(),
+			Price:          p.Price().Amount(),
+			Currency:       p.Price().Currency(),
+			Status:         p.Status().String(),
+			AvailableFrom:  optionalTime(p.Availability().From()),
+			AvailableUntil: optionalTime(p.Availability().Until()),
+			Attributes:     p.Attributes(),
+			Images:         toImageOutputs(p),
+			Stock:          p.Stock().Quantity(),
+			Categories:     categories,
+			UpdatedAt:      p.UpdatedAt(),
 		}
 	}
 
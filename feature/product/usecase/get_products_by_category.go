@@ -2,6 +2,7 @@ package product
 
 import (
 	"context"
+	"sort"
 
 	domain "sago-sample/feature/product/domain"
 )
@@ -9,6 +10,12 @@ import (
 // GetProductsByCategoryInput represents the input data for getting products by category
 type GetProductsByCategoryInput struct {
 	CategoryID string
+	// Status, when set, only returns the products with this status
+	Status string
+	// Available, when true, only returns the products available to customers at the current time of the service's clock
+	Available bool
+	// Locale, when set, returns the texts of the products and their categories in this locale, as GetProductInput.Locale
+	Locale string
 }
 
 // GetProductsByCategoryOutput represents the output data after getting products by category
@@ -19,6 +26,7 @@ type GetProductsByCategoryOutput struct {
 // GetProductsByCategoryUseCase defines the use case for getting products by category
 type GetProductsByCategoryUseCase struct {
 	productService *domain.Service
+	translations   *domain.TranslationService
 }
 
 // NewGetProductsByCategoryUseCase creates a new instance of GetProductsByCategoryUseCase
@@ -28,17 +36,39 @@ func NewGetProductsByCategoryUseCase(productService *domain.Service) *GetProduct
 	}
 }
 
+// SetTranslations makes the use case translate category names; product texts are translated regardless
+func (uc *GetProductsByCategoryUseCase) SetTranslations(translations *domain.TranslationService) {
+	uc.translations = translations
+}
+
 // Execute runs the use case
-func (uc *GetProductsByCategoryUseCase) Execute(ctx context.Context, input GetProductsByCategoryInput) (*GetProductsByCategoryOutput, error) {
+func (uc *GetProductsByCategoryUseCase) Execute(ctx context.Context, input GetProductsByCategoryInput) (_ *GetProductsByCategoryOutput, err error) {
+	ctx, done := observe(ctx, "GetProductsByCategory")
+	defer func() { done(err) }()
+
 	categoryID, err := domain.NewCategoryID(input.CategoryID)
 	if err != nil {
 		return nil, err
 	}
 
+	matches, err := statusFilter(input.Status)
+	if err != nil {
+		return nil, err
+	}
+	if input.Available {
+		matches = availableAt(matches, uc.productService.Clock().Now())
+	}
+
 	products, err := uc.productService.GetProductsByCategory(ctx, categoryID)
 	if err != nil {
 		return nil, err
 	}
+	products = filterProducts(products, matches)
+
+	// Repositories return products in no particular order; sort them so responses are stable
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].ID().String() < products[j].ID().String()
+	})
 
 	output := &GetProductsByCategoryOutput{
 		Products: make([]ProductOutput, len(products)),
@@ -54,15 +84,25 @@ func (uc *GetProductsByCategoryUseCase) Execute(ctx context.Context, input GetPr
 		}
 
 		output.Products[i] = ProductOutput{
-			ID:          p.ID().String(),
-			Name:        p.Name().String(),
-			Description: p.Description().String(),
-			Price:       p.Price().Amount(),
-			Currency:    p.Price().Currency(),
-			Stock:       p.Stock().Quantity(),
-			Categories:  categories,
+			ID:             p.ID().String(),
+			Name:           p.Name().String(),
+			Description:    p.Description().String(),
+			Price:          p.Price().Amount(),
+			Currency:       p.Price().Currency(),
+			Status:         p.Status().String(),
+			AvailableFrom:  optionalTime(p.Availability().From()),
+			AvailableUntil: optionalTime(p.Availability().Until()),
+			Attributes:     p.Attributes(),
+			Images:         toImageOutputs(p),
+			Stock:          p.Stock().Quantity(),
+			Categories:     categories,
+			UpdatedAt:      p.UpdatedAt(),
 		}
 	}
 
+	if err := localizeProducts(ctx, uc.translations, input.Locale, products, output.Products); err != nil {
+		return nil, err
+	}
+
 	return output, nil
 }
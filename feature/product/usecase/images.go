@@ -0,0 +1,248 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	domain "sago-sample/feature/product/domain"
+)
+
+// ImageOutput represents an image of a product
+type ImageOutput struct {
+	ID          string
+	ContentType string
+	// Size is the size of the image file in bytes
+	Size      int64
+	Width     int
+	Height    int
+	CreatedAt time.Time
+}
+
+// toImageOutputs maps the images of a product to outputs, the primary one first
+func toImageOutputs(p *domain.Product) []ImageOutput {
+	images := p.Images()
+	outputs := make([]ImageOutput, 0, len(images))
+	for _, img := range images {
+		outputs = append(outputs, toImageOutput(img))
+	}
+	return outputs
+}
+
+// toImageOutput maps an image to its output
+func toImageOutput(img domain.Image) ImageOutput {
+	return ImageOutput{
+		ID:          img.ID().String(),
+		ContentType: img.ContentType(),
+		Size:        img.Size(),
+		Width:       img.Width(),
+		Height:      img.Height(),
+		CreatedAt:   img.CreatedAt(),
+	}
+}
+
+// imageError returns the bare not-found sentinels of the media service, without the context wrapped around them
+func imageError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound):
+		return domain.ErrProductNotFound
+	case errors.Is(err, domain.ErrImageNotFound):
+		return domain.ErrImageNotFound
+	default:
+		return err
+	}
+}
+
+// UploadProductImageInput represents the input for uploading an image of a product
+type UploadProductImageInput struct {
+	ProductID string
+	// Data is the image file; its type is sniffed from the content
+	Data []byte
+}
+
+// UploadProductImageOutput represents the uploaded image and the updated product
+type UploadProductImageOutput struct {
+	Image   ImageOutput
+	Product ProductOutput
+}
+
+// UploadProductImageUseCase defines the use case for adding an image to a product
+type UploadProductImageUseCase struct {
+	mediaService *domain.MediaService
+}
+
+// NewUploadProductImageUseCase creates a new instance of UploadProductImageUseCase
+func NewUploadProductImageUseCase(mediaService *domain.MediaService) *UploadProductImageUseCase {
+	return &UploadProductImageUseCase{mediaService: mediaService}
+}
+
+// Execute runs the use case
+func (uc *UploadProductImageUseCase) Execute(ctx context.Context, input UploadProductImageInput) (_ *UploadProductImageOutput, err error) {
+	ctx, done := observe(ctx, "UploadProductImage")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedProduct, img, err := uc.mediaService.UploadImage(ctx, productID, input.Data)
+	if err != nil {
+		return nil, imageError(err)
+	}
+
+	return &UploadProductImageOutput{
+		Image:   toImageOutput(img),
+		Product: toProductOutput(updatedProduct),
+	}, nil
+}
+
+// RemoveProductImageInput represents the input for removing an image of a product
+type RemoveProductImageInput struct {
+	ProductID string
+	ImageID   string
+}
+
+// RemoveProductImageUseCase defines the use case for removing an image of a product and its files
+type RemoveProductImageUseCase struct {
+	mediaService *domain.MediaService
+}
+
+// NewRemoveProductImageUseCase creates a new instance of RemoveProductImageUseCase
+func NewRemoveProductImageUseCase(mediaService *domain.MediaService) *RemoveProductImageUseCase {
+	return &RemoveProductImageUseCase{mediaService: mediaService}
+}
+
+// Execute runs the use case
+func (uc *RemoveProductImageUseCase) Execute(ctx context.Context, input RemoveProductImageInput) (_ *ProductOutput, err error) {
+	ctx, done := observe(ctx, "RemoveProductImage")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedProduct, err := uc.mediaService.RemoveImage(ctx, productID, domain.ImageID(input.ImageID))
+	if err != nil {
+		return nil, imageError(err)
+	}
+
+	output := toProductOutput(updatedProduct)
+	return &output, nil
+}
+
+// ReorderProductImagesInput represents the input for reordering the images of a product
+type ReorderProductImagesInput struct {
+	ProductID string
+	// ImageIDs lists every image of the product in the new order; the first one becomes the primary image
+	ImageIDs []string
+}
+
+// ReorderProductImagesUseCase defines the use case for reordering the images of a product
+type ReorderProductImagesUseCase struct {
+	mediaService *domain.MediaService
+}
+
+// NewReorderProductImagesUseCase creates a new instance of ReorderProductImagesUseCase
+func NewReorderProductImagesUseCase(mediaService *domain.MediaService) *ReorderProductImagesUseCase {
+	return &ReorderProductImagesUseCase{mediaService: mediaService}
+}
+
+// Execute runs the use case
+func (uc *ReorderProductImagesUseCase) Execute(ctx context.Context, input ReorderProductImagesInput) (_ *ProductOutput, err error) {
+	ctx, done := observe(ctx, "ReorderProductImages")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]domain.ImageID, 0, len(input.ImageIDs))
+	for _, id := range input.ImageIDs {
+		ids = append(ids, domain.ImageID(id))
+	}
+
+	updatedProduct, err := uc.mediaService.ReorderImages(ctx, productID, ids)
+	if err != nil {
+		return nil, imageError(err)
+	}
+
+	output := toProductOutput(updatedProduct)
+	return &output, nil
+}
+
+// GetProductImageInput represents the input for reading the file of an image
+type GetProductImageInput struct {
+	ProductID string
+	ImageID   string
+	// Thumbnail, when true, reads the thumbnail instead of the image
+	Thumbnail bool
+	// Status, when set, hides the images unless the product has this status
+	Status string
+	// Available, when true, also hides the images unless the product is available to customers at the current time
+	Available bool
+}
+
+// GetProductImageOutput represents the file of an image; the caller closes Content
+type GetProductImageOutput struct {
+	Content     io.ReadCloser
+	ContentType string
+	CreatedAt   time.Time
+}
+
+// GetProductImageUseCase defines the use case for reading the file of an image or of its thumbnail
+type GetProductImageUseCase struct {
+	repo         domain.Repository
+	mediaService *domain.MediaService
+	clock        domain.Clock
+}
+
+// NewGetProductImageUseCase creates a new instance of GetProductImageUseCase
+func NewGetProductImageUseCase(repo domain.Repository, mediaService *domain.MediaService) *GetProductImageUseCase {
+	return &GetProductImageUseCase{repo: repo, mediaService: mediaService, clock: domain.SystemClock}
+}
+
+// SetClock replaces the clock telling which products are available; tests inject a fake one
+func (uc *GetProductImageUseCase) SetClock(clock domain.Clock) {
+	uc.clock = clock
+}
+
+// Execute runs the use case
+func (uc *GetProductImageUseCase) Execute(ctx context.Context, input GetProductImageInput) (_ *GetProductImageOutput, err error) {
+	ctx, done := observe(ctx, "GetProductImage")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := statusFilter(input.Status)
+	if err != nil {
+		return nil, err
+	}
+	if input.Available {
+		matches = availableAt(matches, uc.clock.Now())
+	}
+
+	// The images of a product hidden by the filters do not exist for the caller
+	foundProduct, err := uc.repo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, imageError(err)
+	}
+	if !matches(foundProduct) {
+		return nil, domain.ErrProductNotFound
+	}
+
+	content, img, err := uc.mediaService.OpenImage(ctx, productID, domain.ImageID(input.ImageID), input.Thumbnail)
+	if err != nil {
+		return nil, imageError(err)
+	}
+
+	return &GetProductImageOutput{
+		Content:     content,
+		ContentType: img.ContentType(),
+		CreatedAt:   img.CreatedAt(),
+	}, nil
+}
@@ -0,0 +1,249 @@
+package product
+
+import (
+	"context"
+
+	domain "sago-sample/feature/product/domain"
+)
+
+// Allocation strategies accepted by AllocateStockUseCase
+const (
+	AllocationMostStock = "most-stock"
+	AllocationNearest   = "nearest"
+	AllocationPriority  = "priority"
+)
+
+// AllocationStrategies lists the accepted allocation strategies
+var AllocationStrategies = []string{AllocationMostStock, AllocationNearest, AllocationPriority}
+
+// StockLevelOutput represents the stock of a product in one warehouse
+type StockLevelOutput struct {
+	WarehouseID string
+	Quantity    uint
+}
+
+// InventoryOutput represents the stock of a product per warehouse
+type InventoryOutput struct {
+	ProductID string
+	// Total is the product's stock across every warehouse
+	Total  uint
+	Levels []StockLevelOutput
+}
+
+// toStockLevelOutputs maps stock levels to outputs
+func toStockLevelOutputs(levels []domain.StockLevel) []StockLevelOutput {
+	outputs := make([]StockLevelOutput, 0, len(levels))
+	for _, l := range levels {
+		outputs = append(outputs, StockLevelOutput{WarehouseID: l.WarehouseID().String(), Quantity: l.Quantity()})
+	}
+	return outputs
+}
+
+// toInventoryOutput maps the stock of a product to its output
+func toInventoryOutput(p *domain.Product) InventoryOutput {
+	return InventoryOutput{
+		ProductID: p.ID().String(),
+		Total:     p.Stock().Quantity(),
+		Levels:    toStockLevelOutputs(p.StockLevels()),
+	}
+}
+
+// GetInventoryUseCase defines the use case for getting the stock of a product per warehouse
+type GetInventoryUseCase struct {
+	repo domain.Repository
+}
+
+// NewGetInventoryUseCase creates a new instance of GetInventoryUseCase
+func NewGetInventoryUseCase(repo domain.Repository) *GetInventoryUseCase {
+	return &GetInventoryUseCase{repo: repo}
+}
+
+// Execute runs the use case
+func (uc *GetInventoryUseCase) Execute(ctx context.Context, productID string) (_ *InventoryOutput, err error) {
+	ctx, done := observe(ctx, "GetInventory")
+	defer func() { done(err) }()
+
+	id, err := domain.NewProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := uc.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	output := toInventoryOutput(p)
+	return &output, nil
+}
+
+// SetStockLevelInput represents the input data for setting the stock of a product in a warehouse
+type SetStockLevelInput struct {
+	ProductID   string
+	WarehouseID string
+	Quantity    uint
+}
+
+// SetStockLevelUseCase defines the use case for setting the stock of a product in a warehouse
+type SetStockLevelUseCase struct {
+	inventoryService *domain.InventoryService
+}
+
+// NewSetStockLevelUseCase creates a new instance of SetStockLevelUseCase
+func NewSetStockLevelUseCase(inventoryService *domain.InventoryService) *SetStockLevelUseCase {
+	return &SetStockLevelUseCase{inventoryService: inventoryService}
+}
+
+// Execute runs the use case
+func (uc *SetStockLevelUseCase) Execute(ctx context.Context, input SetStockLevelInput) (_ *InventoryOutput, err error) {
+	ctx, done := observe(ctx, "SetStockLevel")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	warehouseID, err := domain.NewWarehouseID(input.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := uc.inventoryService.SetStockLevel(ctx, productID, warehouseID, domain.NewStock(input.Quantity))
+	if err != nil {
+		return nil, err
+	}
+
+	output := toInventoryOutput(p)
+	return &output, nil
+}
+
+// TransferStockInput represents the input data for moving stock between two warehouses
+type TransferStockInput struct {
+	ProductID string
+	From      string
+	To        string
+	Quantity  uint
+}
+
+// TransferStockUseCase defines the use case for moving stock of a product between two warehouses
+type TransferStockUseCase struct {
+	inventoryService *domain.InventoryService
+}
+
+// NewTransferStockUseCase creates a new instance of TransferStockUseCase
+func NewTransferStockUseCase(inventoryService *domain.InventoryService) *TransferStockUseCase {
+	return &TransferStockUseCase{inventoryService: inventoryService}
+}
+
+// Execute runs the use case
+func (uc *TransferStockUseCase) Execute(ctx context.Context, input TransferStockInput) (_ *InventoryOutput, err error) {
+	ctx, done := observe(ctx, "TransferStock")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	from, err := domain.NewWarehouseID(input.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := domain.NewWarehouseID(input.To)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := uc.inventoryService.TransferStock(ctx, productID, from, to, input.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	output := toInventoryOutput(p)
+	return &output, nil
+}
+
+// AllocateStockInput represents the input data for decreasing the stock of a product across warehouses
+type AllocateStockInput struct {
+	ProductID string
+	Quantity  uint
+	// Strategy is one of AllocationStrategies; empty means AllocationMostStock
+	Strategy string
+	// Latitude and Longitude locate the destination for AllocationNearest
+	Latitude  *float64
+	Longitude *float64
+	// Warehouses lists the warehouses to draw from, in order, for AllocationPriority
+	Warehouses []string
+}
+
+// AllocateStockOutput represents the remaining stock and the quantity taken from each warehouse
+type AllocateStockOutput struct {
+	InventoryOutput
+	Allocations []StockLevelOutput
+}
+
+// AllocateStockUseCase defines the use case for decreasing the stock of a product across warehouses
+type AllocateStockUseCase struct {
+	inventoryService *domain.InventoryService
+}
+
+// NewAllocateStockUseCase creates a new instance of AllocateStockUseCase
+func NewAllocateStockUseCase(inventoryService *domain.InventoryService) *AllocateStockUseCase {
+	return &AllocateStockUseCase{inventoryService: inventoryService}
+}
+
+// Execute runs the use case
+func (uc *AllocateStockUseCase) Execute(ctx context.Context, input AllocateStockInput) (_ *AllocateStockOutput, err error) {
+	ctx, done := observe(ctx, "AllocateStock")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := input.strategy()
+	if err != nil {
+		return nil, err
+	}
+
+	p, allocations, err := uc.inventoryService.AllocateStock(ctx, productID, input.Quantity, strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AllocateStockOutput{
+		InventoryOutput: toInventoryOutput(p),
+		Allocations:     toStockLevelOutputs(allocations),
+	}, nil
+}
+
+// strategy builds the domain allocation strategy of the input
+func (input AllocateStockInput) strategy() (domain.AllocationStrategy, error) {
+	switch input.Strategy {
+	case "", AllocationMostStock:
+		return domain.MostStockStrategy{}, nil
+	case AllocationNearest:
+		if input.Latitude == nil || input.Longitude == nil {
+			return nil, domain.NewValidationError("the nearest strategy requires latitude and longitude")
+		}
+		destination, err := domain.NewLocation(*input.Latitude, *input.Longitude)
+		if err != nil {
+			return nil, err
+		}
+		return domain.NearestStrategy{Destination: destination}, nil
+	case AllocationPriority:
+		if len(input.Warehouses) == 0 {
+			return nil, domain.NewValidationError("the priority strategy requires a list of warehouses")
+		}
+		warehouses := make([]domain.WarehouseID, 0, len(input.Warehouses))
+		for _, w := range input.Warehouses {
+			id, err := domain.NewWarehouseID(w)
+			if err != nil {
+				return nil, err
+			}
+			warehouses = append(warehouses, id)
+		}
+		return domain.PriorityStrategy{Warehouses: warehouses}, nil
+	default:
+		return nil, domain.NewValidationError("unknown allocation strategy " + input.Strategy)
+	}
+}
@@ -0,0 +1,100 @@
+package product
+
+import (
+	"context"
+	"errors"
+
+	domain "sago-sample/feature/product/domain"
+)
+
+// ChangeProductStatusInput represents the input data for moving a product to another lifecycle status
+type ChangeProductStatusInput struct {
+	ProductID string
+	Status    string
+}
+
+// ChangeProductStatusUseCase defines the use case for publishing, unpublishing and archiving products
+type ChangeProductStatusUseCase struct {
+	productService *domain.Service
+}
+
+// NewChangeProductStatusUseCase creates a new instance of ChangeProductStatusUseCase
+func NewChangeProductStatusUseCase(productService *domain.Service) *ChangeProductStatusUseCase {
+	return &ChangeProductStatusUseCase{productService: productService}
+}
+
+// Execute runs the use case
+func (uc *ChangeProductStatusUseCase) Execute(ctx context.Context, input ChangeProductStatusInput) (_ *ProductOutput, err error) {
+	ctx, done := observe(ctx, "ChangeProductStatus")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	status, err := domain.NewProductStatus(input.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedProduct, err := uc.productService.ChangeStatus(ctx, productID, status)
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	output := toProductOutput(updatedProduct)
+	return &output, nil
+}
+
+// statusFilter returns the predicate matching the products of a status; an empty status matches every product
+func statusFilter(status string) (func(p *domain.Product) bool, error) {
+	if status == "" {
+		return func(*domain.Product) bool { return true }, nil
+	}
+	want, err := domain.NewProductStatus(status)
+	if err != nil {
+		return nil, err
+	}
+	return func(p *domain.Product) bool { return p.Status() == want }, nil
+}
+
+// filterProducts returns the products matching keep
+func filterProducts(products []*domain.Product, keep func(p *domain.Product) bool) []*domain.Product {
+	kept := make([]*domain.Product, 0, len(products))
+	for _, p := range products {
+		if keep(p) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// toProductOutput maps a product to its output
+func toProductOutput(p *domain.Product) ProductOutput {
+	categories := make([]CategoryOutput, 0, len(p.Categories()))
+	for _, c := range p.Categories() {
+		categories = append(categories, CategoryOutput{
+			ID:   c.ID().String(),
+			Name: c.Name().String(),
+		})
+	}
+
+	return ProductOutput{
+		ID:             p.ID().String(),
+		Name:           p.Name().String(),
+		Description:    p.Description().String(),
+		Price:          p.Price().Amount(),
+		Currency:       p.Price().Currency(),
+		Status:         p.Status().String(),
+		AvailableFrom:  optionalTime(p.Availability().From()),
+		AvailableUntil: optionalTime(p.Availability().Until()),
+		Attributes:     p.Attributes(),
+		Images:         toImageOutputs(p),
+		Stock:          p.Stock().Quantity(),
+		Categories:     categories,
+		UpdatedAt:      p.UpdatedAt(),
+	}
+}
@@ -0,0 +1,143 @@
+package product
+
+import (
+	"context"
+	"sort"
+
+	domain "sago-sample/feature/product/domain"
+)
+
+// MaxListProductsLimit is the largest page size accepted by ListProductsUseCase
+const MaxListProductsLimit = 100
+
+// ListProductsInput represents the input data for listing a page of products
+type ListProductsInput struct {
+	// Limit is the page size; zero returns every product after the cursor
+	Limit int
+	// After is the ID of the last product of the previous page
+	After string
+	// Status, when set, only lists the products with this status
+	Status string
+	// Available, when true, only lists the products available to customers at the current time
+	Available bool
+	// Attributes, when set, only lists the products whose attributes have each of these values
+	Attributes map[string]string
+	// Locale, when set, returns the texts of the products and their categories in this locale, as GetProductInput.Locale
+	Locale string
+}
+
+// ListProductsOutput represents a page of products ordered by ID
+type ListProductsOutput struct {
+	Products []ProductOutput
+	// NextCursor is the cursor of the next page, or empty on the last page
+	NextCursor string
+	Total      int
+}
+
+// ListProductsUseCase defines the use case for listing products page by page
+type ListProductsUseCase struct {
+	repo         domain.Repository
+	clock        domain.Clock
+	translations *domain.TranslationService
+}
+
+// NewListProductsUseCase creates a new instance of ListProductsUseCase
+func NewListProductsUseCase(repo domain.Repository) *ListProductsUseCase {
+	return &ListProductsUseCase{repo: repo, clock: domain.SystemClock}
+}
+
+// SetClock replaces the clock telling which products are available; tests inject a fake one
+func (uc *ListProductsUseCase) SetClock(clock domain.Clock) {
+	uc.clock = clock
+}
+
+// SetTranslations makes the use case translate category names; product texts are translated regardless
+func (uc *ListProductsUseCase) SetTranslations(translations *domain.TranslationService) {
+	uc.translations = translations
+}
+
+// Execute runs the use case
+func (uc *ListProductsUseCase) Execute(ctx context.Context, input ListProductsInput) (_ *ListProductsOutput, err error) {
+	ctx, done := observe(ctx, "ListProducts")
+	defer func() { done(err) }()
+
+	if input.Limit < 0 {
+		return nil, domain.NewValidationError("limit cannot be negative")
+	}
+	if input.Limit > MaxListProductsLimit {
+		input.Limit = MaxListProductsLimit
+	}
+
+	matches, err := statusFilter(input.Status)
+	if err != nil {
+		return nil, err
+	}
+	if input.Available {
+		matches = availableAt(matches, uc.clock.Now())
+	}
+	if matches, err = attributeFilter(matches, input.Attributes); err != nil {
+		return nil, err
+	}
+
+	products, err := uc.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	products = filterProducts(products, matches)
+
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].ID().String() < products[j].ID().String()
+	})
+
+	start := 0
+	if input.After != "" {
+		start = sort.Search(len(products), func(i int) bool {
+			return products[i].ID().String() > input.After
+		})
+	}
+	end := len(products)
+	if input.Limit > 0 && start+input.Limit < end {
+		end = start + input.Limit
+	}
+
+	output := &ListProductsOutput{
+		Products: make([]ProductOutput, 0, end-start),
+		Total:    len(products),
+	}
+
+	for _, p := range products[start:end] {
+		categories := make([]CategoryOutput, 0, len(p.Categories()))
+		for _, c := range p.Categories() {
+			categories = append(categories, CategoryOutput{
+				ID:   c.ID().String(),
+				Name: c.Name().String(),
+			})
+		}
+
+		output.Products = append(output.Products, ProductOutput{
+			ID:             p.ID().String(),
+			Name:           p.Name().String(),
+			Description:    p.Description().String(),
+			Price:          p.Price().Amount(),
+			Currency:       p.Price().Currency(),
+			Status:         p.Status().String(),
+			AvailableFrom:  optionalTime(p.Availability().From()),
+			AvailableUntil: optionalTime(p.Availability().Until()),
+			Attributes:     p.Attributes(),
+			Images:         toImageOutputs(p),
+			Stock:          p.Stock().Quantity(),
+			Categories:     categories,
+			UpdatedAt:      p.UpdatedAt(),
+		})
+	}
+
+	if err := localizeProducts(ctx, uc.translations, input.Locale, products[start:end], output.Products); err != nil {
+		return nil, err
+	}
+
+	if end < len(products) {
+		output.NextCursor = products[end-1].ID().String()
+	}
+
+	return output, nil
+}
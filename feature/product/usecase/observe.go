@@ -0,0 +1,61 @@
+package product
+
+import (
+	"context"
+	"sync"
+)
+
+// Observer is notified of every use case execution, e.g. to record metrics or traces.
+// Start is called before the use case runs and may return a derived context;
+// the returned function is called with the use case's error once it finishes.
+type Observer interface {
+	Start(ctx context.Context, useCase string) (context.Context, func(err error))
+}
+
+var (
+	observersMutex sync.RWMutex
+	observers      []Observer
+)
+
+// AddObserver registers an observer for every use case and returns a function removing it
+func AddObserver(o Observer) (remove func()) {
+	observersMutex.Lock()
+	defer observersMutex.Unlock()
+
+	observers = append(observers, o)
+	return func() {
+		observersMutex.Lock()
+		defer observersMutex.Unlock()
+
+		for i, registered := range observers {
+			if registered == o {
+				observers = append(observers[:i:i], observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// observe notifies the registered observers that a use case starts
+func observe(ctx context.Context, useCase string) (context.Context, func(err error)) {
+	observersMutex.RLock()
+	registered := observers
+	observersMutex.RUnlock()
+
+	if len(registered) == 0 {
+		return ctx, func(error) {}
+	}
+
+	ends := make([]func(error), 0, len(registered))
+	for _, o := range registered {
+		var end func(error)
+		ctx, end = o.Start(ctx, useCase)
+		ends = append(ends, end)
+	}
+
+	return ctx, func(err error) {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package product
+
+import (
+	"context"
+	"errors"
+
+	domain "sago-sample/feature/product/domain"
+)
+
+// PatchProductInput represents the input data for partially updating a product.
+// Nil fields keep their current value.
+type PatchProductInput struct {
+	ID          string
+	Name        *string
+	Description *string
+	Price       *uint
+	Currency    *string
+	Stock       *uint
+}
+
+// PatchProductUseCase defines the use case for partially updating a product
+type PatchProductUseCase struct {
+	productService *domain.Service
+}
+
+// NewPatchProductUseCase creates a new instance of PatchProductUseCase
+func NewPatchProductUseCase(productService *domain.Service) *PatchProductUseCase {
+	return &PatchProductUseCase{
+		productService: productService,
+	}
+}
+
+// Execute runs the use case
+func (uc *PatchProductUseCase) Execute(ctx context.Context, input PatchProductInput) (_ *ProductOutput, err error) {
+	ctx, done := observe(ctx, "PatchProduct")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := uc.productService.GetProductByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	// Start from the current values and apply the given fields
+	name := current.Name()
+	if input.Name != nil {
+		if name, err = domain.NewProductName(*input.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	description := current.Description()
+	if input.Description != nil {
+		if description, err = domain.NewProductDescription(*input.Description); err != nil {
+			return nil, err
+		}
+	}
+
+	price := current.Price()
+	if input.Price != nil || input.Currency != nil {
+		amount, currency := price.Amount(), price.Currency()
+		if input.Price != nil {
+			amount = *input.Price
+		}
+		if input.Currency != nil {
+			currency = *input.Currency
+		}
+		if price, err = domain.NewPrice(amount, currency); err != nil {
+			return nil, err
+		}
+	}
+
+	stock := current.Stock()
+	if input.Stock != nil {
+		stock = domain.NewStock(*input.Stock)
+	}
+
+	updatedProduct, err := uc.productService.UpdateProduct(ctx, productID, name, description, price, stock)
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	categories := make([]CategoryOutput, 0, len(updatedProduct.Categories()))
+	for _, c := range updatedProduct.Categories() {
+		categories = append(categories, CategoryOutput{
+			ID:   c.ID().String(),
+			Name: c.Name().String(),
+		})
+	}
+
+	return &ProductOutput{
+		ID:             updatedProduct.ID().String(),
+		Name:           updatedProduct.Name().String(),
+		Description:    updatedProduct.Description().String(),
+		Price:          updatedProduct.Price().Amount(),
+		Currency:       updatedProduct.Price().Currency(),
+		Status:         updatedProduct.Status().String(),
+		AvailableFrom:  optionalTime(updatedProduct.Availability().From()),
+		AvailableUntil: optionalTime(updatedProduct.Availability().Until()),
+		Attributes:     updatedProduct.Attributes(),
+		Images:         toImageOutputs(updatedProduct),
+		Stock:          updatedProduct.Stock().Quantity(),
+		Categories:     categories,
+		UpdatedAt:      updatedProduct.UpdatedAt(),
+	}, nil
+}
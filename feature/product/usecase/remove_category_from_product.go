@@ -18,6 +18,7 @@ type RemoveCategoryFromProductOutput struct {
 	Description string
 	Price       uint
 	Currency    string
+	Status      string
 	Stock       uint
 	Categories  []CategoryOutput
 }
@@ -32,7 +33,10 @@ func NewRemoveCategoryFromProductUseCase(productService *domain.Service) *Remove
 	}
 }
 
-func (uc *RemoveCategoryFromProductUseCase) Execute(ctx context.Context, input RemoveCategoryFromProductInput) (*RemoveCategoryFromProductOutput, error) {
+func (uc *RemoveCategoryFromProductUseCase) Execute(ctx context.Context, input RemoveCategoryFromProductInput) (_ *RemoveCategoryFromProductOutput, err error) {
+	ctx, done := observe(ctx, "RemoveCategoryFromProduct")
+	defer func() { done(err) }()
+
 	productID, err := domain.NewProductID(input.ProductID)
 	if err != nil {
 		return nil, err
@@ -46,7 +50,7 @@ func (uc *RemoveCategoryFromProductUseCase) Execute(ctx context.Context, input R
 	updatedProduct, err := uc.productService.RemoveCategoryFromProduct(ctx, productID, categoryID)
 	if err != nil {
 		if errors.Is(err, domain.ErrProductNotFound) {
-			return nil, errors.New("product not found")
+			return nil, domain.ErrProductNotFound
 		}
 		return nil, err
 	}
@@ -65,6 +69,7 @@ func (uc *RemoveCategoryFromProductUseCase) Execute(ctx context.Context, input R
 		Description: updatedProduct.Description().String(),
 		Price:       updatedProduct.Price().Amount(),
 		Currency:    updatedProduct.Price().Currency(),
+		Status:      updatedProduct.Status().String(),
 		Stock:       updatedProduct.Stock().Quantity(),
 		Categories:  categories,
 	}, nil
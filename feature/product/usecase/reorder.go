@@ -0,0 +1,186 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	domain "sago-sample/feature/product/domain"
+)
+
+// ReorderPolicyOutput represents the reorder policy of a product
+type ReorderPolicyOutput struct {
+	ProductID string
+	Threshold uint
+	Target    uint
+}
+
+// toReorderPolicyOutput maps a reorder policy to its output
+func toReorderPolicyOutput(p *domain.ReorderPolicy) *ReorderPolicyOutput {
+	return &ReorderPolicyOutput{
+		ProductID: p.ProductID().String(),
+		Threshold: p.Threshold(),
+		Target:    p.Target(),
+	}
+}
+
+// SetReorderPolicyInput represents the input data for setting the reorder policy of a product
+type SetReorderPolicyInput struct {
+	ProductID string
+	Threshold uint
+	Target    uint
+}
+
+// SetReorderPolicyUseCase defines the use case for setting the reorder policy of a product
+type SetReorderPolicyUseCase struct {
+	repo     domain.Repository
+	policies domain.ReorderPolicyRepository
+}
+
+// NewSetReorderPolicyUseCase creates a new instance of SetReorderPolicyUseCase
+func NewSetReorderPolicyUseCase(repo domain.Repository, policies domain.ReorderPolicyRepository) *SetReorderPolicyUseCase {
+	return &SetReorderPolicyUseCase{repo: repo, policies: policies}
+}
+
+// Execute runs the use case
+func (uc *SetReorderPolicyUseCase) Execute(ctx context.Context, input SetReorderPolicyInput) (_ *ReorderPolicyOutput, err error) {
+	ctx, done := observe(ctx, "SetReorderPolicy")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := domain.NewReorderPolicy(productID, input.Threshold, input.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.repo.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	if err := uc.policies.Save(ctx, policy); err != nil {
+		return nil, err
+	}
+	return toReorderPolicyOutput(policy), nil
+}
+
+// GetReorderPolicyUseCase defines the use case for getting the reorder policy of a product
+type GetReorderPolicyUseCase struct {
+	policies domain.ReorderPolicyRepository
+}
+
+// NewGetReorderPolicyUseCase creates a new instance of GetReorderPolicyUseCase
+func NewGetReorderPolicyUseCase(policies domain.ReorderPolicyRepository) *GetReorderPolicyUseCase {
+	return &GetReorderPolicyUseCase{policies: policies}
+}
+
+// Execute runs the use case
+func (uc *GetReorderPolicyUseCase) Execute(ctx context.Context, productID string) (_ *ReorderPolicyOutput, err error) {
+	ctx, done := observe(ctx, "GetReorderPolicy")
+	defer func() { done(err) }()
+
+	id, err := domain.NewProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := uc.policies.FindByProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toReorderPolicyOutput(policy), nil
+}
+
+// DeleteReorderPolicyUseCase defines the use case for removing the reorder policy of a product
+type DeleteReorderPolicyUseCase struct {
+	policies domain.ReorderPolicyRepository
+}
+
+// NewDeleteReorderPolicyUseCase creates a new instance of DeleteReorderPolicyUseCase
+func NewDeleteReorderPolicyUseCase(policies domain.ReorderPolicyRepository) *DeleteReorderPolicyUseCase {
+	return &DeleteReorderPolicyUseCase{policies: policies}
+}
+
+// Execute runs the use case
+func (uc *DeleteReorderPolicyUseCase) Execute(ctx context.Context, productID string) (err error) {
+	ctx, done := observe(ctx, "DeleteReorderPolicy")
+	defer func() { done(err) }()
+
+	id, err := domain.NewProductID(productID)
+	if err != nil {
+		return err
+	}
+	return uc.policies.Delete(ctx, id)
+}
+
+// ReorderSuggestionOutput represents a product to reorder
+type ReorderSuggestionOutput struct {
+	ProductID   string
+	ProductName string
+	// Status is low_stock or out_of_stock
+	Status    string
+	Stock     uint
+	Threshold uint
+	Target    uint
+	// SuggestedQuantity brings the stock back up to the target
+	SuggestedQuantity uint
+}
+
+// GetReorderSuggestionsUseCase defines the use case for listing the products at or below their reorder threshold,
+// out-of-stock products first, then by product ID
+type GetReorderSuggestionsUseCase struct {
+	repo     domain.Repository
+	policies domain.ReorderPolicyRepository
+}
+
+// NewGetReorderSuggestionsUseCase creates a new instance of GetReorderSuggestionsUseCase
+func NewGetReorderSuggestionsUseCase(repo domain.Repository, policies domain.ReorderPolicyRepository) *GetReorderSuggestionsUseCase {
+	return &GetReorderSuggestionsUseCase{repo: repo, policies: policies}
+}
+
+// Execute runs the use case
+func (uc *GetReorderSuggestionsUseCase) Execute(ctx context.Context) (_ []ReorderSuggestionOutput, err error) {
+	ctx, done := observe(ctx, "GetReorderSuggestions")
+	defer func() { done(err) }()
+
+	policies, err := uc.policies.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []ReorderSuggestionOutput{}
+	for _, policy := range policies {
+		p, err := uc.repo.FindByID(ctx, policy.ProductID())
+		if errors.Is(err, domain.ErrProductNotFound) {
+			// The policy outlived its product
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		quantity := policy.SuggestedQuantity(p.Stock())
+		if quantity == 0 {
+			continue
+		}
+		suggestions = append(suggestions, ReorderSuggestionOutput{
+			ProductID:         p.ID().String(),
+			ProductName:       p.Name().String(),
+			Status:            domain.StatusOf(p.Stock(), policy).String(),
+			Stock:             p.Stock().Quantity(),
+			Threshold:         policy.Threshold(),
+			Target:            policy.Target(),
+			SuggestedQuantity: quantity,
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		iOut, jOut := suggestions[i].Stock == 0, suggestions[j].Stock == 0
+		if iOut != jOut {
+			return iOut
+		}
+		return suggestions[i].ProductID < suggestions[j].ProductID
+	})
+	return suggestions, nil
+}
@@ -0,0 +1,259 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/observability/logging"
+)
+
+// MovementOutput represents an entry of the stock ledger
+type MovementOutput struct {
+	ID          string
+	WarehouseID string
+	Type        string
+	// Quantity is the change of stock, negative for decreases
+	Quantity int
+	// Balance is the product's total stock according to the ledger after the movement
+	Balance    int
+	Reason     string
+	Actor      string
+	OccurredAt time.Time
+}
+
+// ListStockMovementsInput represents the input data for listing the stock ledger of a product
+type ListStockMovementsInput struct {
+	ProductID string
+	// Type and WarehouseID only keep the matching movements when set
+	Type        string
+	WarehouseID string
+}
+
+// ListStockMovementsUseCase defines the use case for listing the stock ledger of a product, oldest first
+type ListStockMovementsUseCase struct {
+	repo      domain.Repository
+	movements domain.MovementRepository
+}
+
+// NewListStockMovementsUseCase creates a new instance of ListStockMovementsUseCase
+func NewListStockMovementsUseCase(repo domain.Repository, movements domain.MovementRepository) *ListStockMovementsUseCase {
+	return &ListStockMovementsUseCase{repo: repo, movements: movements}
+}
+
+// Execute runs the use case
+func (uc *ListStockMovementsUseCase) Execute(ctx context.Context, input ListStockMovementsInput) (_ []MovementOutput, err error) {
+	ctx, done := observe(ctx, "ListStockMovements")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Type != "" {
+		if _, err := domain.NewMovementType(input.Type); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := uc.repo.FindByID(ctx, productID); err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	movements, err := uc.movements.FindByProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]MovementOutput, 0, len(movements))
+	balance := 0
+	for _, m := range movements {
+		// The balance covers every movement, including the ones filtered out
+		balance += m.Quantity()
+		if input.Type != "" && m.Type().String() != input.Type {
+			continue
+		}
+		if input.WarehouseID != "" && m.WarehouseID().String() != input.WarehouseID {
+			continue
+		}
+		outputs = append(outputs, MovementOutput{
+			ID:          m.ID(),
+			WarehouseID: m.WarehouseID().String(),
+			Type:        m.Type().String(),
+			Quantity:    m.Quantity(),
+			Balance:     balance,
+			Reason:      m.Reason(),
+			Actor:       m.Actor(),
+			OccurredAt:  m.OccurredAt(),
+		})
+	}
+	return outputs, nil
+}
+
+// RecordStockMovementInput represents the input data for recording a stock movement
+type RecordStockMovementInput struct {
+	ProductID string
+	// WarehouseID defaults to the default warehouse
+	WarehouseID string
+	Type        string
+	// Quantity is the number of units received, sold, returned or reserved, or the signed change of an adjustment
+	Quantity int
+	Reason   string
+}
+
+// RecordStockMovementUseCase defines the use case for changing the stock of a product through a typed movement
+type RecordStockMovementUseCase struct {
+	inventoryService *domain.InventoryService
+}
+
+// NewRecordStockMovementUseCase creates a new instance of RecordStockMovementUseCase
+func NewRecordStockMovementUseCase(inventoryService *domain.InventoryService) *RecordStockMovementUseCase {
+	return &RecordStockMovementUseCase{inventoryService: inventoryService}
+}
+
+// Execute runs the use case
+func (uc *RecordStockMovementUseCase) Execute(ctx context.Context, input RecordStockMovementInput) (_ *InventoryOutput, err error) {
+	ctx, done := observe(ctx, "RecordStockMovement")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	warehouseID := domain.DefaultWarehouseID
+	if input.WarehouseID != "" {
+		if warehouseID, err = domain.NewWarehouseID(input.WarehouseID); err != nil {
+			return nil, err
+		}
+	}
+	movementType, err := domain.NewMovementType(input.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity := input.Quantity
+	if movementType != domain.MovementAdjustment {
+		if quantity <= 0 {
+			return nil, domain.NewValidationError("quantity must be positive")
+		}
+		if movementType.Decreases() {
+			quantity = -quantity
+		}
+	}
+
+	p, err := uc.inventoryService.RecordMovement(ctx, productID, warehouseID, movementType, quantity, input.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	output := toInventoryOutput(p)
+	return &output, nil
+}
+
+// StockDriftOutput represents a warehouse where the stock of a product differs from its ledger
+type StockDriftOutput struct {
+	ProductID   string
+	WarehouseID string
+	Ledger      int
+	Stock       uint
+	// Difference is Stock minus Ledger
+	Difference int
+}
+
+// ReconcileStockOutput represents the result of a reconciliation
+type ReconcileStockOutput struct {
+	Products int
+	Drifts   []StockDriftOutput
+}
+
+// ReconcileStockUseCase defines the use case for comparing the stock of every product with its ledger
+type ReconcileStockUseCase struct {
+	repo       domain.Repository
+	movements  domain.MovementRepository
+	transactor domain.Transactor
+}
+
+// NewReconcileStockUseCase creates a new instance of ReconcileStockUseCase
+func NewReconcileStockUseCase(repo domain.Repository, movements domain.MovementRepository) *ReconcileStockUseCase {
+	return &ReconcileStockUseCase{repo: repo, movements: movements, transactor: domain.NoTransaction}
+}
+
+// SetTransactor replaces the transactor the products and the ledger are read in, so that both are read from one snapshot
+// and the changes made during a run are not reported as drifts
+func (uc *ReconcileStockUseCase) SetTransactor(transactor domain.Transactor) {
+	uc.transactor = transactor
+}
+
+// Execute runs the use case
+func (uc *ReconcileStockUseCase) Execute(ctx context.Context) (_ *ReconcileStockOutput, err error) {
+	ctx, done := observe(ctx, "ReconcileStock")
+	defer func() { done(err) }()
+
+	var products []*domain.Product
+	var balances map[domain.ProductID]map[domain.WarehouseID]int
+	err = uc.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if products, err = uc.repo.FindAll(ctx); err != nil {
+			return err
+		}
+		balances, err = uc.movements.Balances(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].ID().String() < products[j].ID().String()
+	})
+
+	output := &ReconcileStockOutput{Products: len(products), Drifts: []StockDriftOutput{}}
+	for _, p := range products {
+		for _, d := range domain.Reconcile(p, balances[p.ID()]) {
+			output.Drifts = append(output.Drifts, StockDriftOutput{
+				ProductID:   d.ProductID.String(),
+				WarehouseID: d.WarehouseID.String(),
+				Ledger:      d.Ledger,
+				Stock:       d.Stock,
+				Difference:  d.Difference(),
+			})
+		}
+	}
+	return output, nil
+}
+
+// RunEvery reconciles the stock every interval until ctx is done, logging every drift as a warning
+func (uc *ReconcileStockUseCase) RunEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := logging.FromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		output, err := uc.Execute(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error("stock reconciliation failed", "error", err)
+			}
+			continue
+		}
+		for _, d := range output.Drifts {
+			logger.Warn("stock differs from ledger",
+				"product_id", d.ProductID,
+				"warehouse_id", d.WarehouseID,
+				"ledger", d.Ledger,
+				"stock", d.Stock,
+			)
+		}
+		logger.Info("stock reconciled", "products", output.Products, "drifts", len(output.Drifts))
+	}
+}
@@ -0,0 +1,339 @@
+package product
+
+import (
+	"context"
+	"errors"
+
+	domain "sago-sample/feature/product/domain"
+)
+
+// ProductTranslationOutput represents the texts of a product in one locale
+type ProductTranslationOutput struct {
+	Name string
+	// Description is empty when the default description is shown in the locale
+	Description string
+}
+
+// ProductTranslationsOutput represents the translations of a product
+type ProductTranslationsOutput struct {
+	ProductID string
+	// DefaultLocale is the locale of the product's own name and description
+	DefaultLocale string
+	// Translations are keyed by locale
+	Translations map[string]ProductTranslationOutput
+}
+
+// toProductTranslationsOutput maps the translations of a product to their output
+func toProductTranslationsOutput(p *domain.Product, defaultLocale domain.Locale) *ProductTranslationsOutput {
+	translations := make(map[string]ProductTranslationOutput, len(p.Translations()))
+	for locale, t := range p.Translations() {
+		translations[locale.String()] = ProductTranslationOutput{Name: t.Name().String(), Description: t.Description().String()}
+	}
+	return &ProductTranslationsOutput{
+		ProductID:     p.ID().String(),
+		DefaultLocale: defaultLocale.String(),
+		Translations:  translations,
+	}
+}
+
+// CategoryTranslationsOutput represents the translated names of a category
+type CategoryTranslationsOutput struct {
+	CategoryID string
+	// DefaultLocale is the locale of the category's own name
+	DefaultLocale string
+	// Names are keyed by locale
+	Names map[string]string
+}
+
+// toCategoryTranslationsOutput maps the translations of a category to their output
+func toCategoryTranslationsOutput(t *domain.CategoryTranslations, defaultLocale domain.Locale) *CategoryTranslationsOutput {
+	names := make(map[string]string, len(t.Names()))
+	for locale, name := range t.Names() {
+		names[locale.String()] = name.String()
+	}
+	return &CategoryTranslationsOutput{
+		CategoryID:    t.CategoryID().String(),
+		DefaultLocale: defaultLocale.String(),
+		Names:         names,
+	}
+}
+
+// translationError returns the bare not-found sentinels of the translation service, without the context wrapped around them
+func translationError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound):
+		return domain.ErrProductNotFound
+	case errors.Is(err, domain.ErrTranslationNotFound):
+		return domain.ErrTranslationNotFound
+	default:
+		return err
+	}
+}
+
+// localizeProducts replaces the names and descriptions of outputs, built from products in the same order, with their
+// texts in locale. Category names are translated when translations is set. The empty locale keeps the default texts.
+func localizeProducts(ctx context.Context, translations *domain.TranslationService, rawLocale string, products []*domain.Product, outputs []ProductOutput) error {
+	if rawLocale == "" {
+		return nil
+	}
+	locale, err := domain.NewLocale(rawLocale)
+	if err != nil {
+		return err
+	}
+
+	var categoryNames map[domain.CategoryID]domain.CategoryName
+	if translations != nil {
+		if categoryNames, err = translations.CategoryNames(ctx, locale, products); err != nil {
+			return err
+		}
+	}
+	for i, p := range products {
+		outputs[i].Name = p.LocalizedName(locale).String()
+		outputs[i].Description = p.LocalizedDescription(locale).String()
+		for j, c := range outputs[i].Categories {
+			if name, ok := categoryNames[domain.CategoryID(c.ID)]; ok {
+				outputs[i].Categories[j].Name = name.String()
+			}
+		}
+	}
+	return nil
+}
+
+// GetProductTranslationsInput represents the input for getting the translations of a product
+type GetProductTranslationsInput struct {
+	ProductID string
+}
+
+// GetProductTranslationsUseCase defines the use case for getting the translations of a product
+type GetProductTranslationsUseCase struct {
+	translationService *domain.TranslationService
+}
+
+// NewGetProductTranslationsUseCase creates a new instance of GetProductTranslationsUseCase
+func NewGetProductTranslationsUseCase(translationService *domain.TranslationService) *GetProductTranslationsUseCase {
+	return &GetProductTranslationsUseCase{translationService: translationService}
+}
+
+// Execute runs the use case
+func (uc *GetProductTranslationsUseCase) Execute(ctx context.Context, input GetProductTranslationsInput) (_ *ProductTranslationsOutput, err error) {
+	ctx, done := observe(ctx, "GetProductTranslations")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	foundProduct, err := uc.translationService.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, translationError(err)
+	}
+
+	return toProductTranslationsOutput(foundProduct, uc.translationService.Locales().Default()), nil
+}
+
+// SetProductTranslationInput represents the input for translating a product to a locale
+type SetProductTranslationInput struct {
+	ProductID string
+	Locale    string
+	Name      string
+	// Description, when empty, leaves the default description shown in the locale
+	Description string
+}
+
+// SetProductTranslationUseCase defines the use case for adding or replacing the translation of a product in a locale
+type SetProductTranslationUseCase struct {
+	translationService *domain.TranslationService
+}
+
+// NewSetProductTranslationUseCase creates a new instance of SetProductTranslationUseCase
+func NewSetProductTranslationUseCase(translationService *domain.TranslationService) *SetProductTranslationUseCase {
+	return &SetProductTranslationUseCase{translationService: translationService}
+}
+
+// Execute runs the use case
+func (uc *SetProductTranslationUseCase) Execute(ctx context.Context, input SetProductTranslationInput) (_ *ProductTranslationsOutput, err error) {
+	ctx, done := observe(ctx, "SetProductTranslation")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	locale, err := domain.NewLocale(input.Locale)
+	if err != nil {
+		return nil, err
+	}
+	// The translated texts follow the length rules of the default ones
+	name, err := domain.NewProductName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	description, err := domain.NewProductDescription(input.Description)
+	if err != nil {
+		return nil, err
+	}
+	translation, err := domain.NewProductTranslation(name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedProduct, err := uc.translationService.SetProductTranslation(ctx, productID, locale, translation)
+	if err != nil {
+		return nil, translationError(err)
+	}
+
+	return toProductTranslationsOutput(updatedProduct, uc.translationService.Locales().Default()), nil
+}
+
+// RemoveProductTranslationInput represents the input for removing the translation of a product in a locale
+type RemoveProductTranslationInput struct {
+	ProductID string
+	Locale    string
+}
+
+// RemoveProductTranslationUseCase defines the use case for removing the translation of a product in a locale
+type RemoveProductTranslationUseCase struct {
+	translationService *domain.TranslationService
+}
+
+// NewRemoveProductTranslationUseCase creates a new instance of RemoveProductTranslationUseCase
+func NewRemoveProductTranslationUseCase(translationService *domain.TranslationService) *RemoveProductTranslationUseCase {
+	return &RemoveProductTranslationUseCase{translationService: translationService}
+}
+
+// Execute runs the use case
+func (uc *RemoveProductTranslationUseCase) Execute(ctx context.Context, input RemoveProductTranslationInput) (_ *ProductTranslationsOutput, err error) {
+	ctx, done := observe(ctx, "RemoveProductTranslation")
+	defer func() { done(err) }()
+
+	productID, err := domain.NewProductID(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	locale, err := domain.NewLocale(input.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedProduct, err := uc.translationService.RemoveProductTranslation(ctx, productID, locale)
+	if err != nil {
+		return nil, translationError(err)
+	}
+
+	return toProductTranslationsOutput(updatedProduct, uc.translationService.Locales().Default()), nil
+}
+
+// GetCategoryTranslationsInput represents the input for getting the translated names of a category
+type GetCategoryTranslationsInput struct {
+	CategoryID string
+}
+
+// GetCategoryTranslationsUseCase defines the use case for getting the translated names of a category
+type GetCategoryTranslationsUseCase struct {
+	translationService *domain.TranslationService
+}
+
+// NewGetCategoryTranslationsUseCase creates a new instance of GetCategoryTranslationsUseCase
+func NewGetCategoryTranslationsUseCase(translationService *domain.TranslationService) *GetCategoryTranslationsUseCase {
+	return &GetCategoryTranslationsUseCase{translationService: translationService}
+}
+
+// Execute runs the use case
+func (uc *GetCategoryTranslationsUseCase) Execute(ctx context.Context, input GetCategoryTranslationsInput) (_ *CategoryTranslationsOutput, err error) {
+	ctx, done := observe(ctx, "GetCategoryTranslations")
+	defer func() { done(err) }()
+
+	categoryID, err := domain.NewCategoryID(input.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	translations, err := uc.translationService.GetCategoryTranslations(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCategoryTranslationsOutput(translations, uc.translationService.Locales().Default()), nil
+}
+
+// SetCategoryTranslationInput represents the input for translating the name of a category to a locale
+type SetCategoryTranslationInput struct {
+	CategoryID string
+	Locale     string
+	Name       string
+}
+
+// SetCategoryTranslationUseCase defines the use case for adding or replacing the name of a category in a locale
+type SetCategoryTranslationUseCase struct {
+	translationService *domain.TranslationService
+}
+
+// NewSetCategoryTranslationUseCase creates a new instance of SetCategoryTranslationUseCase
+func NewSetCategoryTranslationUseCase(translationService *domain.TranslationService) *SetCategoryTranslationUseCase {
+	return &SetCategoryTranslationUseCase{translationService: translationService}
+}
+
+// Execute runs the use case
+func (uc *SetCategoryTranslationUseCase) Execute(ctx context.Context, input SetCategoryTranslationInput) (_ *CategoryTranslationsOutput, err error) {
+	ctx, done := observe(ctx, "SetCategoryTranslation")
+	defer func() { done(err) }()
+
+	categoryID, err := domain.NewCategoryID(input.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	locale, err := domain.NewLocale(input.Locale)
+	if err != nil {
+		return nil, err
+	}
+	name, err := domain.NewCategoryName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	translations, err := uc.translationService.SetCategoryTranslation(ctx, categoryID, locale, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCategoryTranslationsOutput(translations, uc.translationService.Locales().Default()), nil
+}
+
+// RemoveCategoryTranslationInput represents the input for removing the name of a category in a locale
+type RemoveCategoryTranslationInput struct {
+	CategoryID string
+	Locale     string
+}
+
+// RemoveCategoryTranslationUseCase defines the use case for removing the name of a category in a locale
+type RemoveCategoryTranslationUseCase struct {
+	translationService *domain.TranslationService
+}
+
+// NewRemoveCategoryTranslationUseCase creates a new instance of RemoveCategoryTranslationUseCase
+func NewRemoveCategoryTranslationUseCase(translationService *domain.TranslationService) *RemoveCategoryTranslationUseCase {
+	return &RemoveCategoryTranslationUseCase{translationService: translationService}
+}
+
+// Execute runs the use case
+func (uc *RemoveCategoryTranslationUseCase) Execute(ctx context.Context, input RemoveCategoryTranslationInput) (_ *CategoryTranslationsOutput, err error) {
+	ctx, done := observe(ctx, "RemoveCategoryTranslation")
+	defer func() { done(err) }()
+
+	categoryID, err := domain.NewCategoryID(input.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	locale, err := domain.NewLocale(input.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	translations, err := uc.translationService.RemoveCategoryTranslation(ctx, categoryID, locale)
+	if err != nil {
+		return nil, translationError(err)
+	}
+
+	return toCategoryTranslationsOutput(translations, uc.translationService.Locales().Default()), nil
+}
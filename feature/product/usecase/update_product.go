@@ -24,7 +24,9 @@ type UpdateProductOutput struct {
 	Description string
 	Price       uint
 	Currency    string
+	Status      string
 	Stock       uint
+	Categories  []CategoryOutput
 }
 
 // UpdateProductUseCase defines the use case for updating a product
@@ -40,7 +42,10 @@ func NewUpdateProductUseCase(productService *domain.Service) *UpdateProductUseCa
 }
 
 // Execute runs the use case
-func (uc *UpdateProductUseCase) Execute(ctx context.Context, input UpdateProductInput) (*UpdateProductOutput, error) {
+func (uc *UpdateProductUseCase) Execute(ctx context.Context, input UpdateProductInput) (_ *UpdateProductOutput, err error) {
+	ctx, done := observe(ctx, "UpdateProduct")
+	defer func() { done(err) }()
+
 	productID, err := domain.NewProductID(input.ID)
 	if err != nil {
 		return nil, err
@@ -66,17 +71,27 @@ func (uc *UpdateProductUseCase) Execute(ctx context.Context, input UpdateProduct
 	updatedProduct, err := uc.productService.UpdateProduct(ctx, productID, productName, productDescription, price, stock)
 	if err != nil {
 		if errors.Is(err, domain.ErrProductNotFound) {
-			return nil, errors.New("product not found")
+			return nil, domain.ErrProductNotFound
 		}
 		return nil, err
 	}
 
+	categories := make([]CategoryOutput, 0, len(updatedProduct.Categories()))
+	for _, c := range updatedProduct.Categories() {
+		categories = append(categories, CategoryOutput{
+			ID:   c.ID().String(),
+			Name: c.Name().String(),
+		})
+	}
+
 	return &UpdateProductOutput{
 		ID:          updatedProduct.ID().String(),
 		Name:        updatedProduct.Name().String(),
 		Description: updatedProduct.Description().String(),
 		Price:       updatedProduct.Price().Amount(),
 		Currency:    updatedProduct.Price().Currency(),
+		Status:      updatedProduct.Status().String(),
 		Stock:       updatedProduct.Stock().Quantity(),
+		Categories:  categories,
 	}, nil
 }
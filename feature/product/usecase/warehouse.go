@@ -0,0 +1,196 @@
+package product
+
+import (
+	"context"
+
+	domain "sago-sample/feature/product/domain"
+)
+
+// WarehouseOutput represents a warehouse returned by the use cases
+type WarehouseOutput struct {
+	ID   string
+	Name string
+	// Latitude and Longitude are nil when the location is unknown
+	Latitude  *float64
+	Longitude *float64
+}
+
+// toWarehouseOutput maps a warehouse to its output
+func toWarehouseOutput(w *domain.Warehouse) WarehouseOutput {
+	output := WarehouseOutput{ID: w.ID().String(), Name: w.Name().String()}
+	if loc := w.Location(); loc != nil {
+		latitude, longitude := loc.Latitude(), loc.Longitude()
+		output.Latitude, output.Longitude = &latitude, &longitude
+	}
+	return output
+}
+
+// WarehouseInput represents the input data for creating or updating a warehouse
+type WarehouseInput struct {
+	ID   string
+	Name string
+	// Latitude and Longitude are either both set or both nil
+	Latitude  *float64
+	Longitude *float64
+}
+
+// values validates the input and returns its value objects
+func (input WarehouseInput) values() (domain.WarehouseID, domain.WarehouseName, *domain.Location, error) {
+	id, err := domain.NewWarehouseID(input.ID)
+	if err != nil {
+		return "", "", nil, err
+	}
+	name, err := domain.NewWarehouseName(input.Name)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if (input.Latitude == nil) != (input.Longitude == nil) {
+		return "", "", nil, domain.NewValidationError("latitude and longitude must be set together")
+	}
+	if input.Latitude == nil {
+		return id, name, nil, nil
+	}
+	location, err := domain.NewLocation(*input.Latitude, *input.Longitude)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return id, name, &location, nil
+}
+
+// CreateWarehouseUseCase defines the use case for creating a warehouse
+type CreateWarehouseUseCase struct {
+	inventoryService *domain.InventoryService
+}
+
+// NewCreateWarehouseUseCase creates a new instance of CreateWarehouseUseCase
+func NewCreateWarehouseUseCase(inventoryService *domain.InventoryService) *CreateWarehouseUseCase {
+	return &CreateWarehouseUseCase{inventoryService: inventoryService}
+}
+
+// Execute runs the use case
+func (uc *CreateWarehouseUseCase) Execute(ctx context.Context, input WarehouseInput) (_ *WarehouseOutput, err error) {
+	ctx, done := observe(ctx, "CreateWarehouse")
+	defer func() { done(err) }()
+
+	id, name, location, err := input.values()
+	if err != nil {
+		return nil, err
+	}
+
+	warehouse, err := uc.inventoryService.CreateWarehouse(ctx, id, name, location)
+	if err != nil {
+		return nil, err
+	}
+
+	output := toWarehouseOutput(warehouse)
+	return &output, nil
+}
+
+// UpdateWarehouseUseCase defines the use case for updating a warehouse
+type UpdateWarehouseUseCase struct {
+	inventoryService *domain.InventoryService
+}
+
+// NewUpdateWarehouseUseCase creates a new instance of UpdateWarehouseUseCase
+func NewUpdateWarehouseUseCase(inventoryService *domain.InventoryService) *UpdateWarehouseUseCase {
+	return &UpdateWarehouseUseCase{inventoryService: inventoryService}
+}
+
+// Execute runs the use case
+func (uc *UpdateWarehouseUseCase) Execute(ctx context.Context, input WarehouseInput) (_ *WarehouseOutput, err error) {
+	ctx, done := observe(ctx, "UpdateWarehouse")
+	defer func() { done(err) }()
+
+	id, name, location, err := input.values()
+	if err != nil {
+		return nil, err
+	}
+
+	warehouse, err := uc.inventoryService.UpdateWarehouse(ctx, id, name, location)
+	if err != nil {
+		return nil, err
+	}
+
+	output := toWarehouseOutput(warehouse)
+	return &output, nil
+}
+
+// GetWarehouseUseCase defines the use case for getting a warehouse
+type GetWarehouseUseCase struct {
+	inventoryService *domain.InventoryService
+}
+
+// NewGetWarehouseUseCase creates a new instance of GetWarehouseUseCase
+func NewGetWarehouseUseCase(inventoryService *domain.InventoryService) *GetWarehouseUseCase {
+	return &GetWarehouseUseCase{inventoryService: inventoryService}
+}
+
+// Execute runs the use case
+func (uc *GetWarehouseUseCase) Execute(ctx context.Context, id string) (_ *WarehouseOutput, err error) {
+	ctx, done := observe(ctx, "GetWarehouse")
+	defer func() { done(err) }()
+
+	warehouseID, err := domain.NewWarehouseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouse, err := uc.inventoryService.GetWarehouse(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	output := toWarehouseOutput(warehouse)
+	return &output, nil
+}
+
+// ListWarehousesUseCase defines the use case for listing warehouses
+type ListWarehousesUseCase struct {
+	inventoryService *domain.InventoryService
+}
+
+// NewListWarehousesUseCase creates a new instance of ListWarehousesUseCase
+func NewListWarehousesUseCase(inventoryService *domain.InventoryService) *ListWarehousesUseCase {
+	return &ListWarehousesUseCase{inventoryService: inventoryService}
+}
+
+// Execute runs the use case
+func (uc *ListWarehousesUseCase) Execute(ctx context.Context) (_ []WarehouseOutput, err error) {
+	ctx, done := observe(ctx, "ListWarehouses")
+	defer func() { done(err) }()
+
+	warehouses, err := uc.inventoryService.GetAllWarehouses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]WarehouseOutput, 0, len(warehouses))
+	for _, w := range warehouses {
+		outputs = append(outputs, toWarehouseOutput(w))
+	}
+	return outputs, nil
+}
+
+// DeleteWarehouseUseCase defines the use case for deleting a warehouse
+type DeleteWarehouseUseCase struct {
+	inventoryService *domain.InventoryService
+}
+
+// NewDeleteWarehouseUseCase creates a new instance of DeleteWarehouseUseCase
+func NewDeleteWarehouseUseCase(inventoryService *domain.InventoryService) *DeleteWarehouseUseCase {
+	return &DeleteWarehouseUseCase{inventoryService: inventoryService}
+}
+
+// Execute runs the use case
+func (uc *DeleteWarehouseUseCase) Execute(ctx context.Context, id string) (err error) {
+	ctx, done := observe(ctx, "DeleteWarehouse")
+	defer func() { done(err) }()
+
+	warehouseID, err := domain.NewWarehouseID(id)
+	if err != nil {
+		return err
+	}
+
+	return uc.inventoryService.DeleteWarehouse(ctx, warehouseID)
+}
@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"errors"
+	"time"
+
+	product "sago-sample/feature/product/domain"
+)
+
+var ErrDeliveryNotFound = errors.New("delivery not found")
+
+// DeliveryStatus is the state of a delivery
+type DeliveryStatus string
+
+const (
+	// DeliveryPending is waiting for its first attempt or a retry
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliverySucceeded was acknowledged with a 2xx response
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryDeadLettered failed too many times and will not be retried
+	DeliveryDeadLettered DeliveryStatus = "dead_lettered"
+)
+
+// IsValid checks if the status is a known delivery status
+func (s DeliveryStatus) IsValid() bool {
+	switch s {
+	case DeliveryPending, DeliverySucceeded, DeliveryDeadLettered:
+		return true
+	}
+	return false
+}
+
+// Delivery is one event sent to one subscription, along with the outcome of its attempts
+type Delivery struct {
+	id             string
+	subscriptionID SubscriptionID
+	eventID        string
+	eventType      product.EventType
+	payload        []byte
+	status         DeliveryStatus
+	attempts       int
+	lastStatusCode int
+	lastError      string
+	nextAttemptAt  time.Time
+	createdAt      time.Time
+	updatedAt      time.Time
+}
+
+// NewDelivery creates a new pending Delivery
+func NewDelivery(id string, subscriptionID SubscriptionID, eventID string, eventType product.EventType, payload []byte) *Delivery {
+	now := time.Now()
+	return &Delivery{
+		id:             id,
+		subscriptionID: subscriptionID,
+		eventID:        eventID,
+		eventType:      eventType,
+		payload:        payload,
+		status:         DeliveryPending,
+		nextAttemptAt:  now,
+		createdAt:      now,
+		updatedAt:      now,
+	}
+}
+
+// ID returns the delivery's ID
+func (d *Delivery) ID() string {
+	return d.id
+}
+
+// SubscriptionID returns the ID of the subscription the delivery is sent to
+func (d *Delivery) SubscriptionID() SubscriptionID {
+	return d.subscriptionID
+}
+
+// EventID returns the ID of the delivered event
+func (d *Delivery) EventID() string {
+	return d.eventID
+}
+
+// EventType returns the type of the delivered event
+func (d *Delivery) EventType() product.EventType {
+	return d.eventType
+}
+
+// Payload returns the JSON body sent to the receiver
+func (d *Delivery) Payload() []byte {
+	return d.payload
+}
+
+// Status returns the delivery's status
+func (d *Delivery) Status() DeliveryStatus {
+	return d.status
+}
+
+// Attempts returns how many times delivery was attempted
+func (d *Delivery) Attempts() int {
+	return d.attempts
+}
+
+// LastStatusCode returns the HTTP status of the last attempt, or 0 if no response was received
+func (d *Delivery) LastStatusCode() int {
+	return d.lastStatusCode
+}
+
+// LastError returns the error of the last failed attempt
+func (d *Delivery) LastError() string {
+	return d.lastError
+}
+
+// NextAttemptAt returns when the next attempt is scheduled; zero once the delivery is finished
+func (d *Delivery) NextAttemptAt() time.Time {
+	return d.nextAttemptAt
+}
+
+// CreatedAt returns when the delivery was created
+func (d *Delivery) CreatedAt() time.Time {
+	return d.createdAt
+}
+
+// UpdatedAt returns when the delivery was last attempted
+func (d *Delivery) UpdatedAt() time.Time {
+	return d.updatedAt
+}
+
+// RecordSuccess marks the delivery as acknowledged by the receiver
+func (d *Delivery) RecordSuccess(statusCode int) {
+	d.attempts++
+	d.status = DeliverySucceeded
+	d.lastStatusCode = statusCode
+	d.lastError = ""
+	d.nextAttemptAt = time.Time{}
+	d.updatedAt = time.Now()
+}
+
+// RecordFailure records a failed attempt.
+// The delivery is dead-lettered once maxAttempts is reached, otherwise it is retried after backoff.
+func (d *Delivery) RecordFailure(statusCode int, reason string, maxAttempts int, backoff time.Duration) {
+	d.attempts++
+	d.lastStatusCode = statusCode
+	d.lastError = reason
+	d.updatedAt = time.Now()
+
+	if d.attempts >= maxAttempts {
+		d.status = DeliveryDeadLettered
+		d.nextAttemptAt = time.Time{}
+		return
+	}
+	d.nextAttemptAt = d.updatedAt.Add(backoff)
+}
+
+// Backoff returns the exponential delay before retry number attempt (starting at 1), capped at max
+func Backoff(attempt int, initial, max time.Duration) time.Duration {
+	delay := initial
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,19 @@
+package webhook
+
+import (
+	"context"
+)
+
+type SubscriptionRepository interface {
+	FindByID(ctx context.Context, id SubscriptionID) (*Subscription, error)
+	FindAll(ctx context.Context) ([]*Subscription, error)
+	Save(ctx context.Context, subscription *Subscription) error
+	Delete(ctx context.Context, id SubscriptionID) error
+}
+
+type DeliveryRepository interface {
+	FindByID(ctx context.Context, id string) (*Delivery, error)
+	// FindBySubscription returns the deliveries of a subscription, newest first
+	FindBySubscription(ctx context.Context, id SubscriptionID) ([]*Delivery, error)
+	Save(ctx context.Context, delivery *Delivery) error
+}
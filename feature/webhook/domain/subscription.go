@@ -0,0 +1,181 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// MinSecretLength is the minimum length of a subscription secret
+const MinSecretLength = 16
+
+// AllEvents subscribes to every event type
+const AllEvents = "*"
+
+var (
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrInvalidSubscription  = errors.New("invalid subscription")
+)
+
+// SubscriptionID represents the unique identifier of a subscription
+type SubscriptionID string
+
+// NewSubscriptionID creates a new SubscriptionID with validation
+func NewSubscriptionID(id string) (SubscriptionID, error) {
+	if id == "" {
+		return "", fmt.Errorf("%w: subscription ID cannot be empty", ErrInvalidSubscription)
+	}
+	return SubscriptionID(id), nil
+}
+
+// String returns the string representation of the SubscriptionID
+func (id SubscriptionID) String() string {
+	return string(id)
+}
+
+// GenerateID returns a random hex identifier
+func GenerateID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// GenerateSecret returns a random signing secret
+func GenerateSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// Subscription is a receiver URL registered for a set of product event types
+type Subscription struct {
+	id         SubscriptionID
+	url        string
+	eventTypes []product.EventType
+	secret     string
+	active     bool
+	createdAt  time.Time
+	updatedAt  time.Time
+}
+
+// NewSubscription creates a new active Subscription with validation
+func NewSubscription(id SubscriptionID, rawURL string, eventTypes []string, secret string) (*Subscription, error) {
+	if len(secret) < MinSecretLength {
+		return nil, fmt.Errorf("%w: secret must be at least %d characters", ErrInvalidSubscription, MinSecretLength)
+	}
+
+	now := time.Now()
+	s := &Subscription{
+		id:        id,
+		secret:    secret,
+		active:    true,
+		createdAt: now,
+		updatedAt: now,
+	}
+	if err := s.Update(rawURL, eventTypes, true); err != nil {
+		return nil, err
+	}
+	s.updatedAt = now
+
+	return s, nil
+}
+
+// Update replaces the URL, event types and active flag of the subscription
+func (s *Subscription) Update(rawURL string, eventTypes []string, active bool) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidSubscription)
+	}
+
+	types, err := parseEventTypes(eventTypes)
+	if err != nil {
+		return err
+	}
+
+	s.url = u.String()
+	s.eventTypes = types
+	s.active = active
+	s.updatedAt = time.Now()
+	return nil
+}
+
+// parseEventTypes validates event type names; AllEvents expands to every type
+func parseEventTypes(names []string) ([]product.EventType, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: at least one event type is required", ErrInvalidSubscription)
+	}
+
+	var types []product.EventType
+	seen := make(map[product.EventType]bool)
+	for _, name := range names {
+		if name == AllEvents {
+			return append([]product.EventType(nil), product.EventTypes...), nil
+		}
+		et := product.EventType(name)
+		if !et.IsValid() {
+			return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidSubscription, name)
+		}
+		if !seen[et] {
+			seen[et] = true
+			types = append(types, et)
+		}
+	}
+	return types, nil
+}
+
+// ID returns the subscription's ID
+func (s *Subscription) ID() SubscriptionID {
+	return s.id
+}
+
+// URL returns the receiver URL
+func (s *Subscription) URL() string {
+	return s.url
+}
+
+// EventTypes returns the subscribed event types
+func (s *Subscription) EventTypes() []product.EventType {
+	return s.eventTypes
+}
+
+// Secret returns the secret used to sign deliveries
+func (s *Subscription) Secret() string {
+	return s.secret
+}
+
+// Active reports whether events are delivered to the subscription
+func (s *Subscription) Active() bool {
+	return s.active
+}
+
+// CreatedAt returns when the subscription was created
+func (s *Subscription) CreatedAt() time.Time {
+	return s.createdAt
+}
+
+// UpdatedAt returns when the subscription was last updated
+func (s *Subscription) UpdatedAt() time.Time {
+	return s.updatedAt
+}
+
+// Matches checks if an event of the given type should be delivered to the subscription
+func (s *Subscription) Matches(eventType product.EventType) bool {
+	if !s.active {
+		return false
+	}
+	for _, et := range s.eventTypes {
+		if et == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	domain "sago-sample/feature/webhook/domain"
+)
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// statusFromError returns the HTTP status code for an error returned by a use case
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrSubscriptionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidSubscription):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondWithUseCaseError returns an error response for an error returned by a use case
+func respondWithUseCaseError(w http.ResponseWriter, err error) {
+	respondWithError(w, statusFromError(err), err.Error())
+}
+
+// respondWithError returns an error response
+func respondWithError(w http.ResponseWriter, code int, message string) {
+	respondWithJSON(w, code, ErrorResponse{Error: message})
+}
+
+// respondWithJSON returns a JSON response
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
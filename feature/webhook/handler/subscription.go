@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	webhook "sago-sample/feature/webhook/usecase"
+)
+
+// CreateSubscriptionRequest represents the request body for creating a subscription
+type CreateSubscriptionRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+// UpdateSubscriptionRequest represents the request body for updating a subscription
+type UpdateSubscriptionRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// SubscriptionResponse represents a subscription in the response
+type SubscriptionResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// DeliveryResponse represents an entry of the delivery log
+type DeliveryResponse struct {
+	ID             string     `json:"id"`
+	EventID        string     `json:"eventId"`
+	Event          string     `json:"event"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastStatusCode int        `json:"lastStatusCode,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// SubscriptionHandler handles webhook subscriptions and their delivery log
+type SubscriptionHandler struct {
+	CreateUseCase     *webhook.CreateSubscriptionUseCase
+	GetUseCase        *webhook.GetSubscriptionUseCase
+	ListUseCase       *webhook.ListSubscriptionsUseCase
+	UpdateUseCase     *webhook.UpdateSubscriptionUseCase
+	DeleteUseCase     *webhook.DeleteSubscriptionUseCase
+	DeliveriesUseCase *webhook.ListDeliveriesUseCase
+}
+
+func NewSubscriptionHandler(
+	createUc *webhook.CreateSubscriptionUseCase,
+	getUc *webhook.GetSubscriptionUseCase,
+	listUc *webhook.ListSubscriptionsUseCase,
+	updateUc *webhook.UpdateSubscriptionUseCase,
+	deleteUc *webhook.DeleteSubscriptionUseCase,
+	deliveriesUc *webhook.ListDeliveriesUseCase,
+) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		CreateUseCase:     createUc,
+		GetUseCase:        getUc,
+		ListUseCase:       listUc,
+		UpdateUseCase:     updateUc,
+		DeleteUseCase:     deleteUc,
+		DeliveriesUseCase: deliveriesUc,
+	}
+}
+
+// Register adds the webhook routes to rtr
+func (h *SubscriptionHandler) Register(rtr chi.Router) {
+	rtr.Get("/api/webhooks", h.HandleList)                           // GET    /api/webhooks
+	rtr.Post("/api/webhooks", h.HandleCreate)                        // POST   /api/webhooks
+	rtr.Get("/api/webhooks/{id}", h.HandleGet)                       // GET    /api/webhooks/{id}
+	rtr.Put("/api/webhooks/{id}", h.HandleUpdate)                    // PUT    /api/webhooks/{id}
+	rtr.Delete("/api/webhooks/{id}", h.HandleDelete)                 // DELETE /api/webhooks/{id}
+	rtr.Get("/api/webhooks/{id}/deliveries", h.HandleListDeliveries) // GET    /api/webhooks/{id}/deliveries
+}
+
+// HandleCreate handles the creation of a subscription; the response is the only one containing the secret
+func (h *SubscriptionHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req CreateSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	output, err := h.CreateUseCase.Execute(r.Context(), webhook.CreateSubscriptionInput{
+		URL:        req.URL,
+		EventTypes: req.Events,
+		Secret:     req.Secret,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	response := toSubscriptionResponse(output.SubscriptionOutput)
+	response.Secret = output.Secret
+	respondWithJSON(w, http.StatusCreated, response)
+}
+
+// HandleList handles listing the subscriptions
+func (h *SubscriptionHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	outputs, err := h.ListUseCase.Execute(r.Context())
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	responses := make([]SubscriptionResponse, 0, len(outputs))
+	for _, o := range outputs {
+		responses = append(responses, toSubscriptionResponse(o))
+	}
+	respondWithJSON(w, http.StatusOK, responses)
+}
+
+// HandleGet handles getting a subscription by ID
+func (h *SubscriptionHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	output, err := h.GetUseCase.Execute(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toSubscriptionResponse(*output))
+}
+
+// HandleUpdate handles replacing the URL, events and active flag of a subscription
+func (h *SubscriptionHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req UpdateSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	output, err := h.UpdateUseCase.Execute(r.Context(), webhook.UpdateSubscriptionInput{
+		ID:         chi.URLParam(r, "id"),
+		URL:        req.URL,
+		EventTypes: req.Events,
+		Active:     active,
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toSubscriptionResponse(*output))
+}
+
+// HandleDelete handles the deletion of a subscription
+func (h *SubscriptionHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.DeleteUseCase.Execute(r.Context(), chi.URLParam(r, "id")); err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleListDeliveries handles reading the delivery log of a subscription, newest first.
+// ?status=dead_lettered lists the dead-letter queue.
+func (h *SubscriptionHandler) HandleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	outputs, err := h.DeliveriesUseCase.Execute(r.Context(), webhook.ListDeliveriesInput{
+		SubscriptionID: chi.URLParam(r, "id"),
+		Status:         r.URL.Query().Get("status"),
+	})
+	if err != nil {
+		respondWithUseCaseError(w, err)
+		return
+	}
+
+	responses := make([]DeliveryResponse, 0, len(outputs))
+	for _, o := range outputs {
+		responses = append(responses, DeliveryResponse{
+			ID:             o.ID,
+			EventID:        o.EventID,
+			Event:          o.EventType,
+			Status:         o.Status,
+			Attempts:       o.Attempts,
+			LastStatusCode: o.LastStatusCode,
+			LastError:      o.LastError,
+			NextAttemptAt:  o.NextAttemptAt,
+			CreatedAt:      o.CreatedAt,
+			UpdatedAt:      o.UpdatedAt,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, responses)
+}
+
+func toSubscriptionResponse(o webhook.SubscriptionOutput) SubscriptionResponse {
+	return SubscriptionResponse{
+		ID:        o.ID,
+		URL:       o.URL,
+		Events:    o.EventTypes,
+		Active:    o.Active,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+}
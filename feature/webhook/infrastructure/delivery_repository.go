@@ -0,0 +1,75 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+
+	webhook "sago-sample/feature/webhook/domain"
+)
+
+// MaxDeliveriesPerSubscription bounds the delivery log kept for each subscription
+const MaxDeliveriesPerSubscription = 1000
+
+// DeliveryRepository is an in-memory implementation of the webhook.DeliveryRepository interface
+type DeliveryRepository struct {
+	deliveries map[string]webhook.Delivery
+	// order holds the delivery IDs of each subscription, oldest first
+	order map[webhook.SubscriptionID][]string
+	mutex sync.RWMutex
+}
+
+// NewDeliveryRepository creates a new in-memory delivery repository
+func NewDeliveryRepository() *DeliveryRepository {
+	return &DeliveryRepository{
+		deliveries: make(map[string]webhook.Delivery),
+		order:      make(map[webhook.SubscriptionID][]string),
+	}
+}
+
+// FindByID finds a delivery by its ID
+func (r *DeliveryRepository) FindByID(ctx context.Context, id string) (*webhook.Delivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	d, exists := r.deliveries[id]
+	if !exists {
+		return nil, webhook.ErrDeliveryNotFound
+	}
+
+	return &d, nil
+}
+
+// FindBySubscription returns the deliveries of a subscription, newest first
+func (r *DeliveryRepository) FindBySubscription(ctx context.Context, id webhook.SubscriptionID) ([]*webhook.Delivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	ids := r.order[id]
+	deliveries := make([]*webhook.Delivery, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		d := r.deliveries[ids[i]]
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, nil
+}
+
+// Save persists a delivery; the oldest entries of the subscription are dropped beyond MaxDeliveriesPerSubscription
+func (r *DeliveryRepository) Save(ctx context.Context, d *webhook.Delivery) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.deliveries[d.ID()]; !exists {
+		ids := append(r.order[d.SubscriptionID()], d.ID())
+		if len(ids) > MaxDeliveriesPerSubscription {
+			for _, old := range ids[:len(ids)-MaxDeliveriesPerSubscription] {
+				delete(r.deliveries, old)
+			}
+			ids = ids[len(ids)-MaxDeliveriesPerSubscription:]
+		}
+		r.order[d.SubscriptionID()] = ids
+	}
+
+	r.deliveries[d.ID()] = *d
+	return nil
+}
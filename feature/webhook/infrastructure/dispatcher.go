@@ -0,0 +1,294 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	product "sago-sample/feature/product/domain"
+	webhook "sago-sample/feature/webhook/domain"
+	"sago-sample/observability/logging"
+)
+
+// DispatcherConfig configures the delivery of webhook events
+type DispatcherConfig struct {
+	// Workers is the number of concurrent deliveries
+	Workers int
+	// MaxAttempts is the number of failed attempts after which a delivery is dead-lettered
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry; it doubles with each attempt
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries
+	MaxBackoff time.Duration
+	// Timeout bounds each delivery request
+	Timeout time.Duration
+	// HTTPClient sends the deliveries; a client with Timeout is used when nil
+	HTTPClient *http.Client
+}
+
+// DefaultDispatcherConfig returns the configuration used in production
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		Workers:        4,
+		MaxAttempts:    8,
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Minute,
+		Timeout:        10 * time.Second,
+	}
+}
+
+// Dispatcher turns product events into signed webhook deliveries.
+// Deliveries are sent by background workers, retried with exponential backoff and
+// dead-lettered after MaxAttempts failures; every attempt is recorded in the delivery log.
+type Dispatcher struct {
+	subscriptions webhook.SubscriptionRepository
+	deliveries    webhook.DeliveryRepository
+	config        DispatcherConfig
+	client        *http.Client
+
+	queue   chan string
+	done    chan struct{}
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+	started bool
+	stopped bool
+}
+
+// NewDispatcher creates a new Dispatcher; call Start to begin delivering
+func NewDispatcher(subscriptions webhook.SubscriptionRepository, deliveries webhook.DeliveryRepository, config DispatcherConfig) *Dispatcher {
+	defaults := DefaultDispatcherConfig()
+	if config.Workers <= 0 {
+		config.Workers = defaults.Workers
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = config.InitialBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: config.Timeout}
+	}
+
+	return &Dispatcher{
+		subscriptions: subscriptions,
+		deliveries:    deliveries,
+		config:        config,
+		client:        client,
+		queue:         make(chan string, 256),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start launches the delivery workers
+func (d *Dispatcher) Start() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.started || d.stopped {
+		return
+	}
+	d.started = true
+
+	for i := 0; i < d.config.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+}
+
+// Stop stops the workers and pending retries, waiting for in-flight deliveries until ctx is done.
+// Deliveries that were not attempted stay pending in the log.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	d.mutex.Lock()
+	if d.stopped {
+		d.mutex.Unlock()
+		return nil
+	}
+	d.stopped = true
+	close(d.done)
+	d.mutex.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// HandleEvent records a delivery for every subscription matching the event and queues it
+func (d *Dispatcher) HandleEvent(ctx context.Context, event product.Event) {
+	subscriptions, err := d.subscriptions.FindAll(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("webhook: failed to load subscriptions", "error", err)
+		return
+	}
+
+	var payload []byte
+	eventID := webhook.GenerateID()
+	for _, s := range subscriptions {
+		if !s.Matches(event.Type) {
+			continue
+		}
+
+		if payload == nil {
+			if payload, err = json.Marshal(newEventPayload(eventID, event)); err != nil {
+				logging.FromContext(ctx).Error("webhook: failed to encode event", "event", event.Type, "error", err)
+				return
+			}
+		}
+
+		delivery := webhook.NewDelivery(webhook.GenerateID(), s.ID(), eventID, event.Type, payload)
+		if err := d.deliveries.Save(ctx, delivery); err != nil {
+			logging.FromContext(ctx).Error("webhook: failed to record delivery", "subscription_id", s.ID().String(), "error", err)
+			continue
+		}
+		d.schedule(delivery.ID(), 0)
+	}
+}
+
+// schedule queues a delivery after delay without blocking the caller
+func (d *Dispatcher) schedule(id string, delay time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.stopped {
+		return
+	}
+
+	if delay == 0 {
+		select {
+		case d.queue <- id:
+			return
+		default:
+		}
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+
+			select {
+			case <-timer.C:
+			case <-d.done:
+				return
+			}
+		}
+
+		select {
+		case d.queue <- id:
+		case <-d.done:
+		}
+	}()
+}
+
+// work delivers queued deliveries until the dispatcher is stopped
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case id := <-d.queue:
+			d.attempt(id)
+		case <-d.done:
+			return
+		}
+	}
+}
+
+// attempt sends a delivery once and records the outcome
+func (d *Dispatcher) attempt(id string) {
+	ctx := context.Background()
+
+	delivery, err := d.deliveries.FindByID(ctx, id)
+	if err != nil || delivery.Status() != webhook.DeliveryPending {
+		return
+	}
+
+	subscription, err := d.subscriptions.FindByID(ctx, delivery.SubscriptionID())
+	if err != nil {
+		// The subscription was deleted; there is no receiver left to retry against
+		delivery.RecordFailure(0, "subscription no longer exists", 0, 0)
+		d.save(delivery)
+		return
+	}
+
+	statusCode, err := d.send(ctx, subscription, delivery)
+	if err == nil {
+		delivery.RecordSuccess(statusCode)
+		d.save(delivery)
+		return
+	}
+
+	backoff := webhook.Backoff(delivery.Attempts()+1, d.config.InitialBackoff, d.config.MaxBackoff)
+	delivery.RecordFailure(statusCode, err.Error(), d.config.MaxAttempts, backoff)
+	d.save(delivery)
+
+	if delivery.Status() == webhook.DeliveryPending {
+		d.schedule(delivery.ID(), backoff)
+	} else {
+		slog.Warn("webhook: delivery dead-lettered", "delivery_id", delivery.ID(), "url", subscription.URL(), "attempts", delivery.Attempts(), "error", err)
+	}
+}
+
+// send posts the signed payload and returns the response status; non-2xx responses are errors
+func (d *Dispatcher) send(ctx context.Context, subscription *webhook.Subscription, delivery *webhook.Delivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL(), bytes.NewReader(delivery.Payload()))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sago-sample-webhooks/1.0")
+	req.Header.Set(webhook.EventHeader, delivery.EventType().String())
+	req.Header.Set(webhook.DeliveryHeader, delivery.ID())
+	req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(subscription.Secret(), timestamp, delivery.Payload()))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) save(delivery *webhook.Delivery) {
+	if err := d.deliveries.Save(context.Background(), delivery); err != nil {
+		slog.Error("webhook: failed to record delivery", "delivery_id", delivery.ID(), "error", err)
+	}
+}
@@ -0,0 +1,109 @@
+package infrastructure
+
+import (
+	"time"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// EventPayload is the JSON body of a webhook delivery
+type EventPayload struct {
+	ID         string           `json:"id"`
+	Type       string           `json:"type"`
+	OccurredAt time.Time        `json:"occurredAt"`
+	Data       EventPayloadData `json:"data"`
+}
+
+// EventPayloadData describes the changed product
+type EventPayloadData struct {
+	ProductID      string           `json:"productId"`
+	Product        *ProductPayload  `json:"product,omitempty"`
+	PreviousPrice  *PricePayload    `json:"previousPrice,omitempty"`
+	PreviousStock  *uint            `json:"previousStock,omitempty"`
+	Category       *CategoryPayload `json:"category,omitempty"`
+	PreviousStatus string           `json:"previousStatus,omitempty"`
+}
+
+// ProductPayload is the state of the product after the change
+type ProductPayload struct {
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	Description    string            `json:"description"`
+	Price          uint              `json:"price"`
+	Currency       string            `json:"currency"`
+	Status         string            `json:"status"`
+	AvailableFrom  *time.Time        `json:"availableFrom,omitempty"`
+	AvailableUntil *time.Time        `json:"availableUntil,omitempty"`
+	Attributes     map[string]any    `json:"attributes,omitempty"`
+	Stock          uint              `json:"stock"`
+	Categories     []CategoryPayload `json:"categories"`
+}
+
+// PricePayload is a price with its currency
+type PricePayload struct {
+	Amount   uint   `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// CategoryPayload is a category of the product
+type CategoryPayload struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// newEventPayload builds the payload of a product event.
+// It is built when the event is handled, so later changes to the product do not leak into queued deliveries.
+func newEventPayload(id string, event product.Event) EventPayload {
+	payload := EventPayload{
+		ID:         id,
+		Type:       event.Type.String(),
+		OccurredAt: event.OccurredAt,
+		Data:       EventPayloadData{ProductID: event.ProductID.String()},
+	}
+
+	if p := event.Product; p != nil {
+		categories := make([]CategoryPayload, 0, len(p.Categories()))
+		for _, c := range p.Categories() {
+			categories = append(categories, CategoryPayload{ID: c.ID().String(), Name: c.Name().String()})
+		}
+		payload.Data.Product = &ProductPayload{
+			ID:          p.ID().String(),
+			Name:        p.Name().String(),
+			Description: p.Description().String(),
+			Price:       p.Price().Amount(),
+			Currency:    p.Price().Currency(),
+			Status:      p.Status().String(),
+			Attributes:  p.Attributes(),
+			Stock:       p.Stock().Quantity(),
+			Categories:  categories,
+		}
+		if from := p.Availability().From(); !from.IsZero() {
+			payload.Data.Product.AvailableFrom = &from
+		}
+		if until := p.Availability().Until(); !until.IsZero() {
+			payload.Data.Product.AvailableUntil = &until
+		}
+	}
+
+	switch event.Type {
+	case product.EventPriceChanged:
+		payload.Data.PreviousPrice = &PricePayload{Amount: event.PreviousPrice.Amount(), Currency: event.PreviousPrice.Currency()}
+	case product.EventStockChanged:
+		quantity := event.PreviousStock.Quantity()
+		payload.Data.PreviousStock = &quantity
+	case product.EventCategoryAdded, product.EventCategoryRemoved:
+		category := CategoryPayload{ID: event.CategoryID.String()}
+		if event.Product != nil {
+			for _, c := range event.Product.Categories() {
+				if c.ID() == event.CategoryID {
+					category.Name = c.Name().String()
+				}
+			}
+		}
+		payload.Data.Category = &category
+	case product.EventStatusChanged:
+		payload.Data.PreviousStatus = event.PreviousStatus.String()
+	}
+
+	return payload
+}
@@ -0,0 +1,79 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	webhook "sago-sample/feature/webhook/domain"
+)
+
+// SubscriptionRepository is an in-memory implementation of the webhook.SubscriptionRepository interface.
+// Subscriptions are copied on the way in and out so the dispatcher never sees a half-applied update.
+type SubscriptionRepository struct {
+	subscriptions map[string]webhook.Subscription
+	mutex         sync.RWMutex
+}
+
+// NewSubscriptionRepository creates a new in-memory subscription repository
+func NewSubscriptionRepository() *SubscriptionRepository {
+	return &SubscriptionRepository{
+		subscriptions: make(map[string]webhook.Subscription),
+	}
+}
+
+// FindByID finds a subscription by its ID
+func (r *SubscriptionRepository) FindByID(ctx context.Context, id webhook.SubscriptionID) (*webhook.Subscription, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	s, exists := r.subscriptions[id.String()]
+	if !exists {
+		return nil, webhook.ErrSubscriptionNotFound
+	}
+
+	return &s, nil
+}
+
+// FindAll returns all subscriptions, oldest first
+func (r *SubscriptionRepository) FindAll(ctx context.Context) ([]*webhook.Subscription, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	subscriptions := make([]*webhook.Subscription, 0, len(r.subscriptions))
+	for _, s := range r.subscriptions {
+		s := s
+		subscriptions = append(subscriptions, &s)
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].CreatedAt().Equal(subscriptions[j].CreatedAt()) {
+			return subscriptions[i].CreatedAt().Before(subscriptions[j].CreatedAt())
+		}
+		return subscriptions[i].ID() < subscriptions[j].ID()
+	})
+
+	return subscriptions, nil
+}
+
+// Save persists a subscription
+func (r *SubscriptionRepository) Save(ctx context.Context, s *webhook.Subscription) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.subscriptions[s.ID().String()] = *s
+	return nil
+}
+
+// Delete removes a subscription
+func (r *SubscriptionRepository) Delete(ctx context.Context, id webhook.SubscriptionID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.subscriptions[id.String()]; !exists {
+		return webhook.ErrSubscriptionNotFound
+	}
+
+	delete(r.subscriptions, id.String())
+	return nil
+}
@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domain "sago-sample/feature/webhook/domain"
+)
+
+// DeliveryOutput represents one entry of a subscription's delivery log
+type DeliveryOutput struct {
+	ID             string
+	EventID        string
+	EventType      string
+	Status         string
+	Attempts       int
+	LastStatusCode int
+	LastError      string
+	NextAttemptAt  *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// ListDeliveriesInput represents the input data for listing deliveries
+type ListDeliveriesInput struct {
+	SubscriptionID string
+	// Status filters the deliveries when set
+	Status string
+}
+
+// ListDeliveriesUseCase defines the use case for reading the delivery log of a subscription
+type ListDeliveriesUseCase struct {
+	subscriptions domain.SubscriptionRepository
+	deliveries    domain.DeliveryRepository
+}
+
+// NewListDeliveriesUseCase creates a new instance of ListDeliveriesUseCase
+func NewListDeliveriesUseCase(subscriptions domain.SubscriptionRepository, deliveries domain.DeliveryRepository) *ListDeliveriesUseCase {
+	return &ListDeliveriesUseCase{
+		subscriptions: subscriptions,
+		deliveries:    deliveries,
+	}
+}
+
+// Execute runs the use case
+func (uc *ListDeliveriesUseCase) Execute(ctx context.Context, input ListDeliveriesInput) ([]DeliveryOutput, error) {
+	subscriptionID, err := domain.NewSubscriptionID(input.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.DeliveryStatus(input.Status)
+	if input.Status != "" && !status.IsValid() {
+		return nil, fmt.Errorf("%w: unknown delivery status %q", domain.ErrInvalidSubscription, input.Status)
+	}
+
+	if _, err := uc.subscriptions.FindByID(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := uc.deliveries.FindBySubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]DeliveryOutput, 0, len(deliveries))
+	for _, d := range deliveries {
+		if input.Status != "" && d.Status() != status {
+			continue
+		}
+
+		output := DeliveryOutput{
+			ID:             d.ID(),
+			EventID:        d.EventID(),
+			EventType:      d.EventType().String(),
+			Status:         string(d.Status()),
+			Attempts:       d.Attempts(),
+			LastStatusCode: d.LastStatusCode(),
+			LastError:      d.LastError(),
+			CreatedAt:      d.CreatedAt(),
+			UpdatedAt:      d.UpdatedAt(),
+		}
+		if next := d.NextAttemptAt(); !next.IsZero() {
+			output.NextAttemptAt = &next
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
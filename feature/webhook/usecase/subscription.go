@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	domain "sago-sample/feature/webhook/domain"
+)
+
+// SubscriptionOutput represents a subscription returned by the use cases
+type SubscriptionOutput struct {
+	ID         string
+	URL        string
+	EventTypes []string
+	Active     bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// toSubscriptionOutput maps a subscription to its output; the secret is never included
+func toSubscriptionOutput(s *domain.Subscription) SubscriptionOutput {
+	eventTypes := make([]string, 0, len(s.EventTypes()))
+	for _, et := range s.EventTypes() {
+		eventTypes = append(eventTypes, et.String())
+	}
+	return SubscriptionOutput{
+		ID:         s.ID().String(),
+		URL:        s.URL(),
+		EventTypes: eventTypes,
+		Active:     s.Active(),
+		CreatedAt:  s.CreatedAt(),
+		UpdatedAt:  s.UpdatedAt(),
+	}
+}
+
+// CreateSubscriptionInput represents the input data for creating a subscription
+type CreateSubscriptionInput struct {
+	URL        string
+	EventTypes []string
+	// Secret is generated when empty
+	Secret string
+}
+
+// CreateSubscriptionOutput represents the created subscription along with its secret
+type CreateSubscriptionOutput struct {
+	SubscriptionOutput
+	Secret string
+}
+
+// CreateSubscriptionUseCase defines the use case for creating a subscription
+type CreateSubscriptionUseCase struct {
+	repo domain.SubscriptionRepository
+}
+
+// NewCreateSubscriptionUseCase creates a new instance of CreateSubscriptionUseCase
+func NewCreateSubscriptionUseCase(repo domain.SubscriptionRepository) *CreateSubscriptionUseCase {
+	return &CreateSubscriptionUseCase{repo: repo}
+}
+
+// Execute runs the use case
+func (uc *CreateSubscriptionUseCase) Execute(ctx context.Context, input CreateSubscriptionInput) (*CreateSubscriptionOutput, error) {
+	secret := input.Secret
+	if secret == "" {
+		secret = domain.GenerateSecret()
+	}
+
+	subscription, err := domain.NewSubscription(domain.SubscriptionID(domain.GenerateID()), input.URL, input.EventTypes, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Save(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return &CreateSubscriptionOutput{
+		SubscriptionOutput: toSubscriptionOutput(subscription),
+		Secret:             subscription.Secret(),
+	}, nil
+}
+
+// GetSubscriptionUseCase defines the use case for getting a subscription
+type GetSubscriptionUseCase struct {
+	repo domain.SubscriptionRepository
+}
+
+// NewGetSubscriptionUseCase creates a new instance of GetSubscriptionUseCase
+func NewGetSubscriptionUseCase(repo domain.SubscriptionRepository) *GetSubscriptionUseCase {
+	return &GetSubscriptionUseCase{repo: repo}
+}
+
+// Execute runs the use case
+func (uc *GetSubscriptionUseCase) Execute(ctx context.Context, id string) (*SubscriptionOutput, error) {
+	subscriptionID, err := domain.NewSubscriptionID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := uc.repo.FindByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	output := toSubscriptionOutput(subscription)
+	return &output, nil
+}
+
+// ListSubscriptionsUseCase defines the use case for listing subscriptions
+type ListSubscriptionsUseCase struct {
+	repo domain.SubscriptionRepository
+}
+
+// NewListSubscriptionsUseCase creates a new instance of ListSubscriptionsUseCase
+func NewListSubscriptionsUseCase(repo domain.SubscriptionRepository) *ListSubscriptionsUseCase {
+	return &ListSubscriptionsUseCase{repo: repo}
+}
+
+// Execute runs the use case
+func (uc *ListSubscriptionsUseCase) Execute(ctx context.Context) ([]SubscriptionOutput, error) {
+	subscriptions, err := uc.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]SubscriptionOutput, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		outputs = append(outputs, toSubscriptionOutput(s))
+	}
+	return outputs, nil
+}
+
+// UpdateSubscriptionInput represents the input data for updating a subscription
+type UpdateSubscriptionInput struct {
+	ID         string
+	URL        string
+	EventTypes []string
+	Active     bool
+}
+
+// UpdateSubscriptionUseCase defines the use case for updating a subscription
+type UpdateSubscriptionUseCase struct {
+	repo domain.SubscriptionRepository
+}
+
+// NewUpdateSubscriptionUseCase creates a new instance of UpdateSubscriptionUseCase
+func NewUpdateSubscriptionUseCase(repo domain.SubscriptionRepository) *UpdateSubscriptionUseCase {
+	return &UpdateSubscriptionUseCase{repo: repo}
+}
+
+// Execute runs the use case
+func (uc *UpdateSubscriptionUseCase) Execute(ctx context.Context, input UpdateSubscriptionInput) (*SubscriptionOutput, error) {
+	subscriptionID, err := domain.NewSubscriptionID(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := uc.repo.FindByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := subscription.Update(input.URL, input.EventTypes, input.Active); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Save(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	output := toSubscriptionOutput(subscription)
+	return &output, nil
+}
+
+// DeleteSubscriptionUseCase defines the use case for deleting a subscription
+type DeleteSubscriptionUseCase struct {
+	repo domain.SubscriptionRepository
+}
+
+// NewDeleteSubscriptionUseCase creates a new instance of DeleteSubscriptionUseCase
+func NewDeleteSubscriptionUseCase(repo domain.SubscriptionRepository) *DeleteSubscriptionUseCase {
+	return &DeleteSubscriptionUseCase{repo: repo}
+}
+
+// Execute runs the use case
+func (uc *DeleteSubscriptionUseCase) Execute(ctx context.Context, id string) error {
+	subscriptionID, err := domain.NewSubscriptionID(id)
+	if err != nil {
+		return err
+	}
+
+	return uc.repo.Delete(ctx, subscriptionID)
+}
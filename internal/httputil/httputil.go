@@ -0,0 +1,82 @@
+// Package httputil holds the request helpers shared by the HTTP middlewares
+package httputil
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// UnmatchedRoute names requests that matched no route, so unknown paths cannot grow label sets or span names
+const UnmatchedRoute = "unmatched"
+
+// RoutePattern returns the chi route pattern of the request.
+// Requests rejected by a middleware before routing, e.g. by request validation, are matched against the routes again.
+func RoutePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return UnmatchedRoute
+	}
+	if pattern := rctx.RoutePattern(); pattern != "" {
+		return pattern
+	}
+
+	if rctx.Routes != nil {
+		match := chi.NewRouteContext()
+		if rctx.Routes.Match(match, r.Method, r.URL.Path) {
+			return match.RoutePattern()
+		}
+	}
+	return UnmatchedRoute
+}
+
+// URLParam returns a route parameter of the request, once it has been routed
+func URLParam(r *http.Request, key string) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+	return rctx.URLParam(key)
+}
+
+// StatusRecorder captures the status code written by a handler
+type StatusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// NewStatusRecorder wraps w; the status is 200 until the handler writes another one
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+// Status returns the status code sent to the client
+func (r *StatusRecorder) Status() int {
+	return r.status
+}
+
+func (r *StatusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *StatusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush keeps streaming responses such as Server-Sent Events working through the recorder
+func (r *StatusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *StatusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
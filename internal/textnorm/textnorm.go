@@ -0,0 +1,106 @@
+// Package textnorm normalizes the free texts held by the value objects, e.g. product names, and measures their length.
+//
+// Every text is put in Unicode Normalization Form C, so "é" typed as e followed by a combining accent and "é" typed as
+// one character are stored, compared and counted the same. Texts holding invalid UTF-8, control characters or the
+// bidirectional embedding, override and isolate characters, which can make a text display differently from what it
+// holds, are rejected. The bidirectional marks U+200E, U+200F and U+061C, which right-to-left texts need, are kept.
+package textnorm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	ErrInvalidUTF8      = errors.New("is not valid UTF-8")
+	ErrControlCharacter = errors.New("contains a control character")
+	ErrBidiControl      = errors.New("contains a bidirectional formatting character")
+)
+
+// Line normalizes a single-line text: each run of whitespace, line breaks included, becomes one space,
+// and the leading and trailing whitespace is removed
+func Line(s string) (string, error) {
+	s, err := nfc(s)
+	if err != nil {
+		return "", err
+	}
+	s = strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+	return s, check(s, false)
+}
+
+// Block normalizes a multi-line text, e.g. a description: line breaks become \n, each run of other whitespace
+// becomes one space, the whitespace around each line is removed, and runs of blank lines become one blank line,
+// so paragraphs are kept
+func Block(s string) (string, error) {
+	s, err := nfc(s)
+	if err != nil {
+		return "", err
+	}
+	s = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\u2028", "\n", "\u2029", "\n", "\u0085", "\n").Replace(s)
+
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Join(strings.FieldsFunc(line, unicode.IsSpace), " ")
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	s = strings.Join(lines, "\n")
+	return s, check(s, true)
+}
+
+// Identifier normalizes a text naming something, e.g. a user: its whitespace is kept as is,
+// but control characters, tabs and line breaks included, are rejected
+func Identifier(s string) (string, error) {
+	s, err := nfc(s)
+	if err != nil {
+		return "", err
+	}
+	return s, check(s, false)
+}
+
+// Length returns the length of a text in characters, counted as Unicode code points of its NFC form.
+// Code points rather than user-perceived characters are counted, as PostgreSQL counts VARCHAR lengths,
+// so a text within a limit always fits its column.
+func Length(s string) int {
+	return utf8.RuneCountInString(norm.NFC.String(s))
+}
+
+// nfc returns the NFC form of s
+func nfc(s string) (string, error) {
+	if !utf8.ValidString(s) {
+		return "", ErrInvalidUTF8
+	}
+	return norm.NFC.String(s), nil
+}
+
+// check rejects the control characters, but for \n in multi-line texts, and the bidirectional formatting characters
+func check(s string, multiline bool) error {
+	for _, r := range s {
+		switch {
+		case r == '\n' && multiline:
+		case unicode.IsControl(r):
+			return fmt.Errorf("%w U+%04X", ErrControlCharacter, r)
+		case isBidiControl(r):
+			return fmt.Errorf("%w U+%04X", ErrBidiControl, r)
+		}
+	}
+	return nil
+}
+
+// isBidiControl reports whether r is an explicit bidirectional embedding, override or isolate (Unicode Standard Annex #9)
+func isBidiControl(r rune) bool {
+	return (r >= '\u202A' && r <= '\u202E') || (r >= '\u2066' && r <= '\u2069')
+}
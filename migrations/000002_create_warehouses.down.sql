@@ -0,0 +1,3 @@
+-- Drop tables in reverse order to avoid foreign key constraints; the totals remain in products.stock_quantity
+DROP TABLE IF EXISTS product_stock;
+DROP TABLE IF EXISTS warehouses;
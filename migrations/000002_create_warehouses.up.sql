@@ -0,0 +1,31 @@
+-- Create warehouses table; the location is optional
+CREATE TABLE IF NOT EXISTS warehouses (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    CHECK ((latitude IS NULL) = (longitude IS NULL))
+);
+
+-- The default warehouse holds stock that was not assigned to a specific warehouse
+INSERT INTO warehouses (id, name) VALUES ('default', 'Default warehouse')
+ON CONFLICT (id) DO NOTHING;
+
+-- Create product_stock table holding the stock of each product per warehouse;
+-- products.stock_quantity keeps the total across warehouses
+CREATE TABLE IF NOT EXISTS product_stock (
+    product_id VARCHAR(36) NOT NULL,
+    warehouse_id VARCHAR(36) NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity >= 0),
+    PRIMARY KEY (product_id, warehouse_id),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON DELETE RESTRICT
+);
+
+-- Move the existing stock to the default warehouse
+INSERT INTO product_stock (product_id, warehouse_id, quantity)
+SELECT id, 'default', stock_quantity FROM products WHERE stock_quantity > 0
+ON CONFLICT (product_id, warehouse_id) DO NOTHING;
+
+-- Create indexes
+CREATE INDEX idx_product_stock_warehouse_id ON product_stock(warehouse_id);
@@ -0,0 +1,32 @@
+-- Create stock_movements table, the append-only stock ledger; seq keeps the order movements were appended in.
+-- There is no foreign key to products so that the history of deleted products is kept.
+CREATE TABLE IF NOT EXISTS stock_movements (
+    seq BIGSERIAL PRIMARY KEY,
+    id VARCHAR(32) NOT NULL UNIQUE,
+    product_id VARCHAR(36) NOT NULL,
+    warehouse_id VARCHAR(36) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('receipt', 'sale', 'return', 'adjustment', 'reservation', 'transfer')),
+    quantity INTEGER NOT NULL CHECK (quantity <> 0),
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    actor VARCHAR(255) NOT NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Movements are never changed once recorded
+CREATE OR REPLACE FUNCTION reject_stock_movement_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stock_movements_append_only
+BEFORE UPDATE OR DELETE ON stock_movements
+FOR EACH ROW EXECUTE FUNCTION reject_stock_movement_change();
+
+-- Open the ledger with the existing stock
+INSERT INTO stock_movements (id, product_id, warehouse_id, type, quantity, reason, actor)
+SELECT md5(product_id || '/' || warehouse_id), product_id, warehouse_id, 'adjustment', quantity, 'opening balance', 'migration'
+FROM product_stock WHERE quantity > 0;
+
+-- Create indexes
+CREATE INDEX idx_stock_movements_product_id ON stock_movements(product_id, seq);
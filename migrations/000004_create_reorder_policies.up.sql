@@ -0,0 +1,9 @@
+-- Create reorder_policies table: a product is low on stock at or below its threshold,
+-- and reorders bring it back up to its target
+CREATE TABLE IF NOT EXISTS reorder_policies (
+    product_id VARCHAR(36) PRIMARY KEY,
+    threshold INTEGER NOT NULL CHECK (threshold >= 0),
+    target INTEGER NOT NULL,
+    CHECK (target > threshold),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
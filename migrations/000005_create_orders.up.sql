@@ -0,0 +1,26 @@
+-- Create orders table; the total is kept for reporting and equals the sum of the lines
+CREATE TABLE IF NOT EXISTS orders (
+    id VARCHAR(36) PRIMARY KEY,
+    status VARCHAR(16) NOT NULL CHECK (status IN ('placed', 'fulfilled', 'cancelled')),
+    currency CHAR(3) NOT NULL,
+    total BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create order_lines table; lines keep the product's name and price when the order was placed,
+-- and outlive the product
+CREATE TABLE IF NOT EXISTS order_lines (
+    order_id VARCHAR(36) NOT NULL,
+    position INTEGER NOT NULL,
+    product_id VARCHAR(36) NOT NULL,
+    product_name VARCHAR(100) NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price INTEGER NOT NULL CHECK (unit_price > 0),
+    PRIMARY KEY (order_id, position),
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+
+-- Create indexes
+CREATE INDEX idx_orders_status_created_at ON orders(status, created_at);
+CREATE INDEX idx_order_lines_product_id ON order_lines(product_id);
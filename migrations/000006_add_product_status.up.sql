@@ -0,0 +1,8 @@
+-- Add the lifecycle status of products; products created before it existed were live,
+-- so they are published while new products start as drafts
+ALTER TABLE products ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'published', 'archived'));
+ALTER TABLE products ALTER COLUMN status SET DEFAULT 'draft';
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_products_status ON products(status);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_products_available_until;
+DROP INDEX IF EXISTS idx_products_available_from;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_availability_window;
+ALTER TABLE products DROP COLUMN IF EXISTS available_until;
+ALTER TABLE products DROP COLUMN IF EXISTS available_from;
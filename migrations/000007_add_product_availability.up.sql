@@ -0,0 +1,9 @@
+-- Add the availability window of products; NULL leaves a bound open
+ALTER TABLE products ADD COLUMN IF NOT EXISTS available_from TIMESTAMP;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS available_until TIMESTAMP;
+ALTER TABLE products ADD CONSTRAINT products_availability_window
+    CHECK (available_from IS NULL OR available_until IS NULL OR available_until > available_from);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_products_available_from ON products(available_from);
+CREATE INDEX IF NOT EXISTS idx_products_available_until ON products(available_until);
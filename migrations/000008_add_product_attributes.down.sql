@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_attributes;
+DROP TABLE IF EXISTS attribute_schemas;
+ALTER TABLE products DROP COLUMN IF EXISTS attributes;
@@ -0,0 +1,12 @@
+-- Add the custom attribute values of products, e.g. {"color": "red", "voltage": 230}
+ALTER TABLE products ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- Create attribute_schemas table: the attributes defined for the products of a category,
+-- as a JSON array of {"key", "type", "required", "values"}
+CREATE TABLE IF NOT EXISTS attribute_schemas (
+    category_id VARCHAR(36) PRIMARY KEY,
+    definitions JSONB NOT NULL DEFAULT '[]'::jsonb
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_products_attributes ON products USING GIN (attributes);
@@ -0,0 +1,17 @@
+-- Create product_images table: the images of a product in display order, the primary one at position 0.
+-- The files are kept in the blob store under key and thumbnail_key.
+CREATE TABLE IF NOT EXISTS product_images (
+    product_id VARCHAR(36) NOT NULL,
+    id VARCHAR(32) NOT NULL,
+    position INTEGER NOT NULL CHECK (position >= 0),
+    key VARCHAR(255) NOT NULL,
+    thumbnail_key VARCHAR(255) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL CHECK (size >= 0),
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, id),
+    UNIQUE (product_id, position),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
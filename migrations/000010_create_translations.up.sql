@@ -0,0 +1,19 @@
+-- Create product_translations table: the name and description of a product in the locales other than the default one.
+-- An empty description falls back to the default description.
+CREATE TABLE IF NOT EXISTS product_translations (
+    product_id VARCHAR(36) NOT NULL,
+    locale VARCHAR(35) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    PRIMARY KEY (product_id, locale),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+-- Create category_translations table: the name of a category in the locales other than the default one.
+-- A category can be translated before any product is added to it, so it does not reference the categories table.
+CREATE TABLE IF NOT EXISTS category_translations (
+    category_id VARCHAR(36) NOT NULL,
+    locale VARCHAR(35) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    PRIMARY KEY (category_id, locale)
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS reservation_lines;
+DROP TABLE IF EXISTS reservations;
+DROP TABLE IF EXISTS cart_lines;
+DROP TABLE IF EXISTS carts;
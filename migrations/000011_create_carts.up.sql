@@ -0,0 +1,44 @@
+-- Create carts table; a cart is keyed by its owner, e.g. "user:alice", of at most 128 characters,
+-- and expires when left untouched
+CREATE TABLE IF NOT EXISTS carts (
+    id VARCHAR(136) PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Create cart_lines table; lines keep the price of the product when it was last added or updated
+CREATE TABLE IF NOT EXISTS cart_lines (
+    cart_id VARCHAR(136) NOT NULL,
+    position INTEGER NOT NULL,
+    product_id VARCHAR(36) NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price INTEGER NOT NULL CHECK (unit_price > 0),
+    currency CHAR(3) NOT NULL,
+    PRIMARY KEY (cart_id, position),
+    FOREIGN KEY (cart_id) REFERENCES carts(id) ON DELETE CASCADE
+);
+
+-- Create reservations table; a reservation holds the stock of the lines of a cart until it expires
+CREATE TABLE IF NOT EXISTS reservations (
+    id VARCHAR(32) PRIMARY KEY,
+    cart_id VARCHAR(136) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Create reservation_lines table
+CREATE TABLE IF NOT EXISTS reservation_lines (
+    reservation_id VARCHAR(32) NOT NULL,
+    position INTEGER NOT NULL,
+    product_id VARCHAR(36) NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price INTEGER NOT NULL CHECK (unit_price > 0),
+    currency CHAR(3) NOT NULL,
+    PRIMARY KEY (reservation_id, position),
+    FOREIGN KEY (reservation_id) REFERENCES reservations(id) ON DELETE CASCADE
+);
+
+-- Create indexes
+CREATE INDEX idx_carts_expires_at ON carts(expires_at);
+CREATE INDEX idx_reservations_expires_at ON reservations(expires_at);
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds each readiness check
+const DefaultTimeout = 2 * time.Second
+
+// Pinger is implemented by dependencies that can report whether they are reachable, such as SQL repositories
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Check is a named readiness check
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// PingCheck checks dep when it implements Pinger and always passes otherwise, e.g. for in-memory repositories
+func PingCheck(name string, dep any) Check {
+	return Check{
+		Name: name,
+		Check: func(ctx context.Context) error {
+			if p, ok := dep.(Pinger); ok {
+				return p.Ping(ctx)
+			}
+			return nil
+		},
+	}
+}
+
+// Response is the body of the health endpoints
+type Response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Handler serves the liveness and readiness endpoints
+type Handler struct {
+	Checks  []Check
+	Timeout time.Duration
+}
+
+// NewHandler creates a new Handler running checks for readiness
+func NewHandler(checks ...Check) *Handler {
+	return &Handler{Checks: checks, Timeout: DefaultTimeout}
+}
+
+// HandleLive reports that the process is up; it never checks dependencies so a slow database does not get the process restarted
+func (h *Handler) HandleLive(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, Response{Status: "ok"})
+}
+
+// HandleReady runs every check concurrently and answers 503 when one fails
+func (h *Handler) HandleReady(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+
+	results := make(map[string]string, len(h.Checks))
+	var (
+		mutex sync.Mutex
+		wg    sync.WaitGroup
+		ready = true
+	)
+	for _, c := range h.Checks {
+		wg.Add(1)
+		go func(c Check) {
+			defer wg.Done()
+
+			status := "ok"
+			if err := c.Check(ctx); err != nil {
+				status = err.Error()
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			results[c.Name] = status
+			if status != "ok" {
+				ready = false
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if !ready {
+		respondWithJSON(w, http.StatusServiceUnavailable, Response{Status: "unavailable", Checks: results})
+		return
+	}
+	respondWithJSON(w, http.StatusOK, Response{Status: "ok", Checks: results})
+}
+
+// respondWithJSON returns a JSON response
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	w.Write(response)
+}
@@ -0,0 +1,30 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// UseCaseLogger logs every use case execution at debug level with the logger of its context;
+// it implements the product use case Observer.
+type UseCaseLogger struct{}
+
+// Start logs the use case once it finishes
+func (UseCaseLogger) Start(ctx context.Context, useCase string) (context.Context, func(err error)) {
+	start := time.Now()
+	return ctx, func(err error) {
+		logger := FromContext(ctx)
+		if !logger.Enabled(ctx, slog.LevelDebug) {
+			return
+		}
+		attrs := []slog.Attr{
+			slog.String("usecase", useCase),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		logger.LogAttrs(ctx, slog.LevelDebug, "use case executed", attrs...)
+	}
+}
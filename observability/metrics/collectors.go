@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	product "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/infrastructure/cache"
+)
+
+// scrapeTimeout bounds the repository read made on each scrape
+const scrapeTimeout = 5 * time.Second
+
+// ProductCollector reports the number of products and of products out of stock.
+// The repository is read when the metrics are scraped, so the gauges are always current.
+type ProductCollector struct {
+	repo       product.Repository
+	products   *prometheus.Desc
+	outOfStock *prometheus.Desc
+	up         *prometheus.Desc
+}
+
+// NewProductCollector creates a new ProductCollector reading repo
+func NewProductCollector(repo product.Repository) *ProductCollector {
+	return &ProductCollector{
+		repo:       repo,
+		products:   prometheus.NewDesc("products", "Number of products in the catalog.", nil, nil),
+		outOfStock: prometheus.NewDesc("products_out_of_stock", "Number of products with no stock left.", nil, nil),
+		up:         prometheus.NewDesc("products_scrape_success", "Whether the product gauges could be read from the repository.", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *ProductCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.products
+	ch <- c.outOfStock
+	ch <- c.up
+}
+
+// Collect implements prometheus.Collector
+func (c *ProductCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	products, err := c.repo.FindAll(ctx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0)
+		return
+	}
+
+	outOfStock := 0
+	for _, p := range products {
+		if p.Stock().Quantity() == 0 {
+			outOfStock++
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.products, prometheus.GaugeValue, float64(len(products)))
+	ch <- prometheus.MustNewConstMetric(c.outOfStock, prometheus.GaugeValue, float64(outOfStock))
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 1)
+}
+
+// CacheCollector exports the lookup counters of a caching product repository
+type CacheCollector struct {
+	stats   func() cache.Stats
+	lookups *prometheus.Desc
+	errors  *prometheus.Desc
+}
+
+// NewCacheCollector creates a new CacheCollector reading stats
+func NewCacheCollector(stats func() cache.Stats) *CacheCollector {
+	return &CacheCollector{
+		stats:   stats,
+		lookups: prometheus.NewDesc("product_cache_lookups_total", "Product cache lookups by result (hit, negative_hit, miss).", []string{"result"}, nil),
+		errors:  prometheus.NewDesc("product_cache_errors_total", "Failed product cache reads, writes and invalidations.", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *CacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.lookups
+	ch <- c.errors
+}
+
+// Collect implements prometheus.Collector
+func (c *CacheCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(c.lookups, prometheus.CounterValue, float64(s.Hits), "hit")
+	ch <- prometheus.MustNewConstMetric(c.lookups, prometheus.CounterValue, float64(s.NegativeHits), "negative_hit")
+	ch <- prometheus.MustNewConstMetric(c.lookups, prometheus.CounterValue, float64(s.Misses), "miss")
+	ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(s.Errors))
+}
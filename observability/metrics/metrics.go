@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the Prometheus collectors of the service
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	useCaseDuration *prometheus.HistogramVec
+	useCaseErrors   *prometheus.CounterVec
+
+	repositoryDuration *prometheus.HistogramVec
+}
+
+// New creates the collectors and registers them, along with the Go runtime and process collectors, in a new registry
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, chi route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, chi route pattern and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		useCaseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "usecase_duration_seconds",
+			Help:    "Use case execution time by use case and result.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"usecase", "result"}),
+		useCaseErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "usecase_errors_total",
+			Help: "Use case executions that returned an error.",
+		}, []string{"usecase"}),
+		repositoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "repository_operation_duration_seconds",
+			Help:    "Repository operation latency by repository, operation and result.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"repository", "operation", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.useCaseDuration,
+		m.useCaseErrors,
+		m.repositoryDuration,
+	)
+
+	return m
+}
+
+// Register adds more collectors, such as the domain gauges, to the registry
+func (m *Metrics) Register(collectors ...prometheus.Collector) error {
+	for _, c := range collectors {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Registry returns the registry holding every collector
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Start records the duration and outcome of a use case; it implements usecase.Observer
+func (m *Metrics) Start(ctx context.Context, useCase string) (context.Context, func(err error)) {
+	start := time.Now()
+	return ctx, func(err error) {
+		m.useCaseDuration.WithLabelValues(useCase, result(err)).Observe(time.Since(start).Seconds())
+		if err != nil {
+			m.useCaseErrors.WithLabelValues(useCase).Inc()
+		}
+	}
+}
+
+// observeRepository records the latency of a repository operation
+func (m *Metrics) observeRepository(repository, operation string, start time.Time, err error) {
+	m.repositoryDuration.WithLabelValues(repository, operation, result(err)).Observe(time.Since(start).Seconds())
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
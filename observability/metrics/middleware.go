@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"sago-sample/internal/httputil"
+)
+
+// Middleware records the count and latency of each request by chi route pattern and status.
+// It must be installed on the chi router so the route pattern is known once the request is served.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := httputil.NewStatusRecorder(w)
+
+		next.ServeHTTP(rec, r)
+
+		route := httputil.RoutePattern(r)
+		status := strconv.Itoa(rec.Status())
+
+		m.requests.WithLabelValues(r.Method, route, status).Inc()
+		m.requestDuration.WithLabelValues(r.Method, route, status).Observe(time.Since(start).Seconds())
+	})
+}
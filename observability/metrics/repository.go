@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// ProductRepository records the latency of every operation of a product.Repository
+type ProductRepository struct {
+	next    product.Repository
+	metrics *Metrics
+}
+
+// NewProductRepository creates a new ProductRepository decorating next
+func NewProductRepository(next product.Repository, m *Metrics) *ProductRepository {
+	return &ProductRepository{next: next, metrics: m}
+}
+
+// FindByID finds a product by its ID
+func (r *ProductRepository) FindByID(ctx context.Context, id product.ProductID) (p *product.Product, err error) {
+	defer r.observe("FindByID", time.Now(), &err)
+	return r.next.FindByID(ctx, id)
+}
+
+// FindAll returns all products
+func (r *ProductRepository) FindAll(ctx context.Context) (products []*product.Product, err error) {
+	defer r.observe("FindAll", time.Now(), &err)
+	return r.next.FindAll(ctx)
+}
+
+// FindByCategory finds products by category ID
+func (r *ProductRepository) FindByCategory(ctx context.Context, categoryID product.CategoryID) (products []*product.Product, err error) {
+	defer r.observe("FindByCategory", time.Now(), &err)
+	return r.next.FindByCategory(ctx, categoryID)
+}
+
+// Facets counts the products per facet
+func (r *ProductRepository) Facets(ctx context.Context, query product.FacetQuery) (facets *product.Facets, err error) {
+	defer r.observe("Facets", time.Now(), &err)
+	return r.next.Facets(ctx, query)
+}
+
+// Save persists a product
+func (r *ProductRepository) Save(ctx context.Context, p *product.Product) (err error) {
+	defer r.observe("Save", time.Now(), &err)
+	return r.next.Save(ctx, p)
+}
+
+// Delete removes a product
+func (r *ProductRepository) Delete(ctx context.Context, id product.ProductID) (err error) {
+	defer r.observe("Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
+
+// Ping checks the underlying repository when it supports it
+func (r *ProductRepository) Ping(ctx context.Context) (err error) {
+	pinger, ok := r.next.(interface{ Ping(context.Context) error })
+	if !ok {
+		return nil
+	}
+	defer r.observe("Ping", time.Now(), &err)
+	return pinger.Ping(ctx)
+}
+
+func (r *ProductRepository) observe(operation string, start time.Time, err *error) {
+	// A missing product is an expected answer, not a failed operation
+	e := *err
+	if errors.Is(e, product.ErrProductNotFound) {
+		e = nil
+	}
+	r.metrics.observeRepository("product", operation, start, e)
+}
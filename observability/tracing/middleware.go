@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"sago-sample/internal/httputil"
+)
+
+// Middleware creates a server span for each request, continuing the trace of the W3C traceparent header if present.
+// It must be installed on the chi router so the span can be named after the route pattern.
+func (t *Tracer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := t.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := t.tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		r = r.WithContext(ctx)
+		rec := httputil.NewStatusRecorder(w)
+		next.ServeHTTP(rec, r)
+
+		route := httputil.RoutePattern(r)
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(rec.Status()))
+
+		// Only the product and category routes name their parameters after those entities
+		switch {
+		case strings.HasPrefix(route, "/api/products/{id}"):
+			span.SetAttributes(ProductIDKey.String(httputil.URLParam(r, "id")))
+			if cid := httputil.URLParam(r, "cid"); cid != "" {
+				span.SetAttributes(CategoryIDKey.String(cid))
+			}
+		case strings.HasPrefix(route, "/api/categories/{id}"):
+			span.SetAttributes(CategoryIDKey.String(httputil.URLParam(r, "id")))
+		}
+
+		if rec.Status() >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", rec.Status()))
+		}
+	})
+}
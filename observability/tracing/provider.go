@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Span exporters supported by NewProvider
+const (
+	// ExporterNone records spans for propagation but exports nothing
+	ExporterNone = "none"
+	// ExporterStdout writes finished spans to standard output as JSON
+	ExporterStdout = "stdout"
+	// ExporterOTLP sends spans to an OpenTelemetry collector over OTLP/HTTP
+	ExporterOTLP = "otlp"
+)
+
+// DefaultServiceName is the service.name of the spans when Config.ServiceName is empty
+const DefaultServiceName = "sago-sample"
+
+// Config selects how spans are exported
+type Config struct {
+	// Exporter is one of ExporterNone (the default), ExporterStdout or ExporterOTLP
+	Exporter    string
+	ServiceName string
+	// OTLPEndpoint is the collector URL, e.g. http://localhost:4318/v1/traces.
+	// When empty, the OTEL_EXPORTER_OTLP_* environment variables apply.
+	OTLPEndpoint string
+}
+
+// NewProvider creates a tracer provider exporting spans as configured.
+// The caller must call Shutdown on it to flush the remaining spans.
+func NewProvider(ctx context.Context, cfg Config) (*sdktrace.TracerProvider, error) {
+	name := cfg.ServiceName
+	if name == "" {
+		name = DefaultServiceName
+	}
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(name))),
+	}
+
+	switch cfg.Exporter {
+	case "", ExporterNone:
+	case ExporterStdout:
+		exporter, err := stdouttrace.New()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	case ExporterOTLP:
+		var clientOpts []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		}
+		exporter, err := otlptracehttp.New(ctx, clientOpts...)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+
+	return sdktrace.NewTracerProvider(opts...), nil
+}
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// ProductCountKey is the number of products returned by a repository query
+const ProductCountKey = attribute.Key("product.count")
+
+// ProductRepository creates a span for every operation of a product.Repository
+type ProductRepository struct {
+	next   product.Repository
+	tracer *Tracer
+}
+
+// NewProductRepository creates a new ProductRepository decorating next
+func NewProductRepository(next product.Repository, t *Tracer) *ProductRepository {
+	return &ProductRepository{next: next, tracer: t}
+}
+
+// FindByID finds a product by its ID
+func (r *ProductRepository) FindByID(ctx context.Context, id product.ProductID) (p *product.Product, err error) {
+	ctx, span := r.start(ctx, "FindByID", ProductIDKey.String(id.String()))
+	defer func() { r.end(span, err) }()
+	return r.next.FindByID(ctx, id)
+}
+
+// FindAll returns all products
+func (r *ProductRepository) FindAll(ctx context.Context) (products []*product.Product, err error) {
+	ctx, span := r.start(ctx, "FindAll")
+	defer func() {
+		span.SetAttributes(ProductCountKey.Int(len(products)))
+		r.end(span, err)
+	}()
+	return r.next.FindAll(ctx)
+}
+
+// FindByCategory finds products by category ID
+func (r *ProductRepository) FindByCategory(ctx context.Context, categoryID product.CategoryID) (products []*product.Product, err error) {
+	ctx, span := r.start(ctx, "FindByCategory", CategoryIDKey.String(categoryID.String()))
+	defer func() {
+		span.SetAttributes(ProductCountKey.Int(len(products)))
+		r.end(span, err)
+	}()
+	return r.next.FindByCategory(ctx, categoryID)
+}
+
+// Facets counts the products per facet
+func (r *ProductRepository) Facets(ctx context.Context, query product.FacetQuery) (facets *product.Facets, err error) {
+	ctx, span := r.start(ctx, "Facets", CategoryIDKey.String(query.CategoryID.String()))
+	defer func() {
+		if facets != nil {
+			span.SetAttributes(ProductCountKey.Int(facets.Total))
+		}
+		r.end(span, err)
+	}()
+	return r.next.Facets(ctx, query)
+}
+
+// Save persists a product
+func (r *ProductRepository) Save(ctx context.Context, p *product.Product) (err error) {
+	ctx, span := r.start(ctx, "Save", ProductIDKey.String(p.ID().String()))
+	defer func() { r.end(span, err) }()
+	return r.next.Save(ctx, p)
+}
+
+// Delete removes a product
+func (r *ProductRepository) Delete(ctx context.Context, id product.ProductID) (err error) {
+	ctx, span := r.start(ctx, "Delete", ProductIDKey.String(id.String()))
+	defer func() { r.end(span, err) }()
+	return r.next.Delete(ctx, id)
+}
+
+// Ping checks the underlying repository when it supports it
+func (r *ProductRepository) Ping(ctx context.Context) (err error) {
+	pinger, ok := r.next.(interface{ Ping(context.Context) error })
+	if !ok {
+		return nil
+	}
+	ctx, span := r.start(ctx, "Ping")
+	defer func() { r.end(span, err) }()
+	return pinger.Ping(ctx)
+}
+
+func (r *ProductRepository) start(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return r.tracer.tracer.Start(ctx, "ProductRepository."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func (r *ProductRepository) end(span trace.Span, err error) {
+	// A missing product is an expected answer, not a failed operation
+	if errors.Is(err, product.ErrProductNotFound) {
+		err = nil
+	}
+	endSpan(span, err)
+}
@@ -0,0 +1,57 @@
+// Package tracing creates OpenTelemetry spans for HTTP requests, use cases and repository operations
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the tracer creating the spans
+const InstrumentationName = "sago-sample/observability/tracing"
+
+// Attributes set on the spans of operations on a product or category
+const (
+	ProductIDKey  = attribute.Key("product.id")
+	CategoryIDKey = attribute.Key("category.id")
+	UseCaseKey    = attribute.Key("usecase")
+)
+
+// Propagator is the W3C Trace Context and Baggage propagator used when none is given
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// Tracer creates the spans of the application
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// New creates a new Tracer. The propagator reads the incoming trace context; nil means Propagator().
+func New(tp trace.TracerProvider, propagator propagation.TextMapPropagator) *Tracer {
+	if propagator == nil {
+		propagator = Propagator()
+	}
+	return &Tracer{tracer: tp.Tracer(InstrumentationName), propagator: propagator}
+}
+
+// Start creates a span for a use case execution; it implements the product use case Observer
+func (t *Tracer) Start(ctx context.Context, useCase string) (context.Context, func(err error)) {
+	ctx, span := t.tracer.Start(ctx, "usecase."+useCase, trace.WithAttributes(UseCaseKey.String(useCase)))
+	return ctx, func(err error) {
+		endSpan(span, err)
+	}
+}
+
+// endSpan records err on the span, if any, and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,46 @@
+// Package principal identifies the caller of an HTTP request for logs and rate limits
+package principal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+// APIKeyHeader is the request header carrying the caller's API key
+const APIKeyHeader = "X-API-Key"
+
+// Anonymous names callers that are neither authenticated nor identified by an API key
+const Anonymous = "anonymous"
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the authenticated principal, e.g. set by an authentication middleware
+func NewContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKey{}, name)
+}
+
+// FromContext returns the authenticated principal of the context, if any
+func FromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(contextKey{}).(string)
+	return name, ok && name != ""
+}
+
+// FromRequest names the caller of a request: the authenticated principal if there is one,
+// then the fingerprint of its API key, and Anonymous otherwise.
+func FromRequest(r *http.Request) string {
+	if name, ok := FromContext(r.Context()); ok {
+		return name
+	}
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return "apikey:" + Fingerprint(key)
+	}
+	return Anonymous
+}
+
+// Fingerprint returns a short, stable digest of an API key, so that keys never appear in logs or metrics
+func Fingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,98 @@
+// Package ratelimit limits the request rate of each client with token buckets
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket refilled with Requests tokens every Period and holding at most Burst tokens.
+// The zero Limit does not limit anything.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	// Burst is the bucket capacity; zero means Requests
+	Burst int
+}
+
+// PerMinute returns a limit of n requests per minute
+func PerMinute(n int) Limit {
+	return Limit{Requests: n, Period: time.Minute}
+}
+
+// Unlimited reports whether the limit lets every request through
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+// Capacity returns the number of tokens of a full bucket
+func (l Limit) Capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// ratePerSecond returns the number of tokens added per second
+func (l Limit) ratePerSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// String formats the limit as a RateLimit-Policy value, e.g. "60;w=60"
+func (l Limit) String() string {
+	return fmt.Sprintf("%d;w=%d", l.Capacity(), int(math.Ceil(l.Period.Seconds())))
+}
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed bool
+	// Limit is the capacity of the bucket
+	Limit int
+	// Remaining is the number of whole tokens left in the bucket
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until a token is available when the request was not allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps the token buckets. The in-memory store serves a single instance;
+// a shared store such as RedisStore applies the limits across instances.
+type Store interface {
+	// Take removes one token from the bucket of key, created full when missing
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucket is the state of a token bucket
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket up to now and removes one token if there is one
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	capacity := float64(limit.Capacity())
+	rate := limit.ratePerSecond()
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+	}
+	b.last = now
+
+	res := Result{Limit: limit.Capacity()}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((capacity - b.tokens) / rate)
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the number of Take calls between two removals of the full buckets
+const sweepInterval = 4096
+
+// MemoryStore keeps the token buckets in process
+type MemoryStore struct {
+	// Clock returns the current time; it defaults to time.Now
+	Clock func() time.Time
+
+	mutex   sync.Mutex
+	buckets map[string]*memoryBucket
+	takes   int
+}
+
+type memoryBucket struct {
+	bucket
+	limit Limit
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{Clock: time.Now, buckets: make(map[string]*memoryBucket)}
+}
+
+// Take removes one token from the bucket of key
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := s.Clock()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.takes++
+	if s.takes%sweepInterval == 0 {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{bucket: bucket{tokens: float64(limit.Capacity()), last: now}}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+	return b.take(limit, now), nil
+}
+
+// Len returns the number of buckets held
+func (s *MemoryStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.buckets)
+}
+
+// sweep removes the buckets that have refilled, which are equivalent to missing ones; the caller must hold the mutex
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		refilled := b.tokens + now.Sub(b.last).Seconds()*b.limit.ratePerSecond()
+		if refilled >= float64(b.limit.Capacity()) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"sago-sample/internal/httputil"
+	"sago-sample/observability/logging"
+	"sago-sample/principal"
+)
+
+// KeyFunc identifies the client a request is counted against
+type KeyFunc func(r *http.Request) string
+
+// Rule sets the limit of the requests matching a method and chi route pattern
+type Rule struct {
+	// Method matches any method when empty
+	Method string
+	// Route is a chi route pattern such as /api/products/{id}
+	Route string
+	Limit Limit
+}
+
+// Config configures Middleware
+type Config struct {
+	// Read limits GET, HEAD and OPTIONS requests
+	Read Limit
+	// Write limits the other methods, which change data and are limited more strictly
+	Write Limit
+	// Rules override Read and Write for matching requests; the first matching rule applies
+	// and has its own bucket. A rule with the zero Limit exempts its requests.
+	Rules []Rule
+	// Key defaults to ClientKey(false)
+	Key KeyFunc
+}
+
+// ClientKey identifies the client by its authenticated principal, else its IP address.
+// An X-API-Key header only counts once an authentication middleware has verified it and set the principal
+// with principal.NewContext; otherwise a client could send a new key with every request to get a fresh bucket.
+// The IP address is taken from X-Forwarded-For only when trustProxy is set, i.e. behind a trusted reverse proxy.
+func ClientKey(trustProxy bool) KeyFunc {
+	return func(r *http.Request) string {
+		if p, ok := principal.FromContext(r.Context()); ok {
+			return p
+		}
+		return "ip:" + clientIP(r, trustProxy)
+	}
+}
+
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Middleware rejects the requests of clients that exceeded their limit with 429 Too Many Requests.
+// Limited responses carry the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers,
+// and rejected ones Retry-After. Requests are let through when the store fails.
+func Middleware(store Store, cfg Config) func(http.Handler) http.Handler {
+	key := cfg.Key
+	if key == nil {
+		key = ClientKey(false)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bucket, limit := cfg.limitFor(r)
+			if limit.Unlimited() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := store.Take(r.Context(), key(r)+"|"+bucket, limit)
+			if err != nil {
+				logging.FromContext(r.Context()).Warn("rate limit store failed", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+			h.Set("RateLimit-Policy", limit.String())
+
+			if !res.Allowed {
+				retryAfter := ceilSeconds(res.RetryAfter)
+				h.Set("Retry-After", strconv.Itoa(retryAfter))
+				respondWithError(w, http.StatusTooManyRequests, fmt.Sprintf("rate limit exceeded, retry in %ds", retryAfter))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// limitFor returns the bucket name and limit of a request
+func (cfg Config) limitFor(r *http.Request) (string, Limit) {
+	route := httputil.RoutePattern(r)
+	for _, rule := range cfg.Rules {
+		if rule.Route == route && (rule.Method == "" || strings.EqualFold(rule.Method, r.Method)) {
+			return rule.Method + " " + rule.Route, rule.Limit
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return "read", cfg.Read
+	default:
+		return "write", cfg.Write
+	}
+}
+
+// ceilSeconds rounds up to whole seconds, so clients never retry too early
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// respondWithError returns an error response in the API's error format
+func respondWithError(w http.ResponseWriter, code int, message string) {
+	response, _ := json.Marshal(map[string]string{"error": message})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
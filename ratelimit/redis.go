@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refills and takes from the bucket stored in the hash KEYS[1] atomically.
+// ARGV: capacity, tokens per millisecond, now in milliseconds. It returns {allowed, tokens * 1000}.
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(state[1])
+local last = tonumber(state[2])
+if tokens == nil then
+  tokens = capacity
+  last = now
+end
+
+if now > last then
+  tokens = math.min(capacity, tokens + (now - last) * rate)
+  last = now
+end
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "last", tostring(last))
+redis.call("PEXPIRE", KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+return {allowed, math.floor(tokens * 1000)}
+`)
+
+// RedisStore keeps the token buckets in Redis, so every instance of the server shares them
+type RedisStore struct {
+	// Clock returns the current time; it defaults to time.Now. The instances' clocks should be synchronized.
+	Clock func() time.Time
+
+	client redis.Scripter
+	prefix string
+}
+
+// NewRedisStore creates a store keeping its buckets under keys starting with prefix
+func NewRedisStore(client redis.Scripter, prefix string) *RedisStore {
+	return &RedisStore{Clock: time.Now, client: client, prefix: prefix}
+}
+
+// Take removes one token from the bucket of key
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	ratePerMilli := limit.ratePerSecond() / 1000
+	now := s.Clock().UnixMilli()
+
+	values, err := takeScript.Run(ctx, s.client, []string{s.prefix + key},
+		limit.Capacity(), strconv.FormatFloat(ratePerMilli, 'g', -1, 64), now).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	tokens := float64(values[1]) / 1000
+	res := Result{
+		Allowed:   values[0] == 1,
+		Limit:     limit.Capacity(),
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Capacity()) - tokens) / limit.ratePerSecond()),
+	}
+	if !res.Allowed {
+		res.RetryAfter = seconds((1 - tokens) / limit.ratePerSecond())
+	}
+	return res, nil
+}
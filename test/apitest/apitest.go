@@ -0,0 +1,258 @@
+// Package apitest serves the HTTP API of the server binary, built by app.NewRouter, over in-memory repositories,
+// and sends it requests for the tests of the handlers
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"sago-sample/app"
+	cart "sago-sample/feature/cart/domain"
+	cartHandler "sago-sample/feature/cart/handler"
+	cartInfra "sago-sample/feature/cart/infrastructure"
+	cartUseCase "sago-sample/feature/cart/usecase"
+	order "sago-sample/feature/order/domain"
+	orderHandler "sago-sample/feature/order/handler"
+	orderInfra "sago-sample/feature/order/infrastructure"
+	orderUseCase "sago-sample/feature/order/usecase"
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/handler"
+	"sago-sample/feature/product/handler/gql"
+	"sago-sample/feature/product/handler/sse"
+	"sago-sample/feature/product/infrastructure"
+	"sago-sample/feature/product/infrastructure/blob"
+	usecase "sago-sample/feature/product/usecase"
+	webhookHandler "sago-sample/feature/webhook/handler"
+	webhookInfra "sago-sample/feature/webhook/infrastructure"
+	webhookUseCase "sago-sample/feature/webhook/usecase"
+	"sago-sample/observability/health"
+	"sago-sample/observability/metrics"
+)
+
+// Options changes the API served by New; the zero value serves it like the binary with the memory backend
+type Options struct {
+	// Products decorates the in-memory product repository, e.g. with metrics or to count the calls
+	Products func(domain.Repository) domain.Repository
+	// Clock tells the time of the services and of the storefront reads; domain.SystemClock when nil
+	Clock domain.Clock
+	// Locales are the locales of the catalog; domain.DefaultLocales when zero
+	Locales domain.Locales
+	// Middlewares run first in the given order, like the middlewares of the binary
+	Middlewares []func(http.Handler) http.Handler
+	// Metrics serves /metrics; the handler of fresh metrics when nil
+	Metrics http.Handler
+}
+
+// API is the router of the API with the services behind it
+type API struct {
+	Router   chi.Router
+	Handlers app.Handlers
+	// Products is the product repository, decorated by Options.Products
+	Products     domain.Repository
+	Service      *domain.Service
+	Inventory    *domain.InventoryService
+	Translations *domain.TranslationService
+	Movements    domain.MovementRepository
+	Reconcile    *usecase.ReconcileStockUseCase
+	Broker       *sse.Broker
+}
+
+// New serves the API over in-memory repositories
+func New(t *testing.T, options Options) *API {
+	t.Helper()
+
+	if options.Clock == nil {
+		options.Clock = domain.SystemClock
+	}
+	if options.Locales.Default() == "" {
+		options.Locales = domain.DefaultLocales
+	}
+	if options.Metrics == nil {
+		options.Metrics = metrics.New().Handler()
+	}
+
+	var repo domain.Repository = infrastructure.NewProductRepository()
+	if options.Products != nil {
+		repo = options.Products(repo)
+	}
+	movements := infrastructure.NewMovementRepository()
+	service := domain.NewService(repo)
+	service.SetClock(options.Clock)
+	service.SetLedger(movements)
+	inventory := domain.NewInventoryService(service, infrastructure.NewWarehouseRepository())
+	policies := infrastructure.NewReorderPolicyRepository()
+	attributes := domain.NewAttributeService(service, infrastructure.NewAttributeSchemaRepository())
+	translations := domain.NewTranslationService(service, infrastructure.NewCategoryTranslationRepository(), options.Locales)
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	media := domain.NewMediaService(service, blobs, domain.DefaultImageLimits)
+	service.Subscribe(media)
+	broker := sse.NewBroker(sse.DefaultReplaySize)
+	service.Subscribe(broker)
+	t.Cleanup(broker.Close)
+	subscriptions := webhookInfra.NewSubscriptionRepository()
+	deliveries := webhookInfra.NewDeliveryRepository()
+	orders := orderInfra.NewOrderRepository()
+	orderService := order.NewService(orders, repo, inventory)
+	orderService.SetClock(options.Clock)
+	carts := cart.NewService(cartInfra.NewCartRepository(), cartInfra.NewReservationRepository(), repo, inventory, cart.Config{Clock: options.Clock})
+
+	create := usecase.NewCreateProductUseCase(service)
+	update := usecase.NewUpdateProductUseCase(service)
+	del := usecase.NewDeleteProductUseCase(service)
+	get := usecase.NewGetProductUseCase(repo)
+	getAll := usecase.NewGetAllProductsUseCase(repo)
+	list := usecase.NewListProductsUseCase(repo)
+	addCat := usecase.NewAddCategoryToProductUseCase(service)
+	remCat := usecase.NewRemoveCategoryFromProductUseCase(service)
+	byCat := usecase.NewGetProductsByCategoryUseCase(service)
+	get.SetClock(options.Clock)
+	list.SetClock(options.Clock)
+	get.SetTranslations(translations)
+	list.SetTranslations(translations)
+	byCat.SetTranslations(translations)
+	reconcile := usecase.NewReconcileStockUseCase(repo, movements)
+
+	hGraphQL, err := gql.NewHandler(create, update, del, get, getAll, addCat, remCat, byCat)
+	require.NoError(t, err)
+	hGet := handler.NewGetProductHandler(get, list, byCat)
+	hGet.Locales = options.Locales
+
+	handlers := app.Handlers{
+		GetProduct:    hGet,
+		CreateProduct: handler.NewCreateProductHandler(create),
+		UpdateProduct: handler.NewUpdateProductHandler(update, get),
+		PatchProduct:  handler.NewPatchProductHandler(usecase.NewPatchProductUseCase(service)),
+		DeleteProduct: handler.NewDeleteProductHandler(del),
+		Category:      handler.NewCategoryHandler(addCat, remCat),
+		GraphQL:       hGraphQL,
+		Stream:        sse.NewHandler(broker, get, sse.DefaultHeartbeat),
+		Inventory: handler.NewInventoryHandler(
+			usecase.NewCreateWarehouseUseCase(inventory),
+			usecase.NewGetWarehouseUseCase(inventory),
+			usecase.NewListWarehousesUseCase(inventory),
+			usecase.NewUpdateWarehouseUseCase(inventory),
+			usecase.NewDeleteWarehouseUseCase(inventory),
+			usecase.NewGetInventoryUseCase(repo),
+			usecase.NewSetStockLevelUseCase(inventory),
+			usecase.NewTransferStockUseCase(inventory),
+			usecase.NewAllocateStockUseCase(inventory),
+		),
+		StockMovement: handler.NewStockMovementHandler(
+			usecase.NewListStockMovementsUseCase(repo, movements),
+			usecase.NewRecordStockMovementUseCase(inventory),
+			reconcile,
+		),
+		Reorder: handler.NewReorderHandler(
+			usecase.NewSetReorderPolicyUseCase(repo, policies),
+			usecase.NewGetReorderPolicyUseCase(policies),
+			usecase.NewDeleteReorderPolicyUseCase(policies),
+			usecase.NewGetReorderSuggestionsUseCase(repo, policies),
+		),
+		Attribute: handler.NewAttributeHandler(
+			usecase.NewSetAttributeSchemaUseCase(attributes),
+			usecase.NewGetAttributeSchemaUseCase(attributes),
+			usecase.NewDeleteAttributeSchemaUseCase(attributes),
+			usecase.NewSetProductAttributesUseCase(attributes),
+		),
+		Facet: handler.NewFacetHandler(usecase.NewGetProductFacetsUseCase(repo, domain.DefaultPriceBounds)),
+		Image: handler.NewImageHandler(
+			usecase.NewUploadProductImageUseCase(media),
+			usecase.NewRemoveProductImageUseCase(media),
+			usecase.NewReorderProductImagesUseCase(media),
+			usecase.NewGetProductImageUseCase(repo, media),
+			domain.DefaultImageLimits.MaxSize,
+		),
+		Translation: handler.NewTranslationHandler(
+			usecase.NewGetProductTranslationsUseCase(translations),
+			usecase.NewSetProductTranslationUseCase(translations),
+			usecase.NewRemoveProductTranslationUseCase(translations),
+			usecase.NewGetCategoryTranslationsUseCase(translations),
+			usecase.NewSetCategoryTranslationUseCase(translations),
+			usecase.NewRemoveCategoryTranslationUseCase(translations),
+		),
+		Lifecycle: handler.NewLifecycleHandler(get, list, usecase.NewChangeProductStatusUseCase(service), usecase.NewSetProductAvailabilityUseCase(service)),
+		Subscription: webhookHandler.NewSubscriptionHandler(
+			webhookUseCase.NewCreateSubscriptionUseCase(subscriptions),
+			webhookUseCase.NewGetSubscriptionUseCase(subscriptions),
+			webhookUseCase.NewListSubscriptionsUseCase(subscriptions),
+			webhookUseCase.NewUpdateSubscriptionUseCase(subscriptions),
+			webhookUseCase.NewDeleteSubscriptionUseCase(subscriptions),
+			webhookUseCase.NewListDeliveriesUseCase(subscriptions, deliveries),
+		),
+		Order: orderHandler.NewOrderHandler(
+			orderUseCase.NewPlaceOrderUseCase(orderService),
+			orderUseCase.NewGetOrderUseCase(orders),
+			orderUseCase.NewListOrdersUseCase(orders),
+			orderUseCase.NewCancelOrderUseCase(orderService),
+			orderUseCase.NewFulfilOrderUseCase(orderService),
+		),
+		Cart: cartHandler.NewCartHandler(
+			cartUseCase.NewGetCartUseCase(carts),
+			cartUseCase.NewAddCartItemUseCase(carts),
+			cartUseCase.NewUpdateCartItemUseCase(carts),
+			cartUseCase.NewRemoveCartItemUseCase(carts),
+			cartUseCase.NewClearCartUseCase(carts),
+			cartUseCase.NewReserveCartUseCase(carts),
+			cartUseCase.NewGetReservationUseCase(carts),
+			cartUseCase.NewReleaseReservationUseCase(carts),
+		),
+		Metrics: options.Metrics,
+		Health:  health.NewHandler(health.PingCheck("repository", repo)),
+	}
+
+	return &API{
+		Router:       app.NewRouter(handlers, options.Middlewares...),
+		Handlers:     handlers,
+		Products:     repo,
+		Service:      service,
+		Inventory:    inventory,
+		Translations: translations,
+		Movements:    movements,
+		Reconcile:    reconcile,
+		Broker:       broker,
+	}
+}
+
+// Do serves a request to h, with body encoded as JSON unless nil
+func Do(t *testing.T, h http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return DoWithHeader(t, h, method, path, body, nil)
+}
+
+// DoWithHeader serves a request to h with the header added, with body encoded as JSON unless nil
+func DoWithHeader(t *testing.T, h http.Handler, method, path string, body any, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range header {
+		req.Header[http.CanonicalHeaderKey(k)] = v
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+// Decode decodes the JSON body of a response
+func Decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var v T
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &v), w.Body.String())
+	return v
+}
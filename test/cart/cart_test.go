@@ -0,0 +1,462 @@
+package cart_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cart "sago-sample/feature/cart/domain"
+	"sago-sample/feature/cart/handler"
+	"sago-sample/feature/cart/infrastructure"
+	usecase "sago-sample/feature/cart/usecase"
+	product "sago-sample/feature/product/domain"
+	productInfra "sago-sample/feature/product/infrastructure"
+	"sago-sample/principal"
+	"sago-sample/test/apitest"
+)
+
+const (
+	ttl            = time.Hour
+	reservationTTL = 30 * time.Minute
+)
+
+type fixture struct {
+	rtr      chi.Router
+	service  *product.Service
+	products product.Repository
+	purge    *usecase.PurgeExpiredCartsUseCase
+	release  *usecase.ReleaseExpiredReservationsUseCase
+	now      *time.Time
+	events   *[]product.Event
+}
+
+// newFixture wires the cart handler to in-memory repositories holding the published products: a laptop (10 units) and a mouse (5 units) in USD,
+// and a keyboard (5 units) in EUR
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+
+	products := productInfra.NewProductRepository()
+	service := product.NewService(products)
+	inventory := product.NewInventoryService(service, productInfra.NewWarehouseRepository())
+	var events []product.Event
+	service.Subscribe(product.EventHandlerFunc(func(_ context.Context, e product.Event) {
+		events = append(events, e)
+	}))
+
+	category, err := product.NewCategory("hardware", "Hardware")
+	require.NoError(t, err)
+	for _, p := range []struct {
+		id, name, currency string
+		price, stock       uint
+	}{
+		{"laptop", "Laptop", "USD", 1000, 10},
+		{"mouse", "Mouse", "USD", 25, 5},
+		{"keyboard", "Keyboard", "EUR", 50, 5},
+	} {
+		price, err := product.NewPrice(p.price, p.currency)
+		require.NoError(t, err)
+		_, err = service.CreateProduct(context.Background(), product.ProductID(p.id), product.ProductName(p.name), "", price, product.NewStock(p.stock))
+		require.NoError(t, err)
+		_, err = service.AddCategoryToProduct(context.Background(), product.ProductID(p.id), category)
+		require.NoError(t, err)
+		_, err = service.ChangeStatus(context.Background(), product.ProductID(p.id), product.StatusPublished)
+		require.NoError(t, err)
+	}
+	events = nil
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	carts := cart.NewService(
+		infrastructure.NewCartRepository(),
+		infrastructure.NewReservationRepository(),
+		products,
+		inventory,
+		cart.Config{TTL: ttl, ReservationTTL: reservationTTL, Clock: product.ClockFunc(func() time.Time { return now })},
+	)
+
+	rtr := chi.NewRouter()
+	handler.NewCartHandler(
+		usecase.NewGetCartUseCase(carts),
+		usecase.NewAddCartItemUseCase(carts),
+		usecase.NewUpdateCartItemUseCase(carts),
+		usecase.NewRemoveCartItemUseCase(carts),
+		usecase.NewClearCartUseCase(carts),
+		usecase.NewReserveCartUseCase(carts),
+		usecase.NewGetReservationUseCase(carts),
+		usecase.NewReleaseReservationUseCase(carts),
+	).Register(rtr)
+
+	return fixture{
+		rtr:      rtr,
+		service:  service,
+		products: products,
+		purge:    usecase.NewPurgeExpiredCartsUseCase(carts),
+		release:  usecase.NewReleaseExpiredReservationsUseCase(carts),
+		now:      &now,
+		events:   &events,
+	}
+}
+
+// do sends a request as the session "s1"
+func (f fixture) do(t *testing.T, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return f.doAs(t, "", "s1", method, path, body)
+}
+
+// doAs sends a request as user, when set, and session
+func (f fixture) doAs(t *testing.T, user, session, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	if session != "" {
+		req.Header.Set(handler.SessionHeader, session)
+	}
+	if user != "" {
+		req = req.WithContext(principal.NewContext(req.Context(), user))
+	}
+	w := httptest.NewRecorder()
+	f.rtr.ServeHTTP(w, req)
+	return w
+}
+
+func (f fixture) add(t *testing.T, productID string, quantity uint) handler.CartResponse {
+	t.Helper()
+
+	w := f.do(t, http.MethodPost, "/api/cart/items", handler.AddCartItemRequest{ProductID: productID, Quantity: quantity})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return apitest.Decode[handler.CartResponse](t, w)
+}
+
+func (f fixture) cart(t *testing.T) handler.CartResponse {
+	t.Helper()
+
+	w := f.do(t, http.MethodGet, "/api/cart", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return apitest.Decode[handler.CartResponse](t, w)
+}
+
+func (f fixture) stock(t *testing.T, id string) uint {
+	t.Helper()
+
+	p, err := f.products.FindByID(context.Background(), product.ProductID(id))
+	require.NoError(t, err)
+	return p.Stock().Quantity()
+}
+
+// reprice changes the price of a product through the product service
+func (f fixture) reprice(t *testing.T, id string, amount uint, currency string) {
+	t.Helper()
+
+	p, err := f.products.FindByID(context.Background(), product.ProductID(id))
+	require.NoError(t, err)
+	price, err := product.NewPrice(amount, currency)
+	require.NoError(t, err)
+	_, err = f.service.UpdateProduct(context.Background(), p.ID(), p.Name(), p.Description(), price, p.Stock())
+	require.NoError(t, err)
+}
+
+// restock replaces the stock of a product through the product service
+func (f fixture) restock(t *testing.T, id string, quantity uint) {
+	t.Helper()
+
+	p, err := f.products.FindByID(context.Background(), product.ProductID(id))
+	require.NoError(t, err)
+	_, err = f.service.UpdateProduct(context.Background(), p.ID(), p.Name(), p.Description(), p.Price(), product.NewStock(quantity))
+	require.NoError(t, err)
+}
+
+func issuesOf(c handler.CartResponse) map[string][]string {
+	issues := make(map[string][]string, len(c.Lines))
+	for _, l := range c.Lines {
+		issues[l.ProductID] = l.Issues
+	}
+	return issues
+}
+
+func TestCart_AddUpdateRemove(t *testing.T) {
+	f := newFixture(t)
+
+	empty := f.cart(t)
+	assert.Equal(t, "session:s1", empty.ID)
+	assert.Empty(t, empty.Lines)
+	assert.Zero(t, empty.Total)
+
+	f.add(t, "laptop", 1)
+	f.add(t, "mouse", 2)
+	c := f.add(t, "laptop", 1)
+	require.Len(t, c.Lines, 2)
+	assert.Equal(t, uint(2), c.Lines[0].Quantity)
+	assert.Equal(t, "USD", c.Currency)
+	assert.Equal(t, uint(2*1000+2*25), c.Total)
+	assert.True(t, c.Valid)
+	assert.Equal(t, f.now.Add(ttl), c.ExpiresAt)
+
+	w := f.do(t, http.MethodPut, "/api/cart/items/mouse", handler.UpdateCartItemRequest{Quantity: 4})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, uint(2*1000+4*25), apitest.Decode[handler.CartResponse](t, w).Total)
+
+	w = f.do(t, http.MethodDelete, "/api/cart/items/laptop", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	c = apitest.Decode[handler.CartResponse](t, w)
+	require.Len(t, c.Lines, 1)
+	assert.Equal(t, "mouse", c.Lines[0].ProductID)
+
+	assert.Equal(t, http.StatusNotFound, f.do(t, http.MethodDelete, "/api/cart/items/laptop", nil).Code)
+	assert.Equal(t, http.StatusNotFound, f.do(t, http.MethodPut, "/api/cart/items/laptop", handler.UpdateCartItemRequest{Quantity: 1}).Code)
+
+	assert.Equal(t, http.StatusNoContent, f.do(t, http.MethodDelete, "/api/cart", nil).Code)
+	assert.Empty(t, f.cart(t).Lines)
+}
+
+func TestCart_Rejections(t *testing.T) {
+	f := newFixture(t)
+	f.add(t, "laptop", 8)
+
+	tests := []struct {
+		name   string
+		req    handler.AddCartItemRequest
+		status int
+	}{
+		{"another currency", handler.AddCartItemRequest{ProductID: "keyboard", Quantity: 1}, http.StatusConflict},
+		{"more than the stock", handler.AddCartItemRequest{ProductID: "laptop", Quantity: 3}, http.StatusConflict},
+		{"unknown product", handler.AddCartItemRequest{ProductID: "tablet", Quantity: 1}, http.StatusNotFound},
+		{"zero quantity", handler.AddCartItemRequest{ProductID: "mouse", Quantity: 0}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := f.do(t, http.MethodPost, "/api/cart/items", tt.req)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+
+	assert.Equal(t, http.StatusBadRequest, f.doAs(t, "", "", http.MethodGet, "/api/cart", nil).Code)
+	assert.Equal(t, uint(8), f.cart(t).Lines[0].Quantity)
+}
+
+func TestCart_KeyedBySessionOrUser(t *testing.T) {
+	f := newFixture(t)
+	f.add(t, "laptop", 1)
+
+	w := f.doAs(t, "alice", "s1", http.MethodPost, "/api/cart/items", handler.AddCartItemRequest{ProductID: "mouse", Quantity: 1})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	alice := apitest.Decode[handler.CartResponse](t, w)
+	assert.Equal(t, "user:alice", alice.ID)
+	require.Len(t, alice.Lines, 1)
+	assert.Equal(t, "mouse", alice.Lines[0].ProductID)
+
+	// The session cart is untouched, and another session has its own cart
+	session := f.cart(t)
+	require.Len(t, session.Lines, 1)
+	assert.Equal(t, "laptop", session.Lines[0].ProductID)
+	w = f.doAs(t, "", "s2", http.MethodGet, "/api/cart", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, apitest.Decode[handler.CartResponse](t, w).Lines)
+}
+
+func TestNewCartID(t *testing.T) {
+	composed, err := cart.NewCartID(cart.OwnerUser, "zoë")
+	require.NoError(t, err)
+	decomposed, err := cart.NewCartID(cart.OwnerUser, "zoe\u0308")
+	require.NoError(t, err)
+	assert.Equal(t, composed, decomposed, "Equivalent user names key the same cart")
+
+	id, err := cart.NewCartID(cart.OwnerUser, strings.Repeat("ü", cart.MaxOwnerLength))
+	require.NoError(t, err)
+	assert.Equal(t, "user:"+strings.Repeat("ü", cart.MaxOwnerLength), id.String())
+
+	for _, owner := range []string{"", "alice\n", "alice\x00", "\u202Ealice", strings.Repeat("ü", cart.MaxOwnerLength+1)} {
+		_, err := cart.NewCartID(cart.OwnerSession, owner)
+		assert.ErrorIs(t, err, cart.ErrInvalidCart, "%q", owner)
+	}
+}
+
+func TestCart_RevalidatesOnRead(t *testing.T) {
+	f := newFixture(t)
+	f.add(t, "laptop", 2)
+	f.add(t, "mouse", 3)
+
+	f.reprice(t, "laptop", 900, "USD")
+	c := f.cart(t)
+	assert.Equal(t, map[string][]string{"laptop": {"price_changed"}, "mouse": {}}, issuesOf(c))
+	assert.Equal(t, uint(1000), c.Lines[0].AddedPrice)
+	assert.Equal(t, uint(900), c.Lines[0].CurrentPrice)
+	assert.Equal(t, uint(2*900+3*25), c.Total, "totals use the current prices")
+	assert.False(t, c.Valid)
+
+	// Updating the line accepts the new price
+	w := f.do(t, http.MethodPut, "/api/cart/items/laptop", handler.UpdateCartItemRequest{Quantity: 2})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, apitest.Decode[handler.CartResponse](t, w).Valid)
+
+	f.restock(t, "mouse", 1)
+	assert.Equal(t, []string{"insufficient_stock"}, issuesOf(f.cart(t))["mouse"])
+	f.restock(t, "mouse", 0)
+	c = f.cart(t)
+	assert.Equal(t, []string{"out_of_stock"}, issuesOf(c)["mouse"])
+	assert.Equal(t, uint(0), c.Lines[1].Available)
+
+	f.reprice(t, "laptop", 900, "EUR")
+	c = f.cart(t)
+	assert.Equal(t, []string{"currency_changed"}, issuesOf(c)["laptop"])
+	assert.Zero(t, c.Lines[0].Total)
+
+	f.restock(t, "mouse", 5)
+	require.NoError(t, f.service.DeleteProduct(context.Background(), "laptop"))
+	c = f.cart(t)
+	assert.Equal(t, map[string][]string{"laptop": {"product_deleted"}, "mouse": {}}, issuesOf(c))
+	assert.Empty(t, c.Lines[0].ProductName)
+	assert.Equal(t, uint(3*25), c.Total, "deleted products are not counted")
+
+	// Deleted products can still be removed
+	w = f.do(t, http.MethodDelete, "/api/cart/items/laptop", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, apitest.Decode[handler.CartResponse](t, w).Valid)
+
+	// Unpublished products stay in the cart but are not counted, and cannot be added again
+	_, err := f.service.ChangeStatus(context.Background(), "mouse", product.StatusDraft)
+	require.NoError(t, err)
+	c = f.cart(t)
+	assert.Equal(t, map[string][]string{"mouse": {"product_unavailable"}}, issuesOf(c))
+	assert.Zero(t, c.Total)
+	assert.False(t, c.Valid)
+	w = f.do(t, http.MethodPut, "/api/cart/items/mouse", handler.UpdateCartItemRequest{Quantity: 1})
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	// So are published products whose availability window closed
+	_, err = f.service.ChangeStatus(context.Background(), "mouse", product.StatusPublished)
+	require.NoError(t, err)
+	window, err := product.NewAvailability(time.Time{}, f.now.Add(time.Minute))
+	require.NoError(t, err)
+	_, err = f.service.SetAvailability(context.Background(), "mouse", window)
+	require.NoError(t, err)
+	assert.True(t, f.cart(t).Valid, "The window is still open")
+	*f.now = f.now.Add(time.Minute)
+	c = f.cart(t)
+	assert.Equal(t, map[string][]string{"mouse": {"product_unavailable"}}, issuesOf(c))
+	w = f.do(t, http.MethodPut, "/api/cart/items/mouse", handler.UpdateCartItemRequest{Quantity: 1})
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+}
+
+func TestCart_Expires(t *testing.T) {
+	f := newFixture(t)
+	f.add(t, "laptop", 1)
+
+	// Every change extends the expiry
+	*f.now = f.now.Add(ttl - time.Minute)
+	c := f.add(t, "mouse", 1)
+	assert.Equal(t, f.now.Add(ttl), c.ExpiresAt)
+
+	*f.now = f.now.Add(ttl - time.Minute)
+	assert.Len(t, f.cart(t).Lines, 2)
+	deleted, err := f.purge.Execute(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	*f.now = f.now.Add(time.Minute)
+	assert.Empty(t, f.cart(t).Lines, "an expired cart reads as empty")
+
+	f.add(t, "laptop", 1)
+	f.doAs(t, "alice", "", http.MethodPost, "/api/cart/items", handler.AddCartItemRequest{ProductID: "mouse", Quantity: 1})
+	*f.now = f.now.Add(ttl)
+	deleted, err = f.purge.Execute(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+}
+
+func TestCart_ReserveAndRelease(t *testing.T) {
+	f := newFixture(t)
+	f.add(t, "laptop", 2)
+	f.add(t, "mouse", 3)
+
+	w := f.do(t, http.MethodPost, "/api/cart/reservations", nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	reservation := apitest.Decode[handler.ReservationResponse](t, w)
+	assert.Equal(t, "session:s1", reservation.CartID)
+	assert.Equal(t, "USD", reservation.Currency)
+	assert.Equal(t, uint(2*1000+3*25), reservation.Total)
+	assert.Equal(t, []handler.ReservationLineResponse{
+		{ProductID: "laptop", Quantity: 2, UnitPrice: 1000, Total: 2000},
+		{ProductID: "mouse", Quantity: 3, UnitPrice: 25, Total: 75},
+	}, reservation.Lines)
+
+	assert.Equal(t, uint(8), f.stock(t, "laptop"))
+	assert.Equal(t, uint(2), f.stock(t, "mouse"))
+	require.Len(t, *f.events, 2)
+	for _, e := range *f.events {
+		require.Len(t, e.Movements, 1)
+		assert.Equal(t, product.MovementReservation, e.Movements[0].Type())
+	}
+	assert.Empty(t, f.cart(t).Lines, "the cart is emptied")
+
+	w = f.do(t, http.MethodGet, "/api/reservations/"+reservation.ID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, reservation.Lines, apitest.Decode[handler.ReservationResponse](t, w).Lines)
+
+	assert.Equal(t, http.StatusNoContent, f.do(t, http.MethodDelete, "/api/reservations/"+reservation.ID, nil).Code)
+	assert.Equal(t, uint(10), f.stock(t, "laptop"))
+	assert.Equal(t, uint(5), f.stock(t, "mouse"))
+	assert.Equal(t, http.StatusNotFound, f.do(t, http.MethodDelete, "/api/reservations/"+reservation.ID, nil).Code)
+}
+
+func TestCart_ReservationsExpire(t *testing.T) {
+	f := newFixture(t)
+	f.add(t, "laptop", 2)
+
+	w := f.do(t, http.MethodPost, "/api/cart/reservations", nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	reservation := apitest.Decode[handler.ReservationResponse](t, w)
+	assert.Equal(t, f.now.Add(reservationTTL), reservation.ExpiresAt)
+	assert.Equal(t, uint(8), f.stock(t, "laptop"))
+
+	*f.now = f.now.Add(reservationTTL - time.Minute)
+	released, err := f.release.Execute(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, released)
+	assert.Equal(t, uint(8), f.stock(t, "laptop"))
+
+	*f.now = f.now.Add(time.Minute)
+	*f.events = nil
+	released, err = f.release.Execute(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+	assert.Equal(t, uint(10), f.stock(t, "laptop"), "the stock is returned")
+	require.Len(t, *f.events, 1)
+	assert.Equal(t, product.MovementReturn, (*f.events)[0].Movements[0].Type())
+	assert.Equal(t, http.StatusNotFound, f.do(t, http.MethodGet, "/api/reservations/"+reservation.ID, nil).Code)
+}
+
+func TestCart_ReserveRequiresReview(t *testing.T) {
+	f := newFixture(t)
+
+	assert.Equal(t, http.StatusBadRequest, f.do(t, http.MethodPost, "/api/cart/reservations", nil).Code, "empty cart")
+
+	f.add(t, "laptop", 2)
+	f.add(t, "mouse", 3)
+	f.reprice(t, "mouse", 30, "USD")
+	*f.events = nil
+
+	w := f.do(t, http.MethodPost, "/api/cart/reservations", nil)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	assert.Equal(t, uint(10), f.stock(t, "laptop"))
+	assert.Empty(t, *f.events)
+	assert.Len(t, f.cart(t).Lines, 2, "the cart is kept")
+
+	// Accepting the new price makes the cart reservable at that price
+	require.Equal(t, http.StatusOK, f.do(t, http.MethodPut, "/api/cart/items/mouse", handler.UpdateCartItemRequest{Quantity: 3}).Code)
+	w = f.do(t, http.MethodPost, "/api/cart/reservations", nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, uint(2*1000+3*30), apitest.Decode[handler.ReservationResponse](t, w).Total)
+}
@@ -0,0 +1,266 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sago-sample/client"
+	"sago-sample/test/apitest"
+)
+
+// newRouter serves the API over in-memory repositories.
+// Products can be published without a category or stock.
+func newRouter(t *testing.T) http.Handler {
+	t.Helper()
+
+	api := apitest.New(t, apitest.Options{})
+	api.Service.SetPublishGuards()
+	return api.Router
+}
+
+func newTestClient(t *testing.T, h http.Handler, opts ...client.Option) *client.ProductClient {
+	t.Helper()
+
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+
+	opts = append([]client.Option{client.WithRetries(3, time.Millisecond, 5*time.Millisecond)}, opts...)
+	c, err := client.NewProductClient(server.URL, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+// createProduct creates and publishes a product
+func createProduct(t *testing.T, c *client.ProductClient, id string) {
+	t.Helper()
+
+	_, err := c.CreateProduct(context.Background(), client.CreateProductRequest{
+		ID:          id,
+		Name:        "Product " + id,
+		Description: "Description " + id,
+		Price:       1000,
+		Currency:    "USD",
+		Stock:       10,
+	})
+	require.NoError(t, err)
+	_, err = c.ChangeStatus(context.Background(), id, client.StatusPublished)
+	require.NoError(t, err)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestProductClient_CRUD(t *testing.T) {
+	c := newTestClient(t, newRouter(t))
+	ctx := context.Background()
+
+	created, err := c.CreateProduct(ctx, client.CreateProductRequest{
+		ID:          "prod-1",
+		Name:        "Smartphone",
+		Description: "Latest model smartphone",
+		Price:       999,
+		Currency:    "USD",
+		Stock:       100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "prod-1", created.ID)
+	assert.Equal(t, client.StatusDraft, created.Status)
+	assert.Empty(t, created.Categories)
+
+	_, err = c.GetProduct(ctx, "prod-1")
+	assert.ErrorIs(t, err, client.ErrNotFound, "Drafts are not public")
+
+	published, err := c.ChangeStatus(ctx, "prod-1", client.StatusPublished)
+	require.NoError(t, err)
+	assert.Equal(t, client.StatusPublished, published.Status)
+
+	got, err := c.GetProduct(ctx, "prod-1")
+	require.NoError(t, err)
+	assert.Equal(t, *published, *got)
+
+	updated, err := c.UpdateProduct(ctx, "prod-1", client.UpdateProductRequest{
+		Name:        "Smartphone Pro",
+		Description: "Latest model smartphone with pro features",
+		Price:       1299,
+		Currency:    "USD",
+		Stock:       50,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Smartphone Pro", updated.Name)
+	assert.Equal(t, uint(50), updated.Stock)
+
+	patched, err := c.PatchProduct(ctx, "prod-1", client.PatchProductRequest{Stock: ptr(uint(7))})
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), patched.Stock)
+	assert.Equal(t, "Smartphone Pro", patched.Name, "Omitted fields should be kept")
+	assert.Equal(t, uint(1299), patched.Price, "Omitted fields should be kept")
+
+	require.NoError(t, c.DeleteProduct(ctx, "prod-1"))
+
+	_, err = c.GetProduct(ctx, "prod-1")
+	assert.ErrorIs(t, err, client.ErrNotFound)
+}
+
+func TestProductClient_Categories(t *testing.T) {
+	c := newTestClient(t, newRouter(t))
+	ctx := context.Background()
+	createProduct(t, c, "prod-1")
+	createProduct(t, c, "prod-2")
+
+	p, err := c.AddCategory(ctx, "prod-1", "cat-1", "Electronics")
+	require.NoError(t, err)
+	assert.Equal(t, []client.Category{{ID: "cat-1", Name: "Electronics"}}, p.Categories)
+
+	products, err := c.ProductsByCategory(ctx, "cat-1")
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	assert.Equal(t, "prod-1", products[0].ID)
+
+	p, err = c.RemoveCategory(ctx, "prod-1", "cat-1")
+	require.NoError(t, err)
+	assert.Empty(t, p.Categories)
+}
+
+func TestProductClient_Pagination(t *testing.T) {
+	c := newTestClient(t, newRouter(t))
+	ctx := context.Background()
+	for _, id := range []string{"prod-3", "prod-1", "prod-5", "prod-2", "prod-4"} {
+		createProduct(t, c, id)
+	}
+
+	page, err := c.ListProducts(ctx, client.ListProductsOptions{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 5, page.Total)
+	require.Len(t, page.Products, 2)
+	assert.Equal(t, "prod-1", page.Products[0].ID)
+	assert.Equal(t, "prod-2", page.NextCursor)
+
+	var ids []string
+	for p, err := range c.Products(ctx, 2) {
+		require.NoError(t, err)
+		ids = append(ids, p.ID)
+	}
+	assert.Equal(t, []string{"prod-1", "prod-2", "prod-3", "prod-4", "prod-5"}, ids)
+
+	// Breaking out of the loop stops fetching pages
+	count := 0
+	for range c.Products(ctx, 2) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, count)
+
+	all, err := c.ListProducts(ctx, client.ListProductsOptions{})
+	require.NoError(t, err)
+	assert.Len(t, all.Products, 5)
+	assert.Empty(t, all.NextCursor)
+}
+
+func TestProductClient_TypedErrors(t *testing.T) {
+	c := newTestClient(t, newRouter(t))
+	ctx := context.Background()
+	createProduct(t, c, "prod-1")
+
+	_, err := c.CreateProduct(ctx, client.CreateProductRequest{ID: "prod-1", Name: "Dup", Price: 1, Currency: "USD"})
+	assert.ErrorIs(t, err, client.ErrConflict)
+
+	_, err = c.CreateProduct(ctx, client.CreateProductRequest{ID: "prod-2", Name: "Bad", Price: 1, Currency: "usd"})
+	require.ErrorIs(t, err, client.ErrBadRequest)
+	var apiErr *client.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "body.currency: must match pattern ^[A-Z]{3}$", apiErr.Message)
+
+	err = c.DeleteProduct(ctx, "missing")
+	assert.ErrorIs(t, err, client.ErrNotFound)
+	assert.NotErrorIs(t, err, client.ErrServer)
+}
+
+func TestProductClient_Retries(t *testing.T) {
+	router := newRouter(t)
+
+	// flaky fails the first two requests of each method, once with 503 and once with 429
+	var gets, posts atomic.Int32
+	flaky := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter := &gets
+		if r.Method == http.MethodPost {
+			counter = &posts
+		}
+		switch counter.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		router.ServeHTTP(w, r)
+	})
+
+	c := newTestClient(t, flaky)
+	ctx := context.Background()
+
+	// POST is not retried on 5xx because it may already have been applied
+	_, err := c.CreateProduct(ctx, client.CreateProductRequest{ID: "prod-1", Name: "P", Price: 1, Currency: "USD"})
+	assert.ErrorIs(t, err, client.ErrServer)
+	assert.Equal(t, int32(1), posts.Load())
+
+	// The next POST gets a 429 first, which is retried
+	_, err = c.CreateProduct(ctx, client.CreateProductRequest{ID: "prod-1", Name: "P", Price: 1, Currency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), posts.Load())
+
+	_, err = c.ChangeStatus(ctx, "prod-1", client.StatusPublished)
+	require.NoError(t, err)
+
+	// GET is retried through both the 503 and the 429
+	p, err := c.GetProduct(ctx, "prod-1")
+	require.NoError(t, err)
+	assert.Equal(t, "prod-1", p.ID)
+	assert.Equal(t, int32(3), gets.Load())
+}
+
+func TestProductClient_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	down := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"database unavailable"}`))
+	})
+
+	c := newTestClient(t, down)
+
+	_, err := c.GetProduct(context.Background(), "prod-1")
+	require.ErrorIs(t, err, client.ErrServer)
+	assert.Contains(t, err.Error(), "database unavailable")
+	assert.Equal(t, int32(4), calls.Load(), "One attempt plus three retries")
+}
+
+func TestProductClient_ContextCancellation(t *testing.T) {
+	down := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	c := newTestClient(t, down, client.WithRetries(10, time.Second, time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetProduct(ctx, "prod-1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
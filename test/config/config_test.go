@@ -0,0 +1,351 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sago-sample/config"
+	product "sago-sample/feature/product/domain"
+	"sago-sample/ratelimit"
+)
+
+// env returns a getenv function reading from vars
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := config.Load(nil, env(nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, config.Default(), cfg)
+	assert.Equal(t, ":8080", cfg.Server.Addr())
+	assert.Equal(t, config.BackendMemory, cfg.Repository.Backend)
+	assert.Equal(t, config.DefaultDatabaseURL, cfg.Database.URL)
+}
+
+func TestLoad_Files(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml", "app.yaml", `
+server:
+  port: 9090
+  writeTimeout: 1m
+repository:
+  backend: postgres
+database:
+  url: postgres://u:p@db:5432/app
+log:
+  level: debug
+`},
+		{"toml", "app.toml", `
+[server]
+port = 9090
+writeTimeout = "1m"
+
+[repository]
+backend = "postgres"
+
+[database]
+url = "postgres://u:p@db:5432/app"
+
+[log]
+level = "debug"
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, tt.file, tt.content)
+
+			cfg, err := config.Load([]string{"-config", path}, env(nil))
+			require.NoError(t, err)
+
+			assert.Equal(t, 9090, cfg.Server.Port)
+			assert.Equal(t, time.Minute, cfg.Server.WriteTimeout)
+			assert.Equal(t, config.BackendPostgres, cfg.Repository.Backend)
+			assert.Equal(t, "postgres://u:p@db:5432/app", cfg.Database.URL)
+			assert.Equal(t, "debug", cfg.Log.Level)
+			// Settings missing from the file keep their defaults
+			assert.Equal(t, config.Default().Server.ReadTimeout, cfg.Server.ReadTimeout)
+		})
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "app.yaml", "server:\n  port: 9000\n  readTimeout: 3s\nlog:\n  level: warn\n")
+
+	// The file is found through CONFIG_FILE; the environment overrides it and flags override both
+	cfg, err := config.Load([]string{"-port", "9002"}, env(map[string]string{
+		config.FileEnv: path,
+		"PORT":         "9001",
+		"LOG_LEVEL":    "error",
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, 9002, cfg.Server.Port)
+	assert.Equal(t, 3*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, "error", cfg.Log.Level)
+}
+
+func TestLoad_DatabaseURLFromComposeVariables(t *testing.T) {
+	vars := map[string]string{
+		"DB_HOST": "db", "DB_PORT": "5432", "DB_USER": "myapp", "DB_PASSWORD": "s3cret", "DB_NAME": "myapp",
+	}
+	cfg, err := config.Load(nil, env(vars))
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://myapp:s3cret@db:5432/myapp?sslmode=disable", cfg.Database.URL)
+
+	// DATABASE_URL wins over the individual variables
+	vars["DATABASE_URL"] = "postgres://other/db"
+	cfg, err = config.Load(nil, env(vars))
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://other/db", cfg.Database.URL)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		vars    map[string]string
+		wantErr []string
+	}{
+		{
+			name:    "invalid values are all reported",
+			vars:    map[string]string{"PORT": "70000", "REPOSITORY_BACKEND": "mysql", "LOG_LEVEL": "verbose"},
+			wantErr: []string{"server.port", "repository.backend", "log.level"},
+		},
+		{
+			name:    "unparsable environment variable",
+			vars:    map[string]string{"SERVER_WRITE_TIMEOUT": "soon"},
+			wantErr: []string{"SERVER_WRITE_TIMEOUT", `invalid duration "soon"`},
+		},
+		{
+			name:    "unparsable flag",
+			args:    []string{"-port", "http"},
+			wantErr: []string{"-port", `invalid integer "http"`},
+		},
+		{
+			name:    "postgres needs a postgres URL",
+			args:    []string{"-repository", "postgres", "-database-url", "mysql://db"},
+			wantErr: []string{"database.url"},
+		},
+		{
+			name:    "shutdown timeout must be positive",
+			vars:    map[string]string{"SERVER_SHUTDOWN_TIMEOUT": "0s"},
+			wantErr: []string{"server.shutdownTimeout"},
+		},
+		{
+			name:    "reconcile interval cannot be negative",
+			args:    []string{"-reconcile-interval", "-1m"},
+			wantErr: []string{"inventory.reconcileInterval"},
+		},
+		{
+			name:    "webhook alerts need a URL",
+			args:    []string{"-alerts", "webhook"},
+			wantErr: []string{"alerts.webhookUrl"},
+		},
+		{
+			name:    "unknown alert notifier",
+			vars:    map[string]string{"ALERTS_NOTIFIER": "sms"},
+			wantErr: []string{"alerts.notifier"},
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"-verbose"},
+			wantErr: []string{"verbose"},
+		},
+		{
+			name:    "missing file",
+			args:    []string{"-config", "/does/not/exist.yaml"},
+			wantErr: []string{"exist.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.Load(tt.args, env(tt.vars))
+			require.Error(t, err)
+			for _, want := range tt.wantErr {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestLoad_RejectsUnknownFileKeys(t *testing.T) {
+	for name, content := range map[string]string{
+		"app.yaml": "server:\n  prot: 9090\n",
+		"app.toml": "[server]\nprot = 9090\n",
+		"app.json": "{}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := config.Load([]string{"-config", writeFile(t, name, content)}, env(nil))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoad_RateLimit(t *testing.T) {
+	path := writeFile(t, "app.yaml", `
+rateLimit:
+  write:
+    requests: 10
+    period: 1m
+  routes:
+    - method: POST
+      route: /api/products
+      limit:
+        requests: 5
+        period: 1m
+        burst: 2
+`)
+
+	cfg, err := config.Load([]string{"-config", path}, env(map[string]string{"RATE_LIMIT_READ": "100/1s/20"}))
+	require.NoError(t, err)
+
+	assert.True(t, cfg.RateLimit.Enabled)
+	assert.Equal(t, ratelimit.Limit{Requests: 100, Period: time.Second, Burst: 20}, cfg.RateLimit.Read)
+	assert.Equal(t, ratelimit.Limit{Requests: 10, Period: time.Minute}, cfg.RateLimit.Write)
+	assert.Equal(t, []ratelimit.Rule{
+		{Method: "POST", Route: "/api/products", Limit: ratelimit.Limit{Requests: 5, Period: time.Minute, Burst: 2}},
+	}, cfg.RateLimit.Routes)
+
+	cfg, err = config.Load([]string{"-rate-limit=false"}, env(nil))
+	require.NoError(t, err)
+	assert.False(t, cfg.RateLimit.Enabled)
+
+	_, err = config.Load(nil, env(map[string]string{"RATE_LIMIT_WRITE": "ten"}))
+	assert.ErrorContains(t, err, "RATE_LIMIT_WRITE")
+}
+
+func TestLoad_Inventory(t *testing.T) {
+	cfg, err := config.Load(nil, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, cfg.Inventory.ReconcileInterval)
+
+	path := writeFile(t, "app.toml", "[inventory]\nreconcileInterval = \"15m\"\n")
+	cfg, err = config.Load([]string{"-config", path}, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, cfg.Inventory.ReconcileInterval)
+
+	cfg, err = config.Load([]string{"-config", path}, env(map[string]string{"INVENTORY_RECONCILE_INTERVAL": "0"}))
+	require.NoError(t, err)
+	assert.Zero(t, cfg.Inventory.ReconcileInterval)
+}
+
+func TestLoad_Cart(t *testing.T) {
+	cfg, err := config.Load(nil, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, cfg.Cart.TTL)
+	assert.Equal(t, 15*time.Minute, cfg.Cart.PurgeInterval)
+
+	path := writeFile(t, "app.yaml", "cart:\n  ttl: 2h\n  purgeInterval: 0s\n")
+	cfg, err = config.Load([]string{"-config", path}, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, cfg.Cart.TTL)
+	assert.Zero(t, cfg.Cart.PurgeInterval)
+
+	cfg, err = config.Load([]string{"-cart-ttl", "30m"}, env(map[string]string{"CART_TTL": "1h"}))
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, cfg.Cart.TTL)
+
+	_, err = config.Load(nil, env(map[string]string{"CART_TTL": "0"}))
+	assert.ErrorContains(t, err, "cart.ttl")
+
+	assert.Equal(t, 30*time.Minute, cfg.Cart.ReservationTTL)
+	cfg, err = config.Load(nil, env(map[string]string{"CART_RESERVATION_TTL": "10m"}))
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, cfg.Cart.ReservationTTL)
+	_, err = config.Load(nil, env(map[string]string{"CART_RESERVATION_TTL": "0"}))
+	assert.ErrorContains(t, err, "cart.reservationTTL")
+}
+
+func TestLoad_PriceBuckets(t *testing.T) {
+	cfg, err := config.Load(nil, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1000, 5000, 10000, 50000}, cfg.Catalog.PriceBuckets)
+
+	path := writeFile(t, "app.yaml", "catalog:\n  priceBuckets: [2000, 8000]\n")
+	cfg, err = config.Load([]string{"-config", path}, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, []uint{2000, 8000}, cfg.Catalog.PriceBuckets)
+
+	cfg, err = config.Load([]string{"-config", path}, env(map[string]string{"CATALOG_PRICE_BUCKETS": "500, 1500"}))
+	require.NoError(t, err)
+	assert.Equal(t, []uint{500, 1500}, cfg.Catalog.PriceBuckets)
+
+	_, err = config.Load(nil, env(map[string]string{"CATALOG_PRICE_BUCKETS": "5000,1000"}))
+	assert.ErrorContains(t, err, "catalog.priceBuckets")
+	_, err = config.Load(nil, env(map[string]string{"CATALOG_PRICE_BUCKETS": "cheap"}))
+	assert.ErrorContains(t, err, "CATALOG_PRICE_BUCKETS")
+}
+
+func TestLoad_Media(t *testing.T) {
+	cfg, err := config.Load(nil, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "media", cfg.Media.Dir)
+	assert.Equal(t, 10<<20, cfg.Media.MaxImageSize)
+	assert.Equal(t, 256, cfg.Media.ThumbnailSize)
+
+	cfg, err = config.Load([]string{"-media-dir", "/var/lib/media"}, env(map[string]string{
+		"MEDIA_DIR":            "/tmp/media",
+		"MEDIA_MAX_IMAGE_SIZE": "1048576",
+		"MEDIA_THUMBNAIL_SIZE": "128",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/media", cfg.Media.Dir, "Flags take precedence over the environment")
+	limits := cfg.Media.ImageLimits()
+	assert.Equal(t, int64(1<<20), limits.MaxSize)
+	assert.Equal(t, 128, limits.ThumbnailSize)
+
+	_, err = config.Load(nil, env(map[string]string{"MEDIA_THUMBNAIL_SIZE": "0"}))
+	assert.ErrorContains(t, err, "media.thumbnailSize")
+}
+
+func TestLoad_Locales(t *testing.T) {
+	cfg, err := config.Load(nil, env(nil))
+	require.NoError(t, err)
+	locales, err := cfg.Catalog.SupportedLocales()
+	require.NoError(t, err)
+	assert.Equal(t, product.DefaultLocales, locales)
+
+	path := writeFile(t, "app.yaml", "catalog:\n  defaultLocale: fr\n  locales: [en, ja]\n")
+	cfg, err = config.Load([]string{"-config", path}, env(map[string]string{"CATALOG_LOCALES": "en, pt_BR,"}))
+	require.NoError(t, err)
+	locales, err = cfg.Catalog.SupportedLocales()
+	require.NoError(t, err)
+	assert.Equal(t, product.Locale("fr"), locales.Default())
+	assert.Equal(t, []product.Locale{"fr", "en", "pt-BR"}, locales.Supported(), "the environment replaces the file's list")
+
+	_, err = config.Load([]string{"-default-locale", "french"}, env(nil))
+	assert.ErrorContains(t, err, "catalog.locales")
+}
+
+func TestLoad_Alerts(t *testing.T) {
+	cfg, err := config.Load(nil, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "log", cfg.Alerts.Notifier)
+	assert.Equal(t, time.Hour, cfg.Alerts.Cooldown)
+
+	path := writeFile(t, "app.yaml", "alerts:\n  notifier: webhook\n  webhookUrl: https://ops.example.com/alerts\n")
+	cfg, err = config.Load([]string{"-config", path}, env(map[string]string{"ALERTS_COOLDOWN": "10m"}))
+	require.NoError(t, err)
+	assert.Equal(t, config.AlertsConfig{Notifier: "webhook", WebhookURL: "https://ops.example.com/alerts", Cooldown: 10 * time.Minute}, cfg.Alerts)
+}
@@ -0,0 +1,133 @@
+package textnorm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sago-sample/internal/textnorm"
+)
+
+func TestLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"ascii", "Laptop Pro", "Laptop Pro"},
+		{"trims", "  Laptop  ", "Laptop"},
+		{"collapses whitespace runs", "Laptop \t\n  Pro", "Laptop Pro"},
+		{"no-break and ideographic spaces", "Laptop\u00A0Pro\u3000Max", "Laptop Pro Max"},
+		{"line separator", "Laptop\u2028Pro", "Laptop Pro"},
+		{"composes French", "Cafe\u0301 cre\u0300me", "Café crème"},
+		{"already composed", "Café crème", "Café crème"},
+		{"composes Vietnamese", "Vie\u0323\u0302t Nam", "Việt Nam"},
+		{"composes Korean jamo", "\u1112\u1161\u11AB\u1100\u1173\u11AF", "한글"},
+		{"Japanese", "ノートパソコン", "ノートパソコン"},
+		{"Chinese", "笔记本电脑", "笔记本电脑"},
+		{"Arabic with a right-to-left mark", "حاسوب\u200F محمول", "حاسوب\u200F محمول"},
+		{"Hebrew", "מחשב נייד", "מחשב נייד"},
+		{"Hindi", "लैपटॉप", "लैपटॉप"},
+		{"emoji sequence", "Family 👨\u200D👩\u200D👧", "Family 👨\u200D👩\u200D👧"},
+		{"only whitespace", " \t\n ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := textnorm.Line(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLine_Rejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{"invalid UTF-8", "Laptop\xff", textnorm.ErrInvalidUTF8},
+		{"NUL", "Lap\x00top", textnorm.ErrControlCharacter},
+		{"escape sequence", "\x1b[31mLaptop", textnorm.ErrControlCharacter},
+		{"delete", "Laptop\x7f", textnorm.ErrControlCharacter},
+		{"C1 control", "Laptop\u009b", textnorm.ErrControlCharacter},
+		{"right-to-left override", "Laptop \u202Egpj.exe", textnorm.ErrBidiControl},
+		{"left-to-right embedding", "\u202ALaptop", textnorm.ErrBidiControl},
+		{"pop directional formatting", "Laptop\u202C", textnorm.ErrBidiControl},
+		{"right-to-left isolate", "\u2067Laptop\u2069", textnorm.ErrBidiControl},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := textnorm.Line(tt.input)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestBlock(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"single line", "  A light   laptop ", "A light laptop"},
+		{"keeps line breaks", "Light\nFast", "Light\nFast"},
+		{"windows and mac line breaks", "Light\r\nFast\rQuiet", "Light\nFast\nQuiet"},
+		{"trims each line", "Light  \n\t Fast", "Light\nFast"},
+		{"keeps one blank line", "Light\n\n\n\nFast", "Light\n\nFast"},
+		{"paragraph separator", "Light\u2029Fast", "Light\nFast"},
+		{"blank lines around", "\n\n Light \n\n", "Light"},
+		{"composes", "Ordinateur le\u0301ger\n\nTre\u0300s rapide", "Ordinateur léger\n\nTrès rapide"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := textnorm.Block(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := textnorm.Block("Light\n\u202EFast")
+	assert.ErrorIs(t, err, textnorm.ErrBidiControl)
+	_, err = textnorm.Block("Light\x00")
+	assert.ErrorIs(t, err, textnorm.ErrControlCharacter)
+}
+
+func TestIdentifier(t *testing.T) {
+	got, err := textnorm.Identifier("Zoe\u0308")
+	require.NoError(t, err)
+	assert.Equal(t, "Zoë", got)
+
+	got, err = textnorm.Identifier(" alice ")
+	require.NoError(t, err)
+	assert.Equal(t, " alice ", got, "Identifiers keep their spaces")
+
+	for _, input := range []string{"alice\tbob", "alice\n", "\u202Ealice", "alice\xff"} {
+		_, err := textnorm.Identifier(input)
+		assert.Error(t, err, "%q", input)
+	}
+}
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{"ascii", "Laptop", 6},
+		{"composed", "Café", 4},
+		{"decomposed counts its NFC form", "Cafe\u0301", 4},
+		{"Japanese", "ノートパソコン", 7},
+		{"Hangul jamo", "\u1112\u1161\u11AB", 1},
+		{"emoji outside the BMP", "💻", 1},
+		{"long Japanese name", strings.Repeat("日本", 20), 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, textnorm.Length(tt.input))
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sago-sample/feature/product/infrastructure"
+	"sago-sample/feature/product/infrastructure/cache"
+	"sago-sample/observability/health"
+)
+
+// pingingRepository stands in for a SQL repository whose database may be down
+type pingingRepository struct {
+	*infrastructure.ProductRepository
+	err error
+}
+
+func (r *pingingRepository) Ping(ctx context.Context) error {
+	return r.err
+}
+
+func get(t *testing.T, h http.HandlerFunc) (int, health.Response) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var resp health.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp
+}
+
+func TestHealth_Live(t *testing.T) {
+	h := health.NewHandler(health.PingCheck("repository", &pingingRepository{err: errors.New("connection refused")}))
+
+	code, resp := get(t, h.HandleLive)
+	assert.Equal(t, http.StatusOK, code, "liveness does not depend on the database")
+	assert.Equal(t, "ok", resp.Status)
+}
+
+func TestHealth_Ready(t *testing.T) {
+	tests := []struct {
+		name   string
+		repo   any
+		want   int
+		status string
+		check  string
+	}{
+		{"memory repository", infrastructure.NewProductRepository(), http.StatusOK, "ok", "ok"},
+		{"database up", &pingingRepository{ProductRepository: infrastructure.NewProductRepository()}, http.StatusOK, "ok", "ok"},
+		{"database down", &pingingRepository{ProductRepository: infrastructure.NewProductRepository(), err: errors.New("connection refused")}, http.StatusServiceUnavailable, "unavailable", "connection refused"},
+		{
+			"database down behind the cache",
+			cache.NewRepository(&pingingRepository{ProductRepository: infrastructure.NewProductRepository(), err: errors.New("connection refused")}, cache.NewLRUCache(10), cache.Options{}),
+			http.StatusServiceUnavailable, "unavailable", "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := health.NewHandler(health.PingCheck("repository", tt.repo))
+
+			code, resp := get(t, h.HandleReady)
+			assert.Equal(t, tt.want, code)
+			assert.Equal(t, tt.status, resp.Status)
+			assert.Equal(t, tt.check, resp.Checks["repository"])
+		})
+	}
+}
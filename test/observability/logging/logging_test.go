@@ -0,0 +1,176 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sago-sample/feature/product/infrastructure"
+	usecase "sago-sample/feature/product/usecase"
+	"sago-sample/observability/logging"
+	"sago-sample/principal"
+)
+
+// records decodes the JSON log lines written to buf
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &rec), line)
+		out = append(out, rec)
+	}
+	return out
+}
+
+func newRouter(t *testing.T, buf *bytes.Buffer, level string) chi.Router {
+	t.Helper()
+
+	logger, err := logging.New(buf, logging.Config{Format: logging.FormatJSON, Level: level})
+	require.NoError(t, err)
+
+	rtr := chi.NewRouter()
+	rtr.Use(logging.Middleware(logger))
+	rtr.Get("/api/products/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logging.FromContext(r.Context()).Info("handling", "id", chi.URLParam(r, "id"))
+		if chi.URLParam(r, "id") == "boom" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(logging.RequestID(r.Context())))
+	})
+	return rtr
+}
+
+func TestMiddleware_AssignsRequestIDAndLogsRequest(t *testing.T) {
+	var buf bytes.Buffer
+	rtr := newRouter(t, &buf, "info")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/products/p1", nil)
+	req.Header.Set(principal.APIKeyHeader, "secret-key")
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, req)
+
+	id := w.Header().Get(logging.RequestIDHeader)
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, w.Body.String())
+
+	recs := records(t, &buf)
+	require.Len(t, recs, 2)
+
+	// The handler's log line carries the request ID
+	assert.Equal(t, "handling", recs[0]["msg"])
+	assert.Equal(t, id, recs[0]["request_id"])
+
+	access := recs[1]
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "INFO", access["level"])
+	assert.Equal(t, id, access["request_id"])
+	assert.Equal(t, "GET", access["method"])
+	assert.Equal(t, "/api/products/{id}", access["route"])
+	assert.Equal(t, "/api/products/p1", access["path"])
+	assert.Equal(t, float64(200), access["status"])
+	assert.Contains(t, access, "latency_ms")
+	assert.Equal(t, "apikey:"+principal.Fingerprint("secret-key"), access["principal"])
+	assert.NotContains(t, buf.String(), "secret-key")
+}
+
+func TestMiddleware_PropagatesRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	rtr := newRouter(t, &buf, "info")
+
+	tests := []struct {
+		name   string
+		header string
+		reused bool
+	}{
+		{"well-formed ID is reused", "req-123_abc.def", true},
+		{"ID with a newline is replaced", "abc\ninjected", false},
+		{"ID with spaces is replaced", "a b", false},
+		{"overlong ID is replaced", strings.Repeat("a", 129), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/products/p1", nil)
+			req.Header.Set(logging.RequestIDHeader, tt.header)
+			w := httptest.NewRecorder()
+			rtr.ServeHTTP(w, req)
+
+			got := w.Header().Get(logging.RequestIDHeader)
+			if tt.reused {
+				assert.Equal(t, tt.header, got)
+			} else {
+				assert.NotEqual(t, tt.header, got)
+				assert.Len(t, got, 32)
+			}
+		})
+	}
+}
+
+func TestMiddleware_LevelsAndPrincipal(t *testing.T) {
+	var buf bytes.Buffer
+	rtr := newRouter(t, &buf, "warn")
+
+	// Successful requests are logged at info, below the configured level
+	rtr.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/products/p1", nil))
+	assert.Empty(t, buf.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/products/boom", nil)
+	req = req.WithContext(principal.NewContext(req.Context(), "user:alice"))
+	rtr.ServeHTTP(httptest.NewRecorder(), req)
+
+	recs := records(t, &buf)
+	require.Len(t, recs, 1)
+	assert.Equal(t, "ERROR", recs[0]["level"])
+	assert.Equal(t, float64(500), recs[0]["status"])
+	assert.Equal(t, "user:alice", recs[0]["principal"])
+}
+
+func TestUseCaseLogger(t *testing.T) {
+	t.Cleanup(usecase.AddObserver(logging.UseCaseLogger{}))
+
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Config{Level: "debug"})
+	require.NoError(t, err)
+	ctx := logging.NewContext(context.Background(), logger.With("request_id", "r-1"))
+
+	uc := usecase.NewGetProductUseCase(infrastructure.NewProductRepository())
+	_, err = uc.Execute(ctx, usecase.GetProductInput{ID: "missing"})
+	require.Error(t, err)
+
+	recs := records(t, &buf)
+	require.Len(t, recs, 1)
+	assert.Equal(t, "use case executed", recs[0]["msg"])
+	assert.Equal(t, "GetProduct", recs[0]["usecase"])
+	assert.Equal(t, "r-1", recs[0]["request_id"])
+	assert.Contains(t, recs[0]["error"], "not found")
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Config{Format: "text", Level: "DEBUG"})
+	require.NoError(t, err)
+	logger.Debug("hello", "k", "v")
+	assert.Contains(t, buf.String(), "level=DEBUG msg=hello k=v")
+
+	assert.True(t, logging.FromContext(context.Background()) == slog.Default())
+
+	_, err = logging.New(&buf, logging.Config{Format: "xml"})
+	assert.Error(t, err)
+	_, err = logging.New(&buf, logging.Config{Level: "verbose"})
+	assert.Error(t, err)
+}
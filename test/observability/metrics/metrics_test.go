@@ -0,0 +1,210 @@
+package metrics_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/handler"
+	"sago-sample/feature/product/infrastructure/cache"
+	usecase "sago-sample/feature/product/usecase"
+	"sago-sample/observability/metrics"
+	"sago-sample/test/apitest"
+)
+
+// newRouter serves the API with metrics on every layer.
+// Products can be published without a category.
+func newRouter(t *testing.T) (chi.Router, *metrics.Metrics) {
+	t.Helper()
+
+	m := metrics.New()
+	t.Cleanup(usecase.AddObserver(m))
+
+	api := apitest.New(t, apitest.Options{
+		Products: func(products domain.Repository) domain.Repository {
+			repo := cache.NewRepository(metrics.NewProductRepository(products, m), cache.NewLRUCache(100), cache.Options{})
+			require.NoError(t, m.Register(metrics.NewProductCollector(repo), metrics.NewCacheCollector(repo.Stats)))
+			return repo
+		},
+		Middlewares: []func(http.Handler) http.Handler{m.Middleware},
+		Metrics:     m.Handler(),
+	})
+	api.Service.SetPublishGuards()
+	return api.Router, m
+}
+
+// find returns the metric of a family whose labels include want
+func find(t *testing.T, m *metrics.Metrics, name string, want map[string]string) *dto.Metric {
+	t.Helper()
+
+	families, err := m.Registry().Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	next:
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range metric.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			for k, v := range want {
+				if labels[k] != v {
+					continue next
+				}
+			}
+			return metric
+		}
+	}
+	return nil
+}
+
+func counter(t *testing.T, m *metrics.Metrics, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	metric := find(t, m, name, labels)
+	require.NotNil(t, metric, "%s%v not found", name, labels)
+	return metric.GetCounter().GetValue()
+}
+
+func histogramCount(t *testing.T, m *metrics.Metrics, name string, labels map[string]string) uint64 {
+	t.Helper()
+
+	metric := find(t, m, name, labels)
+	require.NotNil(t, metric, "%s%v not found", name, labels)
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func gauge(t *testing.T, m *metrics.Metrics, name string) float64 {
+	t.Helper()
+
+	metric := find(t, m, name, nil)
+	require.NotNil(t, metric, "%s not found", name)
+	return metric.GetGauge().GetValue()
+}
+
+func createProduct(t *testing.T, rtr chi.Router, id string, stock uint) {
+	t.Helper()
+
+	w := apitest.Do(t, rtr, http.MethodPost, "/api/products", handler.CreateProductRequest{
+		ID: id, Name: "Laptop", Description: "A laptop", Price: 1000, Currency: "USD", Stock: stock,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+// publish makes a product visible to the public reads
+func publish(t *testing.T, rtr chi.Router, id string) {
+	t.Helper()
+
+	w := apitest.Do(t, rtr, http.MethodPost, "/api/admin/products/"+id+"/status", handler.ChangeStatusRequest{Status: "published"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestMetrics_HTTPRequestsByRoutePattern(t *testing.T) {
+	rtr, m := newRouter(t)
+
+	createProduct(t, rtr, "p1", 5)
+	publish(t, rtr, "p1")
+	apitest.Do(t, rtr, http.MethodGet, "/api/products/p1", nil)
+	apitest.Do(t, rtr, http.MethodGet, "/api/products/p2", nil)
+	apitest.Do(t, rtr, http.MethodPost, "/api/products", map[string]any{"id": "p3"})
+	apitest.Do(t, rtr, http.MethodGet, "/does/not/exist", nil)
+
+	assert.Equal(t, 1.0, counter(t, m, "http_requests_total", map[string]string{"method": "POST", "route": "/api/products", "status": "201"}))
+	assert.Equal(t, 1.0, counter(t, m, "http_requests_total", map[string]string{"method": "GET", "route": "/api/products/{id}", "status": "200"}))
+	assert.Equal(t, 1.0, counter(t, m, "http_requests_total", map[string]string{"method": "GET", "route": "/api/products/{id}", "status": "404"}))
+	assert.Equal(t, 1.0, counter(t, m, "http_requests_total", map[string]string{"method": "POST", "route": "/api/products", "status": "400"}), "requests rejected by validation keep their route")
+	assert.Equal(t, 1.0, counter(t, m, "http_requests_total", map[string]string{"method": "GET", "route": "unmatched", "status": "404"}))
+	assert.Equal(t, uint64(1), histogramCount(t, m, "http_request_duration_seconds", map[string]string{"method": "GET", "route": "/api/products/{id}", "status": "404"}))
+}
+
+func TestMetrics_UseCasesAndRepository(t *testing.T) {
+	rtr, m := newRouter(t)
+
+	createProduct(t, rtr, "p1", 5)
+	w := apitest.Do(t, rtr, http.MethodPost, "/api/products", handler.CreateProductRequest{
+		ID: "p1", Name: "Laptop", Description: "A laptop", Price: 1000, Currency: "USD", Stock: 5,
+	})
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	assert.Equal(t, uint64(1), histogramCount(t, m, "usecase_duration_seconds", map[string]string{"usecase": "CreateProduct", "result": "success"}))
+	assert.Equal(t, uint64(1), histogramCount(t, m, "usecase_duration_seconds", map[string]string{"usecase": "CreateProduct", "result": "error"}))
+	assert.Equal(t, 1.0, counter(t, m, "usecase_errors_total", map[string]string{"usecase": "CreateProduct"}))
+
+	assert.Equal(t, uint64(1), histogramCount(t, m, "repository_operation_duration_seconds", map[string]string{"repository": "product", "operation": "Save", "result": "success"}))
+	// Both existence checks reach the repository; the not-found answer to the first one is not a failure
+	assert.Equal(t, uint64(2), histogramCount(t, m, "repository_operation_duration_seconds", map[string]string{"operation": "FindByID", "result": "success"}))
+}
+
+func TestMetrics_DomainGauges(t *testing.T) {
+	rtr, m := newRouter(t)
+
+	createProduct(t, rtr, "p1", 5)
+	createProduct(t, rtr, "p2", 0)
+	createProduct(t, rtr, "p3", 0)
+
+	assert.Equal(t, 3.0, gauge(t, m, "products"))
+	assert.Equal(t, 2.0, gauge(t, m, "products_out_of_stock"))
+
+	apitest.Do(t, rtr, http.MethodDelete, "/api/products/p3", nil)
+	assert.Equal(t, 2.0, gauge(t, m, "products"))
+	assert.Equal(t, 1.0, gauge(t, m, "products_out_of_stock"))
+}
+
+func TestMetrics_CacheLookups(t *testing.T) {
+	rtr, m := newRouter(t)
+
+	createProduct(t, rtr, "p1", 5)
+	publish(t, rtr, "p1")
+	apitest.Do(t, rtr, http.MethodGet, "/api/products/p1", nil)
+	apitest.Do(t, rtr, http.MethodGet, "/api/products/p1", nil)
+
+	assert.Equal(t, 1.0, counter(t, m, "product_cache_lookups_total", map[string]string{"result": "hit"}))
+}
+
+func TestMetrics_Endpoint(t *testing.T) {
+	rtr, _ := newRouter(t)
+	createProduct(t, rtr, "p1", 5)
+
+	w := apitest.Do(t, rtr, http.MethodGet, "/metrics", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.Contains(t, body, `http_requests_total{method="POST",route="/api/products",status="201"} 1`)
+	assert.Contains(t, body, "products 1")
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestMetrics_MiddlewareKeepsStreaming(t *testing.T) {
+	rtr, _ := newRouter(t)
+	server := httptest.NewServer(rtr)
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/products/stream", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if errors.Is(err, context.Canceled) {
+		t.Fatal("stream was not flushed")
+	}
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, "retry:"))
+}
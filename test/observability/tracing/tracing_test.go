@@ -0,0 +1,198 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"sago-sample/client"
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/handler"
+	usecase "sago-sample/feature/product/usecase"
+	"sago-sample/observability/tracing"
+	"sago-sample/test/apitest"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// newRouter serves the API with tracing on every layer, recording spans in memory.
+// Products can be published without a category.
+func newRouter(t *testing.T) (chi.Router, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	tracer := tracing.New(tp, nil)
+	t.Cleanup(usecase.AddObserver(tracer))
+
+	api := apitest.New(t, apitest.Options{
+		Products: func(products domain.Repository) domain.Repository {
+			return tracing.NewProductRepository(products, tracer)
+		},
+		Middlewares: []func(http.Handler) http.Handler{tracer.Middleware},
+	})
+	api.Service.SetPublishGuards()
+	return api.Router, exporter
+}
+
+func createProduct(t *testing.T, rtr chi.Router, id string) {
+	t.Helper()
+
+	w := apitest.Do(t, rtr, http.MethodPost, "/api/products", handler.CreateProductRequest{
+		ID: id, Name: "Laptop", Description: "A laptop", Price: 1000, Currency: "USD", Stock: 5,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+// publish makes a product visible to the public reads
+func publish(t *testing.T, rtr chi.Router, id string) {
+	t.Helper()
+
+	w := apitest.Do(t, rtr, http.MethodPost, "/api/admin/products/"+id+"/status", handler.ChangeStatusRequest{Status: "published"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+// span returns the only recorded span with the given name
+func span(t *testing.T, exporter *tracetest.InMemoryExporter, name string) tracetest.SpanStub {
+	t.Helper()
+
+	var found []tracetest.SpanStub
+	for _, s := range exporter.GetSpans() {
+		if s.Name == name {
+			found = append(found, s)
+		}
+	}
+	require.Len(t, found, 1, "spans named %q", name)
+	return found[0]
+}
+
+func attr(s tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range s.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracing_SpansAcrossLayers(t *testing.T) {
+	rtr, exporter := newRouter(t)
+	createProduct(t, rtr, "p1")
+	publish(t, rtr, "p1")
+	exporter.Reset()
+
+	w := apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products/p1", nil, http.Header{"Traceparent": {traceparent}})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	server := span(t, exporter, "GET /api/products/{id}")
+	uc := span(t, exporter, "usecase.GetProduct")
+	repo := span(t, exporter, "ProductRepository.FindByID")
+
+	// The request continues the caller's trace
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.True(t, server.Parent.IsRemote())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "/api/products/{id}", attr(server, "http.route").AsString())
+	assert.Equal(t, int64(200), attr(server, "http.response.status_code").AsInt64())
+	assert.Equal(t, "p1", attr(server, tracing.ProductIDKey).AsString())
+
+	// handler → use case → repository
+	assert.Equal(t, server.SpanContext.SpanID(), uc.Parent.SpanID())
+	assert.Equal(t, uc.SpanContext.SpanID(), repo.Parent.SpanID())
+	assert.Equal(t, server.SpanContext.TraceID(), repo.SpanContext.TraceID())
+	assert.Equal(t, "GetProduct", attr(uc, tracing.UseCaseKey).AsString())
+	assert.Equal(t, "p1", attr(repo, tracing.ProductIDKey).AsString())
+}
+
+func TestTracing_WritesAndCategories(t *testing.T) {
+	rtr, exporter := newRouter(t)
+	createProduct(t, rtr, "p1")
+
+	assert.Equal(t, "p1", attr(span(t, exporter, "ProductRepository.Save"), tracing.ProductIDKey).AsString())
+	assert.Equal(t, codes.Unset, span(t, exporter, "usecase.CreateProduct").Status.Code)
+	exporter.Reset()
+
+	w := apitest.Do(t, rtr, http.MethodPost, "/api/products/p1/categories", handler.AddCategoryToProductRequest{CategoryID: "c1", CategoryName: "Computers"})
+	require.Less(t, w.Code, 300, w.Body.String())
+	server := span(t, exporter, "POST /api/products/{id}/categories")
+	assert.Equal(t, "p1", attr(server, tracing.ProductIDKey).AsString())
+	span(t, exporter, "usecase.AddCategoryToProduct")
+	publish(t, rtr, "p1")
+	exporter.Reset()
+
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/categories/c1/products", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "c1", attr(span(t, exporter, "GET /api/categories/{id}/products"), tracing.CategoryIDKey).AsString())
+	repo := span(t, exporter, "ProductRepository.FindByCategory")
+	assert.Equal(t, "c1", attr(repo, tracing.CategoryIDKey).AsString())
+	assert.Equal(t, int64(1), attr(repo, tracing.ProductCountKey).AsInt64())
+}
+
+func TestTracing_Errors(t *testing.T) {
+	rtr, exporter := newRouter(t)
+
+	w := apitest.Do(t, rtr, http.MethodGet, "/api/products/missing", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// The use case failed, but a missing row is a normal repository answer and a 4xx is not a server error
+	uc := span(t, exporter, "usecase.GetProduct")
+	assert.Equal(t, codes.Error, uc.Status.Code)
+	require.NotEmpty(t, uc.Events)
+	assert.Equal(t, "exception", uc.Events[0].Name)
+	assert.Equal(t, codes.Unset, span(t, exporter, "ProductRepository.FindByID").Status.Code)
+	assert.Equal(t, codes.Unset, span(t, exporter, "GET /api/products/{id}").Status.Code)
+
+	// A new trace is started when the request has none
+	assert.False(t, span(t, exporter, "GET /api/products/{id}").Parent.IsValid())
+}
+
+func TestTracing_ClientPropagatesTraceContext(t *testing.T) {
+	rtr, exporter := newRouter(t)
+	createProduct(t, rtr, "p1")
+	publish(t, rtr, "p1")
+	exporter.Reset()
+
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(tracing.Propagator())
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	srv := httptest.NewServer(rtr)
+	t.Cleanup(srv.Close)
+	c, err := client.NewProductClient(srv.URL)
+	require.NoError(t, err)
+
+	callerTP := sdktrace.NewTracerProvider()
+	ctx, caller := callerTP.Tracer("test").Start(context.Background(), "caller")
+	_, err = c.GetProduct(ctx, "p1")
+	caller.End()
+	require.NoError(t, err)
+
+	server := span(t, exporter, "GET /api/products/{id}")
+	assert.Equal(t, caller.SpanContext().TraceID(), server.SpanContext.TraceID())
+	assert.Equal(t, caller.SpanContext().SpanID(), server.Parent.SpanID())
+}
+
+func TestNewProvider(t *testing.T) {
+	for _, exporter := range []string{"", tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP} {
+		tp, err := tracing.NewProvider(context.Background(), tracing.Config{Exporter: exporter, OTLPEndpoint: "http://localhost:4318/v1/traces"})
+		require.NoError(t, err, exporter)
+		require.NoError(t, tp.Shutdown(context.Background()))
+	}
+
+	_, err := tracing.NewProvider(context.Background(), tracing.Config{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,378 @@
+package order_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	order "sago-sample/feature/order/domain"
+	"sago-sample/feature/order/handler"
+	"sago-sample/feature/order/infrastructure"
+	usecase "sago-sample/feature/order/usecase"
+	product "sago-sample/feature/product/domain"
+	productInfra "sago-sample/feature/product/infrastructure"
+	"sago-sample/test/apitest"
+)
+
+// failingOrders is an order repository whose saves fail while fail is set
+type failingOrders struct {
+	order.Repository
+	fail bool
+}
+
+func (r *failingOrders) Save(ctx context.Context, o *order.Order) error {
+	if r.fail {
+		return errors.New("database unavailable")
+	}
+	return r.Repository.Save(ctx, o)
+}
+
+type fixture struct {
+	rtr      chi.Router
+	service  *product.Service
+	products product.Repository
+	orders   *failingOrders
+	events   *[]product.Event
+}
+
+// newFixture wires the order handler to in-memory repositories holding the published products: a laptop (10 units) and a mouse (5 units) in USD,
+// and a keyboard (5 units) in EUR
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+
+	products := productInfra.NewProductRepository()
+	service := product.NewService(products)
+	inventory := product.NewInventoryService(service, productInfra.NewWarehouseRepository())
+	var events []product.Event
+	service.Subscribe(product.EventHandlerFunc(func(_ context.Context, e product.Event) {
+		events = append(events, e)
+	}))
+
+	category, err := product.NewCategory("hardware", "Hardware")
+	require.NoError(t, err)
+	for _, p := range []struct {
+		id, name, currency string
+		price, stock       uint
+	}{
+		{"laptop", "Laptop", "USD", 1000, 10},
+		{"mouse", "Mouse", "USD", 25, 5},
+		{"keyboard", "Keyboard", "EUR", 50, 5},
+	} {
+		price, err := product.NewPrice(p.price, p.currency)
+		require.NoError(t, err)
+		_, err = service.CreateProduct(context.Background(), product.ProductID(p.id), product.ProductName(p.name), "", price, product.NewStock(p.stock))
+		require.NoError(t, err)
+		_, err = service.AddCategoryToProduct(context.Background(), product.ProductID(p.id), category)
+		require.NoError(t, err)
+		_, err = service.ChangeStatus(context.Background(), product.ProductID(p.id), product.StatusPublished)
+		require.NoError(t, err)
+	}
+	events = nil
+
+	orders := &failingOrders{Repository: infrastructure.NewOrderRepository()}
+	orderService := order.NewService(orders, products, inventory)
+
+	rtr := chi.NewRouter()
+	handler.NewOrderHandler(
+		usecase.NewPlaceOrderUseCase(orderService),
+		usecase.NewGetOrderUseCase(orders),
+		usecase.NewListOrdersUseCase(orders),
+		usecase.NewCancelOrderUseCase(orderService),
+		usecase.NewFulfilOrderUseCase(orderService),
+	).Register(rtr)
+
+	return fixture{rtr: rtr, service: service, products: products, orders: orders, events: &events}
+}
+
+func (f fixture) stock(t *testing.T, id string) uint {
+	t.Helper()
+
+	p, err := f.products.FindByID(context.Background(), product.ProductID(id))
+	require.NoError(t, err)
+	return p.Stock().Quantity()
+}
+
+func (f fixture) place(t *testing.T, lines ...handler.PlaceOrderLineRequest) handler.OrderResponse {
+	t.Helper()
+
+	w := apitest.Do(t, f.rtr, http.MethodPost, "/api/orders", handler.PlaceOrderRequest{Lines: lines})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	return apitest.Decode[handler.OrderResponse](t, w)
+}
+
+func line(productID string, quantity uint) handler.PlaceOrderLineRequest {
+	return handler.PlaceOrderLineRequest{ProductID: productID, Quantity: quantity}
+}
+
+func TestStatus_Transitions(t *testing.T) {
+	tests := []struct {
+		from, to order.Status
+		allowed  bool
+	}{
+		{order.StatusPlaced, order.StatusFulfilled, true},
+		{order.StatusPlaced, order.StatusCancelled, true},
+		{order.StatusPlaced, order.StatusPlaced, false},
+		{order.StatusFulfilled, order.StatusCancelled, false},
+		{order.StatusCancelled, order.StatusFulfilled, false},
+		{order.StatusCancelled, order.StatusPlaced, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from.String()+" to "+tt.to.String(), func(t *testing.T) {
+			assert.Equal(t, tt.allowed, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+
+	_, err := order.NewStatus("shipped")
+	assert.ErrorIs(t, err, order.ErrInvalidOrder)
+}
+
+func TestNewOrder_Validation(t *testing.T) {
+	usd, _ := product.NewPrice(100, "USD")
+	eur, _ := product.NewPrice(100, "EUR")
+	laptop, err := order.NewLine("laptop", "Laptop", 2, usd)
+	require.NoError(t, err)
+	mouse, err := order.NewLine("mouse", "Mouse", 1, usd)
+	require.NoError(t, err)
+	keyboard, err := order.NewLine("keyboard", "Keyboard", 1, eur)
+	require.NoError(t, err)
+
+	_, err = order.NewLine("laptop", "Laptop", 0, usd)
+	assert.ErrorIs(t, err, order.ErrInvalidOrder)
+
+	tests := []struct {
+		name  string
+		lines []order.Line
+		valid bool
+	}{
+		{"two products", []order.Line{laptop, mouse}, true},
+		{"no lines", nil, false},
+		{"product ordered twice", []order.Line{laptop, laptop}, false},
+		{"mixed currencies", []order.Line{laptop, keyboard}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := order.NewOrder(order.GenerateID(), tt.lines)
+			if !tt.valid {
+				assert.ErrorIs(t, err, order.ErrInvalidOrder)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, order.StatusPlaced, o.Status())
+			assert.Equal(t, uint(300), o.Total())
+			assert.Equal(t, "USD", o.Currency())
+		})
+	}
+}
+
+func TestOrder_PlaceTakesStock(t *testing.T) {
+	f := newFixture(t)
+
+	placed := f.place(t, line("laptop", 2), line("mouse", 3))
+	assert.Equal(t, "placed", placed.Status)
+	assert.Equal(t, "USD", placed.Currency)
+	assert.Equal(t, uint(2*1000+3*25), placed.Total)
+	require.Len(t, placed.Lines, 2)
+	assert.Equal(t, handler.OrderLineResponse{ProductID: "laptop", ProductName: "Laptop", Quantity: 2, UnitPrice: 1000, Total: 2000}, placed.Lines[0])
+
+	assert.Equal(t, uint(8), f.stock(t, "laptop"))
+	assert.Equal(t, uint(2), f.stock(t, "mouse"))
+
+	// Every product changed is published as a sale
+	require.Len(t, *f.events, 2)
+	for _, e := range *f.events {
+		assert.Equal(t, product.EventStockChanged, e.Type)
+		require.Len(t, e.Movements, 1)
+		assert.Equal(t, product.MovementSale, e.Movements[0].Type())
+	}
+
+	w := apitest.Do(t, f.rtr, http.MethodGet, "/api/orders/"+placed.ID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, placed.Lines, apitest.Decode[handler.OrderResponse](t, w).Lines)
+}
+
+func TestOrder_PlaceIsAllOrNothing(t *testing.T) {
+	tests := []struct {
+		name   string
+		lines  []handler.PlaceOrderLineRequest
+		status int
+	}{
+		{"insufficient stock", []handler.PlaceOrderLineRequest{line("laptop", 2), line("mouse", 6)}, http.StatusConflict},
+		{"unknown product", []handler.PlaceOrderLineRequest{line("laptop", 2), line("tablet", 1)}, http.StatusNotFound},
+		{"mixed currencies", []handler.PlaceOrderLineRequest{line("laptop", 2), line("keyboard", 1)}, http.StatusBadRequest},
+		{"product ordered twice", []handler.PlaceOrderLineRequest{line("laptop", 2), line("laptop", 1)}, http.StatusBadRequest},
+		{"zero quantity", []handler.PlaceOrderLineRequest{line("laptop", 0)}, http.StatusBadRequest},
+		{"no lines", nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+
+			w := apitest.Do(t, f.rtr, http.MethodPost, "/api/orders", handler.PlaceOrderRequest{Lines: tt.lines})
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+
+			assert.Equal(t, uint(10), f.stock(t, "laptop"))
+			assert.Equal(t, uint(5), f.stock(t, "mouse"))
+			assert.Empty(t, *f.events)
+		})
+	}
+}
+
+func TestOrder_PlaceRejectsUnpublishedProducts(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.service.ChangeStatus(context.Background(), "mouse", product.StatusArchived)
+	require.NoError(t, err)
+	*f.events = nil
+
+	w := apitest.Do(t, f.rtr, http.MethodPost, "/api/orders", handler.PlaceOrderRequest{Lines: []handler.PlaceOrderLineRequest{line("laptop", 2), line("mouse", 1)}})
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "not published")
+
+	assert.Equal(t, uint(10), f.stock(t, "laptop"))
+	assert.Empty(t, *f.events)
+}
+
+func TestOrder_PlaceReturnsStockWhenNotSaved(t *testing.T) {
+	f := newFixture(t)
+	f.orders.fail = true
+
+	w := apitest.Do(t, f.rtr, http.MethodPost, "/api/orders", handler.PlaceOrderRequest{Lines: []handler.PlaceOrderLineRequest{line("laptop", 2)}})
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, uint(10), f.stock(t, "laptop"))
+}
+
+func TestOrder_CancelReturnsStock(t *testing.T) {
+	f := newFixture(t)
+	placed := f.place(t, line("laptop", 2), line("mouse", 3))
+
+	w := apitest.Do(t, f.rtr, http.MethodPost, "/api/orders/"+placed.ID+"/cancel", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "cancelled", apitest.Decode[handler.OrderResponse](t, w).Status)
+	assert.Equal(t, uint(10), f.stock(t, "laptop"))
+	assert.Equal(t, uint(5), f.stock(t, "mouse"))
+
+	// Cancelled orders are final
+	w = apitest.Do(t, f.rtr, http.MethodPost, "/api/orders/"+placed.ID+"/cancel", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = apitest.Do(t, f.rtr, http.MethodPost, "/api/orders/"+placed.ID+"/fulfil", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, uint(10), f.stock(t, "laptop"))
+}
+
+func TestOrder_CancelSkipsDeletedProducts(t *testing.T) {
+	f := newFixture(t)
+	placed := f.place(t, line("laptop", 2), line("mouse", 3))
+	require.NoError(t, f.products.Delete(context.Background(), "mouse"))
+
+	w := apitest.Do(t, f.rtr, http.MethodPost, "/api/orders/"+placed.ID+"/cancel", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, uint(10), f.stock(t, "laptop"))
+}
+
+func TestOrder_FulfilKeepsStock(t *testing.T) {
+	f := newFixture(t)
+	placed := f.place(t, line("laptop", 2))
+
+	w := apitest.Do(t, f.rtr, http.MethodPost, "/api/orders/"+placed.ID+"/fulfil", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "fulfilled", apitest.Decode[handler.OrderResponse](t, w).Status)
+	assert.Equal(t, uint(8), f.stock(t, "laptop"))
+
+	w = apitest.Do(t, f.rtr, http.MethodPost, "/api/orders/"+placed.ID+"/cancel", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, uint(8), f.stock(t, "laptop"))
+}
+
+func TestOrder_List(t *testing.T) {
+	f := newFixture(t)
+	first := f.place(t, line("laptop", 1))
+	second := f.place(t, line("mouse", 1))
+	require.Equal(t, http.StatusOK, apitest.Do(t, f.rtr, http.MethodPost, "/api/orders/"+first.ID+"/fulfil", nil).Code)
+
+	w := apitest.Do(t, f.rtr, http.MethodGet, "/api/orders", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	all := apitest.Decode[[]handler.OrderResponse](t, w)
+	require.Len(t, all, 2)
+	assert.Equal(t, second.ID, all[0].ID)
+
+	w = apitest.Do(t, f.rtr, http.MethodGet, "/api/orders?status=fulfilled", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	fulfilled := apitest.Decode[[]handler.OrderResponse](t, w)
+	require.Len(t, fulfilled, 1)
+	assert.Equal(t, first.ID, fulfilled[0].ID)
+
+	assert.Equal(t, http.StatusBadRequest, apitest.Do(t, f.rtr, http.MethodGet, "/api/orders?status=shipped", nil).Code)
+	assert.Equal(t, http.StatusNotFound, apitest.Do(t, f.rtr, http.MethodGet, "/api/orders/missing", nil).Code)
+}
+
+func TestOrder_ConcurrentPlacementsNeverOversell(t *testing.T) {
+	f := newFixture(t)
+
+	const attempts = 20
+	var wg sync.WaitGroup
+	codes := make(chan int, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Products are listed in both orders so that lock ordering is exercised
+			lines := []handler.PlaceOrderLineRequest{line("laptop", 1), line("mouse", 1)}
+			if i%2 == 1 {
+				lines[0], lines[1] = lines[1], lines[0]
+			}
+			codes <- apitest.Do(t, f.rtr, http.MethodPost, "/api/orders", handler.PlaceOrderRequest{Lines: lines}).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	placed := 0
+	for code := range codes {
+		if code == http.StatusCreated {
+			placed++
+		} else {
+			assert.Equal(t, http.StatusConflict, code)
+		}
+	}
+	assert.Equal(t, 5, placed)
+	assert.Equal(t, uint(5), f.stock(t, "laptop"))
+	assert.Equal(t, uint(0), f.stock(t, "mouse"))
+}
+
+func TestOrder_ConcurrentStatusChangesReturnStockOnce(t *testing.T) {
+	f := newFixture(t)
+	placed := f.place(t, line("laptop", 2))
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	codes := make(chan int, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			action := "/cancel"
+			if i%2 == 1 {
+				action = "/fulfil"
+			}
+			codes <- apitest.Do(t, f.rtr, http.MethodPost, "/api/orders/"+placed.ID+action, nil).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	changed := 0
+	for code := range codes {
+		if code == http.StatusOK {
+			changed++
+			continue
+		}
+		assert.Equal(t, http.StatusConflict, code)
+	}
+	assert.Equal(t, 1, changed, "only the first status change applies")
+	assert.Contains(t, []uint{8, 10}, f.stock(t, "laptop"), "the stock is returned at most once")
+}
@@ -0,0 +1,181 @@
+package product_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	product "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/infrastructure"
+)
+
+func mustDefinition(t *testing.T, key string, attrType product.AttributeType, required bool, values ...string) product.AttributeDefinition {
+	t.Helper()
+
+	d, err := product.NewAttributeDefinition(key, attrType, required, values)
+	require.NoError(t, err)
+	return d
+}
+
+func mustSchema(t *testing.T, categoryID string, definitions ...product.AttributeDefinition) *product.AttributeSchema {
+	t.Helper()
+
+	s, err := product.NewAttributeSchema(product.CategoryID(categoryID), definitions)
+	require.NoError(t, err)
+	return s
+}
+
+func TestNewAttributeDefinition(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		attrType product.AttributeType
+		values   []string
+		valid    bool
+	}{
+		{"string", "color", product.AttributeString, nil, true},
+		{"number with underscore", "warranty_months", product.AttributeNumber, nil, true},
+		{"enum", "color", product.AttributeEnum, []string{"red", "black"}, true},
+		{"uppercase key", "Color", product.AttributeString, nil, false},
+		{"key starting with a digit", "4k", product.AttributeBool, nil, false},
+		{"unknown type", "color", product.AttributeType("colour"), nil, false},
+		{"enum without values", "color", product.AttributeEnum, nil, false},
+		{"duplicate enum values", "color", product.AttributeEnum, []string{"red", "red"}, false},
+		{"empty enum value", "color", product.AttributeEnum, []string{""}, false},
+		{"values on a number", "ram_gb", product.AttributeNumber, []string{"8"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := product.NewAttributeDefinition(tt.key, tt.attrType, false, tt.values)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, product.IsValidationError(err), "got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewAttributeSchema_RejectsDuplicateKeys(t *testing.T) {
+	color := mustDefinition(t, "color", product.AttributeString, false)
+
+	_, err := product.NewAttributeSchema("laptops", []product.AttributeDefinition{color, color})
+	assert.True(t, product.IsValidationError(err))
+}
+
+func TestNewAttributes(t *testing.T) {
+	attrs, err := product.NewAttributes(map[string]any{"color": "red", "ram_gb": 16, "refurbished": true})
+	require.NoError(t, err)
+	assert.Equal(t, product.Attributes{"color": "red", "ram_gb": float64(16), "refurbished": true}, attrs, "Integers are stored as float64")
+	assert.Equal(t, []string{"color", "ram_gb", "refurbished"}, attrs.Keys())
+
+	_, err = product.NewAttributes(map[string]any{"ports": []any{"usb"}})
+	assert.True(t, product.IsValidationError(err), "Only scalar values are accepted")
+	_, err = product.NewAttributes(map[string]any{"Color": "red"})
+	assert.True(t, product.IsValidationError(err))
+}
+
+func TestAttributes_Matches(t *testing.T) {
+	attrs, err := product.NewAttributes(map[string]any{"color": "red", "ram_gb": 16, "refurbished": true})
+	require.NoError(t, err)
+
+	tests := []struct {
+		key, value string
+		matches    bool
+	}{
+		{"color", "red", true},
+		{"color", "Red", false},
+		{"ram_gb", "16", true},
+		{"ram_gb", "16.0", true},
+		{"ram_gb", "sixteen", false},
+		{"refurbished", "true", true},
+		{"refurbished", "false", false},
+		{"weight", "2", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.matches, attrs.Matches(tt.key, tt.value))
+		})
+	}
+}
+
+func TestValidateAttributes(t *testing.T) {
+	laptops := mustSchema(t, "laptops",
+		mustDefinition(t, "color", product.AttributeEnum, true, "red", "black"),
+		mustDefinition(t, "ram_gb", product.AttributeNumber, false),
+	)
+	refurbished := mustSchema(t, "refurbished",
+		mustDefinition(t, "grade", product.AttributeString, true),
+		mustDefinition(t, "tested", product.AttributeBool, false),
+	)
+	schemas := []*product.AttributeSchema{laptops, refurbished}
+
+	tests := []struct {
+		name   string
+		values map[string]any
+		errMsg string
+	}{
+		{"valid", map[string]any{"color": "red", "ram_gb": 16, "grade": "A", "tested": true}, ""},
+		{"optional omitted", map[string]any{"color": "black", "grade": "B"}, ""},
+		{"missing required", map[string]any{"color": "red"}, "grade is required"},
+		{"not an enum value", map[string]any{"color": "green", "grade": "A"}, "color must be one of red, black"},
+		{"number as text", map[string]any{"color": "red", "ram_gb": "16", "grade": "A"}, "ram_gb must be a number"},
+		{"bool as text", map[string]any{"color": "red", "grade": "A", "tested": "yes"}, "tested must be a boolean"},
+		{"undefined", map[string]any{"color": "red", "grade": "A", "weight": 2}, "weight is not defined by the categories of the product"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs, err := product.NewAttributes(tt.values)
+			require.NoError(t, err)
+
+			err = product.ValidateAttributes(attrs, schemas)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, product.IsValidationError(err))
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	err := product.ValidateAttributes(product.Attributes{}, schemas)
+	require.Error(t, err)
+	assert.Equal(t, "invalid attributes: color is required; grade is required", err.Error(), "Every problem is reported at once")
+}
+
+func TestAttributeService_SetAttributes(t *testing.T) {
+	ctx := context.Background()
+	service := product.NewService(infrastructure.NewProductRepository())
+	attributes := product.NewAttributeService(service, infrastructure.NewAttributeSchemaRepository())
+
+	var events []product.Event
+	service.Subscribe(product.EventHandlerFunc(func(_ context.Context, e product.Event) {
+		events = append(events, e)
+	}))
+
+	_, err := service.CreateProduct(ctx, "p1", "Laptop", "", product.MustNewPrice(1000, "USD"), product.NewStock(5))
+	require.NoError(t, err)
+	category, err := product.NewCategory("laptops", "Laptops")
+	require.NoError(t, err)
+	_, err = service.AddCategoryToProduct(ctx, "p1", category)
+	require.NoError(t, err)
+
+	red := product.Attributes{"color": "red"}
+	_, err = attributes.SetAttributes(ctx, "p1", red)
+	assert.True(t, product.IsValidationError(err), "Attributes must be defined by a schema")
+
+	require.NoError(t, attributes.SaveSchema(ctx, mustSchema(t, "laptops", mustDefinition(t, "color", product.AttributeString, true))))
+	updated, err := attributes.SetAttributes(ctx, "p1", red)
+	require.NoError(t, err)
+	assert.Equal(t, red, updated.Attributes())
+
+	found, err := service.GetProductByID(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, red, found.Attributes())
+	assert.Equal(t, product.EventProductUpdated, events[len(events)-1].Type)
+
+	_, err = attributes.SetAttributes(ctx, "missing", red)
+	assert.ErrorIs(t, err, product.ErrProductNotFound)
+}
@@ -0,0 +1,176 @@
+package product_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	product "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/infrastructure"
+)
+
+// fakeClock is a Clock that only moves when told to
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestNewAvailability(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := product.NewAvailability(start, start)
+	assert.True(t, product.IsValidationError(err), "An empty window is rejected")
+	_, err = product.NewAvailability(start, start.Add(-time.Hour))
+	assert.True(t, product.IsValidationError(err))
+
+	open, err := product.NewAvailability(time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, product.Availability{}, open)
+}
+
+func TestAvailability_Contains(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		from, until time.Time
+		at          time.Time
+		contains    bool
+	}{
+		{"open window", time.Time{}, time.Time{}, start, true},
+		{"before from", start, time.Time{}, start.Add(-time.Second), false},
+		{"at from", start, time.Time{}, start, true},
+		{"before until", time.Time{}, end, end.Add(-time.Second), true},
+		{"at until", time.Time{}, end, end, false},
+		{"inside window", start, end, start.Add(time.Hour), true},
+		{"after window", start, end, end.Add(time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := product.NewAvailability(tt.from, tt.until)
+			require.NoError(t, err)
+			assert.Equal(t, tt.contains, a.Contains(tt.at))
+		})
+	}
+}
+
+func TestProduct_IsAvailableAt(t *testing.T) {
+	clock := newFakeClock()
+	p := newPublishable(t)
+	window, err := product.NewAvailability(clock.Now().Add(time.Hour), clock.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	p.SetAvailability(window)
+
+	inside := clock.Now().Add(90 * time.Minute)
+	assert.False(t, p.IsAvailableAt(inside), "Drafts are never available")
+	assert.ErrorIs(t, p.CheckAvailableAt(inside), product.ErrProductNotPublished)
+
+	require.NoError(t, p.TransitionTo(product.StatusPublished, product.DefaultPublishGuards))
+	assert.True(t, p.IsAvailableAt(inside))
+	assert.NoError(t, p.CheckAvailableAt(inside))
+	assert.False(t, p.IsAvailableAt(clock.Now()))
+	assert.ErrorIs(t, p.CheckAvailableAt(clock.Now()), product.ErrProductUnavailable)
+}
+
+func TestService_StampsWithItsClock(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	service := product.NewService(infrastructure.NewProductRepository())
+	service.SetClock(clock)
+	var events []product.Event
+	service.Subscribe(product.EventHandlerFunc(func(_ context.Context, e product.Event) {
+		events = append(events, e)
+	}))
+
+	created, err := service.CreateProduct(ctx, "p1", "Laptop", "", product.MustNewPrice(1000, "USD"), product.NewStock(5))
+	require.NoError(t, err)
+	assert.Equal(t, clock.Now(), created.CreatedAt())
+	assert.Equal(t, clock.Now(), created.UpdatedAt())
+
+	clock.Advance(time.Hour)
+	updated, err := service.UpdateProduct(ctx, "p1", "Laptop Pro", "", product.MustNewPrice(1200, "USD"), product.NewStock(5))
+	require.NoError(t, err)
+	assert.Equal(t, clock.Now().Add(-time.Hour), updated.CreatedAt())
+	assert.Equal(t, clock.Now(), updated.UpdatedAt())
+
+	for _, e := range events {
+		assert.False(t, e.OccurredAt.IsZero())
+		assert.False(t, e.OccurredAt.After(clock.Now()), "Events are stamped by the fake clock")
+	}
+	assert.Equal(t, clock.Now(), events[len(events)-1].OccurredAt)
+}
+
+func TestService_AnnounceAvailability(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	start := clock.Now()
+	service := product.NewService(infrastructure.NewProductRepository())
+	service.SetClock(clock)
+	service.SetPublishGuards()
+
+	create := func(id string, status product.ProductStatus, from, until time.Duration) {
+		t.Helper()
+		_, err := service.CreateProduct(ctx, product.ProductID(id), "Laptop", "", product.MustNewPrice(1000, "USD"), product.NewStock(5))
+		require.NoError(t, err)
+		window, err := product.NewAvailability(start.Add(from), start.Add(until))
+		require.NoError(t, err)
+		_, err = service.SetAvailability(ctx, product.ProductID(id), window)
+		require.NoError(t, err)
+		if status != product.StatusDraft {
+			_, err = service.ChangeStatus(ctx, product.ProductID(id), status)
+			require.NoError(t, err)
+		}
+	}
+	create("campaign", product.StatusPublished, time.Hour, 3*time.Hour)
+	create("flash", product.StatusPublished, 90*time.Minute, 100*time.Minute)
+	create("draft", product.StatusDraft, time.Hour, 3*time.Hour)
+
+	var announced []product.Event
+	service.Subscribe(product.EventHandlerFunc(func(_ context.Context, e product.Event) {
+		announced = append(announced, e)
+	}))
+
+	events, err := service.AnnounceAvailability(ctx, start, start.Add(30*time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	events, err = service.AnnounceAvailability(ctx, start.Add(30*time.Minute), start.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, events, 3, "The flash sale both opened and closed in the interval; drafts are ignored")
+	assert.Equal(t, product.EventProductAvailable, events[0].Type)
+	assert.Equal(t, product.ProductID("campaign"), events[0].ProductID)
+	assert.Equal(t, start.Add(time.Hour), events[0].OccurredAt)
+	assert.Equal(t, product.EventProductAvailable, events[1].Type)
+	assert.Equal(t, product.ProductID("flash"), events[1].ProductID)
+	assert.Equal(t, product.EventProductUnavailable, events[2].Type)
+	assert.Equal(t, product.ProductID("flash"), events[2].ProductID)
+	assert.Equal(t, start.Add(100*time.Minute), events[2].OccurredAt)
+	assert.Equal(t, events, announced, "Every announced event is published to the subscribers")
+
+	events, err = service.AnnounceAvailability(ctx, start.Add(2*time.Hour), start.Add(3*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, events, 1, "The upper bound is inclusive")
+	assert.Equal(t, product.EventProductUnavailable, events[0].Type)
+	assert.Equal(t, product.ProductID("campaign"), events[0].ProductID)
+}
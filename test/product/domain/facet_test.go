@@ -0,0 +1,161 @@
+package product_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	product "sago-sample/feature/product/domain"
+)
+
+func TestNewPriceBuckets(t *testing.T) {
+	buckets, err := product.NewPriceBuckets([]uint{1000, 5000})
+	require.NoError(t, err)
+	assert.Equal(t, []product.PriceBucket{{From: 0, To: 1000}, {From: 1000, To: 5000}, {From: 5000}}, buckets)
+	assert.True(t, buckets[0].Contains(999))
+	assert.False(t, buckets[0].Contains(1000), "To is exclusive")
+	assert.True(t, buckets[2].Contains(1_000_000), "The last bucket is unbounded")
+
+	buckets, err = product.NewPriceBuckets(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []product.PriceBucket{{}}, buckets, "No bounds give a single bucket")
+
+	for _, bounds := range [][]uint{{0}, {5000, 1000}, {1000, 1000}} {
+		_, err := product.NewPriceBuckets(bounds)
+		assert.True(t, product.IsValidationError(err), "bounds %v", bounds)
+	}
+}
+
+// facetProduct creates a product with price, stock, categories and attributes
+func facetProduct(t *testing.T, id string, price, stock uint, categories []string, attributes map[string]any) *product.Product {
+	t.Helper()
+
+	p, err := product.NewProduct(product.ProductID(id), product.ProductName(id), "", product.MustNewPrice(price, "USD"), product.NewStock(stock))
+	require.NoError(t, err)
+	for _, c := range categories {
+		category, err := product.NewCategory(product.CategoryID(c), product.CategoryName(c))
+		require.NoError(t, err)
+		p.AddCategory(category)
+	}
+	attrs, err := product.NewAttributes(attributes)
+	require.NoError(t, err)
+	p.SetAttributes(attrs)
+	return p
+}
+
+func TestCountFacets(t *testing.T) {
+	products := []*product.Product{
+		facetProduct(t, "p1", 500, 3, []string{"laptops"}, map[string]any{"color": "red", "ram_gb": 16}),
+		facetProduct(t, "p2", 2500, 0, []string{"laptops"}, map[string]any{"color": "black", "ram_gb": 8}),
+		facetProduct(t, "p3", 7500, 1, []string{"phones"}, map[string]any{"color": "red"}),
+		facetProduct(t, "p4", 2500, 2, []string{"laptops", "sale"}, map[string]any{"color": "red", "ram_gb": 16.0}),
+	}
+	buckets, err := product.NewPriceBuckets([]uint{1000, 5000})
+	require.NoError(t, err)
+	inStock := true
+
+	tests := []struct {
+		name       string
+		query      product.FacetQuery
+		total      int
+		categories map[product.CategoryID]int
+		prices     []int
+		stock      product.StockCount
+		colors     map[string]int
+	}{
+		{
+			name:       "no filters",
+			query:      product.FacetQuery{PriceBuckets: buckets},
+			total:      4,
+			categories: map[product.CategoryID]int{"laptops": 3, "phones": 1, "sale": 1},
+			prices:     []int{1, 2, 1},
+			stock:      product.StockCount{InStock: 3, OutOfStock: 1},
+			colors:     map[string]int{"black": 1, "red": 3},
+		},
+		{
+			name:       "category counts every category",
+			query:      product.FacetQuery{CategoryID: "laptops", PriceBuckets: buckets},
+			total:      3,
+			categories: map[product.CategoryID]int{"laptops": 3, "phones": 1, "sale": 1},
+			prices:     []int{1, 2, 0},
+			stock:      product.StockCount{InStock: 2, OutOfStock: 1},
+			colors:     map[string]int{"black": 1, "red": 2},
+		},
+		{
+			name:       "price range counts every bucket",
+			query:      product.FacetQuery{MinPrice: 1000, MaxPrice: 5000, PriceBuckets: buckets},
+			total:      2,
+			categories: map[product.CategoryID]int{"laptops": 2, "sale": 1},
+			prices:     []int{1, 2, 1},
+			stock:      product.StockCount{InStock: 1, OutOfStock: 1},
+			colors:     map[string]int{"black": 1, "red": 1},
+		},
+		{
+			name:       "attribute counts every value of its key",
+			query:      product.FacetQuery{InStock: &inStock, Attributes: map[string]string{"color": "red"}, PriceBuckets: buckets},
+			total:      3,
+			categories: map[product.CategoryID]int{"laptops": 2, "phones": 1, "sale": 1},
+			prices:     []int{1, 1, 1},
+			stock:      product.StockCount{InStock: 3, OutOfStock: 0},
+			colors:     map[string]int{"red": 3},
+		},
+		{
+			name:       "currency restricts every count",
+			query:      product.FacetQuery{Currency: "EUR", PriceBuckets: buckets},
+			categories: map[product.CategoryID]int{},
+			prices:     []int{0, 0, 0},
+			colors:     map[string]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facets := product.CountFacets(products, tt.query)
+			assert.Equal(t, tt.total, facets.Total)
+
+			categories := make(map[product.CategoryID]int)
+			for _, c := range facets.Categories {
+				categories[c.ID] = c.Count
+			}
+			assert.Equal(t, tt.categories, categories)
+
+			prices := make([]int, 0, len(facets.Prices))
+			for _, p := range facets.Prices {
+				prices = append(prices, p.Count)
+			}
+			assert.Equal(t, tt.prices, prices)
+			assert.Equal(t, tt.stock, facets.Stock)
+
+			colors := make(map[string]int)
+			for _, a := range facets.Attributes {
+				if a.Key == "color" {
+					for _, v := range a.Values {
+						colors[v.Value] = v.Count
+					}
+				}
+			}
+			assert.Equal(t, tt.colors, colors)
+		})
+	}
+}
+
+func TestCountFacets_Ordering(t *testing.T) {
+	products := []*product.Product{
+		facetProduct(t, "p1", 500, 1, []string{"phones", "laptops"}, map[string]any{"ram_gb": 16, "color": "red"}),
+		facetProduct(t, "p2", 500, 1, []string{"laptops"}, map[string]any{"ram_gb": 8, "refurbished": true}),
+	}
+
+	facets := product.CountFacets(products, product.FacetQuery{})
+	require.Len(t, facets.Categories, 2)
+	assert.Equal(t, product.CategoryID("laptops"), facets.Categories[0].ID)
+	assert.Equal(t, 2, facets.Categories[0].Count)
+
+	keys := make([]string, 0, len(facets.Attributes))
+	for _, a := range facets.Attributes {
+		keys = append(keys, a.Key)
+	}
+	assert.Equal(t, []string{"color", "ram_gb", "refurbished"}, keys)
+	assert.Equal(t, []product.AttributeValueCount{{Value: "16", Count: 1}, {Value: "8", Count: 1}}, facets.Attributes[1].Values,
+		"Numbers are written without trailing zeros and ordered as text")
+	assert.Equal(t, []product.AttributeValueCount{{Value: "true", Count: 1}}, facets.Attributes[2].Values)
+}
@@ -0,0 +1,258 @@
+package product_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	product "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/infrastructure"
+	"sago-sample/feature/product/infrastructure/blob"
+)
+
+// encodePNG returns a width×height PNG filled with c
+func encodePNG(t *testing.T, width, height int, c color.Color) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// newMediaService returns a media service storing files in a temporary directory, and a product to attach images to
+func newMediaService(t *testing.T, limits product.ImageLimits) (*product.MediaService, *product.Service, *blob.LocalStore) {
+	t.Helper()
+
+	repo := infrastructure.NewProductRepository()
+	service := product.NewService(repo)
+	_, err := service.CreateProduct(context.Background(), "p1", "Laptop", "", product.MustNewPrice(1000, "USD"), product.NewStock(1))
+	require.NoError(t, err)
+
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	return product.NewMediaService(service, blobs, limits), service, blobs
+}
+
+func TestProduct_ImageOrder(t *testing.T) {
+	p, err := product.NewProduct("p1", "Laptop", "", product.MustNewPrice(1000, "USD"), product.NewStock(1))
+	require.NoError(t, err)
+	_, ok := p.PrimaryImage()
+	assert.False(t, ok)
+
+	for _, id := range []product.ImageID{"a", "b", "c"} {
+		require.NoError(t, p.AddImage(product.RestoreImage(id, "images/"+id.String(), "", "image/png", 1, 1, 1, p.CreatedAt())))
+	}
+	primary, ok := p.PrimaryImage()
+	require.True(t, ok)
+	assert.Equal(t, product.ImageID("a"), primary.ID())
+
+	require.NoError(t, p.SetPrimaryImage("c"))
+	assert.Equal(t, []product.ImageID{"c", "a", "b"}, imageIDs(p))
+
+	require.NoError(t, p.ReorderImages([]product.ImageID{"b", "c", "a"}))
+	assert.Equal(t, []product.ImageID{"b", "c", "a"}, imageIDs(p))
+
+	removed, err := p.RemoveImage("b")
+	require.NoError(t, err)
+	assert.Equal(t, product.ImageID("b"), removed.ID())
+	assert.Equal(t, []product.ImageID{"c", "a"}, imageIDs(p))
+
+	_, err = p.RemoveImage("b")
+	assert.ErrorIs(t, err, product.ErrImageNotFound)
+	assert.ErrorIs(t, p.SetPrimaryImage("b"), product.ErrImageNotFound)
+}
+
+func TestProduct_ReorderImagesRequiresEveryImageOnce(t *testing.T) {
+	p, err := product.NewProduct("p1", "Laptop", "", product.MustNewPrice(1000, "USD"), product.NewStock(1))
+	require.NoError(t, err)
+	require.NoError(t, p.AddImage(product.RestoreImage("a", "images/a", "", "image/png", 1, 1, 1, p.CreatedAt())))
+	require.NoError(t, p.AddImage(product.RestoreImage("b", "images/b", "", "image/png", 1, 1, 1, p.CreatedAt())))
+
+	for name, ids := range map[string][]product.ImageID{
+		"missing":   {"a"},
+		"duplicate": {"a", "a"},
+		"unknown":   {"a", "x"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var validationErr *product.ValidationError
+			assert.ErrorAs(t, p.ReorderImages(ids), &validationErr)
+			assert.Equal(t, []product.ImageID{"a", "b"}, imageIDs(p))
+		})
+	}
+}
+
+func TestProduct_AddImageLimit(t *testing.T) {
+	p, err := product.NewProduct("p1", "Laptop", "", product.MustNewPrice(1000, "USD"), product.NewStock(1))
+	require.NoError(t, err)
+	for i := 0; i < product.MaxImagesPerProduct; i++ {
+		id := product.ImageID(rune('a' + i))
+		require.NoError(t, p.AddImage(product.RestoreImage(id, "images/"+id.String(), "", "image/png", 1, 1, 1, p.CreatedAt())))
+	}
+
+	var validationErr *product.ValidationError
+	assert.ErrorAs(t, p.AddImage(product.RestoreImage("z", "images/z", "", "image/png", 1, 1, 1, p.CreatedAt())), &validationErr)
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		wantW, wantH  int
+	}{
+		{name: "landscape", width: 400, height: 200, wantW: 100, wantH: 50},
+		{name: "portrait", width: 150, height: 600, wantW: 25, wantH: 100},
+		{name: "already small", width: 40, height: 30, wantW: 40, wantH: 30},
+		{name: "thin", width: 1000, height: 2, wantW: 100, wantH: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb, err := product.Thumbnail(encodePNG(t, tt.width, tt.height, color.NRGBA{R: 200, G: 100, B: 50, A: 255}), 100)
+			require.NoError(t, err)
+
+			img, format, err := image.Decode(bytes.NewReader(thumb))
+			require.NoError(t, err)
+			assert.Equal(t, "png", format)
+			assert.Equal(t, tt.wantW, img.Bounds().Dx())
+			assert.Equal(t, tt.wantH, img.Bounds().Dy())
+			// A uniform image stays uniform when averaged
+			r, g, b, _ := img.At(0, 0).RGBA()
+			assert.Equal(t, []uint32{200, 100, 50}, []uint32{r >> 8, g >> 8, b >> 8})
+		})
+	}
+}
+
+func TestThumbnail_KeepsFormat(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 300, 300))
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, src, nil))
+
+	thumb, err := product.Thumbnail(buf.Bytes(), 64)
+	require.NoError(t, err)
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(thumb))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 64, cfg.Width)
+}
+
+func TestMediaService_UploadImage(t *testing.T) {
+	ctx := context.Background()
+	media, _, blobs := newMediaService(t, product.ImageLimits{MaxSize: 1 << 20, MaxDimension: 1000, MaxPixels: 1 << 20, ThumbnailSize: 32})
+
+	updated, img, err := media.UploadImage(ctx, "p1", encodePNG(t, 128, 64, color.White))
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", img.ContentType())
+	assert.Equal(t, 128, img.Width())
+	assert.Equal(t, 64, img.Height())
+	primary, ok := updated.PrimaryImage()
+	require.True(t, ok)
+	assert.Equal(t, img.ID(), primary.ID())
+
+	r, err := blobs.Open(ctx, img.ThumbnailKey())
+	require.NoError(t, err)
+	defer r.Close()
+	cfg, err := png.DecodeConfig(r)
+	require.NoError(t, err)
+	assert.Equal(t, 32, cfg.Width)
+	assert.Equal(t, 16, cfg.Height)
+
+	content, opened, err := media.OpenImage(ctx, "p1", img.ID(), false)
+	require.NoError(t, err)
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	assert.Equal(t, img.Size(), int64(len(data)))
+	assert.Equal(t, img.ID(), opened.ID())
+}
+
+func TestMediaService_UploadImageRejects(t *testing.T) {
+	ctx := context.Background()
+	media, _, _ := newMediaService(t, product.ImageLimits{MaxSize: 4 << 10, MaxDimension: 100, MaxPixels: 2500, ThumbnailSize: 32})
+
+	_, _, err := media.UploadImage(ctx, "p1", []byte("%PDF-1.4 not an image"))
+	assert.ErrorIs(t, err, product.ErrUnsupportedImage)
+
+	_, _, err = media.UploadImage(ctx, "p1", make([]byte, 5<<10))
+	assert.ErrorIs(t, err, product.ErrImageTooLarge)
+
+	var validationErr *product.ValidationError
+	_, _, err = media.UploadImage(ctx, "p1", encodePNG(t, 101, 10, color.White))
+	assert.ErrorAs(t, err, &validationErr)
+	_, _, err = media.UploadImage(ctx, "p1", encodePNG(t, 60, 60, color.White))
+	assert.ErrorAs(t, err, &validationErr, "60×60 pixels are more than 2500")
+
+	_, _, err = media.UploadImage(ctx, "missing", encodePNG(t, 10, 10, color.White))
+	assert.ErrorIs(t, err, product.ErrProductNotFound)
+}
+
+func TestMediaService_UploadImageUndoneWhenTheProductCannotBeSaved(t *testing.T) {
+	ctx := context.Background()
+	repo := infrastructure.NewProductRepository()
+	_, err := product.NewService(repo).CreateProduct(ctx, "p1", "Laptop", "", product.MustNewPrice(1000, "USD"), product.NewStock(1))
+	require.NoError(t, err)
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	media := product.NewMediaService(product.NewService(failingSaves{Repository: repo, id: "p1"}), blobs, product.DefaultImageLimits)
+
+	_, _, err = media.UploadImage(ctx, "p1", encodePNG(t, 10, 10, color.White))
+	require.Error(t, err)
+
+	p, err := repo.FindByID(ctx, "p1")
+	require.NoError(t, err)
+	assert.Empty(t, p.Images(), "the product does not refer to the deleted files")
+}
+
+func TestMediaService_RemoveImageDeletesFiles(t *testing.T) {
+	ctx := context.Background()
+	media, _, blobs := newMediaService(t, product.DefaultImageLimits)
+
+	_, img, err := media.UploadImage(ctx, "p1", encodePNG(t, 10, 10, color.White))
+	require.NoError(t, err)
+
+	updated, err := media.RemoveImage(ctx, "p1", img.ID())
+	require.NoError(t, err)
+	assert.Empty(t, updated.Images())
+	for _, key := range []string{img.Key(), img.ThumbnailKey()} {
+		_, err := blobs.Open(ctx, key)
+		assert.ErrorIs(t, err, product.ErrBlobNotFound)
+	}
+
+	_, err = media.RemoveImage(ctx, "p1", img.ID())
+	assert.ErrorIs(t, err, product.ErrImageNotFound)
+}
+
+func TestMediaService_DeletesFilesOfDeletedProducts(t *testing.T) {
+	ctx := context.Background()
+	media, service, blobs := newMediaService(t, product.DefaultImageLimits)
+	service.Subscribe(media)
+
+	_, img, err := media.UploadImage(ctx, "p1", encodePNG(t, 10, 10, color.White))
+	require.NoError(t, err)
+
+	require.NoError(t, service.DeleteProduct(ctx, "p1"))
+	for _, key := range []string{img.Key(), img.ThumbnailKey()} {
+		_, err := blobs.Open(ctx, key)
+		assert.ErrorIs(t, err, product.ErrBlobNotFound)
+	}
+}
+
+func imageIDs(p *product.Product) []product.ImageID {
+	var ids []product.ImageID
+	for _, img := range p.Images() {
+		ids = append(ids, img.ID())
+	}
+	return ids
+}
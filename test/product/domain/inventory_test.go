@@ -0,0 +1,360 @@
+package product_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	product "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/infrastructure"
+)
+
+// newStockedProduct creates a product holding the given quantity per warehouse
+func newStockedProduct(t *testing.T, levels map[product.WarehouseID]uint) *product.Product {
+	t.Helper()
+
+	p, err := product.NewProduct(
+		product.MustNewProductID("prod-1"),
+		product.MustNewProductName("Laptop"),
+		product.MustNewProductDescription("A laptop"),
+		product.MustNewPrice(1000, "USD"),
+		product.NewStock(0),
+	)
+	require.NoError(t, err)
+	for id, quantity := range levels {
+		p.SetStockLevel(id, product.NewStock(quantity))
+	}
+	return p
+}
+
+func newWarehouse(t *testing.T, id string, latitude, longitude float64) *product.Warehouse {
+	t.Helper()
+
+	location, err := product.NewLocation(latitude, longitude)
+	require.NoError(t, err)
+	w, err := product.NewWarehouse(product.WarehouseID(id), "Warehouse "+product.WarehouseName(id), &location)
+	require.NoError(t, err)
+	return w
+}
+
+func levels(p *product.Product) map[product.WarehouseID]uint {
+	result := make(map[product.WarehouseID]uint)
+	for _, l := range p.StockLevels() {
+		result[l.WarehouseID()] = l.Quantity()
+	}
+	return result
+}
+
+func TestProduct_StockIsTheTotalAcrossWarehouses(t *testing.T) {
+	p := newStockedProduct(t, map[product.WarehouseID]uint{"a": 3, "b": 4})
+	assert.Equal(t, uint(7), p.Stock().Quantity())
+	assert.Equal(t, uint(4), p.StockIn("b").Quantity())
+	assert.Equal(t, uint(0), p.StockIn("missing").Quantity())
+
+	// A new total is reached through the default warehouse first
+	p.UpdateStock(product.NewStock(10))
+	assert.Equal(t, map[product.WarehouseID]uint{product.DefaultWarehouseID: 3, "a": 3, "b": 4}, levels(p))
+
+	p.UpdateStock(product.NewStock(5))
+	assert.Equal(t, map[product.WarehouseID]uint{"a": 1, "b": 4}, levels(p))
+
+	assert.ErrorIs(t, p.DecreaseStock(6), product.ErrInsufficientStock)
+	assert.Equal(t, uint(5), p.Stock().Quantity(), "a failed decrease leaves the stock unchanged")
+}
+
+func TestProduct_TransferStock(t *testing.T) {
+	p := newStockedProduct(t, map[product.WarehouseID]uint{"a": 5})
+
+	require.NoError(t, p.TransferStock("a", "b", 2))
+	assert.Equal(t, map[product.WarehouseID]uint{"a": 3, "b": 2}, levels(p))
+	assert.Equal(t, uint(5), p.Stock().Quantity())
+
+	assert.ErrorIs(t, p.TransferStock("a", "b", 4), product.ErrInsufficientStock)
+	assert.True(t, product.IsValidationError(p.TransferStock("a", "a", 1)))
+	assert.True(t, product.IsValidationError(p.TransferStock("a", "b", 0)))
+	assert.Equal(t, map[product.WarehouseID]uint{"a": 3, "b": 2}, levels(p), "failed transfers move nothing")
+}
+
+func TestProduct_AllocateStock(t *testing.T) {
+	paris := newWarehouse(t, "paris", 48.8566, 2.3522)
+	berlin := newWarehouse(t, "berlin", 52.52, 13.405)
+	madrid := newWarehouse(t, "madrid", 40.4168, -3.7038)
+	warehouses := map[product.WarehouseID]*product.Warehouse{"paris": paris, "berlin": berlin, "madrid": madrid}
+	lyon, err := product.NewLocation(45.764, 4.8357)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		strategy product.AllocationStrategy
+		quantity uint
+		want     []product.StockLevel
+	}{
+		{
+			name:     "most stock",
+			strategy: product.MostStockStrategy{},
+			quantity: 7,
+			want:     []product.StockLevel{product.NewStockLevel("berlin", 6), product.NewStockLevel("madrid", 1)},
+		},
+		{
+			name:     "nearest",
+			strategy: product.NearestStrategy{Destination: lyon},
+			quantity: 4,
+			want:     []product.StockLevel{product.NewStockLevel("paris", 2), product.NewStockLevel("madrid", 2)},
+		},
+		{
+			name:     "priority list",
+			strategy: product.PriorityStrategy{Warehouses: []product.WarehouseID{"madrid", "paris"}},
+			quantity: 5,
+			want:     []product.StockLevel{product.NewStockLevel("madrid", 3), product.NewStockLevel("paris", 2)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newStockedProduct(t, map[product.WarehouseID]uint{"paris": 2, "berlin": 6, "madrid": 3})
+
+			allocations, err := p.AllocateStock(tt.quantity, tt.strategy, warehouses)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, allocations)
+			assert.Equal(t, 11-tt.quantity, p.Stock().Quantity())
+		})
+	}
+
+	t.Run("all or nothing", func(t *testing.T) {
+		p := newStockedProduct(t, map[product.WarehouseID]uint{"paris": 2, "berlin": 6, "madrid": 3})
+
+		// Berlin is not listed, so the listed warehouses cannot cover the quantity
+		_, err := p.AllocateStock(6, product.PriorityStrategy{Warehouses: []product.WarehouseID{"madrid", "paris"}}, warehouses)
+		assert.ErrorIs(t, err, product.ErrInsufficientStock)
+		assert.Equal(t, map[product.WarehouseID]uint{"paris": 2, "berlin": 6, "madrid": 3}, levels(p))
+	})
+}
+
+func TestLocation(t *testing.T) {
+	paris, err := product.NewLocation(48.8566, 2.3522)
+	require.NoError(t, err)
+	london, err := product.NewLocation(51.5074, -0.1278)
+	require.NoError(t, err)
+	assert.InDelta(t, 344, paris.DistanceKm(london), 2)
+
+	_, err = product.NewLocation(91, 0)
+	assert.True(t, product.IsValidationError(err))
+	_, err = product.NewLocation(0, -181)
+	assert.True(t, product.IsValidationError(err))
+}
+
+func TestInventoryService_ConcurrentChangesAreNotLost(t *testing.T) {
+	ctx := context.Background()
+	repo := infrastructure.NewProductRepository()
+	warehouses := infrastructure.NewWarehouseRepository()
+	service := product.NewService(repo)
+	inventory := product.NewInventoryService(service, warehouses)
+
+	_, err := inventory.CreateWarehouse(ctx, "east", "East", nil)
+	require.NoError(t, err)
+	_, err = service.CreateProduct(ctx, "prod-1", "Laptop", "", product.MustNewPrice(1000, "USD"), product.NewStock(100))
+	require.NoError(t, err)
+
+	var events []product.Event
+	var mutex sync.Mutex
+	service.Subscribe(product.EventHandlerFunc(func(_ context.Context, e product.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, e)
+	}))
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := inventory.TransferStock(ctx, "prod-1", product.DefaultWarehouseID, "east", 1)
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			_, _, err := inventory.AllocateStock(ctx, "prod-1", 1, product.MostStockStrategy{})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	p, err := repo.FindByID(ctx, "prod-1")
+	require.NoError(t, err)
+	assert.Equal(t, uint(50), p.Stock().Quantity())
+
+	var stockChanged, updated int
+	for _, e := range events {
+		switch e.Type {
+		case product.EventStockChanged:
+			stockChanged++
+		case product.EventProductUpdated:
+			updated++
+		}
+	}
+	assert.Equal(t, 50, stockChanged, "allocations change the total")
+	assert.Equal(t, 50, updated, "transfers keep the total")
+
+	assert.ErrorIs(t, inventory.DeleteWarehouse(ctx, product.DefaultWarehouseID), product.ErrWarehouseInUse)
+	_, err = inventory.SetStockLevel(ctx, "prod-1", "east", product.NewStock(1))
+	require.NoError(t, err)
+	assert.ErrorIs(t, inventory.DeleteWarehouse(ctx, "east"), product.ErrWarehouseInUse)
+	_, err = inventory.SetStockLevel(ctx, "prod-1", "east", product.NewStock(0))
+	require.NoError(t, err)
+	assert.NoError(t, inventory.DeleteWarehouse(ctx, "east"))
+
+	_, err = inventory.TransferStock(ctx, "prod-1", "east", "missing", 1)
+	assert.ErrorIs(t, err, product.ErrWarehouseNotFound)
+}
+
+// ledger is a movement repository that checks it is written in a transaction and can be made to fail
+type ledger struct {
+	product.MovementRepository
+	fail          error
+	inTransaction []bool
+}
+
+func (l *ledger) Append(ctx context.Context, movements ...product.Movement) error {
+	l.inTransaction = append(l.inTransaction, product.InTransaction(ctx))
+	if l.fail != nil {
+		return l.fail
+	}
+	return l.MovementRepository.Append(ctx, movements...)
+}
+
+func TestService_RecordsMovementsInTheTransactionOfTheStockChange(t *testing.T) {
+	ctx := context.Background()
+	repo := infrastructure.NewProductRepository()
+	movements := &ledger{MovementRepository: infrastructure.NewMovementRepository()}
+	service := product.NewService(repo)
+	inventory := product.NewInventoryService(service, infrastructure.NewWarehouseRepository())
+
+	var transactions int
+	service.SetTransactor(product.TransactorFunc(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		transactions++
+		return fn(ctx)
+	}))
+	service.SetLedger(movements)
+
+	var events []product.Event
+	service.Subscribe(product.EventHandlerFunc(func(_ context.Context, e product.Event) {
+		events = append(events, e)
+	}))
+
+	_, err := service.CreateProduct(ctx, "prod-1", "Laptop", "", product.MustNewPrice(1000, "USD"), product.NewStock(10))
+	require.NoError(t, err)
+	_, err = inventory.TakeStock(ctx, map[product.ProductID]uint{"prod-1": 4}, product.MovementSale, "order")
+	require.NoError(t, err)
+	assert.Equal(t, 2, transactions)
+	assert.Equal(t, []bool{true, true}, movements.inTransaction)
+
+	recorded, err := movements.FindByProduct(ctx, "prod-1")
+	require.NoError(t, err)
+	require.Len(t, recorded, 2)
+	assert.Equal(t, 10, recorded[0].Quantity())
+	assert.Equal(t, -4, recorded[1].Quantity())
+
+	// A change whose movements cannot be recorded fails and is not published
+	movements.fail = errors.New("ledger unavailable")
+	events = nil
+	_, err = inventory.TakeStock(ctx, map[product.ProductID]uint{"prod-1": 1}, product.MovementSale, "order")
+	assert.ErrorIs(t, err, movements.fail)
+	p, err := repo.FindByID(ctx, "prod-1")
+	require.NoError(t, err)
+	assert.Equal(t, uint(6), p.Stock().Quantity(), "the failed take is undone")
+
+	_, err = service.UpdateProduct(ctx, "prod-1", "Laptop", "", product.MustNewPrice(1000, "USD"), product.NewStock(1))
+	assert.ErrorIs(t, err, movements.fail)
+	assert.Empty(t, events)
+}
+
+// failingSaves is a product repository whose saves of one product fail
+type failingSaves struct {
+	product.Repository
+	id product.ProductID
+}
+
+func (r failingSaves) Save(ctx context.Context, p *product.Product) error {
+	if p.ID() == r.id {
+		return errors.New("save failed")
+	}
+	return r.Repository.Save(ctx, p)
+}
+
+func TestInventoryService_TakeStockRunsInOneTransaction(t *testing.T) {
+	ctx := context.Background()
+	repo := infrastructure.NewProductRepository()
+	service := product.NewService(repo)
+	for _, id := range []product.ProductID{"prod-1", "prod-2"} {
+		_, err := service.CreateProduct(ctx, id, "Laptop", "", product.MustNewPrice(1000, "USD"), product.NewStock(10))
+		require.NoError(t, err)
+	}
+
+	service = product.NewService(failingSaves{Repository: repo, id: "prod-2"})
+	inventory := product.NewInventoryService(service, infrastructure.NewWarehouseRepository())
+	var transactions int
+	service.SetTransactor(product.TransactorFunc(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		transactions++
+		return fn(ctx)
+	}))
+	var events []product.Event
+	service.Subscribe(product.EventHandlerFunc(func(_ context.Context, e product.Event) {
+		events = append(events, e)
+	}))
+
+	_, err := inventory.TakeStock(ctx, map[product.ProductID]uint{"prod-1": 3, "prod-2": 3}, product.MovementSale, "order")
+	assert.EqualError(t, err, "save failed")
+	assert.Equal(t, 1, transactions)
+	assert.Empty(t, events)
+	for _, id := range []product.ProductID{"prod-1", "prod-2"} {
+		p, err := repo.FindByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, uint(10), p.Stock().Quantity(), "nothing is taken from %s", id)
+	}
+
+	// The events of the changes made in a caller's transaction are published once it commits, and never when it fails
+	failed := errors.New("order not saved")
+	err = inventory.Transaction(ctx, func(ctx context.Context) error {
+		_, err := inventory.TakeStock(ctx, map[product.ProductID]uint{"prod-1": 3}, product.MovementSale, "order")
+		require.NoError(t, err)
+		assert.Empty(t, events)
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.Empty(t, events)
+
+	err = inventory.Transaction(ctx, func(ctx context.Context) error {
+		_, err := inventory.TakeStock(ctx, map[product.ProductID]uint{"prod-1": 3}, product.MovementSale, "order")
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, transactions)
+	require.Len(t, events, 1)
+	assert.Equal(t, product.EventStockChanged, events[0].Type)
+}
+
+func TestService_StampsMovementsWithItsClock(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	movements := infrastructure.NewMovementRepository()
+	service := product.NewService(infrastructure.NewProductRepository())
+	service.SetClock(product.ClockFunc(func() time.Time { return now }))
+	service.SetLedger(movements)
+	inventory := product.NewInventoryService(service, infrastructure.NewWarehouseRepository())
+
+	_, err := service.CreateProduct(ctx, "prod-1", "Laptop", "", product.MustNewPrice(1000, "USD"), product.NewStock(10))
+	require.NoError(t, err)
+	now = now.Add(time.Hour)
+	_, err = inventory.TakeStock(ctx, map[product.ProductID]uint{"prod-1": 4}, product.MovementSale, "order")
+	require.NoError(t, err)
+
+	recorded, err := movements.FindByProduct(ctx, "prod-1")
+	require.NoError(t, err)
+	require.Len(t, recorded, 2)
+	assert.Equal(t, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), recorded[0].OccurredAt())
+	assert.Equal(t, time.Date(2024, 3, 1, 13, 0, 0, 0, time.UTC), recorded[1].OccurredAt())
+}
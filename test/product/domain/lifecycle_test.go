@@ -0,0 +1,175 @@
+package product_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	product "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/infrastructure"
+)
+
+func TestNewProductStatus(t *testing.T) {
+	for _, s := range product.ProductStatuses {
+		status, err := product.NewProductStatus(s.String())
+		require.NoError(t, err)
+		assert.Equal(t, s, status)
+	}
+
+	_, err := product.NewProductStatus("deleted")
+	assert.True(t, product.IsValidationError(err))
+	_, err = product.NewProductStatus("")
+	assert.True(t, product.IsValidationError(err))
+}
+
+func TestProductStatus_Transitions(t *testing.T) {
+	tests := []struct {
+		from, to product.ProductStatus
+		allowed  bool
+	}{
+		{product.StatusDraft, product.StatusPublished, true},
+		{product.StatusDraft, product.StatusArchived, true},
+		{product.StatusDraft, product.StatusDraft, false},
+		{product.StatusPublished, product.StatusDraft, true},
+		{product.StatusPublished, product.StatusArchived, true},
+		{product.StatusPublished, product.StatusPublished, false},
+		{product.StatusArchived, product.StatusDraft, true},
+		{product.StatusArchived, product.StatusPublished, false},
+		{product.StatusArchived, product.StatusArchived, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from.String()+" to "+tt.to.String(), func(t *testing.T) {
+			assert.Equal(t, tt.allowed, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}
+
+// newDraft returns a draft product with stock and no category
+func newDraft(t *testing.T) *product.Product {
+	t.Helper()
+
+	return newStockedProduct(t, map[product.WarehouseID]uint{product.DefaultWarehouseID: 5})
+}
+
+// newPublishable returns a draft product with a category and stock
+func newPublishable(t *testing.T) *product.Product {
+	t.Helper()
+
+	p := newDraft(t)
+	category, err := product.NewCategory("c1", "Computers")
+	require.NoError(t, err)
+	p.AddCategory(category)
+	return p
+}
+
+func TestProduct_TransitionTo(t *testing.T) {
+	p := newPublishable(t)
+	assert.Equal(t, product.StatusDraft, p.Status(), "New products are drafts")
+	assert.False(t, p.IsPublished())
+
+	require.NoError(t, p.TransitionTo(product.StatusPublished, product.DefaultPublishGuards))
+	assert.True(t, p.IsPublished())
+
+	require.NoError(t, p.TransitionTo(product.StatusArchived, product.DefaultPublishGuards))
+	err := p.TransitionTo(product.StatusPublished, product.DefaultPublishGuards)
+	assert.ErrorIs(t, err, product.ErrInvalidStatusTransition)
+	assert.Equal(t, product.StatusArchived, p.Status())
+
+	require.NoError(t, p.TransitionTo(product.StatusDraft, product.DefaultPublishGuards))
+	assert.Equal(t, product.StatusDraft, p.Status())
+}
+
+func TestProduct_PublishGuards(t *testing.T) {
+	tests := []struct {
+		name    string
+		product func(t *testing.T) *product.Product
+		reasons []string
+	}{
+		{
+			name:    "no category",
+			product: newDraft,
+			reasons: []string{"it has no category"},
+		},
+		{
+			name: "no stock",
+			product: func(t *testing.T) *product.Product {
+				p := newPublishable(t)
+				p.UpdateStock(product.NewStock(0))
+				return p
+			},
+			reasons: []string{"it has no stock"},
+		},
+		{
+			name: "neither",
+			product: func(t *testing.T) *product.Product {
+				p := newDraft(t)
+				p.UpdateStock(product.NewStock(0))
+				return p
+			},
+			reasons: []string{"it has no category", "it has no stock"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.product(t)
+			err := p.TransitionTo(product.StatusPublished, product.DefaultPublishGuards)
+			require.ErrorIs(t, err, product.ErrNotPublishable)
+			for _, reason := range tt.reasons {
+				assert.Contains(t, err.Error(), reason)
+			}
+			assert.Equal(t, product.StatusDraft, p.Status())
+
+			// The guards only apply to publishing
+			assert.NoError(t, p.TransitionTo(product.StatusArchived, product.DefaultPublishGuards))
+		})
+	}
+}
+
+func TestService_ChangeStatus(t *testing.T) {
+	ctx := context.Background()
+	service := product.NewService(infrastructure.NewProductRepository())
+	var events []product.Event
+	service.Subscribe(product.EventHandlerFunc(func(_ context.Context, e product.Event) {
+		events = append(events, e)
+	}))
+
+	price, err := product.NewPrice(1000, "USD")
+	require.NoError(t, err)
+	_, err = service.CreateProduct(ctx, "p1", "Laptop", "", price, product.NewStock(5))
+	require.NoError(t, err)
+	category, err := product.NewCategory("c1", "Computers")
+	require.NoError(t, err)
+
+	_, err = service.ChangeStatus(ctx, "p1", product.StatusPublished)
+	assert.ErrorIs(t, err, product.ErrNotPublishable)
+	_, err = service.ChangeStatus(ctx, "missing", product.StatusPublished)
+	assert.ErrorIs(t, err, product.ErrProductNotFound)
+
+	_, err = service.AddCategoryToProduct(ctx, "p1", category)
+	require.NoError(t, err)
+	events = nil
+
+	published, err := service.ChangeStatus(ctx, "p1", product.StatusPublished)
+	require.NoError(t, err)
+	assert.True(t, published.IsPublished())
+
+	stored, err := service.GetProductByID(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, product.StatusPublished, stored.Status())
+
+	require.Len(t, events, 1)
+	assert.Equal(t, product.EventStatusChanged, events[0].Type)
+	assert.Equal(t, product.StatusDraft, events[0].PreviousStatus)
+	assert.Equal(t, product.StatusPublished, events[0].Product.Status())
+
+	// Without guards, any draft can be published
+	service.SetPublishGuards()
+	_, err = service.CreateProduct(ctx, "p2", "Mouse", "", price, product.NewStock(0))
+	require.NoError(t, err)
+	_, err = service.ChangeStatus(ctx, "p2", product.StatusPublished)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,73 @@
+package product_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	product "sago-sample/feature/product/domain"
+)
+
+func TestNewReorderPolicy(t *testing.T) {
+	policy, err := product.NewReorderPolicy("prod-1", 5, 20)
+	require.NoError(t, err)
+	assert.Equal(t, uint(5), policy.Threshold())
+	assert.Equal(t, uint(20), policy.Target())
+
+	_, err = product.NewReorderPolicy("prod-1", 5, 5)
+	assert.True(t, product.IsValidationError(err))
+	_, err = product.NewReorderPolicy("", 0, 1)
+	assert.True(t, product.IsValidationError(err))
+}
+
+func TestReorderPolicy_SuggestedQuantity(t *testing.T) {
+	policy, err := product.NewReorderPolicy("prod-1", 5, 20)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint(0), policy.SuggestedQuantity(product.NewStock(6)))
+	assert.Equal(t, uint(15), policy.SuggestedQuantity(product.NewStock(5)))
+	assert.Equal(t, uint(20), policy.SuggestedQuantity(product.NewStock(0)))
+}
+
+func TestEvaluateStockChange(t *testing.T) {
+	policy, err := product.NewReorderPolicy("prod-1", 5, 20)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		previous  uint
+		current   uint
+		policy    *product.ReorderPolicy
+		wantAlert product.AlertType
+	}{
+		{name: "above the threshold", previous: 10, current: 6, policy: policy},
+		{name: "falls to the threshold", previous: 6, current: 5, policy: policy, wantAlert: product.AlertLowStock},
+		{name: "stays low", previous: 5, current: 2, policy: policy},
+		{name: "runs out while low", previous: 2, current: 0, policy: policy, wantAlert: product.AlertOutOfStock},
+		{name: "runs out at once", previous: 10, current: 0, policy: policy, wantAlert: product.AlertOutOfStock},
+		{name: "recovers", previous: 0, current: 3, policy: policy},
+		{name: "runs out without a policy", previous: 3, current: 0, wantAlert: product.AlertOutOfStock},
+		{name: "low without a policy", previous: 3, current: 1},
+	}
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert, ok := product.EvaluateStockChange("prod-1", "Laptop", product.NewStock(tt.previous), product.NewStock(tt.current), tt.policy, at)
+			if tt.wantAlert == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.wantAlert, alert.Type)
+			assert.Equal(t, tt.current, alert.Stock)
+			assert.Equal(t, at, alert.OccurredAt)
+			if tt.policy != nil {
+				assert.Equal(t, uint(5), alert.Threshold)
+				assert.Equal(t, 20-tt.current, alert.SuggestedQuantity)
+			}
+		})
+	}
+}
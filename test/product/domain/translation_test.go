@@ -0,0 +1,201 @@
+package product_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	product "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/infrastructure"
+)
+
+func mustLocales(t *testing.T, defaultLocale string, supported ...string) product.Locales {
+	t.Helper()
+
+	l, err := product.NewLocales(defaultLocale, supported)
+	require.NoError(t, err)
+	return l
+}
+
+func mustTranslation(t *testing.T, name, description string) product.ProductTranslation {
+	t.Helper()
+
+	n, err := product.NewProductName(name)
+	require.NoError(t, err)
+	d, err := product.NewProductDescription(description)
+	require.NoError(t, err)
+	tr, err := product.NewProductTranslation(n, d)
+	require.NoError(t, err)
+	return tr
+}
+
+func TestNewLocale(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want product.Locale
+	}{
+		{"en", "en"},
+		{"EN", "en"},
+		{"en-us", "en-US"},
+		{"pt_BR", "pt-BR"},
+		{"zh-hant-tw", "zh-Hant-TW"},
+		{"es-419", "es-419"},
+		{" ja ", "ja"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			l, err := product.NewLocale(tt.tag)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, l)
+		})
+	}
+
+	for _, tag := range []string{"", "*", "e", "english", "en-", "en-USA", "en US"} {
+		t.Run("invalid "+tag, func(t *testing.T) {
+			_, err := product.NewLocale(tag)
+			assert.True(t, product.IsValidationError(err), "got %v", err)
+		})
+	}
+}
+
+func TestNewLocales(t *testing.T) {
+	l := mustLocales(t, "en", "fr", "FR", "ja-JP")
+	assert.Equal(t, product.Locale("en"), l.Default())
+	assert.Equal(t, []product.Locale{"en", "fr", "ja-JP"}, l.Supported(), "the default comes first and duplicates are dropped")
+
+	_, err := product.NewLocales("english", nil)
+	assert.Error(t, err)
+	_, err = product.NewLocales("en", []string{"fr", "??"})
+	assert.Error(t, err)
+}
+
+func TestLocales_Negotiate(t *testing.T) {
+	l := mustLocales(t, "en", "fr", "pt-BR", "ja")
+
+	tests := []struct {
+		name      string
+		preferred []product.Locale
+		want      product.Locale
+	}{
+		{"no preference", nil, "en"},
+		{"exact", []product.Locale{"fr"}, "fr"},
+		{"region falls back to its language", []product.Locale{"ja-JP"}, "ja"},
+		{"language matches a regional locale", []product.Locale{"pt"}, "pt-BR"},
+		{"first supported preference wins", []product.Locale{"de", "ja", "fr"}, "ja"},
+		{"unsupported", []product.Locale{"de", "it"}, "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, l.Negotiate(tt.preferred))
+		})
+	}
+}
+
+func TestProduct_LocalizedTexts(t *testing.T) {
+	p, err := product.NewProduct("p1", "Laptop", "A laptop", product.MustNewPrice(1000, "USD"), product.NewStock(1))
+	require.NoError(t, err)
+	p.SetTranslation("fr", mustTranslation(t, "Ordinateur", "Un ordinateur portable"))
+	p.SetTranslation("ja", mustTranslation(t, "ノートパソコン", ""))
+
+	assert.Equal(t, "Ordinateur", p.LocalizedName("fr").String())
+	assert.Equal(t, "Ordinateur", p.LocalizedName("fr-CA").String(), "a region falls back to its language")
+	assert.Equal(t, "Un ordinateur portable", p.LocalizedDescription("fr").String())
+	assert.Equal(t, "ノートパソコン", p.LocalizedName("ja").String())
+	assert.Equal(t, p.Description().String(), p.LocalizedDescription("ja").String(), "an empty description falls back to the default one")
+	assert.Equal(t, p.Name().String(), p.LocalizedName("de").String())
+
+	require.NoError(t, p.RemoveTranslation("fr"))
+	assert.Equal(t, p.Name().String(), p.LocalizedName("fr").String())
+	assert.ErrorIs(t, p.RemoveTranslation("fr"), product.ErrTranslationNotFound)
+}
+
+func TestProductName_CountsCharacters(t *testing.T) {
+	// Each of these characters takes 3 bytes in UTF-8, so the byte length is well above the limit
+	name := strings.Repeat("日", product.MaxProductNameLength)
+	_, err := product.NewProductName(name)
+	assert.NoError(t, err)
+
+	_, err = product.NewProductName(name + "本")
+	assert.True(t, product.IsValidationError(err), "got %v", err)
+
+	_, err = product.NewCategoryName(strings.Repeat("é", product.MaxCategoryNameLength))
+	assert.NoError(t, err)
+}
+
+func TestCategoryTranslations(t *testing.T) {
+	tr, err := product.NewCategoryTranslations("laptops", nil)
+	require.NoError(t, err)
+
+	name, err := product.NewCategoryName("Portables")
+	require.NoError(t, err)
+	require.NoError(t, tr.Set("fr", name))
+
+	got, ok := tr.Name("fr-BE")
+	assert.True(t, ok)
+	assert.Equal(t, "Portables", got.String())
+	_, ok = tr.Name("de")
+	assert.False(t, ok)
+
+	require.NoError(t, tr.Remove("fr"))
+	assert.ErrorIs(t, tr.Remove("fr"), product.ErrTranslationNotFound)
+	assert.Empty(t, tr.Names())
+}
+
+func TestTranslationService(t *testing.T) {
+	ctx := context.Background()
+	repo := infrastructure.NewProductRepository()
+	service := product.NewService(repo)
+	_, err := service.CreateProduct(ctx, "p1", "Laptop", "A laptop", product.MustNewPrice(1000, "USD"), product.NewStock(1))
+	require.NoError(t, err)
+	laptops, err := product.NewCategory("laptops", "Laptops")
+	require.NoError(t, err)
+	_, err = service.AddCategoryToProduct(ctx, "p1", laptops)
+	require.NoError(t, err)
+
+	translations := product.NewTranslationService(service, infrastructure.NewCategoryTranslationRepository(), mustLocales(t, "en", "fr"))
+
+	t.Run("rejects the default and unsupported locales", func(t *testing.T) {
+		for _, locale := range []product.Locale{"en", "de"} {
+			_, err := translations.SetProductTranslation(ctx, "p1", locale, mustTranslation(t, "Laptop", ""))
+			assert.True(t, product.IsValidationError(err), "%s: got %v", locale, err)
+		}
+	})
+
+	t.Run("saves product translations", func(t *testing.T) {
+		_, err := translations.SetProductTranslation(ctx, "p1", "fr", mustTranslation(t, "Ordinateur", ""))
+		require.NoError(t, err)
+
+		stored, err := repo.FindByID(ctx, "p1")
+		require.NoError(t, err)
+		assert.Equal(t, "Ordinateur", stored.LocalizedName("fr").String())
+
+		_, err = translations.SetProductTranslation(ctx, "missing", "fr", mustTranslation(t, "Ordinateur", ""))
+		assert.ErrorIs(t, err, product.ErrProductNotFound)
+	})
+
+	t.Run("translates category names", func(t *testing.T) {
+		name, err := product.NewCategoryName("Portables")
+		require.NoError(t, err)
+		_, err = translations.SetCategoryTranslation(ctx, "laptops", "fr", name)
+		require.NoError(t, err)
+
+		stored, err := repo.FindByID(ctx, "p1")
+		require.NoError(t, err)
+		names, err := translations.CategoryNames(ctx, "fr", []*product.Product{stored})
+		require.NoError(t, err)
+		assert.Equal(t, "Portables", names["laptops"].String())
+
+		names, err = translations.CategoryNames(ctx, "en", []*product.Product{stored})
+		require.NoError(t, err)
+		assert.Empty(t, names, "the default locale is not looked up")
+
+		_, err = translations.RemoveCategoryTranslation(ctx, "laptops", "fr")
+		require.NoError(t, err)
+		empty, err := translations.GetCategoryTranslations(ctx, "laptops")
+		require.NoError(t, err)
+		assert.Empty(t, empty.Names())
+	})
+}
@@ -0,0 +1,139 @@
+package product_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	product "sago-sample/feature/product/domain"
+)
+
+// nameConstructors are the constructors of the single-line names, returning the name as a string
+var nameConstructors = []struct {
+	name      string
+	maxLength int
+	create    func(string) (string, error)
+}{
+	{"product name", product.MaxProductNameLength, func(s string) (string, error) {
+		n, err := product.NewProductName(s)
+		return n.String(), err
+	}},
+	{"category name", product.MaxCategoryNameLength, func(s string) (string, error) {
+		n, err := product.NewCategoryName(s)
+		return n.String(), err
+	}},
+	{"warehouse name", product.MaxWarehouseNameLength, func(s string) (string, error) {
+		n, err := product.NewWarehouseName(s)
+		return string(n), err
+	}},
+}
+
+func TestNames_AreNormalized(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"French, decomposed", "  Cafe\u0301   Noir ", "Café Noir"},
+		{"Vietnamese, decomposed", "Vie\u0323\u0302t\tNam", "Việt Nam"},
+		{"Korean jamo", "\u1112\u1161\u11AB\u1100\u1173\u11AF", "한글"},
+		{"Japanese with an ideographic space", "東京\u3000倉庫", "東京 倉庫"},
+		{"German", "Größe\nXL", "Größe XL"},
+		{"Arabic", "مستودع الرياض", "مستودع الرياض"},
+		{"Greek", "Αποθήκη", "Αποθήκη"},
+	}
+	for _, c := range nameConstructors {
+		for _, tt := range tests {
+			t.Run(c.name+"/"+tt.name, func(t *testing.T) {
+				got, err := c.create(tt.input)
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			})
+		}
+	}
+}
+
+func TestNames_AreRejected(t *testing.T) {
+	for _, c := range nameConstructors {
+		tests := []struct {
+			name  string
+			input string
+		}{
+			{"empty", ""},
+			{"only whitespace", " \u3000\u00A0\n"},
+			{"control character", "Laptop\x00"},
+			{"escape sequence", "\x1b[2JLaptop"},
+			{"right-to-left override", "Laptop\u202Egpj"},
+			{"isolate", "\u2066Laptop\u2069"},
+			{"invalid UTF-8", "Laptop\xc3"},
+			{"too many characters", strings.Repeat("ü", c.maxLength+1)},
+		}
+		for _, tt := range tests {
+			t.Run(c.name+"/"+tt.name, func(t *testing.T) {
+				_, err := c.create(tt.input)
+				assert.True(t, product.IsValidationError(err), "got %v", err)
+			})
+		}
+	}
+}
+
+func TestNames_LimitCountsCharacters(t *testing.T) {
+	for _, c := range nameConstructors {
+		for _, char := range []string{"日", "é", "e\u0301", "한", "💻"} {
+			t.Run(c.name+"/"+char, func(t *testing.T) {
+				got, err := c.create(strings.Repeat(char, c.maxLength))
+				require.NoError(t, err)
+				assert.Equal(t, c.maxLength, len([]rune(got)))
+			})
+		}
+	}
+}
+
+func TestNewProductDescription_KeepsParagraphs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"collapses spaces", "A  light\tlaptop ", "A light laptop"},
+		{"keeps paragraphs", "Light.\r\n\r\n\r\nFast.", "Light.\n\nFast."},
+		{"composes", "Tre\u0300s le\u0301ger", "Très léger"},
+		{"Japanese", "軽量\n\n高速", "軽量\n\n高速"},
+		{"empty", "   ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := product.NewProductDescription(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+
+	_, err := product.NewProductDescription(strings.Repeat("語", product.MaxProductDescriptionLength))
+	assert.NoError(t, err)
+	_, err = product.NewProductDescription(strings.Repeat("語", product.MaxProductDescriptionLength+1))
+	assert.True(t, product.IsValidationError(err), "got %v", err)
+	_, err = product.NewProductDescription("Light\u202E")
+	assert.True(t, product.IsValidationError(err), "got %v", err)
+}
+
+func TestAttributes_AreNormalized(t *testing.T) {
+	attrs, err := product.NewAttributes(map[string]any{"color": " Bleu  fonce\u0301 "})
+	require.NoError(t, err)
+	assert.Equal(t, "Bleu foncé", attrs["color"])
+	assert.True(t, attrs.Matches("color", "Bleu fonce\u0301"), "Filters are normalized too")
+	assert.False(t, attrs.Matches("color", "Bleu foncé\x00"))
+
+	_, err = product.NewAttributes(map[string]any{"color": "red\u202E"})
+	assert.True(t, product.IsValidationError(err), "got %v", err)
+	_, err = product.NewAttributes(map[string]any{"color": strings.Repeat("色", product.MaxAttributeValueLength)})
+	assert.NoError(t, err)
+
+	d, err := product.NewAttributeDefinition("color", product.AttributeEnum, false, []string{"Cafe\u0301", " noir "})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Café", "noir"}, d.Values())
+	_, err = product.NewAttributeDefinition("color", product.AttributeEnum, false, []string{"Café", "Cafe\u0301"})
+	assert.True(t, product.IsValidationError(err), "Equivalent values are duplicates, got %v", err)
+}
@@ -0,0 +1,173 @@
+package attributes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sago-sample/feature/product/handler"
+	"sago-sample/test/apitest"
+)
+
+// newRouter serves the API over in-memory repositories
+func newRouter(t *testing.T) chi.Router {
+	t.Helper()
+
+	return apitest.New(t, apitest.Options{}).Router
+}
+
+// createProduct creates a published product in the category
+func createProduct(t *testing.T, rtr chi.Router, id, categoryID string) {
+	t.Helper()
+
+	w := apitest.Do(t, rtr, http.MethodPost, "/api/products", handler.CreateProductRequest{
+		ID: id, Name: "Laptop", Description: "A laptop", Price: 1000, Currency: "USD", Stock: 5,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/products/"+id+"/categories", handler.AddCategoryToProductRequest{CategoryID: categoryID, CategoryName: "Category " + categoryID})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/admin/products/"+id+"/status", handler.ChangeStatusRequest{Status: "published"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+// laptopSchema defines a required enum color, a number ram_gb and a bool refurbished
+var laptopSchema = handler.AttributeSchemaRequest{Definitions: []handler.AttributeDefinitionRequest{
+	{Key: "color", Type: "enum", Required: true, Values: []string{"red", "black"}},
+	{Key: "ram_gb", Type: "number"},
+	{Key: "refurbished", Type: "bool"},
+}}
+
+func setAttributes(t *testing.T, rtr chi.Router, id string, attributes map[string]any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return apitest.Do(t, rtr, http.MethodPut, "/api/products/"+id+"/attributes", handler.ProductAttributesRequest{Attributes: attributes})
+}
+
+func ids(products []handler.ProductResponse) []string {
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestAttributes_SchemaLifecycle(t *testing.T) {
+	rtr := newRouter(t)
+
+	assert.Equal(t, http.StatusNotFound, apitest.Do(t, rtr, http.MethodGet, "/api/categories/laptops/attribute-schema", nil).Code)
+
+	w := apitest.Do(t, rtr, http.MethodPut, "/api/categories/laptops/attribute-schema", laptopSchema)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/categories/laptops/attribute-schema", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	got := apitest.Decode[handler.AttributeSchemaResponse](t, w)
+	assert.Equal(t, "laptops", got.CategoryID)
+	assert.Equal(t, laptopSchema.Definitions, got.Definitions)
+
+	assert.Equal(t, http.StatusNoContent, apitest.Do(t, rtr, http.MethodDelete, "/api/categories/laptops/attribute-schema", nil).Code)
+	assert.Equal(t, http.StatusNotFound, apitest.Do(t, rtr, http.MethodDelete, "/api/categories/laptops/attribute-schema", nil).Code)
+}
+
+func TestAttributes_RejectsInvalidSchemas(t *testing.T) {
+	rtr := newRouter(t)
+
+	tests := []struct {
+		name       string
+		definition handler.AttributeDefinitionRequest
+	}{
+		{"unknown type", handler.AttributeDefinitionRequest{Key: "color", Type: "colour"}},
+		{"invalid key", handler.AttributeDefinitionRequest{Key: "Color", Type: "string"}},
+		{"enum without values", handler.AttributeDefinitionRequest{Key: "color", Type: "enum"}},
+		{"values on a string", handler.AttributeDefinitionRequest{Key: "color", Type: "string", Values: []string{"red"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := handler.AttributeSchemaRequest{Definitions: []handler.AttributeDefinitionRequest{tt.definition}}
+			w := apitest.Do(t, rtr, http.MethodPut, "/api/categories/laptops/attribute-schema", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestAttributes_ValidatedAgainstCategorySchemas(t *testing.T) {
+	rtr := newRouter(t)
+	createProduct(t, rtr, "p1", "laptops")
+	require.Equal(t, http.StatusOK, apitest.Do(t, rtr, http.MethodPut, "/api/categories/laptops/attribute-schema", laptopSchema).Code)
+
+	tests := []struct {
+		name       string
+		attributes map[string]any
+		want       int
+	}{
+		{"valid", map[string]any{"color": "red", "ram_gb": 16, "refurbished": false}, http.StatusOK},
+		{"missing required", map[string]any{"ram_gb": 16}, http.StatusBadRequest},
+		{"not an enum value", map[string]any{"color": "green"}, http.StatusBadRequest},
+		{"wrong type", map[string]any{"color": "red", "ram_gb": "16"}, http.StatusBadRequest},
+		{"undefined", map[string]any{"color": "red", "weight": 2}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := setAttributes(t, rtr, "p1", tt.attributes)
+			assert.Equal(t, tt.want, w.Code, w.Body.String())
+		})
+	}
+
+	w := apitest.Do(t, rtr, http.MethodGet, "/api/products/p1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, map[string]any{"color": "red", "ram_gb": float64(16), "refurbished": false}, apitest.Decode[handler.ProductResponse](t, w).Attributes,
+		"Rejected attributes leave the product unchanged")
+
+	assert.Equal(t, http.StatusNotFound, setAttributes(t, rtr, "missing", map[string]any{}).Code)
+}
+
+func TestAttributes_FilterProducts(t *testing.T) {
+	rtr := newRouter(t)
+	require.Equal(t, http.StatusOK, apitest.Do(t, rtr, http.MethodPut, "/api/categories/laptops/attribute-schema", laptopSchema).Code)
+	for id, attributes := range map[string]map[string]any{
+		"p1": {"color": "red", "ram_gb": 16},
+		"p2": {"color": "black", "ram_gb": 16, "refurbished": true},
+		"p3": {"color": "red", "ram_gb": 32},
+	} {
+		createProduct(t, rtr, id, "laptops")
+		require.Equal(t, http.StatusOK, setAttributes(t, rtr, id, attributes).Code)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"p1", "p2", "p3"}},
+		{"?attr.color=red", []string{"p1", "p3"}},
+		{"?attr.color=red&attr.ram_gb=16", []string{"p1"}},
+		{"?attr.ram_gb=16.0", []string{"p1", "p2"}},
+		{"?attr.refurbished=true", []string{"p2"}},
+		{"?attr.color=green", []string{}},
+		{"?attr.weight=2", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := apitest.Do(t, rtr, http.MethodGet, "/api/products"+tt.query, nil)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, tt.want, ids(apitest.Decode[[]handler.ProductResponse](t, w)))
+		})
+	}
+
+	assert.Equal(t, http.StatusBadRequest, apitest.Do(t, rtr, http.MethodGet, "/api/products?attr.Color=red", nil).Code)
+
+	// The next page keeps the filter
+	w := apitest.Do(t, rtr, http.MethodGet, "/api/products?attr.color=red&limit=1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-Total-Count"))
+	assert.Equal(t, `</api/products?attr.color=red&cursor=p1&limit=1>; rel="next"`, w.Header().Get("Link"))
+
+	// Filters are part of the ETag of the list, even when they select the same page
+	other := apitest.Do(t, rtr, http.MethodGet, "/api/products?attr.ram_gb=16&limit=1", nil)
+	require.Equal(t, []string{"p1"}, ids(apitest.Decode[[]handler.ProductResponse](t, other)))
+	assert.Equal(t, "2", other.Header().Get("X-Total-Count"))
+	assert.NotEqual(t, w.Header().Get("ETag"), other.Header().Get("ETag"))
+}
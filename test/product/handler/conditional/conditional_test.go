@@ -0,0 +1,194 @@
+package conditional_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sago-sample/feature/product/handler"
+	"sago-sample/test/apitest"
+)
+
+// newRouter serves the API over in-memory repositories, reading products with the given cache policy.
+// Products can be published without a category.
+func newRouter(t *testing.T, policy handler.CachePolicy) chi.Router {
+	t.Helper()
+
+	api := apitest.New(t, apitest.Options{})
+	api.Service.SetPublishGuards()
+	api.Handlers.GetProduct.CachePolicy = policy
+	return api.Router
+}
+
+// createProduct creates and publishes a product
+func createProduct(t *testing.T, rtr chi.Router, id string) {
+	t.Helper()
+
+	w := apitest.Do(t, rtr, http.MethodPost, "/api/products", handler.CreateProductRequest{
+		ID: id, Name: "Laptop", Description: "A laptop", Price: 1000, Currency: "USD", Stock: 5,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/admin/products/"+id+"/status", handler.ChangeStatusRequest{Status: "published"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func updatePrice(t *testing.T, rtr chi.Router, id string, price uint) {
+	t.Helper()
+
+	w := apitest.Do(t, rtr, http.MethodPut, "/api/products/"+id, handler.UpdateProductRequest{
+		Name: "Laptop", Description: "A laptop", Price: price, Currency: "USD", Stock: 5,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestGetProduct_SendsValidators(t *testing.T) {
+	rtr := newRouter(t, handler.DefaultCachePolicy())
+	createProduct(t, rtr, "p1")
+
+	w := apitest.Do(t, rtr, http.MethodGet, "/api/products/p1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	etag := w.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag, "a strong ETag")
+	lastModified, err := http.ParseTime(w.Header().Get("Last-Modified"))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), lastModified, 2*time.Second)
+	assert.Equal(t, "public, no-cache", w.Header().Get("Cache-Control"))
+
+	again := apitest.Do(t, rtr, http.MethodGet, "/api/products/p1", nil)
+	assert.Equal(t, etag, again.Header().Get("ETag"), "the ETag is stable while the product is unchanged")
+}
+
+func TestGetProduct_IfNoneMatch(t *testing.T) {
+	rtr := newRouter(t, handler.DefaultCachePolicy())
+	createProduct(t, rtr, "p1")
+	etag := apitest.Do(t, rtr, http.MethodGet, "/api/products/p1", nil).Header().Get("ETag")
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"matching", etag, http.StatusNotModified},
+		{"weak comparison", "W/" + etag, http.StatusNotModified},
+		{"one of several", `"other", ` + etag, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"different", `"other"`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products/p1", nil, http.Header{"If-None-Match": {tt.header}})
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			if tt.want == http.StatusNotModified {
+				assert.Empty(t, w.Body.Bytes())
+				assert.Equal(t, "public, no-cache", w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+
+	updatePrice(t, rtr, "p1", 2000)
+
+	w := apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products/p1", nil, http.Header{"If-None-Match": {etag}})
+	require.Equal(t, http.StatusOK, w.Code, "the cached copy is stale after an update")
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"price":2000`)
+
+	w = apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products/p1", nil, http.Header{"If-None-Match": {w.Header().Get("ETag")}})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
+
+func TestGetProduct_IfModifiedSince(t *testing.T) {
+	rtr := newRouter(t, handler.DefaultCachePolicy())
+	createProduct(t, rtr, "p1")
+	w := apitest.Do(t, rtr, http.MethodGet, "/api/products/p1", nil)
+	lastModified := w.Header().Get("Last-Modified")
+	modified, err := http.ParseTime(lastModified)
+	require.NoError(t, err)
+
+	w = apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products/p1", nil, http.Header{"If-Modified-Since": {lastModified}})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	earlier := modified.Add(-time.Second).Format(http.TimeFormat)
+	w = apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products/p1", nil, http.Header{"If-Modified-Since": {earlier}})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products/p1", nil, http.Header{"If-Modified-Since": {"not a date"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// If-None-Match takes precedence over If-Modified-Since
+	w = apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products/p1", nil, http.Header{
+		"If-None-Match":     {`"other"`},
+		"If-Modified-Since": {lastModified},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestListProducts_Revalidation(t *testing.T) {
+	rtr := newRouter(t, handler.DefaultCachePolicy())
+	createProduct(t, rtr, "p1")
+	createProduct(t, rtr, "p2")
+
+	w := apitest.Do(t, rtr, http.MethodGet, "/api/products", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	w = apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products", nil, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-Total-Count"))
+
+	page := apitest.Do(t, rtr, http.MethodGet, "/api/products?limit=1", nil)
+	assert.NotEqual(t, etag, page.Header().Get("ETag"), "each page has its own ETag")
+
+	updatePrice(t, rtr, "p2", 1500)
+	w = apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products", nil, http.Header{"If-None-Match": {etag}})
+	require.Equal(t, http.StatusOK, w.Code)
+	etag = w.Header().Get("ETag")
+
+	require.Equal(t, http.StatusNoContent, apitest.Do(t, rtr, http.MethodDelete, "/api/products/p1", nil).Code)
+	w = apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products", nil, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusOK, w.Code, "removing a product changes the list")
+}
+
+func TestProductsByCategory_Revalidation(t *testing.T) {
+	rtr := newRouter(t, handler.DefaultCachePolicy())
+	createProduct(t, rtr, "p1")
+	createProduct(t, rtr, "p2")
+	for _, id := range []string{"p1", "p2"} {
+		w := apitest.Do(t, rtr, http.MethodPost, "/api/products/"+id+"/categories", handler.AddCategoryToProductRequest{CategoryID: "c1", CategoryName: "Computers"})
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := apitest.Do(t, rtr, http.MethodGet, "/api/categories/c1/products", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+
+	for i := 0; i < 5; i++ {
+		w = apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/categories/c1/products", nil, http.Header{"If-None-Match": {etag}})
+		require.Equal(t, http.StatusNotModified, w.Code)
+	}
+
+	require.Equal(t, http.StatusOK, apitest.Do(t, rtr, http.MethodDelete, "/api/products/p2/categories/c1", nil).Code)
+	w = apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/categories/c1/products", nil, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCachePolicy_PerRoute(t *testing.T) {
+	rtr := newRouter(t, handler.CachePolicy{
+		Product:  "public, max-age=60",
+		List:     "no-store",
+		Category: "",
+	})
+	createProduct(t, rtr, "p1")
+
+	assert.Equal(t, "public, max-age=60", apitest.Do(t, rtr, http.MethodGet, "/api/products/p1", nil).Header().Get("Cache-Control"))
+	assert.Equal(t, "no-store", apitest.Do(t, rtr, http.MethodGet, "/api/products", nil).Header().Get("Cache-Control"))
+	assert.Empty(t, apitest.Do(t, rtr, http.MethodGet, "/api/categories/c1/products", nil).Header().Get("Cache-Control"))
+}
@@ -0,0 +1,116 @@
+package facets_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/handler"
+	"sago-sample/feature/product/infrastructure"
+	usecase "sago-sample/feature/product/usecase"
+	"sago-sample/test/apitest"
+)
+
+// newRouter wires the facet handler to an in-memory repository holding three published laptops and a draft
+func newRouter(t *testing.T) chi.Router {
+	t.Helper()
+
+	ctx := context.Background()
+	repo := infrastructure.NewProductRepository()
+	service := domain.NewService(repo)
+	laptops, err := domain.NewCategory("laptops", "Laptops")
+	require.NoError(t, err)
+
+	add := func(id string, price, stock uint, status domain.ProductStatus, attributes map[string]any) {
+		// Products without stock cannot be published, so the stock is set after publishing
+		_, err := service.CreateProduct(ctx, domain.ProductID(id), domain.ProductName(id), "", domain.MustNewPrice(price, "USD"), domain.NewStock(1))
+		require.NoError(t, err)
+		_, err = service.AddCategoryToProduct(ctx, domain.ProductID(id), laptops)
+		require.NoError(t, err)
+		if status != domain.StatusDraft {
+			_, err = service.ChangeStatus(ctx, domain.ProductID(id), status)
+			require.NoError(t, err)
+		}
+
+		p, err := repo.FindByID(ctx, domain.ProductID(id))
+		require.NoError(t, err)
+		attrs, err := domain.NewAttributes(attributes)
+		require.NoError(t, err)
+		p.SetAttributes(attrs)
+		p.SetStockLevel(domain.DefaultWarehouseID, domain.NewStock(stock))
+		require.NoError(t, repo.Save(ctx, p))
+	}
+	add("p1", 500, 3, domain.StatusPublished, map[string]any{"color": "red"})
+	add("p2", 2500, 0, domain.StatusPublished, map[string]any{"color": "black"})
+	add("p3", 7500, 1, domain.StatusPublished, map[string]any{"color": "red"})
+	add("p4", 2500, 1, domain.StatusDraft, map[string]any{"color": "red"})
+
+	rtr := chi.NewRouter()
+	handler.NewFacetHandler(usecase.NewGetProductFacetsUseCase(repo, []uint{1000, 5000})).Register(rtr)
+	return rtr
+}
+
+func get(t *testing.T, rtr chi.Router, path string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestGetFacets(t *testing.T) {
+	rtr := newRouter(t)
+
+	w := get(t, rtr, "/api/products/facets")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	response := apitest.Decode[handler.FacetsResponse](t, w)
+
+	assert.Equal(t, 3, response.Total, "Drafts are not counted")
+	assert.Equal(t, []handler.CategoryFacetResponse{{ID: "laptops", Name: "Laptops", Count: 3}}, response.Categories)
+	assert.Equal(t, []handler.PriceFacetResponse{
+		{From: 0, To: 1000, Count: 1},
+		{From: 1000, To: 5000, Count: 1},
+		{From: 5000, Count: 1},
+	}, response.Prices)
+	assert.Equal(t, handler.StockFacetResponse{InStock: 2, OutOfStock: 1}, response.Stock)
+	assert.Equal(t, []handler.AttributeFacetResponse{{Key: "color", Values: []handler.AttributeValueFacetResponse{
+		{Value: "black", Count: 1},
+		{Value: "red", Count: 2},
+	}}}, response.Attributes)
+}
+
+func TestGetFacets_Filters(t *testing.T) {
+	rtr := newRouter(t)
+
+	w := get(t, rtr, "/api/products/facets?inStock=true&attr.color=red&priceBuckets=5000")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	response := apitest.Decode[handler.FacetsResponse](t, w)
+
+	assert.Equal(t, 2, response.Total)
+	assert.Equal(t, []handler.PriceFacetResponse{{From: 0, To: 5000, Count: 1}, {From: 5000, Count: 1}}, response.Prices,
+		"priceBuckets replaces the configured bounds")
+	assert.Equal(t, handler.StockFacetResponse{InStock: 2, OutOfStock: 0}, response.Stock, "The stock facet ignores the inStock filter")
+	assert.Equal(t, []handler.AttributeValueFacetResponse{{Value: "red", Count: 2}}, response.Attributes[0].Values,
+		"The out of stock black laptop fails the inStock filter")
+}
+
+func TestGetFacets_InvalidQuery(t *testing.T) {
+	rtr := newRouter(t)
+
+	for _, path := range []string{
+		"/api/products/facets?minPrice=-1",
+		"/api/products/facets?inStock=maybe",
+		"/api/products/facets?priceBuckets=5000,1000",
+		"/api/products/facets?minPrice=5000&maxPrice=1000",
+		"/api/products/facets?attr.Color=red",
+	} {
+		w := get(t, rtr, path)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "%s: %s", path, w.Body.String())
+	}
+}
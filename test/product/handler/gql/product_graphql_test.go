@@ -0,0 +1,297 @@
+package gql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/test/apitest"
+)
+
+// countingRepository counts the read calls made to the wrapped repository
+type countingRepository struct {
+	domain.Repository
+	findAll        atomic.Int32
+	findByCategory atomic.Int32
+}
+
+func (r *countingRepository) FindAll(ctx context.Context) ([]*domain.Product, error) {
+	r.findAll.Add(1)
+	return r.Repository.FindAll(ctx)
+}
+
+func (r *countingRepository) FindByCategory(ctx context.Context, categoryID domain.CategoryID) ([]*domain.Product, error) {
+	r.findByCategory.Add(1)
+	return r.Repository.FindByCategory(ctx, categoryID)
+}
+
+type graphQLResponse struct {
+	Data   map[string]interface{}   `json:"data"`
+	Errors []map[string]interface{} `json:"errors"`
+}
+
+// newTestServer serves the API over in-memory repositories and returns the URL of its GraphQL endpoint
+func newTestServer(t *testing.T) (string, *countingRepository, *domain.Service) {
+	t.Helper()
+
+	var repo *countingRepository
+	api := apitest.New(t, apitest.Options{
+		Products: func(products domain.Repository) domain.Repository {
+			repo = &countingRepository{Repository: products}
+			return repo
+		},
+	})
+
+	server := httptest.NewServer(api.Router)
+	t.Cleanup(server.Close)
+	return server.URL + "/graphql", repo, api.Service
+}
+
+func do(t *testing.T, endpoint, query string, variables map[string]interface{}) graphQLResponse {
+	t.Helper()
+
+	// The OpenAPI document of the API requires variables to be an object when present
+	request := map[string]interface{}{"query": query}
+	if variables != nil {
+		request["variables"] = variables
+	}
+	body, _ := json.Marshal(request)
+	resp, err := http.Post(endpoint, "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result graphQLResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	return result
+}
+
+const createMutation = `mutation($id: ID!, $name: String!) {
+  createProduct(id: $id, input: {name: $name, description: "desc", price: 1000, currency: "USD", stock: 5}) { id }
+}`
+
+const addCategoryMutation = `mutation($pid: ID!, $cid: ID!, $name: String!) {
+  addCategoryToProduct(productId: $pid, categoryId: $cid, categoryName: $name) { id }
+}`
+
+// seed creates three published products through the API: the queries only return published products
+func seed(t *testing.T, endpoint string, service *domain.Service) {
+	t.Helper()
+
+	for _, id := range []string{"prod-1", "prod-2", "prod-3"} {
+		res := do(t, endpoint, createMutation, map[string]interface{}{"id": id, "name": "Product " + id})
+		require.Empty(t, res.Errors)
+	}
+	for _, c := range []struct{ pid, cid, name string }{
+		{"prod-1", "cat-1", "Electronics"},
+		{"prod-2", "cat-1", "Electronics"},
+		{"prod-2", "cat-2", "Sale"},
+		{"prod-3", "cat-2", "Sale"},
+	} {
+		res := do(t, endpoint, addCategoryMutation, map[string]interface{}{"pid": c.pid, "cid": c.cid, "name": c.name})
+		require.Empty(t, res.Errors)
+	}
+	for _, id := range []domain.ProductID{"prod-1", "prod-2", "prod-3"} {
+		_, err := service.ChangeStatus(context.Background(), id, domain.StatusPublished)
+		require.NoError(t, err)
+	}
+}
+
+func TestGraphQL_ProductQuery(t *testing.T) {
+	endpoint, _, service := newTestServer(t)
+	seed(t, endpoint, service)
+
+	res := do(t, endpoint, `{
+  product(id: "prod-2") { id name price { amount currency } stock { quantity available } categories { id name } }
+  missing: product(id: "nope") { id }
+}`, nil)
+	require.Empty(t, res.Errors)
+
+	p := res.Data["product"].(map[string]interface{})
+	assert.Equal(t, "prod-2", p["id"])
+	assert.Equal(t, "Product prod-2", p["name"])
+	assert.Equal(t, map[string]interface{}{"amount": float64(1000), "currency": "USD"}, p["price"])
+	assert.Equal(t, map[string]interface{}{"quantity": float64(5), "available": true}, p["stock"])
+	assert.Len(t, p["categories"], 2)
+	assert.Nil(t, res.Data["missing"])
+}
+
+func TestGraphQL_HidesDraftProducts(t *testing.T) {
+	endpoint, _, service := newTestServer(t)
+	seed(t, endpoint, service)
+	res := do(t, endpoint, createMutation, map[string]interface{}{"id": "draft", "name": "Draft"})
+	require.Empty(t, res.Errors)
+	res = do(t, endpoint, addCategoryMutation, map[string]interface{}{"pid": "draft", "cid": "cat-1", "name": "Electronics"})
+	require.Empty(t, res.Errors)
+
+	res = do(t, endpoint, `{
+  product(id: "draft") { id }
+  products { totalCount }
+  productsByCategory(categoryId: "cat-1") { id }
+}`, nil)
+	require.Empty(t, res.Errors)
+	assert.Nil(t, res.Data["product"], "a draft product is not found")
+	assert.Equal(t, float64(3), res.Data["products"].(map[string]interface{})["totalCount"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "prod-1"},
+		map[string]interface{}{"id": "prod-2"},
+	}, res.Data["productsByCategory"])
+}
+
+func TestGraphQL_ProductsPagination(t *testing.T) {
+	endpoint, _, service := newTestServer(t)
+	seed(t, endpoint, service)
+
+	query := `query($after: String) {
+  products(first: 2, after: $after) { totalCount edges { cursor node { id } } pageInfo { hasNextPage endCursor } }
+}`
+
+	res := do(t, endpoint, query, nil)
+	require.Empty(t, res.Errors)
+	page := res.Data["products"].(map[string]interface{})
+	edges := page["edges"].([]interface{})
+	require.Len(t, edges, 2)
+	assert.Equal(t, float64(3), page["totalCount"])
+	assert.Equal(t, "prod-1", edges[0].(map[string]interface{})["node"].(map[string]interface{})["id"])
+	pageInfo := page["pageInfo"].(map[string]interface{})
+	assert.Equal(t, true, pageInfo["hasNextPage"])
+
+	res = do(t, endpoint, query, map[string]interface{}{"after": pageInfo["endCursor"]})
+	require.Empty(t, res.Errors)
+	page = res.Data["products"].(map[string]interface{})
+	edges = page["edges"].([]interface{})
+	require.Len(t, edges, 1)
+	assert.Equal(t, "prod-3", edges[0].(map[string]interface{})["node"].(map[string]interface{})["id"])
+	assert.Equal(t, false, page["pageInfo"].(map[string]interface{})["hasNextPage"])
+
+	res = do(t, endpoint, query, map[string]interface{}{"after": "not-a-cursor"})
+	assert.NotEmpty(t, res.Errors)
+}
+
+func TestGraphQL_CategoryProductsAreBatched(t *testing.T) {
+	endpoint, repo, service := newTestServer(t)
+	seed(t, endpoint, service)
+	repo.findAll.Store(0)
+	repo.findByCategory.Store(0)
+
+	res := do(t, endpoint, `{
+  products { edges { node { id categories { id products { id } } } } }
+}`, nil)
+	require.Empty(t, res.Errors)
+
+	// One FindAll for the connection and one for every category products field in the response
+	assert.Equal(t, int32(2), repo.findAll.Load())
+	assert.Equal(t, int32(0), repo.findByCategory.Load())
+
+	edges := res.Data["products"].(map[string]interface{})["edges"].([]interface{})
+	node := edges[1].(map[string]interface{})["node"].(map[string]interface{})
+	assert.Equal(t, "prod-2", node["id"])
+	for _, c := range node["categories"].([]interface{}) {
+		category := c.(map[string]interface{})
+		assert.Len(t, category["products"], 2, "category %s", category["id"])
+	}
+}
+
+func TestGraphQL_ProductsByCategory(t *testing.T) {
+	endpoint, _, service := newTestServer(t)
+	seed(t, endpoint, service)
+
+	res := do(t, endpoint, `{ productsByCategory(categoryId: "cat-2") { id } }`, nil)
+	require.Empty(t, res.Errors)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "prod-2"},
+		map[string]interface{}{"id": "prod-3"},
+	}, res.Data["productsByCategory"])
+}
+
+func TestGraphQL_Mutations(t *testing.T) {
+	endpoint, _, service := newTestServer(t)
+	seed(t, endpoint, service)
+
+	res := do(t, endpoint, `mutation {
+  updateProduct(id: "prod-1", input: {name: "Renamed", price: 1500, currency: "jpy", stock: 0}) {
+    name price { currency } stock { available } categories { id }
+  }
+}`, nil)
+	require.Empty(t, res.Errors)
+	updated := res.Data["updateProduct"].(map[string]interface{})
+	assert.Equal(t, "Renamed", updated["name"])
+	assert.Equal(t, "JPY", updated["price"].(map[string]interface{})["currency"])
+	assert.Equal(t, false, updated["stock"].(map[string]interface{})["available"])
+	assert.Len(t, updated["categories"], 1, "Categories should survive an update")
+
+	res = do(t, endpoint, `mutation { removeCategoryFromProduct(productId: "prod-1", categoryId: "cat-1") { categories { id } } }`, nil)
+	require.Empty(t, res.Errors)
+	assert.Empty(t, res.Data["removeCategoryFromProduct"].(map[string]interface{})["categories"])
+
+	res = do(t, endpoint, `mutation { deleteProduct(id: "prod-1") }`, nil)
+	require.Empty(t, res.Errors)
+	assert.Equal(t, true, res.Data["deleteProduct"])
+
+	res = do(t, endpoint, `mutation { deleteProduct(id: "prod-1") }`, nil)
+	require.NotEmpty(t, res.Errors)
+	assert.Equal(t, "product not found", res.Errors[0]["message"])
+
+	res = do(t, endpoint, createMutation, map[string]interface{}{"id": "prod-2", "name": "Duplicate"})
+	require.NotEmpty(t, res.Errors)
+}
+
+func TestGraphQL_BadRequests(t *testing.T) {
+	endpoint, _, _ := newTestServer(t)
+
+	resp, err := http.Post(endpoint, "application/json", bytes.NewReader([]byte("{")))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodDelete, endpoint, nil)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = http.Get(endpoint + "?query=" + "%7Bproducts%7BtotalCount%7D%7D")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestGraphQL_MutationsRequirePOST(t *testing.T) {
+	endpoint, _, service := newTestServer(t)
+	seed(t, endpoint, service)
+
+	for name, params := range map[string]url.Values{
+		"anonymous mutation": {"query": {`mutation { deleteProduct(id: "prod-1") }`}},
+		"named mutation":     {"query": {`query Q { products { totalCount } } mutation M { deleteProduct(id: "prod-1") }`}, "operationName": {"M"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := http.Get(endpoint + "?" + params.Encode())
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+			assert.Equal(t, "POST", resp.Header.Get("Allow"))
+		})
+	}
+
+	// The product was not deleted, and a query next to a mutation can still be selected over GET
+	resp, err := http.Get(endpoint + "?" + url.Values{
+		"query":         {`query Q { product(id: "prod-1") { id } } mutation M { deleteProduct(id: "prod-1") }`},
+		"operationName": {"Q"},
+	}.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var res graphQLResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Empty(t, res.Errors)
+	assert.NotNil(t, res.Data["product"])
+}
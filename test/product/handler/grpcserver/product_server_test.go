@@ -0,0 +1,295 @@
+package grpcserver_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/handler/grpcserver"
+	"sago-sample/feature/product/infrastructure"
+	usecase "sago-sample/feature/product/usecase"
+	productv1 "sago-sample/proto/product/v1"
+)
+
+// newTestClient starts a ProductServer on a bufconn listener and returns a client connected to it,
+// and the product service publishing the products without any guard
+func newTestClient(t *testing.T) (productv1.ProductServiceClient, *domain.Service) {
+	t.Helper()
+
+	repo := infrastructure.NewProductRepository()
+	service := domain.NewService(repo)
+	service.SetPublishGuards()
+
+	productServer := grpcserver.NewProductServer(
+		usecase.NewCreateProductUseCase(service),
+		usecase.NewUpdateProductUseCase(service),
+		usecase.NewDeleteProductUseCase(service),
+		usecase.NewGetProductUseCase(repo),
+		usecase.NewGetAllProductsUseCase(repo),
+		usecase.NewAddCategoryToProductUseCase(service),
+		usecase.NewRemoveCategoryFromProductUseCase(service),
+		usecase.NewGetProductsByCategoryUseCase(service),
+	)
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	productv1.RegisterProductServiceServer(server, productServer)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err, "Failed to dial bufconn")
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return productv1.NewProductServiceClient(conn), service
+}
+
+// publish publishes products, so that GetProduct and ListProducts return them
+func publish(t *testing.T, service *domain.Service, ids ...domain.ProductID) {
+	t.Helper()
+
+	for _, id := range ids {
+		_, err := service.ChangeStatus(context.Background(), id, domain.StatusPublished)
+		require.NoError(t, err)
+	}
+}
+
+func createProduct(t *testing.T, client productv1.ProductServiceClient, id string) {
+	t.Helper()
+
+	_, err := client.CreateProduct(context.Background(), &productv1.CreateProductRequest{
+		Id:          id,
+		Name:        "Product " + id,
+		Description: "Description " + id,
+		Price:       1000,
+		Currency:    "USD",
+		Stock:       10,
+	})
+	require.NoError(t, err, "Failed to create product")
+}
+
+func TestProductServer_CreateAndGet(t *testing.T) {
+	client, service := newTestClient(t)
+	ctx := context.Background()
+
+	created, err := client.CreateProduct(ctx, &productv1.CreateProductRequest{
+		Id:          "prod-123",
+		Name:        "Test Product",
+		Description: "This is a test product",
+		Price:       1000,
+		Currency:    "usd",
+		Stock:       10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "prod-123", created.GetProduct().GetId())
+	assert.Equal(t, "USD", created.GetProduct().GetCurrency(), "Currency should be normalized")
+
+	// A draft product is not found
+	_, err = client.GetProduct(ctx, &productv1.GetProductRequest{Id: "prod-123"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	publish(t, service, "prod-123")
+
+	got, err := client.GetProduct(ctx, &productv1.GetProductRequest{Id: "prod-123"})
+	require.NoError(t, err)
+	assert.Equal(t, "Test Product", got.GetProduct().GetName())
+	assert.Equal(t, "This is a test product", got.GetProduct().GetDescription())
+	assert.Equal(t, uint64(1000), got.GetProduct().GetPrice())
+	assert.Equal(t, uint64(10), got.GetProduct().GetStock())
+	assert.Empty(t, got.GetProduct().GetCategories())
+}
+
+func TestProductServer_UpdateAndDelete(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+	createProduct(t, client, "prod-1")
+
+	updated, err := client.UpdateProduct(ctx, &productv1.UpdateProductRequest{
+		Id:          "prod-1",
+		Name:        "Updated",
+		Description: "Updated description",
+		Price:       2000,
+		Currency:    "JPY",
+		Stock:       3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", updated.GetProduct().GetName())
+	assert.Equal(t, "JPY", updated.GetProduct().GetCurrency())
+
+	_, err = client.DeleteProduct(ctx, &productv1.DeleteProductRequest{Id: "prod-1"})
+	require.NoError(t, err)
+
+	_, err = client.GetProduct(ctx, &productv1.GetProductRequest{Id: "prod-1"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestProductServer_Categories(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+	createProduct(t, client, "prod-1")
+
+	added, err := client.AddCategory(ctx, &productv1.AddCategoryRequest{
+		ProductId:    "prod-1",
+		CategoryId:   "cat-1",
+		CategoryName: "Electronics",
+	})
+	require.NoError(t, err)
+	require.Len(t, added.GetProduct().GetCategories(), 1)
+	assert.Equal(t, "cat-1", added.GetProduct().GetCategories()[0].GetId())
+	assert.Equal(t, "Electronics", added.GetProduct().GetCategories()[0].GetName())
+
+	updated, err := client.UpdateProduct(ctx, &productv1.UpdateProductRequest{
+		Id:          "prod-1",
+		Name:        "Updated",
+		Description: "Updated description",
+		Price:       2000,
+		Currency:    "USD",
+		Stock:       3,
+	})
+	require.NoError(t, err)
+	require.Len(t, updated.GetProduct().GetCategories(), 1, "Update should return the categories like Get")
+	assert.Equal(t, "cat-1", updated.GetProduct().GetCategories()[0].GetId())
+
+	removed, err := client.RemoveCategory(ctx, &productv1.RemoveCategoryRequest{
+		ProductId:  "prod-1",
+		CategoryId: "cat-1",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, removed.GetProduct().GetCategories())
+}
+
+func TestProductServer_ListProducts(t *testing.T) {
+	client, service := newTestClient(t)
+	ctx := context.Background()
+	createProduct(t, client, "prod-1")
+	createProduct(t, client, "prod-2")
+	createProduct(t, client, "prod-3")
+	createProduct(t, client, "draft")
+
+	_, err := client.AddCategory(ctx, &productv1.AddCategoryRequest{
+		ProductId:    "prod-2",
+		CategoryId:   "cat-1",
+		CategoryName: "Electronics",
+	})
+	require.NoError(t, err)
+	_, err = client.AddCategory(ctx, &productv1.AddCategoryRequest{
+		ProductId:    "draft",
+		CategoryId:   "cat-1",
+		CategoryName: "Electronics",
+	})
+	require.NoError(t, err)
+	publish(t, service, "prod-1", "prod-2", "prod-3")
+
+	// recv drains a ListProducts stream and returns the received product IDs
+	recv := func(req *productv1.ListProductsRequest) []string {
+		stream, err := client.ListProducts(ctx, req)
+		require.NoError(t, err)
+
+		var ids []string
+		for {
+			msg, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			ids = append(ids, msg.GetProduct().GetId())
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{"prod-1", "prod-2", "prod-3"}, recv(&productv1.ListProductsRequest{}))
+	assert.Equal(t, []string{"prod-2"}, recv(&productv1.ListProductsRequest{CategoryId: "cat-1"}))
+}
+
+func TestProductServer_ErrorMapping(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+	createProduct(t, client, "prod-1")
+
+	tests := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{
+			name: "get missing product",
+			call: func() error {
+				_, err := client.GetProduct(ctx, &productv1.GetProductRequest{Id: "missing"})
+				return err
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "create duplicate product",
+			call: func() error {
+				_, err := client.CreateProduct(ctx, &productv1.CreateProductRequest{
+					Id: "prod-1", Name: "Dup", Price: 1, Currency: "USD",
+				})
+				return err
+			},
+			code: codes.AlreadyExists,
+		},
+		{
+			name: "create with invalid currency",
+			call: func() error {
+				_, err := client.CreateProduct(ctx, &productv1.CreateProductRequest{
+					Id: "prod-2", Name: "Bad", Price: 1, Currency: "US",
+				})
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "get with empty id",
+			call: func() error {
+				_, err := client.GetProduct(ctx, &productv1.GetProductRequest{})
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "delete missing product",
+			call: func() error {
+				_, err := client.DeleteProduct(ctx, &productv1.DeleteProductRequest{Id: "missing"})
+				return err
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "add category to missing product",
+			call: func() error {
+				_, err := client.AddCategory(ctx, &productv1.AddCategoryRequest{
+					ProductId: "missing", CategoryId: "cat-1", CategoryName: "Electronics",
+				})
+				return err
+			},
+			code: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			require.Error(t, err)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
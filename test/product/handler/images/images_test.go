@@ -0,0 +1,240 @@
+package images_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/handler"
+	"sago-sample/feature/product/infrastructure"
+	"sago-sample/feature/product/infrastructure/blob"
+	usecase "sago-sample/feature/product/usecase"
+)
+
+// maxSize is the upload limit of the test router
+const maxSize = 64 << 10
+
+// newRouter wires the image handler to an in-memory repository holding the published product p1 and the draft p2
+func newRouter(t *testing.T) chi.Router {
+	t.Helper()
+
+	ctx := context.Background()
+	repo := infrastructure.NewProductRepository()
+	service := domain.NewService(repo)
+	for _, id := range []domain.ProductID{"p1", "p2"} {
+		_, err := service.CreateProduct(ctx, id, "Laptop", "", domain.MustNewPrice(1000, "USD"), domain.NewStock(1))
+		require.NoError(t, err)
+	}
+	laptops, err := domain.NewCategory("laptops", "Laptops")
+	require.NoError(t, err)
+	_, err = service.AddCategoryToProduct(ctx, "p1", laptops)
+	require.NoError(t, err)
+	_, err = service.ChangeStatus(ctx, "p1", domain.StatusPublished)
+	require.NoError(t, err)
+
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	media := domain.NewMediaService(service, blobs, domain.ImageLimits{MaxSize: maxSize, MaxDimension: 1000, MaxPixels: 1 << 20, ThumbnailSize: 16})
+
+	rtr := chi.NewRouter()
+	handler.NewImageHandler(
+		usecase.NewUploadProductImageUseCase(media),
+		usecase.NewRemoveProductImageUseCase(media),
+		usecase.NewReorderProductImagesUseCase(media),
+		usecase.NewGetProductImageUseCase(repo, media),
+		maxSize,
+	).Register(rtr)
+	return rtr
+}
+
+// encodePNG returns a width×height white PNG
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	img.Set(0, 0, color.Black)
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// upload posts data as the "image" field of a multipart form
+func upload(t *testing.T, rtr chi.Router, productID string, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	// The declared type is ignored: the handler sniffs the content
+	part, err := form.CreateFormFile("image", "photo.png")
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/api/products/"+productID+"/images", &body)
+	r.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, r)
+	return w
+}
+
+func uploadImage(t *testing.T, rtr chi.Router, productID string) handler.ImageResponse {
+	t.Helper()
+
+	w := upload(t, rtr, productID, encodePNG(t, 64, 32))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var response handler.ImageResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	return response
+}
+
+func serve(rtr chi.Router, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, r)
+	return w
+}
+
+func TestUploadImage(t *testing.T) {
+	rtr := newRouter(t)
+
+	w := upload(t, rtr, "p1", encodePNG(t, 64, 32))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var response handler.ImageResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+
+	assert.Equal(t, "/api/products/p1/images/"+response.ID, w.Header().Get("Location"))
+	assert.Equal(t, "/api/products/p1/images/"+response.ID, response.URL)
+	assert.Equal(t, response.URL+"/thumbnail", response.ThumbnailURL)
+	assert.Equal(t, "image/png", response.ContentType)
+	assert.Equal(t, 64, response.Width)
+	assert.Equal(t, 32, response.Height)
+	assert.True(t, response.Primary)
+
+	second := uploadImage(t, rtr, "p1")
+	assert.False(t, second.Primary)
+}
+
+func TestUploadImage_Rejects(t *testing.T) {
+	rtr := newRouter(t)
+
+	tests := []struct {
+		name      string
+		productID string
+		data      []byte
+		want      int
+	}{
+		{name: "not an image", productID: "p1", data: []byte("<html><body>hello</body></html>"), want: http.StatusUnsupportedMediaType},
+		{name: "too large", productID: "p1", data: make([]byte, maxSize+1), want: http.StatusRequestEntityTooLarge},
+		{name: "unknown product", productID: "missing", data: encodePNG(t, 8, 8), want: http.StatusNotFound},
+		{name: "too many pixels", productID: "p1", data: encodePNG(t, 1001, 1), want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := upload(t, rtr, tt.productID, tt.data)
+			assert.Equal(t, tt.want, w.Code, w.Body.String())
+		})
+	}
+
+	t.Run("not multipart", func(t *testing.T) {
+		w := serve(rtr, http.MethodPost, "/api/products/p1/images", "{}", http.Header{"Content-Type": {"application/json"}})
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		require.NoError(t, form.WriteField("caption", "front"))
+		require.NoError(t, form.Close())
+		w := serve(rtr, http.MethodPost, "/api/products/p1/images", body.String(), http.Header{"Content-Type": {form.FormDataContentType()}})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGetImage(t *testing.T) {
+	rtr := newRouter(t)
+	img := uploadImage(t, rtr, "p1")
+
+	w := serve(rtr, http.MethodGet, img.URL, "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Contains(t, w.Header().Get("Cache-Control"), "immutable")
+	assert.EqualValues(t, img.Size, w.Body.Len())
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	w = serve(rtr, http.MethodGet, img.URL, "", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.Bytes())
+
+	w = serve(rtr, http.MethodGet, img.ThumbnailURL, "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	cfg, err := png.DecodeConfig(w.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 16, cfg.Width)
+	assert.Equal(t, 8, cfg.Height)
+
+	w = serve(rtr, http.MethodGet, "/api/products/p1/images/unknown", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetImage_HidesDraftProducts(t *testing.T) {
+	rtr := newRouter(t)
+	img := uploadImage(t, rtr, "p2")
+
+	w := serve(rtr, http.MethodGet, img.URL, "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestReorderImages(t *testing.T) {
+	rtr := newRouter(t)
+	first := uploadImage(t, rtr, "p1")
+	second := uploadImage(t, rtr, "p1")
+
+	w := serve(rtr, http.MethodPut, "/api/products/p1/images", `{"imageIds":["`+second.ID+`","`+first.ID+`"]}`, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response handler.ProductResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Images, 2)
+	assert.Equal(t, second.ID, response.Images[0].ID)
+	assert.True(t, response.Images[0].Primary)
+	assert.False(t, response.Images[1].Primary)
+
+	w = serve(rtr, http.MethodPut, "/api/products/p1/images", `{"imageIds":["`+first.ID+`"]}`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRemoveImage(t *testing.T) {
+	rtr := newRouter(t)
+	img := uploadImage(t, rtr, "p1")
+
+	w := serve(rtr, http.MethodDelete, img.URL, "", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = serve(rtr, http.MethodGet, img.URL, "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(rtr, http.MethodDelete, img.URL, "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
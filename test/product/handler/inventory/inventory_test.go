@@ -0,0 +1,198 @@
+package inventory_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sago-sample/feature/product/handler"
+	"sago-sample/test/apitest"
+)
+
+// newRouter serves the API over in-memory repositories
+func newRouter(t *testing.T) chi.Router {
+	t.Helper()
+
+	return apitest.New(t, apitest.Options{}).Router
+}
+
+// setup creates two located warehouses and a published product with 10 units in the default warehouse
+func setup(t *testing.T) chi.Router {
+	t.Helper()
+
+	rtr := newRouter(t)
+	for _, wh := range []handler.WarehouseRequest{
+		{ID: "paris", Name: "Paris", Latitude: ptr(48.8566), Longitude: ptr(2.3522)},
+		{ID: "berlin", Name: "Berlin", Latitude: ptr(52.52), Longitude: ptr(13.405)},
+	} {
+		w := apitest.Do(t, rtr, http.MethodPost, "/api/warehouses", wh)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	w := apitest.Do(t, rtr, http.MethodPost, "/api/products", handler.CreateProductRequest{
+		ID: "p1", Name: "Laptop", Description: "A laptop", Price: 1000, Currency: "USD", Stock: 10,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/products/p1/categories", handler.AddCategoryToProductRequest{CategoryID: "c1", CategoryName: "Computers"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/admin/products/p1/status", handler.ChangeStatusRequest{Status: "published"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return rtr
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestWarehouses_CRUD(t *testing.T) {
+	rtr := setup(t)
+
+	w := apitest.Do(t, rtr, http.MethodGet, "/api/warehouses", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	warehouses := apitest.Decode[[]handler.WarehouseResponse](t, w)
+	require.Len(t, warehouses, 3)
+	assert.Equal(t, []string{"berlin", "default", "paris"}, []string{warehouses[0].ID, warehouses[1].ID, warehouses[2].ID})
+	assert.Nil(t, warehouses[1].Latitude)
+
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/warehouses", handler.WarehouseRequest{ID: "paris", Name: "Paris"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/warehouses", handler.WarehouseRequest{ID: "x", Name: "X", Latitude: ptr(10)})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "latitude without longitude")
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/warehouses", handler.WarehouseRequest{ID: "x", Name: "X", Latitude: ptr(100), Longitude: ptr(0)})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "latitude out of range")
+
+	w = apitest.Do(t, rtr, http.MethodPut, "/api/warehouses/paris", handler.WarehouseRequest{Name: "Paris Nord"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	updated := apitest.Decode[handler.WarehouseResponse](t, w)
+	assert.Equal(t, "Paris Nord", updated.Name)
+	assert.Nil(t, updated.Latitude)
+
+	assert.Equal(t, http.StatusNoContent, apitest.Do(t, rtr, http.MethodDelete, "/api/warehouses/paris", nil).Code)
+	assert.Equal(t, http.StatusNotFound, apitest.Do(t, rtr, http.MethodGet, "/api/warehouses/paris", nil).Code)
+	assert.Equal(t, http.StatusConflict, apitest.Do(t, rtr, http.MethodDelete, "/api/warehouses/default", nil).Code)
+}
+
+func TestInventory_SetAndTransfer(t *testing.T) {
+	rtr := setup(t)
+
+	w := apitest.Do(t, rtr, http.MethodPut, "/api/products/p1/stock/berlin", handler.SetStockLevelRequest{Quantity: 4})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	inventory := apitest.Decode[handler.InventoryResponse](t, w)
+	assert.Equal(t, uint(14), inventory.Total)
+	assert.Equal(t, []handler.StockLevelResponse{{WarehouseID: "berlin", Quantity: 4}, {WarehouseID: "default", Quantity: 10}}, inventory.Levels)
+
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/products/p1/stock/transfers", handler.TransferStockRequest{From: "default", To: "paris", Quantity: 3})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	inventory = apitest.Decode[handler.InventoryResponse](t, w)
+	assert.Equal(t, uint(14), inventory.Total)
+	assert.Contains(t, inventory.Levels, handler.StockLevelResponse{WarehouseID: "paris", Quantity: 3})
+
+	// The product read returns the total across warehouses
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/products/p1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, uint(14), apitest.Decode[handler.ProductResponse](t, w).Stock)
+
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/products/p1/stock/transfers", handler.TransferStockRequest{From: "paris", To: "berlin", Quantity: 4})
+	assert.Equal(t, http.StatusConflict, w.Code, "insufficient stock in the source warehouse")
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/products/p1/stock/transfers", handler.TransferStockRequest{From: "paris", To: "nowhere", Quantity: 1})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = apitest.Do(t, rtr, http.MethodPut, "/api/products/missing/stock/paris", handler.SetStockLevelRequest{Quantity: 1})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/products/p1/stock", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, inventory, apitest.Decode[handler.InventoryResponse](t, w), "failed transfers change nothing")
+
+	assert.Equal(t, http.StatusConflict, apitest.Do(t, rtr, http.MethodDelete, "/api/warehouses/paris", nil).Code)
+}
+
+func TestInventory_Allocate(t *testing.T) {
+	rtr := setup(t)
+	require.Equal(t, http.StatusOK, apitest.Do(t, rtr, http.MethodPost, "/api/products/p1/stock/transfers", handler.TransferStockRequest{From: "default", To: "paris", Quantity: 3}).Code)
+	require.Equal(t, http.StatusOK, apitest.Do(t, rtr, http.MethodPost, "/api/products/p1/stock/transfers", handler.TransferStockRequest{From: "default", To: "berlin", Quantity: 3}).Code)
+
+	// Lyon is closer to Paris than to Berlin; the default warehouse has no location and comes last
+	w := apitest.Do(t, rtr, http.MethodPost, "/api/products/p1/stock/allocations", handler.AllocateStockRequest{
+		Quantity: 5, Strategy: "nearest", Latitude: ptr(45.764), Longitude: ptr(4.8357),
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	allocation := apitest.Decode[handler.AllocationResponse](t, w)
+	assert.Equal(t, []handler.StockLevelResponse{{WarehouseID: "paris", Quantity: 3}, {WarehouseID: "berlin", Quantity: 2}}, allocation.Allocations)
+	assert.Equal(t, uint(5), allocation.Total)
+
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/products/p1/stock/allocations", handler.AllocateStockRequest{Quantity: 1, Strategy: "priority", Warehouses: []string{"berlin"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []handler.StockLevelResponse{{WarehouseID: "berlin", Quantity: 1}}, apitest.Decode[handler.AllocationResponse](t, w).Allocations)
+
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/products/p1/stock/allocations", handler.AllocateStockRequest{Quantity: 3})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []handler.StockLevelResponse{{WarehouseID: "default", Quantity: 3}}, apitest.Decode[handler.AllocationResponse](t, w).Allocations, "most-stock is the default strategy")
+
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/products/p1/stock/allocations", handler.AllocateStockRequest{Quantity: 2})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/products/p1/stock/allocations", handler.AllocateStockRequest{Quantity: 1, Strategy: "nearest"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "nearest needs a destination")
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/products/p1/stock/allocations", handler.AllocateStockRequest{Quantity: 1, Strategy: "cheapest"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestReorderPolicies_AndSuggestions(t *testing.T) {
+	rtr := setup(t)
+	for _, p := range []handler.CreateProductRequest{
+		{ID: "p2", Name: "Mouse", Description: "A mouse", Price: 20, Currency: "USD", Stock: 0},
+		{ID: "p3", Name: "Cable", Description: "A cable", Price: 5, Currency: "USD", Stock: 50},
+	} {
+		w := apitest.Do(t, rtr, http.MethodPost, "/api/products", p)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	w := apitest.Do(t, rtr, http.MethodGet, "/api/products/p1/reorder-policy", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// p1 has 10 units, p2 none and p3 50
+	for id, policy := range map[string]handler.ReorderPolicyRequest{
+		"p1": {Threshold: 10, Target: 25},
+		"p2": {Threshold: 2, Target: 8},
+		"p3": {Threshold: 10, Target: 100},
+	} {
+		w := apitest.Do(t, rtr, http.MethodPut, "/api/products/"+id+"/reorder-policy", policy)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/products/p1/reorder-policy", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, handler.ReorderPolicyResponse{ProductID: "p1", Threshold: 10, Target: 25}, apitest.Decode[handler.ReorderPolicyResponse](t, w))
+
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/inventory/reorder-suggestions", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []handler.ReorderSuggestionResponse{
+		{ProductID: "p2", ProductName: "Mouse", Status: "out_of_stock", Stock: 0, Threshold: 2, Target: 8, SuggestedQuantity: 8},
+		{ProductID: "p1", ProductName: "Laptop", Status: "low_stock", Stock: 10, Threshold: 10, Target: 25, SuggestedQuantity: 15},
+	}, apitest.Decode[[]handler.ReorderSuggestionResponse](t, w))
+
+	w = apitest.Do(t, rtr, http.MethodDelete, "/api/products/p2/reorder-policy", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/inventory/reorder-suggestions", nil)
+	assert.Len(t, apitest.Decode[[]handler.ReorderSuggestionResponse](t, w), 1)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   handler.ReorderPolicyRequest
+		status int
+	}{
+		{"target not above threshold", "/api/products/p1/reorder-policy", handler.ReorderPolicyRequest{Threshold: 5, Target: 5}, http.StatusBadRequest},
+		{"unknown product", "/api/products/missing/reorder-policy", handler.ReorderPolicyRequest{Threshold: 1, Target: 5}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := apitest.Do(t, rtr, http.MethodPut, tt.path, tt.body)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+	w = apitest.Do(t, rtr, http.MethodDelete, "/api/products/p2/reorder-policy", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
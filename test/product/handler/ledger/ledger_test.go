@@ -0,0 +1,184 @@
+package ledger_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/handler"
+	usecase "sago-sample/feature/product/usecase"
+	"sago-sample/principal"
+	"sago-sample/test/apitest"
+)
+
+type fixture struct {
+	rtr       chi.Router
+	repo      domain.Repository
+	reconcile *usecase.ReconcileStockUseCase
+}
+
+// newFixture serves the API over in-memory repositories
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+
+	api := apitest.New(t, apitest.Options{})
+	return fixture{rtr: api.Router, repo: api.Products, reconcile: api.Reconcile}
+}
+
+// setup creates a second warehouse and a product with 10 units in the default warehouse
+func setup(t *testing.T) fixture {
+	t.Helper()
+
+	f := newFixture(t)
+	w := apitest.Do(t, f.rtr, http.MethodPost, "/api/warehouses", handler.WarehouseRequest{ID: "paris", Name: "Paris"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = apitest.Do(t, f.rtr, http.MethodPost, "/api/products", handler.CreateProductRequest{
+		ID: "p1", Name: "Laptop", Description: "A laptop", Price: 1000, Currency: "USD", Stock: 10,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	return f
+}
+
+func (f fixture) movements(t *testing.T, query string) []handler.StockMovementResponse {
+	t.Helper()
+
+	w := apitest.Do(t, f.rtr, http.MethodGet, "/api/products/p1/stock-movements"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return apitest.Decode[[]handler.StockMovementResponse](t, w)
+}
+
+func TestStockMovements_RecordEveryStockChange(t *testing.T) {
+	f := setup(t)
+
+	steps := []struct {
+		method string
+		path   string
+		body   any
+	}{
+		{http.MethodPost, "/api/products/p1/stock/transfers", handler.TransferStockRequest{From: "default", To: "paris", Quantity: 4}},
+		{http.MethodPost, "/api/products/p1/stock/allocations", handler.AllocateStockRequest{Quantity: 3}},
+		{http.MethodPut, "/api/products/p1/stock/paris", handler.SetStockLevelRequest{Quantity: 6}},
+		{http.MethodPost, "/api/products/p1/stock-movements", handler.RecordStockMovementRequest{Type: "return", Quantity: 2}},
+	}
+	for _, s := range steps {
+		w := apitest.Do(t, f.rtr, s.method, s.path, s.body)
+		require.Less(t, w.Code, 300, w.Body.String())
+	}
+
+	movements := f.movements(t, "")
+	type entry struct {
+		warehouse, movementType string
+		quantity, balance       int
+	}
+	var got []entry
+	for _, m := range movements {
+		got = append(got, entry{m.WarehouseID, m.Type, m.Quantity, m.Balance})
+		assert.NotEmpty(t, m.ID)
+		assert.Equal(t, principal.Anonymous, m.Actor)
+	}
+	assert.Equal(t, []entry{
+		{"default", "receipt", 10, 10},
+		{"default", "transfer", -4, 6},
+		{"paris", "transfer", 4, 10},
+		{"default", "sale", -3, 7},
+		{"paris", "adjustment", 2, 9},
+		{"default", "return", 2, 11},
+	}, got)
+
+	w := apitest.Do(t, f.rtr, http.MethodGet, "/api/products/p1/stock", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, uint(11), apitest.Decode[handler.InventoryResponse](t, w).Total)
+
+	// Filters keep the running balance of the whole ledger
+	paris := f.movements(t, "?warehouse=paris&type=transfer")
+	require.Len(t, paris, 1)
+	assert.Equal(t, 10, paris[0].Balance)
+	assert.Len(t, f.movements(t, "?type=sale"), 1)
+}
+
+func TestStockMovements_RecordMovement(t *testing.T) {
+	f := setup(t)
+
+	tests := []struct {
+		name   string
+		req    handler.RecordStockMovementRequest
+		status int
+	}{
+		{"receipt", handler.RecordStockMovementRequest{Type: "receipt", Quantity: 5, Reason: "PO-1"}, http.StatusCreated},
+		{"sale", handler.RecordStockMovementRequest{Type: "sale", Quantity: 2}, http.StatusCreated},
+		{"reservation in a warehouse", handler.RecordStockMovementRequest{Type: "reservation", WarehouseID: "paris", Quantity: 1}, http.StatusConflict},
+		{"negative adjustment", handler.RecordStockMovementRequest{Type: "adjustment", Quantity: -3, Reason: "count"}, http.StatusCreated},
+		{"adjustment without reason", handler.RecordStockMovementRequest{Type: "adjustment", Quantity: 1}, http.StatusBadRequest},
+		{"negative receipt", handler.RecordStockMovementRequest{Type: "receipt", Quantity: -1}, http.StatusBadRequest},
+		{"transfer", handler.RecordStockMovementRequest{Type: "transfer", Quantity: 1}, http.StatusBadRequest},
+		{"unknown type", handler.RecordStockMovementRequest{Type: "theft", Quantity: 1}, http.StatusBadRequest},
+		{"unknown warehouse", handler.RecordStockMovementRequest{Type: "receipt", WarehouseID: "rome", Quantity: 1}, http.StatusNotFound},
+		{"more than the stock", handler.RecordStockMovementRequest{Type: "sale", Quantity: 100}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := apitest.Do(t, f.rtr, http.MethodPost, "/api/products/p1/stock-movements", tt.req)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+
+	w := apitest.Do(t, f.rtr, http.MethodGet, "/api/products/p1/stock", nil)
+	assert.Equal(t, uint(10), apitest.Decode[handler.InventoryResponse](t, w).Total)
+	movements := f.movements(t, "")
+	assert.Len(t, movements, 4)
+	assert.Equal(t, "PO-1", movements[1].Reason)
+
+	w = apitest.Do(t, f.rtr, http.MethodPost, "/api/products/missing/stock-movements", handler.RecordStockMovementRequest{Type: "receipt", Quantity: 1})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = apitest.Do(t, f.rtr, http.MethodGet, "/api/products/missing/stock-movements", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestStockMovements_ActorIsTheCaller(t *testing.T) {
+	f := setup(t)
+
+	w := apitest.DoWithHeader(t, f.rtr, http.MethodPost, "/api/products/p1/stock-movements",
+		handler.RecordStockMovementRequest{Type: "receipt", Quantity: 1},
+		http.Header{principal.APIKeyHeader: {"secret-key"}},
+	)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	movements := f.movements(t, "?type=receipt")
+	require.Len(t, movements, 2)
+	assert.Equal(t, principal.Anonymous, movements[0].Actor)
+	assert.Equal(t, "apikey:"+principal.Fingerprint("secret-key"), movements[1].Actor)
+}
+
+func TestReconciliation_ReportsDrift(t *testing.T) {
+	f := setup(t)
+
+	w := apitest.Do(t, f.rtr, http.MethodGet, "/api/inventory/reconciliation", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, handler.ReconciliationResponse{Products: 1, Drifts: []handler.StockDriftResponse{}}, apitest.Decode[handler.ReconciliationResponse](t, w))
+
+	// A change bypassing the services is not in the ledger
+	ctx := context.Background()
+	p, err := f.repo.FindByID(ctx, "p1")
+	require.NoError(t, err)
+	p.SetStockLevel("paris", domain.NewStock(3))
+	require.NoError(t, f.repo.Save(ctx, p))
+
+	w = apitest.Do(t, f.rtr, http.MethodGet, "/api/inventory/reconciliation", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, handler.ReconciliationResponse{
+		Products: 1,
+		Drifts: []handler.StockDriftResponse{
+			{ProductID: "p1", WarehouseID: "paris", Ledger: 0, Stock: 3, Difference: 3},
+		},
+	}, apitest.Decode[handler.ReconciliationResponse](t, w))
+
+	output, err := f.reconcile.Execute(ctx)
+	require.NoError(t, err)
+	assert.Len(t, output.Drifts, 1)
+}
@@ -0,0 +1,150 @@
+package lifecycle_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/handler"
+	usecase "sago-sample/feature/product/usecase"
+	"sago-sample/test/apitest"
+)
+
+// fakeClock is a Clock that only moves when told to
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func setAvailability(t *testing.T, rtr chi.Router, id string, from, until *time.Time) {
+	t.Helper()
+
+	w := apitest.Do(t, rtr, http.MethodPut, "/api/admin/products/"+id+"/availability", handler.AvailabilityRequest{AvailableFrom: from, AvailableUntil: until})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestAvailability_PublicReadsFollowTheClock(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	rtr, _ := newClockedRouter(t, clock)
+	createProduct(t, rtr, "always", 5)
+	createProduct(t, rtr, "campaign", 5)
+	from, until := clock.Now().Add(time.Hour), clock.Now().Add(2*time.Hour)
+	setAvailability(t, rtr, "campaign", &from, &until)
+	require.Equal(t, http.StatusOK, changeStatus(t, rtr, "always", "published").Code)
+	require.Equal(t, http.StatusOK, changeStatus(t, rtr, "campaign", "published").Code)
+
+	visible := func() []string {
+		t.Helper()
+		w := apitest.Do(t, rtr, http.MethodGet, "/api/products", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		return ids(apitest.Decode[[]handler.ProductResponse](t, w))
+	}
+
+	assert.Equal(t, []string{"always"}, visible(), "The campaign has not started")
+	assert.Equal(t, http.StatusNotFound, apitest.Do(t, rtr, http.MethodGet, "/api/products/campaign", nil).Code)
+
+	clock.Advance(time.Hour)
+	assert.Equal(t, []string{"always", "campaign"}, visible(), "The window opens at availableFrom")
+	w := apitest.Do(t, rtr, http.MethodGet, "/api/products/campaign", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	got := apitest.Decode[handler.ProductResponse](t, w)
+	require.NotNil(t, got.AvailableFrom)
+	require.NotNil(t, got.AvailableUntil)
+	assert.True(t, from.Equal(*got.AvailableFrom))
+	assert.True(t, until.Equal(*got.AvailableUntil))
+
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/categories/c1/products", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"always", "campaign"}, ids(apitest.Decode[[]handler.ProductResponse](t, w)))
+
+	clock.Advance(time.Hour)
+	assert.Equal(t, []string{"always"}, visible(), "The window closes at availableUntil")
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/categories/c1/products", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"always"}, ids(apitest.Decode[[]handler.ProductResponse](t, w)))
+
+	// The admin reads ignore the window
+	assert.Equal(t, http.StatusOK, apitest.Do(t, rtr, http.MethodGet, "/api/admin/products/campaign", nil).Code)
+
+	// Clearing the window makes the product visible again
+	setAvailability(t, rtr, "campaign", nil, nil)
+	assert.Equal(t, []string{"always", "campaign"}, visible())
+}
+
+func TestAvailability_RejectsEmptyWindows(t *testing.T) {
+	rtr := newRouter(t)
+	createProduct(t, rtr, "p1", 5)
+	from := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	until := from.Add(-time.Minute)
+
+	w := apitest.Do(t, rtr, http.MethodPut, "/api/admin/products/p1/availability", handler.AvailabilityRequest{AvailableFrom: &from, AvailableUntil: &until})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w = apitest.Do(t, rtr, http.MethodPut, "/api/admin/products/missing/availability", handler.AvailabilityRequest{})
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+func TestAvailability_SchedulerEmitsTransitions(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	rtr, service := newClockedRouter(t, clock)
+	scheduler := usecase.NewScheduleAvailabilityUseCase(service)
+
+	var events []domain.Event
+	service.Subscribe(domain.EventHandlerFunc(func(_ context.Context, e domain.Event) {
+		if e.Type == domain.EventProductAvailable || e.Type == domain.EventProductUnavailable {
+			events = append(events, e)
+		}
+	}))
+
+	createProduct(t, rtr, "campaign", 5)
+	from, until := clock.Now().Add(10*time.Minute), clock.Now().Add(70*time.Minute)
+	setAvailability(t, rtr, "campaign", &from, &until)
+	require.Equal(t, http.StatusOK, changeStatus(t, rtr, "campaign", "published").Code)
+
+	ctx := context.Background()
+	ticks := []struct {
+		advance time.Duration
+		want    []string
+	}{
+		{5 * time.Minute, []string{}},
+		{5 * time.Minute, []string{"product.available"}},
+		{30 * time.Minute, []string{}},
+		{time.Hour, []string{"product.unavailable"}},
+		{time.Hour, []string{}},
+	}
+	for i, tick := range ticks {
+		clock.Advance(tick.advance)
+		output, err := scheduler.Execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, clock.Now(), output.Until)
+
+		got := []string{}
+		for _, e := range output.Events {
+			got = append(got, e.Type)
+			assert.Equal(t, "campaign", e.ProductID)
+		}
+		assert.Equal(t, tick.want, got, "tick %d", i)
+	}
+
+	require.Len(t, events, 2, "Each transition is published once")
+	assert.Equal(t, from, events[0].OccurredAt)
+	assert.Equal(t, until, events[1].OccurredAt)
+}
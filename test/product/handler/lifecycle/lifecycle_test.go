@@ -0,0 +1,157 @@
+package lifecycle_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/handler"
+	"sago-sample/test/apitest"
+)
+
+// newRouter serves the API over in-memory repositories
+func newRouter(t *testing.T) chi.Router {
+	t.Helper()
+
+	rtr, _ := newClockedRouter(t, domain.SystemClock)
+	return rtr
+}
+
+// newClockedRouter serves the API over in-memory repositories, telling the time with clock
+func newClockedRouter(t *testing.T, clock domain.Clock) (chi.Router, *domain.Service) {
+	t.Helper()
+
+	api := apitest.New(t, apitest.Options{Clock: clock})
+	return api.Router, api.Service
+}
+
+// createProduct creates a draft product in the category c1
+func createProduct(t *testing.T, rtr chi.Router, id string, stock uint) {
+	t.Helper()
+
+	w := apitest.Do(t, rtr, http.MethodPost, "/api/products", handler.CreateProductRequest{
+		ID: id, Name: "Laptop", Description: "A laptop", Price: 1000, Currency: "USD", Stock: stock,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "draft", apitest.Decode[handler.ProductResponse](t, w).Status)
+
+	w = apitest.Do(t, rtr, http.MethodPost, "/api/products/"+id+"/categories", handler.AddCategoryToProductRequest{CategoryID: "c1", CategoryName: "Computers"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func changeStatus(t *testing.T, rtr chi.Router, id, status string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return apitest.Do(t, rtr, http.MethodPost, "/api/admin/products/"+id+"/status", handler.ChangeStatusRequest{Status: status})
+}
+
+func ids(products []handler.ProductResponse) []string {
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestLifecycle_PublicReadsOnlyReturnPublishedProducts(t *testing.T) {
+	rtr := newRouter(t)
+	createProduct(t, rtr, "p1", 5)
+	createProduct(t, rtr, "p2", 5)
+	createProduct(t, rtr, "p3", 5)
+
+	w := changeStatus(t, rtr, "p1", "published")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "published", apitest.Decode[handler.ProductResponse](t, w).Status)
+	require.Equal(t, http.StatusOK, changeStatus(t, rtr, "p3", "archived").Code)
+
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/products", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"p1"}, ids(apitest.Decode[[]handler.ProductResponse](t, w)))
+	assert.Equal(t, "1", w.Header().Get("X-Total-Count"))
+
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/categories/c1/products", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"p1"}, ids(apitest.Decode[[]handler.ProductResponse](t, w)))
+
+	assert.Equal(t, http.StatusOK, apitest.Do(t, rtr, http.MethodGet, "/api/products/p1", nil).Code)
+	assert.Equal(t, http.StatusNotFound, apitest.Do(t, rtr, http.MethodGet, "/api/products/p2", nil).Code, "drafts are hidden")
+	assert.Equal(t, http.StatusNotFound, apitest.Do(t, rtr, http.MethodGet, "/api/products/p3", nil).Code, "archived products are hidden")
+
+	// Unpublishing hides the product again
+	require.Equal(t, http.StatusOK, changeStatus(t, rtr, "p1", "draft").Code)
+	assert.Equal(t, http.StatusNotFound, apitest.Do(t, rtr, http.MethodGet, "/api/products/p1", nil).Code)
+}
+
+func TestLifecycle_AdminReads(t *testing.T) {
+	rtr := newRouter(t)
+	createProduct(t, rtr, "p1", 5)
+	createProduct(t, rtr, "p2", 5)
+	createProduct(t, rtr, "p3", 5)
+	require.Equal(t, http.StatusOK, changeStatus(t, rtr, "p2", "published").Code)
+
+	w := apitest.Do(t, rtr, http.MethodGet, "/api/admin/products", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"p1", "p2", "p3"}, ids(apitest.Decode[[]handler.ProductResponse](t, w)))
+
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/admin/products?status=draft&limit=1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"p1"}, ids(apitest.Decode[[]handler.ProductResponse](t, w)))
+	assert.Equal(t, "2", w.Header().Get("X-Total-Count"))
+	assert.Equal(t, "</api/admin/products?cursor=p1&limit=1&status=draft>; rel=\"next\"", w.Header().Get("Link"))
+
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/admin/products/p1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "draft", apitest.Decode[handler.ProductResponse](t, w).Status)
+
+	assert.Equal(t, http.StatusNotFound, apitest.Do(t, rtr, http.MethodGet, "/api/admin/products/missing", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, apitest.Do(t, rtr, http.MethodGet, "/api/admin/products?status=deleted", nil).Code)
+}
+
+func TestLifecycle_Transitions(t *testing.T) {
+	rtr := newRouter(t)
+	createProduct(t, rtr, "p1", 5)
+
+	tests := []struct {
+		status string
+		code   int
+	}{
+		{"published", http.StatusOK},
+		{"published", http.StatusConflict},
+		{"archived", http.StatusOK},
+		{"published", http.StatusConflict},
+		{"draft", http.StatusOK},
+		{"published", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := changeStatus(t, rtr, "p1", tt.status)
+		require.Equal(t, tt.code, w.Code, "to %s: %s", tt.status, w.Body.String())
+	}
+
+	assert.Equal(t, http.StatusBadRequest, changeStatus(t, rtr, "p1", "deleted").Code)
+	assert.Equal(t, http.StatusNotFound, changeStatus(t, rtr, "missing", "published").Code)
+}
+
+func TestLifecycle_PublishGuards(t *testing.T) {
+	rtr := newRouter(t)
+
+	// No category
+	w := apitest.Do(t, rtr, http.MethodPost, "/api/products", handler.CreateProductRequest{ID: "p1", Name: "Laptop", Price: 1000, Currency: "USD", Stock: 5})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = changeStatus(t, rtr, "p1", "published")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, apitest.Decode[handler.ErrorResponse](t, w).Error, "no category")
+
+	// No stock
+	createProduct(t, rtr, "p2", 0)
+	w = changeStatus(t, rtr, "p2", "published")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, apitest.Decode[handler.ErrorResponse](t, w).Error, "no stock")
+
+	w = apitest.Do(t, rtr, http.MethodGet, "/api/admin/products/p2", nil)
+	assert.Equal(t, "draft", apitest.Decode[handler.ProductResponse](t, w).Status)
+}
@@ -0,0 +1,182 @@
+package openapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/handler"
+	"sago-sample/feature/product/handler/openapi"
+	"sago-sample/test/apitest"
+)
+
+// newRouter serves the API over in-memory repositories with the routes of the binary
+func newRouter(t *testing.T) chi.Router {
+	t.Helper()
+
+	return apitest.New(t, apitest.Options{}).Router
+}
+
+func TestSpec_CoversEveryRegisteredRoute(t *testing.T) {
+	rtr := newRouter(t)
+	spec := openapi.Spec()
+
+	routes := 0
+	err := chi.Walk(rtr, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes++
+		item, ok := spec.Paths[route]
+		if !assert.True(t, ok, "route %s is missing from the OpenAPI document", route) {
+			return nil
+		}
+		assert.NotNil(t, item.Operation(method), "operation %s %s is missing from the OpenAPI document", method, route)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Greater(t, routes, 0)
+}
+
+func TestSpec_ServedAsJSON(t *testing.T) {
+	rtr := newRouter(t)
+
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.1.0", doc["openapi"])
+
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	for _, name := range []string{"ProductResponse", "CategoryResponse", "ErrorResponse"} {
+		assert.Contains(t, schemas, name)
+	}
+
+	create := schemas["CreateProductRequest"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, float64(domain.MaxProductNameLength), create["name"].(map[string]interface{})["maxLength"])
+	assert.Equal(t, float64(domain.MaxProductDescriptionLength), create["description"].(map[string]interface{})["maxLength"])
+	assert.Equal(t, "^[A-Z]{3}$", create["currency"].(map[string]interface{})["pattern"])
+}
+
+func TestValidator_RejectsInvalidRequests(t *testing.T) {
+	rtr := newRouter(t)
+
+	valid := map[string]interface{}{
+		"id":          "prod-1",
+		"name":        "Test Product",
+		"description": "This is a test product",
+		"price":       1000,
+		"currency":    "USD",
+		"stock":       10,
+	}
+
+	// with returns a copy of the valid body with one field replaced, or removed when value is nil
+	with := func(key string, value interface{}) map[string]interface{} {
+		body := make(map[string]interface{}, len(valid))
+		for k, v := range valid {
+			body[k] = v
+		}
+		if value == nil {
+			delete(body, key)
+		} else {
+			body[key] = value
+		}
+		return body
+	}
+
+	tests := []struct {
+		name    string
+		body    interface{}
+		message string
+	}{
+		{"missing name", with("name", nil), "body.name: is required"},
+		{"name too long", with("name", strings.Repeat("a", 101)), "body.name: must be at most 100 characters"},
+		{"description too long", with("description", strings.Repeat("a", 1001)), "body.description: must be at most 1000 characters"},
+		{"lowercase currency", with("currency", "usd"), "body.currency: must match pattern ^[A-Z]{3}$"},
+		{"zero price", with("price", 0), "body.price: must be at least 1"},
+		{"negative stock", with("stock", -1), "body.stock: must be at least 0"},
+		{"fractional stock", with("stock", 1.5), "body.stock: must be an integer"},
+		{"string price", with("price", "1000"), "body.price: must be an integer"},
+		{"not an object", []int{1}, "body: must be an object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/api/products", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			rtr.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			var resp handler.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.message, resp.Error)
+		})
+	}
+
+	// A name of 40 Japanese characters is within the limit even though it exceeds 100 bytes
+	body, _ := json.Marshal(with("name", strings.Repeat("商", 40)))
+	req := httptest.NewRequest(http.MethodPost, "/api/products", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, req)
+	assert.NotContains(t, w.Body.String(), "body.name")
+
+	// Lengths are counted on the NFC form, so 60 decomposed é (120 code points) fit the limit as the domain stores them
+	body, _ = json.Marshal(with("name", strings.Repeat("e\u0301", 60)))
+	req = httptest.NewRequest(http.MethodPost, "/api/products", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+	rtr.ServeHTTP(w, req)
+	assert.NotContains(t, w.Body.String(), "body.name")
+
+	// and once the whitespace is collapsed, so a name of 100 characters padded with spaces fits the limit too
+	body, _ = json.Marshal(with("name", "  "+strings.Repeat("a", 50)+"    "+strings.Repeat("a", 49)+"  "))
+	req = httptest.NewRequest(http.MethodPost, "/api/products", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+	rtr.ServeHTTP(w, req)
+	assert.NotContains(t, w.Body.String(), "body.name")
+}
+
+func TestValidator_PassesValidRequests(t *testing.T) {
+	rtr := newRouter(t)
+
+	body := `{"id":"prod-1","name":"Test Product","price":1000,"currency":"USD","stock":10}`
+	req := httptest.NewRequest(http.MethodPost, "/api/products", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created handler.ProductResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "prod-1", created.ID)
+	assert.Equal(t, []handler.CategoryResponse{}, created.Categories)
+	assert.Equal(t, "draft", created.Status)
+
+	// Drafts are only visible to the admin endpoints
+	w = httptest.NewRecorder()
+	rtr.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/products/prod-1", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = httptest.NewRecorder()
+	rtr.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/products/prod-1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	rtr.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/products/missing", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Unknown paths are left to the router
+	w = httptest.NewRecorder()
+	rtr.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
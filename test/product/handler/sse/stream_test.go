@@ -0,0 +1,369 @@
+package sse_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/handler/sse"
+	"sago-sample/feature/product/infrastructure"
+	usecase "sago-sample/feature/product/usecase"
+)
+
+// event is one message read from a stream; comments are reported with an empty ID and event
+type event struct {
+	id      string
+	event   string
+	data    string
+	comment string
+}
+
+type fixture struct {
+	server  *httptest.Server
+	broker  *sse.Broker
+	service *domain.Service
+	create  *usecase.CreateProductUseCase
+	update  *usecase.UpdateProductUseCase
+	addCat  *usecase.AddCategoryToProductUseCase
+}
+
+func newFixture(t *testing.T, replaySize int, heartbeat time.Duration) *fixture {
+	t.Helper()
+	return newFixtureWithWriteTimeout(t, replaySize, heartbeat, 0)
+}
+
+// newFixtureWithWriteTimeout serves the streams from a server with the given http.Server.WriteTimeout
+func newFixtureWithWriteTimeout(t *testing.T, replaySize int, heartbeat, writeTimeout time.Duration) *fixture {
+	t.Helper()
+
+	repo := infrastructure.NewProductRepository()
+	service := domain.NewService(repo)
+	service.SetPublishGuards()
+	broker := sse.NewBroker(replaySize)
+	service.Subscribe(broker)
+
+	h := sse.NewHandler(broker, usecase.NewGetProductUseCase(repo), heartbeat)
+	rtr := chi.NewRouter()
+	rtr.Get("/api/products/stream", h.HandleStream)
+	rtr.Get("/api/products/{id}/stream", h.HandleProductStream)
+
+	server := httptest.NewUnstartedServer(rtr)
+	server.Config.WriteTimeout = writeTimeout
+	server.Start()
+	t.Cleanup(func() {
+		broker.Close()
+		server.Close()
+	})
+
+	return &fixture{
+		server:  server,
+		broker:  broker,
+		service: service,
+		create:  usecase.NewCreateProductUseCase(service),
+		update:  usecase.NewUpdateProductUseCase(service),
+		addCat:  usecase.NewAddCategoryToProductUseCase(service),
+	}
+}
+
+// createProduct creates a published product: only its publication is streamed
+func (f *fixture) createProduct(t *testing.T, id string) {
+	t.Helper()
+
+	f.createDraft(t, id)
+	f.changeStatus(t, id, domain.StatusPublished)
+}
+
+func (f *fixture) createDraft(t *testing.T, id string) {
+	t.Helper()
+
+	_, err := f.create.Execute(context.Background(), usecase.CreateProductInput{
+		ID: id, Name: "Laptop", Description: "A laptop", Price: 1000, Currency: "USD", Stock: 5,
+	})
+	require.NoError(t, err)
+}
+
+func (f *fixture) changeStatus(t *testing.T, id string, status domain.ProductStatus) {
+	t.Helper()
+
+	_, err := f.service.ChangeStatus(context.Background(), domain.ProductID(id), status)
+	require.NoError(t, err)
+}
+
+func (f *fixture) addCategory(t *testing.T, productID, categoryID string) {
+	t.Helper()
+
+	_, err := f.addCat.Execute(context.Background(), usecase.AddCategoryToProductInput{
+		ProductID: productID, CategoryID: categoryID, CategoryName: "Category " + categoryID,
+	})
+	require.NoError(t, err)
+}
+
+// connect opens a stream and returns a channel of its events; the stream is closed by cancel
+func (f *fixture) connect(t *testing.T, path, lastEventID string) (<-chan event, context.CancelFunc) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.server.URL+path, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan event, 64)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		var current event
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if current != (event{}) {
+					events <- current
+				}
+				current = event{}
+			case strings.HasPrefix(line, ":"):
+				current.comment = strings.TrimSpace(line[1:])
+			case strings.HasPrefix(line, "id: "):
+				current.id = line[len("id: "):]
+			case strings.HasPrefix(line, "event: "):
+				current.event = line[len("event: "):]
+			case strings.HasPrefix(line, "data: "):
+				current.data = line[len("data: "):]
+			}
+		}
+	}()
+
+	// Wait until the subscription is registered so no event is published before it
+	require.Eventually(t, func() bool { return f.broker.Subscribers() > 0 }, time.Second, time.Millisecond)
+
+	return events, cancel
+}
+
+// next returns the next non-comment event
+func next(t *testing.T, events <-chan event) event {
+	t.Helper()
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case e, ok := <-events:
+			require.True(t, ok, "stream closed")
+			if e.comment != "" || e.event == "" {
+				continue
+			}
+			return e
+		case <-timeout:
+			t.Fatal("timed out waiting for an event")
+		}
+	}
+}
+
+// none checks that no event arrives for a short while
+func none(t *testing.T, events <-chan event) {
+	t.Helper()
+
+	timeout := time.After(50 * time.Millisecond)
+	for {
+		select {
+		case e := <-events:
+			if e.event != "" {
+				t.Fatalf("unexpected event %+v", e)
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func TestStream_PushesProductChanges(t *testing.T) {
+	f := newFixture(t, 16, time.Minute)
+	events, _ := f.connect(t, "/api/products/stream", "")
+
+	f.createProduct(t, "p1")
+	_, err := f.update.Execute(context.Background(), usecase.UpdateProductInput{
+		ID: "p1", Name: "Laptop", Description: "A laptop", Price: 1000, Currency: "USD", Stock: 2,
+	})
+	require.NoError(t, err)
+
+	published := next(t, events)
+	assert.Equal(t, "1", published.id)
+	assert.Equal(t, "product.status_changed", published.event)
+
+	var data sse.EventData
+	require.NoError(t, json.Unmarshal([]byte(published.data), &data))
+	assert.Equal(t, "p1", data.ProductID)
+	require.NotNil(t, data.Product)
+	assert.Equal(t, uint(5), data.Product.Stock)
+
+	assert.Equal(t, "product.updated", next(t, events).event)
+
+	stock := next(t, events)
+	assert.Equal(t, "3", stock.id)
+	assert.Equal(t, "product.stock_changed", stock.event)
+	require.NoError(t, json.Unmarshal([]byte(stock.data), &data))
+	require.NotNil(t, data.PreviousStock)
+	assert.Equal(t, uint(5), *data.PreviousStock)
+	assert.Equal(t, uint(2), data.Product.Stock)
+}
+
+func TestStream_FiltersByCategory(t *testing.T) {
+	f := newFixture(t, 16, time.Minute)
+	f.createProduct(t, "p1")
+	f.createProduct(t, "p2")
+
+	events, _ := f.connect(t, "/api/products/stream?category=c1", "")
+
+	f.addCategory(t, "p2", "c2")
+	none(t, events)
+
+	f.addCategory(t, "p1", "c1")
+	e := next(t, events)
+	assert.Equal(t, "product.category_added", e.event)
+	assert.Contains(t, e.data, `"productId":"p1"`)
+}
+
+func TestStream_ProductStream(t *testing.T) {
+	f := newFixture(t, 16, time.Minute)
+	f.createProduct(t, "p1")
+	f.createProduct(t, "p2")
+
+	events, _ := f.connect(t, "/api/products/p1/stream", "")
+
+	f.addCategory(t, "p2", "c1")
+	f.addCategory(t, "p1", "c1")
+
+	e := next(t, events)
+	assert.Contains(t, e.data, `"productId":"p1"`)
+	none(t, events)
+
+	f.createDraft(t, "draft")
+	for _, id := range []string{"missing", "draft"} {
+		resp, err := http.Get(f.server.URL + "/api/products/" + id + "/stream")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, id)
+	}
+}
+
+func TestStream_OnlyStreamsPublishedProducts(t *testing.T) {
+	f := newFixture(t, 16, time.Minute)
+	f.createProduct(t, "p1")
+	events, _ := f.connect(t, "/api/products/stream", "")
+
+	f.createDraft(t, "draft")
+	f.addCategory(t, "draft", "c1")
+	none(t, events)
+
+	// Withdrawing a product is streamed, so that clients drop it; its later changes are not
+	f.changeStatus(t, "p1", domain.StatusArchived)
+	e := next(t, events)
+	assert.Equal(t, "product.status_changed", e.event)
+	assert.Contains(t, e.data, `"previousStatus":"published"`)
+	f.addCategory(t, "p1", "c1")
+	none(t, events)
+}
+
+func TestStream_ResumesFromLastEventID(t *testing.T) {
+	f := newFixture(t, 16, time.Minute)
+	f.createProduct(t, "p1")
+	f.createProduct(t, "p2")
+	f.createProduct(t, "p3")
+
+	events, _ := f.connect(t, "/api/products/stream", "1")
+
+	assert.Equal(t, "2", next(t, events).id)
+	assert.Equal(t, "3", next(t, events).id)
+
+	f.createProduct(t, "p4")
+	assert.Equal(t, "4", next(t, events).id)
+}
+
+func TestStream_ReplayBufferIsBounded(t *testing.T) {
+	f := newFixture(t, 2, time.Minute)
+	for _, id := range []string{"p1", "p2", "p3", "p4"} {
+		f.createProduct(t, id)
+	}
+
+	events, _ := f.connect(t, "/api/products/stream", "1")
+
+	assert.Equal(t, "3", next(t, events).id)
+	assert.Equal(t, "4", next(t, events).id)
+	none(t, events)
+}
+
+func TestStream_Heartbeat(t *testing.T) {
+	f := newFixture(t, 16, 10*time.Millisecond)
+	events, _ := f.connect(t, "/api/products/stream", "")
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case e := <-events:
+			if e.comment == "heartbeat" {
+				return
+			}
+		case <-timeout:
+			t.Fatal("no heartbeat received")
+		}
+	}
+}
+
+func TestStream_UnsubscribesOnDisconnect(t *testing.T) {
+	f := newFixture(t, 16, time.Minute)
+	_, cancel := f.connect(t, "/api/products/stream", "")
+	require.Equal(t, 1, f.broker.Subscribers())
+
+	cancel()
+
+	require.Eventually(t, func() bool { return f.broker.Subscribers() == 0 }, time.Second, time.Millisecond)
+}
+
+func TestStream_OutlivesWriteTimeout(t *testing.T) {
+	f := newFixtureWithWriteTimeout(t, 16, time.Minute, 50*time.Millisecond)
+	events, _ := f.connect(t, "/api/products/stream", "")
+
+	time.Sleep(150 * time.Millisecond)
+	f.createProduct(t, "p1")
+
+	e := next(t, events)
+	assert.Equal(t, "product.status_changed", e.event)
+}
+
+func TestStream_EndsWhenBrokerCloses(t *testing.T) {
+	f := newFixture(t, 16, time.Minute)
+	events, _ := f.connect(t, "/api/products/stream", "")
+
+	f.broker.Close()
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("stream still open after the broker was closed")
+		}
+	}
+}
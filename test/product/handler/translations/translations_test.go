@@ -0,0 +1,199 @@
+package translations_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/handler"
+	"sago-sample/test/apitest"
+)
+
+// newRouter serves the API over in-memory repositories holding the published product p1 of the laptops category.
+// The catalog is in English and translated to French and Japanese.
+func newRouter(t *testing.T) chi.Router {
+	t.Helper()
+
+	locales, err := domain.NewLocales("en", []string{"fr", "ja"})
+	require.NoError(t, err)
+	api := apitest.New(t, apitest.Options{Locales: locales})
+
+	ctx := context.Background()
+	_, err = api.Service.CreateProduct(ctx, "p1", "Laptop", "A light laptop", domain.MustNewPrice(1000, "USD"), domain.NewStock(1))
+	require.NoError(t, err)
+	laptops, err := domain.NewCategory("laptops", "Laptops")
+	require.NoError(t, err)
+	_, err = api.Service.AddCategoryToProduct(ctx, "p1", laptops)
+	require.NoError(t, err)
+	_, err = api.Service.ChangeStatus(ctx, "p1", domain.StatusPublished)
+	require.NoError(t, err)
+	return api.Router
+}
+
+// translate sets the French and Japanese texts of p1 and the French name of the laptops category
+func translate(t *testing.T, rtr chi.Router) {
+	t.Helper()
+
+	rec := apitest.Do(t, rtr, http.MethodPut, "/api/products/p1/translations/fr", handler.ProductTranslationRequest{Name: "Ordinateur portable", Description: "Un ordinateur léger"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = apitest.Do(t, rtr, http.MethodPut, "/api/products/p1/translations/ja", handler.ProductTranslationRequest{Name: "ノートパソコン"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = apitest.Do(t, rtr, http.MethodPut, "/api/categories/laptops/translations/fr", handler.CategoryTranslationRequest{Name: "Portables"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestGetProduct_NegotiatesLocale(t *testing.T) {
+	rtr := newRouter(t)
+	translate(t, rtr)
+
+	tests := []struct {
+		name            string
+		acceptLanguage  string
+		contentLanguage string
+		productName     string
+		description     string
+		categoryName    string
+	}{
+		{"no header", "", "en", "Laptop", "A light laptop", "Laptops"},
+		{"exact", "fr", "fr", "Ordinateur portable", "Un ordinateur léger", "Portables"},
+		{"region", "fr-CA, en;q=0.5", "fr", "Ordinateur portable", "Un ordinateur léger", "Portables"},
+		{"quality order", "de, en;q=0.2, ja;q=0.8", "ja", "ノートパソコン", "A light laptop", "Laptops"},
+		{"unsupported", "de, *", "en", "Laptop", "A light laptop", "Laptops"},
+		{"refused locale", "fr;q=0, ja", "ja", "ノートパソコン", "A light laptop", "Laptops"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products/p1", nil, http.Header{"Accept-Language": {tt.acceptLanguage}})
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			assert.Equal(t, tt.contentLanguage, rec.Header().Get("Content-Language"))
+			assert.Contains(t, rec.Header().Values("Vary"), "Accept-Language")
+
+			p := apitest.Decode[handler.ProductResponse](t, rec)
+			assert.Equal(t, tt.productName, p.Name)
+			assert.Equal(t, tt.description, p.Description)
+			require.Len(t, p.Categories, 1)
+			assert.Equal(t, tt.categoryName, p.Categories[0].Name)
+		})
+	}
+}
+
+func TestListReads_AreLocalized(t *testing.T) {
+	rtr := newRouter(t)
+	translate(t, rtr)
+
+	for _, path := range []string{"/api/products", "/api/categories/laptops/products"} {
+		t.Run(path, func(t *testing.T) {
+			rec := apitest.DoWithHeader(t, rtr, http.MethodGet, path, nil, http.Header{"Accept-Language": {"fr"}})
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			assert.Equal(t, "fr", rec.Header().Get("Content-Language"))
+
+			products := apitest.Decode[[]handler.ProductResponse](t, rec)
+			require.Len(t, products, 1)
+			assert.Equal(t, "Ordinateur portable", products[0].Name)
+			assert.Equal(t, "Portables", products[0].Categories[0].Name)
+		})
+	}
+}
+
+func TestETag_DependsOnLocaleAndCategoryNames(t *testing.T) {
+	rtr := newRouter(t)
+	translate(t, rtr)
+
+	etag := func(acceptLanguage string) string {
+		rec := apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products/p1", nil, http.Header{"Accept-Language": {acceptLanguage}})
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Header().Get("ETag")
+	}
+	english, french := etag("en"), etag("fr")
+	assert.NotEqual(t, english, french)
+
+	rec := apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products/p1", nil, http.Header{"Accept-Language": {"fr"}, "If-None-Match": {french}})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	// Renaming the category in French changes the French representation only
+	rec = apitest.Do(t, rtr, http.MethodPut, "/api/categories/laptops/translations/fr", handler.CategoryTranslationRequest{Name: "Ordinateurs portables"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotEqual(t, french, etag("fr"))
+	assert.Equal(t, english, etag("en"))
+}
+
+func TestProductTranslations(t *testing.T) {
+	rtr := newRouter(t)
+	translate(t, rtr)
+
+	rec := apitest.Do(t, rtr, http.MethodGet, "/api/products/p1/translations", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	got := apitest.Decode[handler.ProductTranslationsResponse](t, rec)
+	assert.Equal(t, "p1", got.ProductID)
+	assert.Equal(t, "en", got.DefaultLocale)
+	assert.Equal(t, map[string]handler.ProductTranslationRequest{
+		"fr": {Name: "Ordinateur portable", Description: "Un ordinateur léger"},
+		"ja": {Name: "ノートパソコン"},
+	}, got.Translations)
+
+	rec = apitest.Do(t, rtr, http.MethodDelete, "/api/products/p1/translations/ja", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = apitest.Do(t, rtr, http.MethodDelete, "/api/products/p1/translations/ja", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = apitest.DoWithHeader(t, rtr, http.MethodGet, "/api/products/p1", nil, http.Header{"Accept-Language": {"ja"}})
+	assert.Equal(t, "Laptop", apitest.Decode[handler.ProductResponse](t, rec).Name)
+}
+
+func TestSetProductTranslation_Validation(t *testing.T) {
+	rtr := newRouter(t)
+
+	long := make([]rune, domain.MaxProductNameLength+1)
+	for i := range long {
+		long[i] = 'ü'
+	}
+	tests := []struct {
+		name   string
+		path   string
+		body   any
+		status int
+	}{
+		{"multibyte name within the limit", "/api/products/p1/translations/ja", handler.ProductTranslationRequest{Name: string(long[1:])}, http.StatusOK},
+		{"name over the limit", "/api/products/p1/translations/ja", handler.ProductTranslationRequest{Name: string(long)}, http.StatusBadRequest},
+		{"missing name", "/api/products/p1/translations/ja", handler.ProductTranslationRequest{Description: "説明"}, http.StatusBadRequest},
+		{"default locale", "/api/products/p1/translations/en", handler.ProductTranslationRequest{Name: "Laptop"}, http.StatusBadRequest},
+		{"unsupported locale", "/api/products/p1/translations/de", handler.ProductTranslationRequest{Name: "Laptop"}, http.StatusBadRequest},
+		{"invalid locale", "/api/products/p1/translations/english", handler.ProductTranslationRequest{Name: "Laptop"}, http.StatusBadRequest},
+		{"unknown product", "/api/products/missing/translations/fr", handler.ProductTranslationRequest{Name: "Ordinateur"}, http.StatusNotFound},
+		{"invalid payload", "/api/products/p1/translations/fr", "{", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apitest.Do(t, rtr, http.MethodPut, tt.path, tt.body)
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestCategoryTranslations(t *testing.T) {
+	rtr := newRouter(t)
+
+	rec := apitest.Do(t, rtr, http.MethodGet, "/api/categories/laptops/translations", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, apitest.Decode[handler.CategoryTranslationsResponse](t, rec).Names)
+
+	rec = apitest.Do(t, rtr, http.MethodPut, "/api/categories/laptops/translations/ja", handler.CategoryTranslationRequest{Name: "ノートパソコン"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	got := apitest.Decode[handler.CategoryTranslationsResponse](t, rec)
+	assert.Equal(t, handler.CategoryTranslationsResponse{CategoryID: "laptops", DefaultLocale: "en", Names: map[string]string{"ja": "ノートパソコン"}}, got)
+
+	rec = apitest.Do(t, rtr, http.MethodPut, "/api/categories/laptops/translations/en", handler.CategoryTranslationRequest{Name: "Laptops"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = apitest.Do(t, rtr, http.MethodPut, "/api/categories/laptops/translations/fr", handler.CategoryTranslationRequest{})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = apitest.Do(t, rtr, http.MethodDelete, "/api/categories/laptops/translations/ja", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = apitest.Do(t, rtr, http.MethodDelete, "/api/categories/laptops/translations/ja", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
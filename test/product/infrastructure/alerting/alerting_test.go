@@ -0,0 +1,237 @@
+package alerting_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	product "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/infrastructure"
+	"sago-sample/feature/product/infrastructure/alerting"
+)
+
+// recorder is a product.Notifier recording the alerts it receives; the first failures calls fail
+type recorder struct {
+	mutex    sync.Mutex
+	alerts   []product.Alert
+	failures int
+}
+
+func (r *recorder) Notify(_ context.Context, alert product.Alert) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("unreachable")
+	}
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func (r *recorder) types() []product.AlertType {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	types := make([]product.AlertType, 0, len(r.alerts))
+	for _, a := range r.alerts {
+		types = append(types, a.Type)
+	}
+	return types
+}
+
+// clock returns the evaluation times in order, one per stock change, as minutes after a fixed instant;
+// the last time is repeated once they run out
+type clock struct {
+	mutex   sync.Mutex
+	minutes []int
+}
+
+func (c *clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	minute := 0
+	if len(c.minutes) > 0 {
+		minute = c.minutes[0]
+		if len(c.minutes) > 1 {
+			c.minutes = c.minutes[1:]
+		}
+	}
+	return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(minute) * time.Minute)
+}
+
+type fixture struct {
+	service   *product.Service
+	evaluator *alerting.Evaluator
+	notifier  *recorder
+	clock     *clock
+}
+
+// newFixture creates a product with 10 units, a threshold of 5 and a target of 20, watched by an evaluator with a 1 hour cooldown
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+
+	repo := infrastructure.NewProductRepository()
+	policies := infrastructure.NewReorderPolicyRepository()
+	f := fixture{
+		service:  product.NewService(repo),
+		notifier: &recorder{},
+		clock:    &clock{},
+	}
+	f.evaluator = alerting.NewEvaluator(policies, f.notifier, alerting.Config{Cooldown: time.Hour, Now: f.clock.Now})
+	f.service.Subscribe(f.evaluator)
+	f.evaluator.Start()
+	t.Cleanup(func() { _ = f.evaluator.Stop(context.Background()) })
+
+	_, err := f.service.CreateProduct(context.Background(),
+		product.MustNewProductID("prod-1"),
+		product.MustNewProductName("Laptop"),
+		product.MustNewProductDescription("A laptop"),
+		product.MustNewPrice(1000, "USD"),
+		product.NewStock(10),
+	)
+	require.NoError(t, err)
+	policy, err := product.NewReorderPolicy("prod-1", 5, 20)
+	require.NoError(t, err)
+	require.NoError(t, policies.Save(context.Background(), policy))
+	return f
+}
+
+// setStock updates the stock of the product
+func (f fixture) setStock(t *testing.T, quantity uint) {
+	t.Helper()
+
+	_, err := f.service.UpdateProduct(context.Background(),
+		product.MustNewProductID("prod-1"),
+		product.MustNewProductName("Laptop"),
+		product.MustNewProductDescription("A laptop"),
+		product.MustNewPrice(1000, "USD"),
+		product.NewStock(quantity),
+	)
+	require.NoError(t, err)
+}
+
+// stop drains the evaluator so that every change has been evaluated
+func (f fixture) stop(t *testing.T) {
+	t.Helper()
+	require.NoError(t, f.evaluator.Stop(context.Background()))
+}
+
+func TestEvaluator_AlertsWhenCrossingThresholds(t *testing.T) {
+	f := newFixture(t)
+
+	f.setStock(t, 8) // still above the threshold
+	f.setStock(t, 5) // low
+	f.setStock(t, 3) // still low
+	f.setStock(t, 0) // out
+	f.stop(t)
+
+	assert.Equal(t, []product.AlertType{product.AlertLowStock, product.AlertOutOfStock}, f.notifier.types())
+	alert := f.notifier.alerts[0]
+	assert.Equal(t, product.ProductID("prod-1"), alert.ProductID)
+	assert.Equal(t, uint(5), alert.Stock)
+	assert.Equal(t, uint(15), alert.SuggestedQuantity)
+}
+
+func TestEvaluator_SuppressesDuplicatesWithinCooldown(t *testing.T) {
+	f := newFixture(t)
+	f.clock.minutes = []int{0, 10, 20, 30, 70}
+
+	// Flapping around the threshold only alerts once per cooldown
+	f.setStock(t, 5)
+	f.setStock(t, 6)
+	f.setStock(t, 5)
+	f.setStock(t, 6)
+	f.setStock(t, 4) // 70 minutes after the first alert
+	f.stop(t)
+
+	assert.Equal(t, []product.AlertType{product.AlertLowStock, product.AlertLowStock}, f.notifier.types())
+}
+
+func TestEvaluator_FailedAlertsAreNotDeduplicated(t *testing.T) {
+	f := newFixture(t)
+	f.notifier.failures = 1
+
+	f.setStock(t, 5) // fails
+	f.setStock(t, 6)
+	f.setStock(t, 5)
+	f.stop(t)
+
+	assert.Equal(t, []product.AlertType{product.AlertLowStock}, f.notifier.types())
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	received := make(chan map[string]any, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		received <- body
+		if body["productId"] == "broken" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	notifier := alerting.NewWebhookNotifier(server.URL, nil)
+	alert := product.Alert{Type: product.AlertOutOfStock, ProductID: "prod-1", ProductName: "Laptop", Threshold: 5, Target: 20, SuggestedQuantity: 20}
+	require.NoError(t, notifier.Notify(context.Background(), alert))
+
+	body := <-received
+	assert.Equal(t, "out_of_stock", body["type"])
+	assert.Equal(t, "prod-1", body["productId"])
+	assert.Equal(t, float64(20), body["suggestedQuantity"])
+
+	alert.ProductID = "broken"
+	assert.Error(t, notifier.Notify(context.Background(), alert))
+	<-received
+}
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.jsonl")
+	notifier, closeNotifier, err := alerting.NewNotifier(alerting.NotifierFile, "", path)
+	require.NoError(t, err)
+
+	for _, alertType := range []product.AlertType{product.AlertLowStock, product.AlertOutOfStock} {
+		require.NoError(t, notifier.Notify(context.Background(), product.Alert{Type: alertType, ProductID: "prod-1"}))
+	}
+	require.NoError(t, closeNotifier())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var types []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line struct{ Type string }
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		types = append(types, line.Type)
+	}
+	assert.Equal(t, []string{"low_stock", "out_of_stock"}, types)
+}
+
+func TestNewNotifier(t *testing.T) {
+	for _, kind := range []string{alerting.NotifierNone, alerting.NotifierLog} {
+		n, closeNotifier, err := alerting.NewNotifier(kind, "", "")
+		require.NoError(t, err)
+		assert.NoError(t, n.Notify(context.Background(), product.Alert{}))
+		assert.NoError(t, closeNotifier())
+	}
+
+	_, _, err := alerting.NewNotifier(alerting.NotifierWebhook, "", "")
+	assert.Error(t, err)
+	_, _, err = alerting.NewNotifier("sms", "", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,252 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "sago-sample/feature/product/domain"
+	"sago-sample/feature/product/infrastructure"
+	"sago-sample/feature/product/infrastructure/cache"
+)
+
+// countingRepository counts the FindByID calls reaching the underlying repository.
+// When gate is set, FindByID blocks until it is closed.
+type countingRepository struct {
+	domain.Repository
+	finds atomic.Int32
+	gate  chan struct{}
+}
+
+func (r *countingRepository) FindByID(ctx context.Context, id domain.ProductID) (*domain.Product, error) {
+	r.finds.Add(1)
+	if r.gate != nil {
+		<-r.gate
+	}
+	return r.Repository.FindByID(ctx, id)
+}
+
+func newProduct(t *testing.T, id string, price uint) *domain.Product {
+	t.Helper()
+
+	productID, _ := domain.NewProductID(id)
+	name, _ := domain.NewProductName("Laptop")
+	description, _ := domain.NewProductDescription("A laptop")
+	p, _ := domain.NewPrice(price, "USD")
+	product, err := domain.NewProduct(productID, name, description, p, domain.NewStock(5))
+	require.NoError(t, err)
+
+	categoryID, _ := domain.NewCategoryID("c1")
+	categoryName, _ := domain.NewCategoryName("Computers")
+	category, err := domain.NewCategory(categoryID, categoryName)
+	require.NoError(t, err)
+	product.AddCategory(category)
+
+	return product
+}
+
+// backends runs a test against the in-process LRU and a Redis-compatible fake
+func backends(t *testing.T, run func(t *testing.T, c cache.Cache, expire func(time.Duration))) {
+	t.Run("lru", func(t *testing.T) {
+		run(t, cache.NewLRUCache(100), time.Sleep)
+	})
+	t.Run("redis", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { client.Close() })
+		run(t, cache.NewRedisCache(client, "test:"), mr.FastForward)
+	})
+}
+
+func TestRepository_CachesFindByID(t *testing.T) {
+	backends(t, func(t *testing.T, c cache.Cache, _ func(time.Duration)) {
+		ctx := context.Background()
+		next := &countingRepository{Repository: infrastructure.NewProductRepository()}
+		repo := cache.NewRepository(next, c, cache.Options{})
+
+		product := newProduct(t, "p1", 1000)
+		product.SetStockLevel("east", domain.NewStock(2))
+		require.NoError(t, product.TransitionTo(domain.StatusPublished, domain.DefaultPublishGuards))
+		window, err := domain.NewAvailability(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), time.Time{})
+		require.NoError(t, err)
+		product.SetAvailability(window)
+		attributes, err := domain.NewAttributes(map[string]any{"color": "red", "ram_gb": 16, "refurbished": true})
+		require.NoError(t, err)
+		product.SetAttributes(attributes)
+		image := domain.RestoreImage("img1", "images/img1.png", "images/img1_thumb.png", "image/png", 2048, 640, 480, product.CreatedAt())
+		require.NoError(t, product.AddImage(image))
+		translation, err := domain.NewProductTranslation("ノートパソコン", "")
+		require.NoError(t, err)
+		product.SetTranslation("ja", translation)
+		require.NoError(t, repo.Save(ctx, product))
+
+		for i := 0; i < 3; i++ {
+			found, err := repo.FindByID(ctx, product.ID())
+			require.NoError(t, err)
+			assert.Equal(t, product.Name(), found.Name())
+			assert.Equal(t, product.Price(), found.Price())
+			assert.Equal(t, product.Stock(), found.Stock())
+			assert.Equal(t, product.StockLevels(), found.StockLevels())
+			assert.Equal(t, domain.StatusPublished, found.Status())
+			assert.True(t, window.From().Equal(found.Availability().From()))
+			assert.True(t, found.Availability().Until().IsZero(), "An open bound stays open")
+			assert.Equal(t, attributes, found.Attributes())
+			require.Len(t, found.Images(), 1)
+			assert.Equal(t, image.ThumbnailKey(), found.Images()[0].ThumbnailKey())
+			assert.Equal(t, image.Size(), found.Images()[0].Size())
+			assert.Equal(t, image.Width(), found.Images()[0].Width())
+			assert.Equal(t, map[domain.Locale]domain.ProductTranslation{"ja": translation}, found.Translations())
+			require.Len(t, found.Categories(), 1)
+			assert.Equal(t, "Computers", found.Categories()[0].Name().String())
+			assert.True(t, product.CreatedAt().Equal(found.CreatedAt()))
+		}
+
+		assert.Equal(t, int32(1), next.finds.Load())
+		assert.Equal(t, cache.Stats{Hits: 2, Misses: 1}, repo.Stats())
+	})
+}
+
+func TestRepository_CachesTextsSavedBeforeTheyWereNormalized(t *testing.T) {
+	backends(t, func(t *testing.T, c cache.Cache, _ func(time.Duration)) {
+		ctx := context.Background()
+		repo := cache.NewRepository(infrastructure.NewProductRepository(), c, cache.Options{})
+
+		// A product restored from a row saved before the texts were normalized
+		category, err := domain.NewCategory("c1", "Old\u0007 category")
+		require.NoError(t, err)
+		legacy := domain.RestoreProduct("p1", "Cafe\u0301  Laptop\u202e", "A laptop\r\n\r\n\r\nwith\ta bag",
+			domain.MustNewPrice(1000, "USD"), domain.StatusPublished, domain.Availability{}, nil, nil, nil,
+			[]domain.StockLevel{domain.NewStockLevel(domain.DefaultWarehouseID, 5)}, []*domain.Category{category},
+			time.Now(), time.Now())
+		require.NoError(t, repo.Save(ctx, legacy))
+
+		for i := 0; i < 2; i++ {
+			found, err := repo.FindByID(ctx, "p1")
+			require.NoError(t, err)
+			assert.Equal(t, legacy.Name(), found.Name())
+			assert.Equal(t, legacy.Description(), found.Description())
+			require.Len(t, found.Categories(), 1)
+			assert.Equal(t, category.Name(), found.Categories()[0].Name())
+		}
+		assert.Equal(t, cache.Stats{Hits: 1, Misses: 1}, repo.Stats())
+	})
+}
+
+func TestRepository_CachesNotFound(t *testing.T) {
+	backends(t, func(t *testing.T, c cache.Cache, _ func(time.Duration)) {
+		ctx := context.Background()
+		next := &countingRepository{Repository: infrastructure.NewProductRepository()}
+		repo := cache.NewRepository(next, c, cache.Options{})
+		id, _ := domain.NewProductID("missing")
+
+		for i := 0; i < 3; i++ {
+			_, err := repo.FindByID(ctx, id)
+			assert.ErrorIs(t, err, domain.ErrProductNotFound)
+		}
+		assert.Equal(t, int32(1), next.finds.Load())
+		assert.Equal(t, cache.Stats{NegativeHits: 2, Misses: 1}, repo.Stats())
+
+		// Creating the product replaces the negative entry
+		require.NoError(t, repo.Save(ctx, newProduct(t, "missing", 1000)))
+		found, err := repo.FindByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, id, found.ID())
+	})
+}
+
+func TestRepository_InvalidatesOnSaveAndDelete(t *testing.T) {
+	backends(t, func(t *testing.T, c cache.Cache, _ func(time.Duration)) {
+		ctx := context.Background()
+		repo := cache.NewRepository(infrastructure.NewProductRepository(), c, cache.Options{})
+
+		product := newProduct(t, "p1", 1000)
+		require.NoError(t, repo.Save(ctx, product))
+		_, err := repo.FindByID(ctx, product.ID())
+		require.NoError(t, err)
+
+		updated := newProduct(t, "p1", 2000)
+		require.NoError(t, repo.Save(ctx, updated))
+		found, err := repo.FindByID(ctx, product.ID())
+		require.NoError(t, err)
+		assert.Equal(t, uint(2000), found.Price().Amount())
+
+		require.NoError(t, repo.Delete(ctx, product.ID()))
+		_, err = repo.FindByID(ctx, product.ID())
+		assert.ErrorIs(t, err, domain.ErrProductNotFound)
+	})
+}
+
+func TestRepository_ExpiresEntries(t *testing.T) {
+	backends(t, func(t *testing.T, c cache.Cache, expire func(time.Duration)) {
+		ctx := context.Background()
+		next := &countingRepository{Repository: infrastructure.NewProductRepository()}
+		repo := cache.NewRepository(next, c, cache.Options{TTL: 20 * time.Millisecond, NegativeTTL: 20 * time.Millisecond})
+
+		product := newProduct(t, "p1", 1000)
+		require.NoError(t, next.Repository.Save(ctx, product))
+
+		_, err := repo.FindByID(ctx, product.ID())
+		require.NoError(t, err)
+		expire(30 * time.Millisecond)
+		_, err = repo.FindByID(ctx, product.ID())
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(2), next.finds.Load())
+	})
+}
+
+func TestRepository_SharesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	next := &countingRepository{Repository: infrastructure.NewProductRepository(), gate: make(chan struct{})}
+	repo := cache.NewRepository(next, cache.NewLRUCache(100), cache.Options{})
+
+	product := newProduct(t, "p1", 1000)
+	require.NoError(t, next.Repository.Save(ctx, product))
+
+	const callers = 10
+	results := make([]*domain.Product, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p, err := repo.FindByID(ctx, product.ID())
+			assert.NoError(t, err)
+			results[i] = p
+		}(i)
+	}
+
+	require.Eventually(t, func() bool { return repo.Stats().Misses == callers }, time.Second, time.Millisecond)
+	close(next.gate)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), next.finds.Load())
+	for i := 1; i < callers; i++ {
+		assert.NotSame(t, results[0], results[i], "callers must not share a mutable product")
+	}
+}
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewLRUCache(2)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Minute))
+	_, ok, _ := c.Get(ctx, "a")
+	require.True(t, ok)
+	require.NoError(t, c.Set(ctx, "c", []byte("3"), time.Minute))
+
+	_, ok, _ = c.Get(ctx, "b")
+	assert.False(t, ok, "b was the least recently used entry")
+	v, ok, _ := c.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), v)
+	assert.Equal(t, 2, c.Len())
+}
@@ -34,6 +34,14 @@ func (m *MockProductRepository) FindByCategory(ctx context.Context, categoryID d
 	return args.Get(0).([]*domain.Product), args.Error(1)
 }
 
+func (m *MockProductRepository) Facets(ctx context.Context, query domain.FacetQuery) (*domain.Facets, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Facets), args.Error(1)
+}
+
 func (m *MockProductRepository) Save(ctx context.Context, product *domain.Product) error {
 	args := m.Called(ctx, product)
 	return args.Error(0)
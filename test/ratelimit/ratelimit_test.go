@@ -0,0 +1,278 @@
+package ratelimit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sago-sample/principal"
+	"sago-sample/ratelimit"
+)
+
+// clock is a manually advanced time source
+type clock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func newClock() *clock {
+	return &clock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// stores runs a test against the in-memory store and the Redis store backed by a fake server
+func stores(t *testing.T, run func(t *testing.T, store ratelimit.Store, clk *clock)) {
+	t.Run("memory", func(t *testing.T) {
+		clk := newClock()
+		store := ratelimit.NewMemoryStore()
+		store.Clock = clk.Now
+		run(t, store, clk)
+	})
+	t.Run("redis", func(t *testing.T) {
+		srv := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+
+		clk := newClock()
+		store := ratelimit.NewRedisStore(client, "ratelimit:")
+		store.Clock = clk.Now
+		run(t, store, clk)
+	})
+}
+
+func TestStore_TokenBucket(t *testing.T) {
+	stores(t, func(t *testing.T, store ratelimit.Store, clk *clock) {
+		ctx := context.Background()
+		limit := ratelimit.Limit{Requests: 1, Period: time.Second, Burst: 3}
+
+		// A new bucket is full
+		for i := 2; i >= 0; i-- {
+			res, err := store.Take(ctx, "client", limit)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, 3, res.Limit)
+			assert.Equal(t, i, res.Remaining)
+		}
+
+		res, err := store.Take(ctx, "client", limit)
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+		assert.InDelta(t, time.Second, res.RetryAfter, float64(time.Millisecond))
+		assert.InDelta(t, 3*time.Second, res.Reset, float64(time.Millisecond))
+
+		// Other clients have their own bucket
+		res, err = store.Take(ctx, "other", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+
+		// One token is added per second, up to the burst
+		clk.Advance(1500 * time.Millisecond)
+		res, err = store.Take(ctx, "client", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+
+		clk.Advance(time.Hour)
+		res, err = store.Take(ctx, "client", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 2, res.Remaining)
+	})
+}
+
+func TestMemoryStore_SweepsRefilledBuckets(t *testing.T) {
+	clk := newClock()
+	store := ratelimit.NewMemoryStore()
+	store.Clock = clk.Now
+	limit := ratelimit.PerMinute(60)
+
+	for i := 0; i < 100; i++ {
+		_, err := store.Take(context.Background(), fmt.Sprintf("client-%d", i), limit)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 100, store.Len())
+
+	clk.Advance(time.Minute)
+	for i := 0; store.Len() > 1 && i < 10000; i++ {
+		_, err := store.Take(context.Background(), "busy", limit)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, store.Len())
+}
+
+// failingStore always fails
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unavailable")
+}
+
+func newRouter(store ratelimit.Store, cfg ratelimit.Config) chi.Router {
+	rtr := chi.NewRouter()
+	rtr.Use(ratelimit.Middleware(store, cfg))
+
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	rtr.Get("/api/products", ok)
+	rtr.Post("/api/products", ok)
+	rtr.Get("/api/products/{id}", ok)
+	rtr.Put("/api/products/{id}", ok)
+	rtr.Get("/healthz", ok)
+	return rtr
+}
+
+func do(rtr http.Handler, method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range header {
+		for _, value := range v {
+			req.Header.Add(k, value)
+		}
+	}
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware_HeadersAndRejection(t *testing.T) {
+	rtr := newRouter(ratelimit.NewMemoryStore(), ratelimit.Config{
+		Read:  ratelimit.Limit{Requests: 60, Period: time.Minute, Burst: 2},
+		Write: ratelimit.PerMinute(1),
+	})
+
+	w := do(rtr, http.MethodGet, "/api/products", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	require.Equal(t, http.StatusOK, do(rtr, http.MethodGet, "/api/products/p1", nil).Code)
+
+	w = do(rtr, http.MethodGet, "/api/products", nil)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "rate limit exceeded, retry in 1s", body["error"])
+
+	// Writes have their own, stricter bucket
+	require.Equal(t, http.StatusOK, do(rtr, http.MethodPost, "/api/products", nil).Code)
+	w = do(rtr, http.MethodPut, "/api/products/p1", nil)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+}
+
+func TestMiddleware_Rules(t *testing.T) {
+	rtr := newRouter(ratelimit.NewMemoryStore(), ratelimit.Config{
+		Read:  ratelimit.PerMinute(1),
+		Write: ratelimit.PerMinute(1),
+		Rules: []ratelimit.Rule{
+			{Method: http.MethodGet, Route: "/api/products/{id}", Limit: ratelimit.PerMinute(3)},
+			{Route: "/healthz"},
+		},
+	})
+
+	// The rule's bucket is separate from the read bucket
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, do(rtr, http.MethodGet, fmt.Sprintf("/api/products/p%d", i), nil).Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, do(rtr, http.MethodGet, "/api/products/p1", nil).Code)
+	assert.Equal(t, http.StatusOK, do(rtr, http.MethodGet, "/api/products", nil).Code)
+
+	// Exempt routes are never limited and carry no headers
+	for i := 0; i < 5; i++ {
+		w := do(rtr, http.MethodGet, "/healthz", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestMiddleware_ClientKeys(t *testing.T) {
+	cfg := ratelimit.Config{Read: ratelimit.PerMinute(1), Write: ratelimit.PerMinute(1)}
+
+	t.Run("authenticated API keys and IP addresses have separate buckets", func(t *testing.T) {
+		// authenticate stands for an authentication middleware that only knows key-a and key-b
+		authenticate := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if key := r.Header.Get(principal.APIKeyHeader); key == "key-a" || key == "key-b" {
+					r = r.WithContext(principal.NewContext(r.Context(), "apikey:"+principal.Fingerprint(key)))
+				}
+				next.ServeHTTP(w, r)
+			})
+		}
+		rtr := authenticate(newRouter(ratelimit.NewMemoryStore(), cfg))
+		keyA := http.Header{principal.APIKeyHeader: {"key-a"}}
+		keyB := http.Header{principal.APIKeyHeader: {"key-b"}}
+
+		require.Equal(t, http.StatusOK, do(rtr, http.MethodGet, "/api/products", keyA).Code)
+		require.Equal(t, http.StatusTooManyRequests, do(rtr, http.MethodGet, "/api/products", keyA).Code)
+		require.Equal(t, http.StatusOK, do(rtr, http.MethodGet, "/api/products", keyB).Code)
+		// Anonymous requests from the test's remote address
+		require.Equal(t, http.StatusOK, do(rtr, http.MethodGet, "/api/products", nil).Code)
+		require.Equal(t, http.StatusTooManyRequests, do(rtr, http.MethodGet, "/api/products", nil).Code)
+	})
+
+	t.Run("rotating unverified API keys share the IP bucket", func(t *testing.T) {
+		rtr := newRouter(ratelimit.NewMemoryStore(), cfg)
+
+		require.Equal(t, http.StatusOK, do(rtr, http.MethodGet, "/api/products", http.Header{principal.APIKeyHeader: {"bogus-0"}}).Code)
+		for i := 1; i <= 3; i++ {
+			header := http.Header{principal.APIKeyHeader: {fmt.Sprintf("bogus-%d", i)}}
+			assert.Equal(t, http.StatusTooManyRequests, do(rtr, http.MethodGet, "/api/products", header).Code)
+		}
+	})
+
+	t.Run("authenticated principal", func(t *testing.T) {
+		key := ratelimit.ClientKey(false)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set(principal.APIKeyHeader, "key-a")
+		assert.Equal(t, "ip:10.0.0.1", key(req), "An unverified API key should not identify the client")
+
+		req = req.WithContext(principal.NewContext(req.Context(), "user:alice"))
+		assert.Equal(t, "user:alice", key(req))
+	})
+
+	t.Run("X-Forwarded-For is only trusted behind a proxy", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+
+		assert.Equal(t, "ip:10.0.0.1", ratelimit.ClientKey(false)(req))
+		assert.Equal(t, "ip:203.0.113.7", ratelimit.ClientKey(true)(req))
+	})
+}
+
+func TestMiddleware_FailsOpen(t *testing.T) {
+	rtr := newRouter(failingStore{}, ratelimit.Config{Read: ratelimit.PerMinute(1)})
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, do(rtr, http.MethodGet, "/api/products", nil).Code)
+	}
+}
@@ -0,0 +1,317 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	product "sago-sample/feature/product/domain"
+	productInfra "sago-sample/feature/product/infrastructure"
+	productUseCase "sago-sample/feature/product/usecase"
+	webhook "sago-sample/feature/webhook/domain"
+	"sago-sample/feature/webhook/handler"
+	"sago-sample/feature/webhook/infrastructure"
+	usecase "sago-sample/feature/webhook/usecase"
+	"sago-sample/test/apitest"
+)
+
+// receivedDelivery is a request captured by the test receiver
+type receivedDelivery struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver is an httptest server answering with the next queued status, then 200
+type receiver struct {
+	server   *httptest.Server
+	mutex    sync.Mutex
+	statuses []int
+	received []receivedDelivery
+	calls    atomic.Int32
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	t.Helper()
+
+	rc := &receiver{statuses: statuses}
+	rc.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		rc.mutex.Lock()
+		rc.received = append(rc.received, receivedDelivery{header: r.Header.Clone(), body: body})
+		status := http.StatusOK
+		if len(rc.statuses) > 0 {
+			status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+		}
+		rc.mutex.Unlock()
+
+		rc.calls.Add(1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rc.server.Close)
+	return rc
+}
+
+func (rc *receiver) deliveries() []receivedDelivery {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	return append([]receivedDelivery(nil), rc.received...)
+}
+
+// fixture wires the product service, the webhook dispatcher and the webhook routes
+type fixture struct {
+	router chi.Router
+	create *productUseCase.CreateProductUseCase
+	update *productUseCase.UpdateProductUseCase
+}
+
+func newFixture(t *testing.T, maxAttempts int) *fixture {
+	t.Helper()
+
+	subRepo := infrastructure.NewSubscriptionRepository()
+	deliveryRepo := infrastructure.NewDeliveryRepository()
+
+	dispatcher := infrastructure.NewDispatcher(subRepo, deliveryRepo, infrastructure.DispatcherConfig{
+		Workers:        2,
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: 5 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		Timeout:        time.Second,
+	})
+	dispatcher.Start()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, dispatcher.Stop(ctx))
+	})
+
+	service := product.NewService(productInfra.NewProductRepository())
+	service.Subscribe(dispatcher)
+
+	rtr := chi.NewRouter()
+	handler.NewSubscriptionHandler(
+		usecase.NewCreateSubscriptionUseCase(subRepo),
+		usecase.NewGetSubscriptionUseCase(subRepo),
+		usecase.NewListSubscriptionsUseCase(subRepo),
+		usecase.NewUpdateSubscriptionUseCase(subRepo),
+		usecase.NewDeleteSubscriptionUseCase(subRepo),
+		usecase.NewListDeliveriesUseCase(subRepo, deliveryRepo),
+	).Register(rtr)
+
+	return &fixture{
+		router: rtr,
+		create: productUseCase.NewCreateProductUseCase(service),
+		update: productUseCase.NewUpdateProductUseCase(service),
+	}
+}
+
+func (f *fixture) subscribe(t *testing.T, url string, events ...string) handler.SubscriptionResponse {
+	t.Helper()
+
+	w := apitest.Do(t, f.router, http.MethodPost, "/api/webhooks", handler.CreateSubscriptionRequest{URL: url, Events: events, Secret: "0123456789abcdef"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var sub handler.SubscriptionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sub))
+	return sub
+}
+
+func (f *fixture) createProduct(t *testing.T, id string) {
+	t.Helper()
+
+	_, err := f.create.Execute(context.Background(), productUseCase.CreateProductInput{
+		ID: id, Name: "Laptop", Description: "A laptop", Price: 1000, Currency: "USD", Stock: 5,
+	})
+	require.NoError(t, err)
+}
+
+func (f *fixture) deliveryLog(t *testing.T, subscriptionID, query string) []handler.DeliveryResponse {
+	t.Helper()
+
+	w := apitest.Do(t, f.router, http.MethodGet, "/api/webhooks/"+subscriptionID+"/deliveries"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var deliveries []handler.DeliveryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &deliveries))
+	return deliveries
+}
+
+func TestSubscription_CRUD(t *testing.T) {
+	f := newFixture(t, 3)
+
+	w := apitest.Do(t, f.router, http.MethodPost, "/api/webhooks", handler.CreateSubscriptionRequest{URL: "https://example.com/hook", Events: []string{"*"}})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created handler.SubscriptionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.ID)
+	assert.NotEmpty(t, created.Secret, "a secret is generated when none is given")
+	assert.Len(t, created.Events, len(product.EventTypes))
+
+	w = apitest.Do(t, f.router, http.MethodGet, "/api/webhooks/"+created.ID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), created.Secret, "the secret is only returned on creation")
+
+	inactive := false
+	w = apitest.Do(t, f.router, http.MethodPut, "/api/webhooks/"+created.ID, handler.UpdateSubscriptionRequest{
+		URL: "https://example.com/other", Events: []string{"product.deleted"}, Active: &inactive,
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	var updated handler.SubscriptionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, "https://example.com/other", updated.URL)
+	assert.Equal(t, []string{"product.deleted"}, updated.Events)
+	assert.False(t, updated.Active)
+
+	w = apitest.Do(t, f.router, http.MethodGet, "/api/webhooks", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []handler.SubscriptionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list, 1)
+
+	assert.Equal(t, http.StatusNoContent, apitest.Do(t, f.router, http.MethodDelete, "/api/webhooks/"+created.ID, nil).Code)
+	assert.Equal(t, http.StatusNotFound, apitest.Do(t, f.router, http.MethodGet, "/api/webhooks/"+created.ID, nil).Code)
+	assert.Equal(t, http.StatusNotFound, apitest.Do(t, f.router, http.MethodGet, "/api/webhooks/"+created.ID+"/deliveries", nil).Code)
+}
+
+func TestSubscription_Validation(t *testing.T) {
+	f := newFixture(t, 3)
+
+	tests := []struct {
+		name string
+		req  handler.CreateSubscriptionRequest
+	}{
+		{"relative URL", handler.CreateSubscriptionRequest{URL: "/hook", Events: []string{"*"}}},
+		{"unsupported scheme", handler.CreateSubscriptionRequest{URL: "ftp://example.com", Events: []string{"*"}}},
+		{"no events", handler.CreateSubscriptionRequest{URL: "https://example.com"}},
+		{"unknown event", handler.CreateSubscriptionRequest{URL: "https://example.com", Events: []string{"order.created"}}},
+		{"short secret", handler.CreateSubscriptionRequest{URL: "https://example.com", Events: []string{"*"}, Secret: "short"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := apitest.Do(t, f.router, http.MethodPost, "/api/webhooks", tt.req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestDelivery_SignedPayload(t *testing.T) {
+	f := newFixture(t, 3)
+	rc := newReceiver(t)
+	sub := f.subscribe(t, rc.server.URL, "product.created")
+
+	f.createProduct(t, "p1")
+
+	require.Eventually(t, func() bool { return rc.calls.Load() == 1 }, time.Second, 5*time.Millisecond)
+	got := rc.deliveries()[0]
+
+	assert.Equal(t, "product.created", got.header.Get(webhook.EventHeader))
+	assert.NotEmpty(t, got.header.Get(webhook.DeliveryHeader))
+	timestamp, err := strconv.ParseInt(got.header.Get(webhook.TimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, webhook.Verify("0123456789abcdef", timestamp, got.body, got.header.Get(webhook.SignatureHeader)))
+	assert.False(t, webhook.Verify("another-secret-value", timestamp, got.body, got.header.Get(webhook.SignatureHeader)))
+
+	var payload infrastructure.EventPayload
+	require.NoError(t, json.Unmarshal(got.body, &payload))
+	assert.Equal(t, "product.created", payload.Type)
+	assert.Equal(t, "p1", payload.Data.ProductID)
+	require.NotNil(t, payload.Data.Product)
+	assert.Equal(t, "Laptop", payload.Data.Product.Name)
+
+	require.Eventually(t, func() bool {
+		log := f.deliveryLog(t, sub.ID, "")
+		return len(log) == 1 && log[0].Status == string(webhook.DeliverySucceeded)
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestDelivery_FiltersByEventType(t *testing.T) {
+	f := newFixture(t, 3)
+	rc := newReceiver(t)
+	f.subscribe(t, rc.server.URL, "product.price_changed")
+
+	f.createProduct(t, "p1")
+	_, err := f.update.Execute(context.Background(), productUseCase.UpdateProductInput{
+		ID: "p1", Name: "Laptop", Description: "A laptop", Price: 900, Currency: "USD", Stock: 5,
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return rc.calls.Load() == 1 }, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	require.Len(t, rc.deliveries(), 1)
+
+	var payload infrastructure.EventPayload
+	require.NoError(t, json.Unmarshal(rc.deliveries()[0].body, &payload))
+	assert.Equal(t, "product.price_changed", payload.Type)
+	require.NotNil(t, payload.Data.PreviousPrice)
+	assert.Equal(t, uint(1000), payload.Data.PreviousPrice.Amount)
+	assert.Equal(t, uint(900), payload.Data.Product.Price)
+}
+
+func TestDelivery_RetriesWithBackoff(t *testing.T) {
+	f := newFixture(t, 5)
+	rc := newReceiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable)
+	sub := f.subscribe(t, rc.server.URL, "*")
+
+	f.createProduct(t, "p1")
+
+	require.Eventually(t, func() bool {
+		log := f.deliveryLog(t, sub.ID, "")
+		return len(log) == 1 && log[0].Status == string(webhook.DeliverySucceeded)
+	}, 2*time.Second, 5*time.Millisecond)
+
+	log := f.deliveryLog(t, sub.ID, "")
+	assert.Equal(t, 3, log[0].Attempts)
+	assert.Equal(t, http.StatusOK, log[0].LastStatusCode)
+
+	received := rc.deliveries()
+	require.Len(t, received, 3)
+	deliveryID := received[0].header.Get(webhook.DeliveryHeader)
+	for _, r := range received {
+		assert.Equal(t, deliveryID, r.header.Get(webhook.DeliveryHeader), "retries keep the delivery ID")
+		assert.Equal(t, received[0].body, r.body)
+	}
+}
+
+func TestDelivery_DeadLettersAfterMaxAttempts(t *testing.T) {
+	f := newFixture(t, 3)
+	rc := newReceiver(t, 500, 500, 500, 500, 500)
+	sub := f.subscribe(t, rc.server.URL, "*")
+
+	f.createProduct(t, "p1")
+
+	require.Eventually(t, func() bool {
+		return len(f.deliveryLog(t, sub.ID, "?status=dead_lettered")) == 1
+	}, 2*time.Second, 5*time.Millisecond)
+
+	dead := f.deliveryLog(t, sub.ID, "?status=dead_lettered")[0]
+	assert.Equal(t, 3, dead.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, dead.LastStatusCode)
+	assert.NotEmpty(t, dead.LastError)
+	assert.Nil(t, dead.NextAttemptAt)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(3), rc.calls.Load(), "dead-lettered deliveries are not retried")
+	assert.Empty(t, f.deliveryLog(t, sub.ID, "?status=pending"))
+
+	assert.Equal(t, http.StatusBadRequest, apitest.Do(t, f.router, http.MethodGet, "/api/webhooks/"+sub.ID+"/deliveries?status=unknown", nil).Code)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, webhook.Backoff(1, time.Second, time.Minute))
+	assert.Equal(t, 2*time.Second, webhook.Backoff(2, time.Second, time.Minute))
+	assert.Equal(t, 8*time.Second, webhook.Backoff(4, time.Second, time.Minute))
+	assert.Equal(t, time.Minute, webhook.Backoff(20, time.Second, time.Minute))
+}